@@ -29,6 +29,7 @@ WITH NO DATA;
 CREATE UNIQUE INDEX idx_mv_monthly_visit_stats ON mv_monthly_visit_stats (month, district, visit_type, status);
 
 -- Child vaccination coverage by age group
+-- Eligible children are aged from 30 days before to 91 days after the dose's recommended age
 CREATE MATERIALIZED VIEW mv_vaccination_coverage AS
 SELECT
   date_trunc('month', CURRENT_DATE) AS report_month,
@@ -40,24 +41,22 @@ SELECT
     SELECT COUNT(*) 
     FROM children c2
     WHERE 
-      EXTRACT(YEAR FROM age(CURRENT_DATE, c2.date_of_birth)) * 12 +
-      EXTRACT(MONTH FROM age(CURRENT_DATE, c2.date_of_birth)) 
+      (CURRENT_DATE - c2.date_of_birth)
       BETWEEN 
-        (SELECT age_months FROM vaccine_schedules vs WHERE vs.vaccine_name = ir.vaccine_name AND vs.vaccine_dose = ir.vaccine_dose) - 1
+        (SELECT age_days FROM vaccine_schedules vs WHERE vs.vaccine_name = ir.vaccine_name AND vs.vaccine_dose = ir.vaccine_dose) - 30
       AND 
-        (SELECT age_months FROM vaccine_schedules vs WHERE vs.vaccine_name = ir.vaccine_name AND vs.vaccine_dose = ir.vaccine_dose) + 3
+        (SELECT age_days FROM vaccine_schedules vs WHERE vs.vaccine_name = ir.vaccine_name AND vs.vaccine_dose = ir.vaccine_dose) + 91
   ) AS eligible_children,
   CASE 
     WHEN (
       SELECT COUNT(*) 
       FROM children c2
       WHERE 
-        EXTRACT(YEAR FROM age(CURRENT_DATE, c2.date_of_birth)) * 12 +
-        EXTRACT(MONTH FROM age(CURRENT_DATE, c2.date_of_birth)) 
+        (CURRENT_DATE - c2.date_of_birth)
         BETWEEN 
-          (SELECT age_months FROM vaccine_schedules vs WHERE vs.vaccine_name = ir.vaccine_name AND vs.vaccine_dose = ir.vaccine_dose) - 1
+          (SELECT age_days FROM vaccine_schedules vs WHERE vs.vaccine_name = ir.vaccine_name AND vs.vaccine_dose = ir.vaccine_dose) - 30
         AND 
-          (SELECT age_months FROM vaccine_schedules vs WHERE vs.vaccine_name = ir.vaccine_name AND vs.vaccine_dose = ir.vaccine_dose) + 3
+          (SELECT age_days FROM vaccine_schedules vs WHERE vs.vaccine_name = ir.vaccine_name AND vs.vaccine_dose = ir.vaccine_dose) + 91
     ) > 0 THEN
       ROUND(
         (COUNT(DISTINCT c.id)::numeric / 
@@ -65,12 +64,11 @@ SELECT
           SELECT COUNT(*) 
           FROM children c2
           WHERE 
-            EXTRACT(YEAR FROM age(CURRENT_DATE, c2.date_of_birth)) * 12 +
-            EXTRACT(MONTH FROM age(CURRENT_DATE, c2.date_of_birth)) 
+            (CURRENT_DATE - c2.date_of_birth)
             BETWEEN 
-              (SELECT age_months FROM vaccine_schedules vs WHERE vs.vaccine_name = ir.vaccine_name AND vs.vaccine_dose = ir.vaccine_dose) - 1
+              (SELECT age_days FROM vaccine_schedules vs WHERE vs.vaccine_name = ir.vaccine_name AND vs.vaccine_dose = ir.vaccine_dose) - 30
             AND 
-              (SELECT age_months FROM vaccine_schedules vs WHERE vs.vaccine_name = ir.vaccine_name AND vs.vaccine_dose = ir.vaccine_dose) + 3
+              (SELECT age_days FROM vaccine_schedules vs WHERE vs.vaccine_name = ir.vaccine_name AND vs.vaccine_dose = ir.vaccine_dose) + 91
         )::numeric) * 100, 2
       )
     ELSE 0
@@ -16,11 +16,15 @@ CREATE TABLE IF NOT EXISTS growth_measurements (
   height_cm DECIMAL(5,2) CHECK (height_cm BETWEEN 20 AND 150),
   head_circumference_cm DECIMAL(4,1) CHECK (head_circumference_cm BETWEEN 10 AND 60),
   mid_upper_arm_circumference_cm DECIMAL(4,1),
+  measured_recumbent BOOLEAN, -- Length lying down; WHO uses length under 24 months
   
-  -- WHO z-scores (standard deviations from median)
-  weight_for_age_z DECIMAL(3,2),
-  height_for_age_z DECIMAL(3,2),
-  weight_for_height_z DECIMAL(3,2),
+  -- WHO z-scores (standard deviations from median), computed by the growth service
+  weight_for_age_z DECIMAL(4,2),
+  height_for_age_z DECIMAL(4,2),
+  weight_for_height_z DECIMAL(4,2),
+  head_circumference_for_age_z DECIMAL(4,2),
+  muac_for_age_z DECIMAL(4,2),
+  implausible_z BOOLEAN NOT NULL DEFAULT FALSE, -- Beyond the WHO plausibility limits
   
   -- Growth status assessment
   growth_status growth_status NOT NULL,
@@ -41,7 +45,8 @@ CREATE TABLE IF NOT EXISTS growth_measurements (
   CONSTRAINT measurement_has_weight_or_height CHECK (
     weight_grams IS NOT NULL OR 
     height_cm IS NOT NULL OR 
-    head_circumference_cm IS NOT NULL
+    head_circumference_cm IS NOT NULL OR
+    mid_upper_arm_circumference_cm IS NOT NULL
   ),
   
   CONSTRAINT valid_measurer_info CHECK (
@@ -9,7 +9,7 @@ CREATE TABLE IF NOT EXISTS screener_answers (
   screener_result_id UUID NOT NULL REFERENCES screener_results(id) ON DELETE CASCADE,
   
   -- Question reference
-  question_id UUID NOT NULL REFERENCES screener_questions(id),
+  question_id UUID NOT NULL REFERENCES screener_questions(id) ON DELETE RESTRICT, -- Answers keep their questions
   question_text TEXT NOT NULL, -- Denormalized for historical record
   
   -- Answer data (stored in appropriate type column based on question type)
@@ -0,0 +1,80 @@
+-- Screener questionnaires table for MamaCare SL
+-- Versions of the screening questions asked of mothers and children
+
+CREATE TABLE IF NOT EXISTS screener_questionnaires (
+  -- Primary identifier
+  id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+  
+  -- Who the questionnaire screens and which version it is
+  subject TEXT NOT NULL CHECK (subject IN ('mother', 'child')),
+  version INTEGER NOT NULL CHECK (version > 0),
+  title TEXT NOT NULL,
+  
+  -- Life cycle: drafts are edited, published versions are in use, retired ones are kept for history
+  status TEXT NOT NULL DEFAULT 'DRAFT' CHECK (status IN ('DRAFT', 'PUBLISHED', 'RETIRED')),
+  based_on_id UUID REFERENCES screener_questionnaires(id), -- Version this one was copied from
+  notes TEXT,
+  
+  -- Authoring
+  created_by_id UUID REFERENCES users(id),
+  published_by_id UUID REFERENCES users(id),
+  published_at TIMESTAMP WITH TIME ZONE,
+  
+  -- System fields
+  created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  
+  -- Versions are numbered per subject
+  UNIQUE (subject, version),
+  
+  CONSTRAINT published_questionnaire_has_date CHECK (status = 'DRAFT' OR published_at IS NOT NULL),
+  
+  -- Only one version per subject is in use. Deferred so that publishing can retire the
+  -- previous version in the same statement.
+  CONSTRAINT one_published_questionnaire EXCLUDE (subject WITH =) WHERE (status = 'PUBLISHED')
+    DEFERRABLE INITIALLY DEFERRED
+);
+
+-- Automatically update the updated_at timestamp
+CREATE TRIGGER update_screener_questionnaires_updated_at
+BEFORE UPDATE ON screener_questionnaires
+FOR EACH ROW
+EXECUTE FUNCTION update_updated_at_column();
+
+-- Published and retired versions cannot be edited or deleted; only their status moves on
+CREATE OR REPLACE FUNCTION protect_published_screener_questionnaire()
+RETURNS TRIGGER AS $$
+BEGIN
+  IF TG_OP = 'DELETE' THEN
+    IF OLD.status <> 'DRAFT' THEN
+      RAISE EXCEPTION 'screener questionnaire % version % is published and cannot be deleted', OLD.subject, OLD.version;
+    END IF;
+    RETURN OLD;
+  END IF;
+
+  IF OLD.status <> 'DRAFT' AND (
+    NEW.status = 'DRAFT' OR
+    (OLD.status = 'RETIRED' AND NEW.status <> 'RETIRED') OR
+    ROW(NEW.subject, NEW.version, NEW.title, NEW.notes, NEW.based_on_id, NEW.published_at)
+      IS DISTINCT FROM ROW(OLD.subject, OLD.version, OLD.title, OLD.notes, OLD.based_on_id, OLD.published_at)
+  ) THEN
+    RAISE EXCEPTION 'screener questionnaire % version % is published and cannot be changed', OLD.subject, OLD.version;
+  END IF;
+  RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER protect_published_screener_questionnaire
+BEFORE UPDATE OR DELETE ON screener_questionnaires
+FOR EACH ROW
+EXECUTE FUNCTION protect_published_screener_questionnaire();
+
+-- No row-level security since this is reference data
+-- This should be managed by admins only via Hasura permissions
+
+-- Create indexes for common queries
+CREATE INDEX idx_screener_questionnaires_subject_status ON screener_questionnaires (subject, status);
+
+-- Add comments for documentation
+COMMENT ON TABLE screener_questionnaires IS 'Versions of the screening questions asked of mothers and children';
+COMMENT ON COLUMN screener_questionnaires.status IS 'DRAFT while being authored, PUBLISHED while in use, RETIRED once replaced';
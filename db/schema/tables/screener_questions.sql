@@ -5,6 +5,9 @@ CREATE TABLE IF NOT EXISTS screener_questions (
   -- Primary identifier
   id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
   
+  -- Questionnaire version the question belongs to
+  questionnaire_id UUID NOT NULL REFERENCES screener_questionnaires(id) ON DELETE CASCADE,
+  
   -- Question content
   question_text non_empty_text NOT NULL,
   question_code TEXT NOT NULL, -- Machine-readable code for this question
@@ -45,8 +48,8 @@ CREATE TABLE IF NOT EXISTS screener_questions (
     answer_type != 'MULTIPLE_CHOICE' OR answer_options IS NOT NULL
   ),
   
-  -- Ensure unique question codes
-  UNIQUE (question_code)
+  -- Ensure unique question codes within a questionnaire version
+  CONSTRAINT unique_questionnaire_question_code UNIQUE (questionnaire_id, question_code)
 );
 
 -- Automatically update the updated_at timestamp
@@ -55,6 +58,39 @@ BEFORE UPDATE ON screener_questions
 FOR EACH ROW
 EXECUTE FUNCTION update_updated_at_column();
 
+-- Questions of published and retired versions cannot be changed
+CREATE OR REPLACE FUNCTION protect_published_screener_question()
+RETURNS TRIGGER AS $$
+DECLARE
+  questionnaire_ids UUID[];
+BEGIN
+  IF TG_OP = 'INSERT' THEN
+    questionnaire_ids := ARRAY[NEW.questionnaire_id];
+  ELSIF TG_OP = 'UPDATE' THEN
+    questionnaire_ids := ARRAY[OLD.questionnaire_id, NEW.questionnaire_id];
+  ELSE
+    questionnaire_ids := ARRAY[OLD.questionnaire_id];
+  END IF;
+
+  IF EXISTS (
+    SELECT 1 FROM screener_questionnaires q
+    WHERE q.id = ANY(questionnaire_ids) AND q.status <> 'DRAFT'
+  ) THEN
+    RAISE EXCEPTION 'questions of a published screener questionnaire cannot be changed';
+  END IF;
+
+  IF TG_OP = 'DELETE' THEN
+    RETURN OLD;
+  END IF;
+  RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER protect_published_screener_question
+BEFORE INSERT OR UPDATE OR DELETE ON screener_questions
+FOR EACH ROW
+EXECUTE FUNCTION protect_published_screener_question();
+
 -- No row-level security since this is reference data
 -- This should be managed by admins only via Hasura permissions
 
@@ -63,9 +99,11 @@ CREATE INDEX idx_screener_questions_category ON screener_questions (category);
 CREATE INDEX idx_screener_questions_is_danger_sign ON screener_questions (is_danger_sign);
 CREATE INDEX idx_screener_questions_risk_category ON screener_questions (risk_category);
 CREATE INDEX idx_screener_questions_active ON screener_questions (is_active);
+CREATE INDEX idx_screener_questions_questionnaire_id ON screener_questions (questionnaire_id, display_order);
 
 -- Add comments for documentation
 COMMENT ON TABLE screener_questions IS 'Defines health screening questions used in maternal and child health assessments';
-COMMENT ON COLUMN screener_questions.question_code IS 'Unique code for programmatic reference';
+COMMENT ON COLUMN screener_questions.questionnaire_id IS 'Questionnaire version the question belongs to';
+COMMENT ON COLUMN screener_questions.question_code IS 'Code for programmatic reference, unique within a questionnaire version';
 COMMENT ON COLUMN screener_questions.is_danger_sign IS 'Whether a positive response indicates a potential emergency';
 COMMENT ON COLUMN screener_questions.answer_options IS 'JSON array of options for multiple choice questions';
@@ -9,6 +9,9 @@ CREATE TABLE IF NOT EXISTS screener_results (
   mother_id UUID REFERENCES users(id) ON DELETE SET NULL,
   child_id UUID REFERENCES children(id) ON DELETE SET NULL,
   
+  -- Questionnaire version the screening was done with
+  questionnaire_id UUID REFERENCES screener_questionnaires(id),
+  
   -- Screening session details
   screened_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
   screened_by_user_id UUID REFERENCES users(id),
@@ -90,6 +93,7 @@ CREATE INDEX idx_screener_results_child_id ON screener_results (child_id);
 CREATE INDEX idx_screener_results_risk_level ON screener_results (risk_level);
 CREATE INDEX idx_screener_results_danger_signs ON screener_results (danger_signs_detected);
 CREATE INDEX idx_screener_results_date ON screener_results (screened_at);
+CREATE INDEX idx_screener_results_questionnaire_id ON screener_results (questionnaire_id);
 
 -- Add comments for documentation
 COMMENT ON TABLE screener_results IS 'Stores health assessment responses and risk evaluations';
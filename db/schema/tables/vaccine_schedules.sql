@@ -10,9 +10,12 @@ CREATE TABLE IF NOT EXISTS vaccine_schedules (
   vaccine_dose TEXT NOT NULL, -- e.g., "1", "2", "booster"
   
   -- When to administer
-  age_months INTEGER NOT NULL, -- Can be 0 for birth vaccines
+  age_days INTEGER NOT NULL, -- Can be 0 for birth vaccines; the EPI schedule is in weeks
+  age_months NUMERIC(4,1), -- Deprecated, for display only; use age_days
   window_start_days INTEGER NOT NULL, -- Days before recommended age when vaccine can be given
   window_end_days INTEGER NOT NULL, -- Days after recommended age when vaccine should be given
+  min_interval_days INTEGER NOT NULL DEFAULT 0, -- Minimum days after the previous dose of the series
+  max_age_days INTEGER, -- Age after which the dose is no longer given, if any
   
   -- Vaccine details
   disease_target TEXT NOT NULL, -- What disease this prevents
@@ -25,6 +28,9 @@ CREATE TABLE IF NOT EXISTS vaccine_schedules (
   
   -- Make sure window_end_days ≥ window_start_days
   CONSTRAINT valid_window CHECK (window_end_days >= window_start_days),
+  CONSTRAINT valid_age_days CHECK (age_days >= 0),
+  CONSTRAINT valid_min_interval CHECK (min_interval_days >= 0),
+  CONSTRAINT valid_max_age CHECK (max_age_days IS NULL OR max_age_days >= age_days),
   
   -- Unique constraint to prevent duplicate entries
   UNIQUE (vaccine_name, vaccine_dose)
@@ -40,11 +46,14 @@ EXECUTE FUNCTION update_updated_at_column();
 -- Only admins should be able to modify this via Hasura permissions
 
 -- Create indexes for common queries
-CREATE INDEX idx_vaccine_schedules_age ON vaccine_schedules (age_months);
+CREATE INDEX idx_vaccine_schedules_age ON vaccine_schedules (age_days);
 CREATE INDEX idx_vaccine_schedules_vaccine ON vaccine_schedules (vaccine_name, vaccine_dose);
 
 -- Add comments for documentation
 COMMENT ON TABLE vaccine_schedules IS 'Standard vaccination schedules based on Sierra Leone national guidelines';
-COMMENT ON COLUMN vaccine_schedules.age_months IS 'Recommended age in months for this vaccine';
+COMMENT ON COLUMN vaccine_schedules.age_days IS 'Recommended age in days for this vaccine';
+COMMENT ON COLUMN vaccine_schedules.age_months IS 'Deprecated, approximate age in months for display; use age_days';
 COMMENT ON COLUMN vaccine_schedules.window_start_days IS 'Days before recommended age when vaccine can be given';
 COMMENT ON COLUMN vaccine_schedules.window_end_days IS 'Days after recommended age when vaccine should be given';
+COMMENT ON COLUMN vaccine_schedules.min_interval_days IS 'Minimum days after the previous dose of the series for this dose to count';
+COMMENT ON COLUMN vaccine_schedules.max_age_days IS 'Age in days after which this dose is no longer given';
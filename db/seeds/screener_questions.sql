@@ -1,13 +1,20 @@
 -- Screener Questions Seed Data for MamaCare SL
 -- Contains health screening questions for risk assessment
 
--- Clear existing data (for re-seeding)
-TRUNCATE TABLE screener_questions RESTART IDENTITY CASCADE;
+-- Questions are seeded into a draft version 1 of each questionnaire, which is published at
+-- the end. Once published they can no longer change, so re-seeding leaves them untouched;
+-- later changes are authored as new questionnaire versions.
+INSERT INTO screener_questionnaires (subject, version, title, notes)
+VALUES
+('mother', 1, 'Maternal danger sign screening', 'Initial questions'),
+('child', 1, 'Child danger sign screening', 'Initial questions')
+ON CONFLICT (subject, version) DO NOTHING;
 
 -- Insert maternal danger sign questions
 INSERT INTO screener_questions 
-(question_text, question_code, answer_type, is_danger_sign, risk_category, category, subcategory, display_order, translation_key)
-VALUES
+(questionnaire_id, question_text, question_code, answer_type, is_danger_sign, risk_category, category, subcategory, display_order, translation_key)
+SELECT q.id, v.*
+FROM (VALUES
 -- Severe danger signs (RED risk level)
 ('Are you experiencing any vaginal bleeding?', 'MATERNAL_BLEEDING', 'BOOLEAN', TRUE, 'RED', 'Maternal', 'Bleeding', 1, 'question.maternal.bleeding'),
 ('Have you had any convulsions or fits?', 'MATERNAL_CONVULSIONS', 'BOOLEAN', TRUE, 'RED', 'Maternal', 'Neurological', 2, 'question.maternal.convulsions'),
@@ -27,12 +34,16 @@ VALUES
 ('How many weeks pregnant are you?', 'MATERNAL_GESTATION', 'NUMERIC', FALSE, NULL, 'Maternal', 'General', 12, 'question.maternal.gestation'),
 ('Have you attended any antenatal care visits?', 'MATERNAL_ANC', 'BOOLEAN', FALSE, NULL, 'Maternal', 'General', 13, 'question.maternal.anc'),
 ('Are you taking iron and folic acid supplements?', 'MATERNAL_SUPPLEMENTS', 'BOOLEAN', FALSE, NULL, 'Maternal', 'Nutrition', 14, 'question.maternal.supplements'),
-('How many meals do you eat per day?', 'MATERNAL_NUTRITION', 'NUMERIC', FALSE, NULL, 'Maternal', 'Nutrition', 15, 'question.maternal.nutrition');
+('How many meals do you eat per day?', 'MATERNAL_NUTRITION', 'NUMERIC', FALSE, NULL, 'Maternal', 'Nutrition', 15, 'question.maternal.nutrition')
+) AS v (question_text, question_code, answer_type, is_danger_sign, risk_category, category, subcategory, display_order, translation_key)
+JOIN screener_questionnaires q ON q.subject = 'mother' AND q.version = 1 AND q.status = 'DRAFT'
+ON CONFLICT (questionnaire_id, question_code) DO NOTHING;
 
 -- Insert newborn/infant danger sign questions
 INSERT INTO screener_questions 
-(question_text, question_code, answer_type, is_danger_sign, risk_category, category, subcategory, display_order, translation_key)
-VALUES
+(questionnaire_id, question_text, question_code, answer_type, is_danger_sign, risk_category, category, subcategory, display_order, translation_key)
+SELECT q.id, v.*
+FROM (VALUES
 -- Severe danger signs (RED risk level)
 ('Is the child having difficulty breathing?', 'CHILD_BREATHING', 'BOOLEAN', TRUE, 'RED', 'Child', 'Respiratory', 16, 'question.child.breathing'),
 ('Does the child have convulsions or fits?', 'CHILD_CONVULSIONS', 'BOOLEAN', TRUE, 'RED', 'Child', 'Neurological', 17, 'question.child.convulsions'),
@@ -52,20 +63,38 @@ VALUES
 ('Is the child up-to-date with vaccinations?', 'CHILD_VACCINATION', 'BOOLEAN', FALSE, NULL, 'Child', 'General', 27, 'question.child.vaccination'),
 ('Is the child breastfeeding?', 'CHILD_BREASTFEEDING', 'BOOLEAN', FALSE, NULL, 'Child', 'Nutrition', 28, 'question.child.breastfeeding'),
 ('What is the child''s weight?', 'CHILD_WEIGHT', 'NUMERIC', FALSE, NULL, 'Child', 'Growth', 29, 'question.child.weight'),
-('Has the child lost weight recently?', 'CHILD_WEIGHT_LOSS', 'BOOLEAN', FALSE, NULL, 'Child', 'Growth', 30, 'question.child.weight_loss');
+('Has the child lost weight recently?', 'CHILD_WEIGHT_LOSS', 'BOOLEAN', FALSE, NULL, 'Child', 'Growth', 30, 'question.child.weight_loss')
+) AS v (question_text, question_code, answer_type, is_danger_sign, risk_category, category, subcategory, display_order, translation_key)
+JOIN screener_questionnaires q ON q.subject = 'child' AND q.version = 1 AND q.status = 'DRAFT'
+ON CONFLICT (questionnaire_id, question_code) DO NOTHING;
 
 -- Insert multiple-choice questions
-UPDATE screener_questions
-SET answer_options = '["Less than 3", "3-4", "5 or more"]'
-WHERE question_code = 'MATERNAL_NUTRITION';
+UPDATE screener_questions sq
+SET answer_type = 'MULTIPLE_CHOICE',
+    answer_options = '["Less than 3", "3-4", "5 or more"]'
+FROM screener_questionnaires q
+WHERE sq.questionnaire_id = q.id AND q.status = 'DRAFT' AND q.version = 1
+  AND sq.question_code = 'MATERNAL_NUTRITION';
 
 -- Define dependencies between questions
-UPDATE screener_questions
-SET depends_on_question_id = (SELECT id FROM screener_questions WHERE question_code = 'CHILD_DIARRHEA'),
+UPDATE screener_questions sq
+SET depends_on_question_id = d.id,
     depends_on_answer = 'true'
-WHERE question_code = 'CHILD_WEIGHT_LOSS';
+FROM screener_questionnaires q, screener_questions d
+WHERE sq.questionnaire_id = q.id AND q.status = 'DRAFT' AND q.version = 1
+  AND d.questionnaire_id = q.id AND d.question_code = 'CHILD_DIARRHEA'
+  AND sq.question_code = 'CHILD_WEIGHT_LOSS';
 
-UPDATE screener_questions
-SET depends_on_question_id = (SELECT id FROM screener_questions WHERE question_code = 'MATERNAL_FEVER'),
+UPDATE screener_questions sq
+SET depends_on_question_id = d.id,
     depends_on_answer = 'true'
-WHERE question_code = 'MATERNAL_FEVER_HEADACHE';
+FROM screener_questionnaires q, screener_questions d
+WHERE sq.questionnaire_id = q.id AND q.status = 'DRAFT' AND q.version = 1
+  AND d.questionnaire_id = q.id AND d.question_code = 'MATERNAL_FEVER'
+  AND sq.question_code = 'MATERNAL_FEVER_HEADACHE';
+
+-- Publish the initial questionnaires
+UPDATE screener_questionnaires
+SET status = 'PUBLISHED',
+    published_at = CURRENT_TIMESTAMP
+WHERE version = 1 AND status = 'DRAFT';
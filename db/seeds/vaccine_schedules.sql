@@ -1,52 +1,52 @@
 -- Vaccine Schedules Seed Data for MamaCare SL
 -- Based on WHO Expanded Programme on Immunization (EPI) for Sierra Leone
+-- Ages are in days, as the primary series is given at 6, 10 and 14 weeks
 
 -- Clear existing data (for re-seeding)
 TRUNCATE TABLE vaccine_schedules RESTART IDENTITY CASCADE;
 
 -- Insert vaccine schedule based on Sierra Leone's EPI
-INSERT INTO vaccine_schedules 
-(vaccine_name, vaccine_dose, age_months, window_start_days, window_end_days, disease_target, is_required)
+INSERT INTO vaccine_schedules
+(vaccine_name, vaccine_dose, age_days, age_months, window_start_days, window_end_days, min_interval_days, max_age_days, disease_target, is_required)
 VALUES
 -- At Birth
-('BCG', '1', 0, 0, 30, 'Tuberculosis', TRUE),
-('OPV', '0', 0, 0, 30, 'Polio', TRUE),
-('Hepatitis B', '1', 0, 0, 30, 'Hepatitis B', TRUE),
-
--- 6 Weeks (1.5 months)
-('OPV', '1', 1.5, -7, 30, 'Polio', TRUE),
-('Penta', '1', 1.5, -7, 30, 'Diphtheria, Pertussis, Tetanus, Hepatitis B, Hib', TRUE),
-('PCV', '1', 1.5, -7, 30, 'Pneumococcal disease', TRUE),
-('Rotavirus', '1', 1.5, -7, 30, 'Rotavirus diarrhea', TRUE),
-
--- 10 Weeks (2.5 months)
-('OPV', '2', 2.5, -7, 30, 'Polio', TRUE),
-('Penta', '2', 2.5, -7, 30, 'Diphtheria, Pertussis, Tetanus, Hepatitis B, Hib', TRUE),
-('PCV', '2', 2.5, -7, 30, 'Pneumococcal disease', TRUE),
-('Rotavirus', '2', 2.5, -7, 30, 'Rotavirus diarrhea', TRUE),
-
--- 14 Weeks (3.5 months)
-('OPV', '3', 3.5, -7, 30, 'Polio', TRUE),
-('IPV', '1', 3.5, -7, 30, 'Polio', TRUE),
-('Penta', '3', 3.5, -7, 30, 'Diphtheria, Pertussis, Tetanus, Hepatitis B, Hib', TRUE),
-('PCV', '3', 3.5, -7, 30, 'Pneumococcal disease', TRUE),
+('BCG', '1', 0, 0, 0, 30, 0, 365, 'Tuberculosis', TRUE),
+('OPV', '0', 0, 0, 0, 14, 0, 14, 'Polio', TRUE),
+('Hepatitis B', '1', 0, 0, 0, 30, 0, NULL, 'Hepatitis B', TRUE),
+
+-- 6 Weeks
+('OPV', '1', 42, 1.5, -7, 30, 28, NULL, 'Polio', TRUE),
+('Penta', '1', 42, 1.5, -7, 30, 0, NULL, 'Diphtheria, Pertussis, Tetanus, Hepatitis B, Hib', TRUE),
+('PCV', '1', 42, 1.5, -7, 30, 0, NULL, 'Pneumococcal disease', TRUE),
+('Rotavirus', '1', 42, 1.5, -7, 30, 0, 105, 'Rotavirus diarrhea', TRUE),
+
+-- 10 Weeks
+('OPV', '2', 70, 2.5, -7, 30, 28, NULL, 'Polio', TRUE),
+('Penta', '2', 70, 2.5, -7, 30, 28, NULL, 'Diphtheria, Pertussis, Tetanus, Hepatitis B, Hib', TRUE),
+('PCV', '2', 70, 2.5, -7, 30, 28, NULL, 'Pneumococcal disease', TRUE),
+('Rotavirus', '2', 70, 2.5, -7, 30, 28, 224, 'Rotavirus diarrhea', TRUE),
+
+-- 14 Weeks
+('OPV', '3', 98, 3.5, -7, 30, 28, NULL, 'Polio', TRUE),
+('IPV', '1', 98, 3.5, -7, 30, 0, NULL, 'Polio', TRUE),
+('Penta', '3', 98, 3.5, -7, 30, 28, NULL, 'Diphtheria, Pertussis, Tetanus, Hepatitis B, Hib', TRUE),
+('PCV', '3', 98, 3.5, -7, 30, 28, NULL, 'Pneumococcal disease', TRUE),
 
 -- 9 Months
-('Measles/Rubella', '1', 9, -7, 60, 'Measles and Rubella', TRUE),
-('Yellow Fever', '1', 9, -7, 60, 'Yellow Fever', TRUE),
-('Vitamin A', '1', 9, -7, 60, 'Vitamin A deficiency', TRUE),
+('Measles/Rubella', '1', 274, 9, -7, 60, 0, NULL, 'Measles and Rubella', TRUE),
+('Yellow Fever', '1', 274, 9, -7, 60, 0, NULL, 'Yellow Fever', TRUE),
+('Vitamin A', '1', 274, 9, -7, 60, 0, NULL, 'Vitamin A deficiency', TRUE),
 
 -- 15 Months
-('Measles/Rubella', '2', 15, -7, 60, 'Measles and Rubella', TRUE),
-('Meningitis A', '1', 15, -7, 60, 'Meningitis A', TRUE),
+('Measles/Rubella', '2', 457, 15, -7, 60, 28, NULL, 'Measles and Rubella', TRUE),
+('Meningitis A', '1', 457, 15, -7, 60, 0, NULL, 'Meningitis A', TRUE),
 
 -- 18 Months
-('DTP', 'Booster', 18, -14, 60, 'Diphtheria, Tetanus, Pertussis', TRUE),
-('OPV', 'Booster', 18, -14, 60, 'Polio', TRUE),
-('Vitamin A', '2', 18, -14, 60, 'Vitamin A deficiency', TRUE),
+('DTP', 'Booster', 548, 18, -14, 60, 180, NULL, 'Diphtheria, Tetanus, Pertussis', TRUE),
+('OPV', 'Booster', 548, 18, -14, 60, 28, NULL, 'Polio', TRUE),
+('Vitamin A', '2', 548, 18, -14, 60, 180, NULL, 'Vitamin A deficiency', TRUE);
 
--- Other boosters
-('Tetanus toxoid', 'Pregnant women', NULL, NULL, NULL, 'Maternal and Neonatal Tetanus', TRUE);
+-- Tetanus toxoid for pregnant women is not a child schedule and is recorded at ANC visits
 
 -- Update the window values for "catch-up" immunizations that can be given later if missed
 UPDATE vaccine_schedules
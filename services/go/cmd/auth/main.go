@@ -12,6 +12,7 @@ import (
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/mamacare/services/internal/app/auth"
 	"github.com/mamacare/services/internal/infra/database"
+	"github.com/mamacare/services/internal/infra/database/migrations"
 	"github.com/mamacare/services/internal/infra/firebase"
 	httpserver "github.com/mamacare/services/internal/infra/http"
 	dbrepository "github.com/mamacare/services/internal/infra/database/repository"
@@ -76,9 +77,10 @@ func main() {
 		// Run migrations
 		migrationManager := database.NewMigrationManager(pool, log)
 		
-		// Add initial migration if needed
-		initialMigration := database.CreateInitialMigration()
-		migrationManager.AddMigration(1, "Initial schema", initialMigration.SQL)
+		// Add the migrations shipped with the service
+		if err := migrationManager.AddMigrationsFS(migrations.Files); err != nil {
+			log.Fatal("Failed to load migrations", err)
+		}
 		
 		// Initialize migration table
 		if err := migrationManager.Initialize(ctx); err != nil {
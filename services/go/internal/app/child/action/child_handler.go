@@ -0,0 +1,389 @@
+package action
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mamacare/services/internal/app/child/registry"
+	"github.com/mamacare/services/internal/domain/model"
+	"github.com/mamacare/services/internal/port/hasura"
+	"github.com/mamacare/services/internal/port/response"
+	"github.com/mamacare/services/internal/port/validation"
+	"github.com/mamacare/services/pkg/errorx"
+	"github.com/mamacare/services/pkg/logger"
+)
+
+// RegisterChildRequest is the request for registering a child
+type RegisterChildRequest struct {
+	FirstName           string `json:"first_name" validate:"required"`
+	MiddleName          string `json:"middle_name,omitempty"`
+	LastName            string `json:"last_name" validate:"required"`
+	Sex                 string `json:"sex,omitempty" validate:"omitempty,oneof=male female"`
+	DateOfBirth         string `json:"date_of_birth" validate:"required,datetime=2006-01-02"`
+	BirthWeightGrams    int    `json:"birth_weight_grams,omitempty" validate:"omitempty,min=300,max=7000"`
+	BirthLengthCm       int    `json:"birth_length_cm,omitempty" validate:"omitempty,min=20,max=70"`
+	PlaceOfBirth        string `json:"place_of_birth,omitempty"`
+	DeliveryType        string `json:"delivery_type,omitempty" validate:"omitempty,oneof=VAGINAL C_SECTION ASSISTED"`
+	GestationalAgeWeeks int    `json:"gestational_age_weeks,omitempty" validate:"omitempty,min=20,max=45"`
+	MotherID            string `json:"mother_id,omitempty" validate:"omitempty,uuid"`
+	FatherID            string `json:"father_id,omitempty" validate:"omitempty,uuid"`
+	FatherName          string `json:"father_name,omitempty"`
+}
+
+// NewbornNamesRequest names a newborn being registered from a delivery outcome
+type NewbornNamesRequest struct {
+	NewbornID  string `json:"newborn_id" validate:"required,uuid"`
+	FirstName  string `json:"first_name,omitempty"`
+	MiddleName string `json:"middle_name,omitempty"`
+	LastName   string `json:"last_name,omitempty"`
+}
+
+// RegisterNewbornsRequest is the request for registering the newborns of a delivery outcome
+type RegisterNewbornsRequest struct {
+	DeliveryOutcomeID string                `json:"delivery_outcome_id" validate:"required,uuid"`
+	Names             []NewbornNamesRequest `json:"names,omitempty" validate:"omitempty,dive"`
+}
+
+// GetChildRequest is the request for getting a child
+type GetChildRequest struct {
+	ChildID string `json:"child_id" validate:"required,uuid"`
+}
+
+// GetChildrenByParentRequest is the request for getting a parent's children
+type GetChildrenByParentRequest struct {
+	ParentID string `json:"parent_id" validate:"required,uuid"`
+}
+
+// LinkChildParentsRequest is the request for linking a child to their parents
+type LinkChildParentsRequest struct {
+	ChildID    string `json:"child_id" validate:"required,uuid"`
+	MotherID   string `json:"mother_id,omitempty" validate:"omitempty,uuid"`
+	FatherID   string `json:"father_id,omitempty" validate:"omitempty,uuid"`
+	FatherName string `json:"father_name,omitempty"`
+}
+
+// DeactivateChildRequest is the request for deactivating a child record
+type DeactivateChildRequest struct {
+	ChildID string `json:"child_id" validate:"required,uuid"`
+	Reason  string `json:"reason" validate:"required"`
+}
+
+// MergeChildrenRequest is the request for merging a duplicate child record
+type MergeChildrenRequest struct {
+	PrimaryID   string `json:"primary_id" validate:"required,uuid"`
+	DuplicateID string `json:"duplicate_id" validate:"required,uuid"`
+}
+
+// ChildHandler handles child registry actions
+type ChildHandler struct {
+	hasura.BaseActionHandler
+	registryService *registry.Service
+	validator       *validation.Validator
+	log             logger.Logger
+}
+
+// NewChildHandler creates a new child handler
+func NewChildHandler(
+	log logger.Logger,
+	registryService *registry.Service,
+	validator *validation.Validator,
+) *ChildHandler {
+	return &ChildHandler{
+		BaseActionHandler: hasura.BaseActionHandler{},
+		registryService:   registryService,
+		validator:         validator,
+		log:               log,
+	}
+}
+
+// RegisterChild registers a child and returns any records that may be the same child
+func (h *ChildHandler) RegisterChild(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	reqID := response.GetRequestID(ctx)
+
+	var req RegisterChildRequest
+	requestedByID, ok := h.parseAndValidate(w, r, reqID, &req)
+	if !ok {
+		return
+	}
+
+	dateOfBirth, err := time.Parse("2006-01-02", req.DateOfBirth)
+	if err != nil {
+		response.WriteErrorResponse(w, reqID, errorx.New(errorx.BadRequest, "Invalid date of birth"))
+		return
+	}
+
+	input := &registry.ChildInput{
+		FirstName:           req.FirstName,
+		MiddleName:          req.MiddleName,
+		LastName:            req.LastName,
+		Sex:                 req.Sex,
+		DateOfBirth:         dateOfBirth,
+		BirthWeightGrams:    req.BirthWeightGrams,
+		BirthLengthCm:       req.BirthLengthCm,
+		PlaceOfBirth:        req.PlaceOfBirth,
+		DeliveryType:        model.ChildDeliveryType(req.DeliveryType),
+		GestationalAgeWeeks: req.GestationalAgeWeeks,
+		MotherID:            optionalID(req.MotherID),
+		FatherID:            optionalID(req.FatherID),
+		FatherName:          req.FatherName,
+	}
+
+	result, err := h.registryService.RegisterChild(ctx, requestedByID, input)
+	if err != nil {
+		h.log.Error("Failed to register child", logger.Fields{
+			"request_id": reqID,
+			"error":      err.Error(),
+		})
+		response.WriteErrorResponse(w, reqID, err)
+		return
+	}
+
+	response.WriteJSONResponse(w, reqID, result)
+}
+
+// RegisterNewborns registers the live newborns of a delivery outcome as children
+func (h *ChildHandler) RegisterNewborns(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	reqID := response.GetRequestID(ctx)
+
+	var req RegisterNewbornsRequest
+	requestedByID, ok := h.parseAndValidate(w, r, reqID, &req)
+	if !ok {
+		return
+	}
+
+	outcomeID, err := uuid.Parse(req.DeliveryOutcomeID)
+	if err != nil {
+		response.WriteErrorResponse(w, reqID, errorx.New(errorx.BadRequest, "Invalid delivery outcome ID"))
+		return
+	}
+
+	names := make([]registry.NewbornNames, 0, len(req.Names))
+	for _, n := range req.Names {
+		newbornID, err := uuid.Parse(n.NewbornID)
+		if err != nil {
+			response.WriteErrorResponse(w, reqID, errorx.New(errorx.BadRequest, "Invalid newborn ID"))
+			return
+		}
+		names = append(names, registry.NewbornNames{
+			NewbornID:  newbornID,
+			FirstName:  n.FirstName,
+			MiddleName: n.MiddleName,
+			LastName:   n.LastName,
+		})
+	}
+
+	result, err := h.registryService.RegisterFromDelivery(ctx, requestedByID, outcomeID, names)
+	if err != nil {
+		h.log.Error("Failed to register newborns", logger.Fields{
+			"request_id": reqID,
+			"outcome_id": outcomeID.String(),
+			"error":      err.Error(),
+		})
+		response.WriteErrorResponse(w, reqID, err)
+		return
+	}
+
+	response.WriteJSONResponse(w, reqID, result)
+}
+
+// GetChild returns a child the requester may access
+func (h *ChildHandler) GetChild(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	reqID := response.GetRequestID(ctx)
+
+	var req GetChildRequest
+	requestedByID, ok := h.parseAndValidate(w, r, reqID, &req)
+	if !ok {
+		return
+	}
+
+	childID, err := uuid.Parse(req.ChildID)
+	if err != nil {
+		response.WriteErrorResponse(w, reqID, errorx.New(errorx.BadRequest, "Invalid child ID"))
+		return
+	}
+
+	child, err := h.registryService.GetChild(ctx, requestedByID, childID)
+	if err != nil {
+		response.WriteErrorResponse(w, reqID, err)
+		return
+	}
+
+	response.WriteJSONResponse(w, reqID, child)
+}
+
+// GetChildrenByParent returns the children of a mother or father
+func (h *ChildHandler) GetChildrenByParent(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	reqID := response.GetRequestID(ctx)
+
+	var req GetChildrenByParentRequest
+	requestedByID, ok := h.parseAndValidate(w, r, reqID, &req)
+	if !ok {
+		return
+	}
+
+	parentID, err := uuid.Parse(req.ParentID)
+	if err != nil {
+		response.WriteErrorResponse(w, reqID, errorx.New(errorx.BadRequest, "Invalid parent ID"))
+		return
+	}
+
+	children, err := h.registryService.GetChildrenByParent(ctx, requestedByID, parentID)
+	if err != nil {
+		response.WriteErrorResponse(w, reqID, err)
+		return
+	}
+
+	response.WriteJSONResponse(w, reqID, children)
+}
+
+// LinkChildParents links a child to their mother and father
+func (h *ChildHandler) LinkChildParents(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	reqID := response.GetRequestID(ctx)
+
+	var req LinkChildParentsRequest
+	requestedByID, ok := h.parseAndValidate(w, r, reqID, &req)
+	if !ok {
+		return
+	}
+
+	childID, err := uuid.Parse(req.ChildID)
+	if err != nil {
+		response.WriteErrorResponse(w, reqID, errorx.New(errorx.BadRequest, "Invalid child ID"))
+		return
+	}
+
+	child, err := h.registryService.LinkParents(ctx, requestedByID, childID, &registry.ParentLinkInput{
+		MotherID:   optionalID(req.MotherID),
+		FatherID:   optionalID(req.FatherID),
+		FatherName: req.FatherName,
+	})
+	if err != nil {
+		h.log.Error("Failed to link child parents", logger.Fields{
+			"request_id": reqID,
+			"child_id":   childID.String(),
+			"error":      err.Error(),
+		})
+		response.WriteErrorResponse(w, reqID, err)
+		return
+	}
+
+	response.WriteJSONResponse(w, reqID, child)
+}
+
+// DeactivateChild marks a child record inactive
+func (h *ChildHandler) DeactivateChild(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	reqID := response.GetRequestID(ctx)
+
+	var req DeactivateChildRequest
+	requestedByID, ok := h.parseAndValidate(w, r, reqID, &req)
+	if !ok {
+		return
+	}
+
+	childID, err := uuid.Parse(req.ChildID)
+	if err != nil {
+		response.WriteErrorResponse(w, reqID, errorx.New(errorx.BadRequest, "Invalid child ID"))
+		return
+	}
+
+	child, err := h.registryService.DeactivateChild(ctx, requestedByID, childID, req.Reason)
+	if err != nil {
+		h.log.Error("Failed to deactivate child", logger.Fields{
+			"request_id": reqID,
+			"child_id":   childID.String(),
+			"error":      err.Error(),
+		})
+		response.WriteErrorResponse(w, reqID, err)
+		return
+	}
+
+	response.WriteJSONResponse(w, reqID, child)
+}
+
+// MergeChildren merges a duplicate child record into the primary record
+func (h *ChildHandler) MergeChildren(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	reqID := response.GetRequestID(ctx)
+
+	var req MergeChildrenRequest
+	requestedByID, ok := h.parseAndValidate(w, r, reqID, &req)
+	if !ok {
+		return
+	}
+
+	primaryID, err := uuid.Parse(req.PrimaryID)
+	if err != nil {
+		response.WriteErrorResponse(w, reqID, errorx.New(errorx.BadRequest, "Invalid primary child ID"))
+		return
+	}
+
+	duplicateID, err := uuid.Parse(req.DuplicateID)
+	if err != nil {
+		response.WriteErrorResponse(w, reqID, errorx.New(errorx.BadRequest, "Invalid duplicate child ID"))
+		return
+	}
+
+	child, err := h.registryService.MergeChildren(ctx, requestedByID, primaryID, duplicateID)
+	if err != nil {
+		h.log.Error("Failed to merge children", logger.Fields{
+			"request_id":   reqID,
+			"primary_id":   primaryID.String(),
+			"duplicate_id": duplicateID.String(),
+			"error":        err.Error(),
+		})
+		response.WriteErrorResponse(w, reqID, err)
+		return
+	}
+
+	response.WriteJSONResponse(w, reqID, child)
+}
+
+// parseAndValidate parses and validates a request and returns the ID of the user making it,
+// taken from the Hasura session. It writes the error response on failure.
+func (h *ChildHandler) parseAndValidate(w http.ResponseWriter, r *http.Request, reqID string, req interface{}) (uuid.UUID, bool) {
+	actionReq, err := h.ParseRequest(r, req)
+	if err != nil {
+		h.log.Error("Failed to parse request", logger.Fields{
+			"request_id": reqID,
+			"error":      err.Error(),
+		})
+		response.WriteErrorResponse(w, reqID, err)
+		return uuid.Nil, false
+	}
+
+	requestedByID, err := actionReq.UserID()
+	if err != nil {
+		response.WriteErrorResponse(w, reqID, err)
+		return uuid.Nil, false
+	}
+
+	if err := h.validator.Validate(req); err != nil {
+		h.log.Error("Invalid request", logger.Fields{
+			"request_id": reqID,
+			"error":      err.Error(),
+		})
+		response.WriteErrorResponse(w, reqID, err)
+		return uuid.Nil, false
+	}
+
+	return requestedByID, true
+}
+
+// optionalID parses an optional ID the validator has already checked is a UUID
+func optionalID(value string) *uuid.UUID {
+	if value == "" {
+		return nil
+	}
+	id, err := uuid.Parse(value)
+	if err != nil {
+		return nil
+	}
+	return &id
+}
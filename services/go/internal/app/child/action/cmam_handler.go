@@ -0,0 +1,318 @@
+package action
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mamacare/services/internal/app/child/cmam"
+	"github.com/mamacare/services/internal/domain/model"
+	"github.com/mamacare/services/internal/port/hasura"
+	"github.com/mamacare/services/internal/port/response"
+	"github.com/mamacare/services/internal/port/validation"
+	"github.com/mamacare/services/pkg/errorx"
+	"github.com/mamacare/services/pkg/logger"
+)
+
+// AdmitToCMAMRequest is the request for admitting a child to acute malnutrition treatment
+type AdmitToCMAMRequest struct {
+	ChildID            string   `json:"child_id" validate:"required,uuid"`
+	FacilityID         string   `json:"facility_id" validate:"required,uuid"`
+	AdmittedAt         string   `json:"admitted_at" validate:"required,datetime=2006-01-02"`
+	WeightGrams        int      `json:"weight_grams" validate:"required,min=500,max=30000"`
+	HeightCm           *float64 `json:"height_cm,omitempty" validate:"omitempty,min=20,max=150"`
+	MUACCm             *float64 `json:"muac_cm,omitempty" validate:"omitempty,min=5,max=30"`
+	OedemaGrade        int      `json:"oedema_grade" validate:"min=0,max=3"`
+	Complications      bool     `json:"complications"`
+	AppetiteTestPassed bool     `json:"appetite_test_passed"`
+	Programme          string   `json:"programme,omitempty" validate:"omitempty,oneof=otp sc"`
+	RUTFSachets        *int     `json:"rutf_sachets,omitempty" validate:"omitempty,min=0"`
+	Notes              string   `json:"notes,omitempty"`
+}
+
+// RecordCMAMFollowUpRequest is the request for recording a weekly CMAM follow-up
+type RecordCMAMFollowUpRequest struct {
+	EnrollmentID string   `json:"enrollment_id" validate:"required,uuid"`
+	VisitedAt    string   `json:"visited_at" validate:"required,datetime=2006-01-02"`
+	WeightGrams  int      `json:"weight_grams" validate:"required,min=500,max=30000"`
+	HeightCm     *float64 `json:"height_cm,omitempty" validate:"omitempty,min=20,max=150"`
+	MUACCm       *float64 `json:"muac_cm,omitempty" validate:"omitempty,min=5,max=30"`
+	OedemaGrade  int      `json:"oedema_grade" validate:"min=0,max=3"`
+	RUTFSachets  *int     `json:"rutf_sachets,omitempty" validate:"omitempty,min=0"`
+	Notes        string   `json:"notes,omitempty"`
+}
+
+// ExitCMAMRequest is the request for ending a child's CMAM treatment or referral
+type ExitCMAMRequest struct {
+	EnrollmentID string `json:"enrollment_id" validate:"required,uuid"`
+	Status       string `json:"status" validate:"required,oneof=cured defaulted died non_responder not_admitted"`
+	ExitedAt     string `json:"exited_at" validate:"required,datetime=2006-01-02"`
+	Notes        string `json:"notes,omitempty"`
+}
+
+// GetChildCMAMRequest is the request for a child's CMAM referrals and treatments
+type GetChildCMAMRequest struct {
+	ChildID string `json:"child_id" validate:"required,uuid"`
+}
+
+// GetCMAMOutcomesRequest is the request for CMAM outcomes per facility
+type GetCMAMOutcomesRequest struct {
+	From       string `json:"from" validate:"required,datetime=2006-01-02"`
+	To         string `json:"to" validate:"required,datetime=2006-01-02"`
+	FacilityID string `json:"facility_id,omitempty" validate:"omitempty,uuid"`
+}
+
+// CMAMHandler handles acute malnutrition treatment actions
+type CMAMHandler struct {
+	hasura.BaseActionHandler
+	cmamService  *cmam.Service
+	defaulterJob *cmam.DefaulterJob
+	validator    *validation.Validator
+	log          logger.Logger
+}
+
+// NewCMAMHandler creates a new CMAM handler
+func NewCMAMHandler(
+	log logger.Logger,
+	cmamService *cmam.Service,
+	defaulterJob *cmam.DefaulterJob,
+	validator *validation.Validator,
+) *CMAMHandler {
+	return &CMAMHandler{
+		BaseActionHandler: hasura.BaseActionHandler{},
+		cmamService:       cmamService,
+		defaulterJob:      defaulterJob,
+		validator:         validator,
+		log:               log,
+	}
+}
+
+// AdmitToCMAM assesses a child and admits them to outpatient or stabilisation centre care
+func (h *CMAMHandler) AdmitToCMAM(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	reqID := response.GetRequestID(ctx)
+
+	var req AdmitToCMAMRequest
+	requestedByID, ok := h.parseAndValidate(w, r, reqID, &req)
+	if !ok {
+		return
+	}
+
+	childID, ok := parseChildID(w, reqID, req.ChildID)
+	if !ok {
+		return
+	}
+
+	facilityID, err := uuid.Parse(req.FacilityID)
+	if err != nil {
+		response.WriteErrorResponse(w, reqID, errorx.New(errorx.BadRequest, "Invalid facility ID"))
+		return
+	}
+
+	admittedAt, err := time.Parse("2006-01-02", req.AdmittedAt)
+	if err != nil {
+		response.WriteErrorResponse(w, reqID, errorx.New(errorx.BadRequest, "Invalid admission date"))
+		return
+	}
+
+	result, err := h.cmamService.Admit(ctx, requestedByID, childID, &cmam.AdmissionInput{
+		FacilityID:         facilityID,
+		AdmittedAt:         admittedAt,
+		WeightGrams:        req.WeightGrams,
+		HeightCm:           req.HeightCm,
+		MUACCm:             req.MUACCm,
+		OedemaGrade:        req.OedemaGrade,
+		Complications:      req.Complications,
+		AppetiteTestPassed: req.AppetiteTestPassed,
+		Programme:          model.CMAMProgramme(req.Programme),
+		RUTFSachets:        req.RUTFSachets,
+		Notes:              req.Notes,
+	})
+	if err != nil {
+		response.WriteErrorResponse(w, reqID, err)
+		return
+	}
+
+	response.WriteJSONResponse(w, reqID, result)
+}
+
+// RecordCMAMFollowUp records a weekly follow-up, dispenses RUTF and discharges the child if they are cured
+func (h *CMAMHandler) RecordCMAMFollowUp(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	reqID := response.GetRequestID(ctx)
+
+	var req RecordCMAMFollowUpRequest
+	requestedByID, ok := h.parseAndValidate(w, r, reqID, &req)
+	if !ok {
+		return
+	}
+
+	enrollmentID, ok := parseEnrollmentID(w, reqID, req.EnrollmentID)
+	if !ok {
+		return
+	}
+
+	visitedAt, err := time.Parse("2006-01-02", req.VisitedAt)
+	if err != nil {
+		response.WriteErrorResponse(w, reqID, errorx.New(errorx.BadRequest, "Invalid visit date"))
+		return
+	}
+
+	result, err := h.cmamService.RecordFollowUp(ctx, requestedByID, enrollmentID, &cmam.FollowUpInput{
+		VisitedAt:   visitedAt,
+		WeightGrams: req.WeightGrams,
+		HeightCm:    req.HeightCm,
+		MUACCm:      req.MUACCm,
+		OedemaGrade: req.OedemaGrade,
+		RUTFSachets: req.RUTFSachets,
+		Notes:       req.Notes,
+	})
+	if err != nil {
+		response.WriteErrorResponse(w, reqID, err)
+		return
+	}
+
+	response.WriteJSONResponse(w, reqID, result)
+}
+
+// ExitCMAM ends a child's treatment with an outcome, or closes a referral as not admitted
+func (h *CMAMHandler) ExitCMAM(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	reqID := response.GetRequestID(ctx)
+
+	var req ExitCMAMRequest
+	requestedByID, ok := h.parseAndValidate(w, r, reqID, &req)
+	if !ok {
+		return
+	}
+
+	enrollmentID, ok := parseEnrollmentID(w, reqID, req.EnrollmentID)
+	if !ok {
+		return
+	}
+
+	exitedAt, err := time.Parse("2006-01-02", req.ExitedAt)
+	if err != nil {
+		response.WriteErrorResponse(w, reqID, errorx.New(errorx.BadRequest, "Invalid exit date"))
+		return
+	}
+
+	enrollment, err := h.cmamService.Exit(ctx, requestedByID, enrollmentID, model.CMAMStatus(req.Status), exitedAt, req.Notes)
+	if err != nil {
+		response.WriteErrorResponse(w, reqID, err)
+		return
+	}
+
+	response.WriteJSONResponse(w, reqID, enrollment)
+}
+
+// GetChildCMAM returns a child's CMAM referrals and treatments with their follow-ups and progress
+func (h *CMAMHandler) GetChildCMAM(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	reqID := response.GetRequestID(ctx)
+
+	var req GetChildCMAMRequest
+	requestedByID, ok := h.parseAndValidate(w, r, reqID, &req)
+	if !ok {
+		return
+	}
+
+	childID, ok := parseChildID(w, reqID, req.ChildID)
+	if !ok {
+		return
+	}
+
+	enrollments, err := h.cmamService.GetChildEnrollments(ctx, requestedByID, childID)
+	if err != nil {
+		response.WriteErrorResponse(w, reqID, err)
+		return
+	}
+
+	response.WriteJSONResponse(w, reqID, enrollments)
+}
+
+// GetCMAMOutcomes reports CMAM outcomes per facility against the Sphere standards
+func (h *CMAMHandler) GetCMAMOutcomes(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	reqID := response.GetRequestID(ctx)
+
+	var req GetCMAMOutcomesRequest
+	requestedByID, ok := h.parseAndValidate(w, r, reqID, &req)
+	if !ok {
+		return
+	}
+
+	from, err := time.Parse("2006-01-02", req.From)
+	if err != nil {
+		response.WriteErrorResponse(w, reqID, errorx.New(errorx.BadRequest, "Invalid start date"))
+		return
+	}
+	to, err := time.Parse("2006-01-02", req.To)
+	if err != nil {
+		response.WriteErrorResponse(w, reqID, errorx.New(errorx.BadRequest, "Invalid end date"))
+		return
+	}
+
+	report, err := h.cmamService.GetOutcomeReport(ctx, requestedByID, from, to, optionalID(req.FacilityID))
+	if err != nil {
+		response.WriteErrorResponse(w, reqID, err)
+		return
+	}
+
+	response.WriteJSONResponse(w, reqID, report)
+}
+
+// CheckCMAMDefaulters runs the missed follow-up job, for use by a scheduled trigger
+func (h *CMAMHandler) CheckCMAMDefaulters(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	reqID := response.GetRequestID(ctx)
+
+	report, err := h.defaulterJob.Run(ctx)
+	if err != nil {
+		response.WriteErrorResponse(w, reqID, err)
+		return
+	}
+
+	response.WriteJSONResponse(w, reqID, report)
+}
+
+// parseAndValidate parses and validates a request and returns the ID of the user making it,
+// taken from the Hasura session. It writes the error response on failure.
+func (h *CMAMHandler) parseAndValidate(w http.ResponseWriter, r *http.Request, reqID string, req interface{}) (uuid.UUID, bool) {
+	actionReq, err := h.ParseRequest(r, req)
+	if err != nil {
+		h.log.Error("Failed to parse request", logger.Fields{
+			"request_id": reqID,
+			"error":      err.Error(),
+		})
+		response.WriteErrorResponse(w, reqID, err)
+		return uuid.Nil, false
+	}
+
+	requestedByID, err := actionReq.UserID()
+	if err != nil {
+		response.WriteErrorResponse(w, reqID, err)
+		return uuid.Nil, false
+	}
+
+	if err := h.validator.Validate(req); err != nil {
+		h.log.Error("Invalid request", logger.Fields{
+			"request_id": reqID,
+			"error":      err.Error(),
+		})
+		response.WriteErrorResponse(w, reqID, err)
+		return uuid.Nil, false
+	}
+
+	return requestedByID, true
+}
+
+// parseEnrollmentID parses a CMAM enrollment ID, writing the error response on failure
+func parseEnrollmentID(w http.ResponseWriter, reqID, enrollment string) (uuid.UUID, bool) {
+	enrollmentID, err := uuid.Parse(enrollment)
+	if err != nil {
+		response.WriteErrorResponse(w, reqID, errorx.New(errorx.BadRequest, "Invalid enrollment ID"))
+		return uuid.Nil, false
+	}
+	return enrollmentID, true
+}
@@ -0,0 +1,154 @@
+package action
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mamacare/services/internal/app/child/growth"
+	"github.com/mamacare/services/internal/port/hasura"
+	"github.com/mamacare/services/internal/port/response"
+	"github.com/mamacare/services/internal/port/validation"
+	"github.com/mamacare/services/pkg/errorx"
+	"github.com/mamacare/services/pkg/logger"
+)
+
+// RecordChildGrowthRequest is the request for recording a child's growth measurement
+type RecordChildGrowthRequest struct {
+	ChildID             string   `json:"child_id" validate:"required,uuid"`
+	MeasuredAt          string   `json:"measured_at" validate:"required,datetime=2006-01-02"`
+	WeightGrams         *int     `json:"weight_grams,omitempty" validate:"omitempty,min=500,max=30000"`
+	HeightCm            *float64 `json:"height_cm,omitempty" validate:"omitempty,min=20,max=150"`
+	MeasuredRecumbent   *bool    `json:"measured_recumbent,omitempty"`
+	HeadCircumferenceCm *float64 `json:"head_circumference_cm,omitempty" validate:"omitempty,min=10,max=60"`
+	MUACCm              *float64 `json:"mid_upper_arm_circumference_cm,omitempty" validate:"omitempty,min=5,max=30"`
+	MeasuredByID        string   `json:"measured_by_user_id,omitempty" validate:"omitempty,uuid"`
+	MeasuredByName      string   `json:"measured_by_name,omitempty"`
+	FacilityID          string   `json:"facility_id,omitempty" validate:"omitempty,uuid"`
+	Notes               string   `json:"notes,omitempty"`
+}
+
+// GetChildGrowthRequest is the request for a child's growth measurements
+type GetChildGrowthRequest struct {
+	ChildID string `json:"child_id" validate:"required,uuid"`
+}
+
+// GrowthHandler handles child growth measurement actions
+type GrowthHandler struct {
+	hasura.BaseActionHandler
+	growthService *growth.Service
+	validator     *validation.Validator
+	log           logger.Logger
+}
+
+// NewGrowthHandler creates a new child growth handler
+func NewGrowthHandler(
+	log logger.Logger,
+	growthService *growth.Service,
+	validator *validation.Validator,
+) *GrowthHandler {
+	return &GrowthHandler{
+		BaseActionHandler: hasura.BaseActionHandler{},
+		growthService:     growthService,
+		validator:         validator,
+		log:               log,
+	}
+}
+
+// RecordChildGrowth records a growth measurement and returns its WHO z-scores and classification
+func (h *GrowthHandler) RecordChildGrowth(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	reqID := response.GetRequestID(ctx)
+
+	var req RecordChildGrowthRequest
+	requestedByID, ok := h.parseAndValidate(w, r, reqID, &req)
+	if !ok {
+		return
+	}
+
+	childID, ok := parseChildID(w, reqID, req.ChildID)
+	if !ok {
+		return
+	}
+
+	measuredAt, err := time.Parse("2006-01-02", req.MeasuredAt)
+	if err != nil {
+		response.WriteErrorResponse(w, reqID, errorx.New(errorx.BadRequest, "Invalid measurement date"))
+		return
+	}
+
+	result, err := h.growthService.RecordMeasurement(ctx, requestedByID, childID, &growth.MeasurementInput{
+		MeasuredAt:          measuredAt,
+		WeightGrams:         req.WeightGrams,
+		HeightCm:            req.HeightCm,
+		MeasuredRecumbent:   req.MeasuredRecumbent,
+		HeadCircumferenceCm: req.HeadCircumferenceCm,
+		MUACCm:              req.MUACCm,
+		MeasuredByID:        optionalID(req.MeasuredByID),
+		MeasuredByName:      req.MeasuredByName,
+		FacilityID:          optionalID(req.FacilityID),
+		Notes:               req.Notes,
+	})
+	if err != nil {
+		response.WriteErrorResponse(w, reqID, err)
+		return
+	}
+
+	response.WriteJSONResponse(w, reqID, result)
+}
+
+// GetChildGrowth returns a child's growth measurements, oldest first
+func (h *GrowthHandler) GetChildGrowth(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	reqID := response.GetRequestID(ctx)
+
+	var req GetChildGrowthRequest
+	requestedByID, ok := h.parseAndValidate(w, r, reqID, &req)
+	if !ok {
+		return
+	}
+
+	childID, ok := parseChildID(w, reqID, req.ChildID)
+	if !ok {
+		return
+	}
+
+	measurements, err := h.growthService.GetMeasurements(ctx, requestedByID, childID)
+	if err != nil {
+		response.WriteErrorResponse(w, reqID, err)
+		return
+	}
+
+	response.WriteJSONResponse(w, reqID, measurements)
+}
+
+// parseAndValidate parses and validates a request and returns the ID of the user making it,
+// taken from the Hasura session. It writes the error response on failure.
+func (h *GrowthHandler) parseAndValidate(w http.ResponseWriter, r *http.Request, reqID string, req interface{}) (uuid.UUID, bool) {
+	actionReq, err := h.ParseRequest(r, req)
+	if err != nil {
+		h.log.Error("Failed to parse request", logger.Fields{
+			"request_id": reqID,
+			"error":      err.Error(),
+		})
+		response.WriteErrorResponse(w, reqID, err)
+		return uuid.Nil, false
+	}
+
+	requestedByID, err := actionReq.UserID()
+	if err != nil {
+		response.WriteErrorResponse(w, reqID, err)
+		return uuid.Nil, false
+	}
+
+	if err := h.validator.Validate(req); err != nil {
+		h.log.Error("Invalid request", logger.Fields{
+			"request_id": reqID,
+			"error":      err.Error(),
+		})
+		response.WriteErrorResponse(w, reqID, err)
+		return uuid.Nil, false
+	}
+
+	return requestedByID, true
+}
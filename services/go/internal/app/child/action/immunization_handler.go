@@ -0,0 +1,212 @@
+package action
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mamacare/services/internal/app/child/immunization"
+	"github.com/mamacare/services/internal/port/hasura"
+	"github.com/mamacare/services/internal/port/response"
+	"github.com/mamacare/services/internal/port/validation"
+	"github.com/mamacare/services/pkg/errorx"
+	"github.com/mamacare/services/pkg/logger"
+)
+
+// ImmunizationStatusRequest is the request for a child's immunization status or catch-up plan
+type ImmunizationStatusRequest struct {
+	ChildID string `json:"child_id" validate:"required,uuid"`
+}
+
+// RecordImmunizationRequest is the request for recording a vaccine dose
+type RecordImmunizationRequest struct {
+	ChildID            string `json:"child_id" validate:"required,uuid"`
+	VaccineName        string `json:"vaccine_name" validate:"required"`
+	Dose               string `json:"vaccine_dose" validate:"required"`
+	AdministeredDate   string `json:"administered_date" validate:"required,datetime=2006-01-02"`
+	AdministeredByID   string `json:"administered_by_user_id,omitempty" validate:"omitempty,uuid"`
+	AdministeredByName string `json:"administered_by_name,omitempty"`
+	FacilityID         string `json:"facility_id" validate:"required,uuid"`
+	BatchNumber        string `json:"batch_number" validate:"required"`
+	Manufacturer       string `json:"manufacturer,omitempty"`
+	AdverseReactions   string `json:"adverse_reactions,omitempty"`
+	Notes              string `json:"notes,omitempty"`
+}
+
+// ImmunizationHandler handles immunization actions
+type ImmunizationHandler struct {
+	hasura.BaseActionHandler
+	immunizationService *immunization.Service
+	reminderJob         *immunization.ReminderJob
+	validator           *validation.Validator
+	log                 logger.Logger
+}
+
+// NewImmunizationHandler creates a new immunization handler
+func NewImmunizationHandler(
+	log logger.Logger,
+	immunizationService *immunization.Service,
+	reminderJob *immunization.ReminderJob,
+	validator *validation.Validator,
+) *ImmunizationHandler {
+	return &ImmunizationHandler{
+		BaseActionHandler:   hasura.BaseActionHandler{},
+		immunizationService: immunizationService,
+		reminderJob:         reminderJob,
+		validator:           validator,
+		log:                 log,
+	}
+}
+
+// GetImmunizationStatus returns a child's given, due, overdue and missing doses
+func (h *ImmunizationHandler) GetImmunizationStatus(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	reqID := response.GetRequestID(ctx)
+
+	var req ImmunizationStatusRequest
+	requestedByID, ok := h.parseAndValidate(w, r, reqID, &req)
+	if !ok {
+		return
+	}
+
+	childID, ok := parseChildID(w, reqID, req.ChildID)
+	if !ok {
+		return
+	}
+
+	status, err := h.immunizationService.GetStatus(ctx, requestedByID, childID)
+	if err != nil {
+		response.WriteErrorResponse(w, reqID, err)
+		return
+	}
+
+	response.WriteJSONResponse(w, reqID, status)
+}
+
+// RecordImmunization records a vaccine dose and returns the child's updated status
+func (h *ImmunizationHandler) RecordImmunization(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	reqID := response.GetRequestID(ctx)
+
+	var req RecordImmunizationRequest
+	requestedByID, ok := h.parseAndValidate(w, r, reqID, &req)
+	if !ok {
+		return
+	}
+
+	childID, ok := parseChildID(w, reqID, req.ChildID)
+	if !ok {
+		return
+	}
+
+	administeredDate, err := time.Parse("2006-01-02", req.AdministeredDate)
+	if err != nil {
+		response.WriteErrorResponse(w, reqID, errorx.New(errorx.BadRequest, "Invalid administered date"))
+		return
+	}
+
+	facilityID, err := uuid.Parse(req.FacilityID)
+	if err != nil {
+		response.WriteErrorResponse(w, reqID, errorx.New(errorx.BadRequest, "Invalid facility ID"))
+		return
+	}
+
+	result, err := h.immunizationService.RecordDose(ctx, requestedByID, childID, &immunization.DoseInput{
+		VaccineName:        req.VaccineName,
+		Dose:               req.Dose,
+		AdministeredDate:   administeredDate,
+		AdministeredByID:   optionalID(req.AdministeredByID),
+		AdministeredByName: req.AdministeredByName,
+		FacilityID:         facilityID,
+		BatchNumber:        req.BatchNumber,
+		Manufacturer:       req.Manufacturer,
+		AdverseReactions:   req.AdverseReactions,
+		Notes:              req.Notes,
+	})
+	if err != nil {
+		response.WriteErrorResponse(w, reqID, err)
+		return
+	}
+
+	response.WriteJSONResponse(w, reqID, result)
+}
+
+// GetCatchUpSchedule returns the visits a late child needs to catch up on their vaccines
+func (h *ImmunizationHandler) GetCatchUpSchedule(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	reqID := response.GetRequestID(ctx)
+
+	var req ImmunizationStatusRequest
+	requestedByID, ok := h.parseAndValidate(w, r, reqID, &req)
+	if !ok {
+		return
+	}
+
+	childID, ok := parseChildID(w, reqID, req.ChildID)
+	if !ok {
+		return
+	}
+
+	plan, err := h.immunizationService.GetCatchUpPlan(ctx, requestedByID, childID)
+	if err != nil {
+		response.WriteErrorResponse(w, reqID, err)
+		return
+	}
+
+	response.WriteJSONResponse(w, reqID, plan)
+}
+
+// SendVaccineReminders runs the vaccine reminder job, for use by a scheduled trigger
+func (h *ImmunizationHandler) SendVaccineReminders(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	reqID := response.GetRequestID(ctx)
+
+	report, err := h.reminderJob.Run(ctx)
+	if err != nil {
+		response.WriteErrorResponse(w, reqID, err)
+		return
+	}
+
+	response.WriteJSONResponse(w, reqID, report)
+}
+
+// parseAndValidate parses and validates a request and returns the ID of the user making it,
+// taken from the Hasura session. It writes the error response on failure.
+func (h *ImmunizationHandler) parseAndValidate(w http.ResponseWriter, r *http.Request, reqID string, req interface{}) (uuid.UUID, bool) {
+	actionReq, err := h.ParseRequest(r, req)
+	if err != nil {
+		h.log.Error("Failed to parse request", logger.Fields{
+			"request_id": reqID,
+			"error":      err.Error(),
+		})
+		response.WriteErrorResponse(w, reqID, err)
+		return uuid.Nil, false
+	}
+
+	requestedByID, err := actionReq.UserID()
+	if err != nil {
+		response.WriteErrorResponse(w, reqID, err)
+		return uuid.Nil, false
+	}
+
+	if err := h.validator.Validate(req); err != nil {
+		h.log.Error("Invalid request", logger.Fields{
+			"request_id": reqID,
+			"error":      err.Error(),
+		})
+		response.WriteErrorResponse(w, reqID, err)
+		return uuid.Nil, false
+	}
+
+	return requestedByID, true
+}
+
+// parseChildID parses a child ID, writing the error response on failure
+func parseChildID(w http.ResponseWriter, reqID, child string) (uuid.UUID, bool) {
+	childID, err := uuid.Parse(child)
+	if err != nil {
+		response.WriteErrorResponse(w, reqID, errorx.New(errorx.BadRequest, "Invalid child ID"))
+		return uuid.Nil, false
+	}
+	return childID, true
+}
@@ -0,0 +1,306 @@
+package action
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mamacare/services/internal/app/child/neonatal"
+	"github.com/mamacare/services/internal/domain/model"
+	"github.com/mamacare/services/internal/port/hasura"
+	"github.com/mamacare/services/internal/port/response"
+	"github.com/mamacare/services/internal/port/validation"
+	"github.com/mamacare/services/pkg/errorx"
+	"github.com/mamacare/services/pkg/logger"
+)
+
+// RecordBirthCareRequest is the request for recording the essential newborn care given at birth
+type RecordBirthCareRequest struct {
+	ChildID                  string     `json:"child_id" validate:"required,uuid"`
+	FacilityID               string     `json:"facility_id" validate:"required,uuid"`
+	Apgar1Min                *int       `json:"apgar_1_min,omitempty" validate:"omitempty,min=0,max=10"`
+	Apgar5Min                *int       `json:"apgar_5_min,omitempty" validate:"omitempty,min=0,max=10"`
+	Apgar10Min               *int       `json:"apgar_10_min,omitempty" validate:"omitempty,min=0,max=10"`
+	BirthWeightGrams         *int       `json:"birth_weight_grams,omitempty" validate:"omitempty,min=300,max=7000"`
+	BreastfeedingInitiatedAt *time.Time `json:"breastfeeding_initiated_at,omitempty"`
+	CordCare                 string     `json:"cord_care,omitempty" validate:"omitempty,oneof=chlorhexidine dry"`
+	KMCStartedAt             *time.Time `json:"kmc_started_at,omitempty"`
+	Notes                    string     `json:"notes,omitempty"`
+}
+
+// RecordKMCSessionRequest is the request for recording a day of kangaroo mother care
+type RecordKMCSessionRequest struct {
+	ChildID                string   `json:"child_id" validate:"required,uuid"`
+	Date                   string   `json:"date" validate:"required,datetime=2006-01-02"`
+	SkinToSkinHours        float64  `json:"skin_to_skin_hours" validate:"min=0,max=24"`
+	TemperatureC           *float64 `json:"temperature_c,omitempty" validate:"omitempty,min=30,max=43"`
+	WeightGrams            *int     `json:"weight_grams,omitempty" validate:"omitempty,min=300,max=7000"`
+	ExclusiveBreastfeeding bool     `json:"exclusive_breastfeeding"`
+	Notes                  string   `json:"notes,omitempty"`
+}
+
+// EndKMCRequest is the request for ending a baby's kangaroo mother care
+type EndKMCRequest struct {
+	ChildID string    `json:"child_id" validate:"required,uuid"`
+	EndedAt time.Time `json:"ended_at" validate:"required"`
+	Reason  string    `json:"reason" validate:"required"`
+}
+
+// RecordNewbornCheckRequest is the request for recording a newborn check for danger signs
+type RecordNewbornCheckRequest struct {
+	ChildID              string     `json:"child_id" validate:"required,uuid"`
+	FacilityID           string     `json:"facility_id" validate:"required,uuid"`
+	VisitID              string     `json:"visit_id,omitempty" validate:"omitempty,uuid"`
+	CheckedAt            *time.Time `json:"checked_at,omitempty"`
+	TemperatureC         *float64   `json:"temperature_c,omitempty" validate:"omitempty,min=30,max=43"`
+	RespiratoryRate      *int       `json:"respiratory_rate,omitempty" validate:"omitempty,min=0,max=150"`
+	WeightGrams          *int       `json:"weight_grams,omitempty" validate:"omitempty,min=300,max=7000"`
+	Feeding              string     `json:"feeding" validate:"required,oneof=good poor not_feeding"`
+	Jaundice             string     `json:"jaundice" validate:"required,oneof=none face trunk palms_soles"`
+	Convulsions          bool       `json:"convulsions"`
+	Lethargic            bool       `json:"lethargic"`
+	SevereChestIndrawing bool       `json:"severe_chest_indrawing"`
+	UmbilicalInfection   bool       `json:"umbilical_infection"`
+	Latitude             *float64   `json:"latitude,omitempty" validate:"omitempty,min=-90,max=90"`
+	Longitude            *float64   `json:"longitude,omitempty" validate:"omitempty,min=-180,max=180"`
+	Notes                string     `json:"notes,omitempty"`
+}
+
+// GetNeonatalCareRequest is the request for a child's newborn care
+type GetNeonatalCareRequest struct {
+	ChildID string `json:"child_id" validate:"required,uuid"`
+}
+
+// NeonatalHandler handles newborn care actions
+type NeonatalHandler struct {
+	hasura.BaseActionHandler
+	neonatalService *neonatal.Service
+	validator       *validation.Validator
+	log             logger.Logger
+}
+
+// NewNeonatalHandler creates a new neonatal handler
+func NewNeonatalHandler(
+	log logger.Logger,
+	neonatalService *neonatal.Service,
+	validator *validation.Validator,
+) *NeonatalHandler {
+	return &NeonatalHandler{
+		BaseActionHandler: hasura.BaseActionHandler{},
+		neonatalService:   neonatalService,
+		validator:         validator,
+		log:               log,
+	}
+}
+
+// RecordBirthCare records APGAR, birth weight, breastfeeding, cord care and KMC for a newborn
+func (h *NeonatalHandler) RecordBirthCare(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	reqID := response.GetRequestID(ctx)
+
+	var req RecordBirthCareRequest
+	requestedByID, ok := h.parseAndValidate(w, r, reqID, &req)
+	if !ok {
+		return
+	}
+
+	childID, ok := parseChildID(w, reqID, req.ChildID)
+	if !ok {
+		return
+	}
+
+	facilityID, err := uuid.Parse(req.FacilityID)
+	if err != nil {
+		response.WriteErrorResponse(w, reqID, errorx.New(errorx.BadRequest, "Invalid facility ID"))
+		return
+	}
+
+	result, err := h.neonatalService.RecordBirthCare(ctx, requestedByID, childID, &neonatal.BirthCareInput{
+		FacilityID:               facilityID,
+		Apgar1Min:                req.Apgar1Min,
+		Apgar5Min:                req.Apgar5Min,
+		Apgar10Min:               req.Apgar10Min,
+		BirthWeightGrams:         req.BirthWeightGrams,
+		BreastfeedingInitiatedAt: req.BreastfeedingInitiatedAt,
+		CordCare:                 model.CordCare(req.CordCare),
+		KMCStartedAt:             req.KMCStartedAt,
+		Notes:                    req.Notes,
+	})
+	if err != nil {
+		response.WriteErrorResponse(w, reqID, err)
+		return
+	}
+
+	response.WriteJSONResponse(w, reqID, result)
+}
+
+// RecordKMCSession records a day of kangaroo mother care and returns the baby's KMC progress
+func (h *NeonatalHandler) RecordKMCSession(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	reqID := response.GetRequestID(ctx)
+
+	var req RecordKMCSessionRequest
+	requestedByID, ok := h.parseAndValidate(w, r, reqID, &req)
+	if !ok {
+		return
+	}
+
+	childID, ok := parseChildID(w, reqID, req.ChildID)
+	if !ok {
+		return
+	}
+
+	date, err := time.Parse("2006-01-02", req.Date)
+	if err != nil {
+		response.WriteErrorResponse(w, reqID, errorx.New(errorx.BadRequest, "Invalid KMC date"))
+		return
+	}
+
+	progress, err := h.neonatalService.RecordKMCSession(ctx, requestedByID, childID, &neonatal.KMCSessionInput{
+		Date:                   date,
+		SkinToSkinHours:        req.SkinToSkinHours,
+		TemperatureC:           req.TemperatureC,
+		WeightGrams:            req.WeightGrams,
+		ExclusiveBreastfeeding: req.ExclusiveBreastfeeding,
+		Notes:                  req.Notes,
+	})
+	if err != nil {
+		response.WriteErrorResponse(w, reqID, err)
+		return
+	}
+
+	response.WriteJSONResponse(w, reqID, progress)
+}
+
+// EndKMC ends a baby's kangaroo mother care
+func (h *NeonatalHandler) EndKMC(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	reqID := response.GetRequestID(ctx)
+
+	var req EndKMCRequest
+	requestedByID, ok := h.parseAndValidate(w, r, reqID, &req)
+	if !ok {
+		return
+	}
+
+	childID, ok := parseChildID(w, reqID, req.ChildID)
+	if !ok {
+		return
+	}
+
+	record, err := h.neonatalService.EndKMC(ctx, requestedByID, childID, req.EndedAt, req.Reason)
+	if err != nil {
+		response.WriteErrorResponse(w, reqID, err)
+		return
+	}
+
+	response.WriteJSONResponse(w, reqID, record)
+}
+
+// RecordNewbornCheck records a newborn check, referring the baby or scheduling a recheck for danger signs
+func (h *NeonatalHandler) RecordNewbornCheck(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	reqID := response.GetRequestID(ctx)
+
+	var req RecordNewbornCheckRequest
+	requestedByID, ok := h.parseAndValidate(w, r, reqID, &req)
+	if !ok {
+		return
+	}
+
+	childID, ok := parseChildID(w, reqID, req.ChildID)
+	if !ok {
+		return
+	}
+
+	facilityID, err := uuid.Parse(req.FacilityID)
+	if err != nil {
+		response.WriteErrorResponse(w, reqID, errorx.New(errorx.BadRequest, "Invalid facility ID"))
+		return
+	}
+
+	checkedAt := time.Now()
+	if req.CheckedAt != nil {
+		checkedAt = *req.CheckedAt
+	}
+
+	result, err := h.neonatalService.RecordCheck(ctx, requestedByID, childID, &neonatal.CheckInput{
+		FacilityID:           facilityID,
+		VisitID:              optionalID(req.VisitID),
+		CheckedAt:            checkedAt,
+		TemperatureC:         req.TemperatureC,
+		RespiratoryRate:      req.RespiratoryRate,
+		WeightGrams:          req.WeightGrams,
+		Feeding:              model.NewbornFeeding(req.Feeding),
+		Jaundice:             model.JaundiceExtent(req.Jaundice),
+		Convulsions:          req.Convulsions,
+		Lethargic:            req.Lethargic,
+		SevereChestIndrawing: req.SevereChestIndrawing,
+		UmbilicalInfection:   req.UmbilicalInfection,
+		Latitude:             req.Latitude,
+		Longitude:            req.Longitude,
+		Notes:                req.Notes,
+	})
+	if err != nil {
+		response.WriteErrorResponse(w, reqID, err)
+		return
+	}
+
+	response.WriteJSONResponse(w, reqID, result)
+}
+
+// GetNeonatalCare returns a child's birth care, kangaroo mother care and newborn checks
+func (h *NeonatalHandler) GetNeonatalCare(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	reqID := response.GetRequestID(ctx)
+
+	var req GetNeonatalCareRequest
+	requestedByID, ok := h.parseAndValidate(w, r, reqID, &req)
+	if !ok {
+		return
+	}
+
+	childID, ok := parseChildID(w, reqID, req.ChildID)
+	if !ok {
+		return
+	}
+
+	summary, err := h.neonatalService.GetSummary(ctx, requestedByID, childID)
+	if err != nil {
+		response.WriteErrorResponse(w, reqID, err)
+		return
+	}
+
+	response.WriteJSONResponse(w, reqID, summary)
+}
+
+// parseAndValidate parses and validates a request and returns the ID of the user making it,
+// taken from the Hasura session. It writes the error response on failure.
+func (h *NeonatalHandler) parseAndValidate(w http.ResponseWriter, r *http.Request, reqID string, req interface{}) (uuid.UUID, bool) {
+	actionReq, err := h.ParseRequest(r, req)
+	if err != nil {
+		h.log.Error("Failed to parse request", logger.Fields{
+			"request_id": reqID,
+			"error":      err.Error(),
+		})
+		response.WriteErrorResponse(w, reqID, err)
+		return uuid.Nil, false
+	}
+
+	requestedByID, err := actionReq.UserID()
+	if err != nil {
+		response.WriteErrorResponse(w, reqID, err)
+		return uuid.Nil, false
+	}
+
+	if err := h.validator.Validate(req); err != nil {
+		h.log.Error("Invalid request", logger.Fields{
+			"request_id": reqID,
+			"error":      err.Error(),
+		})
+		response.WriteErrorResponse(w, reqID, err)
+		return uuid.Nil, false
+	}
+
+	return requestedByID, true
+}
@@ -0,0 +1,133 @@
+package cmam
+
+import (
+	"context"
+	"time"
+
+	"github.com/mamacare/services/internal/domain/model"
+	"github.com/mamacare/services/internal/domain/repository"
+	"github.com/mamacare/services/pkg/errorx"
+	"github.com/mamacare/services/pkg/logger"
+)
+
+const (
+	// missedGraceDays is how long after a follow-up was due it counts as missed
+	missedGraceDays = 3
+	// defaultMissedVisits is how many follow-ups in a row a child can miss before defaulting
+	defaultMissedVisits = 2
+)
+
+// DefaulterReport is the outcome of a missed follow-up run
+type DefaulterReport struct {
+	RunAt             time.Time `json:"run_at"`
+	FollowUpsChecked  int       `json:"follow_ups_checked"`
+	FollowUpsMissed   int       `json:"follow_ups_missed"`
+	ChildrenDefaulted int       `json:"children_defaulted"`
+	Failed            int       `json:"failed"`
+}
+
+// DefaulterJob marks follow-ups missed once their grace period has passed, schedules
+// the next week's follow-up, and discharges children who miss two in a row as defaulted
+type DefaulterJob struct {
+	cmamRepo repository.CMAMRepository
+	log      logger.Logger
+}
+
+// NewDefaulterJob creates a new CMAM defaulter job
+func NewDefaulterJob(cmamRepo repository.CMAMRepository, log logger.Logger) *DefaulterJob {
+	return &DefaulterJob{
+		cmamRepo: cmamRepo,
+		log:      log,
+	}
+}
+
+// Run checks every overdue follow-up. A failure on one enrollment is logged and counted
+// without stopping the run. It is meant to run daily.
+func (j *DefaulterJob) Run(ctx context.Context) (*DefaulterReport, error) {
+	now := time.Now()
+	report := &DefaulterReport{RunAt: now}
+
+	overdue, err := j.cmamRepo.GetOverdueFollowUps(ctx, now.AddDate(0, 0, -missedGraceDays))
+	if err != nil {
+		j.log.Error("Failed to get overdue CMAM follow-ups", logger.Fields{
+			"error": err.Error(),
+		})
+		return nil, errorx.Wrap(err, "failed to get overdue CMAM follow-ups")
+	}
+
+	for _, followUp := range overdue {
+		report.FollowUpsChecked++
+		defaulted, err := j.miss(ctx, followUp, now)
+		if err != nil {
+			j.log.Error("Failed to process missed CMAM follow-up", logger.Fields{
+				"error":         err.Error(),
+				"follow_up_id":  followUp.ID.String(),
+				"enrollment_id": followUp.EnrollmentID.String(),
+			})
+			report.Failed++
+			continue
+		}
+		report.FollowUpsMissed++
+		if defaulted {
+			report.ChildrenDefaulted++
+		}
+	}
+
+	j.log.Info("CMAM defaulter run finished", logger.Fields{
+		"checked":   report.FollowUpsChecked,
+		"missed":    report.FollowUpsMissed,
+		"defaulted": report.ChildrenDefaulted,
+		"failed":    report.Failed,
+	})
+
+	return report, nil
+}
+
+// miss marks a follow-up missed, then either defaults the child or schedules the next week
+func (j *DefaulterJob) miss(ctx context.Context, followUp *model.CMAMFollowUp, now time.Time) (bool, error) {
+	followUp.Status = model.CMAMFollowUpMissed
+	followUp.UpdatedAt = now
+	if err := j.cmamRepo.UpdateFollowUp(ctx, followUp); err != nil {
+		return false, err
+	}
+
+	followUps, err := j.cmamRepo.GetFollowUpsByEnrollment(ctx, followUp.EnrollmentID)
+	if err != nil {
+		return false, err
+	}
+
+	missed := 0
+	var lastWeightGrams *int
+	for _, f := range followUps {
+		switch f.Status {
+		case model.CMAMFollowUpMissed:
+			missed++
+		case model.CMAMFollowUpAttended:
+			missed = 0
+			if f.WeightGrams != nil {
+				lastWeightGrams = f.WeightGrams
+			}
+		}
+	}
+
+	if missed < defaultMissedVisits {
+		next := model.NewCMAMFollowUp(followUp.EnrollmentID, followUp.Week+1, followUp.ScheduledDate.AddDate(0, 0, 7))
+		return false, j.cmamRepo.CreateFollowUp(ctx, next)
+	}
+
+	enrollment, err := j.cmamRepo.GetEnrollmentByID(ctx, followUp.EnrollmentID)
+	if err != nil {
+		return false, err
+	}
+	enrollment.Exit(model.CMAMStatusDefaulted, followUp.ScheduledDate, lastWeightGrams, "missed two follow-ups in a row")
+	if err := j.cmamRepo.UpdateEnrollment(ctx, enrollment); err != nil {
+		return false, err
+	}
+
+	j.log.Warn("Child defaulted from CMAM treatment", logger.Fields{
+		"child_id":      enrollment.ChildID.String(),
+		"enrollment_id": enrollment.ID.String(),
+	})
+
+	return true, nil
+}
@@ -0,0 +1,217 @@
+package cmam
+
+import (
+	"math"
+	"time"
+
+	"github.com/mamacare/services/internal/domain/model"
+)
+
+const (
+	// infantAgeDays is 6 months, under which children are treated as inpatients and MUAC is not used
+	infantAgeDays = 183
+	// maxTreatmentWeeks is how long a child can stay in treatment before being a non-responder
+	maxTreatmentWeeks = 16
+	// dischargeVisits is how many visits in a row a child must meet the discharge criteria
+	dischargeVisits = 2
+	// reviewWeek is when a child not gaining weight is reviewed for referral to the stabilisation centre
+	reviewWeek = 3
+)
+
+// rutfBand is the weekly RUTF ration for children from a minimum weight, giving about
+// 200 kcal/kg/day from 500 kcal sachets
+type rutfBand struct {
+	minGrams int
+	sachets  int
+}
+
+// rutfBands is the WHO/UNICEF OTP ration table, heaviest first
+var rutfBands = []rutfBand{
+	{minGrams: 12000, sachets: 35},
+	{minGrams: 10500, sachets: 32},
+	{minGrams: 9500, sachets: 28},
+	{minGrams: 8500, sachets: 25},
+	{minGrams: 7000, sachets: 21},
+	{minGrams: 5500, sachets: 18},
+	{minGrams: 4000, sachets: 14},
+	{minGrams: 3500, sachets: 11},
+}
+
+// WeeklyRUTFSachets returns the RUTF sachets a child of the given weight needs for a week
+// in outpatient care, or 0 below 3.5 kg, where children are fed in a stabilisation centre
+func WeeklyRUTFSachets(weightGrams int) int {
+	for _, band := range rutfBands {
+		if weightGrams >= band.minGrams {
+			return band.sachets
+		}
+	}
+	return 0
+}
+
+// Criteria returns the severe acute malnutrition admission criteria a child meets. MUAC
+// is only used from 6 months.
+func Criteria(ageDays int, muacCm, whz *float64, oedemaGrade int) []model.CMAMCriterion {
+	criteria := []model.CMAMCriterion{}
+	if muacCm != nil && ageDays >= infantAgeDays && *muacCm < model.CMAMSevereMUACCm {
+		criteria = append(criteria, model.CMAMCriterionMUAC)
+	}
+	if whz != nil && *whz < model.CMAMSevereWHZ {
+		criteria = append(criteria, model.CMAMCriterionWHZ)
+	}
+	if oedemaGrade > 0 {
+		criteria = append(criteria, model.CMAMCriterionOedema)
+	}
+	return criteria
+}
+
+// NeedsStabilisation reports whether a child must be treated as an inpatient: infants
+// under 6 months, children with medical complications or no appetite, and severe oedema
+func NeedsStabilisation(ageDays int, complications, appetiteTestPassed bool, oedemaGrade int) bool {
+	return ageDays < infantAgeDays || complications || !appetiteTestPassed || oedemaGrade >= 3
+}
+
+// TargetWeightGrams returns the discharge target weight, 15% above the admission weight.
+// There is no target for oedematous children, whose admission weight includes fluid.
+func TargetWeightGrams(admissionWeightGrams int, oedemaGrade int) *int {
+	if oedemaGrade > 0 {
+		return nil
+	}
+	target := int(math.Round(float64(admissionWeightGrams) * (1 + model.CMAMTargetWeightGain)))
+	return &target
+}
+
+// Progress is how a child in treatment is doing against the discharge criteria
+type Progress struct {
+	Week int `json:"week"`
+	// WeightGainGrams is the gain since admission
+	WeightGainGrams *int `json:"weight_gain_grams,omitempty"`
+	// WeightGainGKgDay is the average daily gain per kg of admission weight
+	WeightGainGKgDay  *float64 `json:"weight_gain_g_kg_day,omitempty"`
+	TargetWeightGrams *int     `json:"target_weight_grams,omitempty"`
+	// PercentOfTarget is how much of the target gain has been made
+	PercentOfTarget *float64 `json:"percent_of_target,omitempty"`
+	CriteriaMet     bool     `json:"criteria_met"`
+	// ConsecutiveVisitsMet is how many of the latest visits in a row met the discharge criteria
+	ConsecutiveVisitsMet int  `json:"consecutive_visits_met"`
+	DischargeReady       bool `json:"discharge_ready"`
+	// ReferToSC means an outpatient should be referred to the stabilisation centre
+	ReferToSC bool     `json:"refer_to_sc"`
+	Warnings  []string `json:"warnings"`
+}
+
+// Evaluate assesses a child's progress from their attended visits in week order,
+// starting with the admission visit
+func Evaluate(enrollment *model.CMAMEnrollment, visits []*model.CMAMFollowUp) *Progress {
+	progress := &Progress{
+		TargetWeightGrams: enrollment.TargetWeightGrams,
+		Warnings:          []string{},
+	}
+
+	attended := make([]*model.CMAMFollowUp, 0, len(visits))
+	for _, visit := range visits {
+		if visit.Status == model.CMAMFollowUpAttended {
+			attended = append(attended, visit)
+		}
+	}
+	if len(attended) == 0 || enrollment.AdmittedAt == nil {
+		return progress
+	}
+	latest := attended[len(attended)-1]
+	progress.Week = latest.Week
+
+	if enrollment.AdmissionWeightGrams != nil && latest.WeightGrams != nil {
+		admission := *enrollment.AdmissionWeightGrams
+		gain := *latest.WeightGrams - admission
+		progress.WeightGainGrams = &gain
+
+		if visitedAt := visitDate(latest); visitedAt.After(*enrollment.AdmittedAt) {
+			days := visitedAt.Sub(*enrollment.AdmittedAt).Hours() / 24
+			rate := math.Round(float64(gain)/(float64(admission)/1000)/days*10) / 10
+			progress.WeightGainGKgDay = &rate
+		}
+		if target := enrollment.TargetWeightGrams; target != nil && *target > admission {
+			percent := math.Round(float64(gain) / float64(*target-admission) * 100)
+			progress.PercentOfTarget = &percent
+		}
+	}
+
+	// The admission visit never counts towards discharge
+	for i := len(attended) - 1; i >= 0 && attended[i].Week > 0; i-- {
+		if !meetsDischargeCriteria(enrollment, attended[i]) {
+			break
+		}
+		progress.ConsecutiveVisitsMet++
+	}
+	progress.CriteriaMet = progress.ConsecutiveVisitsMet > 0
+	progress.DischargeReady = progress.ConsecutiveVisitsMet >= dischargeVisits
+
+	if !progress.DischargeReady {
+		progress.warn(enrollment, attended)
+	}
+
+	return progress
+}
+
+// warn adds the OTP action protocol warnings for a child who is not responding
+func (p *Progress) warn(enrollment *model.CMAMEnrollment, attended []*model.CMAMFollowUp) {
+	latest := attended[len(attended)-1]
+
+	losses := 0
+	for i := len(attended) - 1; i > 0; i-- {
+		current, previous := attended[i].WeightGrams, attended[i-1].WeightGrams
+		if current == nil || previous == nil || *current >= *previous {
+			break
+		}
+		losses++
+	}
+	if losses >= 2 {
+		p.Warnings = append(p.Warnings, "weight loss at two visits in a row")
+		p.ReferToSC = true
+	}
+
+	if latest.Week >= reviewWeek && enrollment.AdmissionWeightGrams != nil && latest.WeightGrams != nil &&
+		*latest.WeightGrams <= *enrollment.AdmissionWeightGrams && !enrollment.HasCriterion(model.CMAMCriterionOedema) {
+		p.Warnings = append(p.Warnings, "no weight gain since admission")
+		p.ReferToSC = true
+	}
+
+	if latest.OedemaGrade != nil {
+		if *latest.OedemaGrade >= 3 || *latest.OedemaGrade > enrollment.AdmissionOedemaGrade {
+			p.Warnings = append(p.Warnings, "oedema has got worse")
+			p.ReferToSC = true
+		} else if latest.Week >= reviewWeek && *latest.OedemaGrade > 0 && *latest.OedemaGrade == enrollment.AdmissionOedemaGrade {
+			p.Warnings = append(p.Warnings, "oedema has not gone down")
+		}
+	}
+
+	if latest.Week >= maxTreatmentWeeks-2 {
+		p.Warnings = append(p.Warnings, "approaching the end of treatment without meeting the discharge criteria")
+	}
+}
+
+// meetsDischargeCriteria checks a visit against the criteria the child was admitted on:
+// no oedema, MUAC of at least 12.5 cm if admitted on MUAC, and WHZ of at least -2 if
+// admitted on WHZ. Children admitted on oedema alone need either measure to be normal.
+func meetsDischargeCriteria(enrollment *model.CMAMEnrollment, visit *model.CMAMFollowUp) bool {
+	if visit.OedemaGrade == nil || *visit.OedemaGrade > 0 {
+		return false
+	}
+
+	muacMet := visit.MUACCm != nil && *visit.MUACCm >= model.CMAMDischargeMUACCm
+	whzMet := visit.WHZ != nil && *visit.WHZ >= model.CMAMDischargeWHZ
+
+	byMUAC := enrollment.HasCriterion(model.CMAMCriterionMUAC)
+	byWHZ := enrollment.HasCriterion(model.CMAMCriterionWHZ)
+	if !byMUAC && !byWHZ {
+		return muacMet || whzMet
+	}
+	return (!byMUAC || muacMet) && (!byWHZ || whzMet)
+}
+
+// visitDate returns when a visit happened, or when it was due if it has not
+func visitDate(visit *model.CMAMFollowUp) time.Time {
+	if visit.VisitedAt != nil {
+		return *visit.VisitedAt
+	}
+	return visit.ScheduledDate
+}
@@ -0,0 +1,101 @@
+package cmam
+
+import (
+	"context"
+	"math"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mamacare/services/internal/domain/model"
+	"github.com/mamacare/services/pkg/errorx"
+	"github.com/mamacare/services/pkg/logger"
+)
+
+// Sphere minimum standards for the outcomes of severe acute malnutrition treatment
+const (
+	sphereMinCureRate    = 75.0
+	sphereMaxDeathRate   = 10.0
+	sphereMaxDefaultRate = 15.0
+)
+
+// FacilityOutcomes is a facility's CMAM outcomes for a programme, with the rates of each
+// outcome among children who left treatment, in percent
+type FacilityOutcomes struct {
+	*model.CMAMFacilityOutcomes
+	Exits           int      `json:"exits"`
+	CureRate        *float64 `json:"cure_rate,omitempty"`
+	DefaultRate     *float64 `json:"default_rate,omitempty"`
+	DeathRate       *float64 `json:"death_rate,omitempty"`
+	NonResponseRate *float64 `json:"non_response_rate,omitempty"`
+	// MeetsSphere is whether the cure, death and default rates meet the Sphere standards
+	MeetsSphere bool `json:"meets_sphere"`
+}
+
+// OutcomeReport is the CMAM outcomes of each facility over a period
+type OutcomeReport struct {
+	From       time.Time           `json:"from"`
+	To         time.Time           `json:"to"`
+	Facilities []*FacilityOutcomes `json:"facilities"`
+}
+
+// GetOutcomeReport reports admissions and outcomes per facility and programme between
+// two dates, for one facility or all of them if facilityID is nil
+func (s *Service) GetOutcomeReport(ctx context.Context, requesterID uuid.UUID, from, to time.Time, facilityID *uuid.UUID) (*OutcomeReport, error) {
+	if to.Before(from) {
+		return nil, errorx.New(errorx.BadRequest, "report end date must not be before its start date")
+	}
+
+	requester, err := s.requireHealthWorker(ctx, requesterID)
+	if err != nil {
+		return nil, err
+	}
+	if requester.Role == model.RoleCHW {
+		return nil, errorx.New(errorx.Forbidden, "only clinicians and admins can view CMAM outcome reports")
+	}
+
+	outcomes, err := s.cmamRepo.GetFacilityOutcomes(ctx, from, to, facilityID)
+	if err != nil {
+		s.log.Error("Failed to get CMAM outcomes", logger.Fields{
+			"error": err.Error(),
+			"from":  from.Format("2006-01-02"),
+			"to":    to.Format("2006-01-02"),
+		})
+		return nil, errorx.Wrap(err, "failed to get CMAM outcomes")
+	}
+
+	report := &OutcomeReport{
+		From:       from,
+		To:         to,
+		Facilities: make([]*FacilityOutcomes, 0, len(outcomes)),
+	}
+	for _, outcome := range outcomes {
+		report.Facilities = append(report.Facilities, rates(outcome))
+	}
+
+	return report, nil
+}
+
+// rates computes a facility's outcome rates and checks them against the Sphere standards
+func rates(outcome *model.CMAMFacilityOutcomes) *FacilityOutcomes {
+	result := &FacilityOutcomes{
+		CMAMFacilityOutcomes: outcome,
+		Exits:                outcome.Exits(),
+	}
+	if result.Exits == 0 {
+		return result
+	}
+
+	rate := func(count int) *float64 {
+		r := math.Round(float64(count)/float64(result.Exits)*1000) / 10
+		return &r
+	}
+	result.CureRate = rate(outcome.Cured)
+	result.DefaultRate = rate(outcome.Defaulted)
+	result.DeathRate = rate(outcome.Died)
+	result.NonResponseRate = rate(outcome.NonResponder)
+	result.MeetsSphere = *result.CureRate > sphereMinCureRate &&
+		*result.DeathRate < sphereMaxDeathRate &&
+		*result.DefaultRate < sphereMaxDefaultRate
+
+	return result
+}
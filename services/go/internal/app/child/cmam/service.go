@@ -0,0 +1,637 @@
+package cmam
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mamacare/services/internal/app/child/growth"
+	"github.com/mamacare/services/internal/app/child/registry"
+	"github.com/mamacare/services/internal/domain/model"
+	"github.com/mamacare/services/internal/domain/repository"
+	"github.com/mamacare/services/pkg/errorx"
+	"github.com/mamacare/services/pkg/logger"
+)
+
+// maxAgeDays is the oldest a child can be admitted, the end of the WHO growth standards
+const maxAgeDays = 1856
+
+// AdmissionInput contains a child's assessment for CMAM admission
+type AdmissionInput struct {
+	FacilityID    uuid.UUID
+	AdmittedAt    time.Time
+	WeightGrams   int
+	HeightCm      *float64
+	MUACCm        *float64
+	OedemaGrade   int
+	Complications bool
+	// AppetiteTestPassed is whether the child ate the RUTF test feed
+	AppetiteTestPassed bool
+	// Programme, if set, overrides the programme the assessment points to. A child who
+	// needs stabilisation cannot be sent to outpatient care.
+	Programme model.CMAMProgramme
+	// RUTFSachets, if set, overrides the ration by weight for the first week of outpatient care
+	RUTFSachets *int
+	Notes       string
+}
+
+// FollowUpInput contains a weekly follow-up of a child in treatment
+type FollowUpInput struct {
+	VisitedAt   time.Time
+	WeightGrams int
+	HeightCm    *float64
+	MUACCm      *float64
+	OedemaGrade int
+	// RUTFSachets, if set, overrides the ration by weight for the coming week
+	RUTFSachets *int
+	Notes       string
+}
+
+// VisitResult is an enrollment after an admission or follow-up visit
+type VisitResult struct {
+	Enrollment *model.CMAMEnrollment `json:"enrollment"`
+	Visit      *model.CMAMFollowUp   `json:"visit"`
+	Progress   *Progress             `json:"progress"`
+	// NextFollowUp is the next weekly follow-up, unless the child left treatment
+	NextFollowUp *model.CMAMFollowUp `json:"next_follow_up,omitempty"`
+	// Stock is the RUTF left at the facility after the ration was dispensed
+	Stock *model.SupplementStock `json:"rutf_stock,omitempty"`
+}
+
+// EnrollmentDetail is an enrollment with its visits and progress
+type EnrollmentDetail struct {
+	Enrollment *model.CMAMEnrollment `json:"enrollment"`
+	FollowUps  []*model.CMAMFollowUp `json:"follow_ups"`
+	Progress   *Progress             `json:"progress"`
+}
+
+// Service manages children's treatment for severe acute malnutrition, from referral by
+// growth monitoring through weekly follow-ups to discharge
+type Service struct {
+	registryService *registry.Service
+	cmamRepo        repository.CMAMRepository
+	userRepo        repository.UserRepository
+	facilityRepo    repository.FacilityRepository
+	standards       *growth.Standards
+	log             logger.Logger
+}
+
+// NewService creates a new CMAM service with the growth standards loaded by growth.LoadPublishedStandards
+func NewService(
+	registryService *registry.Service,
+	cmamRepo repository.CMAMRepository,
+	userRepo repository.UserRepository,
+	facilityRepo repository.FacilityRepository,
+	standards *growth.Standards,
+	log logger.Logger,
+) *Service {
+	return &Service{
+		registryService: registryService,
+		cmamRepo:        cmamRepo,
+		userRepo:        userRepo,
+		facilityRepo:    facilityRepo,
+		standards:       standards,
+		log:             log,
+	}
+}
+
+// ReferFromMeasurement refers a child whose growth measurement shows acute malnutrition
+// to be assessed for admission. A child already referred or in treatment keeps their
+// open enrollment, which is returned instead.
+func (s *Service) ReferFromMeasurement(ctx context.Context, child *model.Child, measurement *model.GrowthMeasurement) (*model.CMAMEnrollment, error) {
+	open, err := s.cmamRepo.GetOpenEnrollment(ctx, child.ID)
+	if err == nil {
+		return open, nil
+	}
+	if !errorx.IsType(err, errorx.NotFound) {
+		s.log.Error("Failed to get open CMAM enrollment", logger.Fields{
+			"error":    err.Error(),
+			"child_id": child.ID.String(),
+		})
+		return nil, errorx.Wrap(err, "failed to get open CMAM enrollment")
+	}
+
+	referral := model.NewCMAMReferral(child.ID, measurement.MeasuredAt)
+	referral.FacilityID = measurement.FacilityID
+	referral.GrowthMeasurementID = &measurement.ID
+
+	if err := s.cmamRepo.CreateEnrollment(ctx, referral); err != nil {
+		s.log.Error("Failed to create CMAM referral", logger.Fields{
+			"error":    err.Error(),
+			"child_id": child.ID.String(),
+		})
+		return nil, errorx.Wrap(err, "failed to create CMAM referral")
+	}
+
+	s.log.Warn("Child referred for acute malnutrition treatment", logger.Fields{
+		"child_id":       child.ID.String(),
+		"measurement_id": measurement.ID.String(),
+		"growth_status":  string(measurement.GrowthStatus),
+	})
+
+	return referral, nil
+}
+
+// Admit assesses a child against the severe acute malnutrition criteria and admits them
+// to outpatient care, or to the stabilisation centre if they are under 6 months, have
+// complications, fail the appetite test or have severe oedema. The admission visit is
+// recorded as week 0, outpatients get their first week of RUTF and the first weekly
+// follow-up is scheduled.
+func (s *Service) Admit(ctx context.Context, requesterID, childID uuid.UUID, input *AdmissionInput) (*VisitResult, error) {
+	if err := validateVisit(input.AdmittedAt, input.WeightGrams, input.OedemaGrade, input.RUTFSachets); err != nil {
+		return nil, err
+	}
+	if input.Programme != "" && !input.Programme.IsValid() {
+		return nil, errorx.Newf(errorx.BadRequest, "unknown CMAM programme: %s", input.Programme)
+	}
+
+	requester, err := s.requireHealthWorker(ctx, requesterID)
+	if err != nil {
+		return nil, err
+	}
+
+	child, err := s.registryService.GetChild(ctx, requesterID, childID)
+	if err != nil {
+		return nil, err
+	}
+	if !child.IsActive {
+		return nil, errorx.New(errorx.BadRequest, "child record is inactive")
+	}
+	if input.AdmittedAt.Before(child.DateOfBirth) {
+		return nil, errorx.New(errorx.BadRequest, "admission date cannot be before the child's date of birth")
+	}
+	ageDays := child.AgeInDays(input.AdmittedAt)
+	if ageDays > maxAgeDays {
+		return nil, errorx.New(errorx.BadRequest, "CMAM treats children under five years")
+	}
+
+	if _, err := s.facilityRepo.GetByID(ctx, input.FacilityID); err != nil {
+		s.log.Error("Failed to find facility", logger.Fields{
+			"error":       err.Error(),
+			"facility_id": input.FacilityID.String(),
+		})
+		return nil, errorx.Wrap(err, "failed to find facility")
+	}
+
+	whz, err := s.weightForHeightZ(child, input.AdmittedAt, input.WeightGrams, input.HeightCm)
+	if err != nil {
+		return nil, err
+	}
+	criteria := Criteria(ageDays, input.MUACCm, whz, input.OedemaGrade)
+	if len(criteria) == 0 {
+		return nil, errorx.New(errorx.BadRequest, "child does not meet the severe acute malnutrition admission criteria")
+	}
+
+	programme := model.CMAMProgrammeOTP
+	if NeedsStabilisation(ageDays, input.Complications, input.AppetiteTestPassed, input.OedemaGrade) {
+		programme = model.CMAMProgrammeSC
+	}
+	if input.Programme != "" {
+		if programme == model.CMAMProgrammeSC && input.Programme == model.CMAMProgrammeOTP {
+			return nil, errorx.New(errorx.BadRequest, "child needs stabilisation and cannot be treated as an outpatient")
+		}
+		programme = input.Programme
+	}
+
+	enrollment, err := s.cmamRepo.GetOpenEnrollment(ctx, child.ID)
+	create := false
+	switch {
+	case err == nil && enrollment.Status == model.CMAMStatusActive:
+		return nil, errorx.New(errorx.AlreadyExists, "child is already in CMAM treatment")
+	case err != nil && errorx.IsType(err, errorx.NotFound):
+		enrollment = model.NewCMAMReferral(child.ID, input.AdmittedAt)
+		create = true
+	case err != nil:
+		s.log.Error("Failed to get open CMAM enrollment", logger.Fields{
+			"error":    err.Error(),
+			"child_id": child.ID.String(),
+		})
+		return nil, errorx.Wrap(err, "failed to get open CMAM enrollment")
+	}
+
+	enrollment.Status = model.CMAMStatusActive
+	enrollment.FacilityID = &input.FacilityID
+	enrollment.Programme = programme
+	enrollment.AdmittedAt = &input.AdmittedAt
+	enrollment.AdmissionCriteria = criteria
+	enrollment.AdmissionWeightGrams = &input.WeightGrams
+	enrollment.AdmissionHeightCm = input.HeightCm
+	enrollment.AdmissionMUACCm = input.MUACCm
+	enrollment.AdmissionWHZ = whz
+	enrollment.AdmissionOedemaGrade = input.OedemaGrade
+	enrollment.Complications = input.Complications
+	enrollment.TargetWeightGrams = TargetWeightGrams(input.WeightGrams, input.OedemaGrade)
+	enrollment.AdmittedByID = &requester.ID
+	enrollment.UpdatedAt = time.Now()
+
+	if create {
+		err = s.cmamRepo.CreateEnrollment(ctx, enrollment)
+	} else {
+		err = s.cmamRepo.UpdateEnrollment(ctx, enrollment)
+	}
+	if err != nil {
+		s.log.Error("Failed to save CMAM enrollment", logger.Fields{
+			"error":    err.Error(),
+			"child_id": child.ID.String(),
+		})
+		return nil, errorx.Wrap(err, "failed to admit child to CMAM")
+	}
+
+	visit := model.NewCMAMFollowUp(enrollment.ID, 0, input.AdmittedAt)
+	if err := s.cmamRepo.CreateFollowUp(ctx, visit); err != nil {
+		s.log.Error("Failed to create CMAM admission visit", logger.Fields{
+			"error":         err.Error(),
+			"enrollment_id": enrollment.ID.String(),
+		})
+		return nil, errorx.Wrap(err, "failed to record admission visit")
+	}
+	attend(visit, requester.ID, input.AdmittedAt, input.WeightGrams, input.HeightCm, input.MUACCm, whz, input.OedemaGrade, input.Notes)
+	if programme == model.CMAMProgrammeOTP {
+		visit.RUTFSachets = ration(input.WeightGrams, input.RUTFSachets)
+	}
+
+	stock, err := s.saveVisit(ctx, visit, input.FacilityID)
+	if err != nil {
+		return nil, err
+	}
+
+	next, err := s.scheduleFollowUp(ctx, enrollment.ID, 1, input.AdmittedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	s.log.Info("Child admitted to CMAM", logger.Fields{
+		"child_id":     child.ID.String(),
+		"facility_id":  input.FacilityID.String(),
+		"programme":    string(programme),
+		"rutf_sachets": visit.RUTFSachets,
+	})
+
+	return &VisitResult{
+		Enrollment:   enrollment,
+		Visit:        visit,
+		Progress:     Evaluate(enrollment, []*model.CMAMFollowUp{visit}),
+		NextFollowUp: next,
+		Stock:        stock,
+	}, nil
+}
+
+// RecordFollowUp records a child's weekly follow-up and dispenses the next week's RUTF.
+// A child who has met the discharge criteria at two visits in a row is discharged as
+// cured, and one still in treatment after 16 weeks as a non-responder; otherwise the
+// next follow-up is scheduled a week later.
+func (s *Service) RecordFollowUp(ctx context.Context, requesterID, enrollmentID uuid.UUID, input *FollowUpInput) (*VisitResult, error) {
+	if err := validateVisit(input.VisitedAt, input.WeightGrams, input.OedemaGrade, input.RUTFSachets); err != nil {
+		return nil, err
+	}
+
+	requester, err := s.requireHealthWorker(ctx, requesterID)
+	if err != nil {
+		return nil, err
+	}
+
+	enrollment, child, err := s.loadEnrollment(ctx, requesterID, enrollmentID)
+	if err != nil {
+		return nil, err
+	}
+	if enrollment.Status != model.CMAMStatusActive {
+		return nil, errorx.Newf(errorx.BadRequest, "child is not in CMAM treatment (status %s)", enrollment.Status)
+	}
+	if input.VisitedAt.Before(*enrollment.AdmittedAt) {
+		return nil, errorx.New(errorx.BadRequest, "follow-up cannot be before admission")
+	}
+
+	followUps, err := s.getFollowUps(ctx, enrollment.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	// The visit fulfils the earliest follow-up still scheduled, or a new one if the
+	// child comes back after missing theirs
+	var visit *model.CMAMFollowUp
+	heightCm := enrollment.AdmissionHeightCm
+	lastWeek := 0
+	for _, followUp := range followUps {
+		if followUp.HeightCm != nil {
+			heightCm = followUp.HeightCm
+		}
+		if visit == nil && followUp.Status == model.CMAMFollowUpScheduled {
+			visit = followUp
+		}
+		lastWeek = followUp.Week
+	}
+	if visit == nil {
+		if visit, err = s.scheduleFollowUp(ctx, enrollment.ID, lastWeek+1, input.VisitedAt.AddDate(0, 0, -7)); err != nil {
+			return nil, err
+		}
+		followUps = append(followUps, visit)
+	}
+	if input.HeightCm != nil {
+		heightCm = input.HeightCm
+	}
+
+	whz, err := s.weightForHeightZ(child, input.VisitedAt, input.WeightGrams, heightCm)
+	if err != nil {
+		return nil, err
+	}
+	attend(visit, requester.ID, input.VisitedAt, input.WeightGrams, input.HeightCm, input.MUACCm, whz, input.OedemaGrade, input.Notes)
+
+	progress := Evaluate(enrollment, followUps)
+	var outcome model.CMAMStatus
+	switch {
+	case progress.DischargeReady:
+		outcome = model.CMAMStatusCured
+	case visit.Week >= maxTreatmentWeeks:
+		outcome = model.CMAMStatusNonResponder
+	case enrollment.Programme == model.CMAMProgrammeOTP:
+		visit.RUTFSachets = ration(input.WeightGrams, input.RUTFSachets)
+	}
+
+	stock, err := s.saveVisit(ctx, visit, *enrollment.FacilityID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &VisitResult{
+		Enrollment: enrollment,
+		Visit:      visit,
+		Progress:   progress,
+		Stock:      stock,
+	}
+
+	if outcome != "" {
+		enrollment.Exit(outcome, input.VisitedAt, &input.WeightGrams, input.Notes)
+		if err := s.cmamRepo.UpdateEnrollment(ctx, enrollment); err != nil {
+			s.log.Error("Failed to discharge child from CMAM", logger.Fields{
+				"error":         err.Error(),
+				"enrollment_id": enrollment.ID.String(),
+				"outcome":       string(outcome),
+			})
+			return nil, errorx.Wrap(err, "failed to discharge child from CMAM")
+		}
+		s.log.Info("Child discharged from CMAM", logger.Fields{
+			"child_id":      child.ID.String(),
+			"enrollment_id": enrollment.ID.String(),
+			"outcome":       string(outcome),
+			"weeks":         visit.Week,
+		})
+		return result, nil
+	}
+
+	if result.NextFollowUp, err = s.scheduleFollowUp(ctx, enrollment.ID, visit.Week+1, input.VisitedAt); err != nil {
+		return nil, err
+	}
+
+	if progress.ReferToSC && enrollment.Programme == model.CMAMProgrammeOTP {
+		s.log.Warn("CMAM outpatient not responding to treatment", logger.Fields{
+			"child_id":      child.ID.String(),
+			"enrollment_id": enrollment.ID.String(),
+			"week":          visit.Week,
+		})
+	}
+
+	return result, nil
+}
+
+// Exit ends a child's treatment with an outcome recorded by a health worker, such as
+// a death or a child who stopped coming, or closes a referral as not admitted
+func (s *Service) Exit(ctx context.Context, requesterID, enrollmentID uuid.UUID, status model.CMAMStatus, exitedAt time.Time, notes string) (*model.CMAMEnrollment, error) {
+	if exitedAt.IsZero() || exitedAt.After(time.Now()) {
+		return nil, errorx.New(errorx.BadRequest, "exit date must be in the past")
+	}
+
+	if _, err := s.requireHealthWorker(ctx, requesterID); err != nil {
+		return nil, err
+	}
+
+	enrollment, _, err := s.loadEnrollment(ctx, requesterID, enrollmentID)
+	if err != nil {
+		return nil, err
+	}
+
+	switch enrollment.Status {
+	case model.CMAMStatusReferred:
+		if status != model.CMAMStatusNotAdmitted {
+			return nil, errorx.New(errorx.BadRequest, "a referral can only be closed as not admitted")
+		}
+	case model.CMAMStatusActive:
+		if !status.IsOutcome() {
+			return nil, errorx.Newf(errorx.BadRequest, "invalid CMAM outcome: %s", status)
+		}
+		if exitedAt.Before(*enrollment.AdmittedAt) {
+			return nil, errorx.New(errorx.BadRequest, "exit date cannot be before admission")
+		}
+	default:
+		return nil, errorx.Newf(errorx.BadRequest, "CMAM enrollment has already ended (status %s)", enrollment.Status)
+	}
+
+	var weightGrams *int
+	if status.IsOutcome() {
+		followUps, err := s.getFollowUps(ctx, enrollment.ID)
+		if err != nil {
+			return nil, err
+		}
+		for _, followUp := range followUps {
+			if followUp.Status == model.CMAMFollowUpAttended && followUp.WeightGrams != nil {
+				weightGrams = followUp.WeightGrams
+			}
+		}
+	}
+
+	enrollment.Exit(status, exitedAt, weightGrams, notes)
+	if err := s.cmamRepo.UpdateEnrollment(ctx, enrollment); err != nil {
+		s.log.Error("Failed to exit CMAM enrollment", logger.Fields{
+			"error":         err.Error(),
+			"enrollment_id": enrollment.ID.String(),
+			"status":        string(status),
+		})
+		return nil, errorx.Wrap(err, "failed to exit CMAM enrollment")
+	}
+
+	s.log.Info("CMAM enrollment ended", logger.Fields{
+		"child_id":      enrollment.ChildID.String(),
+		"enrollment_id": enrollment.ID.String(),
+		"status":        string(status),
+	})
+
+	return enrollment, nil
+}
+
+// GetChildEnrollments returns a child's referrals and treatments, newest first, with their visits and progress
+func (s *Service) GetChildEnrollments(ctx context.Context, requesterID, childID uuid.UUID) ([]*EnrollmentDetail, error) {
+	child, err := s.registryService.GetChild(ctx, requesterID, childID)
+	if err != nil {
+		return nil, err
+	}
+
+	enrollments, err := s.cmamRepo.GetEnrollmentsByChildID(ctx, child.ID)
+	if err != nil {
+		s.log.Error("Failed to get CMAM enrollments", logger.Fields{
+			"error":    err.Error(),
+			"child_id": child.ID.String(),
+		})
+		return nil, errorx.Wrap(err, "failed to get CMAM enrollments")
+	}
+
+	details := make([]*EnrollmentDetail, 0, len(enrollments))
+	for _, enrollment := range enrollments {
+		followUps, err := s.getFollowUps(ctx, enrollment.ID)
+		if err != nil {
+			return nil, err
+		}
+		details = append(details, &EnrollmentDetail{
+			Enrollment: enrollment,
+			FollowUps:  followUps,
+			Progress:   Evaluate(enrollment, followUps),
+		})
+	}
+
+	return details, nil
+}
+
+// requireHealthWorker checks that the requester is a CHW, clinician or admin
+func (s *Service) requireHealthWorker(ctx context.Context, requesterID uuid.UUID) (*model.User, error) {
+	requester, err := s.userRepo.GetByID(ctx, requesterID)
+	if err != nil {
+		s.log.Error("Failed to find requester", logger.Fields{
+			"error":   err.Error(),
+			"user_id": requesterID.String(),
+		})
+		return nil, errorx.Wrap(err, "failed to find requester")
+	}
+	if requester.Role != model.RoleCHW && requester.Role != model.RoleClinician && requester.Role != model.RoleAdmin {
+		return nil, errorx.New(errorx.Forbidden, "only health workers can manage malnutrition treatment")
+	}
+	return requester, nil
+}
+
+// loadEnrollment gets an enrollment and its child, checking the requester can see the child
+func (s *Service) loadEnrollment(ctx context.Context, requesterID, enrollmentID uuid.UUID) (*model.CMAMEnrollment, *model.Child, error) {
+	enrollment, err := s.cmamRepo.GetEnrollmentByID(ctx, enrollmentID)
+	if err != nil {
+		s.log.Error("Failed to find CMAM enrollment", logger.Fields{
+			"error":         err.Error(),
+			"enrollment_id": enrollmentID.String(),
+		})
+		return nil, nil, errorx.Wrap(err, "failed to find CMAM enrollment")
+	}
+
+	child, err := s.registryService.GetChild(ctx, requesterID, enrollment.ChildID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return enrollment, child, nil
+}
+
+// getFollowUps gets an enrollment's follow-ups in week order
+func (s *Service) getFollowUps(ctx context.Context, enrollmentID uuid.UUID) ([]*model.CMAMFollowUp, error) {
+	followUps, err := s.cmamRepo.GetFollowUpsByEnrollment(ctx, enrollmentID)
+	if err != nil {
+		s.log.Error("Failed to get CMAM follow-ups", logger.Fields{
+			"error":         err.Error(),
+			"enrollment_id": enrollmentID.String(),
+		})
+		return nil, errorx.Wrap(err, "failed to get CMAM follow-ups")
+	}
+	return followUps, nil
+}
+
+// scheduleFollowUp schedules the given week's follow-up a week after the previous visit
+func (s *Service) scheduleFollowUp(ctx context.Context, enrollmentID uuid.UUID, week int, previous time.Time) (*model.CMAMFollowUp, error) {
+	followUp := model.NewCMAMFollowUp(enrollmentID, week, previous.AddDate(0, 0, 7))
+	if err := s.cmamRepo.CreateFollowUp(ctx, followUp); err != nil {
+		s.log.Error("Failed to schedule CMAM follow-up", logger.Fields{
+			"error":         err.Error(),
+			"enrollment_id": enrollmentID.String(),
+			"week":          week,
+		})
+		return nil, errorx.Wrap(err, "failed to schedule CMAM follow-up")
+	}
+	return followUp, nil
+}
+
+// saveVisit stores an attended visit, taking any RUTF dispensed off the facility's stock
+func (s *Service) saveVisit(ctx context.Context, visit *model.CMAMFollowUp, facilityID uuid.UUID) (*model.SupplementStock, error) {
+	if visit.RUTFSachets == 0 {
+		if err := s.cmamRepo.UpdateFollowUp(ctx, visit); err != nil {
+			s.log.Error("Failed to record CMAM visit", logger.Fields{
+				"error":        err.Error(),
+				"follow_up_id": visit.ID.String(),
+			})
+			return nil, errorx.Wrap(err, "failed to record CMAM visit")
+		}
+		return nil, nil
+	}
+
+	stock, err := s.cmamRepo.RecordFollowUp(ctx, visit, facilityID)
+	if err != nil {
+		s.log.Error("Failed to record CMAM visit and dispense RUTF", logger.Fields{
+			"error":        err.Error(),
+			"follow_up_id": visit.ID.String(),
+			"facility_id":  facilityID.String(),
+			"rutf_sachets": visit.RUTFSachets,
+		})
+		return nil, errorx.Wrap(err, "failed to record CMAM visit")
+	}
+	return stock, nil
+}
+
+// weightForHeightZ scores weight against length or height, or returns nil if the child
+// was not measured, their length is outside the standards or the z-score is implausible
+// or from an interim table. Admission and discharge then rest on MUAC and oedema alone.
+func (s *Service) weightForHeightZ(child *model.Child, date time.Time, weightGrams int, heightCm *float64) (*float64, error) {
+	if heightCm == nil {
+		return nil, nil
+	}
+	weightKg := float64(weightGrams) / 1000
+	assessment, err := s.standards.Assess(growth.Measurement{
+		Sex:      growth.Sex(child.Sex),
+		AgeDays:  child.AgeInDays(date),
+		WeightKg: &weightKg,
+		LengthCm: heightCm,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return assessment.UsableWeightForLength(), nil
+}
+
+// attend fills in a visit's measurements and marks it attended
+func attend(visit *model.CMAMFollowUp, recordedByID uuid.UUID, visitedAt time.Time, weightGrams int, heightCm, muacCm, whz *float64, oedemaGrade int, notes string) {
+	visit.Status = model.CMAMFollowUpAttended
+	visit.VisitedAt = &visitedAt
+	visit.WeightGrams = &weightGrams
+	visit.HeightCm = heightCm
+	visit.MUACCm = muacCm
+	visit.WHZ = whz
+	visit.OedemaGrade = &oedemaGrade
+	visit.RecordedByID = &recordedByID
+	visit.Notes = notes
+	visit.UpdatedAt = time.Now()
+}
+
+// ration returns the sachets to dispense, the override if given or the ration by weight
+func ration(weightGrams int, override *int) int {
+	if override != nil {
+		return *override
+	}
+	return WeeklyRUTFSachets(weightGrams)
+}
+
+// validateVisit validates the measurements taken at an admission or follow-up visit
+func validateVisit(visitedAt time.Time, weightGrams, oedemaGrade int, rutfSachets *int) error {
+	if visitedAt.IsZero() || visitedAt.After(time.Now()) {
+		return errorx.New(errorx.BadRequest, "visit date must be in the past")
+	}
+	if weightGrams < 500 || weightGrams > 30000 {
+		return errorx.New(errorx.BadRequest, "weight must be between 500 and 30000 grams")
+	}
+	if oedemaGrade < 0 || oedemaGrade > 3 {
+		return errorx.New(errorx.BadRequest, "oedema grade must be between 0 and 3")
+	}
+	if rutfSachets != nil && *rutfSachets < 0 {
+		return errorx.New(errorx.BadRequest, "RUTF sachets cannot be negative")
+	}
+	return nil
+}
@@ -0,0 +1,247 @@
+package growth
+
+import (
+	"math"
+
+	"github.com/mamacare/services/internal/domain/model"
+	"github.com/mamacare/services/pkg/errorx"
+)
+
+// WHO cut-offs and limits used in classification
+const (
+	// maxAgeDays is the end of the standards, 60 months
+	maxAgeDays = 1856
+	// lengthHeightSwitchDays is 24 months, before which length is measured lying down
+	lengthHeightSwitchDays = 731
+	// recumbentDifferenceCm is how much longer a child measures lying down than standing
+	recumbentDifferenceCm = 0.7
+	// moderateZ and severeZ are the cut-offs below which a child is moderately or severely malnourished
+	moderateZ = -2.0
+	severeZ   = -3.0
+	// overweightZ is the weight-for-height z-score above which a child is overweight
+	overweightZ = 2.0
+)
+
+// Measurement is a child's anthropometry to assess against the standards
+type Measurement struct {
+	Sex      Sex
+	AgeDays  int
+	WeightKg *float64
+	// LengthCm is length or height as measured; Recumbent says how, and defaults to
+	// lying down under 24 months and standing from then on
+	LengthCm            *float64
+	Recumbent           *bool
+	HeadCircumferenceCm *float64
+	MUACCm              *float64
+}
+
+// ZScores are the WHO z-scores of a measurement
+type ZScores struct {
+	WeightForAge            *float64 `json:"weight_for_age,omitempty"`
+	LengthForAge            *float64 `json:"length_for_age,omitempty"`
+	WeightForLength         *float64 `json:"weight_for_length,omitempty"`
+	HeadCircumferenceForAge *float64 `json:"head_circumference_for_age,omitempty"`
+	MUACForAge              *float64 `json:"muac_for_age,omitempty"`
+}
+
+// Finding is a malnutrition or overweight finding from one z-score
+type Finding struct {
+	Status    model.GrowthStatus `json:"status"`
+	Indicator Indicator          `json:"indicator"`
+	ZScore    float64            `json:"z_score"`
+	Severe    bool               `json:"severe"`
+}
+
+// Assessment is a measurement's z-scores and what they show
+type Assessment struct {
+	AgeDays int `json:"age_days"`
+	// AdjustedLengthCm is the length or height after correcting for how it was measured
+	AdjustedLengthCm *float64  `json:"adjusted_length_cm,omitempty"`
+	ZScores          ZScores   `json:"z_scores"`
+	Findings         []Finding `json:"findings"`
+	// Status is the most serious finding, or NORMAL
+	Status model.GrowthStatus `json:"status"`
+	// Implausible lists the z-scores beyond the WHO plausibility limits. They are left
+	// out of the findings and the measurement should be taken again.
+	Implausible []Indicator `json:"implausible"`
+	// Interim lists the z-scores scored from interim tables, which are reported but
+	// left out of the findings
+	Interim []Indicator `json:"interim"`
+}
+
+// excluded reports whether an indicator's z-score is left out of the findings
+func (a *Assessment) excluded(indicator Indicator) bool {
+	for _, i := range a.Implausible {
+		if i == indicator {
+			return true
+		}
+	}
+	for _, i := range a.Interim {
+		if i == indicator {
+			return true
+		}
+	}
+	return false
+}
+
+// UsableWeightForLength returns the weight-for-length or weight-for-height z-score, or
+// nil if it was not scored or is left out of the findings as implausible or interim
+func (a *Assessment) UsableWeightForLength() *float64 {
+	if a.excluded(IndicatorWeightForLength) || a.excluded(IndicatorWeightForHeight) {
+		return nil
+	}
+	return a.ZScores.WeightForLength
+}
+
+// statusRank orders growth statuses by how urgently they need action
+func statusRank(status model.GrowthStatus) int {
+	switch status {
+	case model.GrowthStatusWasted:
+		return 4
+	case model.GrowthStatusStunted:
+		return 3
+	case model.GrowthStatusUnderweight:
+		return 2
+	case model.GrowthStatusOverweight:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// plausible reports whether a z-score is within the WHO flagging limits for the indicator
+func plausible(indicator Indicator, z float64) bool {
+	switch indicator {
+	case IndicatorWeightForAge:
+		return z >= -6 && z <= 5
+	case IndicatorLengthForAge, IndicatorHeightForAge:
+		return z >= -6 && z <= 6
+	case IndicatorWeightForLength, IndicatorWeightForHeight:
+		return z >= -5 && z <= 5
+	default:
+		return z >= -5 && z <= 5
+	}
+}
+
+// Assess computes the z-scores of a measurement and classifies the child's growth.
+// Length is used before 24 months and height after, adjusting by 0.7 cm when the child
+// was measured the other way, and weight is compared with length or height accordingly.
+func (s *Standards) Assess(m Measurement) (*Assessment, error) {
+	if m.Sex != SexMale && m.Sex != SexFemale {
+		return nil, errorx.New(errorx.BadRequest, "the child's sex is needed to compare growth with WHO standards")
+	}
+	if m.AgeDays < 0 || m.AgeDays > maxAgeDays {
+		return nil, errorx.New(errorx.BadRequest, "WHO growth standards cover children from birth to five years")
+	}
+
+	assessment := &Assessment{
+		AgeDays:     m.AgeDays,
+		Findings:    []Finding{},
+		Status:      model.GrowthStatusNormal,
+		Implausible: []Indicator{},
+		Interim:     []Indicator{},
+	}
+	age := float64(m.AgeDays)
+	infant := m.AgeDays < lengthHeightSwitchDays
+
+	score := func(indicator Indicator, x, y float64) (*float64, error) {
+		z, err := s.ZScore(indicator, m.Sex, x, y)
+		if err != nil {
+			return nil, err
+		}
+		if !plausible(indicator, z) {
+			assessment.Implausible = append(assessment.Implausible, indicator)
+		}
+		if s.Interim(indicator, m.Sex) {
+			assessment.Interim = append(assessment.Interim, indicator)
+		}
+		z = math.Round(z*100) / 100
+		return &z, nil
+	}
+
+	var err error
+	if m.WeightKg != nil {
+		if assessment.ZScores.WeightForAge, err = score(IndicatorWeightForAge, age, *m.WeightKg); err != nil {
+			return nil, err
+		}
+	}
+
+	if m.LengthCm != nil {
+		length := *m.LengthCm
+		recumbent := infant
+		if m.Recumbent != nil {
+			recumbent = *m.Recumbent
+		}
+		switch {
+		case infant && !recumbent:
+			length += recumbentDifferenceCm
+		case !infant && recumbent:
+			length -= recumbentDifferenceCm
+		}
+		assessment.AdjustedLengthCm = &length
+
+		forAge, forLength := IndicatorLengthForAge, IndicatorWeightForLength
+		if !infant {
+			forAge, forLength = IndicatorHeightForAge, IndicatorWeightForHeight
+		}
+		if assessment.ZScores.LengthForAge, err = score(forAge, age, length); err != nil {
+			return nil, err
+		}
+		if m.WeightKg != nil {
+			// Lengths outside the weight-for-length table are left unscored rather than rejected
+			if _, ok := s.LMS(forLength, m.Sex, length); ok {
+				if assessment.ZScores.WeightForLength, err = score(forLength, length, *m.WeightKg); err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+
+	if m.HeadCircumferenceCm != nil {
+		if assessment.ZScores.HeadCircumferenceForAge, err = score(IndicatorHeadCircumferenceForAge, age, *m.HeadCircumferenceCm); err != nil {
+			return nil, err
+		}
+	}
+
+	if m.MUACCm != nil {
+		// Arm circumference-for-age starts at 3 months; younger infants are not scored
+		if _, ok := s.LMS(IndicatorMUACForAge, m.Sex, age); ok {
+			if assessment.ZScores.MUACForAge, err = score(IndicatorMUACForAge, age, *m.MUACCm); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	assessment.classify(infant)
+	return assessment, nil
+}
+
+// classify records the findings from the z-scores and picks the most serious as the status.
+// Implausible z-scores and those from interim tables are left out.
+func (a *Assessment) classify(infant bool) {
+	forLength := IndicatorWeightForLength
+	forAge := IndicatorLengthForAge
+	if !infant {
+		forLength = IndicatorWeightForHeight
+		forAge = IndicatorHeightForAge
+	}
+
+	low := func(status model.GrowthStatus, indicator Indicator, z *float64) {
+		if z != nil && *z < moderateZ && !a.excluded(indicator) {
+			a.Findings = append(a.Findings, Finding{Status: status, Indicator: indicator, ZScore: *z, Severe: *z < severeZ})
+		}
+	}
+	low(model.GrowthStatusWasted, forLength, a.ZScores.WeightForLength)
+	low(model.GrowthStatusWasted, IndicatorMUACForAge, a.ZScores.MUACForAge)
+	low(model.GrowthStatusStunted, forAge, a.ZScores.LengthForAge)
+	low(model.GrowthStatusUnderweight, IndicatorWeightForAge, a.ZScores.WeightForAge)
+	if z := a.ZScores.WeightForLength; z != nil && *z > overweightZ && !a.excluded(forLength) {
+		a.Findings = append(a.Findings, Finding{Status: model.GrowthStatusOverweight, Indicator: forLength, ZScore: *z, Severe: *z > 3})
+	}
+
+	for _, finding := range a.Findings {
+		if statusRank(finding.Status) > statusRank(a.Status) {
+			a.Status = finding.Status
+		}
+	}
+}
@@ -0,0 +1,289 @@
+package growth
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mamacare/services/internal/app/child/registry"
+	"github.com/mamacare/services/internal/domain/model"
+	"github.com/mamacare/services/internal/domain/repository"
+	"github.com/mamacare/services/pkg/errorx"
+	"github.com/mamacare/services/pkg/logger"
+)
+
+// MeasurementInput contains a child's anthropometry taken at a visit
+type MeasurementInput struct {
+	MeasuredAt          time.Time
+	WeightGrams         *int
+	HeightCm            *float64
+	MeasuredRecumbent   *bool
+	HeadCircumferenceCm *float64
+	MUACCm              *float64
+	MeasuredByID        *uuid.UUID
+	MeasuredByName      string
+	FacilityID          *uuid.UUID
+	Notes               string
+}
+
+// MeasurementResult is a recorded measurement and its assessment
+type MeasurementResult struct {
+	Measurement *model.GrowthMeasurement `json:"measurement"`
+	Assessment  *Assessment              `json:"assessment"`
+	// CurrentStatus is the child's growth status from their latest measurement
+	CurrentStatus model.GrowthStatus `json:"current_growth_status"`
+	// CMAMReferral is the child's acute malnutrition referral or treatment, if the
+	// measurement showed wasting or a low arm circumference
+	CMAMReferral *model.CMAMEnrollment `json:"cmam_referral,omitempty"`
+	// Remeasure means a z-score was implausible, probably from a mistyped or misread
+	// value, and the child should be measured again
+	Remeasure bool `json:"remeasure"`
+}
+
+// MalnutritionReferrer refers children whose measurements show acute malnutrition for treatment
+type MalnutritionReferrer interface {
+	// ReferFromMeasurement refers the child to be assessed for admission, returning their open referral or treatment
+	ReferFromMeasurement(ctx context.Context, child *model.Child, measurement *model.GrowthMeasurement) (*model.CMAMEnrollment, error)
+}
+
+// Service records child growth measurements and scores them against the WHO standards
+type Service struct {
+	registryService *registry.Service
+	measurementRepo repository.GrowthMeasurementRepository
+	childRepo       repository.ChildRepository
+	userRepo        repository.UserRepository
+	standards       *Standards
+	referrer        MalnutritionReferrer
+	log             logger.Logger
+}
+
+// NewService creates a new growth service with the standards loaded by
+// LoadPublishedStandards. Children are not referred for malnutrition treatment if
+// referrer is nil.
+func NewService(
+	registryService *registry.Service,
+	measurementRepo repository.GrowthMeasurementRepository,
+	childRepo repository.ChildRepository,
+	userRepo repository.UserRepository,
+	standards *Standards,
+	referrer MalnutritionReferrer,
+	log logger.Logger,
+) *Service {
+	return &Service{
+		registryService: registryService,
+		measurementRepo: measurementRepo,
+		childRepo:       childRepo,
+		userRepo:        userRepo,
+		standards:       standards,
+		referrer:        referrer,
+		log:             log,
+	}
+}
+
+// RecordMeasurement scores a child's measurement against the WHO standards, stores it
+// with its z-scores and growth status, and updates the child's current growth status
+// if it is their latest measurement. A latest measurement showing wasting or an arm
+// circumference under 11.5 cm refers the child for malnutrition treatment. Implausible
+// z-scores are left out of the status and referral, and the measurement is flagged
+// for re-measuring.
+func (s *Service) RecordMeasurement(
+	ctx context.Context,
+	requesterID, childID uuid.UUID,
+	input *MeasurementInput,
+) (*MeasurementResult, error) {
+	if err := validateMeasurementInput(input); err != nil {
+		return nil, err
+	}
+
+	requester, err := s.userRepo.GetByID(ctx, requesterID)
+	if err != nil {
+		s.log.Error("Failed to find requester", logger.Fields{
+			"error":   err.Error(),
+			"user_id": requesterID.String(),
+		})
+		return nil, errorx.Wrap(err, "failed to find requester")
+	}
+	if requester.Role != model.RoleCHW && requester.Role != model.RoleClinician && requester.Role != model.RoleAdmin {
+		return nil, errorx.New(errorx.Forbidden, "only health workers can record growth measurements")
+	}
+
+	child, err := s.registryService.GetChild(ctx, requesterID, childID)
+	if err != nil {
+		return nil, err
+	}
+	if !child.IsActive {
+		return nil, errorx.New(errorx.BadRequest, "child record is inactive")
+	}
+	if input.MeasuredAt.Before(child.DateOfBirth) {
+		return nil, errorx.New(errorx.BadRequest, "measurement date cannot be before the child's date of birth")
+	}
+
+	measurement := model.NewGrowthMeasurement(uuid.New(), child.ID, input.MeasuredAt)
+	measurement.WeightGrams = input.WeightGrams
+	measurement.HeightCm = input.HeightCm
+	measurement.MeasuredRecumbent = input.MeasuredRecumbent
+	measurement.HeadCircumferenceCm = input.HeadCircumferenceCm
+	measurement.MUACCm = input.MUACCm
+	measurement.MeasuredByID = input.MeasuredByID
+	measurement.MeasuredByName = input.MeasuredByName
+	measurement.FacilityID = input.FacilityID
+	measurement.Notes = input.Notes
+	if measurement.MeasuredByID == nil && measurement.MeasuredByName == "" {
+		measurement.MeasuredByID = &requester.ID
+	}
+
+	assessment, err := s.standards.Assess(Measurement{
+		Sex:                 Sex(child.Sex),
+		AgeDays:             child.AgeInDays(input.MeasuredAt),
+		WeightKg:            measurement.WeightKg(),
+		LengthCm:            input.HeightCm,
+		Recumbent:           input.MeasuredRecumbent,
+		HeadCircumferenceCm: input.HeadCircumferenceCm,
+		MUACCm:              input.MUACCm,
+	})
+	if err != nil {
+		return nil, err
+	}
+	applyAssessment(measurement, assessment)
+
+	if err := s.measurementRepo.Create(ctx, measurement); err != nil {
+		s.log.Error("Failed to create growth measurement", logger.Fields{
+			"error":    err.Error(),
+			"child_id": child.ID.String(),
+		})
+		return nil, errorx.Wrap(err, "failed to record growth measurement")
+	}
+
+	if measurement.ImplausibleZ {
+		s.log.Warn("Implausible growth measurement flagged for re-measuring", logger.Fields{
+			"child_id":       child.ID.String(),
+			"measurement_id": measurement.ID.String(),
+			"implausible":    assessment.Implausible,
+		})
+	}
+
+	latest, err := s.updateCurrentStatus(ctx, child)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &MeasurementResult{
+		Measurement:   measurement,
+		Assessment:    assessment,
+		CurrentStatus: latest.GrowthStatus,
+		Remeasure:     measurement.ImplausibleZ,
+	}
+
+	if s.referrer != nil && latest.ID == measurement.ID && acutelyMalnourished(measurement, assessment) {
+		// The measurement is already stored, so a failed referral is logged rather than returned
+		if result.CMAMReferral, err = s.referrer.ReferFromMeasurement(ctx, child, measurement); err != nil {
+			s.log.Error("Failed to refer child for malnutrition treatment", logger.Fields{
+				"error":          err.Error(),
+				"child_id":       child.ID.String(),
+				"measurement_id": measurement.ID.String(),
+			})
+		}
+	}
+
+	return result, nil
+}
+
+// GetMeasurements returns a child's measurements, oldest first
+func (s *Service) GetMeasurements(ctx context.Context, requesterID, childID uuid.UUID) ([]*model.GrowthMeasurement, error) {
+	child, err := s.registryService.GetChild(ctx, requesterID, childID)
+	if err != nil {
+		return nil, err
+	}
+
+	measurements, err := s.measurementRepo.GetByChildID(ctx, child.ID)
+	if err != nil {
+		s.log.Error("Failed to get growth measurements", logger.Fields{
+			"error":    err.Error(),
+			"child_id": child.ID.String(),
+		})
+		return nil, errorx.Wrap(err, "failed to get growth measurements")
+	}
+	return measurements, nil
+}
+
+// updateCurrentStatus sets the child's current growth status from their latest
+// measurement, which may not be the one just recorded if that was back-dated, and
+// returns that measurement
+func (s *Service) updateCurrentStatus(ctx context.Context, child *model.Child) (*model.GrowthMeasurement, error) {
+	latest, err := s.measurementRepo.GetLatestByChildID(ctx, child.ID)
+	if err != nil {
+		s.log.Error("Failed to get latest growth measurement", logger.Fields{
+			"error":    err.Error(),
+			"child_id": child.ID.String(),
+		})
+		return nil, errorx.Wrap(err, "failed to get latest growth measurement")
+	}
+
+	status := latest.GrowthStatus
+	if child.CurrentGrowthStatus != nil && *child.CurrentGrowthStatus == status {
+		return latest, nil
+	}
+
+	if err := s.childRepo.UpdateGrowthStatus(ctx, child.ID, &status); err != nil {
+		s.log.Error("Failed to update child growth status", logger.Fields{
+			"error":    err.Error(),
+			"child_id": child.ID.String(),
+			"status":   string(status),
+		})
+		return nil, errorx.Wrap(err, "failed to update child growth status")
+	}
+	child.CurrentGrowthStatus = &status
+
+	return latest, nil
+}
+
+// acutelyMalnourished checks if a measurement shows wasting or a severely low arm
+// circumference. An arm circumference with an implausible z-score is not relied on.
+func acutelyMalnourished(measurement *model.GrowthMeasurement, assessment *Assessment) bool {
+	if measurement.GrowthStatus == model.GrowthStatusWasted {
+		return true
+	}
+	for _, indicator := range assessment.Implausible {
+		if indicator == IndicatorMUACForAge {
+			return false
+		}
+	}
+	return measurement.MUACCm != nil && *measurement.MUACCm < model.CMAMSevereMUACCm
+}
+
+// applyAssessment copies the z-scores and status onto the measurement
+func applyAssessment(measurement *model.GrowthMeasurement, assessment *Assessment) {
+	measurement.WeightForAgeZ = assessment.ZScores.WeightForAge
+	measurement.HeightForAgeZ = assessment.ZScores.LengthForAge
+	measurement.WeightForHeightZ = assessment.ZScores.WeightForLength
+	measurement.HeadCircumferenceForAgeZ = assessment.ZScores.HeadCircumferenceForAge
+	measurement.MUACForAgeZ = assessment.ZScores.MUACForAge
+	measurement.ImplausibleZ = len(assessment.Implausible) > 0
+	measurement.GrowthStatus = assessment.Status
+}
+
+// validateMeasurementInput validates a growth measurement
+func validateMeasurementInput(input *MeasurementInput) error {
+	if input == nil {
+		return errorx.New(errorx.BadRequest, "measurement details are required")
+	}
+	if input.MeasuredAt.IsZero() || input.MeasuredAt.After(time.Now()) {
+		return errorx.New(errorx.BadRequest, "measurement date must be in the past")
+	}
+	if input.WeightGrams == nil && input.HeightCm == nil && input.HeadCircumferenceCm == nil && input.MUACCm == nil {
+		return errorx.New(errorx.BadRequest, "at least one of weight, length or height, head or arm circumference is required")
+	}
+	if input.WeightGrams != nil && (*input.WeightGrams < 500 || *input.WeightGrams > 30000) {
+		return errorx.New(errorx.BadRequest, "weight must be between 500 and 30000 grams")
+	}
+	if input.HeightCm != nil && (*input.HeightCm < 20 || *input.HeightCm > 150) {
+		return errorx.New(errorx.BadRequest, "length or height must be between 20 and 150 cm")
+	}
+	if input.HeadCircumferenceCm != nil && (*input.HeadCircumferenceCm < 10 || *input.HeadCircumferenceCm > 60) {
+		return errorx.New(errorx.BadRequest, "head circumference must be between 10 and 60 cm")
+	}
+	if input.MUACCm != nil && (*input.MUACCm < 5 || *input.MUACCm > 30) {
+		return errorx.New(errorx.BadRequest, "arm circumference must be between 5 and 30 cm")
+	}
+	return nil
+}
@@ -0,0 +1,350 @@
+package growth
+
+import (
+	"bufio"
+	"bytes"
+	"embed"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/mamacare/services/pkg/errorx"
+)
+
+// daysPerMonth converts the monthly WHO tables to age in days
+const daysPerMonth = 30.4375
+
+// Indicator is a WHO growth indicator
+type Indicator string
+
+const (
+	// IndicatorWeightForAge is weight-for-age
+	IndicatorWeightForAge Indicator = "wfa"
+	// IndicatorLengthForAge is recumbent length-for-age, from birth to 24 months
+	IndicatorLengthForAge Indicator = "lfa"
+	// IndicatorHeightForAge is standing height-for-age, from 24 to 60 months
+	IndicatorHeightForAge Indicator = "hfa"
+	// IndicatorWeightForLength is weight-for-length, from birth to 24 months
+	IndicatorWeightForLength Indicator = "wfl"
+	// IndicatorWeightForHeight is weight-for-height, from 24 to 60 months
+	IndicatorWeightForHeight Indicator = "wfh"
+	// IndicatorHeadCircumferenceForAge is head circumference-for-age
+	IndicatorHeadCircumferenceForAge Indicator = "hcfa"
+	// IndicatorMUACForAge is mid-upper arm circumference-for-age, from 3 months
+	IndicatorMUACForAge Indicator = "acfa"
+)
+
+// indicators are the tables a set of standards must have, for each sex
+var indicators = []Indicator{
+	IndicatorWeightForAge,
+	IndicatorLengthForAge,
+	IndicatorHeightForAge,
+	IndicatorWeightForLength,
+	IndicatorWeightForHeight,
+	IndicatorHeadCircumferenceForAge,
+	IndicatorMUACForAge,
+}
+
+// restricted reports whether the indicator is weight or arm circumference based, for which
+// WHO restricts the LMS curve beyond ±3 SD because of the skew in the upper tail
+func (i Indicator) restricted() bool {
+	switch i {
+	case IndicatorWeightForAge, IndicatorWeightForLength, IndicatorWeightForHeight, IndicatorMUACForAge:
+		return true
+	default:
+		return false
+	}
+}
+
+// publishedStep is the step of the published WHO table for the indicator: 0.5 cm for
+// weight-for-length and weight-for-height, and a month for the age-based indicators
+func (i Indicator) publishedStep() float64 {
+	switch i {
+	case IndicatorWeightForLength, IndicatorWeightForHeight:
+		return 0.5
+	default:
+		return daysPerMonth
+	}
+}
+
+// Sex is the sex a growth standard is for
+type Sex string
+
+const (
+	// SexMale selects the boys' tables
+	SexMale Sex = "male"
+	// SexFemale selects the girls' tables
+	SexFemale Sex = "female"
+)
+
+// fileSuffix returns the suffix of the table files for the sex
+func (s Sex) fileSuffix() string {
+	if s == SexFemale {
+		return "girls"
+	}
+	return "boys"
+}
+
+// LMS is the Box-Cox power, median and coefficient of variation at a point of a standard
+type LMS struct {
+	L float64 `json:"l"`
+	M float64 `json:"m"`
+	S float64 `json:"s"`
+}
+
+// value returns the measurement at a z-score
+func (p LMS) value(z float64) float64 {
+	if p.L == 0 {
+		return p.M * math.Exp(p.S*z)
+	}
+	return p.M * math.Pow(1+p.L*p.S*z, 1/p.L)
+}
+
+// zScore returns the z-score of a measurement
+func (p LMS) zScore(y float64) float64 {
+	if p.L == 0 {
+		return math.Log(y/p.M) / p.S
+	}
+	return (math.Pow(y/p.M, p.L) - 1) / (p.L * p.S)
+}
+
+// table is one indicator's LMS values for one sex. x is age in days for the age-based
+// indicators and length or height in cm for weight-for-length and weight-for-height.
+type table struct {
+	x   []float64
+	lms []LMS
+}
+
+// at interpolates the LMS values linearly at x, returning false outside the table
+func (t *table) at(x float64) (LMS, bool) {
+	n := len(t.x)
+	if n == 0 || x < t.x[0] || x > t.x[n-1] {
+		return LMS{}, false
+	}
+
+	i := sort.SearchFloat64s(t.x, x)
+	if t.x[i] == x {
+		return t.lms[i], true
+	}
+
+	lo, hi := t.lms[i-1], t.lms[i]
+	f := (x - t.x[i-1]) / (t.x[i] - t.x[i-1])
+	return LMS{
+		L: lo.L + f*(hi.L-lo.L),
+		M: lo.M + f*(hi.M-lo.M),
+		S: lo.S + f*(hi.S-lo.S),
+	}, true
+}
+
+// maxStep returns the widest gap between consecutive rows
+func (t *table) maxStep() float64 {
+	step := 0.0
+	for i := 1; i < len(t.x); i++ {
+		step = math.Max(step, t.x[i]-t.x[i-1])
+	}
+	return step
+}
+
+// tableKey identifies a table
+type tableKey struct {
+	indicator Indicator
+	sex       Sex
+}
+
+// Standards are the WHO 2006 Child Growth Standards as LMS tables
+type Standards struct {
+	tables map[tableKey]*table
+}
+
+// builtinTables are the WHO tables built into the service: monthly for the age-based
+// indicators, 5 cm steps for weight-for-length and weight-for-height and 3-monthly for
+// arm circumference. The weight-for-length, weight-for-height and arm circumference
+// tables are interim: they are coarser and less precise than the published WHO tables
+// (0.5 cm and monthly steps), so Interim reports them and their z-scores are not used
+// to classify growth. Deployments load the published tables, or the expanded daily
+// ones, with LoadPublishedStandards.
+//
+//go:embed tables/*.txt
+var builtinTables embed.FS
+
+// DefaultStandards returns the built-in standards
+func DefaultStandards() *Standards {
+	standards, err := loadStandards(func(name string) ([]byte, error) {
+		return builtinTables.ReadFile("tables/" + name)
+	})
+	if err != nil {
+		// The embedded tables are part of the build, so this is a programming error
+		panic("invalid built-in growth standards: " + err.Error())
+	}
+	return standards
+}
+
+// LoadStandardsDir reads the standards from a directory of tables named like the
+// built-in ones, such as wfa_boys.txt. The WHO expanded daily tables can be used as
+// published, with their Day, Length or Height column first.
+func LoadStandardsDir(dir string) (*Standards, error) {
+	return loadStandards(func(name string) ([]byte, error) {
+		return os.ReadFile(filepath.Join(dir, name))
+	})
+}
+
+// LoadPublishedStandards loads the standards at startup from the directory configured as
+// growth.standards_dir. Every table must be at the published WHO precision: with the
+// interim built-in tables wasting could never be classified by weight-for-height or arm
+// circumference, so a missing directory or a coarser table is an error that should stop
+// the service from starting.
+func LoadPublishedStandards(dir string) (*Standards, error) {
+	if dir == "" {
+		return nil, errorx.New(errorx.InternalServerError, "growth.standards_dir must be set to the published WHO growth tables")
+	}
+	standards, err := LoadStandardsDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	for _, indicator := range indicators {
+		for _, sex := range []Sex{SexMale, SexFemale} {
+			if standards.Interim(indicator, sex) {
+				return nil, errorx.Newf(errorx.InternalServerError,
+					"growth standard %s_%s.txt in %s is coarser than the published WHO table", indicator, sex.fileSuffix(), dir)
+			}
+		}
+	}
+	return standards, nil
+}
+
+// loadStandards reads every indicator's table for both sexes
+func loadStandards(read func(name string) ([]byte, error)) (*Standards, error) {
+	standards := &Standards{tables: make(map[tableKey]*table)}
+	for _, indicator := range indicators {
+		for _, sex := range []Sex{SexMale, SexFemale} {
+			name := fmt.Sprintf("%s_%s.txt", indicator, sex.fileSuffix())
+			data, err := read(name)
+			if err != nil {
+				return nil, errorx.Wrap(err, errorx.InternalServerError, "failed to read growth standard "+name)
+			}
+			t, err := parseTable(data)
+			if err != nil {
+				return nil, errorx.Wrap(err, errorx.InternalServerError, "invalid growth standard "+name)
+			}
+			standards.tables[tableKey{indicator: indicator, sex: sex}] = t
+		}
+	}
+	return standards, nil
+}
+
+// parseTable parses a tab or space separated LMS table. Lines starting with # are
+// comments; the header names the first column Month, Day, Length or Height, and the
+// L, M and S columns, which may be followed by centile or SD columns that are ignored.
+func parseTable(data []byte) (*table, error) {
+	t := &table{}
+	scale := 0.0
+	columns := map[string]int{}
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fields := strings.Fields(line)
+
+		if scale == 0 {
+			switch strings.ToLower(fields[0]) {
+			case "month":
+				scale = daysPerMonth
+			case "day", "length", "height":
+				scale = 1
+			default:
+				return nil, fmt.Errorf("unknown first column %q", fields[0])
+			}
+			for i, name := range fields {
+				columns[strings.ToUpper(name)] = i
+			}
+			for _, name := range []string{"L", "M", "S"} {
+				if _, ok := columns[name]; !ok {
+					return nil, fmt.Errorf("missing %s column", name)
+				}
+			}
+			continue
+		}
+
+		values := make([]float64, len(fields))
+		for i, field := range fields {
+			value, err := strconv.ParseFloat(field, 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid value %q", field)
+			}
+			values[i] = value
+		}
+		if len(values) <= columns["L"] || len(values) <= columns["M"] || len(values) <= columns["S"] {
+			return nil, fmt.Errorf("short row %q", line)
+		}
+
+		x := values[0] * scale
+		if n := len(t.x); n > 0 && x <= t.x[n-1] {
+			return nil, fmt.Errorf("rows are not in ascending order at %q", line)
+		}
+		t.x = append(t.x, x)
+		t.lms = append(t.lms, LMS{L: values[columns["L"]], M: values[columns["M"]], S: values[columns["S"]]})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if len(t.x) < 2 {
+		return nil, fmt.Errorf("table has fewer than two rows")
+	}
+	return t, nil
+}
+
+// LMS returns the interpolated LMS values of an indicator at x, which is age in days or
+// length or height in cm. It returns false if x is outside the standard.
+func (s *Standards) LMS(indicator Indicator, sex Sex, x float64) (LMS, bool) {
+	t, ok := s.tables[tableKey{indicator: indicator, sex: sex}]
+	if !ok {
+		return LMS{}, false
+	}
+	return t.at(x)
+}
+
+// Interim reports whether an indicator's table is coarser than the published WHO table,
+// which makes its z-scores too imprecise to classify growth or refer children with
+func (s *Standards) Interim(indicator Indicator, sex Sex) bool {
+	t, ok := s.tables[tableKey{indicator: indicator, sex: sex}]
+	if !ok {
+		return true
+	}
+	// Monthly tables are converted to days, so allow for rounding in the comparison
+	return t.maxStep() > indicator.publishedStep()+1e-6
+}
+
+// ZScore returns the z-score of a measurement y at x. For weight and arm circumference
+// z-scores beyond ±3 are computed from the distance between the 2 and 3 SD curves, as
+// WHO does, so the skew of the upper tail does not compress extreme values.
+func (s *Standards) ZScore(indicator Indicator, sex Sex, x, y float64) (float64, error) {
+	if y <= 0 {
+		return 0, errorx.Newf(errorx.BadRequest, "%s measurement must be positive", indicator)
+	}
+	lms, ok := s.LMS(indicator, sex, x)
+	if !ok {
+		return 0, errorx.Newf(errorx.BadRequest, "%v is outside the WHO %s standard", x, indicator)
+	}
+
+	z := lms.zScore(y)
+	if !indicator.restricted() {
+		return z, nil
+	}
+
+	switch {
+	case z > 3:
+		sd3 := lms.value(3)
+		z = 3 + (y-sd3)/(sd3-lms.value(2))
+	case z < -3:
+		sd3 := lms.value(-3)
+		z = -3 + (y-sd3)/(lms.value(-2)-sd3)
+	}
+	return z, nil
+}
@@ -0,0 +1,125 @@
+package growth
+
+import (
+	"math"
+	"testing"
+)
+
+// testTable is a two-row monthly table with a skewed and a log-normal row
+const testTable = `# test standard
+Month	L	M	S
+0	0.5	10	0.1
+2	0	20	0.1
+`
+
+// newTestStandards returns standards with testTable as the boys' length-for-age and
+// weight-for-age
+func newTestStandards(t *testing.T) *Standards {
+	t.Helper()
+	tbl, err := parseTable([]byte(testTable))
+	if err != nil {
+		t.Fatalf("parseTable() error = %v", err)
+	}
+	return &Standards{tables: map[tableKey]*table{
+		{indicator: IndicatorLengthForAge, sex: SexMale}: tbl,
+		{indicator: IndicatorWeightForAge, sex: SexMale}: tbl,
+	}}
+}
+
+func TestZScore(t *testing.T) {
+	skewed := LMS{L: 0.5, M: 10, S: 0.1}
+	logNormal := LMS{L: 0, M: 20, S: 0.1}
+
+	tests := []struct {
+		name      string
+		indicator Indicator
+		x, y      float64
+		want      float64
+		wantErr   bool
+	}{
+		{"median", IndicatorLengthForAge, 0, 10, 0, false},
+		{"one SD above the median", IndicatorLengthForAge, 0, skewed.value(1), 1, false},
+		{"log-normal row", IndicatorLengthForAge, 2 * daysPerMonth, logNormal.value(-2), -2, false},
+		{"interpolated between rows", IndicatorLengthForAge, daysPerMonth, 15, 0, false},
+		{"length beyond +3 SD is not restricted", IndicatorLengthForAge, 0, skewed.value(4), 4, false},
+		{"weight at +3 SD", IndicatorWeightForAge, 0, skewed.value(3), 3, false},
+		{
+			name:      "weight beyond +3 SD uses the 2 to 3 SD distance",
+			indicator: IndicatorWeightForAge,
+			y:         skewed.value(3) + (skewed.value(3)-skewed.value(2))/2,
+			want:      3.5,
+		},
+		{
+			name:      "weight below -3 SD uses the 2 to 3 SD distance",
+			indicator: IndicatorWeightForAge,
+			y:         skewed.value(-3) - (skewed.value(-2) - skewed.value(-3)),
+			want:      -4,
+		},
+		{"zero measurement", IndicatorWeightForAge, 0, 0, 0, true},
+		{"age outside the standard", IndicatorWeightForAge, 3 * daysPerMonth, 10, 0, true},
+		{"indicator without a table", IndicatorMUACForAge, 0, 10, 0, true},
+	}
+
+	standards := newTestStandards(t)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := standards.ZScore(tt.indicator, SexMale, tt.x, tt.y)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ZScore() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("ZScore() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestZScoreWithDefaultStandards(t *testing.T) {
+	standards := DefaultStandards()
+
+	tests := []struct {
+		name      string
+		indicator Indicator
+		sex       Sex
+		x, y      float64
+		want      float64
+	}{
+		{"boy at the median birth weight", IndicatorWeightForAge, SexMale, 0, 3.3464, 0},
+		{"boy at the median weight at one month", IndicatorWeightForAge, SexMale, daysPerMonth, 4.4709, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := standards.ZScore(tt.indicator, tt.sex, tt.x, tt.y)
+			if err != nil {
+				t.Fatalf("ZScore() error = %v", err)
+			}
+			if math.Abs(got-tt.want) > 1e-6 {
+				t.Errorf("ZScore() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestInterim(t *testing.T) {
+	standards := DefaultStandards()
+
+	tests := []struct {
+		indicator Indicator
+		want      bool
+	}{
+		{IndicatorWeightForAge, false},
+		{IndicatorLengthForAge, false},
+		{IndicatorWeightForLength, true},
+		{IndicatorWeightForHeight, true},
+		{IndicatorMUACForAge, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.indicator), func(t *testing.T) {
+			if got := standards.Interim(tt.indicator, SexFemale); got != tt.want {
+				t.Errorf("Interim() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,77 @@
+package immunization
+
+import (
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// CatchUpVisit is a visit in a catch-up plan and the doses to give at it
+type CatchUpVisit struct {
+	Date  time.Time       `json:"date"`
+	Doses []ScheduledDose `json:"doses"`
+}
+
+// CatchUpPlan is the visits a late child needs to complete the doses they have fallen behind on
+type CatchUpPlan struct {
+	ChildID uuid.UUID      `json:"child_id"`
+	Visits  []CatchUpVisit `json:"visits"`
+	// Unreachable are outstanding doses the child would be too old for by the time they could be given
+	Unreachable []ScheduledDose `json:"unreachable"`
+	GeneratedAt time.Time       `json:"generated_at"`
+}
+
+// PlanCatchUp plans the outstanding doses from today. Different vaccines are given
+// together at the first visit; later doses of the same vaccine follow at their minimum
+// interval, counted from the previous dose given or planned. Doses that would fall
+// after the maximum age are left out of the visits.
+func PlanCatchUp(status *Status, now time.Time) *CatchUpPlan {
+	plan := &CatchUpPlan{
+		ChildID:     status.ChildID,
+		Visits:      []CatchUpVisit{},
+		Unreachable: []ScheduledDose{},
+		GeneratedAt: now,
+	}
+
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	previous := make(map[string]time.Time)
+	byDate := make(map[time.Time][]ScheduledDose)
+
+	for _, dose := range status.Doses {
+		if dose.Status == DoseStatusGiven {
+			previous[dose.VaccineName] = dose.Record.AdministeredDate
+			continue
+		}
+		if !dose.Status.IsOutstanding() {
+			continue
+		}
+
+		date := today
+		if dose.WindowStart.After(date) {
+			date = dose.WindowStart
+		}
+		if last, ok := previous[dose.VaccineName]; ok {
+			if next := last.AddDate(0, 0, dose.MinIntervalDays); next.After(date) {
+				date = next
+			}
+		}
+		date = time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, now.Location())
+		if dose.LastDate != nil && date.After(*dose.LastDate) {
+			plan.Unreachable = append(plan.Unreachable, dose)
+			continue
+		}
+
+		previous[dose.VaccineName] = date
+		byDate[date] = append(byDate[date], dose)
+	}
+
+	for date, doses := range byDate {
+		plan.Visits = append(plan.Visits, CatchUpVisit{Date: date, Doses: doses})
+	}
+	sort.Slice(plan.Visits, func(a, b int) bool {
+		return plan.Visits[a].Date.Before(plan.Visits[b].Date)
+	})
+
+	return plan
+}
@@ -0,0 +1,222 @@
+package immunization
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mamacare/services/internal/app/notification/preference"
+	"github.com/mamacare/services/internal/app/notification/push"
+	"github.com/mamacare/services/internal/app/notification/scheduler"
+	"github.com/mamacare/services/internal/domain/model"
+	"github.com/mamacare/services/internal/domain/repository"
+	"github.com/mamacare/services/pkg/errorx"
+	"github.com/mamacare/services/pkg/logger"
+)
+
+const (
+	// reminderLeadDays is how many days before a dose opens parents are reminded it is coming up
+	reminderLeadDays = 3
+	// reminderAgeYears is the oldest a child can be and still be followed for reminders
+	reminderAgeYears = 5
+	// reminderDelay gives the scheduler a delivery time safely in the future
+	reminderDelay = time.Minute
+)
+
+// ReminderReport is the outcome of a vaccine reminder run
+type ReminderReport struct {
+	RunAt           time.Time `json:"run_at"`
+	ChildrenChecked int       `json:"children_checked"`
+	RemindersSent   int       `json:"reminders_sent"`
+	ChildrenSkipped int       `json:"children_skipped"`
+	DueDoses        int       `json:"due_doses"`
+	OverdueDoses    int       `json:"overdue_doses"`
+}
+
+// ReminderJob sends parents vaccine reminders for doses coming due and doses overdue
+type ReminderJob struct {
+	childRepo         repository.ChildRepository
+	immunizationRepo  repository.ImmunizationRepository
+	preferenceService *preference.Service
+	schedulerService  *scheduler.Service
+	log               logger.Logger
+}
+
+// NewReminderJob creates a new vaccine reminder job
+func NewReminderJob(
+	childRepo repository.ChildRepository,
+	immunizationRepo repository.ImmunizationRepository,
+	preferenceService *preference.Service,
+	schedulerService *scheduler.Service,
+	log logger.Logger,
+) *ReminderJob {
+	return &ReminderJob{
+		childRepo:         childRepo,
+		immunizationRepo:  immunizationRepo,
+		preferenceService: preferenceService,
+		schedulerService:  schedulerService,
+		log:               log,
+	}
+}
+
+// Run checks every active child under five against the schedule and reminds their
+// parents once per dose when it is about to open and again if it becomes overdue.
+// It is meant to run daily.
+func (j *ReminderJob) Run(ctx context.Context) (*ReminderReport, error) {
+	now := time.Now()
+
+	children, err := j.childRepo.GetActiveBornAfter(ctx, now.AddDate(-reminderAgeYears, 0, 0))
+	if err != nil {
+		j.log.Error("Failed to list children for vaccine reminders", logger.Fields{
+			"error": err.Error(),
+		})
+		return nil, errorx.Wrap(err, "failed to list children")
+	}
+
+	schedules, err := j.immunizationRepo.GetSchedules(ctx)
+	if err != nil {
+		j.log.Error("Failed to get vaccine schedules", logger.Fields{
+			"error": err.Error(),
+		})
+		return nil, errorx.Wrap(err, "failed to get vaccine schedules")
+	}
+
+	report := &ReminderReport{RunAt: now}
+	for _, child := range children {
+		records, err := j.immunizationRepo.GetRecordsByChildID(ctx, child.ID)
+		if err != nil {
+			j.log.Warn("Failed to get immunization records for reminders", logger.Fields{
+				"error":    err.Error(),
+				"child_id": child.ID.String(),
+			})
+			report.ChildrenSkipped++
+			continue
+		}
+		report.ChildrenChecked++
+
+		due, overdue := reminderDoses(Evaluate(child, schedules, records, now), now)
+		if len(due) == 0 && len(overdue) == 0 {
+			continue
+		}
+
+		recipients := j.recipients(ctx, child)
+		if len(recipients) == 0 {
+			report.ChildrenSkipped++
+			continue
+		}
+
+		if sent := j.remind(ctx, child, recipients, due, model.VaccineReminderDue); sent > 0 {
+			report.RemindersSent++
+			report.DueDoses += sent
+		}
+		if sent := j.remind(ctx, child, recipients, overdue, model.VaccineReminderOverdue); sent > 0 {
+			report.RemindersSent++
+			report.OverdueDoses += sent
+		}
+	}
+
+	j.log.Info("Vaccine reminder run finished", logger.Fields{
+		"children":  len(children),
+		"checked":   report.ChildrenChecked,
+		"skipped":   report.ChildrenSkipped,
+		"reminders": report.RemindersSent,
+	})
+
+	return report, nil
+}
+
+// reminderDoses splits a child's doses into those opening soon or due, and those overdue.
+// Missing doses are left to the clinic, as a later dose has already been given.
+func reminderDoses(status *Status, now time.Time) ([]ScheduledDose, []ScheduledDose) {
+	var due, overdue []ScheduledDose
+	lead := now.AddDate(0, 0, reminderLeadDays)
+	for _, dose := range status.Doses {
+		switch {
+		case dose.Status == DoseStatusDue:
+			due = append(due, dose)
+		case dose.Status == DoseStatusNotYetDue && !dose.EarliestDate.After(lead):
+			due = append(due, dose)
+		case dose.Status == DoseStatusOverdue:
+			overdue = append(overdue, dose)
+		}
+	}
+	return due, overdue
+}
+
+// recipients returns the child's linked parents who want vaccine reminders
+func (j *ReminderJob) recipients(ctx context.Context, child *model.Child) []uuid.UUID {
+	var recipients []uuid.UUID
+	for _, parentID := range []*uuid.UUID{child.MotherID, child.FatherID} {
+		if parentID == nil {
+			continue
+		}
+		enabled, err := j.preferenceService.IsNotificationEnabled(ctx, *parentID, preference.TypeVaccineReminder)
+		if err != nil {
+			j.log.Warn("Failed to check vaccine reminder preference", logger.Fields{
+				"error":   err.Error(),
+				"user_id": parentID.String(),
+			})
+			continue
+		}
+		if enabled {
+			recipients = append(recipients, *parentID)
+		}
+	}
+	return recipients
+}
+
+// remind sends one reminder covering the doses not already reminded about, returning how many it covered
+func (j *ReminderJob) remind(
+	ctx context.Context,
+	child *model.Child,
+	recipients []uuid.UUID,
+	doses []ScheduledDose,
+	kind model.VaccineReminderKind,
+) int {
+	var labels []string
+	for _, dose := range doses {
+		fresh, err := j.immunizationRepo.MarkReminderSent(ctx, child.ID, dose.VaccineName, dose.Dose, kind)
+		if err != nil {
+			j.log.Warn("Failed to record vaccine reminder", logger.Fields{
+				"error":    err.Error(),
+				"child_id": child.ID.String(),
+				"vaccine":  dose.Label(),
+			})
+			continue
+		}
+		if fresh {
+			labels = append(labels, dose.Label())
+		}
+	}
+	if len(labels) == 0 {
+		return 0
+	}
+
+	payload := &push.NotificationPayload{
+		Title: fmt.Sprintf("Vaccines due for %s", child.FirstName),
+		Body:  fmt.Sprintf("%s is due for %s. Please visit your nearest clinic.", child.FirstName, strings.Join(labels, ", ")),
+		Data: map[string]interface{}{
+			"type":     string(preference.TypeVaccineReminder),
+			"kind":     string(kind),
+			"child_id": child.ID.String(),
+			"vaccines": labels,
+		},
+	}
+	if kind == model.VaccineReminderOverdue {
+		payload.Title = fmt.Sprintf("Vaccines overdue for %s", child.FirstName)
+		payload.Body = fmt.Sprintf("%s has missed %s. It is not too late - please visit your nearest clinic.", child.FirstName, strings.Join(labels, ", "))
+	}
+
+	for _, recipientID := range recipients {
+		if _, err := j.schedulerService.SchedulePush(ctx, recipientID, payload, push.PushProvider(""), time.Now().Add(reminderDelay)); err != nil {
+			j.log.Warn("Failed to schedule vaccine reminder", logger.Fields{
+				"error":        err.Error(),
+				"child_id":     child.ID.String(),
+				"recipient_id": recipientID.String(),
+			})
+		}
+	}
+	return len(labels)
+}
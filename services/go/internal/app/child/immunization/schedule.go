@@ -0,0 +1,245 @@
+package immunization
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mamacare/services/internal/domain/model"
+)
+
+// DoseStatus is where a scheduled dose stands for a child
+type DoseStatus string
+
+const (
+	// DoseStatusGiven means a valid dose has been recorded
+	DoseStatusGiven DoseStatus = "given"
+	// DoseStatusNotYetDue means the child is too young for the dose
+	DoseStatusNotYetDue DoseStatus = "not_yet_due"
+	// DoseStatusDue means the dose can be given now and is still on time
+	DoseStatusDue DoseStatus = "due"
+	// DoseStatusOverdue means the dose's window has passed without it being given
+	DoseStatusOverdue DoseStatus = "overdue"
+	// DoseStatusMissing means a later dose of the same vaccine was given but this one was not
+	DoseStatusMissing DoseStatus = "missing"
+	// DoseStatusExpired means the child is past the maximum age for a dose they never had
+	DoseStatusExpired DoseStatus = "expired"
+)
+
+// IsOutstanding checks if the dose still needs to be given now
+func (s DoseStatus) IsOutstanding() bool {
+	return s == DoseStatusDue || s == DoseStatusOverdue || s == DoseStatusMissing
+}
+
+// ScheduledDose is one dose of the schedule and where it stands for a child
+type ScheduledDose struct {
+	VaccineName     string    `json:"vaccine_name"`
+	Dose            string    `json:"vaccine_dose"`
+	DiseaseTarget   string    `json:"disease_target"`
+	IsRequired      bool      `json:"is_required"`
+	RecommendedDate time.Time `json:"recommended_date"`
+	WindowStart     time.Time `json:"window_start"`
+	WindowEnd       time.Time `json:"window_end"`
+	// EarliestDate is the window start, pushed back to the minimum interval after the previous dose
+	EarliestDate time.Time `json:"earliest_date"`
+	// LastDate is the day the child reaches the maximum age for the dose, if it has one
+	LastDate        *time.Time                `json:"last_date,omitempty"`
+	MinIntervalDays int                       `json:"min_interval_days"`
+	Status          DoseStatus                `json:"status"`
+	Record          *model.ImmunizationRecord `json:"record,omitempty"`
+}
+
+// Label returns the vaccine and dose, as shown to parents
+func (d ScheduledDose) Label() string {
+	return fmt.Sprintf("%s %s", d.VaccineName, d.Dose)
+}
+
+// Status is a child's immunization status against the EPI schedule
+type Status struct {
+	ChildID      uuid.UUID                   `json:"child_id"`
+	DateOfBirth  time.Time                   `json:"date_of_birth"`
+	AgeDays      int                         `json:"age_days"`
+	Doses        []ScheduledDose             `json:"doses"`
+	InvalidDoses []*model.ImmunizationRecord `json:"invalid_doses"`
+	DueCount     int                         `json:"due_count"`
+	OverdueCount int                         `json:"overdue_count"`
+	MissingCount int                         `json:"missing_count"`
+	// UpToDate means every required dose whose window has opened has been given
+	UpToDate    bool      `json:"up_to_date"`
+	EvaluatedAt time.Time `json:"evaluated_at"`
+}
+
+// Outstanding returns the doses that still need to be given now
+func (s *Status) Outstanding() []ScheduledDose {
+	var doses []ScheduledDose
+	for _, dose := range s.Doses {
+		if dose.Status.IsOutstanding() {
+			doses = append(doses, dose)
+		}
+	}
+	return doses
+}
+
+// series groups the schedule into vaccines, keeping each vaccine's doses in schedule order
+func series(schedules []*model.VaccineSchedule) ([]string, map[string][]*model.VaccineSchedule) {
+	var names []string
+	byName := make(map[string][]*model.VaccineSchedule)
+	for _, schedule := range schedules {
+		if _, ok := byName[schedule.VaccineName]; !ok {
+			names = append(names, schedule.VaccineName)
+		}
+		byName[schedule.VaccineName] = append(byName[schedule.VaccineName], schedule)
+	}
+	return names, byName
+}
+
+// doseKey identifies a vaccine dose
+func doseKey(vaccineName, dose string) string {
+	return vaccineName + "|" + dose
+}
+
+// Evaluate works out which doses of the schedule a child has had, is due, overdue or
+// missing, and which they are now too old for. Invalid doses do not count and are
+// listed separately.
+func Evaluate(
+	child *model.Child,
+	schedules []*model.VaccineSchedule,
+	records []*model.ImmunizationRecord,
+	now time.Time,
+) *Status {
+	status := &Status{
+		ChildID:      child.ID,
+		DateOfBirth:  child.DateOfBirth,
+		AgeDays:      child.AgeInDays(now),
+		Doses:        []ScheduledDose{},
+		InvalidDoses: []*model.ImmunizationRecord{},
+		UpToDate:     true,
+		EvaluatedAt:  now,
+	}
+
+	given := make(map[string]*model.ImmunizationRecord, len(records))
+	for _, record := range records {
+		if !record.IsValid {
+			status.InvalidDoses = append(status.InvalidDoses, record)
+			continue
+		}
+		given[doseKey(record.VaccineName, record.Dose)] = record
+	}
+
+	names, byName := series(schedules)
+	for _, name := range names {
+		doses := byName[name]
+
+		// A dose is missing rather than overdue if a later dose was given after it
+		lastGiven := -1
+		for i, schedule := range doses {
+			if given[doseKey(schedule.VaccineName, schedule.Dose)] != nil {
+				lastGiven = i
+			}
+		}
+
+		var previous *time.Time
+		for i, schedule := range doses {
+			dose := ScheduledDose{
+				VaccineName:     schedule.VaccineName,
+				Dose:            schedule.Dose,
+				DiseaseTarget:   schedule.DiseaseTarget,
+				IsRequired:      schedule.IsRequired,
+				RecommendedDate: schedule.RecommendedDate(child.DateOfBirth),
+				WindowStart:     schedule.WindowStart(child.DateOfBirth),
+				WindowEnd:       schedule.WindowEnd(child.DateOfBirth),
+				LastDate:        schedule.LastDate(child.DateOfBirth),
+				MinIntervalDays: schedule.MinIntervalDays,
+			}
+			dose.EarliestDate = dose.WindowStart
+			if previous != nil {
+				if next := schedule.EarliestAfter(*previous); next.After(dose.EarliestDate) {
+					dose.EarliestDate = next
+				}
+			}
+
+			if record := given[doseKey(schedule.VaccineName, schedule.Dose)]; record != nil {
+				dose.Status = DoseStatusGiven
+				dose.Record = record
+				administered := record.AdministeredDate
+				previous = &administered
+			} else {
+				switch {
+				case dose.LastDate != nil && now.After(*dose.LastDate):
+					dose.Status = DoseStatusExpired
+				case i < lastGiven:
+					dose.Status = DoseStatusMissing
+					status.MissingCount++
+				case now.Before(dose.EarliestDate):
+					dose.Status = DoseStatusNotYetDue
+				case !now.After(dose.WindowEnd):
+					dose.Status = DoseStatusDue
+					status.DueCount++
+				default:
+					dose.Status = DoseStatusOverdue
+					status.OverdueCount++
+				}
+				// Nothing can be done about a dose the child is too old for
+				if dose.IsRequired && dose.Status != DoseStatusExpired && !now.Before(dose.WindowStart) {
+					status.UpToDate = false
+				}
+			}
+
+			status.Doses = append(status.Doses, dose)
+		}
+	}
+
+	return status
+}
+
+// invalidReason checks a dose about to be recorded against the child's age and the
+// minimum interval after the previous valid dose of the same vaccine, returning why it
+// does not count or ""
+func invalidReason(
+	child *model.Child,
+	schedule *model.VaccineSchedule,
+	schedules []*model.VaccineSchedule,
+	records []*model.ImmunizationRecord,
+	administered time.Time,
+) string {
+	windowStart := schedule.WindowStart(child.DateOfBirth)
+	if administered.Before(windowStart) {
+		return fmt.Sprintf("given at %d days old, before the minimum age of %d days",
+			child.AgeInDays(administered), child.AgeInDays(windowStart))
+	}
+	if last := schedule.LastDate(child.DateOfBirth); last != nil && administered.After(*last) {
+		return fmt.Sprintf("given at %d days old, after the maximum age of %d days",
+			child.AgeInDays(administered), *schedule.MaxAgeDays)
+	}
+
+	// The previous dose is the latest valid dose given for an earlier place in the series
+	_, byName := series(schedules)
+	earlier := make(map[string]bool)
+	for _, s := range byName[schedule.VaccineName] {
+		if s == schedule {
+			break
+		}
+		earlier[s.Dose] = true
+	}
+
+	var previous *model.ImmunizationRecord
+	for _, record := range records {
+		if !record.IsValid || record.VaccineName != schedule.VaccineName || !earlier[record.Dose] {
+			continue
+		}
+		if record.AdministeredDate.After(administered) {
+			continue
+		}
+		if previous == nil || record.AdministeredDate.After(previous.AdministeredDate) {
+			previous = record
+		}
+	}
+
+	if previous != nil && administered.Before(schedule.EarliestAfter(previous.AdministeredDate)) {
+		gap := int(administered.Sub(previous.AdministeredDate).Hours() / 24)
+		return fmt.Sprintf("given %d days after %s dose %s, less than the %d-day minimum interval",
+			gap, previous.VaccineName, previous.Dose, schedule.MinIntervalDays)
+	}
+
+	return ""
+}
@@ -0,0 +1,211 @@
+package immunization
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mamacare/services/internal/app/child/registry"
+	"github.com/mamacare/services/internal/domain/model"
+	"github.com/mamacare/services/internal/domain/repository"
+	"github.com/mamacare/services/pkg/errorx"
+	"github.com/mamacare/services/pkg/logger"
+)
+
+// DoseInput contains the details of a vaccine dose given to a child
+type DoseInput struct {
+	VaccineName        string
+	Dose               string
+	AdministeredDate   time.Time
+	AdministeredByID   *uuid.UUID
+	AdministeredByName string
+	FacilityID         uuid.UUID
+	BatchNumber        string
+	Manufacturer       string
+	AdverseReactions   string
+	Notes              string
+}
+
+// DoseResult is a recorded dose and the child's updated status
+type DoseResult struct {
+	Record *model.ImmunizationRecord `json:"record"`
+	Status *Status                   `json:"status"`
+}
+
+// Service tracks children's immunizations against the EPI schedule
+type Service struct {
+	registryService  *registry.Service
+	immunizationRepo repository.ImmunizationRepository
+	facilityRepo     repository.FacilityRepository
+	log              logger.Logger
+}
+
+// NewService creates a new immunization service
+func NewService(
+	registryService *registry.Service,
+	immunizationRepo repository.ImmunizationRepository,
+	facilityRepo repository.FacilityRepository,
+	log logger.Logger,
+) *Service {
+	return &Service{
+		registryService:  registryService,
+		immunizationRepo: immunizationRepo,
+		facilityRepo:     facilityRepo,
+		log:              log,
+	}
+}
+
+// GetStatus returns which vaccines a child has had and which are due, overdue or missing
+func (s *Service) GetStatus(ctx context.Context, requesterID, childID uuid.UUID) (*Status, error) {
+	child, schedules, records, err := s.load(ctx, requesterID, childID)
+	if err != nil {
+		return nil, err
+	}
+	return Evaluate(child, schedules, records, time.Now()), nil
+}
+
+// GetCatchUpPlan returns the visits a child needs to catch up on outstanding doses
+func (s *Service) GetCatchUpPlan(ctx context.Context, requesterID, childID uuid.UUID) (*CatchUpPlan, error) {
+	child, schedules, records, err := s.load(ctx, requesterID, childID)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	return PlanCatchUp(Evaluate(child, schedules, records, now), now), nil
+}
+
+// RecordDose records a vaccine dose given to a child. A dose given before the minimum
+// age or too soon after the previous dose is stored as invalid so it can be repeated.
+func (s *Service) RecordDose(ctx context.Context, requesterID, childID uuid.UUID, input *DoseInput) (*DoseResult, error) {
+	if err := validateDoseInput(input); err != nil {
+		return nil, err
+	}
+
+	child, schedules, records, err := s.load(ctx, requesterID, childID)
+	if err != nil {
+		return nil, err
+	}
+	if !child.IsActive {
+		return nil, errorx.New(errorx.BadRequest, "child record is inactive")
+	}
+	if input.AdministeredDate.Before(child.DateOfBirth) {
+		return nil, errorx.New(errorx.BadRequest, "a dose cannot be given before the child's date of birth")
+	}
+
+	var schedule *model.VaccineSchedule
+	for _, candidate := range schedules {
+		if strings.EqualFold(candidate.VaccineName, input.VaccineName) && strings.EqualFold(candidate.Dose, input.Dose) {
+			schedule = candidate
+			break
+		}
+	}
+	if schedule == nil {
+		return nil, errorx.Newf(errorx.BadRequest, "%s dose %s is not in the vaccine schedule", input.VaccineName, input.Dose)
+	}
+
+	for _, record := range records {
+		if record.IsValid && record.VaccineName == schedule.VaccineName && record.Dose == schedule.Dose {
+			return nil, errorx.Newf(errorx.AlreadyExists, "%s dose %s has already been recorded for this child", schedule.VaccineName, schedule.Dose)
+		}
+	}
+
+	if _, err := s.facilityRepo.GetByID(ctx, input.FacilityID); err != nil {
+		s.log.Error("Failed to find facility", logger.Fields{
+			"error":       err.Error(),
+			"facility_id": input.FacilityID.String(),
+		})
+		return nil, errorx.Wrap(err, "failed to find facility")
+	}
+
+	record := model.NewImmunizationRecord(uuid.New(), child.ID, schedule.VaccineName, schedule.Dose, input.AdministeredDate)
+	record.AdministeredByID = input.AdministeredByID
+	record.AdministeredByName = input.AdministeredByName
+	record.FacilityID = &input.FacilityID
+	record.BatchNumber = strings.TrimSpace(input.BatchNumber)
+	record.Manufacturer = input.Manufacturer
+	record.AdverseReactions = input.AdverseReactions
+	record.Notes = input.Notes
+	if reason := invalidReason(child, schedule, schedules, records, input.AdministeredDate); reason != "" {
+		record.MarkInvalid(reason)
+	}
+
+	if err := s.immunizationRepo.CreateRecord(ctx, record); err != nil {
+		s.log.Error("Failed to create immunization record", logger.Fields{
+			"error":    err.Error(),
+			"child_id": child.ID.String(),
+			"vaccine":  schedule.VaccineName,
+			"dose":     schedule.Dose,
+		})
+		return nil, errorx.Wrap(err, "failed to record dose")
+	}
+
+	if !record.IsValid {
+		s.log.Warn("Invalid vaccine dose recorded", logger.Fields{
+			"child_id": child.ID.String(),
+			"vaccine":  schedule.VaccineName,
+			"dose":     schedule.Dose,
+			"reason":   record.InvalidReason,
+		})
+	}
+
+	records = append(records, record)
+	return &DoseResult{
+		Record: record,
+		Status: Evaluate(child, schedules, records, time.Now()),
+	}, nil
+}
+
+// load retrieves a child the requester may access, the vaccine schedule and the child's
+// doses. A merged child resolves to the record it was merged into.
+func (s *Service) load(
+	ctx context.Context,
+	requesterID, childID uuid.UUID,
+) (*model.Child, []*model.VaccineSchedule, []*model.ImmunizationRecord, error) {
+	child, err := s.registryService.GetChild(ctx, requesterID, childID)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	schedules, err := s.immunizationRepo.GetSchedules(ctx)
+	if err != nil {
+		s.log.Error("Failed to get vaccine schedules", logger.Fields{
+			"error": err.Error(),
+		})
+		return nil, nil, nil, errorx.Wrap(err, "failed to get vaccine schedules")
+	}
+
+	records, err := s.immunizationRepo.GetRecordsByChildID(ctx, child.ID)
+	if err != nil {
+		s.log.Error("Failed to get immunization records", logger.Fields{
+			"error":    err.Error(),
+			"child_id": child.ID.String(),
+		})
+		return nil, nil, nil, errorx.Wrap(err, "failed to get immunization records")
+	}
+
+	return child, schedules, records, nil
+}
+
+// validateDoseInput validates the details of a dose
+func validateDoseInput(input *DoseInput) error {
+	if input == nil {
+		return errorx.New(errorx.BadRequest, "dose details are required")
+	}
+	if strings.TrimSpace(input.VaccineName) == "" || strings.TrimSpace(input.Dose) == "" {
+		return errorx.New(errorx.BadRequest, "vaccine name and dose are required")
+	}
+	if input.AdministeredDate.IsZero() || input.AdministeredDate.After(time.Now()) {
+		return errorx.New(errorx.BadRequest, "administered date must be in the past")
+	}
+	if strings.TrimSpace(input.BatchNumber) == "" {
+		return errorx.New(errorx.BadRequest, "batch number is required")
+	}
+	if input.FacilityID == uuid.Nil {
+		return errorx.New(errorx.BadRequest, "facility is required")
+	}
+	if input.AdministeredByID == nil && strings.TrimSpace(input.AdministeredByName) == "" {
+		return errorx.New(errorx.BadRequest, "the health worker who gave the dose is required")
+	}
+	return nil
+}
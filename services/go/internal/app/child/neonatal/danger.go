@@ -0,0 +1,142 @@
+package neonatal
+
+import (
+	"github.com/mamacare/services/internal/domain/model"
+)
+
+// SOS priorities for newborn referrals. A newborn SOS starts at the priority of its nature
+// and is raised for the signs most likely to end in death on the way to hospital.
+const (
+	priorityUrgent   = 4
+	priorityCritical = 5
+)
+
+// earlyNeonatalDays is the first week of life, when most newborn deaths happen
+const earlyNeonatalDays = 7
+
+// severeSigns are the IMNCI signs of possible serious bacterial infection or very severe
+// disease, which need urgent referral to hospital
+var severeSigns = map[model.NeonatalDangerSign]bool{
+	model.NeonatalSignNotFeeding:        true,
+	model.NeonatalSignConvulsions:       true,
+	model.NeonatalSignLethargy:          true,
+	model.NeonatalSignFastBreathing:     true,
+	model.NeonatalSignChestIndrawing:    true,
+	model.NeonatalSignFever:             true,
+	model.NeonatalSignSevereHypothermia: true,
+	model.NeonatalSignSevereJaundice:    true,
+}
+
+// criticalSigns are the severe signs that raise a referral to the highest priority
+var criticalSigns = map[model.NeonatalDangerSign]bool{
+	model.NeonatalSignNotFeeding:        true,
+	model.NeonatalSignConvulsions:       true,
+	model.NeonatalSignLethargy:          true,
+	model.NeonatalSignSevereHypothermia: true,
+}
+
+// recheckDays is how soon a baby with a moderate sign is seen again: the next day for
+// low temperature and jaundice, which can get worse quickly, and after two days otherwise
+var recheckDays = map[model.NeonatalDangerSign]int{
+	model.NeonatalSignHypothermia:        1,
+	model.NeonatalSignJaundice:           1,
+	model.NeonatalSignPoorFeeding:        2,
+	model.NeonatalSignUmbilicalInfection: 2,
+}
+
+// Assessment is the classification of a newborn check
+type Assessment struct {
+	DangerSigns []model.NeonatalDangerSign `json:"danger_signs"`
+	Severity    model.NeonatalSeverity     `json:"severity"`
+	// Priority is the SOS priority for a severe classification
+	Priority int `json:"priority,omitempty"`
+	// RecheckDays is how soon a moderate classification should be seen again
+	RecheckDays int `json:"recheck_days,omitempty"`
+}
+
+// DangerSigns returns the danger signs found at a check of a baby of the given age
+func DangerSigns(check *model.NeonatalCheck, ageHours float64) []model.NeonatalDangerSign {
+	signs := []model.NeonatalDangerSign{}
+
+	switch check.Feeding {
+	case model.NewbornFeedingNone:
+		signs = append(signs, model.NeonatalSignNotFeeding)
+	case model.NewbornFeedingPoor:
+		signs = append(signs, model.NeonatalSignPoorFeeding)
+	}
+	if check.Convulsions {
+		signs = append(signs, model.NeonatalSignConvulsions)
+	}
+	if check.Lethargic {
+		signs = append(signs, model.NeonatalSignLethargy)
+	}
+	if check.RespiratoryRate != nil && *check.RespiratoryRate >= model.NeonatalFastBreathingRate {
+		signs = append(signs, model.NeonatalSignFastBreathing)
+	}
+	if check.SevereChestIndrawing {
+		signs = append(signs, model.NeonatalSignChestIndrawing)
+	}
+
+	if t := check.TemperatureC; t != nil {
+		switch {
+		case *t >= model.NeonatalFeverC:
+			signs = append(signs, model.NeonatalSignFever)
+		case *t < model.NeonatalSevereHypothermiaC:
+			signs = append(signs, model.NeonatalSignSevereHypothermia)
+		case *t < model.NeonatalHypothermiaC:
+			signs = append(signs, model.NeonatalSignHypothermia)
+		}
+	}
+
+	// Jaundice in the first day of life is never physiological
+	if check.Jaundice == model.JaundicePalmsSoles ||
+		(check.Jaundice != model.JaundiceNone && ageHours < 24) {
+		signs = append(signs, model.NeonatalSignSevereJaundice)
+	} else if check.Jaundice != model.JaundiceNone {
+		signs = append(signs, model.NeonatalSignJaundice)
+	}
+
+	if check.UmbilicalInfection {
+		signs = append(signs, model.NeonatalSignUmbilicalInfection)
+	}
+
+	return signs
+}
+
+// Assess classifies a newborn check. Any severe sign needs urgent referral, as does any
+// sign at all in a very low birth weight baby. Referrals are critical for the most
+// dangerous signs, in the first week of life and for very low birth weight babies.
+func Assess(check *model.NeonatalCheck, ageHours float64, category model.BirthWeightCategory) *Assessment {
+	assessment := &Assessment{
+		DangerSigns: DangerSigns(check, ageHours),
+		Severity:    model.NeonatalSeverityNone,
+	}
+	if len(assessment.DangerSigns) == 0 {
+		return assessment
+	}
+
+	severe := category.IsVeryLow()
+	critical := category.IsVeryLow() || ageHours < earlyNeonatalDays*24
+	for _, sign := range assessment.DangerSigns {
+		severe = severe || severeSigns[sign]
+		critical = critical || criticalSigns[sign]
+	}
+
+	if severe {
+		assessment.Severity = model.NeonatalSeveritySevere
+		assessment.Priority = priorityUrgent
+		if critical {
+			assessment.Priority = priorityCritical
+		}
+		return assessment
+	}
+
+	assessment.Severity = model.NeonatalSeverityModerate
+	for _, sign := range assessment.DangerSigns {
+		if days := recheckDays[sign]; days > 0 && (assessment.RecheckDays == 0 || days < assessment.RecheckDays) {
+			assessment.RecheckDays = days
+		}
+	}
+
+	return assessment
+}
@@ -0,0 +1,82 @@
+package neonatal
+
+import (
+	"math"
+
+	"github.com/mamacare/services/internal/domain/model"
+)
+
+// kmcMinDailyHours is the skin-to-skin contact below which a day counts as intermittent
+// rather than continuous kangaroo mother care
+const kmcMinDailyHours = 8.0
+
+// KMCProgress is how a low birth weight baby is doing in kangaroo mother care
+type KMCProgress struct {
+	InKMC bool `json:"in_kmc"`
+	Days  int  `json:"days"`
+	// MeanDailyHours is the average skin-to-skin contact a day
+	MeanDailyHours float64 `json:"mean_daily_hours"`
+	// ShortDays is how many days had less than the minimum skin-to-skin contact
+	ShortDays         int  `json:"short_days"`
+	LatestWeightGrams *int `json:"latest_weight_grams,omitempty"`
+	// WeightGainGKgDay is the average daily gain per kg since the first weighed day
+	WeightGainGKgDay *float64 `json:"weight_gain_g_kg_day,omitempty"`
+	// ReadyToEnd means the baby has reached 2500 g and KMC can be weaned
+	ReadyToEnd bool     `json:"ready_to_end"`
+	Warnings   []string `json:"warnings"`
+}
+
+// EvaluateKMC assesses a baby's kangaroo mother care from their sessions in date order
+func EvaluateKMC(record *model.NeonatalRecord, sessions []*model.KMCSession) *KMCProgress {
+	progress := &KMCProgress{
+		InKMC:    record.InKMC(),
+		Days:     len(sessions),
+		Warnings: []string{},
+	}
+	if len(sessions) == 0 {
+		return progress
+	}
+
+	var totalHours float64
+	var first, latest *model.KMCSession
+	for _, session := range sessions {
+		totalHours += session.SkinToSkinHours
+		if session.SkinToSkinHours < kmcMinDailyHours {
+			progress.ShortDays++
+		}
+		if session.WeightGrams != nil {
+			if first == nil {
+				first = session
+			}
+			latest = session
+		}
+	}
+	progress.MeanDailyHours = math.Round(totalHours/float64(len(sessions))*10) / 10
+
+	if latest != nil {
+		progress.LatestWeightGrams = latest.WeightGrams
+		progress.ReadyToEnd = *latest.WeightGrams >= model.LowBirthWeightGrams
+
+		if days := latest.Date.Sub(first.Date).Hours() / 24; days > 0 {
+			gain := float64(*latest.WeightGrams - *first.WeightGrams)
+			rate := math.Round(gain/(float64(*first.WeightGrams)/1000)/days*10) / 10
+			progress.WeightGainGKgDay = &rate
+			if rate < 15 && days >= 3 {
+				progress.Warnings = append(progress.Warnings, "gaining less than 15 g/kg a day")
+			}
+		}
+	}
+
+	today := sessions[len(sessions)-1]
+	if today.SkinToSkinHours < kmcMinDailyHours {
+		progress.Warnings = append(progress.Warnings, "less than 8 hours of skin-to-skin contact")
+	}
+	if today.TemperatureC != nil && *today.TemperatureC < model.NeonatalHypothermiaC {
+		progress.Warnings = append(progress.Warnings, "low temperature: check the baby for danger signs")
+	}
+	if !today.ExclusiveBreastfeeding {
+		progress.Warnings = append(progress.Warnings, "not exclusively breastfed")
+	}
+
+	return progress
+}
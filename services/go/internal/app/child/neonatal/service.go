@@ -0,0 +1,868 @@
+package neonatal
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mamacare/services/internal/app/child/registry"
+	"github.com/mamacare/services/internal/app/geo/location"
+	"github.com/mamacare/services/internal/domain/model"
+	"github.com/mamacare/services/internal/domain/repository"
+	"github.com/mamacare/services/pkg/errorx"
+	"github.com/mamacare/services/pkg/logger"
+)
+
+// maxCheckAgeDays is the end of the IMNCI young infant age group, the oldest a baby can
+// be checked for newborn danger signs
+const maxCheckAgeDays = 59
+
+// lowBirthWeightCheck is an extra newborn check for a low birth weight baby
+type lowBirthWeightCheck struct {
+	day        int
+	windowDays int
+}
+
+// lowBirthWeightChecks are the checks a low birth weight baby gets on top of the PNC
+// contacts, so they are seen every other day in the first week and weekly to day 28
+var lowBirthWeightChecks = []lowBirthWeightCheck{
+	{day: 2, windowDays: 1},
+	{day: 5, windowDays: 1},
+	{day: 21, windowDays: 3},
+	{day: model.NeonatalPeriodDays, windowDays: 3},
+}
+
+// ReferralService defines the interface to the SOS flow used to refer sick newborns to a hospital
+type ReferralService interface {
+	// ReportSOSEvent reports a new SOS event
+	ReportSOSEvent(
+		ctx context.Context,
+		motherID uuid.UUID,
+		reportedByID uuid.UUID,
+		lat, lng float64,
+		nature model.SOSEventNature,
+		description string,
+	) (*model.SOSEvent, error)
+
+	// AssignFacilityToSOSEvent assigns the receiving facility to an SOS event
+	AssignFacilityToSOSEvent(ctx context.Context, sosID, facilityID uuid.UUID) (*model.SOSEvent, error)
+
+	// UpdateSOSEventPriority changes the priority of an SOS event
+	UpdateSOSEventPriority(ctx context.Context, sosID uuid.UUID, priority int) (*model.SOSEvent, error)
+}
+
+// BirthCareInput contains the essential newborn care given at birth. Fields left empty
+// keep what was recorded before, so care can be recorded as it happens.
+type BirthCareInput struct {
+	// FacilityID is the facility responsible for the baby's newborn checks
+	FacilityID               uuid.UUID
+	Apgar1Min                *int
+	Apgar5Min                *int
+	Apgar10Min               *int
+	BirthWeightGrams         *int
+	BreastfeedingInitiatedAt *time.Time
+	CordCare                 model.CordCare
+	KMCStartedAt             *time.Time
+	Notes                    string
+}
+
+// BirthCareResult is a neonatal record with any checks it scheduled
+type BirthCareResult struct {
+	Record *model.NeonatalRecord `json:"record"`
+	// ScheduledChecks are the extra newborn checks for a low birth weight baby
+	ScheduledChecks []*model.Visit `json:"scheduled_checks"`
+}
+
+// KMCSessionInput contains a day of kangaroo mother care
+type KMCSessionInput struct {
+	Date                   time.Time
+	SkinToSkinHours        float64
+	TemperatureC           *float64
+	WeightGrams            *int
+	ExclusiveBreastfeeding bool
+	Notes                  string
+}
+
+// CheckInput contains a newborn examined for danger signs
+type CheckInput struct {
+	FacilityID           uuid.UUID
+	VisitID              *uuid.UUID
+	CheckedAt            time.Time
+	TemperatureC         *float64
+	RespiratoryRate      *int
+	WeightGrams          *int
+	Feeding              model.NewbornFeeding
+	Jaundice             model.JaundiceExtent
+	Convulsions          bool
+	Lethargic            bool
+	SevereChestIndrawing bool
+	UmbilicalInfection   bool
+	// Latitude and Longitude are where the baby is, used for the referral instead of the facility
+	Latitude  *float64
+	Longitude *float64
+	Notes     string
+}
+
+// CheckResult is a newborn check with the referral or follow-up it raised
+type CheckResult struct {
+	Check      *model.NeonatalCheck `json:"check"`
+	Assessment *Assessment          `json:"assessment"`
+	Referral   *model.SOSEvent      `json:"referral,omitempty"`
+	FollowUp   *model.Visit         `json:"follow_up,omitempty"`
+}
+
+// Summary is a child's newborn care
+type Summary struct {
+	Record      *model.NeonatalRecord  `json:"record,omitempty"`
+	KMCSessions []*model.KMCSession    `json:"kmc_sessions"`
+	KMC         *KMCProgress           `json:"kmc,omitempty"`
+	Checks      []*model.NeonatalCheck `json:"checks"`
+}
+
+// Service manages newborn care: the care given at birth, kangaroo mother care for low
+// birth weight babies, and newborn checks that refer babies with danger signs
+type Service struct {
+	registryService *registry.Service
+	neonatalRepo    repository.NeonatalRepository
+	deliveryRepo    repository.DeliveryOutcomeRepository
+	motherRepo      repository.MotherRepository
+	visitRepo       repository.VisitRepository
+	userRepo        repository.UserRepository
+	facilityRepo    repository.FacilityRepository
+	referralService ReferralService
+	locationService *location.Service
+	log             logger.Logger
+}
+
+// NewService creates a new neonatal care service
+func NewService(
+	registryService *registry.Service,
+	neonatalRepo repository.NeonatalRepository,
+	deliveryRepo repository.DeliveryOutcomeRepository,
+	motherRepo repository.MotherRepository,
+	visitRepo repository.VisitRepository,
+	userRepo repository.UserRepository,
+	facilityRepo repository.FacilityRepository,
+	referralService ReferralService,
+	locationService *location.Service,
+	log logger.Logger,
+) *Service {
+	return &Service{
+		registryService: registryService,
+		neonatalRepo:    neonatalRepo,
+		deliveryRepo:    deliveryRepo,
+		motherRepo:      motherRepo,
+		visitRepo:       visitRepo,
+		userRepo:        userRepo,
+		facilityRepo:    facilityRepo,
+		referralService: referralService,
+		locationService: locationService,
+		log:             log,
+	}
+}
+
+// RecordBirthCare records the care a newborn was given at birth. The first time a baby
+// is found to be low birth weight, their extra newborn checks are scheduled.
+func (s *Service) RecordBirthCare(ctx context.Context, requesterID, childID uuid.UUID, input *BirthCareInput) (*BirthCareResult, error) {
+	if err := validateBirthCare(input); err != nil {
+		return nil, err
+	}
+	if _, err := s.requireHealthWorker(ctx, requesterID); err != nil {
+		return nil, err
+	}
+
+	child, err := s.registryService.GetChild(ctx, requesterID, childID)
+	if err != nil {
+		return nil, err
+	}
+	birth, err := s.birthTime(ctx, child)
+	if err != nil {
+		return nil, err
+	}
+
+	record, err := s.neonatalRepo.GetRecordByChildID(ctx, child.ID)
+	isNew := err != nil && errorx.IsType(err, errorx.NotFound)
+	if err != nil && !isNew {
+		s.log.Error("Failed to get neonatal record", logger.Fields{
+			"error":    err.Error(),
+			"child_id": child.ID.String(),
+		})
+		return nil, errorx.Wrap(err, "failed to get neonatal record")
+	}
+	if isNew {
+		record = model.NewNeonatalRecord(child.ID, requesterID)
+	}
+	wasLow := record.KMCEligible()
+
+	if input.Apgar1Min != nil {
+		record.Apgar1Min = input.Apgar1Min
+	}
+	if input.Apgar5Min != nil {
+		record.Apgar5Min = input.Apgar5Min
+	}
+	if input.Apgar10Min != nil {
+		record.Apgar10Min = input.Apgar10Min
+	}
+	if input.BirthWeightGrams != nil {
+		record.SetBirthWeight(*input.BirthWeightGrams)
+	} else if record.BirthWeightGrams == nil && child.BirthWeightGrams != nil {
+		record.SetBirthWeight(*child.BirthWeightGrams)
+	}
+	if input.BreastfeedingInitiatedAt != nil {
+		if input.BreastfeedingInitiatedAt.Before(birth) {
+			return nil, errorx.New(errorx.BadRequest, "breastfeeding cannot start before birth")
+		}
+		record.BreastfeedingInitiatedAt = input.BreastfeedingInitiatedAt
+		record.EarlyBreastfeeding = input.BreastfeedingInitiatedAt.Sub(birth) <= time.Hour
+	}
+	if input.CordCare != "" {
+		record.CordCare = input.CordCare
+	}
+	if input.KMCStartedAt != nil && record.KMCStartedAt == nil {
+		if !record.KMCEligible() {
+			return nil, errorx.New(errorx.BadRequest, "kangaroo mother care is for low birth weight babies")
+		}
+		record.KMCStartedAt = input.KMCStartedAt
+	}
+	if input.Notes != "" {
+		record.Notes = input.Notes
+	}
+	record.UpdatedAt = time.Now()
+
+	if isNew {
+		err = s.neonatalRepo.CreateRecord(ctx, record)
+	} else {
+		err = s.neonatalRepo.UpdateRecord(ctx, record)
+	}
+	if err != nil {
+		s.log.Error("Failed to save neonatal record", logger.Fields{
+			"error":    err.Error(),
+			"child_id": child.ID.String(),
+		})
+		return nil, errorx.Wrap(err, "failed to save neonatal record")
+	}
+
+	result := &BirthCareResult{
+		Record:          record,
+		ScheduledChecks: []*model.Visit{},
+	}
+	if record.KMCEligible() && !wasLow {
+		visits, err := s.scheduleLowBirthWeightChecks(ctx, child, birth, input.FacilityID)
+		if err != nil {
+			return nil, err
+		}
+		result.ScheduledChecks = visits
+	}
+
+	s.log.Info("Newborn care recorded", logger.Fields{
+		"child_id":              child.ID.String(),
+		"birth_weight_category": string(record.BirthWeightCategory),
+		"early_breastfeeding":   record.EarlyBreastfeeding,
+		"low_apgar":             record.LowApgar(),
+		"scheduled_checks":      len(result.ScheduledChecks),
+	})
+
+	return result, nil
+}
+
+// RecordKMCSession records a day of kangaroo mother care for a low birth weight baby,
+// starting their KMC if it had not been started
+func (s *Service) RecordKMCSession(ctx context.Context, requesterID, childID uuid.UUID, input *KMCSessionInput) (*KMCProgress, error) {
+	if input == nil || input.SkinToSkinHours < 0 || input.SkinToSkinHours > 24 {
+		return nil, errorx.New(errorx.BadRequest, "skin-to-skin hours must be between 0 and 24")
+	}
+	if input.Date.IsZero() || input.Date.After(time.Now()) {
+		return nil, errorx.New(errorx.BadRequest, "KMC date must not be in the future")
+	}
+	if input.WeightGrams != nil && (*input.WeightGrams < 300 || *input.WeightGrams > 7000) {
+		return nil, errorx.New(errorx.BadRequest, "weight must be between 300 and 7000 grams")
+	}
+	if _, err := s.requireHealthWorker(ctx, requesterID); err != nil {
+		return nil, err
+	}
+
+	child, err := s.registryService.GetChild(ctx, requesterID, childID)
+	if err != nil {
+		return nil, err
+	}
+	record, err := s.getRecord(ctx, child.ID)
+	if err != nil {
+		return nil, err
+	}
+	if !record.KMCEligible() {
+		return nil, errorx.New(errorx.BadRequest, "kangaroo mother care is for low birth weight babies")
+	}
+	if record.KMCEndedAt != nil {
+		return nil, errorx.New(errorx.BadRequest, "kangaroo mother care has already ended")
+	}
+
+	date := time.Date(input.Date.Year(), input.Date.Month(), input.Date.Day(), 0, 0, 0, 0, time.UTC)
+	if record.KMCStartedAt == nil {
+		record.KMCStartedAt = &date
+		record.UpdatedAt = time.Now()
+		if err := s.neonatalRepo.UpdateRecord(ctx, record); err != nil {
+			s.log.Error("Failed to start kangaroo mother care", logger.Fields{
+				"error":    err.Error(),
+				"child_id": child.ID.String(),
+			})
+			return nil, errorx.Wrap(err, "failed to start kangaroo mother care")
+		}
+	}
+
+	session := model.NewKMCSession(child.ID, date, input.SkinToSkinHours, requesterID)
+	session.TemperatureC = input.TemperatureC
+	session.WeightGrams = input.WeightGrams
+	session.ExclusiveBreastfeeding = input.ExclusiveBreastfeeding
+	session.Notes = input.Notes
+	if err := s.neonatalRepo.SaveKMCSession(ctx, session); err != nil {
+		s.log.Error("Failed to save KMC session", logger.Fields{
+			"error":    err.Error(),
+			"child_id": child.ID.String(),
+		})
+		return nil, errorx.Wrap(err, "failed to save KMC session")
+	}
+
+	sessions, err := s.getKMCSessions(ctx, child.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return EvaluateKMC(record, sessions), nil
+}
+
+// EndKMC records that a baby's kangaroo mother care has ended
+func (s *Service) EndKMC(ctx context.Context, requesterID, childID uuid.UUID, endedAt time.Time, reason string) (*model.NeonatalRecord, error) {
+	if strings.TrimSpace(reason) == "" {
+		return nil, errorx.New(errorx.BadRequest, "a reason is required to end kangaroo mother care")
+	}
+	if _, err := s.requireHealthWorker(ctx, requesterID); err != nil {
+		return nil, err
+	}
+
+	child, err := s.registryService.GetChild(ctx, requesterID, childID)
+	if err != nil {
+		return nil, err
+	}
+	record, err := s.getRecord(ctx, child.ID)
+	if err != nil {
+		return nil, err
+	}
+	if !record.InKMC() {
+		return nil, errorx.New(errorx.BadRequest, "baby is not in kangaroo mother care")
+	}
+	if endedAt.Before(*record.KMCStartedAt) {
+		return nil, errorx.New(errorx.BadRequest, "kangaroo mother care cannot end before it started")
+	}
+
+	record.KMCEndedAt = &endedAt
+	record.KMCEndReason = reason
+	record.UpdatedAt = time.Now()
+	if err := s.neonatalRepo.UpdateRecord(ctx, record); err != nil {
+		s.log.Error("Failed to end kangaroo mother care", logger.Fields{
+			"error":    err.Error(),
+			"child_id": child.ID.String(),
+		})
+		return nil, errorx.Wrap(err, "failed to end kangaroo mother care")
+	}
+
+	return record, nil
+}
+
+// RecordCheck records a newborn check and acts on the danger signs it found. Severe signs
+// raise a newborn SOS sent to the nearest hospital; moderate signs schedule a recheck.
+func (s *Service) RecordCheck(ctx context.Context, requesterID, childID uuid.UUID, input *CheckInput) (*CheckResult, error) {
+	if err := validateCheck(input); err != nil {
+		return nil, err
+	}
+	if _, err := s.requireHealthWorker(ctx, requesterID); err != nil {
+		return nil, err
+	}
+
+	child, err := s.registryService.GetChild(ctx, requesterID, childID)
+	if err != nil {
+		return nil, err
+	}
+	birth, err := s.birthTime(ctx, child)
+	if err != nil {
+		return nil, err
+	}
+	if input.CheckedAt.Before(birth) {
+		return nil, errorx.New(errorx.BadRequest, "check cannot be before birth")
+	}
+	ageHours := input.CheckedAt.Sub(birth).Hours()
+	if ageHours >= (maxCheckAgeDays+1)*24 {
+		return nil, errorx.Newf(errorx.BadRequest, "newborn checks are for babies up to %d days old", maxCheckAgeDays)
+	}
+
+	facility, err := s.facilityRepo.GetByID(ctx, input.FacilityID)
+	if err != nil {
+		s.log.Error("Failed to find facility", logger.Fields{
+			"error":       err.Error(),
+			"facility_id": input.FacilityID.String(),
+		})
+		return nil, errorx.Wrap(err, "failed to find facility")
+	}
+
+	category, err := s.birthWeightCategory(ctx, child)
+	if err != nil {
+		return nil, err
+	}
+
+	check := model.NewNeonatalCheck(child.ID, facility.ID, requesterID, input.CheckedAt)
+	check.VisitID = input.VisitID
+	check.TemperatureC = input.TemperatureC
+	check.RespiratoryRate = input.RespiratoryRate
+	check.WeightGrams = input.WeightGrams
+	check.Feeding = input.Feeding
+	check.Jaundice = input.Jaundice
+	check.Convulsions = input.Convulsions
+	check.Lethargic = input.Lethargic
+	check.SevereChestIndrawing = input.SevereChestIndrawing
+	check.UmbilicalInfection = input.UmbilicalInfection
+	check.Notes = input.Notes
+
+	assessment := Assess(check, ageHours, category)
+	check.DangerSigns = assessment.DangerSigns
+	check.Severity = assessment.Severity
+
+	if err := s.neonatalRepo.CreateCheck(ctx, check); err != nil {
+		s.log.Error("Failed to save newborn check", logger.Fields{
+			"error":    err.Error(),
+			"child_id": child.ID.String(),
+		})
+		return nil, errorx.Wrap(err, "failed to save newborn check")
+	}
+
+	result := &CheckResult{
+		Check:      check,
+		Assessment: assessment,
+	}
+	if check.Severity == model.NeonatalSeverityNone {
+		return result, nil
+	}
+
+	motherID, err := s.motherID(ctx, child)
+	if err != nil {
+		return nil, err
+	}
+
+	switch check.Severity {
+	case model.NeonatalSeveritySevere:
+		lat, lng := facility.Location.Latitude, facility.Location.Longitude
+		if input.Latitude != nil && input.Longitude != nil {
+			lat, lng = *input.Latitude, *input.Longitude
+		}
+		sosEvent, err := s.refer(ctx, check, assessment, motherID, facility, lat, lng)
+		if err != nil {
+			return nil, err
+		}
+		check.SOSEventID = &sosEvent.ID
+		result.Referral = sosEvent
+	case model.NeonatalSeverityModerate:
+		visit, err := s.scheduleRecheck(ctx, check, assessment, motherID)
+		if err != nil {
+			return nil, err
+		}
+		check.FollowUpVisitID = &visit.ID
+		result.FollowUp = visit
+	}
+
+	if err := s.neonatalRepo.UpdateCheck(ctx, check); err != nil {
+		s.log.Error("Failed to link newborn check to its follow-up", logger.Fields{
+			"error":    err.Error(),
+			"check_id": check.ID.String(),
+		})
+		return nil, errorx.Wrap(err, "failed to update newborn check")
+	}
+
+	return result, nil
+}
+
+// GetSummary retrieves a child's newborn care
+func (s *Service) GetSummary(ctx context.Context, requesterID, childID uuid.UUID) (*Summary, error) {
+	child, err := s.registryService.GetChild(ctx, requesterID, childID)
+	if err != nil {
+		return nil, err
+	}
+
+	summary := &Summary{}
+	record, err := s.neonatalRepo.GetRecordByChildID(ctx, child.ID)
+	if err != nil && !errorx.IsType(err, errorx.NotFound) {
+		s.log.Error("Failed to get neonatal record", logger.Fields{
+			"error":    err.Error(),
+			"child_id": child.ID.String(),
+		})
+		return nil, errorx.Wrap(err, "failed to get neonatal record")
+	}
+	if err == nil {
+		summary.Record = record
+	}
+
+	summary.KMCSessions, err = s.getKMCSessions(ctx, child.ID)
+	if err != nil {
+		return nil, err
+	}
+	if summary.Record != nil && summary.Record.KMCEligible() {
+		summary.KMC = EvaluateKMC(summary.Record, summary.KMCSessions)
+	}
+
+	checks, err := s.neonatalRepo.GetChecksByChildID(ctx, child.ID)
+	if err != nil {
+		s.log.Error("Failed to get newborn checks", logger.Fields{
+			"error":    err.Error(),
+			"child_id": child.ID.String(),
+		})
+		return nil, errorx.Wrap(err, "failed to get newborn checks")
+	}
+	summary.Checks = checks
+	if summary.Checks == nil {
+		summary.Checks = []*model.NeonatalCheck{}
+	}
+	if summary.KMCSessions == nil {
+		summary.KMCSessions = []*model.KMCSession{}
+	}
+
+	return summary, nil
+}
+
+// refer raises a newborn SOS for a severe check, sends it to the nearest hospital and
+// raises its priority for critical signs
+func (s *Service) refer(
+	ctx context.Context,
+	check *model.NeonatalCheck,
+	assessment *Assessment,
+	motherID uuid.UUID,
+	facility *model.HealthcareFacility,
+	lat, lng float64,
+) (*model.SOSEvent, error) {
+	if s.referralService == nil {
+		return nil, errorx.New(errorx.InternalServerError, "referral service not configured")
+	}
+
+	description := fmt.Sprintf("Newborn danger signs found at %s: %s", facility.Name, describeSigns(assessment.DangerSigns))
+
+	sosEvent, err := s.referralService.ReportSOSEvent(ctx, motherID, check.CheckedByID, lat, lng, model.SOSEventNatureNewborn, description)
+	if err != nil {
+		s.log.Error("Failed to report newborn SOS", logger.Fields{
+			"error":    err.Error(),
+			"child_id": check.ChildID.String(),
+			"check_id": check.ID.String(),
+		})
+		return nil, errorx.Wrap(err, "failed to report newborn SOS")
+	}
+
+	if assessment.Priority > sosEvent.Priority {
+		if updated, err := s.referralService.UpdateSOSEventPriority(ctx, sosEvent.ID, assessment.Priority); err != nil {
+			s.log.Warn("Failed to raise newborn SOS priority", logger.Fields{
+				"error":    err.Error(),
+				"sos_id":   sosEvent.ID.String(),
+				"priority": assessment.Priority,
+			})
+		} else {
+			sosEvent = updated
+		}
+	}
+
+	// Sick newborns need a hospital with inpatient care rather than the PHU that found them
+	if hospital := s.nearestHospital(ctx, lat, lng); hospital != nil && hospital.ID != facility.ID {
+		if updated, err := s.referralService.AssignFacilityToSOSEvent(ctx, sosEvent.ID, hospital.ID); err != nil {
+			s.log.Warn("Failed to assign hospital to newborn SOS", logger.Fields{
+				"error":       err.Error(),
+				"sos_id":      sosEvent.ID.String(),
+				"facility_id": hospital.ID.String(),
+			})
+		} else {
+			sosEvent = updated
+		}
+	}
+
+	s.log.Info("Newborn referred for danger signs", logger.Fields{
+		"child_id": check.ChildID.String(),
+		"check_id": check.ID.String(),
+		"sos_id":   sosEvent.ID.String(),
+		"priority": sosEvent.Priority,
+	})
+
+	return sosEvent, nil
+}
+
+// scheduleRecheck schedules a follow-up check for moderate danger signs
+func (s *Service) scheduleRecheck(ctx context.Context, check *model.NeonatalCheck, assessment *Assessment, motherID uuid.UUID) (*model.Visit, error) {
+	days := assessment.RecheckDays
+	if days == 0 {
+		days = 2
+	}
+	scheduled := check.CheckedAt.AddDate(0, 0, days)
+
+	visit := model.NewVisit(uuid.New(), motherID, check.FacilityID, scheduled, model.VisitTypePostnatal).
+		WithChild(check.ChildID).
+		WithDueBy(scheduled.AddDate(0, 0, 1)).
+		WithNotes("Newborn recheck for " + describeSigns(assessment.DangerSigns))
+
+	if err := s.visitRepo.Create(ctx, visit); err != nil {
+		s.log.Error("Failed to schedule newborn recheck", logger.Fields{
+			"error":    err.Error(),
+			"child_id": check.ChildID.String(),
+		})
+		return nil, errorx.Wrap(err, "failed to schedule newborn recheck")
+	}
+
+	return visit, nil
+}
+
+// scheduleLowBirthWeightChecks creates the extra newborn checks for a low birth weight
+// baby, skipping any already scheduled or whose window has closed
+func (s *Service) scheduleLowBirthWeightChecks(ctx context.Context, child *model.Child, birth time.Time, facilityID uuid.UUID) ([]*model.Visit, error) {
+	motherID, err := s.motherID(ctx, child)
+	if err != nil {
+		return nil, err
+	}
+
+	options := repository.NewVisitQueryOptions().
+		WithType(model.VisitTypePostnatal).
+		WithDateRange(birth, birth.AddDate(0, 0, model.NeonatalPeriodDays+3))
+	existingVisits, err := s.visitRepo.GetByMotherID(ctx, motherID, options)
+	if err != nil {
+		s.log.Error("Failed to get existing postnatal visits", logger.Fields{
+			"error":     err.Error(),
+			"mother_id": motherID.String(),
+		})
+		return nil, errorx.Wrap(err, "failed to get existing postnatal visits")
+	}
+	existing := make(map[string]bool, len(existingVisits))
+	for _, visit := range existingVisits {
+		if visit.ChildID != nil && *visit.ChildID == child.ID {
+			existing[visit.VisitNotes] = true
+		}
+	}
+
+	now := time.Now()
+	visits := []*model.Visit{}
+	for _, lbwCheck := range lowBirthWeightChecks {
+		notes := fmt.Sprintf("Low birth weight newborn check on day %d", lbwCheck.day)
+		scheduled := birth.AddDate(0, 0, lbwCheck.day)
+		dueBy := scheduled.AddDate(0, 0, lbwCheck.windowDays)
+		if existing[notes] || dueBy.Before(now) {
+			continue
+		}
+		if scheduled.Before(now) {
+			scheduled = now
+		}
+
+		visit := model.NewVisit(uuid.New(), motherID, facilityID, scheduled, model.VisitTypePostnatal).
+			WithChild(child.ID).
+			WithDueBy(dueBy).
+			WithNotes(notes)
+		if err := s.visitRepo.Create(ctx, visit); err != nil {
+			s.log.Error("Failed to schedule low birth weight newborn check", logger.Fields{
+				"error":    err.Error(),
+				"child_id": child.ID.String(),
+				"day":      lbwCheck.day,
+			})
+			return nil, errorx.Wrap(err, "failed to schedule newborn check")
+		}
+		visits = append(visits, visit)
+	}
+
+	return visits, nil
+}
+
+// nearestHospital finds the hospital closest to a location
+func (s *Service) nearestHospital(ctx context.Context, lat, lng float64) *model.HealthcareFacility {
+	hospitals, err := s.facilityRepo.FindByType(ctx, model.FacilityTypeHospital)
+	if err != nil {
+		s.log.Warn("Failed to find hospitals for newborn referral", logger.Fields{
+			"error": err.Error(),
+		})
+		return nil
+	}
+
+	var nearest *model.HealthcareFacility
+	best := math.MaxFloat64
+	for _, hospital := range hospitals {
+		distance := s.locationService.CalculateDistance(
+			lat, lng,
+			hospital.Location.Latitude, hospital.Location.Longitude,
+		)
+		if distance < best {
+			best = distance
+			nearest = hospital
+		}
+	}
+	return nearest
+}
+
+// birthTime returns when a child was born, from their delivery outcome if they have one
+func (s *Service) birthTime(ctx context.Context, child *model.Child) (time.Time, error) {
+	if child.DeliveryOutcomeID == nil {
+		return child.DateOfBirth, nil
+	}
+	outcome, err := s.deliveryRepo.GetByID(ctx, *child.DeliveryOutcomeID)
+	if err != nil {
+		s.log.Error("Failed to find delivery outcome", logger.Fields{
+			"error":      err.Error(),
+			"outcome_id": child.DeliveryOutcomeID.String(),
+		})
+		return time.Time{}, errorx.Wrap(err, "failed to find delivery outcome")
+	}
+	return outcome.DeliveryDate, nil
+}
+
+// motherID returns the mother record a child's visits and referrals are made under
+func (s *Service) motherID(ctx context.Context, child *model.Child) (uuid.UUID, error) {
+	if child.DeliveryOutcomeID != nil {
+		outcome, err := s.deliveryRepo.GetByID(ctx, *child.DeliveryOutcomeID)
+		if err != nil {
+			s.log.Error("Failed to find delivery outcome", logger.Fields{
+				"error":      err.Error(),
+				"outcome_id": child.DeliveryOutcomeID.String(),
+			})
+			return uuid.Nil, errorx.Wrap(err, "failed to find delivery outcome")
+		}
+		return outcome.MotherID, nil
+	}
+	if child.MotherID == nil {
+		return uuid.Nil, errorx.New(errorx.BadRequest, "child is not linked to a mother")
+	}
+
+	mother, err := s.motherRepo.FindByUserID(ctx, *child.MotherID)
+	if err != nil {
+		s.log.Error("Failed to find mother", logger.Fields{
+			"error":   err.Error(),
+			"user_id": child.MotherID.String(),
+		})
+		return uuid.Nil, errorx.Wrap(err, "failed to find mother")
+	}
+	return mother.ID, nil
+}
+
+// birthWeightCategory returns a child's birth weight category, from their neonatal record
+// or else their registered birth weight
+func (s *Service) birthWeightCategory(ctx context.Context, child *model.Child) (model.BirthWeightCategory, error) {
+	record, err := s.neonatalRepo.GetRecordByChildID(ctx, child.ID)
+	if err == nil && record.BirthWeightCategory != "" {
+		return record.BirthWeightCategory, nil
+	}
+	if err != nil && !errorx.IsType(err, errorx.NotFound) {
+		s.log.Error("Failed to get neonatal record", logger.Fields{
+			"error":    err.Error(),
+			"child_id": child.ID.String(),
+		})
+		return "", errorx.Wrap(err, "failed to get neonatal record")
+	}
+	if child.BirthWeightGrams != nil {
+		return model.ClassifyBirthWeight(*child.BirthWeightGrams), nil
+	}
+	return "", nil
+}
+
+// getRecord gets a child's neonatal record
+func (s *Service) getRecord(ctx context.Context, childID uuid.UUID) (*model.NeonatalRecord, error) {
+	record, err := s.neonatalRepo.GetRecordByChildID(ctx, childID)
+	if err != nil {
+		if errorx.IsType(err, errorx.NotFound) {
+			return nil, errorx.New(errorx.BadRequest, "newborn care has not been recorded for this child")
+		}
+		s.log.Error("Failed to get neonatal record", logger.Fields{
+			"error":    err.Error(),
+			"child_id": childID.String(),
+		})
+		return nil, errorx.Wrap(err, "failed to get neonatal record")
+	}
+	return record, nil
+}
+
+// getKMCSessions gets a child's kangaroo mother care in date order
+func (s *Service) getKMCSessions(ctx context.Context, childID uuid.UUID) ([]*model.KMCSession, error) {
+	sessions, err := s.neonatalRepo.GetKMCSessions(ctx, childID)
+	if err != nil {
+		s.log.Error("Failed to get KMC sessions", logger.Fields{
+			"error":    err.Error(),
+			"child_id": childID.String(),
+		})
+		return nil, errorx.Wrap(err, "failed to get KMC sessions")
+	}
+	return sessions, nil
+}
+
+// requireHealthWorker checks the requester is a CHW, clinician or admin
+func (s *Service) requireHealthWorker(ctx context.Context, requesterID uuid.UUID) (*model.User, error) {
+	requester, err := s.userRepo.GetByID(ctx, requesterID)
+	if err != nil {
+		s.log.Error("Failed to find requester", logger.Fields{
+			"error":   err.Error(),
+			"user_id": requesterID.String(),
+		})
+		return nil, errorx.Wrap(err, "failed to find requester")
+	}
+	if requester.Role != model.RoleCHW && requester.Role != model.RoleClinician && requester.Role != model.RoleAdmin {
+		return nil, errorx.New(errorx.Forbidden, "only health workers can record newborn care")
+	}
+	return requester, nil
+}
+
+// validateBirthCare checks the birth care details before anything is saved
+func validateBirthCare(input *BirthCareInput) error {
+	if input == nil {
+		return errorx.New(errorx.BadRequest, "newborn care details are required")
+	}
+	if input.FacilityID == uuid.Nil {
+		return errorx.New(errorx.BadRequest, "facility is required")
+	}
+	for _, apgar := range []*int{input.Apgar1Min, input.Apgar5Min, input.Apgar10Min} {
+		if apgar != nil && (*apgar < 0 || *apgar > 10) {
+			return errorx.New(errorx.BadRequest, "APGAR scores must be between 0 and 10")
+		}
+	}
+	if input.BirthWeightGrams != nil && (*input.BirthWeightGrams < 300 || *input.BirthWeightGrams > 7000) {
+		return errorx.New(errorx.BadRequest, "birth weight must be between 300 and 7000 grams")
+	}
+	if input.CordCare != "" && !input.CordCare.IsValid() {
+		return errorx.Newf(errorx.BadRequest, "invalid cord care: %s", input.CordCare)
+	}
+	return nil
+}
+
+// validateCheck checks a newborn check before anything is saved
+func validateCheck(input *CheckInput) error {
+	if input == nil {
+		return errorx.New(errorx.BadRequest, "check details are required")
+	}
+	if input.FacilityID == uuid.Nil {
+		return errorx.New(errorx.BadRequest, "facility is required")
+	}
+	if input.CheckedAt.IsZero() || input.CheckedAt.After(time.Now()) {
+		return errorx.New(errorx.BadRequest, "check time must not be in the future")
+	}
+	if !input.Feeding.IsValid() {
+		return errorx.Newf(errorx.BadRequest, "invalid feeding status: %s", input.Feeding)
+	}
+	if !input.Jaundice.IsValid() {
+		return errorx.Newf(errorx.BadRequest, "invalid jaundice extent: %s", input.Jaundice)
+	}
+	if input.TemperatureC != nil && (*input.TemperatureC < 30 || *input.TemperatureC > 43) {
+		return errorx.New(errorx.BadRequest, "temperature must be between 30 and 43 C")
+	}
+	if input.RespiratoryRate != nil && (*input.RespiratoryRate < 0 || *input.RespiratoryRate > 150) {
+		return errorx.New(errorx.BadRequest, "respiratory rate must be between 0 and 150 breaths a minute")
+	}
+	if input.WeightGrams != nil && (*input.WeightGrams < 300 || *input.WeightGrams > 7000) {
+		return errorx.New(errorx.BadRequest, "weight must be between 300 and 7000 grams")
+	}
+	if (input.Latitude == nil) != (input.Longitude == nil) {
+		return errorx.New(errorx.BadRequest, "latitude and longitude must be given together")
+	}
+	return nil
+}
+
+// describeSigns lists danger signs in words
+func describeSigns(signs []model.NeonatalDangerSign) string {
+	words := make([]string, 0, len(signs))
+	for _, sign := range signs {
+		words = append(words, strings.ReplaceAll(string(sign), "_", " "))
+	}
+	return strings.Join(words, ", ")
+}
@@ -0,0 +1,58 @@
+package registry
+
+import (
+	"context"
+
+	"github.com/mamacare/services/internal/domain/model"
+	"github.com/mamacare/services/pkg/errorx"
+	"github.com/mamacare/services/pkg/logger"
+)
+
+// canAccess applies the children table's row-level security policies: parents manage
+// their own children, CHWs manage children whose mother lives in their assigned area,
+// and clinicians and admins manage every child
+func (s *Service) canAccess(ctx context.Context, user *model.User, child *model.Child) (bool, error) {
+	switch user.Role {
+	case model.RoleAdmin, model.RoleClinician:
+		return true, nil
+	case model.RoleCHW:
+		if child.MotherID == nil || user.AssignedArea == "" {
+			return false, nil
+		}
+		mother, err := s.userRepo.GetByID(ctx, *child.MotherID)
+		if err != nil {
+			if errorx.IsType(err, errorx.NotFound) {
+				return false, nil
+			}
+			s.log.Error("Failed to find child's mother", logger.Fields{
+				"error":    err.Error(),
+				"child_id": child.ID.String(),
+			})
+			return false, errorx.Wrap(err, "failed to find child's mother")
+		}
+		return mother.AssignedArea == user.AssignedArea, nil
+	default:
+		return child.IsParent(user.ID), nil
+	}
+}
+
+// authorize checks that the user may access the child
+func (s *Service) authorize(ctx context.Context, user *model.User, child *model.Child) error {
+	allowed, err := s.canAccess(ctx, user, child)
+	if err != nil {
+		return err
+	}
+	if !allowed {
+		return errorx.New(errorx.Forbidden, "not allowed to access this child")
+	}
+	return nil
+}
+
+// authorizeRegistryMaintenance checks that the user may deactivate and merge records,
+// which affect a child's whole history and are kept to clinicians and admins
+func authorizeRegistryMaintenance(user *model.User) error {
+	if user.Role != model.RoleClinician && user.Role != model.RoleAdmin {
+		return errorx.New(errorx.Forbidden, "only clinicians and admins can deactivate or merge child records")
+	}
+	return nil
+}
@@ -0,0 +1,538 @@
+package registry
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mamacare/services/internal/domain/model"
+	"github.com/mamacare/services/internal/domain/repository"
+	"github.com/mamacare/services/pkg/errorx"
+	"github.com/mamacare/services/pkg/logger"
+)
+
+// duplicateWindow is how far apart two dates of birth can be for the records to be
+// considered the same child
+const duplicateWindow = 3 * 24 * time.Hour
+
+// ChildInput contains the details for registering a child
+type ChildInput struct {
+	FirstName           string
+	MiddleName          string
+	LastName            string
+	Sex                 string
+	DateOfBirth         time.Time
+	BirthWeightGrams    int
+	BirthLengthCm       int
+	PlaceOfBirth        string
+	DeliveryType        model.ChildDeliveryType
+	GestationalAgeWeeks int
+	MotherID            *uuid.UUID // the mother's user ID
+	FatherID            *uuid.UUID // the father's user ID
+	FatherName          string
+}
+
+// NewbornNames names a newborn when registering from a delivery outcome
+type NewbornNames struct {
+	NewbornID  uuid.UUID
+	FirstName  string
+	MiddleName string
+	LastName   string
+}
+
+// ParentLinkInput links a child to their parents; nil or empty fields are left unchanged
+type ParentLinkInput struct {
+	MotherID   *uuid.UUID
+	FatherID   *uuid.UUID
+	FatherName string
+}
+
+// RegistrationResult is a registered child and any existing records that may be the same child
+type RegistrationResult struct {
+	Child              *model.Child   `json:"child"`
+	PossibleDuplicates []*model.Child `json:"possible_duplicates"`
+}
+
+// DeliveryRegistrationResult is the children registered from a delivery outcome
+type DeliveryRegistrationResult struct {
+	Children          []*model.Child `json:"children"`
+	AlreadyRegistered int            `json:"already_registered"`
+}
+
+// Service manages the child registry
+type Service struct {
+	childRepo    repository.ChildRepository
+	deliveryRepo repository.DeliveryOutcomeRepository
+	motherRepo   repository.MotherRepository
+	userRepo     repository.UserRepository
+	transactor   repository.Transactor
+	log          logger.Logger
+}
+
+// NewService creates a new child registry service
+func NewService(
+	childRepo repository.ChildRepository,
+	deliveryRepo repository.DeliveryOutcomeRepository,
+	motherRepo repository.MotherRepository,
+	userRepo repository.UserRepository,
+	transactor repository.Transactor,
+	log logger.Logger,
+) *Service {
+	return &Service{
+		childRepo:    childRepo,
+		deliveryRepo: deliveryRepo,
+		motherRepo:   motherRepo,
+		userRepo:     userRepo,
+		transactor:   transactor,
+		log:          log,
+	}
+}
+
+// RegisterChild registers a child and reports existing records that may be the same child
+func (s *Service) RegisterChild(
+	ctx context.Context,
+	requesterID uuid.UUID,
+	input *ChildInput,
+) (*RegistrationResult, error) {
+	requester, err := s.getUser(ctx, requesterID)
+	if err != nil {
+		return nil, err
+	}
+
+	if input != nil && requester.Role == model.RoleMother {
+		// Mothers can only register their own children
+		input.MotherID = &requester.ID
+	}
+	if err := validateChildInput(input); err != nil {
+		return nil, err
+	}
+
+	child := model.NewChild(uuid.New(), input.FirstName, input.LastName, input.DateOfBirth).
+		WithBirthDetails(input.BirthWeightGrams, input.BirthLengthCm, input.GestationalAgeWeeks).
+		WithFatherName(input.FatherName)
+	child.MiddleName = input.MiddleName
+	child.Sex = input.Sex
+	child.PlaceOfBirth = input.PlaceOfBirth
+	child.DeliveryType = input.DeliveryType
+	if requester.Role == model.RoleCHW {
+		child.CHWID = &requester.ID
+	}
+	if err := s.linkParents(ctx, child, &ParentLinkInput{MotherID: input.MotherID, FatherID: input.FatherID}); err != nil {
+		return nil, err
+	}
+	if err := s.authorize(ctx, requester, child); err != nil {
+		return nil, err
+	}
+
+	duplicates, err := s.childRepo.FindPossibleDuplicates(ctx, child, duplicateWindow)
+	if err != nil {
+		s.log.Error("Failed to check for duplicate children", logger.Fields{
+			"error": err.Error(),
+		})
+		return nil, errorx.Wrap(err, "failed to check for duplicate children")
+	}
+
+	if err := s.childRepo.Create(ctx, child); err != nil {
+		s.log.Error("Failed to create child", logger.Fields{
+			"error": err.Error(),
+		})
+		return nil, errorx.Wrap(err, "failed to create child")
+	}
+
+	s.log.Info("Child registered successfully", logger.Fields{
+		"child_id":            child.ID.String(),
+		"registered_by":       requesterID.String(),
+		"possible_duplicates": len(duplicates),
+	})
+
+	if duplicates == nil {
+		duplicates = []*model.Child{}
+	}
+	return &RegistrationResult{Child: child, PossibleDuplicates: duplicates}, nil
+}
+
+// RegisterFromDelivery registers the live newborns of a delivery outcome, reusing each
+// newborn's ID as the child ID so their postnatal visits already point at the child.
+// Newborns registered before are skipped; unnamed newborns are registered as "Baby"
+// with the mother's family name until the parents choose a name.
+func (s *Service) RegisterFromDelivery(
+	ctx context.Context,
+	requesterID uuid.UUID,
+	outcomeID uuid.UUID,
+	names []NewbornNames,
+) (*DeliveryRegistrationResult, error) {
+	requester, err := s.getUser(ctx, requesterID)
+	if err != nil {
+		return nil, err
+	}
+
+	outcome, err := s.deliveryRepo.GetByID(ctx, outcomeID)
+	if err != nil {
+		s.log.Error("Failed to find delivery outcome", logger.Fields{
+			"error":      err.Error(),
+			"outcome_id": outcomeID.String(),
+		})
+		return nil, errorx.Wrap(err, "failed to find delivery outcome")
+	}
+
+	mother, err := s.motherRepo.GetByID(ctx, outcome.MotherID)
+	if err != nil {
+		s.log.Error("Failed to find mother", logger.Fields{
+			"error":     err.Error(),
+			"mother_id": outcome.MotherID.String(),
+		})
+		return nil, errorx.Wrap(err, "failed to find mother")
+	}
+
+	motherUser, err := s.getUser(ctx, mother.UserID)
+	if err != nil {
+		return nil, err
+	}
+	familyName := "Unknown"
+	if parts := strings.Fields(motherUser.Name); len(parts) > 0 {
+		familyName = parts[len(parts)-1]
+	}
+
+	named := make(map[uuid.UUID]NewbornNames, len(names))
+	for _, n := range names {
+		named[n.NewbornID] = n
+	}
+
+	result := &DeliveryRegistrationResult{Children: []*model.Child{}}
+	for _, newborn := range outcome.LiveBirths() {
+		if _, err := s.childRepo.GetByID(ctx, newborn.ID); err == nil {
+			result.AlreadyRegistered++
+			continue
+		} else if !errorx.IsType(err, errorx.NotFound) {
+			s.log.Error("Failed to check for registered newborn", logger.Fields{
+				"error":      err.Error(),
+				"newborn_id": newborn.ID.String(),
+			})
+			return nil, errorx.Wrap(err, "failed to check for registered newborn")
+		}
+
+		firstName, lastName := "Baby", familyName
+		n, ok := named[newborn.ID]
+		if ok && strings.TrimSpace(n.FirstName) != "" {
+			firstName = strings.TrimSpace(n.FirstName)
+		}
+		if ok && strings.TrimSpace(n.LastName) != "" {
+			lastName = strings.TrimSpace(n.LastName)
+		}
+
+		child := model.NewChild(newborn.ID, firstName, lastName, outcome.DeliveryDate).
+			WithMother(mother.UserID).
+			WithDeliveryOutcome(outcome).
+			WithBirthDetails(newborn.BirthWeightGrams, 0, outcome.GestationalAgeWeeks)
+		child.MiddleName = strings.TrimSpace(n.MiddleName)
+		child.Sex = newborn.Sex
+		if requester.Role == model.RoleCHW {
+			child.CHWID = &requester.ID
+		}
+
+		if err := s.authorize(ctx, requester, child); err != nil {
+			return nil, err
+		}
+
+		if err := s.childRepo.Create(ctx, child); err != nil {
+			s.log.Error("Failed to create child from delivery", logger.Fields{
+				"error":      err.Error(),
+				"outcome_id": outcome.ID.String(),
+				"newborn_id": newborn.ID.String(),
+			})
+			return nil, errorx.Wrap(err, "failed to create child")
+		}
+		result.Children = append(result.Children, child)
+	}
+
+	s.log.Info("Newborns registered from delivery outcome", logger.Fields{
+		"outcome_id":         outcome.ID.String(),
+		"registered":         len(result.Children),
+		"already_registered": result.AlreadyRegistered,
+	})
+
+	return result, nil
+}
+
+// GetChild retrieves a child the requester may access. A merged duplicate resolves to
+// the record it was merged into.
+func (s *Service) GetChild(ctx context.Context, requesterID, childID uuid.UUID) (*model.Child, error) {
+	requester, err := s.getUser(ctx, requesterID)
+	if err != nil {
+		return nil, err
+	}
+
+	child, err := s.getChild(ctx, childID)
+	if err != nil {
+		return nil, err
+	}
+	if child.MergedIntoID != nil {
+		if child, err = s.getChild(ctx, *child.MergedIntoID); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.authorize(ctx, requester, child); err != nil {
+		return nil, err
+	}
+	return child, nil
+}
+
+// GetChildrenByParent retrieves a parent's active children that the requester may access
+func (s *Service) GetChildrenByParent(ctx context.Context, requesterID, parentID uuid.UUID) ([]*model.Child, error) {
+	requester, err := s.getUser(ctx, requesterID)
+	if err != nil {
+		return nil, err
+	}
+
+	children, err := s.childRepo.GetByParentID(ctx, parentID)
+	if err != nil {
+		s.log.Error("Failed to get children by parent", logger.Fields{
+			"error":     err.Error(),
+			"parent_id": parentID.String(),
+		})
+		return nil, errorx.Wrap(err, "failed to get children")
+	}
+
+	visible := make([]*model.Child, 0, len(children))
+	for _, child := range children {
+		allowed, err := s.canAccess(ctx, requester, child)
+		if err != nil {
+			return nil, err
+		}
+		if allowed {
+			visible = append(visible, child)
+		}
+	}
+	return visible, nil
+}
+
+// LinkParents links a child to their mother and father. A father who is a user replaces
+// a father recorded by name.
+func (s *Service) LinkParents(
+	ctx context.Context,
+	requesterID, childID uuid.UUID,
+	input *ParentLinkInput,
+) (*model.Child, error) {
+	if input == nil || (input.MotherID == nil && input.FatherID == nil && strings.TrimSpace(input.FatherName) == "") {
+		return nil, errorx.New(errorx.BadRequest, "a mother, father or father's name is required")
+	}
+
+	requester, err := s.getUser(ctx, requesterID)
+	if err != nil {
+		return nil, err
+	}
+	child, err := s.getActiveChild(ctx, childID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.authorize(ctx, requester, child); err != nil {
+		return nil, err
+	}
+
+	if err := s.linkParents(ctx, child, input); err != nil {
+		return nil, err
+	}
+	// The new links must still leave the requester able to manage the child
+	if err := s.authorize(ctx, requester, child); err != nil {
+		return nil, err
+	}
+
+	if err := s.childRepo.Update(ctx, child); err != nil {
+		s.log.Error("Failed to update child parents", logger.Fields{
+			"error":    err.Error(),
+			"child_id": childID.String(),
+		})
+		return nil, errorx.Wrap(err, "failed to update child")
+	}
+
+	return child, nil
+}
+
+// DeactivateChild marks a child record inactive, for example one registered in error
+func (s *Service) DeactivateChild(ctx context.Context, requesterID, childID uuid.UUID, reason string) (*model.Child, error) {
+	if strings.TrimSpace(reason) == "" {
+		return nil, errorx.New(errorx.BadRequest, "a reason is required to deactivate a child record")
+	}
+
+	requester, err := s.getUser(ctx, requesterID)
+	if err != nil {
+		return nil, err
+	}
+	if err := authorizeRegistryMaintenance(requester); err != nil {
+		return nil, err
+	}
+
+	child, err := s.getActiveChild(ctx, childID)
+	if err != nil {
+		return nil, err
+	}
+
+	child.Deactivate(strings.TrimSpace(reason))
+	if err := s.childRepo.Update(ctx, child); err != nil {
+		s.log.Error("Failed to deactivate child", logger.Fields{
+			"error":    err.Error(),
+			"child_id": childID.String(),
+		})
+		return nil, errorx.Wrap(err, "failed to deactivate child")
+	}
+
+	s.log.Info("Child record deactivated", logger.Fields{
+		"child_id":       childID.String(),
+		"deactivated_by": requesterID.String(),
+	})
+
+	return child, nil
+}
+
+// MergeChildren merges a duplicate record into the primary one. Details missing from the
+// primary are copied from the duplicate, its visits, immunizations, growth, CMAM and
+// neonatal records move to the primary and it is deactivated, all in one transaction.
+func (s *Service) MergeChildren(ctx context.Context, requesterID, primaryID, duplicateID uuid.UUID) (*model.Child, error) {
+	if primaryID == duplicateID {
+		return nil, errorx.New(errorx.BadRequest, "a child cannot be merged into itself")
+	}
+
+	requester, err := s.getUser(ctx, requesterID)
+	if err != nil {
+		return nil, err
+	}
+	if err := authorizeRegistryMaintenance(requester); err != nil {
+		return nil, err
+	}
+
+	primary, err := s.getActiveChild(ctx, primaryID)
+	if err != nil {
+		return nil, err
+	}
+	duplicate, err := s.getActiveChild(ctx, duplicateID)
+	if err != nil {
+		return nil, err
+	}
+
+	if primary.MotherID != nil && duplicate.MotherID != nil && *primary.MotherID != *duplicate.MotherID {
+		return nil, errorx.New(errorx.BadRequest, "records with different mothers cannot be the same child")
+	}
+	if primary.DeliveryOutcomeID != nil && duplicate.DeliveryOutcomeID != nil && *primary.DeliveryOutcomeID == *duplicate.DeliveryOutcomeID {
+		return nil, errorx.New(errorx.BadRequest, "children registered from the same delivery are siblings, not duplicates")
+	}
+
+	primary.FillFrom(duplicate)
+	duplicate.MergeInto(primary.ID)
+
+	err = s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		return s.childRepo.Merge(ctx, primary, duplicate)
+	})
+	if err != nil {
+		s.log.Error("Failed to merge children", logger.Fields{
+			"error":        err.Error(),
+			"primary_id":   primaryID.String(),
+			"duplicate_id": duplicateID.String(),
+		})
+		return nil, errorx.Wrap(err, "failed to merge children")
+	}
+
+	s.log.Info("Duplicate child record merged", logger.Fields{
+		"primary_id":   primaryID.String(),
+		"duplicate_id": duplicateID.String(),
+		"merged_by":    requesterID.String(),
+	})
+
+	return primary, nil
+}
+
+// linkParents checks the parents are users with the right role and links them to the child
+func (s *Service) linkParents(ctx context.Context, child *model.Child, input *ParentLinkInput) error {
+	if input.MotherID != nil {
+		mother, err := s.getUser(ctx, *input.MotherID)
+		if err != nil {
+			return err
+		}
+		if mother.Role != model.RoleMother {
+			return errorx.New(errorx.BadRequest, "the child's mother must be a mother user")
+		}
+		child.WithMother(mother.ID)
+	}
+
+	if input.FatherID != nil {
+		if _, err := s.getUser(ctx, *input.FatherID); err != nil {
+			return err
+		}
+		child.WithFather(*input.FatherID).WithFatherName("")
+	} else if name := strings.TrimSpace(input.FatherName); name != "" {
+		child.FatherID = nil
+		child.WithFatherName(name)
+	}
+
+	if !child.HasParent() {
+		return errorx.New(errorx.BadRequest, "a child must be linked to a mother, father or father's name")
+	}
+	return nil
+}
+
+// getUser retrieves a user
+func (s *Service) getUser(ctx context.Context, userID uuid.UUID) (*model.User, error) {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		s.log.Error("Failed to find user", logger.Fields{
+			"error":   err.Error(),
+			"user_id": userID.String(),
+		})
+		return nil, errorx.Wrap(err, "failed to find user")
+	}
+	return user, nil
+}
+
+// getChild retrieves a child
+func (s *Service) getChild(ctx context.Context, childID uuid.UUID) (*model.Child, error) {
+	child, err := s.childRepo.GetByID(ctx, childID)
+	if err != nil {
+		s.log.Error("Failed to find child", logger.Fields{
+			"error":    err.Error(),
+			"child_id": childID.String(),
+		})
+		return nil, errorx.Wrap(err, "failed to find child")
+	}
+	return child, nil
+}
+
+// getActiveChild retrieves a child whose record is still active
+func (s *Service) getActiveChild(ctx context.Context, childID uuid.UUID) (*model.Child, error) {
+	child, err := s.getChild(ctx, childID)
+	if err != nil {
+		return nil, err
+	}
+	if !child.IsActive {
+		return nil, errorx.New(errorx.BadRequest, "child record is inactive")
+	}
+	return child, nil
+}
+
+// validateChildInput validates the details for registering a child
+func validateChildInput(input *ChildInput) error {
+	if input == nil {
+		return errorx.New(errorx.BadRequest, "child details are required")
+	}
+	if strings.TrimSpace(input.FirstName) == "" || strings.TrimSpace(input.LastName) == "" {
+		return errorx.New(errorx.BadRequest, "first and last name are required")
+	}
+	if input.DateOfBirth.IsZero() || input.DateOfBirth.After(time.Now()) {
+		return errorx.New(errorx.BadRequest, "date of birth must be in the past")
+	}
+	if input.Sex != "" && input.Sex != "male" && input.Sex != "female" {
+		return errorx.New(errorx.BadRequest, "sex must be male or female")
+	}
+	if input.GestationalAgeWeeks != 0 && (input.GestationalAgeWeeks < 20 || input.GestationalAgeWeeks > 45) {
+		return errorx.New(errorx.BadRequest, "gestational age at birth must be between 20 and 45 weeks")
+	}
+	switch input.DeliveryType {
+	case "", model.ChildDeliveryVaginal, model.ChildDeliveryCSection, model.ChildDeliveryAssisted:
+	default:
+		return errorx.Newf(errorx.BadRequest, "unknown delivery type %s", input.DeliveryType)
+	}
+	if input.MotherID == nil && input.FatherID == nil && strings.TrimSpace(input.FatherName) == "" {
+		return errorx.New(errorx.BadRequest, "a child must be linked to a mother, father or father's name")
+	}
+	return nil
+}
@@ -154,6 +154,8 @@ func (h *SOSHandler) ReportSOS(w http.ResponseWriter, r *http.Request) {
 		nature = model.SOSEventNatureBleeding
 	case "accident":
 		nature = model.SOSEventNatureAccident
+	case "newborn":
+		nature = model.SOSEventNatureNewborn
 	case "other":
 		nature = model.SOSEventNatureOther
 	default:
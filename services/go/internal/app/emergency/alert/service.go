@@ -104,7 +104,7 @@ func (s *Service) AlertNearbyFacilities(ctx context.Context, sosID uuid.UUID, ra
 	// Determine alert level based on SOS event nature
 	var alertLevel AlertLevel
 	switch sosEvent.Nature {
-	case model.SOSEventNatureBleeding:
+	case model.SOSEventNatureBleeding, model.SOSEventNatureNewborn:
 		alertLevel = AlertLevelCritical
 	case model.SOSEventNatureLabor:
 		alertLevel = AlertLevelEmergency
@@ -167,7 +167,7 @@ func (s *Service) AlertEmergencyContacts(ctx context.Context, sosID uuid.UUID) (
 	// Determine alert level based on SOS event nature
 	var alertLevel AlertLevel
 	switch sosEvent.Nature {
-	case model.SOSEventNatureBleeding:
+	case model.SOSEventNatureBleeding, model.SOSEventNatureNewborn:
 		alertLevel = AlertLevelCritical
 	case model.SOSEventNatureLabor:
 		alertLevel = AlertLevelEmergency
@@ -222,7 +222,7 @@ func (s *Service) AlertFacility(ctx context.Context, sosID uuid.UUID, facilityID
 	// Determine alert level based on SOS event nature
 	var alertLevel AlertLevel
 	switch sosEvent.Nature {
-	case model.SOSEventNatureBleeding:
+	case model.SOSEventNatureBleeding, model.SOSEventNatureNewborn:
 		alertLevel = AlertLevelCritical
 	case model.SOSEventNatureLabor:
 		alertLevel = AlertLevelEmergency
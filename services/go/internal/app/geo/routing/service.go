@@ -220,7 +220,7 @@ func (s *Service) optimizeWaypoints(points []model.Location) []model.Location {
 		return points
 	}
 	
-	// For MVP, use a simple greedy algorithm (nearest neighbor)
+	// Seed with nearest neighbour, then refine with 2-opt
 	optimized := make([]model.Location, 0, len(points))
 	optimized = append(optimized, start)
 	
@@ -249,7 +249,7 @@ func (s *Service) optimizeWaypoints(points []model.Location) []model.Location {
 	// Add the end point
 	optimized = append(optimized, end)
 	
-	return optimized
+	return s.twoOpt(optimized)
 }
 
 // findNearestPointIndex finds the index of the nearest point
@@ -0,0 +1,383 @@
+package routing
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/mamacare/services/internal/domain/model"
+	"github.com/mamacare/services/pkg/errorx"
+)
+
+// VRPStop is a visit that needs to be placed on a route
+type VRPStop struct {
+	ID              string         `json:"id"`
+	Name            string         `json:"name,omitempty"`
+	Location        model.Location `json:"location"`
+	WindowStart     time.Time      `json:"window_start"`
+	WindowEnd       time.Time      `json:"window_end"`        // latest allowed arrival
+	Windows         []TimeWindow   `json:"windows,omitempty"` // if set, arrival must also fall in one of these
+	ServiceDuration time.Duration  `json:"service_duration"`  // time spent at the stop
+	Priority        int            `json:"priority"`          // higher is planned first
+}
+
+// TimeWindow is a period in which a stop can be reached, such as when someone is at home
+type TimeWindow struct {
+	Start time.Time `json:"start"`
+	End   time.Time `json:"end"` // latest allowed arrival
+}
+
+// VRPShift is a working period in which stops can be visited
+type VRPShift struct {
+	Start time.Time `json:"start"`
+	End   time.Time `json:"end"`
+}
+
+// VRPRequest describes a multi-day vehicle routing problem with time windows
+type VRPRequest struct {
+	Depot          model.Location `json:"depot"`
+	ReturnToDepot  bool           `json:"return_to_depot"`
+	Stops          []VRPStop      `json:"stops"`
+	Shifts         []VRPShift     `json:"shifts"`
+	TransportMode  TransportMode  `json:"transport_mode"`
+	MaxStopsPerDay int            `json:"max_stops_per_day,omitempty"`
+}
+
+// PlannedStop is a stop with its scheduled arrival and departure
+type PlannedStop struct {
+	Stop                 VRPStop   `json:"stop"`
+	Arrival              time.Time `json:"arrival"`
+	Departure            time.Time `json:"departure"`
+	WaitSeconds          int       `json:"wait_seconds"`
+	DistanceFromPrevious float64   `json:"distance_from_previous"` // km
+	TravelSeconds        int       `json:"travel_seconds"`
+}
+
+// DayRoute is the planned route for a single shift
+type DayRoute struct {
+	Shift          VRPShift      `json:"shift"`
+	Stops          []PlannedStop `json:"stops"`
+	ReturnDistance float64       `json:"return_distance"` // km
+	TotalDistance  float64       `json:"total_distance"`  // km
+	TotalDuration  int           `json:"total_duration"`  // seconds
+	EndTime        time.Time     `json:"end_time"`
+}
+
+// VRPSolution is the result of a multi-day route plan
+type VRPSolution struct {
+	Days          []*DayRoute `json:"days"`
+	Unassigned    []VRPStop   `json:"unassigned"`
+	TotalDistance float64     `json:"total_distance"` // km
+	TotalDuration int         `json:"total_duration"` // seconds
+}
+
+// vrpSolver holds precomputed distances for a single planning run
+type vrpSolver struct {
+	service  *Service
+	req      *VRPRequest
+	distance [][]float64 // node 0 is the depot, node i+1 is req.Stops[i]
+}
+
+// PlanRoutes plans stops across several shifts, respecting each stop's time window.
+// Stops are placed by cheapest feasible insertion and every day is then improved with 2-opt.
+func (s *Service) PlanRoutes(ctx context.Context, req *VRPRequest) (*VRPSolution, error) {
+	if req == nil || len(req.Shifts) == 0 {
+		return nil, errorx.New(errorx.BadRequest, "Route plan requires at least one shift")
+	}
+	for _, shift := range req.Shifts {
+		if !shift.End.After(shift.Start) {
+			return nil, errorx.New(errorx.BadRequest, "Shift end must be after shift start")
+		}
+	}
+	if req.TransportMode == "" {
+		req.TransportMode = TransportModeWalking
+	}
+
+	solver := s.newVRPSolver(req)
+
+	// Plan the most constrained stops first: earliest deadline, then highest priority
+	order := make([]int, len(req.Stops))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		sa, sb := req.Stops[order[a]], req.Stops[order[b]]
+		if !sa.WindowEnd.Equal(sb.WindowEnd) {
+			return sa.WindowEnd.Before(sb.WindowEnd)
+		}
+		return sa.Priority > sb.Priority
+	})
+
+	routes := make([][]int, len(req.Shifts))
+	var unassigned []VRPStop
+
+	for _, stopIdx := range order {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+
+		bestDay, bestPos := -1, -1
+		bestCost := 0.0
+
+		for day, route := range routes {
+			if req.MaxStopsPerDay > 0 && len(route) >= req.MaxStopsPerDay {
+				continue
+			}
+
+			baseCost := 0.0
+			if len(route) > 0 {
+				baseRoute, _ := solver.simulate(day, route)
+				baseCost = solver.cost(baseRoute)
+			}
+
+			for pos := 0; pos <= len(route); pos++ {
+				candidate := insertAt(route, pos, stopIdx)
+				planned, ok := solver.simulate(day, candidate)
+				if !ok {
+					continue
+				}
+
+				delta := solver.cost(planned) - baseCost
+				if bestDay < 0 || delta < bestCost {
+					bestDay, bestPos, bestCost = day, pos, delta
+				}
+			}
+		}
+
+		if bestDay < 0 {
+			unassigned = append(unassigned, req.Stops[stopIdx])
+			continue
+		}
+		routes[bestDay] = insertAt(routes[bestDay], bestPos, stopIdx)
+	}
+
+	solution := &VRPSolution{
+		Days:       make([]*DayRoute, len(routes)),
+		Unassigned: unassigned,
+	}
+
+	for day, route := range routes {
+		route = solver.improve(day, route)
+		planned, _ := solver.simulate(day, route)
+		solution.Days[day] = planned
+		solution.TotalDistance += planned.TotalDistance
+		solution.TotalDuration += planned.TotalDuration
+	}
+
+	return solution, nil
+}
+
+// newVRPSolver precomputes the distance matrix for a request
+func (s *Service) newVRPSolver(req *VRPRequest) *vrpSolver {
+	nodes := make([]model.Location, 0, len(req.Stops)+1)
+	nodes = append(nodes, req.Depot)
+	for _, stop := range req.Stops {
+		nodes = append(nodes, stop.Location)
+	}
+
+	distance := make([][]float64, len(nodes))
+	for i := range nodes {
+		distance[i] = make([]float64, len(nodes))
+		for j := range nodes {
+			if i != j {
+				distance[i][j] = s.locationService.CalculateDistance(
+					nodes[i].Latitude, nodes[i].Longitude,
+					nodes[j].Latitude, nodes[j].Longitude,
+				)
+			}
+		}
+	}
+
+	return &vrpSolver{
+		service:  s,
+		req:      req,
+		distance: distance,
+	}
+}
+
+// simulate walks a route through a shift and reports whether all windows are met
+func (v *vrpSolver) simulate(day int, route []int) (*DayRoute, bool) {
+	shift := v.req.Shifts[day]
+	planned := &DayRoute{
+		Shift: shift,
+		Stops: make([]PlannedStop, 0, len(route)),
+	}
+
+	feasible := true
+	current := shift.Start
+	prevNode := 0
+
+	for _, stopIdx := range route {
+		stop := v.req.Stops[stopIdx]
+		node := stopIdx + 1
+
+		distance := v.distance[prevNode][node]
+		travel := v.service.calculateDuration(distance, v.req.TransportMode)
+		arrival := current.Add(time.Duration(travel) * time.Second)
+
+		wait := 0
+		if arrival.Before(stop.WindowStart) {
+			wait = int(stop.WindowStart.Sub(arrival).Seconds())
+			arrival = stop.WindowStart
+		}
+		if len(stop.Windows) > 0 {
+			open, ok := nextOpening(stop.Windows, arrival)
+			if !ok {
+				feasible = false
+			} else if arrival.Before(open) {
+				wait += int(open.Sub(arrival).Seconds())
+				arrival = open
+			}
+		}
+		// Checked after any wait for an opening, which can push arrival past the window
+		if !stop.WindowEnd.IsZero() && arrival.After(stop.WindowEnd) {
+			feasible = false
+		}
+
+		departure := arrival.Add(stop.ServiceDuration)
+		if departure.After(shift.End) {
+			feasible = false
+		}
+
+		planned.Stops = append(planned.Stops, PlannedStop{
+			Stop:                 stop,
+			Arrival:              arrival,
+			Departure:            departure,
+			WaitSeconds:          wait,
+			DistanceFromPrevious: distance,
+			TravelSeconds:        travel,
+		})
+		planned.TotalDistance += distance
+
+		current = departure
+		prevNode = node
+	}
+
+	if v.req.ReturnToDepot && len(route) > 0 {
+		distance := v.distance[prevNode][0]
+		travel := v.service.calculateDuration(distance, v.req.TransportMode)
+		current = current.Add(time.Duration(travel) * time.Second)
+		planned.ReturnDistance = distance
+		planned.TotalDistance += distance
+		if current.After(shift.End) {
+			feasible = false
+		}
+	}
+
+	if len(route) == 0 {
+		current = shift.Start
+	}
+	planned.EndTime = current
+	planned.TotalDuration = int(current.Sub(shift.Start).Seconds())
+
+	return planned, feasible
+}
+
+// nextOpening returns the earliest time at or after arrival that falls in one of the
+// windows, or false if every window has already closed
+func nextOpening(windows []TimeWindow, arrival time.Time) (time.Time, bool) {
+	var earliest time.Time
+	found := false
+	for _, window := range windows {
+		if arrival.After(window.End) {
+			continue
+		}
+		open := window.Start
+		if arrival.After(open) {
+			open = arrival
+		}
+		if !found || open.Before(earliest) {
+			earliest, found = open, true
+		}
+	}
+	return earliest, found
+}
+
+// cost scores a day route; travel and waiting time dominate, distance breaks ties
+func (v *vrpSolver) cost(route *DayRoute) float64 {
+	if route == nil || len(route.Stops) == 0 {
+		return 0
+	}
+	firstArrival := route.Stops[0].Arrival.Sub(route.Shift.Start).Seconds()
+	// Don't penalise starting the day late when the first window opens later
+	return float64(route.TotalDuration) - firstArrival + float64(route.Stops[0].TravelSeconds) + route.TotalDistance
+}
+
+// improve applies 2-opt moves that keep the route feasible and lower its cost
+func (v *vrpSolver) improve(day int, route []int) []int {
+	if len(route) < 3 {
+		return route
+	}
+
+	best := append([]int(nil), route...)
+	bestRoute, _ := v.simulate(day, best)
+	bestCost := v.cost(bestRoute)
+
+	for improved := true; improved; {
+		improved = false
+		for i := 0; i < len(best)-1; i++ {
+			for j := i + 1; j < len(best); j++ {
+				candidate := reverseSegment(best, i, j)
+				planned, ok := v.simulate(day, candidate)
+				if !ok {
+					continue
+				}
+				if c := v.cost(planned); c+1e-6 < bestCost {
+					best, bestCost = candidate, c
+					improved = true
+				}
+			}
+		}
+	}
+
+	return best
+}
+
+// twoOpt shortens an open path between fixed endpoints by reversing segments
+func (s *Service) twoOpt(points []model.Location) []model.Location {
+	if len(points) < 4 {
+		return points
+	}
+
+	dist := func(a, b model.Location) float64 {
+		return s.locationService.CalculateDistance(a.Latitude, a.Longitude, b.Latitude, b.Longitude)
+	}
+
+	route := append([]model.Location(nil), points...)
+	for improved := true; improved; {
+		improved = false
+		// Endpoints stay fixed, so only reverse interior segments [i, j]
+		for i := 1; i < len(route)-2; i++ {
+			for j := i + 1; j < len(route)-1; j++ {
+				before := dist(route[i-1], route[i]) + dist(route[j], route[j+1])
+				after := dist(route[i-1], route[j]) + dist(route[i], route[j+1])
+				if after+1e-9 < before {
+					for l, r := i, j; l < r; l, r = l+1, r-1 {
+						route[l], route[r] = route[r], route[l]
+					}
+					improved = true
+				}
+			}
+		}
+	}
+
+	return route
+}
+
+// insertAt returns a copy of route with value inserted at pos
+func insertAt(route []int, pos, value int) []int {
+	result := make([]int, 0, len(route)+1)
+	result = append(result, route[:pos]...)
+	result = append(result, value)
+	result = append(result, route[pos:]...)
+	return result
+}
+
+// reverseSegment returns a copy of route with the segment [i, j] reversed
+func reverseSegment(route []int, i, j int) []int {
+	result := append([]int(nil), route...)
+	for l, r := i, j; l < r; l, r = l+1, r-1 {
+		result[l], result[r] = result[r], result[l]
+	}
+	return result
+}
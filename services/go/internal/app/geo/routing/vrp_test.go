@@ -0,0 +1,196 @@
+package routing
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/mamacare/services/internal/app/geo/location"
+	"github.com/mamacare/services/internal/domain/model"
+)
+
+var (
+	testDay   = time.Date(2024, 3, 4, 0, 0, 0, 0, time.UTC)
+	testDepot = model.Location{Latitude: 8.4657, Longitude: -13.2317}
+)
+
+// at returns a time on the test day
+func at(hour, minute int) time.Time {
+	return testDay.Add(time.Duration(hour)*time.Hour + time.Duration(minute)*time.Minute)
+}
+
+func newTestService() *Service {
+	return &Service{locationService: location.NewService(nil)}
+}
+
+func TestNextOpening(t *testing.T) {
+	windows := []TimeWindow{
+		{Start: at(9, 0), End: at(10, 0)},
+		{Start: at(14, 0), End: at(15, 0)},
+	}
+
+	tests := []struct {
+		name     string
+		arrival  time.Time
+		wantOpen time.Time
+		wantOK   bool
+	}{
+		{"before the first window", at(8, 0), at(9, 0), true},
+		{"inside the first window", at(9, 30), at(9, 30), true},
+		{"at the end of the first window", at(10, 0), at(10, 0), true},
+		{"between windows", at(11, 0), at(14, 0), true},
+		{"after every window", at(15, 1), time.Time{}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			open, ok := nextOpening(windows, tt.arrival)
+			if ok != tt.wantOK {
+				t.Fatalf("nextOpening() ok = %v, want %v", ok, tt.wantOK)
+			}
+			if ok && !open.Equal(tt.wantOpen) {
+				t.Errorf("nextOpening() = %s, want %s", open.Format("15:04"), tt.wantOpen.Format("15:04"))
+			}
+		})
+	}
+}
+
+func TestSimulateTimeWindows(t *testing.T) {
+	tests := []struct {
+		name         string
+		stop         VRPStop
+		wantArrival  time.Time
+		wantWait     time.Duration
+		wantFeasible bool
+	}{
+		{
+			name:         "no window",
+			stop:         VRPStop{},
+			wantArrival:  at(8, 0),
+			wantFeasible: true,
+		},
+		{
+			name:         "waits for the window to open",
+			stop:         VRPStop{WindowStart: at(10, 0), WindowEnd: at(12, 0)},
+			wantArrival:  at(10, 0),
+			wantWait:     2 * time.Hour,
+			wantFeasible: true,
+		},
+		{
+			name:         "window closed before the shift",
+			stop:         VRPStop{WindowEnd: at(7, 0)},
+			wantArrival:  at(8, 0),
+			wantFeasible: false,
+		},
+		{
+			name: "opening within the window",
+			stop: VRPStop{
+				WindowEnd: at(11, 0),
+				Windows:   []TimeWindow{{Start: at(9, 0), End: at(10, 0)}},
+			},
+			wantArrival:  at(9, 0),
+			wantWait:     time.Hour,
+			wantFeasible: true,
+		},
+		{
+			name: "waiting for an opening passes the latest arrival",
+			stop: VRPStop{
+				WindowEnd: at(11, 0),
+				Windows:   []TimeWindow{{Start: at(12, 0), End: at(13, 0)}},
+			},
+			wantArrival:  at(12, 0),
+			wantWait:     4 * time.Hour,
+			wantFeasible: false,
+		},
+		{
+			name: "every opening has closed",
+			stop: VRPStop{
+				Windows: []TimeWindow{{Start: at(6, 0), End: at(7, 0)}},
+			},
+			wantArrival:  at(8, 0),
+			wantFeasible: false,
+		},
+		{
+			name:         "service runs past the end of the shift",
+			stop:         VRPStop{ServiceDuration: 10 * time.Hour},
+			wantArrival:  at(8, 0),
+			wantFeasible: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// The stop is at the depot, so arrival is only moved by its windows
+			tt.stop.ID = "stop"
+			tt.stop.Location = testDepot
+			req := &VRPRequest{
+				Depot:         testDepot,
+				Stops:         []VRPStop{tt.stop},
+				Shifts:        []VRPShift{{Start: at(8, 0), End: at(17, 0)}},
+				TransportMode: TransportModeWalking,
+			}
+
+			planned, feasible := newTestService().newVRPSolver(req).simulate(0, []int{0})
+			if feasible != tt.wantFeasible {
+				t.Errorf("simulate() feasible = %v, want %v", feasible, tt.wantFeasible)
+			}
+			stop := planned.Stops[0]
+			if !stop.Arrival.Equal(tt.wantArrival) {
+				t.Errorf("simulate() arrival = %s, want %s", stop.Arrival.Format("15:04"), tt.wantArrival.Format("15:04"))
+			}
+			if got := time.Duration(stop.WaitSeconds) * time.Second; got != tt.wantWait {
+				t.Errorf("simulate() wait = %s, want %s", got, tt.wantWait)
+			}
+		})
+	}
+}
+
+func TestPlanRoutesAssignsStopsToShiftsInTheirWindows(t *testing.T) {
+	nextDay := func(hour int) time.Time { return at(hour, 0).AddDate(0, 0, 1) }
+
+	req := &VRPRequest{
+		Depot: testDepot,
+		Stops: []VRPStop{
+			{ID: "today", Location: testDepot, WindowStart: at(9, 0), WindowEnd: at(12, 0)},
+			{ID: "tomorrow", Location: testDepot, WindowStart: nextDay(9), WindowEnd: nextDay(12)},
+			{ID: "closed", Location: testDepot, Windows: []TimeWindow{{Start: at(18, 0), End: at(19, 0)}}},
+		},
+		Shifts: []VRPShift{
+			{Start: at(8, 0), End: at(17, 0)},
+			{Start: nextDay(8), End: nextDay(17)},
+		},
+		TransportMode: TransportModeWalking,
+	}
+
+	solution, err := newTestService().PlanRoutes(context.Background(), req)
+	if err != nil {
+		t.Fatalf("PlanRoutes() error = %v", err)
+	}
+
+	tests := []struct {
+		day    int
+		wantID string
+	}{
+		{0, "today"},
+		{1, "tomorrow"},
+	}
+	for _, tt := range tests {
+		stops := solution.Days[tt.day].Stops
+		if len(stops) != 1 || stops[0].Stop.ID != tt.wantID {
+			t.Errorf("day %d stops = %v, want [%s]", tt.day, stopIDs(stops), tt.wantID)
+		}
+	}
+
+	if len(solution.Unassigned) != 1 || solution.Unassigned[0].ID != "closed" {
+		t.Errorf("unassigned = %v, want [closed]", solution.Unassigned)
+	}
+}
+
+// stopIDs lists the IDs of planned stops
+func stopIDs(stops []PlannedStop) []string {
+	ids := make([]string, 0, len(stops))
+	for _, stop := range stops {
+		ids = append(ids, stop.Stop.ID)
+	}
+	return ids
+}
@@ -0,0 +1,167 @@
+package action
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mamacare/services/internal/app/health/dating"
+	"github.com/mamacare/services/internal/domain/model"
+	"github.com/mamacare/services/internal/port/hasura"
+	"github.com/mamacare/services/internal/port/response"
+	"github.com/mamacare/services/internal/port/validation"
+	"github.com/mamacare/services/pkg/errorx"
+	"github.com/mamacare/services/pkg/logger"
+)
+
+// PregnancyDatingRequest is the request to date a pregnancy from LMP, ultrasound or fundal height
+type PregnancyDatingRequest struct {
+	MotherID       string   `json:"mother_id" validate:"required,uuid"`
+	Method         string   `json:"method" validate:"required,oneof=lmp ultrasound_crl ultrasound_bpd fundal_height"`
+	ExamDate       string   `json:"exam_date,omitempty"`
+	LMP            string   `json:"lmp,omitempty"`
+	MeasurementMm  *float64 `json:"measurement_mm,omitempty"`
+	FundalHeightCm *float64 `json:"fundal_height_cm,omitempty"`
+}
+
+// DatingHistoryRequest is the request for a mother's dating history
+type DatingHistoryRequest struct {
+	MotherID string `json:"mother_id" validate:"required,uuid"`
+}
+
+// DatingHandler handles pregnancy dating actions
+type DatingHandler struct {
+	hasura.BaseActionHandler
+	datingService *dating.Service
+	validator     *validation.Validator
+	log           logger.Logger
+}
+
+// NewDatingHandler creates a new pregnancy dating handler
+func NewDatingHandler(
+	log logger.Logger,
+	datingService *dating.Service,
+	validator *validation.Validator,
+) *DatingHandler {
+	return &DatingHandler{
+		BaseActionHandler: hasura.BaseActionHandler{},
+		datingService:     datingService,
+		validator:         validator,
+		log:               log,
+	}
+}
+
+// DatePregnancy records a dating estimate and corrects the EDD and visit plan if it applies
+func (h *DatingHandler) DatePregnancy(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	reqID := response.GetRequestID(ctx)
+
+	var req PregnancyDatingRequest
+	actionReq, err := h.ParseRequest(r, &req)
+	if err != nil {
+		h.log.Error("Failed to parse request", logger.Fields{
+			"request_id": reqID,
+			"error":      err.Error(),
+		})
+		response.WriteErrorResponse(w, reqID, err)
+		return
+	}
+
+	// The dating estimate is recorded by the caller, never by an ID in the input
+	recordedByID, err := actionReq.UserID()
+	if err != nil {
+		response.WriteErrorResponse(w, reqID, err)
+		return
+	}
+
+	// Validate request
+	if err := h.validator.Validate(req); err != nil {
+		h.log.Error("Invalid request", logger.Fields{
+			"request_id": reqID,
+			"error":      err.Error(),
+		})
+		response.WriteErrorResponse(w, reqID, err)
+		return
+	}
+
+	motherID, err := uuid.Parse(req.MotherID)
+	if err != nil {
+		response.WriteErrorResponse(w, reqID, errorx.New(errorx.BadRequest, "Invalid mother ID"))
+		return
+	}
+
+	input := &dating.Input{
+		Method:         model.DatingMethod(req.Method),
+		MeasurementMm:  req.MeasurementMm,
+		FundalHeightCm: req.FundalHeightCm,
+	}
+
+	if req.ExamDate != "" {
+		examDate, err := time.Parse("2006-01-02", req.ExamDate)
+		if err != nil {
+			response.WriteErrorResponse(w, reqID, errorx.New(errorx.BadRequest, "Invalid exam date format. Use YYYY-MM-DD"))
+			return
+		}
+		input.ExamDate = examDate
+	}
+
+	if req.LMP != "" {
+		lmp, err := time.Parse("2006-01-02", req.LMP)
+		if err != nil {
+			response.WriteErrorResponse(w, reqID, errorx.New(errorx.BadRequest, "Invalid LMP date format. Use YYYY-MM-DD"))
+			return
+		}
+		input.LMP = &lmp
+	}
+
+	input.RecordedByID = &recordedByID
+
+	result, err := h.datingService.RecordDating(ctx, motherID, input)
+	if err != nil {
+		h.log.Error("Failed to date pregnancy", logger.Fields{
+			"request_id": reqID,
+			"mother_id":  motherID.String(),
+			"method":     req.Method,
+			"error":      err.Error(),
+		})
+		response.WriteErrorResponse(w, reqID, err)
+		return
+	}
+
+	response.WriteJSONResponse(w, reqID, result)
+}
+
+// GetDatingHistory returns every dating estimate for a mother and whether it was applied
+func (h *DatingHandler) GetDatingHistory(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	reqID := response.GetRequestID(ctx)
+
+	var req DatingHistoryRequest
+	if err := h.ParseRequest(r, &req); err != nil {
+		h.log.Error("Failed to parse request", logger.Fields{
+			"request_id": reqID,
+			"error":      err.Error(),
+		})
+		response.WriteErrorResponse(w, reqID, err)
+		return
+	}
+
+	if err := h.validator.Validate(req); err != nil {
+		response.WriteErrorResponse(w, reqID, err)
+		return
+	}
+
+	motherID, err := uuid.Parse(req.MotherID)
+	if err != nil {
+		response.WriteErrorResponse(w, reqID, errorx.New(errorx.BadRequest, "Invalid mother ID"))
+		return
+	}
+
+	history, err := h.datingService.GetDatingHistory(ctx, motherID)
+	if err != nil {
+		response.WriteErrorResponse(w, reqID, err)
+		return
+	}
+
+	response.WriteJSONResponse(w, reqID, history)
+}
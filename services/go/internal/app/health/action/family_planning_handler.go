@@ -0,0 +1,241 @@
+package action
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mamacare/services/internal/app/health/familyplanning"
+	"github.com/mamacare/services/internal/domain/model"
+	"github.com/mamacare/services/internal/port/hasura"
+	"github.com/mamacare/services/internal/port/response"
+	"github.com/mamacare/services/internal/port/validation"
+	"github.com/mamacare/services/pkg/errorx"
+	"github.com/mamacare/services/pkg/logger"
+)
+
+// RecordFamilyPlanningVisitRequest is the request to record family planning counselling,
+// a method chosen or given, or a discontinuation
+type RecordFamilyPlanningVisitRequest struct {
+	MotherID              string   `json:"mother_id" validate:"required,uuid"`
+	FacilityID            string   `json:"facility_id" validate:"required,uuid"`
+	VisitDate             string   `json:"visit_date,omitempty" validate:"omitempty,datetime=2006-01-02"`
+	Topics                []string `json:"topics,omitempty" validate:"omitempty,dive,oneof=birth_spacing return_to_fertility lam method_options side_effects dual_protection"`
+	Method                string   `json:"method,omitempty" validate:"omitempty,oneof=implant injectable iud pills lam"`
+	MethodGiven           bool     `json:"method_given"`
+	Quantity              int      `json:"quantity,omitempty" validate:"omitempty,min=1"`
+	ImplantYears          int      `json:"implant_years,omitempty" validate:"omitempty,oneof=3 5"`
+	Discontinue           bool     `json:"discontinue"`
+	DiscontinuationReason string   `json:"discontinuation_reason,omitempty"`
+	Notes                 string   `json:"notes,omitempty"`
+}
+
+// GetFamilyPlanningStatusRequest is the request for a mother's family planning status
+type GetFamilyPlanningStatusRequest struct {
+	MotherID string `json:"mother_id" validate:"required,uuid"`
+}
+
+// GetFamilyPlanningCoverageRequest is the request for family planning coverage per facility and district
+type GetFamilyPlanningCoverageRequest struct {
+	From       string `json:"from" validate:"required,datetime=2006-01-02"`
+	To         string `json:"to" validate:"required,datetime=2006-01-02"`
+	FacilityID string `json:"facility_id,omitempty" validate:"omitempty,uuid"`
+	District   string `json:"district,omitempty"`
+}
+
+// FamilyPlanningHandler handles postpartum family planning actions
+type FamilyPlanningHandler struct {
+	hasura.BaseActionHandler
+	familyPlanningService *familyplanning.Service
+	reminderJob           *familyplanning.ReminderJob
+	validator             *validation.Validator
+	log                   logger.Logger
+}
+
+// NewFamilyPlanningHandler creates a new family planning handler
+func NewFamilyPlanningHandler(
+	log logger.Logger,
+	familyPlanningService *familyplanning.Service,
+	reminderJob *familyplanning.ReminderJob,
+	validator *validation.Validator,
+) *FamilyPlanningHandler {
+	return &FamilyPlanningHandler{
+		BaseActionHandler:     hasura.BaseActionHandler{},
+		familyPlanningService: familyPlanningService,
+		reminderJob:           reminderJob,
+		validator:             validator,
+		log:                   log,
+	}
+}
+
+// RecordFamilyPlanningVisit records counselling, the method a mother chose or was given,
+// or that she stopped using her method, and returns her status
+func (h *FamilyPlanningHandler) RecordFamilyPlanningVisit(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	reqID := response.GetRequestID(ctx)
+
+	var req RecordFamilyPlanningVisitRequest
+	requestedByID, ok := h.parseAndValidate(w, r, reqID, &req)
+	if !ok {
+		return
+	}
+
+	motherID, ok := parseMotherID(w, reqID, req.MotherID)
+	if !ok {
+		return
+	}
+
+	facilityID, err := uuid.Parse(req.FacilityID)
+	if err != nil {
+		response.WriteErrorResponse(w, reqID, errorx.New(errorx.BadRequest, "Invalid facility ID"))
+		return
+	}
+
+	input := &familyplanning.VisitInput{
+		FacilityID:            facilityID,
+		Topics:                make([]model.FPTopic, 0, len(req.Topics)),
+		MethodGiven:           req.MethodGiven,
+		Quantity:              req.Quantity,
+		ImplantYears:          req.ImplantYears,
+		Discontinue:           req.Discontinue,
+		DiscontinuationReason: req.DiscontinuationReason,
+		Notes:                 req.Notes,
+	}
+	if req.VisitDate != "" {
+		if input.VisitDate, err = time.Parse("2006-01-02", req.VisitDate); err != nil {
+			response.WriteErrorResponse(w, reqID, errorx.New(errorx.BadRequest, "Invalid visit date"))
+			return
+		}
+	}
+	for _, topic := range req.Topics {
+		input.Topics = append(input.Topics, model.FPTopic(topic))
+	}
+	if req.Method != "" {
+		method := model.FPMethod(req.Method)
+		input.Method = &method
+	}
+
+	result, err := h.familyPlanningService.RecordVisit(ctx, requestedByID, motherID, input)
+	if err != nil {
+		h.log.Error("Failed to record family planning visit", logger.Fields{
+			"request_id": reqID,
+			"mother_id":  motherID.String(),
+			"error":      err.Error(),
+		})
+		response.WriteErrorResponse(w, reqID, err)
+		return
+	}
+
+	response.WriteJSONResponse(w, reqID, result)
+}
+
+// GetFamilyPlanningStatus returns a mother's current method, when it is next due and her visits
+func (h *FamilyPlanningHandler) GetFamilyPlanningStatus(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	reqID := response.GetRequestID(ctx)
+
+	var req GetFamilyPlanningStatusRequest
+	requestedByID, ok := h.parseAndValidate(w, r, reqID, &req)
+	if !ok {
+		return
+	}
+
+	motherID, ok := parseMotherID(w, reqID, req.MotherID)
+	if !ok {
+		return
+	}
+
+	status, err := h.familyPlanningService.GetStatus(ctx, requestedByID, motherID)
+	if err != nil {
+		response.WriteErrorResponse(w, reqID, err)
+		return
+	}
+
+	response.WriteJSONResponse(w, reqID, status)
+}
+
+// GetFamilyPlanningCoverage reports postpartum family planning coverage per facility and district
+func (h *FamilyPlanningHandler) GetFamilyPlanningCoverage(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	reqID := response.GetRequestID(ctx)
+
+	var req GetFamilyPlanningCoverageRequest
+	requestedByID, ok := h.parseAndValidate(w, r, reqID, &req)
+	if !ok {
+		return
+	}
+
+	from, err := time.Parse("2006-01-02", req.From)
+	if err != nil {
+		response.WriteErrorResponse(w, reqID, errorx.New(errorx.BadRequest, "Invalid start date"))
+		return
+	}
+	to, err := time.Parse("2006-01-02", req.To)
+	if err != nil {
+		response.WriteErrorResponse(w, reqID, errorx.New(errorx.BadRequest, "Invalid end date"))
+		return
+	}
+
+	report, err := h.familyPlanningService.GetCoverageReport(ctx, requestedByID, from, to, optionalID(req.FacilityID), req.District)
+	if err != nil {
+		response.WriteErrorResponse(w, reqID, err)
+		return
+	}
+
+	response.WriteJSONResponse(w, reqID, report)
+}
+
+// SendFamilyPlanningReminders runs the re-supply reminder job, for use by a scheduled trigger
+func (h *FamilyPlanningHandler) SendFamilyPlanningReminders(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	reqID := response.GetRequestID(ctx)
+
+	report, err := h.reminderJob.Run(ctx)
+	if err != nil {
+		response.WriteErrorResponse(w, reqID, err)
+		return
+	}
+
+	response.WriteJSONResponse(w, reqID, report)
+}
+
+// parseAndValidate parses and validates a request and returns the ID of the user making it,
+// taken from the Hasura session. It writes the error response on failure.
+func (h *FamilyPlanningHandler) parseAndValidate(w http.ResponseWriter, r *http.Request, reqID string, req interface{}) (uuid.UUID, bool) {
+	actionReq, err := h.ParseRequest(r, req)
+	if err != nil {
+		h.log.Error("Failed to parse request", logger.Fields{
+			"request_id": reqID,
+			"error":      err.Error(),
+		})
+		response.WriteErrorResponse(w, reqID, err)
+		return uuid.Nil, false
+	}
+
+	requestedByID, err := actionReq.UserID()
+	if err != nil {
+		response.WriteErrorResponse(w, reqID, err)
+		return uuid.Nil, false
+	}
+
+	if err := h.validator.Validate(req); err != nil {
+		h.log.Error("Invalid request", logger.Fields{
+			"request_id": reqID,
+			"error":      err.Error(),
+		})
+		response.WriteErrorResponse(w, reqID, err)
+		return uuid.Nil, false
+	}
+
+	return requestedByID, true
+}
+
+// parseMotherID parses a mother ID, writing the error response on failure
+func parseMotherID(w http.ResponseWriter, reqID, mother string) (uuid.UUID, bool) {
+	motherID, err := uuid.Parse(mother)
+	if err != nil {
+		response.WriteErrorResponse(w, reqID, errorx.New(errorx.BadRequest, "Invalid mother ID"))
+		return uuid.Nil, false
+	}
+	return motherID, true
+}
@@ -0,0 +1,165 @@
+package action
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mamacare/services/internal/app/health/growth"
+	"github.com/mamacare/services/internal/port/hasura"
+	"github.com/mamacare/services/internal/port/response"
+	"github.com/mamacare/services/internal/port/validation"
+	"github.com/mamacare/services/pkg/errorx"
+	"github.com/mamacare/services/pkg/logger"
+)
+
+// GrowthMeasurementRequest is the request to record a fundal height or estimated fetal weight
+type GrowthMeasurementRequest struct {
+	MotherID             string   `json:"mother_id" validate:"required,uuid"`
+	FundalHeightCm       *float64 `json:"fundal_height_cm,omitempty"`
+	EstimatedFetalWeight *float64 `json:"estimated_fetal_weight,omitempty"`
+	RecordedAt           string   `json:"recorded_at,omitempty"`
+	VisitID              string   `json:"visit_id,omitempty" validate:"omitempty,uuid"`
+	Notes                string   `json:"notes,omitempty"`
+}
+
+// FetalGrowthRequest is the request for a mother's fetal growth charts
+type FetalGrowthRequest struct {
+	MotherID string `json:"mother_id" validate:"required,uuid"`
+}
+
+// GrowthHandler handles fetal growth chart actions
+type GrowthHandler struct {
+	hasura.BaseActionHandler
+	growthService *growth.Service
+	validator     *validation.Validator
+	log           logger.Logger
+}
+
+// NewGrowthHandler creates a new fetal growth handler
+func NewGrowthHandler(
+	log logger.Logger,
+	growthService *growth.Service,
+	validator *validation.Validator,
+) *GrowthHandler {
+	return &GrowthHandler{
+		BaseActionHandler: hasura.BaseActionHandler{},
+		growthService:     growthService,
+		validator:         validator,
+		log:               log,
+	}
+}
+
+// RecordGrowthMeasurement records a fundal height or estimated fetal weight and returns the updated growth charts
+func (h *GrowthHandler) RecordGrowthMeasurement(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	reqID := response.GetRequestID(ctx)
+
+	var req GrowthMeasurementRequest
+	actionReq, err := h.ParseRequest(r, &req)
+	if err != nil {
+		h.log.Error("Failed to parse request", logger.Fields{
+			"request_id": reqID,
+			"error":      err.Error(),
+		})
+		response.WriteErrorResponse(w, reqID, err)
+		return
+	}
+
+	// The measurement is recorded by the caller, never by an ID in the input
+	recordedByID, err := actionReq.UserID()
+	if err != nil {
+		response.WriteErrorResponse(w, reqID, err)
+		return
+	}
+
+	// Validate request
+	if err := h.validator.Validate(req); err != nil {
+		h.log.Error("Invalid request", logger.Fields{
+			"request_id": reqID,
+			"error":      err.Error(),
+		})
+		response.WriteErrorResponse(w, reqID, err)
+		return
+	}
+
+	motherID, err := uuid.Parse(req.MotherID)
+	if err != nil {
+		response.WriteErrorResponse(w, reqID, errorx.New(errorx.BadRequest, "Invalid mother ID"))
+		return
+	}
+
+	input := &growth.MeasurementInput{
+		FundalHeightCm:       req.FundalHeightCm,
+		EstimatedFetalWeight: req.EstimatedFetalWeight,
+		Notes:                req.Notes,
+	}
+
+	if req.RecordedAt != "" {
+		recordedAt, err := time.Parse(time.RFC3339, req.RecordedAt)
+		if err != nil {
+			response.WriteErrorResponse(w, reqID, errorx.New(errorx.BadRequest, "Invalid recorded at time. Use RFC3339"))
+			return
+		}
+		input.RecordedAt = recordedAt
+	}
+
+	if req.VisitID != "" {
+		visitID, err := uuid.Parse(req.VisitID)
+		if err != nil {
+			response.WriteErrorResponse(w, reqID, errorx.New(errorx.BadRequest, "Invalid visit ID"))
+			return
+		}
+		input.VisitID = &visitID
+	}
+
+	input.RecordedByID = &recordedByID
+
+	result, err := h.growthService.RecordMeasurement(ctx, motherID, input)
+	if err != nil {
+		h.log.Error("Failed to record growth measurement", logger.Fields{
+			"request_id": reqID,
+			"mother_id":  motherID.String(),
+			"error":      err.Error(),
+		})
+		response.WriteErrorResponse(w, reqID, err)
+		return
+	}
+
+	response.WriteJSONResponse(w, reqID, result)
+}
+
+// GetFetalGrowth returns a mother's fundal height and fetal weight charts with growth flags
+func (h *GrowthHandler) GetFetalGrowth(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	reqID := response.GetRequestID(ctx)
+
+	var req FetalGrowthRequest
+	if err := h.ParseRequest(r, &req); err != nil {
+		h.log.Error("Failed to parse request", logger.Fields{
+			"request_id": reqID,
+			"error":      err.Error(),
+		})
+		response.WriteErrorResponse(w, reqID, err)
+		return
+	}
+
+	if err := h.validator.Validate(req); err != nil {
+		response.WriteErrorResponse(w, reqID, err)
+		return
+	}
+
+	motherID, err := uuid.Parse(req.MotherID)
+	if err != nil {
+		response.WriteErrorResponse(w, reqID, errorx.New(errorx.BadRequest, "Invalid mother ID"))
+		return
+	}
+
+	assessment, err := h.growthService.AssessMother(ctx, motherID)
+	if err != nil {
+		response.WriteErrorResponse(w, reqID, err)
+		return
+	}
+
+	response.WriteJSONResponse(w, reqID, assessment)
+}
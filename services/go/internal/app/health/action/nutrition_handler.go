@@ -0,0 +1,400 @@
+package action
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mamacare/services/internal/app/health/nutrition"
+	"github.com/mamacare/services/internal/domain/model"
+	"github.com/mamacare/services/internal/port/hasura"
+	"github.com/mamacare/services/internal/port/response"
+	"github.com/mamacare/services/internal/port/validation"
+	"github.com/mamacare/services/pkg/errorx"
+	"github.com/mamacare/services/pkg/logger"
+)
+
+// BodyMeasurementsRequest is the request to record height and pre-pregnancy weight
+type BodyMeasurementsRequest struct {
+	MotherID           string  `json:"mother_id" validate:"required,uuid"`
+	HeightCm           float64 `json:"height_cm" validate:"required"`
+	PrePregnancyWeight float64 `json:"pre_pregnancy_weight" validate:"required"`
+}
+
+// NutritionMeasurementRequest is the request to record a weight or MUAC
+type NutritionMeasurementRequest struct {
+	MotherID   string     `json:"mother_id" validate:"required,uuid"`
+	WeightKg   *float64   `json:"weight_kg,omitempty"`
+	MUACCm     *float64   `json:"muac_cm,omitempty"`
+	RecordedAt *time.Time `json:"recorded_at,omitempty"`
+	VisitID    string     `json:"visit_id,omitempty" validate:"omitempty,uuid"`
+	FacilityID string     `json:"facility_id,omitempty" validate:"omitempty,uuid"`
+}
+
+// NutritionStatusRequest is the request for a mother's nutrition status
+type NutritionStatusRequest struct {
+	MotherID string `json:"mother_id" validate:"required,uuid"`
+}
+
+// NutritionCounsellingRequest is the request to record dietary counselling
+type NutritionCounsellingRequest struct {
+	MotherID     string     `json:"mother_id" validate:"required,uuid"`
+	Topics       []string   `json:"topics" validate:"required,min=1"`
+	Notes        string     `json:"notes,omitempty"`
+	VisitID      string     `json:"visit_id,omitempty" validate:"omitempty,uuid"`
+	CounselledAt *time.Time `json:"counselled_at,omitempty"`
+}
+
+// DispenseSupplementRequest is the request to dispense supplements at a visit
+type DispenseSupplementRequest struct {
+	EnrollmentID string `json:"enrollment_id" validate:"required,uuid"`
+	FacilityID   string `json:"facility_id" validate:"required,uuid"`
+	Item         string `json:"item" validate:"required,oneof=bep_ration mms_tablet ifa_tablet"`
+	Quantity     int    `json:"quantity" validate:"required,min=1"`
+	VisitID      string `json:"visit_id,omitempty" validate:"omitempty,uuid"`
+}
+
+// EndSupplementationRequest is the request to close a supplementation enrollment
+type EndSupplementationRequest struct {
+	EnrollmentID string `json:"enrollment_id" validate:"required,uuid"`
+	Status       string `json:"status" validate:"required,oneof=completed withdrawn"`
+	Reason       string `json:"reason,omitempty"`
+}
+
+// SupplementStockRequest is the request to receive stock or view a facility's stock
+type SupplementStockRequest struct {
+	FacilityID string `json:"facility_id" validate:"required,uuid"`
+	Item       string `json:"item,omitempty" validate:"omitempty,oneof=bep_ration mms_tablet ifa_tablet"`
+	Quantity   int    `json:"quantity,omitempty" validate:"omitempty,min=1"`
+}
+
+// NutritionHandler handles maternal nutrition and supplementation actions
+type NutritionHandler struct {
+	hasura.BaseActionHandler
+	nutritionService *nutrition.Service
+	validator        *validation.Validator
+	log              logger.Logger
+}
+
+// NewNutritionHandler creates a new nutrition handler
+func NewNutritionHandler(
+	log logger.Logger,
+	nutritionService *nutrition.Service,
+	validator *validation.Validator,
+) *NutritionHandler {
+	return &NutritionHandler{
+		BaseActionHandler: hasura.BaseActionHandler{},
+		nutritionService:  nutritionService,
+		validator:         validator,
+		log:               log,
+	}
+}
+
+// RecordBodyMeasurements sets a mother's height and pre-pregnancy weight and returns her nutrition status
+func (h *NutritionHandler) RecordBodyMeasurements(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	reqID := response.GetRequestID(ctx)
+
+	var req BodyMeasurementsRequest
+	if _, ok := h.parseAndValidate(w, r, reqID, &req); !ok {
+		return
+	}
+
+	motherID, err := uuid.Parse(req.MotherID)
+	if err != nil {
+		response.WriteErrorResponse(w, reqID, errorx.New(errorx.BadRequest, "Invalid mother ID"))
+		return
+	}
+
+	assessment, err := h.nutritionService.RecordBodyMeasurements(ctx, motherID, req.HeightCm, req.PrePregnancyWeight)
+	if err != nil {
+		h.log.Error("Failed to record body measurements", logger.Fields{
+			"request_id": reqID,
+			"mother_id":  motherID.String(),
+			"error":      err.Error(),
+		})
+		response.WriteErrorResponse(w, reqID, err)
+		return
+	}
+
+	response.WriteJSONResponse(w, reqID, assessment)
+}
+
+// RecordNutritionMeasurement records a weight or MUAC, flags poor or excessive gain and
+// enrolls undernourished mothers in supplementation
+func (h *NutritionHandler) RecordNutritionMeasurement(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	reqID := response.GetRequestID(ctx)
+
+	var req NutritionMeasurementRequest
+	// The measurement is recorded by the caller, never by an ID in the input
+	recordedByID, ok := h.parseAndValidate(w, r, reqID, &req)
+	if !ok {
+		return
+	}
+
+	motherID, err := uuid.Parse(req.MotherID)
+	if err != nil {
+		response.WriteErrorResponse(w, reqID, errorx.New(errorx.BadRequest, "Invalid mother ID"))
+		return
+	}
+
+	input := &nutrition.MeasurementInput{
+		WeightKg:     req.WeightKg,
+		MUACCm:       req.MUACCm,
+		VisitID:      optionalID(req.VisitID),
+		FacilityID:   optionalID(req.FacilityID),
+		RecordedByID: &recordedByID,
+	}
+	if req.RecordedAt != nil {
+		input.RecordedAt = *req.RecordedAt
+	}
+
+	assessment, err := h.nutritionService.RecordMeasurement(ctx, motherID, input)
+	if err != nil {
+		h.log.Error("Failed to record nutrition measurement", logger.Fields{
+			"request_id": reqID,
+			"mother_id":  motherID.String(),
+			"error":      err.Error(),
+		})
+		response.WriteErrorResponse(w, reqID, err)
+		return
+	}
+
+	response.WriteJSONResponse(w, reqID, assessment)
+}
+
+// GetNutritionStatus returns a mother's weight gain against her IOM target, MUAC and enrollment
+func (h *NutritionHandler) GetNutritionStatus(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	reqID := response.GetRequestID(ctx)
+
+	var req NutritionStatusRequest
+	if _, ok := h.parseAndValidate(w, r, reqID, &req); !ok {
+		return
+	}
+
+	motherID, err := uuid.Parse(req.MotherID)
+	if err != nil {
+		response.WriteErrorResponse(w, reqID, errorx.New(errorx.BadRequest, "Invalid mother ID"))
+		return
+	}
+
+	assessment, err := h.nutritionService.EvaluateMother(ctx, motherID, nil)
+	if err != nil {
+		response.WriteErrorResponse(w, reqID, err)
+		return
+	}
+
+	response.WriteJSONResponse(w, reqID, assessment)
+}
+
+// RecordNutritionCounselling records a dietary counselling session
+func (h *NutritionHandler) RecordNutritionCounselling(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	reqID := response.GetRequestID(ctx)
+
+	var req NutritionCounsellingRequest
+	// Counselling is given by the caller, never by an ID in the input
+	counselledByID, ok := h.parseAndValidate(w, r, reqID, &req)
+	if !ok {
+		return
+	}
+
+	motherID, err := uuid.Parse(req.MotherID)
+	if err != nil {
+		response.WriteErrorResponse(w, reqID, errorx.New(errorx.BadRequest, "Invalid mother ID"))
+		return
+	}
+
+	input := &nutrition.CounsellingInput{
+		Notes:          req.Notes,
+		VisitID:        optionalID(req.VisitID),
+		CounselledByID: &counselledByID,
+	}
+	for _, topic := range req.Topics {
+		input.Topics = append(input.Topics, model.CounsellingTopic(topic))
+	}
+	if req.CounselledAt != nil {
+		input.CounselledAt = *req.CounselledAt
+	}
+
+	counselling, err := h.nutritionService.RecordCounselling(ctx, motherID, input)
+	if err != nil {
+		h.log.Error("Failed to record nutrition counselling", logger.Fields{
+			"request_id": reqID,
+			"mother_id":  motherID.String(),
+			"error":      err.Error(),
+		})
+		response.WriteErrorResponse(w, reqID, err)
+		return
+	}
+
+	response.WriteJSONResponse(w, reqID, counselling)
+}
+
+// DispenseSupplement records supplements handed to an enrolled mother and returns the stock left
+func (h *NutritionHandler) DispenseSupplement(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	reqID := response.GetRequestID(ctx)
+
+	var req DispenseSupplementRequest
+	// Supplements are dispensed by the caller, never by an ID in the input
+	dispensedByID, ok := h.parseAndValidate(w, r, reqID, &req)
+	if !ok {
+		return
+	}
+
+	enrollmentID, err := uuid.Parse(req.EnrollmentID)
+	if err != nil {
+		response.WriteErrorResponse(w, reqID, errorx.New(errorx.BadRequest, "Invalid enrollment ID"))
+		return
+	}
+	facilityID, err := uuid.Parse(req.FacilityID)
+	if err != nil {
+		response.WriteErrorResponse(w, reqID, errorx.New(errorx.BadRequest, "Invalid facility ID"))
+		return
+	}
+
+	result, err := h.nutritionService.DispenseSupplement(ctx, &nutrition.DispenseInput{
+		EnrollmentID:  enrollmentID,
+		FacilityID:    facilityID,
+		Item:          model.SupplementItem(req.Item),
+		Quantity:      req.Quantity,
+		VisitID:       optionalID(req.VisitID),
+		DispensedByID: &dispensedByID,
+	})
+	if err != nil {
+		h.log.Error("Failed to dispense supplement", logger.Fields{
+			"request_id":    reqID,
+			"enrollment_id": enrollmentID.String(),
+			"error":         err.Error(),
+		})
+		response.WriteErrorResponse(w, reqID, err)
+		return
+	}
+
+	response.WriteJSONResponse(w, reqID, result)
+}
+
+// EndSupplementation closes a mother's supplementation enrollment
+func (h *NutritionHandler) EndSupplementation(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	reqID := response.GetRequestID(ctx)
+
+	var req EndSupplementationRequest
+	if _, ok := h.parseAndValidate(w, r, reqID, &req); !ok {
+		return
+	}
+
+	enrollmentID, err := uuid.Parse(req.EnrollmentID)
+	if err != nil {
+		response.WriteErrorResponse(w, reqID, errorx.New(errorx.BadRequest, "Invalid enrollment ID"))
+		return
+	}
+
+	enrollment, err := h.nutritionService.EndEnrollment(ctx, enrollmentID, model.EnrollmentStatus(req.Status), req.Reason)
+	if err != nil {
+		response.WriteErrorResponse(w, reqID, err)
+		return
+	}
+
+	response.WriteJSONResponse(w, reqID, enrollment)
+}
+
+// ReceiveSupplementStock adds a delivery of supplements to a facility's stock
+func (h *NutritionHandler) ReceiveSupplementStock(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	reqID := response.GetRequestID(ctx)
+
+	var req SupplementStockRequest
+	if _, ok := h.parseAndValidate(w, r, reqID, &req); !ok {
+		return
+	}
+	if req.Item == "" || req.Quantity == 0 {
+		response.WriteErrorResponse(w, reqID, errorx.New(errorx.BadRequest, "item and quantity are required"))
+		return
+	}
+
+	facilityID, err := uuid.Parse(req.FacilityID)
+	if err != nil {
+		response.WriteErrorResponse(w, reqID, errorx.New(errorx.BadRequest, "Invalid facility ID"))
+		return
+	}
+
+	stock, err := h.nutritionService.ReceiveStock(ctx, facilityID, model.SupplementItem(req.Item), req.Quantity)
+	if err != nil {
+		response.WriteErrorResponse(w, reqID, err)
+		return
+	}
+
+	response.WriteJSONResponse(w, reqID, stock)
+}
+
+// GetSupplementStock returns a facility's supplement stock
+func (h *NutritionHandler) GetSupplementStock(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	reqID := response.GetRequestID(ctx)
+
+	var req SupplementStockRequest
+	if _, ok := h.parseAndValidate(w, r, reqID, &req); !ok {
+		return
+	}
+
+	facilityID, err := uuid.Parse(req.FacilityID)
+	if err != nil {
+		response.WriteErrorResponse(w, reqID, errorx.New(errorx.BadRequest, "Invalid facility ID"))
+		return
+	}
+
+	stocks, err := h.nutritionService.GetFacilityStock(ctx, facilityID)
+	if err != nil {
+		response.WriteErrorResponse(w, reqID, err)
+		return
+	}
+
+	response.WriteJSONResponse(w, reqID, stocks)
+}
+
+// parseAndValidate parses and validates a request, returning the calling user's ID and
+// writing the error response on failure
+func (h *NutritionHandler) parseAndValidate(w http.ResponseWriter, r *http.Request, reqID string, req interface{}) (uuid.UUID, bool) {
+	actionReq, err := h.ParseRequest(r, req)
+	if err != nil {
+		h.log.Error("Failed to parse request", logger.Fields{
+			"request_id": reqID,
+			"error":      err.Error(),
+		})
+		response.WriteErrorResponse(w, reqID, err)
+		return uuid.Nil, false
+	}
+
+	callerID, err := actionReq.UserID()
+	if err != nil {
+		response.WriteErrorResponse(w, reqID, err)
+		return uuid.Nil, false
+	}
+
+	if err := h.validator.Validate(req); err != nil {
+		h.log.Error("Invalid request", logger.Fields{
+			"request_id": reqID,
+			"error":      err.Error(),
+		})
+		response.WriteErrorResponse(w, reqID, err)
+		return uuid.Nil, false
+	}
+
+	return callerID, true
+}
+
+// optionalID parses an optional ID the validator has already checked is a UUID
+func optionalID(value string) *uuid.UUID {
+	if value == "" {
+		return nil
+	}
+	id, err := uuid.Parse(value)
+	if err != nil {
+		return nil
+	}
+	return &id
+}
@@ -0,0 +1,284 @@
+package action
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mamacare/services/internal/app/health/partograph"
+	"github.com/mamacare/services/internal/domain/model"
+	"github.com/mamacare/services/internal/port/hasura"
+	"github.com/mamacare/services/internal/port/response"
+	"github.com/mamacare/services/internal/port/validation"
+	"github.com/mamacare/services/pkg/errorx"
+	"github.com/mamacare/services/pkg/logger"
+)
+
+// OpenPartographRequest is the request to start a partograph
+type OpenPartographRequest struct {
+	MotherID   string     `json:"mother_id" validate:"required,uuid"`
+	FacilityID string     `json:"facility_id" validate:"required,uuid"`
+	AdmittedAt *time.Time `json:"admitted_at,omitempty"`
+}
+
+// PartographObservationRequest is the request to record labour observations
+type PartographObservationRequest struct {
+	PartographID       string                    `json:"partograph_id" validate:"required,uuid"`
+	RecordedAt         *time.Time                `json:"recorded_at,omitempty"`
+	CervicalDilationCm *float64                  `json:"cervical_dilation_cm,omitempty" validate:"omitempty,min=0,max=10"`
+	DescentFifths      *int                      `json:"descent_fifths,omitempty" validate:"omitempty,min=0,max=5"`
+	Contractions       *model.ContractionReading `json:"contractions,omitempty"`
+	FetalHeartRate     *float64                  `json:"fetal_heart_rate,omitempty"`
+	Liquor             *model.LiquorStatus       `json:"liquor,omitempty"`
+	BloodPressure      *model.BloodPressure      `json:"blood_pressure,omitempty"`
+	PulseRate          *float64                  `json:"pulse_rate,omitempty"`
+	TemperatureC       *float64                  `json:"temperature_c,omitempty"`
+	Notes              string                    `json:"notes,omitempty"`
+}
+
+// ReferPartographRequest is the request to refer labour to a hospital
+type ReferPartographRequest struct {
+	PartographID string `json:"partograph_id" validate:"required,uuid"`
+	Reason       string `json:"reason" validate:"required"`
+}
+
+// ClosePartographRequest is the request to stop labour monitoring
+type ClosePartographRequest struct {
+	PartographID string `json:"partograph_id" validate:"required,uuid"`
+	Status       string `json:"status" validate:"required,oneof=delivered closed"`
+}
+
+// PartographRequest is the request for a single partograph
+type PartographRequest struct {
+	PartographID string `json:"partograph_id" validate:"required,uuid"`
+}
+
+// PartographHandler handles partograph actions
+type PartographHandler struct {
+	hasura.BaseActionHandler
+	partographService *partograph.Service
+	validator         *validation.Validator
+	log               logger.Logger
+}
+
+// NewPartographHandler creates a new partograph handler
+func NewPartographHandler(
+	log logger.Logger,
+	partographService *partograph.Service,
+	validator *validation.Validator,
+) *PartographHandler {
+	return &PartographHandler{
+		BaseActionHandler: hasura.BaseActionHandler{},
+		partographService: partographService,
+		validator:         validator,
+		log:               log,
+	}
+}
+
+// OpenPartograph starts a partograph for a mother admitted in labour
+func (h *PartographHandler) OpenPartograph(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	reqID := response.GetRequestID(ctx)
+
+	var req OpenPartographRequest
+	// The attendant is the caller, never an ID in the input
+	attendantID, ok := h.parseAndValidate(w, r, reqID, &req)
+	if !ok {
+		return
+	}
+
+	motherID, err := uuid.Parse(req.MotherID)
+	if err != nil {
+		response.WriteErrorResponse(w, reqID, errorx.New(errorx.BadRequest, "Invalid mother ID"))
+		return
+	}
+	facilityID, err := uuid.Parse(req.FacilityID)
+	if err != nil {
+		response.WriteErrorResponse(w, reqID, errorx.New(errorx.BadRequest, "Invalid facility ID"))
+		return
+	}
+
+	admittedAt := time.Now()
+	if req.AdmittedAt != nil {
+		admittedAt = *req.AdmittedAt
+	}
+
+	result, err := h.partographService.OpenPartograph(ctx, motherID, facilityID, attendantID, admittedAt)
+	if err != nil {
+		h.log.Error("Failed to open partograph", logger.Fields{
+			"request_id": reqID,
+			"mother_id":  motherID.String(),
+			"error":      err.Error(),
+		})
+		response.WriteErrorResponse(w, reqID, err)
+		return
+	}
+
+	response.WriteJSONResponse(w, reqID, result)
+}
+
+// RecordPartographObservation plots labour observations and returns any alerts or referral
+func (h *PartographHandler) RecordPartographObservation(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	reqID := response.GetRequestID(ctx)
+
+	var req PartographObservationRequest
+	// Observations are recorded by the caller, never by an ID in the input
+	recordedByID, ok := h.parseAndValidate(w, r, reqID, &req)
+	if !ok {
+		return
+	}
+
+	partographID, err := uuid.Parse(req.PartographID)
+	if err != nil {
+		response.WriteErrorResponse(w, reqID, errorx.New(errorx.BadRequest, "Invalid partograph ID"))
+		return
+	}
+
+	input := &partograph.ObservationInput{
+		RecordedByID:       recordedByID,
+		CervicalDilationCm: req.CervicalDilationCm,
+		DescentFifths:      req.DescentFifths,
+		Contractions:       req.Contractions,
+		FetalHeartRate:     req.FetalHeartRate,
+		Liquor:             req.Liquor,
+		BloodPressure:      req.BloodPressure,
+		PulseRate:          req.PulseRate,
+		TemperatureC:       req.TemperatureC,
+		Notes:              req.Notes,
+	}
+	if req.RecordedAt != nil {
+		input.RecordedAt = *req.RecordedAt
+	}
+
+	result, err := h.partographService.RecordObservation(ctx, partographID, input)
+	if err != nil {
+		h.log.Error("Failed to record partograph observation", logger.Fields{
+			"request_id":    reqID,
+			"partograph_id": partographID.String(),
+			"error":         err.Error(),
+		})
+		response.WriteErrorResponse(w, reqID, err)
+		return
+	}
+
+	response.WriteJSONResponse(w, reqID, result)
+}
+
+// ReferPartograph refers labour to the nearest hospital through the SOS flow
+func (h *PartographHandler) ReferPartograph(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	reqID := response.GetRequestID(ctx)
+
+	var req ReferPartographRequest
+	// The referral is reported by the caller, never by an ID in the input
+	reportedByID, ok := h.parseAndValidate(w, r, reqID, &req)
+	if !ok {
+		return
+	}
+
+	partographID, err := uuid.Parse(req.PartographID)
+	if err != nil {
+		response.WriteErrorResponse(w, reqID, errorx.New(errorx.BadRequest, "Invalid partograph ID"))
+		return
+	}
+
+	sosEvent, err := h.partographService.Refer(ctx, partographID, reportedByID, req.Reason)
+	if err != nil {
+		h.log.Error("Failed to refer partograph", logger.Fields{
+			"request_id":    reqID,
+			"partograph_id": partographID.String(),
+			"error":         err.Error(),
+		})
+		response.WriteErrorResponse(w, reqID, err)
+		return
+	}
+
+	response.WriteJSONResponse(w, reqID, sosEvent)
+}
+
+// ClosePartograph stops labour monitoring
+func (h *PartographHandler) ClosePartograph(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	reqID := response.GetRequestID(ctx)
+
+	var req ClosePartographRequest
+	if _, ok := h.parseAndValidate(w, r, reqID, &req); !ok {
+		return
+	}
+
+	partographID, err := uuid.Parse(req.PartographID)
+	if err != nil {
+		response.WriteErrorResponse(w, reqID, errorx.New(errorx.BadRequest, "Invalid partograph ID"))
+		return
+	}
+
+	result, err := h.partographService.ClosePartograph(ctx, partographID, model.PartographStatus(req.Status))
+	if err != nil {
+		h.log.Error("Failed to close partograph", logger.Fields{
+			"request_id":    reqID,
+			"partograph_id": partographID.String(),
+			"error":         err.Error(),
+		})
+		response.WriteErrorResponse(w, reqID, err)
+		return
+	}
+
+	response.WriteJSONResponse(w, reqID, result)
+}
+
+// GetPartographChart returns the alert line, action line and plotted observations
+func (h *PartographHandler) GetPartographChart(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	reqID := response.GetRequestID(ctx)
+
+	var req PartographRequest
+	if _, ok := h.parseAndValidate(w, r, reqID, &req); !ok {
+		return
+	}
+
+	partographID, err := uuid.Parse(req.PartographID)
+	if err != nil {
+		response.WriteErrorResponse(w, reqID, errorx.New(errorx.BadRequest, "Invalid partograph ID"))
+		return
+	}
+
+	result, err := h.partographService.GetPartograph(ctx, partographID)
+	if err != nil {
+		response.WriteErrorResponse(w, reqID, err)
+		return
+	}
+
+	response.WriteJSONResponse(w, reqID, partograph.BuildChart(result))
+}
+
+// parseAndValidate parses and validates a request, returning the calling user's ID and
+// writing the error response on failure
+func (h *PartographHandler) parseAndValidate(w http.ResponseWriter, r *http.Request, reqID string, req interface{}) (uuid.UUID, bool) {
+	actionReq, err := h.ParseRequest(r, req)
+	if err != nil {
+		h.log.Error("Failed to parse request", logger.Fields{
+			"request_id": reqID,
+			"error":      err.Error(),
+		})
+		response.WriteErrorResponse(w, reqID, err)
+		return uuid.Nil, false
+	}
+
+	callerID, err := actionReq.UserID()
+	if err != nil {
+		response.WriteErrorResponse(w, reqID, err)
+		return uuid.Nil, false
+	}
+
+	if err := h.validator.Validate(req); err != nil {
+		h.log.Error("Invalid request", logger.Fields{
+			"request_id": reqID,
+			"error":      err.Error(),
+		})
+		response.WriteErrorResponse(w, reqID, err)
+		return uuid.Nil, false
+	}
+
+	return callerID, true
+}
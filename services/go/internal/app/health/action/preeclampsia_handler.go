@@ -0,0 +1,96 @@
+package action
+
+import (
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/mamacare/services/internal/app/health/preeclampsia"
+	"github.com/mamacare/services/internal/port/hasura"
+	"github.com/mamacare/services/internal/port/response"
+	"github.com/mamacare/services/internal/port/validation"
+	"github.com/mamacare/services/pkg/errorx"
+	"github.com/mamacare/services/pkg/logger"
+)
+
+// PreeclampsiaAssessmentRequest is the request for a pre-eclampsia assessment
+type PreeclampsiaAssessmentRequest struct {
+	MotherID string   `json:"mother_id" validate:"required,uuid"`
+	Symptoms []string `json:"symptoms,omitempty"`
+}
+
+// PreeclampsiaHandler handles pre-eclampsia assessment actions
+type PreeclampsiaHandler struct {
+	hasura.BaseActionHandler
+	preeclampsiaService *preeclampsia.Service
+	validator           *validation.Validator
+	log                 logger.Logger
+}
+
+// NewPreeclampsiaHandler creates a new pre-eclampsia handler
+func NewPreeclampsiaHandler(
+	log logger.Logger,
+	preeclampsiaService *preeclampsia.Service,
+	validator *validation.Validator,
+) *PreeclampsiaHandler {
+	return &PreeclampsiaHandler{
+		BaseActionHandler:   hasura.BaseActionHandler{},
+		preeclampsiaService: preeclampsiaService,
+		validator:           validator,
+		log:                 log,
+	}
+}
+
+// AssessPreeclampsia classifies a mother's hypertensive status and suggests a referral or SOS
+func (h *PreeclampsiaHandler) AssessPreeclampsia(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	reqID := response.GetRequestID(ctx)
+
+	var req PreeclampsiaAssessmentRequest
+	if err := h.ParseRequest(r, &req); err != nil {
+		h.log.Error("Failed to parse request", logger.Fields{
+			"request_id": reqID,
+			"error":      err.Error(),
+		})
+		response.WriteErrorResponse(w, reqID, err)
+		return
+	}
+
+	// Validate request
+	if err := h.validator.Validate(req); err != nil {
+		h.log.Error("Invalid request", logger.Fields{
+			"request_id": reqID,
+			"error":      err.Error(),
+		})
+		response.WriteErrorResponse(w, reqID, err)
+		return
+	}
+
+	motherID, err := uuid.Parse(req.MotherID)
+	if err != nil {
+		response.WriteErrorResponse(w, reqID, errorx.New(errorx.BadRequest, "Invalid mother ID"))
+		return
+	}
+
+	symptoms := make([]preeclampsia.DangerSign, 0, len(req.Symptoms))
+	for _, code := range req.Symptoms {
+		sign, ok := preeclampsia.ParseDangerSign(code)
+		if !ok {
+			response.WriteErrorResponse(w, reqID, errorx.Newf(errorx.BadRequest, "Unknown symptom: %s", code))
+			return
+		}
+		symptoms = append(symptoms, sign)
+	}
+
+	assessment, err := h.preeclampsiaService.AssessMother(ctx, motherID, symptoms)
+	if err != nil {
+		h.log.Error("Failed to assess pre-eclampsia", logger.Fields{
+			"request_id": reqID,
+			"mother_id":  motherID.String(),
+			"error":      err.Error(),
+		})
+		response.WriteErrorResponse(w, reqID, err)
+		return
+	}
+
+	response.WriteJSONResponse(w, reqID, assessment)
+}
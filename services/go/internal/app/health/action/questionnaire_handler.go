@@ -0,0 +1,438 @@
+package action
+
+import (
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/mamacare/services/internal/app/health/screener"
+	"github.com/mamacare/services/internal/domain/model"
+	"github.com/mamacare/services/internal/port/hasura"
+	"github.com/mamacare/services/internal/port/response"
+	"github.com/mamacare/services/internal/port/validation"
+	"github.com/mamacare/services/pkg/errorx"
+	"github.com/mamacare/services/pkg/logger"
+)
+
+// ListQuestionnairesRequest is the request for the screener questionnaire versions
+type ListQuestionnairesRequest struct {
+	Subject string `json:"subject,omitempty" validate:"omitempty,oneof=mother child"`
+}
+
+// QuestionnaireRequest is a request about one screener questionnaire version
+type QuestionnaireRequest struct {
+	QuestionnaireID string `json:"questionnaire_id" validate:"required,uuid"`
+}
+
+// CreateQuestionnaireDraftRequest is the request to start a new questionnaire version
+type CreateQuestionnaireDraftRequest struct {
+	// Subject is taken from the version the draft is based on when not given
+	Subject   string `json:"subject,omitempty" validate:"omitempty,oneof=mother child"`
+	Title     string `json:"title" validate:"required"`
+	Notes     string `json:"notes,omitempty"`
+	BasedOnID string `json:"based_on_id,omitempty" validate:"omitempty,uuid"`
+}
+
+// UpdateQuestionnaireDraftRequest is the request to change a draft's title and notes
+type UpdateQuestionnaireDraftRequest struct {
+	QuestionnaireRequest
+	Title string `json:"title" validate:"required"`
+	Notes string `json:"notes,omitempty"`
+}
+
+// QuestionDetails is a screener question as an admin writes it
+type QuestionDetails struct {
+	Text                string                 `json:"question_text" validate:"required"`
+	Code                string                 `json:"question_code" validate:"required"`
+	AnswerType          string                 `json:"answer_type" validate:"required,oneof=BOOLEAN MULTIPLE_CHOICE NUMERIC TEXT"`
+	AnswerOptions       []model.ScreenerOption `json:"answer_options,omitempty"`
+	IsDangerSign        bool                   `json:"is_danger_sign"`
+	RiskCategory        string                 `json:"risk_category,omitempty" validate:"omitempty,oneof=GREEN YELLOW RED"`
+	Category            string                 `json:"category" validate:"required"`
+	Subcategory         string                 `json:"subcategory,omitempty"`
+	DisplayOrder        int                    `json:"display_order"`
+	DependsOnQuestionID string                 `json:"depends_on_question_id,omitempty" validate:"omitempty,uuid"`
+	DependsOnAnswer     *string                `json:"depends_on_answer,omitempty"`
+	TranslationKey      string                 `json:"translation_key" validate:"required"`
+	// IsActive defaults to true
+	IsActive *bool `json:"is_active,omitempty"`
+}
+
+// AddQuestionRequest is the request to add a question to a draft
+type AddQuestionRequest struct {
+	QuestionnaireRequest
+	QuestionDetails
+}
+
+// UpdateQuestionRequest is the request to replace a question of a draft
+type UpdateQuestionRequest struct {
+	QuestionID string `json:"question_id" validate:"required,uuid"`
+	QuestionDetails
+}
+
+// RemoveQuestionRequest is the request to delete a question from a draft
+type RemoveQuestionRequest struct {
+	QuestionID string `json:"question_id" validate:"required,uuid"`
+}
+
+// PreviewQuestionnaireRequest is the request to play answers through a questionnaire version
+type PreviewQuestionnaireRequest struct {
+	QuestionnaireRequest
+	Answers []ScreenerAnswerRequest `json:"answers" validate:"dive"`
+}
+
+// QuestionnaireHandler handles the admin actions for authoring and publishing screener questionnaires
+type QuestionnaireHandler struct {
+	hasura.BaseActionHandler
+	authoringService *screener.AuthoringService
+	validator        *validation.Validator
+	log              logger.Logger
+}
+
+// NewQuestionnaireHandler creates a new questionnaire handler
+func NewQuestionnaireHandler(
+	log logger.Logger,
+	authoringService *screener.AuthoringService,
+	validator *validation.Validator,
+) *QuestionnaireHandler {
+	return &QuestionnaireHandler{
+		BaseActionHandler: hasura.BaseActionHandler{},
+		authoringService:  authoringService,
+		validator:         validator,
+		log:               log,
+	}
+}
+
+// ListQuestionnaires returns the questionnaire versions, newest first
+func (h *QuestionnaireHandler) ListQuestionnaires(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	reqID := response.GetRequestID(ctx)
+
+	var req ListQuestionnairesRequest
+	requestedByID, ok := h.parseAndValidate(w, r, reqID, &req)
+	if !ok {
+		return
+	}
+
+	var subject *model.ScreenerSubject
+	if req.Subject != "" {
+		value := model.ScreenerSubject(req.Subject)
+		subject = &value
+	}
+
+	questionnaires, err := h.authoringService.ListQuestionnaires(ctx, requestedByID, subject)
+	if err != nil {
+		response.WriteErrorResponse(w, reqID, err)
+		return
+	}
+
+	response.WriteJSONResponse(w, reqID, questionnaires)
+}
+
+// GetQuestionnaire returns a questionnaire version with all its questions
+func (h *QuestionnaireHandler) GetQuestionnaire(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	reqID := response.GetRequestID(ctx)
+
+	var req QuestionnaireRequest
+	requestedByID, ok := h.parseAndValidate(w, r, reqID, &req)
+	if !ok {
+		return
+	}
+
+	questionnaireID, ok := parseQuestionnaireID(w, reqID, req.QuestionnaireID)
+	if !ok {
+		return
+	}
+
+	questionnaire, err := h.authoringService.GetQuestionnaire(ctx, requestedByID, questionnaireID)
+	if err != nil {
+		response.WriteErrorResponse(w, reqID, err)
+		return
+	}
+
+	response.WriteJSONResponse(w, reqID, questionnaire)
+}
+
+// CreateDraft starts a new questionnaire version, optionally copying an existing one
+func (h *QuestionnaireHandler) CreateDraft(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	reqID := response.GetRequestID(ctx)
+
+	var req CreateQuestionnaireDraftRequest
+	requestedByID, ok := h.parseAndValidate(w, r, reqID, &req)
+	if !ok {
+		return
+	}
+
+	questionnaire, err := h.authoringService.CreateDraft(ctx, requestedByID, &screener.DraftInput{
+		Subject:   model.ScreenerSubject(req.Subject),
+		Title:     req.Title,
+		Notes:     req.Notes,
+		BasedOnID: optionalID(req.BasedOnID),
+	})
+	if err != nil {
+		response.WriteErrorResponse(w, reqID, err)
+		return
+	}
+
+	response.WriteJSONResponse(w, reqID, questionnaire)
+}
+
+// UpdateDraft changes a draft's title and notes
+func (h *QuestionnaireHandler) UpdateDraft(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	reqID := response.GetRequestID(ctx)
+
+	var req UpdateQuestionnaireDraftRequest
+	requestedByID, ok := h.parseAndValidate(w, r, reqID, &req)
+	if !ok {
+		return
+	}
+
+	questionnaireID, ok := parseQuestionnaireID(w, reqID, req.QuestionnaireID)
+	if !ok {
+		return
+	}
+
+	questionnaire, err := h.authoringService.UpdateDraft(ctx, requestedByID, questionnaireID, req.Title, req.Notes)
+	if err != nil {
+		response.WriteErrorResponse(w, reqID, err)
+		return
+	}
+
+	response.WriteJSONResponse(w, reqID, questionnaire)
+}
+
+// AddQuestion adds a question to a draft
+func (h *QuestionnaireHandler) AddQuestion(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	reqID := response.GetRequestID(ctx)
+
+	var req AddQuestionRequest
+	requestedByID, ok := h.parseAndValidate(w, r, reqID, &req)
+	if !ok {
+		return
+	}
+
+	questionnaireID, ok := parseQuestionnaireID(w, reqID, req.QuestionnaireID)
+	if !ok {
+		return
+	}
+
+	question, err := h.authoringService.AddQuestion(ctx, requestedByID, questionnaireID, questionInput(&req.QuestionDetails))
+	if err != nil {
+		response.WriteErrorResponse(w, reqID, err)
+		return
+	}
+
+	response.WriteJSONResponse(w, reqID, question)
+}
+
+// UpdateQuestion replaces a question of a draft
+func (h *QuestionnaireHandler) UpdateQuestion(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	reqID := response.GetRequestID(ctx)
+
+	var req UpdateQuestionRequest
+	requestedByID, ok := h.parseAndValidate(w, r, reqID, &req)
+	if !ok {
+		return
+	}
+
+	questionID, err := uuid.Parse(req.QuestionID)
+	if err != nil {
+		response.WriteErrorResponse(w, reqID, errorx.New(errorx.BadRequest, "Invalid question ID"))
+		return
+	}
+
+	question, err := h.authoringService.UpdateQuestion(ctx, requestedByID, questionID, questionInput(&req.QuestionDetails))
+	if err != nil {
+		response.WriteErrorResponse(w, reqID, err)
+		return
+	}
+
+	response.WriteJSONResponse(w, reqID, question)
+}
+
+// RemoveQuestion deletes a question from a draft
+func (h *QuestionnaireHandler) RemoveQuestion(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	reqID := response.GetRequestID(ctx)
+
+	var req RemoveQuestionRequest
+	requestedByID, ok := h.parseAndValidate(w, r, reqID, &req)
+	if !ok {
+		return
+	}
+
+	questionID, err := uuid.Parse(req.QuestionID)
+	if err != nil {
+		response.WriteErrorResponse(w, reqID, errorx.New(errorx.BadRequest, "Invalid question ID"))
+		return
+	}
+
+	if err := h.authoringService.RemoveQuestion(ctx, requestedByID, questionID); err != nil {
+		response.WriteErrorResponse(w, reqID, err)
+		return
+	}
+
+	response.WriteJSONResponse(w, reqID, struct {
+		Success bool `json:"success"`
+	}{
+		Success: true,
+	})
+}
+
+// ValidateQuestionnaire checks a questionnaire version for problems that would stop it being published
+func (h *QuestionnaireHandler) ValidateQuestionnaire(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	reqID := response.GetRequestID(ctx)
+
+	var req QuestionnaireRequest
+	requestedByID, ok := h.parseAndValidate(w, r, reqID, &req)
+	if !ok {
+		return
+	}
+
+	questionnaireID, ok := parseQuestionnaireID(w, reqID, req.QuestionnaireID)
+	if !ok {
+		return
+	}
+
+	report, err := h.authoringService.Validate(ctx, requestedByID, questionnaireID)
+	if err != nil {
+		response.WriteErrorResponse(w, reqID, err)
+		return
+	}
+
+	response.WriteJSONResponse(w, reqID, report)
+}
+
+// PreviewQuestionnaire plays answers through a questionnaire version without saving a screening
+func (h *QuestionnaireHandler) PreviewQuestionnaire(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	reqID := response.GetRequestID(ctx)
+
+	var req PreviewQuestionnaireRequest
+	requestedByID, ok := h.parseAndValidate(w, r, reqID, &req)
+	if !ok {
+		return
+	}
+
+	questionnaireID, ok := parseQuestionnaireID(w, reqID, req.QuestionnaireID)
+	if !ok {
+		return
+	}
+
+	answers := make([]screener.AnswerInput, 0, len(req.Answers))
+	for _, answer := range req.Answers {
+		questionID, err := uuid.Parse(answer.QuestionID)
+		if err != nil {
+			response.WriteErrorResponse(w, reqID, errorx.New(errorx.BadRequest, "Invalid question ID"))
+			return
+		}
+		answers = append(answers, screener.AnswerInput{QuestionID: questionID, Value: answer.Value})
+	}
+
+	preview, err := h.authoringService.Preview(ctx, requestedByID, questionnaireID, answers)
+	if err != nil {
+		response.WriteErrorResponse(w, reqID, err)
+		return
+	}
+
+	response.WriteJSONResponse(w, reqID, preview)
+}
+
+// PublishQuestionnaire publishes a draft, retiring the version published before it
+func (h *QuestionnaireHandler) PublishQuestionnaire(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	reqID := response.GetRequestID(ctx)
+
+	var req QuestionnaireRequest
+	requestedByID, ok := h.parseAndValidate(w, r, reqID, &req)
+	if !ok {
+		return
+	}
+
+	questionnaireID, ok := parseQuestionnaireID(w, reqID, req.QuestionnaireID)
+	if !ok {
+		return
+	}
+
+	questionnaire, err := h.authoringService.Publish(ctx, requestedByID, questionnaireID)
+	if err != nil {
+		response.WriteErrorResponse(w, reqID, err)
+		return
+	}
+
+	response.WriteJSONResponse(w, reqID, questionnaire)
+}
+
+// parseAndValidate parses and validates a request and returns the ID of the user making it,
+// taken from the Hasura session, which must have the admin role. It writes the error
+// response on failure.
+func (h *QuestionnaireHandler) parseAndValidate(w http.ResponseWriter, r *http.Request, reqID string, req interface{}) (uuid.UUID, bool) {
+	actionReq, err := h.ParseRequest(r, req)
+	if err != nil {
+		h.log.Error("Failed to parse request", logger.Fields{
+			"request_id": reqID,
+			"error":      err.Error(),
+		})
+		response.WriteErrorResponse(w, reqID, err)
+		return uuid.Nil, false
+	}
+
+	requestedByID, err := actionReq.UserID()
+	if err != nil {
+		response.WriteErrorResponse(w, reqID, err)
+		return uuid.Nil, false
+	}
+	if model.UserRole(actionReq.Role()) != model.RoleAdmin {
+		response.WriteErrorResponse(w, reqID, errorx.New(errorx.Forbidden, "only admins can author screener questionnaires"))
+		return uuid.Nil, false
+	}
+
+	if err := h.validator.Validate(req); err != nil {
+		h.log.Error("Invalid request", logger.Fields{
+			"request_id": reqID,
+			"error":      err.Error(),
+		})
+		response.WriteErrorResponse(w, reqID, err)
+		return uuid.Nil, false
+	}
+
+	return requestedByID, true
+}
+
+// parseQuestionnaireID parses a questionnaire ID, writing the error response on failure
+func parseQuestionnaireID(w http.ResponseWriter, reqID, questionnaire string) (uuid.UUID, bool) {
+	questionnaireID, err := uuid.Parse(questionnaire)
+	if err != nil {
+		response.WriteErrorResponse(w, reqID, errorx.New(errorx.BadRequest, "Invalid questionnaire ID"))
+		return uuid.Nil, false
+	}
+	return questionnaireID, true
+}
+
+// questionInput converts question details the validator has already checked
+func questionInput(details *QuestionDetails) *screener.QuestionInput {
+	input := &screener.QuestionInput{
+		Text:                details.Text,
+		Code:                details.Code,
+		AnswerType:          model.ScreenerAnswerType(details.AnswerType),
+		AnswerOptions:       details.AnswerOptions,
+		IsDangerSign:        details.IsDangerSign,
+		Category:            details.Category,
+		Subcategory:         details.Subcategory,
+		DisplayOrder:        details.DisplayOrder,
+		DependsOnQuestionID: optionalID(details.DependsOnQuestionID),
+		DependsOnAnswer:     details.DependsOnAnswer,
+		TranslationKey:      details.TranslationKey,
+		IsActive:            details.IsActive == nil || *details.IsActive,
+	}
+	if details.RiskCategory != "" {
+		risk := model.ScreenerRisk(details.RiskCategory)
+		input.RiskCategory = &risk
+	}
+	return input
+}
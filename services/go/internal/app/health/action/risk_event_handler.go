@@ -0,0 +1,154 @@
+package action
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/mamacare/services/internal/app/health/risk"
+	"github.com/mamacare/services/internal/domain/model"
+	"github.com/mamacare/services/internal/port/hasura"
+	"github.com/mamacare/services/pkg/errorx"
+	"github.com/mamacare/services/pkg/logger"
+)
+
+// healthMetricEventRow is the part of a health_metrics row needed to reassess risk
+type healthMetricEventRow struct {
+	ID       uuid.UUID `json:"id"`
+	MotherID uuid.UUID `json:"mother_id"`
+}
+
+// motherEventRow is the part of a mothers row whose changes affect risk
+type motherEventRow struct {
+	ID               uuid.UUID       `json:"id"`
+	HealthConditions json.RawMessage `json:"health_conditions"`
+	PregnancyHistory json.RawMessage `json:"pregnancy_history"`
+	BloodType        *string         `json:"blood_type"`
+	DateOfBirth      *string         `json:"date_of_birth"`
+}
+
+// RiskEventHandler recomputes risk assessments from Hasura event triggers
+type RiskEventHandler struct {
+	*hasura.BaseEventHandler
+	riskMonitor *risk.Monitor
+	log         logger.Logger
+}
+
+// NewRiskEventHandler creates a new risk event handler
+func NewRiskEventHandler(log logger.Logger, riskMonitor *risk.Monitor) *RiskEventHandler {
+	return &RiskEventHandler{
+		BaseEventHandler: hasura.NewBaseEventHandler(log),
+		riskMonitor:      riskMonitor,
+		log:              log,
+	}
+}
+
+// HandleHealthMetricInserted reassesses a mother when a new health metric is recorded
+func (h *RiskEventHandler) HandleHealthMetricInserted(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	payload, err := h.ParseEventPayload(r)
+	if err != nil {
+		h.SendEventError(w, r, err)
+		return
+	}
+
+	var row healthMetricEventRow
+	if err := h.ParseNewData(payload, &row); err != nil {
+		h.SendEventError(w, r, err)
+		return
+	}
+	if row.MotherID == uuid.Nil {
+		h.SendEventError(w, r, errorx.New(errorx.BadRequest, "health metric has no mother_id"))
+		return
+	}
+
+	metricID := row.ID
+	if _, err := h.riskMonitor.Reassess(ctx, row.MotherID, model.RiskTriggerHealthMetric, &metricID); err != nil {
+		h.SendEventError(w, r, err)
+		return
+	}
+
+	h.SendEventSuccess(w, r)
+}
+
+// HandleMotherUpdated reassesses a mother when her conditions or history change
+func (h *RiskEventHandler) HandleMotherUpdated(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	payload, err := h.ParseEventPayload(r)
+	if err != nil {
+		h.SendEventError(w, r, err)
+		return
+	}
+
+	var newRow, oldRow motherEventRow
+	if err := h.ParseNewData(payload, &newRow); err != nil {
+		h.SendEventError(w, r, err)
+		return
+	}
+	if err := h.ParseOldData(payload, &oldRow); err != nil {
+		h.SendEventError(w, r, err)
+		return
+	}
+
+	// Updates to other columns, including the risk level we write ourselves, are ignored
+	if !riskInputsChanged(oldRow, newRow) {
+		h.SendEventSuccess(w, r)
+		return
+	}
+
+	if _, err := h.riskMonitor.OnConditionsChanged(ctx, newRow.ID); err != nil {
+		h.SendEventError(w, r, err)
+		return
+	}
+
+	h.SendEventSuccess(w, r)
+}
+
+// ReassessGestationThresholds is called by a daily cron trigger to reassess mothers
+// whose gestational age has crossed a rule threshold
+func (h *RiskEventHandler) ReassessGestationThresholds(w http.ResponseWriter, r *http.Request) {
+	reassessed, err := h.riskMonitor.ReassessGestationThresholds(r.Context())
+	if err != nil {
+		h.SendEventError(w, r, err)
+		return
+	}
+
+	h.log.Info("Gestation threshold reassessment finished", logger.Fields{
+		"reassessed": reassessed,
+	})
+
+	h.SendEventSuccess(w, r)
+}
+
+// riskInputsChanged checks if any mother column used by the risk rules changed
+func riskInputsChanged(oldRow, newRow motherEventRow) bool {
+	if !jsonEqual(oldRow.HealthConditions, newRow.HealthConditions) ||
+		!jsonEqual(oldRow.PregnancyHistory, newRow.PregnancyHistory) {
+		return true
+	}
+	return !stringPtrEqual(oldRow.BloodType, newRow.BloodType) ||
+		!stringPtrEqual(oldRow.DateOfBirth, newRow.DateOfBirth)
+}
+
+// jsonEqual compares two JSON values ignoring formatting differences
+func jsonEqual(a, b json.RawMessage) bool {
+	var bufA, bufB bytes.Buffer
+	if err := json.Compact(&bufA, a); err != nil {
+		return bytes.Equal(a, b)
+	}
+	if err := json.Compact(&bufB, b); err != nil {
+		return bytes.Equal(a, b)
+	}
+	return bytes.Equal(bufA.Bytes(), bufB.Bytes())
+}
+
+// stringPtrEqual compares two optional strings
+func stringPtrEqual(a, b *string) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
@@ -20,17 +20,28 @@ type RiskAssessmentRequest struct {
 	MotherID uuid.UUID `json:"mother_id" validate:"required"`
 }
 
+// RiskHistoryRequest is the request for a mother's stored risk assessments
+type RiskHistoryRequest struct {
+	MotherID uuid.UUID `json:"mother_id" validate:"required"`
+	Limit    int       `json:"limit,omitempty"`
+}
+
 // RiskAssessmentResult is the response for risk assessment
 type RiskAssessmentResult struct {
-	RiskLevel   string           `json:"risk_level"`
-	RiskScore   int              `json:"risk_score"`
-	RiskFactors json.RawMessage  `json:"risk_factors"`
+	RiskLevel      string           `json:"risk_level"`
+	RiskScore      int              `json:"risk_score"`
+	RiskFactors    json.RawMessage  `json:"risk_factors"`
+	RuleSetVersion string           `json:"rule_set_version"`
+	FiredRules     []risk.FiredRule `json:"fired_rules"`
+	AssessmentID   string           `json:"assessment_id"`
+	PreviousLevel  string           `json:"previous_risk_level,omitempty"`
 }
 
 // RiskHandler handles risk assessment actions
 type RiskHandler struct {
 	hasura.BaseActionHandler
 	riskService    *risk.Service
+	riskMonitor    *risk.Monitor
 	motherRepo     repository.MotherRepository
 	healthMetricRepo repository.HealthMetricRepository
 	log           logger.Logger
@@ -40,12 +51,14 @@ type RiskHandler struct {
 func NewRiskHandler(
 	log logger.Logger,
 	riskService *risk.Service,
+	riskMonitor *risk.Monitor,
 	motherRepo repository.MotherRepository,
 	healthMetricRepo repository.HealthMetricRepository,
 ) *RiskHandler {
 	return &RiskHandler{
 		BaseActionHandler: hasura.BaseActionHandler{},
 		riskService:      riskService,
+		riskMonitor:      riskMonitor,
 		motherRepo:       motherRepo,
 		healthMetricRepo: healthMetricRepo,
 		log:             log,
@@ -90,44 +103,65 @@ func (h *RiskHandler) CalculateRisk(w http.ResponseWriter, r *http.Request) {
 	}
 
 	result := RiskAssessmentResult{
-		RiskLevel:   string(riskAssessment.RiskLevel),
-		RiskScore:   riskAssessment.RiskScore,
-		RiskFactors: riskFactorsJSON,
+		RiskLevel:      string(riskAssessment.RiskLevel),
+		RiskScore:      riskAssessment.RiskScore,
+		RiskFactors:    riskFactorsJSON,
+		RuleSetVersion: riskAssessment.RuleSetVersion,
+		FiredRules:     riskAssessment.FiredRules,
+		AssessmentID:   riskAssessment.ID.String(),
+	}
+	if riskAssessment.PreviousRiskLevel != nil {
+		result.PreviousLevel = string(*riskAssessment.PreviousRiskLevel)
 	}
 
 	h.log.Info("Risk assessment completed", logger.FieldsMap{
-		"request_id": reqID,
-		"mother_id":  req.MotherID.String(),
-		"risk_level": result.RiskLevel,
+		"request_id":       reqID,
+		"mother_id":        req.MotherID.String(),
+		"risk_level":       result.RiskLevel,
+		"rule_set_version": result.RuleSetVersion,
 	})
 
 	response.WriteJSONResponse(w, reqID, result)
 }
 
-// calculateRiskForMother retrieves mother data and calculates risk
-func (h *RiskHandler) calculateRiskForMother(ctx context.Context, motherID uuid.UUID) (*risk.RiskAssessment, error) {
-	// Retrieve mother information
-	mother, err := h.motherRepo.GetByID(ctx, motherID)
+// calculateRiskForMother calculates and stores a risk assessment for a mother
+func (h *RiskHandler) calculateRiskForMother(ctx context.Context, motherID uuid.UUID) (*model.RiskAssessment, error) {
+	assessment, err := h.riskMonitor.Reassess(ctx, motherID, model.RiskTriggerManual, nil)
 	if err != nil {
-		return nil, errorx.New(errorx.NotFound, "Mother not found")
+		if errorx.IsType(err, errorx.NotFound) {
+			return nil, errorx.New(errorx.NotFound, "Mother not found")
+		}
+		return nil, errorx.New(errorx.Internal, "Failed to calculate risk assessment")
 	}
 
-	// Retrieve recent health metrics
-	metrics, err := h.healthMetricRepo.GetRecentByMotherID(ctx, motherID, 10)
-	if err != nil {
-		h.log.Warn("Failed to retrieve health metrics", logger.FieldsMap{
-			"mother_id": motherID.String(),
-			"error":     err.Error(),
+	return assessment, nil
+}
+
+// GetRiskHistory returns a mother's stored risk assessments, most recent first
+func (h *RiskHandler) GetRiskHistory(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	reqID := response.GetRequestID(ctx)
+
+	var req RiskHistoryRequest
+	if err := h.ParseRequest(r, &req); err != nil {
+		h.log.Error("Failed to parse request", logger.FieldsMap{
+			"request_id": reqID,
+			"error":      err.Error(),
 		})
-		// Continue with empty metrics rather than failing
-		metrics = []*model.HealthMetric{}
+		response.WriteErrorResponse(w, reqID, err)
+		return
 	}
 
-	// Calculate risk assessment
-	assessment, err := h.riskService.CalculateRisk(mother, metrics)
+	assessments, err := h.riskMonitor.GetAssessmentHistory(ctx, req.MotherID, req.Limit)
 	if err != nil {
-		return nil, errorx.New(errorx.Internal, "Failed to calculate risk assessment")
+		h.log.Error("Failed to get risk history", logger.FieldsMap{
+			"request_id": reqID,
+			"mother_id":  req.MotherID.String(),
+			"error":      err.Error(),
+		})
+		response.WriteErrorResponse(w, reqID, err)
+		return
 	}
 
-	return assessment, nil
+	response.WriteJSONResponse(w, reqID, assessments)
 }
@@ -0,0 +1,291 @@
+package action
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mamacare/services/internal/app/health/screener"
+	"github.com/mamacare/services/internal/domain/model"
+	"github.com/mamacare/services/internal/port/hasura"
+	"github.com/mamacare/services/internal/port/response"
+	"github.com/mamacare/services/internal/port/validation"
+	"github.com/mamacare/services/pkg/errorx"
+	"github.com/mamacare/services/pkg/logger"
+)
+
+// ScreenerQuestionnaireRequest is the request for the screening questions for a mother or a child
+type ScreenerQuestionnaireRequest struct {
+	Subject string `json:"subject" validate:"required,oneof=mother child"`
+}
+
+// StartScreeningRequest is the request to start a screening of a mother or a child
+type StartScreeningRequest struct {
+	ResultID       string     `json:"result_id,omitempty" validate:"omitempty,uuid"`
+	MotherID       string     `json:"mother_id,omitempty" validate:"omitempty,uuid"`
+	ChildID        string     `json:"child_id,omitempty" validate:"omitempty,uuid"`
+	ScreenedAt     *time.Time `json:"screened_at,omitempty"`
+	ScreenedByName string     `json:"screened_by_name,omitempty"`
+	FacilityID     string     `json:"facility_id,omitempty" validate:"omitempty,uuid"`
+	Latitude       *float64   `json:"latitude,omitempty" validate:"omitempty,min=-90,max=90"`
+	Longitude      *float64   `json:"longitude,omitempty" validate:"omitempty,min=-180,max=180"`
+}
+
+// ScreenerAnswerRequest is an answer to a screening question
+type ScreenerAnswerRequest struct {
+	QuestionID string `json:"question_id" validate:"required,uuid"`
+	Value      string `json:"value" validate:"required"`
+}
+
+// AnswerScreeningRequest is the request to answer the next question of a screening
+type AnswerScreeningRequest struct {
+	ResultID string `json:"result_id" validate:"required,uuid"`
+	ScreenerAnswerRequest
+}
+
+// SubmitScreeningRequest is the request to upload a screening done offline
+type SubmitScreeningRequest struct {
+	StartScreeningRequest
+	QuestionnaireID string                  `json:"questionnaire_id,omitempty" validate:"omitempty,uuid"`
+	Answers         []ScreenerAnswerRequest `json:"answers" validate:"dive"`
+}
+
+// GetScreeningRequest is the request for a screening's result and answers
+type GetScreeningRequest struct {
+	ResultID string `json:"result_id" validate:"required,uuid"`
+}
+
+// ScreenerHandler handles health screening actions and USSD screening sessions
+type ScreenerHandler struct {
+	hasura.BaseActionHandler
+	screenerService *screener.Service
+	validator       *validation.Validator
+	log             logger.Logger
+}
+
+// NewScreenerHandler creates a new screener handler
+func NewScreenerHandler(
+	log logger.Logger,
+	screenerService *screener.Service,
+	validator *validation.Validator,
+) *ScreenerHandler {
+	return &ScreenerHandler{
+		BaseActionHandler: hasura.BaseActionHandler{},
+		screenerService:   screenerService,
+		validator:         validator,
+		log:               log,
+	}
+}
+
+// GetScreenerQuestionnaire returns the questions and their branching for offline screening
+func (h *ScreenerHandler) GetScreenerQuestionnaire(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	reqID := response.GetRequestID(ctx)
+
+	var req ScreenerQuestionnaireRequest
+	if _, ok := h.parseAndValidate(w, r, reqID, &req); !ok {
+		return
+	}
+
+	questionnaire, err := h.screenerService.GetQuestionnaire(ctx, model.ScreenerSubject(req.Subject))
+	if err != nil {
+		response.WriteErrorResponse(w, reqID, err)
+		return
+	}
+
+	response.WriteJSONResponse(w, reqID, questionnaire)
+}
+
+// StartScreening starts a screening and returns its first question
+func (h *ScreenerHandler) StartScreening(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	reqID := response.GetRequestID(ctx)
+
+	var req StartScreeningRequest
+	requestedByID, ok := h.parseAndValidate(w, r, reqID, &req)
+	if !ok {
+		return
+	}
+
+	progress, err := h.screenerService.StartScreening(ctx, requestedByID, startInput(&req))
+	if err != nil {
+		response.WriteErrorResponse(w, reqID, err)
+		return
+	}
+
+	response.WriteJSONResponse(w, reqID, progress)
+}
+
+// AnswerScreeningQuestion records an answer and returns the screening's next question
+func (h *ScreenerHandler) AnswerScreeningQuestion(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	reqID := response.GetRequestID(ctx)
+
+	var req AnswerScreeningRequest
+	requestedByID, ok := h.parseAndValidate(w, r, reqID, &req)
+	if !ok {
+		return
+	}
+
+	resultID, err := uuid.Parse(req.ResultID)
+	if err != nil {
+		response.WriteErrorResponse(w, reqID, errorx.New(errorx.BadRequest, "Invalid result ID"))
+		return
+	}
+
+	questionID, err := uuid.Parse(req.QuestionID)
+	if err != nil {
+		response.WriteErrorResponse(w, reqID, errorx.New(errorx.BadRequest, "Invalid question ID"))
+		return
+	}
+
+	progress, err := h.screenerService.AnswerQuestion(ctx, requestedByID, resultID, screener.AnswerInput{
+		QuestionID: questionID,
+		Value:      req.Value,
+	})
+	if err != nil {
+		response.WriteErrorResponse(w, reqID, err)
+		return
+	}
+
+	response.WriteJSONResponse(w, reqID, progress)
+}
+
+// SubmitScreening records a screening done offline by a CHW app
+func (h *ScreenerHandler) SubmitScreening(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	reqID := response.GetRequestID(ctx)
+
+	var req SubmitScreeningRequest
+	requestedByID, ok := h.parseAndValidate(w, r, reqID, &req)
+	if !ok {
+		return
+	}
+
+	answers := make([]screener.AnswerInput, 0, len(req.Answers))
+	for _, answer := range req.Answers {
+		questionID, err := uuid.Parse(answer.QuestionID)
+		if err != nil {
+			response.WriteErrorResponse(w, reqID, errorx.New(errorx.BadRequest, "Invalid question ID"))
+			return
+		}
+		answers = append(answers, screener.AnswerInput{QuestionID: questionID, Value: answer.Value})
+	}
+
+	result, err := h.screenerService.SubmitScreening(ctx, requestedByID, &screener.SubmissionInput{
+		StartInput:      *startInput(&req.StartScreeningRequest),
+		QuestionnaireID: optionalID(req.QuestionnaireID),
+		Answers:         answers,
+	})
+	if err != nil {
+		response.WriteErrorResponse(w, reqID, err)
+		return
+	}
+
+	response.WriteJSONResponse(w, reqID, result)
+}
+
+// GetScreening returns a screening's result and answers
+func (h *ScreenerHandler) GetScreening(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	reqID := response.GetRequestID(ctx)
+
+	var req GetScreeningRequest
+	requestedByID, ok := h.parseAndValidate(w, r, reqID, &req)
+	if !ok {
+		return
+	}
+
+	resultID, err := uuid.Parse(req.ResultID)
+	if err != nil {
+		response.WriteErrorResponse(w, reqID, errorx.New(errorx.BadRequest, "Invalid result ID"))
+		return
+	}
+
+	result, err := h.screenerService.GetResult(ctx, requestedByID, resultID)
+	if err != nil {
+		response.WriteErrorResponse(w, reqID, err)
+		return
+	}
+
+	response.WriteJSONResponse(w, reqID, result)
+}
+
+// HandleUSSD answers a USSD gateway callback. The gateway posts the session ID, phone
+// number and inputs so far as a form and shows the plain text reply on the phone.
+func (h *ScreenerHandler) HandleUSSD(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	if err := r.ParseForm(); err != nil {
+		h.log.Error("Failed to parse USSD request", logger.Fields{
+			"error": err.Error(),
+		})
+		http.Error(w, "invalid request", http.StatusBadRequest)
+		return
+	}
+
+	reply, err := h.screenerService.HandleUSSD(ctx, &screener.USSDRequest{
+		SessionID:   r.PostFormValue("sessionId"),
+		PhoneNumber: r.PostFormValue("phoneNumber"),
+		Text:        r.PostFormValue("text"),
+	})
+	if err != nil {
+		h.log.Error("Failed to handle USSD request", logger.Fields{
+			"error":      err.Error(),
+			"session_id": r.PostFormValue("sessionId"),
+		})
+		reply = "END Sorry, something went wrong. Please try again later."
+	}
+
+	w.Header().Set("Content-Type", "text/plain")
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write([]byte(reply))
+}
+
+// parseAndValidate parses and validates a request and returns the ID of the user making it,
+// taken from the Hasura session. It writes the error response on failure.
+func (h *ScreenerHandler) parseAndValidate(w http.ResponseWriter, r *http.Request, reqID string, req interface{}) (uuid.UUID, bool) {
+	actionReq, err := h.ParseRequest(r, req)
+	if err != nil {
+		h.log.Error("Failed to parse request", logger.Fields{
+			"request_id": reqID,
+			"error":      err.Error(),
+		})
+		response.WriteErrorResponse(w, reqID, err)
+		return uuid.Nil, false
+	}
+
+	requestedByID, err := actionReq.UserID()
+	if err != nil {
+		response.WriteErrorResponse(w, reqID, err)
+		return uuid.Nil, false
+	}
+
+	if err := h.validator.Validate(req); err != nil {
+		h.log.Error("Invalid request", logger.Fields{
+			"request_id": reqID,
+			"error":      err.Error(),
+		})
+		response.WriteErrorResponse(w, reqID, err)
+		return uuid.Nil, false
+	}
+
+	return requestedByID, true
+}
+
+// startInput converts a start screening request the validator has already checked
+func startInput(req *StartScreeningRequest) *screener.StartInput {
+	input := &screener.StartInput{
+		ResultID:       optionalID(req.ResultID),
+		MotherID:       optionalID(req.MotherID),
+		ChildID:        optionalID(req.ChildID),
+		ScreenedByName: req.ScreenedByName,
+		FacilityID:     optionalID(req.FacilityID),
+		Latitude:       req.Latitude,
+		Longitude:      req.Longitude,
+	}
+	if req.ScreenedAt != nil {
+		input.ScreenedAt = *req.ScreenedAt
+	}
+	return input
+}
@@ -0,0 +1,56 @@
+package action
+
+import (
+	"net/http"
+
+	"github.com/mamacare/services/internal/app/health/trend"
+	"github.com/mamacare/services/internal/port/hasura"
+	"github.com/mamacare/services/pkg/logger"
+)
+
+// trendBatchPayload is the optional cron trigger payload for the trend batch
+type trendBatchPayload struct {
+	LookbackDays int `json:"lookback_days"`
+}
+
+// TrendEventHandler runs trend analysis from Hasura cron triggers
+type TrendEventHandler struct {
+	*hasura.BaseEventHandler
+	batchJob *trend.BatchJob
+	log      logger.Logger
+}
+
+// NewTrendEventHandler creates a new trend event handler
+func NewTrendEventHandler(log logger.Logger, batchJob *trend.BatchJob) *TrendEventHandler {
+	return &TrendEventHandler{
+		BaseEventHandler: hasura.NewBaseEventHandler(log),
+		batchJob:         batchJob,
+		log:              log,
+	}
+}
+
+// RunTrendBatch is called by a daily cron trigger to analyse the trends of all active
+// mothers and notify CHWs about deteriorations
+func (h *TrendEventHandler) RunTrendBatch(w http.ResponseWriter, r *http.Request) {
+	lookbackDays := trend.DefaultLookbackDays
+
+	if payload, err := h.ParseEventPayload(r); err == nil {
+		var body trendBatchPayload
+		if err := h.ParseNewData(payload, &body); err == nil && body.LookbackDays > 0 {
+			lookbackDays = body.LookbackDays
+		}
+	}
+
+	report, err := h.batchJob.Run(r.Context(), lookbackDays)
+	if err != nil {
+		h.SendEventError(w, r, err)
+		return
+	}
+
+	h.log.Info("Trend batch run finished", logger.Fields{
+		"analyzed":       report.MothersAnalyzed,
+		"deteriorations": len(report.Deteriorations),
+	})
+
+	h.SendEventSuccess(w, r)
+}
@@ -23,17 +23,26 @@ type TrendAnalysisRequest struct {
 	TimeRangeDays int      `json:"time_range_days" validate:"required,min=1,max=365"`
 }
 
+
 // TrendResult represents a single detected trend
 type TrendResult struct {
-	MetricName        string  `json:"metric_name"`
-	TrendType         string  `json:"trend_type"`
-	AlertLevel        string  `json:"alert_level"`
-	Description       string  `json:"description"`
-	RecommendedAction string  `json:"recommended_action,omitempty"`
-	FirstValue        float64 `json:"first_value"`
-	LastValue         float64 `json:"last_value"`
-	ChangeRate        float64 `json:"change_rate"`
-	ChangePerDay      float64 `json:"change_per_day"`
+	MetricName        string              `json:"metric_name"`
+	TrendType         string              `json:"trend_type"`
+	AlertLevel        string              `json:"alert_level"`
+	Description       string              `json:"description"`
+	RecommendedAction string              `json:"recommended_action,omitempty"`
+	FirstValue        float64             `json:"first_value"`
+	LastValue         float64             `json:"last_value"`
+	ChangeRate        float64             `json:"change_rate"`
+	ChangePerDay      float64             `json:"change_per_day"`
+	DataPoints        int                 `json:"data_points"`
+	Confidence        string              `json:"confidence"`
+	ConfidenceScore   float64             `json:"confidence_score"`
+	Baseline          *trend.Baseline     `json:"baseline,omitempty"`
+	BaselineDeviation float64             `json:"baseline_deviation"`
+	ChangePoints      []trend.ChangePoint `json:"change_points,omitempty"`
+	Outliers          []trend.Outlier     `json:"outliers,omitempty"`
+	LatestJump        *trend.LatestJump   `json:"latest_jump,omitempty"`
 }
 
 // TrendAnalysisResult is the response for trend analysis
@@ -52,6 +61,7 @@ type TrendHandler struct {
 	*hasura.BaseActionHandler
 	trendService     *trend.Service
 	healthMetricRepo repository.HealthMetricRepository
+	motherRepo       repository.MotherRepository
 	log              logger.Logger
 }
 
@@ -60,11 +70,13 @@ func NewTrendHandler(
 	log logger.Logger,
 	trendService *trend.Service,
 	healthMetricRepo repository.HealthMetricRepository,
+	motherRepo repository.MotherRepository,
 ) *TrendHandler {
 	return &TrendHandler{
 		BaseActionHandler: hasura.NewBaseActionHandler(log),
 		trendService:      trendService,
 		healthMetricRepo:  healthMetricRepo,
+		motherRepo:        motherRepo,
 		log:              log,
 	}
 }
@@ -101,14 +113,14 @@ func (h *TrendHandler) AnalyzeTrends(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if len(metrics) < 3 {
+	if len(metrics) < trend.MinDataPoints {
 		h.log.Warn("Insufficient data for trend analysis", logger.Fields{
 			"request_id": reqID,
 			"mother_id":  req.MotherID.String(),
 			"metrics_count": len(metrics),
 		})
 		response.WriteError(w, reqID, 
-			errorx.NewError(errorx.BadRequest, "Insufficient data for trend analysis. At least 3 measurements are required"))
+			errorx.Newf(errorx.BadRequest, "Insufficient data for trend analysis. At least %d measurements are required", trend.MinDataPoints))
 		return
 	}
 
@@ -137,6 +149,14 @@ func (h *TrendHandler) AnalyzeTrends(w http.ResponseWriter, r *http.Request) {
 			LastValue:         t.LastValue,
 			ChangeRate:        t.ChangeRate,
 			ChangePerDay:      t.ChangePerDay,
+			DataPoints:        t.DataPoints,
+			Confidence:        string(t.Confidence),
+			ConfidenceScore:   t.ConfidenceScore,
+			Baseline:          t.Baseline,
+			BaselineDeviation: t.BaselineDeviation,
+			ChangePoints:      t.ChangePoints,
+			Outliers:          t.Outliers,
+			LatestJump:        t.LatestJump,
 		})
 	}
 
@@ -173,8 +193,7 @@ func (h *TrendHandler) retrieveHealthMetrics(
 
 // GetMetricsAnalysis processes and analyzes a single health metric
 type MetricsAnalysisRequest struct {
-	MetricID        uuid.UUID `json:"metric_id" validate:"required"`
-	GestationalAge  int       `json:"gestational_age" validate:"required,min=0,max=45"`
+	MetricID uuid.UUID `json:"metric_id" validate:"required"`
 }
 
 // MetricsAnalysisResult is the response for metrics analysis
@@ -187,7 +206,8 @@ type MetricsAnalysisResult struct {
 	SeverityLevel      string                 `json:"severity_level"`
 }
 
-// AnalyzeMetric analyzes a single health metric
+// AnalyzeMetric analyzes a single health metric against the reference ranges for the
+// mother's gestational age, or her postpartum period, when it was recorded
 func (h *TrendHandler) AnalyzeMetric(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 	reqID := response.GetRequestID(ctx)
@@ -214,9 +234,21 @@ func (h *TrendHandler) AnalyzeMetric(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// The mother gives the gestation, postpartum period and BMI the metric is judged against
+	mother, err := h.motherRepo.GetByID(ctx, metric.MotherID)
+	if err != nil {
+		h.log.Error("Failed to retrieve mother", logger.FieldsMap{
+			"request_id": reqID,
+			"mother_id":  metric.MotherID.String(),
+			"error":      err.Error(),
+		})
+		response.WriteErrorResponse(w, reqID, errorx.Wrap(err, "failed to find mother"))
+		return
+	}
+
 	// Analyze the metric
 	analysisService := metrics.NewService(h.log)
-	analysis, err := analysisService.AnalyzeMetric(metric, req.GestationalAge)
+	analysis, err := analysisService.AnalyzeMetricInContext(metric, metrics.ContextForMother(mother, metric.RecordedAt))
 	if err != nil {
 		h.log.Error("Failed to analyze metric", logger.FieldsMap{
 			"request_id": reqID,
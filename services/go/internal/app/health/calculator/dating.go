@@ -0,0 +1,169 @@
+package calculator
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/mamacare/services/internal/domain/model"
+	"github.com/mamacare/services/pkg/errorx"
+)
+
+// pregnancyLengthDays is the length of a pregnancy from LMP to EDD
+const pregnancyLengthDays = 280
+
+// DatingEstimate is a gestational age estimate at an exam date and the EDD it implies
+type DatingEstimate struct {
+	Method             model.DatingMethod `json:"method"`
+	ExamDate           time.Time          `json:"exam_date"`
+	GestationalAgeDays int                `json:"gestational_age_days"`
+	ExpectedDelivery   time.Time          `json:"expected_delivery_date"`
+}
+
+// DatingDecision says whether a new estimate should replace the current EDD
+type DatingDecision struct {
+	Apply           bool   `json:"apply"`
+	DiscrepancyDays int    `json:"discrepancy_days"` // new estimate minus current gestational age at the exam
+	ThresholdDays   int    `json:"threshold_days,omitempty"`
+	Reason          string `json:"reason"`
+}
+
+// newEstimate builds an estimate from the gestational age at an exam date
+func newEstimate(method model.DatingMethod, examDate time.Time, gestationalAgeDays int) *DatingEstimate {
+	return &DatingEstimate{
+		Method:             method,
+		ExamDate:           examDate,
+		GestationalAgeDays: gestationalAgeDays,
+		ExpectedDelivery:   examDate.AddDate(0, 0, pregnancyLengthDays-gestationalAgeDays),
+	}
+}
+
+// DateFromLMP estimates gestational age from the last menstrual period
+func (s *Service) DateFromLMP(lmp, examDate time.Time) (*DatingEstimate, error) {
+	days, err := s.CalculateGestationalAge(lmp, examDate)
+	if err != nil {
+		return nil, err
+	}
+	if days > 44*7 {
+		return nil, errorx.New(errorx.BadRequest, "last menstrual period is more than 44 weeks before the exam date")
+	}
+	return newEstimate(model.DatingMethodLMP, examDate, days), nil
+}
+
+// DateFromCRL estimates gestational age from an ultrasound crown-rump length using
+// Robinson's formula, valid for CRL 10-84 mm (about 7 to 14 weeks)
+func (s *Service) DateFromCRL(crlMm float64, examDate time.Time) (*DatingEstimate, error) {
+	if crlMm < 10 || crlMm > 84 {
+		return nil, errorx.New(errorx.BadRequest, "crown-rump length must be between 10 and 84 mm; use biparietal diameter after 14 weeks")
+	}
+	days := 8.052*math.Sqrt(crlMm) + 23.73
+	return newEstimate(model.DatingMethodCRL, examDate, int(math.Round(days))), nil
+}
+
+// DateFromBPD estimates gestational age from an ultrasound biparietal diameter using
+// Hadlock's formula, for BPD 25-95 mm (about 14 to 40 weeks)
+func (s *Service) DateFromBPD(bpdMm float64, examDate time.Time) (*DatingEstimate, error) {
+	if bpdMm < 25 || bpdMm > 95 {
+		return nil, errorx.New(errorx.BadRequest, "biparietal diameter must be between 25 and 95 mm")
+	}
+	bpdCm := bpdMm / 10
+	weeks := 9.54 + 1.482*bpdCm + 0.1676*bpdCm*bpdCm
+	return newEstimate(model.DatingMethodBPD, examDate, int(math.Round(weeks*7))), nil
+}
+
+// DateFromFundalHeight estimates gestational age from symphysis-fundal height using
+// McDonald's rule (height in cm is about the weeks of gestation from 20 to 36 weeks)
+func (s *Service) DateFromFundalHeight(fundalHeightCm float64, examDate time.Time) (*DatingEstimate, error) {
+	if fundalHeightCm < 20 || fundalHeightCm > 36 {
+		return nil, errorx.New(errorx.BadRequest, "fundal height can only date a pregnancy between 20 and 36 cm")
+	}
+	return newEstimate(model.DatingMethodFundalHeight, examDate, int(math.Round(fundalHeightCm*7))), nil
+}
+
+// RedatingThresholdDays returns how far an ultrasound estimate must differ from LMP dating
+// before it replaces it, by gestational age at the scan (ACOG Committee Opinion 700)
+func RedatingThresholdDays(gestationalAgeDays int) int {
+	switch {
+	case gestationalAgeDays < 9*7:
+		return 5
+	case gestationalAgeDays < 16*7:
+		return 7
+	case gestationalAgeDays < 22*7:
+		return 10
+	case gestationalAgeDays < 28*7:
+		return 14
+	default:
+		return 21
+	}
+}
+
+// ReconcileDating decides whether a new estimate replaces the current dating. The
+// current dating is nil when the pregnancy has not been reliably dated yet.
+//
+// Ultrasound overrides LMP when the difference is more than the threshold for the
+// gestational age; the earliest ultrasound is kept over later scans, except that a
+// CRL replaces a BPD; fundal height only dates pregnancies with no other dating.
+func (s *Service) ReconcileDating(current, candidate *DatingEstimate) DatingDecision {
+	if current == nil {
+		return DatingDecision{
+			Apply:  true,
+			Reason: fmt.Sprintf("first dating of the pregnancy, by %s", candidate.Method),
+		}
+	}
+
+	// Compare both estimates at the new exam date
+	currentDays := pregnancyLengthDays - int(math.Round(current.ExpectedDelivery.Sub(candidate.ExamDate).Hours()/24))
+	decision := DatingDecision{DiscrepancyDays: candidate.GestationalAgeDays - currentDays}
+
+	switch candidate.Method {
+	case model.DatingMethodLMP:
+		if current.Method.IsUltrasound() {
+			decision.Reason = "pregnancy is dated by ultrasound, which is kept over LMP"
+			return decision
+		}
+		decision.Apply = true
+		decision.Reason = "LMP replaces less reliable or earlier LMP dating"
+		return decision
+
+	case model.DatingMethodCRL, model.DatingMethodBPD:
+		if current.Method.IsUltrasound() {
+			if candidate.Method == model.DatingMethodCRL && current.Method == model.DatingMethodBPD {
+				decision.Apply = true
+				decision.Reason = "first-trimester crown-rump length is more accurate than biparietal diameter"
+				return decision
+			}
+			decision.Reason = "pregnancy is already dated by an earlier ultrasound; later scans assess growth, not dates"
+			return decision
+		}
+		if current.Method == model.DatingMethodFundalHeight {
+			decision.Apply = true
+			decision.Reason = "ultrasound replaces fundal height dating"
+			return decision
+		}
+
+		decision.ThresholdDays = RedatingThresholdDays(currentDays)
+		if abs(decision.DiscrepancyDays) > decision.ThresholdDays {
+			decision.Apply = true
+			decision.Reason = fmt.Sprintf("ultrasound differs from LMP by %d days, more than %d days at this gestation",
+				abs(decision.DiscrepancyDays), decision.ThresholdDays)
+			return decision
+		}
+		decision.Reason = fmt.Sprintf("ultrasound agrees with LMP within %d days; LMP dating is kept", decision.ThresholdDays)
+		return decision
+
+	default:
+		decision.Reason = "fundal height does not change an existing dating"
+		if abs(decision.DiscrepancyDays) > 3*7 {
+			decision.Reason += "; a difference of more than 3 weeks should be checked for growth problems"
+		}
+		return decision
+	}
+}
+
+// abs returns the absolute value of an int
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
@@ -0,0 +1,99 @@
+package calculator
+
+import (
+	"math"
+
+	"github.com/mamacare/services/pkg/errorx"
+)
+
+// Fetal growth standards the reference curves come from
+const (
+	// StandardIntergrowthSFH is the INTERGROWTH-21st symphysis-fundal height standard (Papageorghiou 2016)
+	StandardIntergrowthSFH = "intergrowth21_sfh"
+	// StandardHadlockEFW is Hadlock's in-utero estimated fetal weight standard (Hadlock 1991)
+	StandardHadlockEFW = "hadlock_efw"
+)
+
+// Gestational ages, in days, the reference curves are valid for
+const (
+	FundalHeightMinDays = 16 * 7
+	FundalHeightMaxDays = 40 * 7
+	FetalWeightMinDays  = 10 * 7
+	FetalWeightMaxDays  = 42 * 7
+)
+
+// z-scores of the 10th and 90th centiles, the usual limits for small and large for gestational age
+const (
+	ZScoreP10 = -1.2816
+	ZScoreP90 = 1.2816
+)
+
+// hadlockEFWCoefficientOfVariation is the spread of Hadlock's EFW standard around the mean
+const hadlockEFWCoefficientOfVariation = 0.127
+
+// GrowthReference is the expected distribution of a fetal measurement at a gestational age
+type GrowthReference struct {
+	Standard           string  `json:"standard"`
+	GestationalAgeDays int     `json:"gestational_age_days"`
+	Mean               float64 `json:"mean"`
+	SD                 float64 `json:"sd"`
+	P10                float64 `json:"p10"`
+	P90                float64 `json:"p90"`
+}
+
+// ZScore returns how many standard deviations a value is from the reference mean
+func (r *GrowthReference) ZScore(value float64) float64 {
+	if r.SD <= 0 {
+		return 0
+	}
+	return (value - r.Mean) / r.SD
+}
+
+// Centile returns the centile (0-100) of a value on the reference curve
+func (r *GrowthReference) Centile(value float64) float64 {
+	return ZScoreCentile(r.ZScore(value))
+}
+
+// ZScoreCentile converts a z-score to a centile of the normal distribution
+func ZScoreCentile(z float64) float64 {
+	return 50 * (1 + math.Erf(z/math.Sqrt2))
+}
+
+// newGrowthReference builds a reference with the 10th and 90th centiles from a mean and SD
+func newGrowthReference(standard string, gestationalAgeDays int, mean, sd float64) *GrowthReference {
+	return &GrowthReference{
+		Standard:           standard,
+		GestationalAgeDays: gestationalAgeDays,
+		Mean:               mean,
+		SD:                 sd,
+		P10:                mean + ZScoreP10*sd,
+		P90:                mean + ZScoreP90*sd,
+	}
+}
+
+// FundalHeightReference returns the INTERGROWTH-21st symphysis-fundal height
+// distribution in cm at a gestational age in days, valid from 16 to 40 weeks
+func FundalHeightReference(gestationalAgeDays int) (*GrowthReference, error) {
+	if gestationalAgeDays < FundalHeightMinDays || gestationalAgeDays > FundalHeightMaxDays {
+		return nil, errorx.New(errorx.BadRequest, "gestational age must be between 16 and 40 weeks for fundal height")
+	}
+
+	ga := float64(gestationalAgeDays) / 7
+	mean := 5.133374 + 0.1058353119*ga*ga - 0.0231295*ga*ga*math.Log(ga)
+	sd := 0.9922667 + 0.0258087*ga
+
+	return newGrowthReference(StandardIntergrowthSFH, gestationalAgeDays, mean, sd), nil
+}
+
+// FetalWeightReference returns Hadlock's in-utero estimated fetal weight
+// distribution in grams at a gestational age in days, from 10 to 42 weeks
+func FetalWeightReference(gestationalAgeDays int) (*GrowthReference, error) {
+	if gestationalAgeDays < FetalWeightMinDays || gestationalAgeDays > FetalWeightMaxDays {
+		return nil, errorx.New(errorx.BadRequest, "gestational age must be between 10 and 42 weeks for fetal weight")
+	}
+
+	ga := float64(gestationalAgeDays) / 7
+	mean := math.Exp(0.578 + 0.332*ga - 0.00354*ga*ga)
+
+	return newGrowthReference(StandardHadlockEFW, gestationalAgeDays, mean, mean*hadlockEFWCoefficientOfVariation), nil
+}
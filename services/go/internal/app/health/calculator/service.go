@@ -188,62 +188,32 @@ func (s *Service) CalculateExpectedDeliveryDate(lmp time.Time) (time.Time, error
 }
 
 // EstimateFundusHeight estimates the expected fundus height based on gestational age
-// Returns the height in centimeters and an acceptable range
+// Returns the median height in centimeters and the 10th to 90th centile range of the
+// INTERGROWTH-21st standard
 func (s *Service) EstimateFundusHeight(gestationalWeeks int) (float64, float64, float64, error) {
 	if gestationalWeeks < 16 || gestationalWeeks > 40 {
 		return 0, 0, 0, errorx.New(errorx.BadRequest, "gestational age must be between 16 and 40 weeks")
 	}
-	
-	// Simplified McDonald's rule: After 24 weeks, fundus height in cm often correlates with weeks of gestation
-	// Before 24 weeks, this is less reliable but we'll provide estimates
-	expectedHeight := float64(gestationalWeeks - 4) // Simple approximation
-	if gestationalWeeks < 20 {
-		expectedHeight = float64(gestationalWeeks - 6) // Adjusted for earlier weeks
-	}
-	
-	// Define acceptable range: +/- 2cm is often considered normal variation
-	minRange := expectedHeight - 2.0
-	maxRange := expectedHeight + 2.0
-	
-	if minRange < 0 {
-		minRange = 0
+
+	ref, err := FundalHeightReference(gestationalWeeks * 7)
+	if err != nil {
+		return 0, 0, 0, err
 	}
-	
-	return expectedHeight, minRange, maxRange, nil
+
+	return ref.Mean, ref.P10, ref.P90, nil
 }
 
 // EstimateFetusWeight estimates fetal weight based on gestational age
-// Returns the estimated weight in grams and an acceptable range
+// Returns the median weight in grams and the 10th to 90th centile range of Hadlock's standard
 func (s *Service) EstimateFetusWeight(gestationalWeeks int) (float64, float64, float64, error) {
 	if gestationalWeeks < 10 || gestationalWeeks > 42 {
 		return 0, 0, 0, errorx.New(errorx.BadRequest, "gestational age must be between 10 and 42 weeks")
 	}
-	
-	// Simple estimation based on standard growth charts
-	// This is a simplified approximation; actual calculations would use more complex formulas
-	var estimatedWeight float64
-	
-	switch {
-	case gestationalWeeks <= 12:
-		estimatedWeight = float64((gestationalWeeks - 8) * 10) // ~10g gain per week in first trimester
-	case gestationalWeeks <= 28:
-		estimatedWeight = 100.0 + float64((gestationalWeeks - 12) * 100) // ~100g gain per week in second trimester
-	default:
-		// Third trimester: weight gain accelerates
-		baseWeight := 1700.0 // Approximate weight at 28 weeks
-		weeksPast28 := gestationalWeeks - 28
-		weeklyGain := []float64{200, 200, 200, 225, 225, 225, 250, 250, 250, 225, 225, 200, 200, 175}
-		
-		for i := 0; i < weeksPast28 && i < len(weeklyGain); i++ {
-			baseWeight += weeklyGain[i]
-		}
-		
-		estimatedWeight = baseWeight
-	}
-	
-	// Define normal range (10th to 90th percentile, approx +/- 15%)
-	minRange := estimatedWeight * 0.85
-	maxRange := estimatedWeight * 1.15
-	
-	return estimatedWeight, minRange, maxRange, nil
+
+	ref, err := FetalWeightReference(gestationalWeeks * 7)
+	if err != nil {
+		return 0, 0, 0, err
+	}
+
+	return ref.Mean, ref.P10, ref.P90, nil
 }
@@ -0,0 +1,255 @@
+package dating
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mamacare/services/internal/app/health/calculator"
+	"github.com/mamacare/services/internal/domain/model"
+	"github.com/mamacare/services/internal/domain/repository"
+	"github.com/mamacare/services/pkg/errorx"
+	"github.com/mamacare/services/pkg/logger"
+)
+
+// VisitPlanner defines the interface for moving a mother's visit plan to a corrected EDD
+type VisitPlanner interface {
+	// RealignVisitPlan moves upcoming routine visits after the EDD has changed
+	RealignVisitPlan(ctx context.Context, motherID uuid.UUID, previousEDD time.Time) (int, error)
+}
+
+// Input is a new dating measurement for a pregnancy
+type Input struct {
+	Method         model.DatingMethod
+	ExamDate       time.Time
+	LMP            *time.Time
+	MeasurementMm  *float64 // CRL or BPD
+	FundalHeightCm *float64
+	RecordedByID   *uuid.UUID
+}
+
+// Result is the stored dating estimate and its effect on the mother's EDD
+type Result struct {
+	Dating          *model.PregnancyDating        `json:"dating"`
+	Decision        calculator.DatingDecision     `json:"decision"`
+	Mother          *model.Mother                 `json:"mother"`
+	VisitsRealigned int                           `json:"visits_realigned"`
+	PregnancyDates  *calculator.PregnancyDateInfo `json:"pregnancy_dates"`
+}
+
+// Service keeps pregnancy dating history and the mother's EDD in line with the best estimate
+type Service struct {
+	calcService  *calculator.Service
+	motherRepo   repository.MotherRepository
+	datingRepo   repository.PregnancyDatingRepository
+	visitPlanner VisitPlanner
+	transactor   repository.Transactor
+	log          logger.Logger
+}
+
+// NewService creates a new pregnancy dating service
+func NewService(
+	calcService *calculator.Service,
+	motherRepo repository.MotherRepository,
+	datingRepo repository.PregnancyDatingRepository,
+	visitPlanner VisitPlanner,
+	transactor repository.Transactor,
+	log logger.Logger,
+) *Service {
+	return &Service{
+		calcService:  calcService,
+		motherRepo:   motherRepo,
+		datingRepo:   datingRepo,
+		visitPlanner: visitPlanner,
+		transactor:   transactor,
+		log:          log,
+	}
+}
+
+// RecordDating stores a dating estimate, applies it to the mother's EDD when the
+// dating rules say so, and moves her visit plan to the corrected EDD. The estimate and
+// the mother's EDD are saved in one transaction, so the history always explains the EDD.
+func (s *Service) RecordDating(ctx context.Context, motherID uuid.UUID, input *Input) (*Result, error) {
+	mother, err := s.motherRepo.GetByID(ctx, motherID)
+	if err != nil {
+		s.log.Error("Failed to find mother", logger.Fields{
+			"error":     err.Error(),
+			"mother_id": motherID.String(),
+		})
+		return nil, errorx.Wrap(err, "failed to find mother")
+	}
+
+	if mother.IsPostpartum() {
+		return nil, errorx.New(errorx.BadRequest, "mother has already delivered")
+	}
+	if input.ExamDate.IsZero() {
+		input.ExamDate = time.Now()
+	}
+	if input.ExamDate.After(time.Now()) {
+		return nil, errorx.New(errorx.BadRequest, "exam date cannot be in the future")
+	}
+
+	candidate, err := s.estimate(input)
+	if err != nil {
+		return nil, err
+	}
+
+	decision := s.calcService.ReconcileDating(currentDating(mother), candidate)
+
+	previousEDD := mother.ExpectedDeliveryDate
+	previousMethod := mother.DatingMethod
+	record := &model.PregnancyDating{
+		ID:                 uuid.New(),
+		MotherID:           motherID,
+		Method:             candidate.Method,
+		ExamDate:           input.ExamDate,
+		LMP:                input.LMP,
+		MeasurementMm:      input.MeasurementMm,
+		FundalHeightCm:     input.FundalHeightCm,
+		GestationalAgeDays: candidate.GestationalAgeDays,
+		EstimatedEDD:       candidate.ExpectedDelivery,
+		PreviousEDD:        &previousEDD,
+		PreviousMethod:     &previousMethod,
+		DiscrepancyDays:    decision.DiscrepancyDays,
+		Applied:            decision.Apply,
+		Reason:             decision.Reason,
+		RecordedByID:       input.RecordedByID,
+		CreatedAt:          time.Now(),
+	}
+
+	// A reported LMP is kept on the mother even when ultrasound dating is kept
+	updateMother := input.LMP != nil || decision.Apply
+	if input.LMP != nil {
+		mother.WithLMP(*input.LMP)
+	}
+	if decision.Apply {
+		mother.ApplyDating(candidate.ExpectedDelivery, candidate.Method)
+	}
+
+	err = s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		return s.storeDating(ctx, record, mother, updateMother)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result := &Result{
+		Dating:   record,
+		Decision: decision,
+		Mother:   mother,
+	}
+
+	if decision.Apply && !sameDay(previousEDD, mother.ExpectedDeliveryDate) && s.visitPlanner != nil {
+		moved, err := s.visitPlanner.RealignVisitPlan(ctx, motherID, previousEDD)
+		if err != nil {
+			// The EDD is corrected; visits can be realigned by hand
+			s.log.Warn("Failed to realign visit plan", logger.Fields{
+				"error":     err.Error(),
+				"mother_id": motherID.String(),
+			})
+		}
+		result.VisitsRealigned = moved
+	}
+
+	result.PregnancyDates, err = s.calcService.CalculatePregnancyDates(mother.DatingLMP())
+	if err != nil {
+		s.log.Warn("Failed to calculate pregnancy dates", logger.Fields{
+			"error":     err.Error(),
+			"mother_id": motherID.String(),
+		})
+	}
+
+	s.log.Info("Pregnancy dating recorded", logger.Fields{
+		"mother_id":        motherID.String(),
+		"method":           string(candidate.Method),
+		"applied":          decision.Apply,
+		"discrepancy_days": decision.DiscrepancyDays,
+		"edd":              mother.ExpectedDeliveryDate.Format("2006-01-02"),
+		"previous_edd":     previousEDD.Format("2006-01-02"),
+	})
+
+	return result, nil
+}
+
+// storeDating saves a dating estimate and, if it changed, the mother's dating
+func (s *Service) storeDating(ctx context.Context, record *model.PregnancyDating, mother *model.Mother, updateMother bool) error {
+	if err := s.datingRepo.Create(ctx, record); err != nil {
+		s.log.Error("Failed to store pregnancy dating", logger.Fields{
+			"error":     err.Error(),
+			"mother_id": mother.ID.String(),
+		})
+		return errorx.Wrap(err, "failed to store pregnancy dating")
+	}
+
+	if !updateMother {
+		return nil
+	}
+	if err := s.motherRepo.Save(ctx, mother); err != nil {
+		s.log.Error("Failed to update mother dating", logger.Fields{
+			"error":     err.Error(),
+			"mother_id": mother.ID.String(),
+		})
+		return errorx.Wrap(err, "failed to update mother dating")
+	}
+	return nil
+}
+
+// GetDatingHistory retrieves a mother's dating estimates, oldest first
+func (s *Service) GetDatingHistory(ctx context.Context, motherID uuid.UUID) ([]*model.PregnancyDating, error) {
+	history, err := s.datingRepo.GetByMotherID(ctx, motherID)
+	if err != nil {
+		s.log.Error("Failed to get pregnancy dating history", logger.Fields{
+			"error":     err.Error(),
+			"mother_id": motherID.String(),
+		})
+		return nil, errorx.Wrap(err, "failed to get pregnancy dating history")
+	}
+	return history, nil
+}
+
+// estimate turns a measurement into a dating estimate
+func (s *Service) estimate(input *Input) (*calculator.DatingEstimate, error) {
+	switch input.Method {
+	case model.DatingMethodLMP:
+		if input.LMP == nil {
+			return nil, errorx.New(errorx.BadRequest, "LMP is required for LMP dating")
+		}
+		return s.calcService.DateFromLMP(*input.LMP, input.ExamDate)
+	case model.DatingMethodCRL:
+		if input.MeasurementMm == nil {
+			return nil, errorx.New(errorx.BadRequest, "crown-rump length is required")
+		}
+		return s.calcService.DateFromCRL(*input.MeasurementMm, input.ExamDate)
+	case model.DatingMethodBPD:
+		if input.MeasurementMm == nil {
+			return nil, errorx.New(errorx.BadRequest, "biparietal diameter is required")
+		}
+		return s.calcService.DateFromBPD(*input.MeasurementMm, input.ExamDate)
+	case model.DatingMethodFundalHeight:
+		if input.FundalHeightCm == nil {
+			return nil, errorx.New(errorx.BadRequest, "fundal height is required")
+		}
+		return s.calcService.DateFromFundalHeight(*input.FundalHeightCm, input.ExamDate)
+	default:
+		return nil, errorx.Newf(errorx.BadRequest, "unknown dating method: %s", input.Method)
+	}
+}
+
+// currentDating describes how the mother's EDD was set, or nil if it was only a guess.
+// An LMP-dated EDD without a recorded LMP was entered without dating and can be replaced.
+func currentDating(mother *model.Mother) *calculator.DatingEstimate {
+	if mother.DatingMethod == "" || (mother.DatingMethod == model.DatingMethodLMP && mother.LMP == nil) {
+		return nil
+	}
+	return &calculator.DatingEstimate{
+		Method:           mother.DatingMethod,
+		ExpectedDelivery: mother.ExpectedDeliveryDate,
+	}
+}
+
+// sameDay checks if two times fall on the same calendar day
+func sameDay(a, b time.Time) bool {
+	ay, am, ad := a.Date()
+	by, bm, bd := b.Date()
+	return ay == by && am == bm && ad == bd
+}
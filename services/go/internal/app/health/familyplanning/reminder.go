@@ -0,0 +1,210 @@
+package familyplanning
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/mamacare/services/internal/app/notification/preference"
+	"github.com/mamacare/services/internal/app/notification/push"
+	"github.com/mamacare/services/internal/app/notification/scheduler"
+	"github.com/mamacare/services/internal/domain/model"
+	"github.com/mamacare/services/internal/domain/repository"
+	"github.com/mamacare/services/pkg/errorx"
+	"github.com/mamacare/services/pkg/logger"
+)
+
+const (
+	// reminderLeadDays is how many days before a method is due mothers are reminded
+	reminderLeadDays = 7
+	// overdueAfterDays is how many days after a method was due mothers are reminded again
+	overdueAfterDays = 3
+	// reminderDelay gives the scheduler a delivery time safely in the future
+	reminderDelay = time.Minute
+)
+
+// methodNames are how methods are named in reminders
+var methodNames = map[model.FPMethod]string{
+	model.FPMethodImplant:    "implant",
+	model.FPMethodInjectable: "family planning injection",
+	model.FPMethodIUD:        "IUD",
+	model.FPMethodPills:      "family planning pills",
+	model.FPMethodLAM:        "breastfeeding protection (LAM)",
+}
+
+// ReminderReport is the outcome of a family planning reminder run
+type ReminderReport struct {
+	RunAt            time.Time `json:"run_at"`
+	DueReminders     int       `json:"due_reminders"`
+	OverdueReminders int       `json:"overdue_reminders"`
+	MothersSkipped   int       `json:"mothers_skipped"`
+}
+
+// ReminderJob reminds mothers when their injection, pills or other method is due to be
+// re-supplied or replaced, and again if they have not come back once it is late
+type ReminderJob struct {
+	motherRepo         repository.MotherRepository
+	familyPlanningRepo repository.FamilyPlanningRepository
+	preferenceService  *preference.Service
+	schedulerService   *scheduler.Service
+	log                logger.Logger
+}
+
+// NewReminderJob creates a new family planning reminder job
+func NewReminderJob(
+	motherRepo repository.MotherRepository,
+	familyPlanningRepo repository.FamilyPlanningRepository,
+	preferenceService *preference.Service,
+	schedulerService *scheduler.Service,
+	log logger.Logger,
+) *ReminderJob {
+	return &ReminderJob{
+		motherRepo:         motherRepo,
+		familyPlanningRepo: familyPlanningRepo,
+		preferenceService:  preferenceService,
+		schedulerService:   schedulerService,
+		log:                log,
+	}
+}
+
+// Run reminds each mother once when her current method is coming due and once more if it
+// becomes overdue. Overdue reminders are sent first, so a mother who is already late is
+// not also told it is coming due. It is meant to run daily.
+func (j *ReminderJob) Run(ctx context.Context) (*ReminderReport, error) {
+	now := time.Now()
+	today := truncateDay(now)
+	report := &ReminderReport{RunAt: now}
+
+	overdue, err := j.familyPlanningRepo.GetRemindersDue(ctx, model.FPReminderOverdue, today.AddDate(0, 0, -overdueAfterDays))
+	if err != nil {
+		j.log.Error("Failed to get overdue family planning methods", logger.Fields{
+			"error": err.Error(),
+		})
+		return nil, errorx.Wrap(err, "failed to get overdue family planning methods")
+	}
+	for _, visit := range overdue {
+		if j.remind(ctx, visit, model.FPReminderOverdue) {
+			report.OverdueReminders++
+		} else {
+			report.MothersSkipped++
+		}
+	}
+
+	due, err := j.familyPlanningRepo.GetRemindersDue(ctx, model.FPReminderDue, today.AddDate(0, 0, reminderLeadDays))
+	if err != nil {
+		j.log.Error("Failed to get family planning methods coming due", logger.Fields{
+			"error": err.Error(),
+		})
+		return nil, errorx.Wrap(err, "failed to get family planning methods coming due")
+	}
+	for _, visit := range due {
+		if visit.OverdueReminderSentAt != nil {
+			continue
+		}
+		if j.remind(ctx, visit, model.FPReminderDue) {
+			report.DueReminders++
+		} else {
+			report.MothersSkipped++
+		}
+	}
+
+	j.log.Info("Family planning reminder run finished", logger.Fields{
+		"due":     report.DueReminders,
+		"overdue": report.OverdueReminders,
+		"skipped": report.MothersSkipped,
+	})
+
+	return report, nil
+}
+
+// remind sends a mother a reminder for the method given at a visit, unless she has turned
+// visit reminders off or it was already sent. The reminder is recorded before it is
+// scheduled, so overlapping runs send it once, and the record is cleared if scheduling
+// fails so the next run tries again. It reports whether the reminder was sent.
+func (j *ReminderJob) remind(ctx context.Context, visit *model.FamilyPlanningVisit, kind model.FPReminderKind) bool {
+	mother, err := j.motherRepo.GetByID(ctx, visit.MotherID)
+	if err != nil {
+		j.log.Warn("Failed to find mother for family planning reminder", logger.Fields{
+			"error":     err.Error(),
+			"mother_id": visit.MotherID.String(),
+		})
+		return false
+	}
+
+	enabled, err := j.preferenceService.IsNotificationEnabled(ctx, mother.UserID, preference.TypeVisitReminder)
+	if err != nil {
+		j.log.Warn("Failed to check visit reminder preference", logger.Fields{
+			"error":   err.Error(),
+			"user_id": mother.UserID.String(),
+		})
+		return false
+	}
+	if !enabled {
+		return false
+	}
+
+	fresh, err := j.familyPlanningRepo.MarkReminderSent(ctx, visit.ID, kind)
+	if err != nil {
+		j.log.Warn("Failed to record family planning reminder", logger.Fields{
+			"error":    err.Error(),
+			"visit_id": visit.ID.String(),
+		})
+		return false
+	}
+	if !fresh {
+		return false
+	}
+
+	payload := reminderPayload(visit, kind)
+	if _, err := j.schedulerService.SchedulePush(ctx, mother.UserID, payload, push.PushProvider(""), time.Now().Add(reminderDelay)); err != nil {
+		j.log.Warn("Failed to schedule family planning reminder", logger.Fields{
+			"error":     err.Error(),
+			"mother_id": mother.ID.String(),
+		})
+		if err := j.familyPlanningRepo.ClearReminderSent(ctx, visit.ID, kind); err != nil {
+			j.log.Error("Failed to clear family planning reminder", logger.Fields{
+				"error":    err.Error(),
+				"visit_id": visit.ID.String(),
+			})
+		}
+		return false
+	}
+	return true
+}
+
+// reminderPayload words a reminder for the method given at a visit
+func reminderPayload(visit *model.FamilyPlanningVisit, kind model.FPReminderKind) *push.NotificationPayload {
+	method := *visit.Method
+	name := methodNames[method]
+	due := visit.NextDueDate.Format("2 January")
+
+	payload := &push.NotificationPayload{
+		Data: map[string]interface{}{
+			"type":      string(preference.TypeVisitReminder),
+			"kind":      string(kind),
+			"mother_id": visit.MotherID.String(),
+			"visit_id":  visit.ID.String(),
+			"method":    string(method),
+			"due_date":  visit.NextDueDate.Format("2006-01-02"),
+		},
+	}
+
+	switch {
+	case method == model.FPMethodLAM && kind == model.FPReminderDue:
+		payload.Title = "Time to choose a family planning method"
+		payload.Body = fmt.Sprintf("Breastfeeding stops protecting you from pregnancy on %s. Visit your clinic to start another method.", due)
+	case method == model.FPMethodLAM:
+		payload.Title = "Breastfeeding no longer protects you"
+		payload.Body = fmt.Sprintf("Breastfeeding stopped protecting you from pregnancy on %s. Please visit your clinic to start another method.", due)
+	case method.NeedsResupply() && kind == model.FPReminderDue:
+		payload.Title = "Family planning re-supply due"
+		payload.Body = fmt.Sprintf("Your next %s is due on %s. Please visit your clinic.", name, due)
+	case method.NeedsResupply():
+		payload.Title = "Family planning re-supply overdue"
+		payload.Body = fmt.Sprintf("Your %s was due on %s. Please visit your clinic as soon as you can and use condoms until then.", name, due)
+	default:
+		payload.Title = "Family planning method due for replacement"
+		payload.Body = fmt.Sprintf("Your %s should be replaced by %s. Please visit your clinic.", name, due)
+	}
+	return payload
+}
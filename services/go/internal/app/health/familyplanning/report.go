@@ -0,0 +1,130 @@
+package familyplanning
+
+import (
+	"context"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mamacare/services/internal/domain/model"
+	"github.com/mamacare/services/pkg/errorx"
+	"github.com/mamacare/services/pkg/logger"
+)
+
+// FacilityCoverage is a facility's family planning coverage over a period, with the
+// postpartum counselling and uptake rates among women who delivered there, in percent
+type FacilityCoverage struct {
+	*model.FamilyPlanningCoverage
+	CounsellingRate *float64 `json:"counselling_rate,omitempty"`
+	UptakeRate      *float64 `json:"uptake_rate,omitempty"`
+}
+
+// DistrictCoverage is the coverage of a district's facilities added together
+type DistrictCoverage struct {
+	District             string                 `json:"district"`
+	Facilities           int                    `json:"facilities"`
+	Deliveries           int                    `json:"deliveries"`
+	CounselledPostpartum int                    `json:"counselled_postpartum"`
+	PostpartumUptake     int                    `json:"postpartum_uptake"`
+	CounsellingVisits    int                    `json:"counselling_visits"`
+	NewAcceptors         int                    `json:"new_acceptors"`
+	Resupplies           int                    `json:"resupplies"`
+	Discontinuations     int                    `json:"discontinuations"`
+	MethodsGiven         map[model.FPMethod]int `json:"methods_given"`
+	CounsellingRate      *float64               `json:"counselling_rate,omitempty"`
+	UptakeRate           *float64               `json:"uptake_rate,omitempty"`
+}
+
+// CoverageReport is family planning coverage per facility and district over a period
+type CoverageReport struct {
+	From       time.Time           `json:"from"`
+	To         time.Time           `json:"to"`
+	Facilities []*FacilityCoverage `json:"facilities"`
+	Districts  []*DistrictCoverage `json:"districts"`
+}
+
+// GetCoverageReport reports postpartum family planning counselling and uptake, and the
+// methods given, per facility and district between two dates. It covers one facility or
+// all of them if facilityID is nil, and one district or all of them if district is empty.
+func (s *Service) GetCoverageReport(
+	ctx context.Context,
+	requesterID uuid.UUID,
+	from, to time.Time,
+	facilityID *uuid.UUID,
+	district string,
+) (*CoverageReport, error) {
+	if to.Before(from) {
+		return nil, errorx.New(errorx.BadRequest, "report end date must not be before its start date")
+	}
+
+	requester, err := s.requireHealthWorker(ctx, requesterID)
+	if err != nil {
+		return nil, err
+	}
+	if requester.Role == model.RoleCHW {
+		return nil, errorx.New(errorx.Forbidden, "only clinicians and admins can view family planning coverage reports")
+	}
+
+	coverage, err := s.familyPlanningRepo.GetFacilityCoverage(ctx, from, to, facilityID, strings.TrimSpace(district))
+	if err != nil {
+		s.log.Error("Failed to get family planning coverage", logger.Fields{
+			"error": err.Error(),
+			"from":  from.Format("2006-01-02"),
+			"to":    to.Format("2006-01-02"),
+		})
+		return nil, errorx.Wrap(err, "failed to get family planning coverage")
+	}
+
+	report := &CoverageReport{
+		From:       from,
+		To:         to,
+		Facilities: make([]*FacilityCoverage, 0, len(coverage)),
+		Districts:  []*DistrictCoverage{},
+	}
+
+	// Facilities come ordered by district, so each district's are together
+	var current *DistrictCoverage
+	for _, facility := range coverage {
+		report.Facilities = append(report.Facilities, &FacilityCoverage{
+			FamilyPlanningCoverage: facility,
+			CounsellingRate:        rate(facility.CounselledPostpartum, facility.Deliveries),
+			UptakeRate:             rate(facility.PostpartumUptake, facility.Deliveries),
+		})
+
+		if current == nil || current.District != facility.District {
+			current = &DistrictCoverage{
+				District:     facility.District,
+				MethodsGiven: make(map[model.FPMethod]int),
+			}
+			report.Districts = append(report.Districts, current)
+		}
+		current.Facilities++
+		current.Deliveries += facility.Deliveries
+		current.CounselledPostpartum += facility.CounselledPostpartum
+		current.PostpartumUptake += facility.PostpartumUptake
+		current.CounsellingVisits += facility.CounsellingVisits
+		current.NewAcceptors += facility.NewAcceptors
+		current.Resupplies += facility.Resupplies
+		current.Discontinuations += facility.Discontinuations
+		for method, given := range facility.MethodsGiven {
+			current.MethodsGiven[method] += given
+		}
+	}
+
+	for _, district := range report.Districts {
+		district.CounsellingRate = rate(district.CounselledPostpartum, district.Deliveries)
+		district.UptakeRate = rate(district.PostpartumUptake, district.Deliveries)
+	}
+
+	return report, nil
+}
+
+// rate is count as a percent of total to one decimal place, or nil if total is zero
+func rate(count, total int) *float64 {
+	if total == 0 {
+		return nil
+	}
+	r := math.Round(float64(count)/float64(total)*1000) / 10
+	return &r
+}
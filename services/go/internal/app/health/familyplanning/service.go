@@ -0,0 +1,467 @@
+package familyplanning
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mamacare/services/internal/domain/model"
+	"github.com/mamacare/services/internal/domain/repository"
+	"github.com/mamacare/services/pkg/errorx"
+	"github.com/mamacare/services/pkg/logger"
+)
+
+// implantYears are the implant durations that can be recorded: Implanon lasts 3 years and Jadelle 5
+var implantYears = map[int]bool{3: true, 5: true}
+
+// VisitInput is a family planning visit: counselling, the method the mother chose and
+// whether it was given, or that she stopped using her method
+type VisitInput struct {
+	FacilityID uuid.UUID
+	VisitDate  time.Time
+	Topics     []model.FPTopic
+	// Method is the method she chose. It is given at the visit only if MethodGiven is set,
+	// and replaces any method she was using.
+	Method      *model.FPMethod
+	MethodGiven bool
+	// Quantity is the pill cycles given; an injection is always one
+	Quantity int
+	// ImplantYears, if set, is how long the implant given lasts
+	ImplantYears          int
+	Discontinue           bool
+	DiscontinuationReason string
+	Notes                 string
+}
+
+// Status is a mother's family planning after her latest delivery
+type Status struct {
+	MotherID     uuid.UUID  `json:"mother_id"`
+	DeliveryDate *time.Time `json:"delivery_date,omitempty"`
+	// Gravida and Para are her pregnancies and births from her pregnancy history
+	Gravida int `json:"gravida"`
+	Para    int `json:"para"`
+	// SpacingUntil is when the next pregnancy should be planned at the earliest, 24 months
+	// after delivery. It is only set once her pregnancy history records a birth.
+	SpacingUntil *time.Time `json:"spacing_until,omitempty"`
+	Counselled   bool       `json:"counselled"`
+	// CurrentMethod is the method she was last given, unless she stopped using it
+	CurrentMethod *model.FPMethod `json:"current_method,omitempty"`
+	MethodGivenOn *time.Time      `json:"method_given_on,omitempty"`
+	NextDueDate   *time.Time      `json:"next_due_date,omitempty"`
+	Overdue       bool            `json:"overdue"`
+	// PregnancyCheckNeeded is whether an injection is so late that pregnancy must be ruled
+	// out before the next one is given
+	PregnancyCheckNeeded bool                         `json:"pregnancy_check_needed"`
+	Visits               []*model.FamilyPlanningVisit `json:"visits"`
+}
+
+// VisitResult is a recorded visit and the mother's status after it
+type VisitResult struct {
+	Visit  *model.FamilyPlanningVisit `json:"visit"`
+	Status *Status                    `json:"status"`
+}
+
+// Service manages postpartum family planning: counselling on birth spacing, the methods
+// mothers choose and are given, re-supply dates and coverage per facility and district
+type Service struct {
+	motherRepo         repository.MotherRepository
+	userRepo           repository.UserRepository
+	facilityRepo       repository.FacilityRepository
+	deliveryRepo       repository.DeliveryOutcomeRepository
+	familyPlanningRepo repository.FamilyPlanningRepository
+	log                logger.Logger
+}
+
+// NewService creates a new family planning service
+func NewService(
+	motherRepo repository.MotherRepository,
+	userRepo repository.UserRepository,
+	facilityRepo repository.FacilityRepository,
+	deliveryRepo repository.DeliveryOutcomeRepository,
+	familyPlanningRepo repository.FamilyPlanningRepository,
+	log logger.Logger,
+) *Service {
+	return &Service{
+		motherRepo:         motherRepo,
+		userRepo:           userRepo,
+		facilityRepo:       facilityRepo,
+		deliveryRepo:       deliveryRepo,
+		familyPlanningRepo: familyPlanningRepo,
+		log:                log,
+	}
+}
+
+// RecordVisit records a family planning visit. A method given is checked against the
+// postpartum eligibility rules and its next due date is worked out; giving the method she
+// was already using is a re-supply. Implants and IUDs are inserted by clinicians only.
+func (s *Service) RecordVisit(ctx context.Context, requesterID, motherID uuid.UUID, input *VisitInput) (*VisitResult, error) {
+	requester, err := s.requireHealthWorker(ctx, requesterID)
+	if err != nil {
+		return nil, err
+	}
+
+	visitDate := input.VisitDate
+	if visitDate.IsZero() {
+		visitDate = time.Now()
+	}
+	visitDate = truncateDay(visitDate)
+	if visitDate.After(time.Now()) {
+		return nil, errorx.New(errorx.BadRequest, "visit date cannot be in the future")
+	}
+
+	if err := validateVisit(input); err != nil {
+		return nil, err
+	}
+	if input.MethodGiven && requester.Role == model.RoleCHW &&
+		(*input.Method == model.FPMethodImplant || *input.Method == model.FPMethodIUD) {
+		return nil, errorx.New(errorx.Forbidden, "only clinicians can insert implants and IUDs")
+	}
+
+	mother, err := s.motherRepo.GetByID(ctx, motherID)
+	if err != nil {
+		s.log.Error("Failed to find mother", logger.Fields{
+			"error":     err.Error(),
+			"mother_id": motherID.String(),
+		})
+		return nil, errorx.Wrap(err, "failed to find mother")
+	}
+
+	if _, err := s.facilityRepo.GetByID(ctx, input.FacilityID); err != nil {
+		s.log.Error("Failed to find facility", logger.Fields{
+			"error":       err.Error(),
+			"facility_id": input.FacilityID.String(),
+		})
+		return nil, errorx.Wrap(err, "failed to find facility")
+	}
+
+	visits, err := s.getVisits(ctx, mother.ID)
+	if err != nil {
+		return nil, err
+	}
+	deliveryDate, err := s.deliveryDate(ctx, mother, visitDate)
+	if err != nil {
+		return nil, err
+	}
+
+	visit := model.NewFamilyPlanningVisit(mother.ID, input.FacilityID, requester.ID, visitDate)
+	visit.Topics = append(visit.Topics, input.Topics...)
+	visit.Method = input.Method
+	visit.Notes = strings.TrimSpace(input.Notes)
+	if deliveryDate != nil {
+		days := daysBetween(*deliveryDate, visitDate)
+		visit.PostpartumDays = &days
+	}
+
+	// Only the visits up to this one decide what she was using, as it can be backdated
+	current := currentMethod(visitsUpTo(visits, visitDate))
+	switch {
+	case input.Discontinue:
+		if current == nil {
+			return nil, errorx.New(errorx.BadRequest, "mother is not using a family planning method")
+		}
+		visit.Method = current.Method
+		visit.Discontinued = true
+		visit.DiscontinuationReason = strings.TrimSpace(input.DiscontinuationReason)
+
+	case input.MethodGiven:
+		if err := checkEligibility(*input.Method, visitDate, deliveryDate); err != nil {
+			return nil, err
+		}
+		visit.MethodGiven = true
+		visit.Quantity = input.Quantity
+		if *input.Method == model.FPMethodInjectable {
+			visit.Quantity = 1
+		}
+		visit.Resupply = current != nil && *current.Method == *input.Method && input.Method.NeedsResupply()
+		visit.NextDueDate = nextDueDate(*input.Method, visitDate, deliveryDate, visit.Quantity, input.ImplantYears)
+	}
+
+	if err := s.familyPlanningRepo.CreateVisit(ctx, visit); err != nil {
+		s.log.Error("Failed to create family planning visit", logger.Fields{
+			"error":     err.Error(),
+			"mother_id": mother.ID.String(),
+		})
+		return nil, errorx.Wrap(err, "failed to create family planning visit")
+	}
+
+	if visit.MethodGiven {
+		s.log.Info("Family planning method given", logger.Fields{
+			"mother_id": mother.ID.String(),
+			"method":    string(*visit.Method),
+			"resupply":  visit.Resupply,
+		})
+	}
+
+	return &VisitResult{
+		Visit:  visit,
+		Status: buildStatus(mother, deliveryDate, insertVisit(visits, visit), time.Now()),
+	}, nil
+}
+
+// GetStatus returns a mother's family planning status and visits. Mothers can see their own.
+func (s *Service) GetStatus(ctx context.Context, requesterID, motherID uuid.UUID) (*Status, error) {
+	requester, err := s.userRepo.GetByID(ctx, requesterID)
+	if err != nil {
+		s.log.Error("Failed to find requester", logger.Fields{
+			"error":   err.Error(),
+			"user_id": requesterID.String(),
+		})
+		return nil, errorx.Wrap(err, "failed to find requester")
+	}
+
+	mother, err := s.motherRepo.GetByID(ctx, motherID)
+	if err != nil {
+		s.log.Error("Failed to find mother", logger.Fields{
+			"error":     err.Error(),
+			"mother_id": motherID.String(),
+		})
+		return nil, errorx.Wrap(err, "failed to find mother")
+	}
+	if !requester.CanAccessMothersData(mother.UserID) {
+		return nil, errorx.New(errorx.Forbidden, "not allowed to view this mother's family planning")
+	}
+
+	visits, err := s.getVisits(ctx, mother.ID)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	deliveryDate, err := s.deliveryDate(ctx, mother, now)
+	if err != nil {
+		return nil, err
+	}
+
+	return buildStatus(mother, deliveryDate, visits, now), nil
+}
+
+// validateVisit checks the visit records something and its method details fit the method
+func validateVisit(input *VisitInput) error {
+	if len(input.Topics) == 0 && input.Method == nil && !input.Discontinue {
+		return errorx.New(errorx.BadRequest, "a visit must record counselling, a method or a discontinuation")
+	}
+	for _, topic := range input.Topics {
+		if !topic.IsValid() {
+			return errorx.Newf(errorx.BadRequest, "invalid counselling topic: %s", topic)
+		}
+	}
+
+	if input.Discontinue {
+		if input.MethodGiven {
+			return errorx.New(errorx.BadRequest, "a method given replaces the current one; do not also discontinue it")
+		}
+		return nil
+	}
+	if input.Method != nil && !input.Method.IsValid() {
+		return errorx.Newf(errorx.BadRequest, "invalid family planning method: %s", *input.Method)
+	}
+	if input.MethodGiven && input.Method == nil {
+		return errorx.New(errorx.BadRequest, "method is required when a method is given")
+	}
+
+	method := model.FPMethod("")
+	if input.MethodGiven {
+		method = *input.Method
+	}
+	switch {
+	case method == model.FPMethodPills && (input.Quantity < 1 || input.Quantity > model.FPMaxPillCycles):
+		return errorx.Newf(errorx.BadRequest, "between 1 and %d pill cycles must be given", model.FPMaxPillCycles)
+	case method != model.FPMethodPills && method != model.FPMethodInjectable && input.Quantity != 0:
+		return errorx.New(errorx.BadRequest, "quantity is only recorded for pills and injections")
+	case method == model.FPMethodImplant && input.ImplantYears != 0 && !implantYears[input.ImplantYears]:
+		return errorx.New(errorx.BadRequest, "implants last 3 or 5 years")
+	case method != model.FPMethodImplant && input.ImplantYears != 0:
+		return errorx.New(errorx.BadRequest, "implant years are only recorded when an implant is given")
+	}
+	return nil
+}
+
+// checkEligibility applies the postpartum rules for a method: LAM only protects in the
+// first 6 months after delivery, and an IUD is inserted within 48 hours of delivery or
+// from 4 weeks after it, not in between
+func checkEligibility(method model.FPMethod, visitDate time.Time, deliveryDate *time.Time) error {
+	switch method {
+	case model.FPMethodLAM:
+		if deliveryDate == nil {
+			return errorx.New(errorx.BadRequest, "LAM can only be used after delivery")
+		}
+		if !visitDate.Before(deliveryDate.AddDate(0, model.FPLAMMaxMonths, 0)) {
+			return errorx.Newf(errorx.BadRequest, "LAM only protects for the first %d months after delivery", model.FPLAMMaxMonths)
+		}
+	case model.FPMethodIUD:
+		if deliveryDate == nil {
+			return nil
+		}
+		days := daysBetween(*deliveryDate, visitDate)
+		if days >= model.FPPostpartumIUDDays && days < model.FPIntervalIUDDays {
+			return errorx.Newf(errorx.BadRequest, "an IUD cannot be inserted from 48 hours to %d days after delivery; it can be inserted from %s",
+				model.FPIntervalIUDDays, deliveryDate.AddDate(0, 0, model.FPIntervalIUDDays).Format("2006-01-02"))
+		}
+	}
+	return nil
+}
+
+// nextDueDate is when a method given on a date is next due: the next injection, the end
+// of the pills given, implant or IUD replacement, or the end of LAM's protection
+func nextDueDate(method model.FPMethod, visitDate time.Time, deliveryDate *time.Time, quantity, years int) *time.Time {
+	var due time.Time
+	switch method {
+	case model.FPMethodInjectable:
+		due = visitDate.AddDate(0, 0, model.FPInjectableIntervalDays)
+	case model.FPMethodPills:
+		due = visitDate.AddDate(0, 0, model.FPPillCycleDays*quantity)
+	case model.FPMethodImplant:
+		if years == 0 {
+			years = model.FPImplantYears
+		}
+		due = visitDate.AddDate(years, 0, 0)
+	case model.FPMethodIUD:
+		due = visitDate.AddDate(model.FPIUDYears, 0, 0)
+	case model.FPMethodLAM:
+		due = truncateDay(*deliveryDate).AddDate(0, model.FPLAMMaxMonths, 0)
+	default:
+		return nil
+	}
+	return &due
+}
+
+// currentMethod returns the visit at which the mother was given the method she is using,
+// or nil if she has none or stopped. Visits are newest first.
+func currentMethod(visits []*model.FamilyPlanningVisit) *model.FamilyPlanningVisit {
+	for _, visit := range visits {
+		if visit.Discontinued {
+			return nil
+		}
+		if visit.MethodGiven {
+			return visit
+		}
+	}
+	return nil
+}
+
+// visitsUpTo returns the visits on or before a date, keeping their order
+func visitsUpTo(visits []*model.FamilyPlanningVisit, date time.Time) []*model.FamilyPlanningVisit {
+	var earlier []*model.FamilyPlanningVisit
+	for _, visit := range visits {
+		if !visit.VisitDate.After(date) {
+			earlier = append(earlier, visit)
+		}
+	}
+	return earlier
+}
+
+// insertVisit adds a newly recorded visit to visits ordered newest first, as they are
+// loaded: by visit date, then the latest recorded first
+func insertVisit(visits []*model.FamilyPlanningVisit, visit *model.FamilyPlanningVisit) []*model.FamilyPlanningVisit {
+	i := 0
+	for i < len(visits) && visits[i].VisitDate.After(visit.VisitDate) {
+		i++
+	}
+
+	result := make([]*model.FamilyPlanningVisit, 0, len(visits)+1)
+	result = append(result, visits[:i]...)
+	result = append(result, visit)
+	return append(result, visits[i:]...)
+}
+
+// buildStatus summarises a mother's visits, newest first
+func buildStatus(mother *model.Mother, deliveryDate *time.Time, visits []*model.FamilyPlanningVisit, now time.Time) *Status {
+	status := &Status{
+		MotherID:     mother.ID,
+		DeliveryDate: deliveryDate,
+		Gravida:      mother.PregnancyHistory.PreviousPregnancies,
+		Para:         mother.PregnancyHistory.PreviousDeliveries,
+		Visits:       visits,
+	}
+	if status.Visits == nil {
+		status.Visits = []*model.FamilyPlanningVisit{}
+	}
+	if deliveryDate != nil && status.Para > 0 {
+		until := deliveryDate.AddDate(0, model.FPBirthSpacingMonths, 0)
+		status.SpacingUntil = &until
+	}
+
+	for _, visit := range visits {
+		if visit.Counselled() && (deliveryDate == nil || !visit.VisitDate.Before(truncateDay(*deliveryDate))) {
+			status.Counselled = true
+			break
+		}
+	}
+
+	if current := currentMethod(visits); current != nil {
+		status.CurrentMethod = current.Method
+		status.MethodGivenOn = &current.VisitDate
+		status.NextDueDate = current.NextDueDate
+		if current.NextDueDate != nil {
+			today := truncateDay(now)
+			status.Overdue = today.After(*current.NextDueDate)
+			status.PregnancyCheckNeeded = *current.Method == model.FPMethodInjectable &&
+				today.After(current.NextDueDate.AddDate(0, 0, model.FPInjectableGraceDays))
+		}
+	}
+
+	return status
+}
+
+// deliveryDate returns the mother's latest delivery on or before a date, from her delivery
+// outcomes or, if none was recorded, her registration. It is nil if she has not delivered.
+func (s *Service) deliveryDate(ctx context.Context, mother *model.Mother, on time.Time) (*time.Time, error) {
+	outcomes, err := s.deliveryRepo.GetByMotherID(ctx, mother.ID)
+	if err != nil {
+		s.log.Error("Failed to get delivery outcomes", logger.Fields{
+			"error":     err.Error(),
+			"mother_id": mother.ID.String(),
+		})
+		return nil, errorx.Wrap(err, "failed to get delivery outcomes")
+	}
+
+	for _, outcome := range outcomes {
+		if !truncateDay(outcome.DeliveryDate).After(on) {
+			delivered := outcome.DeliveryDate
+			return &delivered, nil
+		}
+	}
+	if len(outcomes) == 0 && mother.DeliveryDate != nil && !truncateDay(*mother.DeliveryDate).After(on) {
+		return mother.DeliveryDate, nil
+	}
+	return nil, nil
+}
+
+// getVisits loads a mother's visits, newest first
+func (s *Service) getVisits(ctx context.Context, motherID uuid.UUID) ([]*model.FamilyPlanningVisit, error) {
+	visits, err := s.familyPlanningRepo.GetVisitsByMotherID(ctx, motherID)
+	if err != nil {
+		s.log.Error("Failed to get family planning visits", logger.Fields{
+			"error":     err.Error(),
+			"mother_id": motherID.String(),
+		})
+		return nil, errorx.Wrap(err, "failed to get family planning visits")
+	}
+	return visits, nil
+}
+
+// requireHealthWorker checks that the requester is a CHW, clinician or admin
+func (s *Service) requireHealthWorker(ctx context.Context, requesterID uuid.UUID) (*model.User, error) {
+	requester, err := s.userRepo.GetByID(ctx, requesterID)
+	if err != nil {
+		s.log.Error("Failed to find requester", logger.Fields{
+			"error":   err.Error(),
+			"user_id": requesterID.String(),
+		})
+		return nil, errorx.Wrap(err, "failed to find requester")
+	}
+	if requester.Role != model.RoleCHW && requester.Role != model.RoleClinician && requester.Role != model.RoleAdmin {
+		return nil, errorx.New(errorx.Forbidden, "only health workers can record family planning")
+	}
+	return requester, nil
+}
+
+// truncateDay returns the start of the day of a time
+func truncateDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
+
+// daysBetween returns the whole days from one date to another
+func daysBetween(from, to time.Time) int {
+	return int(truncateDay(to).Sub(truncateDay(from)).Hours() / 24)
+}
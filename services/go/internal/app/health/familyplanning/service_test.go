@@ -0,0 +1,81 @@
+package familyplanning
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mamacare/services/internal/domain/model"
+)
+
+// date returns midnight UTC on a day
+func date(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+func TestCheckEligibility(t *testing.T) {
+	delivered := date(2024, 3, 1)
+
+	tests := []struct {
+		name         string
+		method       model.FPMethod
+		visitDate    time.Time
+		deliveryDate *time.Time
+		wantErr      bool
+	}{
+		{"LAM before delivery", model.FPMethodLAM, delivered, nil, true},
+		{"LAM five months after delivery", model.FPMethodLAM, date(2024, 8, 1), &delivered, false},
+		{"LAM the day before six months", model.FPMethodLAM, date(2024, 8, 31), &delivered, false},
+		{"LAM six months after delivery", model.FPMethodLAM, date(2024, 9, 1), &delivered, true},
+		{"IUD before delivery", model.FPMethodIUD, delivered, nil, false},
+		{"IUD on the day of delivery", model.FPMethodIUD, delivered, &delivered, false},
+		{"IUD the day after delivery", model.FPMethodIUD, date(2024, 3, 2), &delivered, false},
+		{"IUD two days after delivery", model.FPMethodIUD, date(2024, 3, 3), &delivered, true},
+		{"IUD the day before four weeks", model.FPMethodIUD, date(2024, 3, 28), &delivered, true},
+		{"IUD four weeks after delivery", model.FPMethodIUD, date(2024, 3, 29), &delivered, false},
+		{"injectable two days after delivery", model.FPMethodInjectable, date(2024, 3, 3), &delivered, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkEligibility(tt.method, tt.visitDate, tt.deliveryDate)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("checkEligibility() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestNextDueDate(t *testing.T) {
+	visit := date(2024, 4, 15)
+	delivered := time.Date(2024, 3, 1, 10, 30, 0, 0, time.UTC)
+	due := func(d time.Time) *time.Time { return &d }
+
+	tests := []struct {
+		name     string
+		method   model.FPMethod
+		quantity int
+		years    int
+		want     *time.Time
+	}{
+		{"injectable after 13 weeks", model.FPMethodInjectable, 0, 0, due(date(2024, 7, 15))},
+		{"pills at the end of the cycles given", model.FPMethodPills, 3, 0, due(date(2024, 7, 8))},
+		{"implant with the default duration", model.FPMethodImplant, 0, 0, due(date(2027, 4, 15))},
+		{"five year implant", model.FPMethodImplant, 0, 5, due(date(2029, 4, 15))},
+		{"IUD after ten years", model.FPMethodIUD, 0, 0, due(date(2034, 4, 15))},
+		{"LAM six months after the day of delivery", model.FPMethodLAM, 0, 0, due(date(2024, 9, 1))},
+		{"unknown method", model.FPMethod("condoms"), 0, 0, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := nextDueDate(tt.method, visit, &delivered, tt.quantity, tt.years)
+			switch {
+			case got == nil && tt.want == nil:
+			case got == nil || tt.want == nil:
+				t.Errorf("nextDueDate() = %v, want %v", got, tt.want)
+			case !got.Equal(*tt.want):
+				t.Errorf("nextDueDate() = %s, want %s", got.Format("2006-01-02"), tt.want.Format("2006-01-02"))
+			}
+		})
+	}
+}
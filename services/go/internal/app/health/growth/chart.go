@@ -0,0 +1,349 @@
+package growth
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mamacare/services/internal/app/health/calculator"
+	"github.com/mamacare/services/internal/domain/model"
+)
+
+// Measure is a fetal growth measurement that is plotted on a chart
+type Measure string
+
+const (
+	// MeasureFundalHeight is symphysis-fundal height in cm
+	MeasureFundalHeight Measure = "fundal_height"
+	// MeasureFetalWeight is ultrasound estimated fetal weight in grams
+	MeasureFetalWeight Measure = "estimated_fetal_weight"
+)
+
+// Flag is a growth finding on one of the charts
+type Flag string
+
+const (
+	// FlagSmallForGestationalAge means the latest measurement is below the 10th centile
+	FlagSmallForGestationalAge Flag = "small_for_gestational_age"
+	// FlagLargeForGestationalAge means the latest measurement is above the 90th centile
+	FlagLargeForGestationalAge Flag = "large_for_gestational_age"
+	// FlagFallingCentiles means growth has dropped across centiles between measurements
+	FlagFallingCentiles Flag = "falling_centiles"
+	// FlagRisingCentiles means growth has climbed across centiles between measurements
+	FlagRisingCentiles Flag = "rising_centiles"
+	// FlagStaticGrowth means the measurement has not increased over two weeks or more
+	FlagStaticGrowth Flag = "static_growth"
+	// FlagMacrosomia means the estimated fetal weight is 4000 g or more
+	FlagMacrosomia Flag = "macrosomia"
+)
+
+// Concern is the overall growth problem the findings point to
+type Concern string
+
+const (
+	// ConcernNone means growth is following the reference curves
+	ConcernNone Concern = "none"
+	// ConcernGrowthRestriction means intrauterine growth restriction is suspected
+	ConcernGrowthRestriction Concern = "growth_restriction_suspected"
+	// ConcernExcessGrowth means macrosomia or excess growth is suspected
+	ConcernExcessGrowth Concern = "excess_growth_suspected"
+)
+
+// Thresholds for comparing serial measurements
+const (
+	// minComparisonDays is the shortest gap between measurements that are compared
+	minComparisonDays = 14
+	// centileShiftZ is the change in z-score that counts as crossing centiles
+	centileShiftZ = 1.0
+	// staticFundalHeightCm is the least fundal height should grow over two weeks
+	staticFundalHeightCm = 1.0
+	// macrosomiaGrams is the estimated fetal weight that defines macrosomia
+	macrosomiaGrams = 4000.0
+)
+
+// Point is a measurement plotted against the reference curve
+type Point struct {
+	MetricID            uuid.UUID `json:"metric_id"`
+	RecordedAt          time.Time `json:"recorded_at"`
+	GestationalAgeDays  int       `json:"gestational_age_days"`
+	GestationalAgeWeeks float64   `json:"gestational_age_weeks"`
+	Value               float64   `json:"value"`
+	Expected            float64   `json:"expected"`
+	P10                 float64   `json:"p10"`
+	P90                 float64   `json:"p90"`
+	ZScore              float64   `json:"z_score"`
+	Centile             float64   `json:"centile"`
+}
+
+// ReferencePoint is a point on the reference curve, weekly, for drawing the chart
+type ReferencePoint struct {
+	Weeks int     `json:"weeks"`
+	P10   float64 `json:"p10"`
+	P50   float64 `json:"p50"`
+	P90   float64 `json:"p90"`
+}
+
+// Chart is one measure plotted against its growth standard
+type Chart struct {
+	Measure   Measure          `json:"measure"`
+	Unit      string           `json:"unit"`
+	Standard  string           `json:"standard"`
+	Reference []ReferencePoint `json:"reference"`
+	Points    []Point          `json:"points"`
+}
+
+// Latest returns the most recent point on the chart, or nil if there are none
+func (c *Chart) Latest() *Point {
+	if c == nil || len(c.Points) == 0 {
+		return nil
+	}
+	return &c.Points[len(c.Points)-1]
+}
+
+// Finding is a growth flag with the measurement that raised it
+type Finding struct {
+	Flag    Flag    `json:"flag"`
+	Measure Measure `json:"measure"`
+	Message string  `json:"message"`
+}
+
+// Assessment is a mother's fetal growth charts and what they show
+type Assessment struct {
+	MotherID            uuid.UUID `json:"mother_id"`
+	GestationalAgeWeeks *int      `json:"gestational_age_weeks,omitempty"`
+	FundalHeight        *Chart    `json:"fundal_height"`
+	FetalWeight         *Chart    `json:"estimated_fetal_weight"`
+	Findings            []Finding `json:"findings"`
+	Concern             Concern   `json:"concern"`
+	Recommendation      string    `json:"recommendation,omitempty"`
+	AssessedAt          time.Time `json:"assessed_at"`
+}
+
+// HasFlag checks if any finding raised the flag
+func (a *Assessment) HasFlag(flag Flag) bool {
+	for _, finding := range a.Findings {
+		if finding.Flag == flag {
+			return true
+		}
+	}
+	return false
+}
+
+// series describes how to read and chart one measure
+type series struct {
+	measure   Measure
+	unit      string
+	standard  string
+	minDays   int
+	maxDays   int
+	value     func(model.VitalSigns) *float64
+	reference func(int) (*calculator.GrowthReference, error)
+}
+
+var (
+	fundalHeightSeries = series{
+		measure:   MeasureFundalHeight,
+		unit:      "cm",
+		standard:  calculator.StandardIntergrowthSFH,
+		minDays:   calculator.FundalHeightMinDays,
+		maxDays:   calculator.FundalHeightMaxDays,
+		value:     func(v model.VitalSigns) *float64 { return v.FundalHeightCm },
+		reference: calculator.FundalHeightReference,
+	}
+	fetalWeightSeries = series{
+		measure:   MeasureFetalWeight,
+		unit:      "g",
+		standard:  calculator.StandardHadlockEFW,
+		minDays:   calculator.FetalWeightMinDays,
+		maxDays:   calculator.FetalWeightMaxDays,
+		value:     func(v model.VitalSigns) *float64 { return v.EstimatedFetalWeight },
+		reference: calculator.FetalWeightReference,
+	}
+)
+
+// Assess plots a mother's fundal height and estimated fetal weight against the growth
+// standards at the gestational age implied by her EDD, and flags growth restriction or
+// excess growth from the latest measurement and from serial measurements
+func Assess(mother *model.Mother, metrics []*model.HealthMetric, now time.Time) *Assessment {
+	assessment := &Assessment{
+		MotherID:     mother.ID,
+		FundalHeight: buildChart(fundalHeightSeries, mother, metrics),
+		FetalWeight:  buildChart(fetalWeightSeries, mother, metrics),
+		Findings:     []Finding{},
+		Concern:      ConcernNone,
+		AssessedAt:   now,
+	}
+
+	if !mother.IsPostpartum() {
+		weeks := mother.GetWeeksPregnant(now)
+		assessment.GestationalAgeWeeks = &weeks
+	}
+
+	assessment.Findings = append(assessment.Findings, evaluateChart(assessment.FundalHeight)...)
+	assessment.Findings = append(assessment.Findings, evaluateChart(assessment.FetalWeight)...)
+
+	if latest := assessment.FetalWeight.Latest(); latest != nil && latest.Value >= macrosomiaGrams {
+		assessment.Findings = append(assessment.Findings, Finding{
+			Flag:    FlagMacrosomia,
+			Measure: MeasureFetalWeight,
+			Message: "estimated fetal weight is 4000 g or more",
+		})
+	}
+
+	switch {
+	case assessment.HasFlag(FlagSmallForGestationalAge) ||
+		assessment.HasFlag(FlagFallingCentiles) ||
+		assessment.HasFlag(FlagStaticGrowth):
+		assessment.Concern = ConcernGrowthRestriction
+		assessment.Recommendation = "Refer for ultrasound biometry and umbilical artery Doppler to assess for fetal growth restriction"
+	case assessment.HasFlag(FlagLargeForGestationalAge) ||
+		assessment.HasFlag(FlagRisingCentiles) ||
+		assessment.HasFlag(FlagMacrosomia):
+		assessment.Concern = ConcernExcessGrowth
+		assessment.Recommendation = "Refer for ultrasound and screening for gestational diabetes; plan delivery where shoulder dystocia can be managed"
+	}
+
+	return assessment
+}
+
+// buildChart plots the measurements of one series, oldest first, with its reference curve
+func buildChart(s series, mother *model.Mother, metrics []*model.HealthMetric) *Chart {
+	chart := &Chart{
+		Measure:   s.measure,
+		Unit:      s.unit,
+		Standard:  s.standard,
+		Reference: []ReferencePoint{},
+		Points:    []Point{},
+	}
+
+	for weeks := s.minDays / 7; weeks <= s.maxDays/7; weeks++ {
+		ref, err := s.reference(weeks * 7)
+		if err != nil {
+			continue
+		}
+		chart.Reference = append(chart.Reference, ReferencePoint{
+			Weeks: weeks,
+			P10:   round1(ref.P10),
+			P50:   round1(ref.Mean),
+			P90:   round1(ref.P90),
+		})
+	}
+
+	for _, metric := range metrics {
+		value := s.value(metric.VitalSigns)
+		if value == nil {
+			continue
+		}
+		// Measurements after delivery are not fetal growth
+		if mother.DeliveryDate != nil && metric.RecordedAt.After(*mother.DeliveryDate) {
+			continue
+		}
+
+		days := gestationalAgeDays(mother, metric.RecordedAt)
+		ref, err := s.reference(days)
+		if err != nil {
+			// Outside the range the standard covers
+			continue
+		}
+
+		z := ref.ZScore(*value)
+		chart.Points = append(chart.Points, Point{
+			MetricID:            metric.ID,
+			RecordedAt:          metric.RecordedAt,
+			GestationalAgeDays:  days,
+			GestationalAgeWeeks: round1(float64(days) / 7),
+			Value:               *value,
+			Expected:            round1(ref.Mean),
+			P10:                 round1(ref.P10),
+			P90:                 round1(ref.P90),
+			ZScore:              math.Round(z*100) / 100,
+			Centile:             round1(calculator.ZScoreCentile(z)),
+		})
+	}
+
+	sort.Slice(chart.Points, func(i, j int) bool {
+		return chart.Points[i].RecordedAt.Before(chart.Points[j].RecordedAt)
+	})
+
+	return chart
+}
+
+// evaluateChart flags the latest point against the centiles and compares it with the
+// most recent measurement at least two weeks before it
+func evaluateChart(chart *Chart) []Finding {
+	findings := []Finding{}
+
+	latest := chart.Latest()
+	if latest == nil {
+		return findings
+	}
+
+	switch {
+	case latest.ZScore < calculator.ZScoreP10:
+		findings = append(findings, Finding{
+			Flag:    FlagSmallForGestationalAge,
+			Measure: chart.Measure,
+			Message: "latest measurement is below the 10th centile",
+		})
+	case latest.ZScore > calculator.ZScoreP90:
+		findings = append(findings, Finding{
+			Flag:    FlagLargeForGestationalAge,
+			Measure: chart.Measure,
+			Message: "latest measurement is above the 90th centile",
+		})
+	}
+
+	previous := previousPoint(chart.Points, latest)
+	if previous == nil {
+		return findings
+	}
+
+	shift := latest.ZScore - previous.ZScore
+	switch {
+	case shift <= -centileShiftZ:
+		findings = append(findings, Finding{
+			Flag:    FlagFallingCentiles,
+			Measure: chart.Measure,
+			Message: "growth has fallen across centiles since the previous measurement",
+		})
+	case shift >= centileShiftZ:
+		findings = append(findings, Finding{
+			Flag:    FlagRisingCentiles,
+			Measure: chart.Measure,
+			Message: "growth has risen across centiles since the previous measurement",
+		})
+	}
+
+	growth := latest.Value - previous.Value
+	if (chart.Measure == MeasureFundalHeight && growth < staticFundalHeightCm) ||
+		(chart.Measure == MeasureFetalWeight && growth <= 0) {
+		findings = append(findings, Finding{
+			Flag:    FlagStaticGrowth,
+			Measure: chart.Measure,
+			Message: "measurement has not increased over two weeks or more",
+		})
+	}
+
+	return findings
+}
+
+// previousPoint returns the latest point at least minComparisonDays of gestation before the given one
+func previousPoint(points []Point, latest *Point) *Point {
+	for i := len(points) - 1; i >= 0; i-- {
+		if latest.GestationalAgeDays-points[i].GestationalAgeDays >= minComparisonDays {
+			return &points[i]
+		}
+	}
+	return nil
+}
+
+// gestationalAgeDays returns the gestational age on a date from the mother's EDD
+func gestationalAgeDays(mother *model.Mother, at time.Time) int {
+	return 280 - int(math.Round(mother.ExpectedDeliveryDate.Sub(at).Hours()/24))
+}
+
+// round1 rounds to one decimal place
+func round1(v float64) float64 {
+	return math.Round(v*10) / 10
+}
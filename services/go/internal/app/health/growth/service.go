@@ -0,0 +1,179 @@
+package growth
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mamacare/services/internal/domain/model"
+	"github.com/mamacare/services/internal/domain/repository"
+	"github.com/mamacare/services/pkg/errorx"
+	"github.com/mamacare/services/pkg/logger"
+)
+
+// MeasurementInput is a fundal height or estimated fetal weight taken at a visit or scan
+type MeasurementInput struct {
+	FundalHeightCm       *float64
+	EstimatedFetalWeight *float64
+	RecordedAt           time.Time
+	VisitID              *uuid.UUID
+	RecordedByID         *uuid.UUID
+	Notes                string
+}
+
+// MeasurementResult is the stored measurement and the growth assessment that includes it
+type MeasurementResult struct {
+	Metric     *model.HealthMetric `json:"metric"`
+	Assessment *Assessment         `json:"assessment"`
+}
+
+// Service records fetal growth measurements and plots them against growth standards
+type Service struct {
+	motherRepo       repository.MotherRepository
+	healthMetricRepo repository.HealthMetricRepository
+	log              logger.Logger
+}
+
+// NewService creates a new fetal growth service
+func NewService(
+	motherRepo repository.MotherRepository,
+	healthMetricRepo repository.HealthMetricRepository,
+	log logger.Logger,
+) *Service {
+	return &Service{
+		motherRepo:       motherRepo,
+		healthMetricRepo: healthMetricRepo,
+		log:              log,
+	}
+}
+
+// RecordMeasurement stores a fundal height or estimated fetal weight as a health metric
+// and reassesses fetal growth. The metric is flagged abnormal when it raises a growth flag.
+func (s *Service) RecordMeasurement(ctx context.Context, motherID uuid.UUID, input *MeasurementInput) (*MeasurementResult, error) {
+	if input.FundalHeightCm == nil && input.EstimatedFetalWeight == nil {
+		return nil, errorx.New(errorx.BadRequest, "fundal height or estimated fetal weight is required")
+	}
+	if input.FundalHeightCm != nil && (*input.FundalHeightCm < 5 || *input.FundalHeightCm > 60) {
+		return nil, errorx.New(errorx.BadRequest, "fundal height must be between 5 and 60 cm")
+	}
+	if input.EstimatedFetalWeight != nil && (*input.EstimatedFetalWeight < 50 || *input.EstimatedFetalWeight > 7000) {
+		return nil, errorx.New(errorx.BadRequest, "estimated fetal weight must be between 50 and 7000 g")
+	}
+	if input.RecordedAt.IsZero() {
+		input.RecordedAt = time.Now()
+	}
+	if input.RecordedAt.After(time.Now()) {
+		return nil, errorx.New(errorx.BadRequest, "measurement date cannot be in the future")
+	}
+
+	mother, err := s.motherRepo.GetByID(ctx, motherID)
+	if err != nil {
+		s.log.Error("Failed to find mother", logger.Fields{
+			"error":     err.Error(),
+			"mother_id": motherID.String(),
+		})
+		return nil, errorx.Wrap(err, "failed to find mother")
+	}
+
+	if mother.DeliveryDate != nil && input.RecordedAt.After(*mother.DeliveryDate) {
+		return nil, errorx.New(errorx.BadRequest, "mother has already delivered")
+	}
+
+	metric := model.NewHealthMetric(uuid.New(), motherID)
+	metric.RecordedAt = input.RecordedAt
+	if input.VisitID != nil {
+		metric.WithVisit(*input.VisitID)
+	}
+	if input.RecordedByID != nil {
+		metric.WithRecordedBy(*input.RecordedByID)
+	}
+	if input.FundalHeightCm != nil {
+		metric.WithFundalHeight(*input.FundalHeightCm)
+	}
+	if input.EstimatedFetalWeight != nil {
+		metric.WithEstimatedFetalWeight(*input.EstimatedFetalWeight)
+	}
+	if input.Notes != "" {
+		metric.WithNotes(input.Notes)
+	}
+
+	metrics, err := s.healthMetricRepo.FindByMother(ctx, motherID)
+	if err != nil {
+		s.log.Error("Failed to get health metrics", logger.Fields{
+			"error":     err.Error(),
+			"mother_id": motherID.String(),
+		})
+		return nil, errorx.Wrap(err, "failed to get health metrics")
+	}
+
+	assessment := Assess(mother, append(metrics, metric), time.Now())
+	if raisesFlag(assessment, metric.ID) {
+		metric.SetAbnormal(true)
+	}
+
+	if err := s.healthMetricRepo.Save(ctx, metric); err != nil {
+		s.log.Error("Failed to save growth measurement", logger.Fields{
+			"error":     err.Error(),
+			"mother_id": motherID.String(),
+		})
+		return nil, errorx.Wrap(err, "failed to save growth measurement")
+	}
+
+	s.log.Info("Fetal growth measurement recorded", logger.Fields{
+		"mother_id": motherID.String(),
+		"metric_id": metric.ID.String(),
+		"concern":   string(assessment.Concern),
+		"findings":  len(assessment.Findings),
+	})
+
+	return &MeasurementResult{
+		Metric:     metric,
+		Assessment: assessment,
+	}, nil
+}
+
+// AssessMother builds a mother's fetal growth charts and flags from all her measurements
+func (s *Service) AssessMother(ctx context.Context, motherID uuid.UUID) (*Assessment, error) {
+	mother, err := s.motherRepo.GetByID(ctx, motherID)
+	if err != nil {
+		s.log.Error("Failed to find mother", logger.Fields{
+			"error":     err.Error(),
+			"mother_id": motherID.String(),
+		})
+		return nil, errorx.Wrap(err, "failed to find mother")
+	}
+
+	metrics, err := s.healthMetricRepo.FindByMother(ctx, motherID)
+	if err != nil {
+		s.log.Error("Failed to get health metrics", logger.Fields{
+			"error":     err.Error(),
+			"mother_id": motherID.String(),
+		})
+		return nil, errorx.Wrap(err, "failed to get health metrics")
+	}
+
+	assessment := Assess(mother, metrics, time.Now())
+
+	s.log.Info("Fetal growth assessment completed", logger.Fields{
+		"mother_id":     motherID.String(),
+		"concern":       string(assessment.Concern),
+		"fundal_height": len(assessment.FundalHeight.Points),
+		"fetal_weight":  len(assessment.FetalWeight.Points),
+	})
+
+	return assessment, nil
+}
+
+// raisesFlag checks if the metric is the latest point of a chart that has findings
+func raisesFlag(assessment *Assessment, metricID uuid.UUID) bool {
+	for _, finding := range assessment.Findings {
+		chart := assessment.FundalHeight
+		if finding.Measure == MeasureFetalWeight {
+			chart = assessment.FetalWeight
+		}
+		if latest := chart.Latest(); latest != nil && latest.MetricID == metricID {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,348 @@
+package metrics
+
+import (
+	"github.com/mamacare/services/internal/domain/model"
+)
+
+// Metrics covered by the reference range catalogue
+const (
+	MetricSystolic       = "blood_pressure_systolic"
+	MetricDiastolic      = "blood_pressure_diastolic"
+	MetricFetalHeartRate = "fetal_heart_rate"
+	MetricFetalMovement  = "fetal_movement"
+	MetricHemoglobin     = "hemoglobin"
+	MetricBloodSugar     = "blood_sugar"
+	MetricWeight         = "weight"
+	MetricWeightGain     = "weight_gain"
+)
+
+// Limit is one edge of a reference range
+type Limit struct {
+	Value float64 `json:"value"`
+	// Inclusive means the limit value itself is outside the range
+	Inclusive bool `json:"inclusive"`
+}
+
+// beyond creates a limit where values past v, under a lower or over an upper limit, are abnormal
+func beyond(v float64) *Limit {
+	return &Limit{Value: v}
+}
+
+// atOrAbove creates an upper limit where v and higher are abnormal
+func atOrAbove(v float64) *Limit {
+	return &Limit{Value: v, Inclusive: true}
+}
+
+// RangeStatus is where a value falls against a reference range
+type RangeStatus string
+
+const (
+	// RangeNormal means the value is within range
+	RangeNormal RangeStatus = "normal"
+	// RangeLow means the value is below range
+	RangeLow RangeStatus = "low"
+	// RangeHigh means the value is above range
+	RangeHigh RangeStatus = "high"
+	// RangeSevereLow means the value is below the severe limit
+	RangeSevereLow RangeStatus = "severe_low"
+	// RangeSevereHigh means the value is above the severe limit
+	RangeSevereHigh RangeStatus = "severe_high"
+)
+
+// ReferenceRange defines normal and severe limits for a vital sign at a point in pregnancy
+type ReferenceRange struct {
+	Metric     string `json:"metric"`
+	Unit       string `json:"unit"`
+	Low        *Limit `json:"low,omitempty"`
+	High       *Limit `json:"high,omitempty"`
+	SevereLow  *Limit `json:"severe_low,omitempty"`
+	SevereHigh *Limit `json:"severe_high,omitempty"`
+	Source     string `json:"source,omitempty"`
+}
+
+// Classify reports where a value falls in the range
+func (r ReferenceRange) Classify(value float64) RangeStatus {
+	switch {
+	case isBelow(value, r.SevereLow):
+		return RangeSevereLow
+	case isAbove(value, r.SevereHigh):
+		return RangeSevereHigh
+	case isBelow(value, r.Low):
+		return RangeLow
+	case isAbove(value, r.High):
+		return RangeHigh
+	default:
+		return RangeNormal
+	}
+}
+
+// isBelow checks a value against a lower limit
+func isBelow(value float64, limit *Limit) bool {
+	if limit == nil {
+		return false
+	}
+	if limit.Inclusive {
+		return value <= limit.Value
+	}
+	return value < limit.Value
+}
+
+// isAbove checks a value against an upper limit
+func isAbove(value float64, limit *Limit) bool {
+	if limit == nil {
+		return false
+	}
+	if limit.Inclusive {
+		return value >= limit.Value
+	}
+	return value > limit.Value
+}
+
+// GestationContext is the point in pregnancy or the postpartum period a value was taken at
+type GestationContext struct {
+	// Weeks is the gestational age, or weeks since delivery when Postpartum is set
+	Weeks      int  `json:"weeks"`
+	Postpartum bool `json:"postpartum"`
+}
+
+// Pregnant creates a context for a gestational age in weeks
+func Pregnant(weeks int) GestationContext {
+	return GestationContext{Weeks: weeks}
+}
+
+// PostpartumWeeks creates a context for a number of weeks after delivery
+func PostpartumWeeks(weeks int) GestationContext {
+	return GestationContext{Weeks: weeks, Postpartum: true}
+}
+
+// Trimester returns the trimester (1-3), or 0 when postpartum
+func (c GestationContext) Trimester() int {
+	switch {
+	case c.Postpartum:
+		return 0
+	case c.Weeks < 14:
+		return 1
+	case c.Weeks < 28:
+		return 2
+	default:
+		return 3
+	}
+}
+
+// rangeEntry is a catalogue row: a range that applies to a metric within a window of weeks
+type rangeEntry struct {
+	metric     string
+	postpartum bool
+	minWeeks   int // inclusive
+	maxWeeks   int // inclusive
+	timing     model.GlucoseTiming
+	rng        ReferenceRange
+}
+
+// openWeeks is the upper bound for entries that apply to the end of a period
+const openWeeks = 1000
+
+// WeightGainTarget is the IOM 2009 gestational weight gain guidance for a BMI category
+type WeightGainTarget struct {
+	BMICategory string  `json:"bmi_category"`
+	TotalMin    float64 `json:"total_min"`     // kg at term
+	TotalMax    float64 `json:"total_max"`     // kg at term
+	WeeklyMin   float64 `json:"weekly_min"`    // kg/week in 2nd and 3rd trimesters
+	WeeklyMax   float64 `json:"weekly_max"`    // kg/week in 2nd and 3rd trimesters
+	FirstTriMin float64 `json:"first_tri_min"` // kg by the end of the 1st trimester
+	FirstTriMax float64 `json:"first_tri_max"` // kg by the end of the 1st trimester
+}
+
+// Catalogue holds reference ranges keyed by metric and gestational week
+type Catalogue struct {
+	entries    []rangeEntry
+	weightGain map[string]WeightGainTarget
+}
+
+// DefaultCatalogue returns the built-in reference ranges
+func DefaultCatalogue() *Catalogue {
+	return &Catalogue{
+		entries:    defaultEntries(),
+		weightGain: defaultWeightGainTargets(),
+	}
+}
+
+// Lookup returns the range for a metric at a point in pregnancy or postpartum
+func (c *Catalogue) Lookup(metric string, ctx GestationContext) (ReferenceRange, bool) {
+	return c.lookup(metric, "", ctx)
+}
+
+// LookupGlucose returns the range for a glucose value taken at a known time.
+// Values without a recorded timing are treated as fasting.
+func (c *Catalogue) LookupGlucose(timing *model.GlucoseTiming, ctx GestationContext) (ReferenceRange, bool) {
+	t := model.GlucoseTimingFasting
+	if timing != nil {
+		t = *timing
+	}
+	return c.lookup(MetricBloodSugar, t, ctx)
+}
+
+// lookup finds the first matching catalogue entry
+func (c *Catalogue) lookup(metric string, timing model.GlucoseTiming, ctx GestationContext) (ReferenceRange, bool) {
+	for _, entry := range c.entries {
+		if entry.metric != metric || entry.timing != timing || entry.postpartum != ctx.Postpartum {
+			continue
+		}
+		if ctx.Weeks >= entry.minWeeks && ctx.Weeks <= entry.maxWeeks {
+			return entry.rng, true
+		}
+	}
+	return ReferenceRange{}, false
+}
+
+// WeightGainTarget returns the weight gain guidance for a BMI category from calculator.CalculateBMI
+func (c *Catalogue) WeightGainTarget(bmiCategory string) (WeightGainTarget, bool) {
+	target, ok := c.weightGain[bmiCategory]
+	return target, ok
+}
+
+// WeightGainRange returns the expected gain since pre-pregnancy weight at a gestational age
+func (c *Catalogue) WeightGainRange(bmiCategory string, ctx GestationContext) (ReferenceRange, bool) {
+	target, ok := c.WeightGainTarget(bmiCategory)
+	if !ok || ctx.Postpartum {
+		return ReferenceRange{}, false
+	}
+
+	rng := ReferenceRange{
+		Metric: MetricWeightGain,
+		Unit:   "kg",
+		Source: "IOM 2009",
+	}
+
+	// Gain in the first trimester is small and loss with nausea is common,
+	// so only excess gain is flagged until 13 weeks
+	if ctx.Weeks <= 13 {
+		rng.High = beyond(target.FirstTriMax)
+		return rng, true
+	}
+
+	weeks := float64(ctx.Weeks - 13)
+	rng.Low = beyond(target.FirstTriMin + weeks*target.WeeklyMin)
+	rng.High = beyond(target.FirstTriMax + weeks*target.WeeklyMax)
+	return rng, true
+}
+
+// defaultEntries builds the catalogue rows
+func defaultEntries() []rangeEntry {
+	systolic := ReferenceRange{
+		Metric:     MetricSystolic,
+		Unit:       "mmHg",
+		Low:        beyond(90),
+		High:       atOrAbove(140),
+		SevereHigh: atOrAbove(160),
+		Source:     "WHO/ACOG hypertensive disorders of pregnancy",
+	}
+	diastolic := ReferenceRange{
+		Metric:     MetricDiastolic,
+		Unit:       "mmHg",
+		Low:        beyond(60),
+		High:       atOrAbove(90),
+		SevereHigh: atOrAbove(110),
+		Source:     "WHO/ACOG hypertensive disorders of pregnancy",
+	}
+
+	return []rangeEntry{
+		// Blood pressure thresholds hold throughout pregnancy and the postpartum period
+		{metric: MetricSystolic, minWeeks: 0, maxWeeks: openWeeks, rng: systolic},
+		{metric: MetricSystolic, postpartum: true, minWeeks: 0, maxWeeks: openWeeks, rng: systolic},
+		{metric: MetricDiastolic, minWeeks: 0, maxWeeks: openWeeks, rng: diastolic},
+		{metric: MetricDiastolic, postpartum: true, minWeeks: 0, maxWeeks: openWeeks, rng: diastolic},
+
+		// Fetal heart rate is faster early in pregnancy and settles by the third trimester
+		{metric: MetricFetalHeartRate, minWeeks: 10, maxWeeks: 13, rng: ReferenceRange{
+			Metric: MetricFetalHeartRate, Unit: "bpm",
+			Low: beyond(120), High: beyond(180), SevereLow: beyond(100),
+		}},
+		{metric: MetricFetalHeartRate, minWeeks: 14, maxWeeks: 27, rng: ReferenceRange{
+			Metric: MetricFetalHeartRate, Unit: "bpm",
+			Low: beyond(110), High: beyond(170), SevereLow: beyond(100),
+		}},
+		{metric: MetricFetalHeartRate, minWeeks: 28, maxWeeks: openWeeks, rng: ReferenceRange{
+			Metric: MetricFetalHeartRate, Unit: "bpm",
+			Low: beyond(110), High: beyond(160), SevereLow: beyond(110),
+			Source: "FIGO intrapartum fetal monitoring",
+		}},
+
+		// Movement counts are only reliable once movements are felt regularly
+		{metric: MetricFetalMovement, minWeeks: 24, maxWeeks: openWeeks, rng: ReferenceRange{
+			Metric: MetricFetalMovement, Unit: "movements per count",
+			Low: beyond(10), SevereLow: beyond(3),
+		}},
+
+		// Hemoglobin dips in the second trimester with plasma expansion
+		{metric: MetricHemoglobin, minWeeks: 0, maxWeeks: 13, rng: ReferenceRange{
+			Metric: MetricHemoglobin, Unit: "g/dL",
+			Low: beyond(11), SevereLow: beyond(7), Source: "WHO/CDC",
+		}},
+		{metric: MetricHemoglobin, minWeeks: 14, maxWeeks: 27, rng: ReferenceRange{
+			Metric: MetricHemoglobin, Unit: "g/dL",
+			Low: beyond(10.5), SevereLow: beyond(7), Source: "CDC",
+		}},
+		{metric: MetricHemoglobin, minWeeks: 28, maxWeeks: openWeeks, rng: ReferenceRange{
+			Metric: MetricHemoglobin, Unit: "g/dL",
+			Low: beyond(11), SevereLow: beyond(7), Source: "WHO/CDC",
+		}},
+		{metric: MetricHemoglobin, postpartum: true, minWeeks: 0, maxWeeks: 6, rng: ReferenceRange{
+			Metric: MetricHemoglobin, Unit: "g/dL",
+			Low: beyond(10), SevereLow: beyond(7),
+		}},
+		{metric: MetricHemoglobin, postpartum: true, minWeeks: 7, maxWeeks: openWeeks, rng: ReferenceRange{
+			Metric: MetricHemoglobin, Unit: "g/dL",
+			Low: beyond(12), SevereLow: beyond(8), Source: "WHO non-pregnant women",
+		}},
+
+		// Gestational diabetes by a 75 g OGTT: any one value at or above the limit.
+		// The severe limits are the thresholds for overt diabetes in pregnancy.
+		{metric: MetricBloodSugar, timing: model.GlucoseTimingFasting, minWeeks: 0, maxWeeks: openWeeks, rng: ReferenceRange{
+			Metric: MetricBloodSugar, Unit: "mg/dL",
+			Low: beyond(60), High: atOrAbove(92), SevereHigh: atOrAbove(126), Source: "IADPSG/WHO 2013",
+		}},
+		{metric: MetricBloodSugar, timing: model.GlucoseTimingOGTT1h, minWeeks: 0, maxWeeks: openWeeks, rng: ReferenceRange{
+			Metric: MetricBloodSugar, Unit: "mg/dL",
+			High: atOrAbove(180), Source: "IADPSG/WHO 2013",
+		}},
+		{metric: MetricBloodSugar, timing: model.GlucoseTimingOGTT2h, minWeeks: 0, maxWeeks: openWeeks, rng: ReferenceRange{
+			Metric: MetricBloodSugar, Unit: "mg/dL",
+			High: atOrAbove(153), SevereHigh: atOrAbove(200), Source: "IADPSG/WHO 2013",
+		}},
+		{metric: MetricBloodSugar, timing: model.GlucoseTimingRandom, minWeeks: 0, maxWeeks: openWeeks, rng: ReferenceRange{
+			Metric: MetricBloodSugar, Unit: "mg/dL",
+			Low: beyond(60), SevereHigh: atOrAbove(200), Source: "WHO 2013",
+		}},
+
+		// After delivery the non-pregnant diabetes criteria apply
+		{metric: MetricBloodSugar, timing: model.GlucoseTimingFasting, postpartum: true, minWeeks: 0, maxWeeks: openWeeks, rng: ReferenceRange{
+			Metric: MetricBloodSugar, Unit: "mg/dL",
+			Low: beyond(60), High: atOrAbove(100), SevereHigh: atOrAbove(126), Source: "WHO/ADA",
+		}},
+		{metric: MetricBloodSugar, timing: model.GlucoseTimingOGTT2h, postpartum: true, minWeeks: 0, maxWeeks: openWeeks, rng: ReferenceRange{
+			Metric: MetricBloodSugar, Unit: "mg/dL",
+			High: atOrAbove(140), SevereHigh: atOrAbove(200), Source: "WHO/ADA",
+		}},
+		{metric: MetricBloodSugar, timing: model.GlucoseTimingRandom, postpartum: true, minWeeks: 0, maxWeeks: openWeeks, rng: ReferenceRange{
+			Metric: MetricBloodSugar, Unit: "mg/dL",
+			Low: beyond(60), SevereHigh: atOrAbove(200), Source: "WHO/ADA",
+		}},
+
+		// Absolute weight floor, used when pre-pregnancy BMI is unknown
+		{metric: MetricWeight, minWeeks: 0, maxWeeks: openWeeks, rng: ReferenceRange{
+			Metric: MetricWeight, Unit: "kg",
+			Low: beyond(45),
+		}},
+	}
+}
+
+// defaultWeightGainTargets are the IOM 2009 targets keyed by calculator BMI category
+func defaultWeightGainTargets() map[string]WeightGainTarget {
+	return map[string]WeightGainTarget{
+		"underweight": {BMICategory: "underweight", TotalMin: 12.5, TotalMax: 18, WeeklyMin: 0.44, WeeklyMax: 0.58, FirstTriMin: 0.5, FirstTriMax: 2},
+		"normal":      {BMICategory: "normal", TotalMin: 11.5, TotalMax: 16, WeeklyMin: 0.35, WeeklyMax: 0.50, FirstTriMin: 0.5, FirstTriMax: 2},
+		"overweight":  {BMICategory: "overweight", TotalMin: 7, TotalMax: 11.5, WeeklyMin: 0.23, WeeklyMax: 0.33, FirstTriMin: 0.5, FirstTriMax: 2},
+		"obese":       {BMICategory: "obese", TotalMin: 5, TotalMax: 9, WeeklyMin: 0.17, WeeklyMax: 0.27, FirstTriMin: 0.5, FirstTriMax: 2},
+	}
+}
@@ -1,67 +1,109 @@
 package metrics
 
 import (
+	"fmt"
 	"sort"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/mamacare/services/internal/app/health/calculator"
 	"github.com/mamacare/services/internal/domain/model"
 	"github.com/mamacare/services/pkg/errorx"
 	"github.com/mamacare/services/pkg/logger"
 )
 
-// ReferenceRange defines normal ranges for a specific vital sign
-type ReferenceRange struct {
-	Min float64
-	Max float64
-}
-
 // MetricAnalysis represents the analysis result of a health metric
 type MetricAnalysis struct {
-	MetricID       uuid.UUID          `json:"metric_id"`
-	MotherID       uuid.UUID          `json:"mother_id"`
-	AnalysisDate   time.Time          `json:"analysis_date"`
-	Abnormalities  map[string]string  `json:"abnormalities,omitempty"`
-	Trends         map[string]string  `json:"trends,omitempty"`
-	RecommendedActions []string       `json:"recommended_actions,omitempty"`
-	SeverityLevel  string             `json:"severity_level,omitempty"`
+	MetricID           uuid.UUID                 `json:"metric_id"`
+	MotherID           uuid.UUID                 `json:"mother_id"`
+	AnalysisDate       time.Time                 `json:"analysis_date"`
+	Gestation          GestationContext          `json:"gestation"`
+	Abnormalities      map[string]string         `json:"abnormalities,omitempty"`
+	Trends             map[string]string         `json:"trends,omitempty"`
+	ReferenceRanges    map[string]ReferenceRange `json:"reference_ranges,omitempty"`
+	RecommendedActions []string                  `json:"recommended_actions,omitempty"`
+	SeverityLevel      string                    `json:"severity_level,omitempty"`
+}
+
+// AnalysisContext is what the analyzers need to know about the mother beyond the metric itself
+type AnalysisContext struct {
+	Gestation GestationContext
+	// HeightCm and PrePregnancyWeight give the pre-pregnancy BMI for weight gain targets
+	HeightCm           *float64
+	PrePregnancyWeight *float64
+}
+
+// ContextForMother builds an analysis context for a mother at a point in time
+func ContextForMother(mother *model.Mother, at time.Time) AnalysisContext {
+	ctx := AnalysisContext{
+		Gestation:          Pregnant(mother.GetWeeksPregnant(at)),
+		HeightCm:           mother.HeightCm,
+		PrePregnancyWeight: mother.PrePregnancyWeight,
+	}
+
+	if mother.IsPostpartum() {
+		weeks := 0
+		if mother.DeliveryDate != nil && at.After(*mother.DeliveryDate) {
+			weeks = int(at.Sub(*mother.DeliveryDate).Hours() / 24 / 7)
+		}
+		ctx.Gestation = PostpartumWeeks(weeks)
+	}
+
+	return ctx
 }
 
 // Service provides health metric analysis functionality
 type Service struct {
-	log logger.Logger
+	catalogue  *Catalogue
+	calculator *calculator.Service
+	log        logger.Logger
 }
 
 // NewService creates a new metrics analysis service
 func NewService(log logger.Logger) *Service {
 	return &Service{
-		log: log,
+		catalogue:  DefaultCatalogue(),
+		calculator: calculator.NewService(log),
+		log:        log,
 	}
 }
 
+// Catalogue returns the reference ranges used by the analyzers
+func (s *Service) Catalogue() *Catalogue {
+	return s.catalogue
+}
+
 // AnalyzeMetric performs comprehensive analysis on a single health metric
 func (s *Service) AnalyzeMetric(metric *model.HealthMetric, gestationalAge int) (*MetricAnalysis, error) {
+	return s.AnalyzeMetricInContext(metric, AnalysisContext{Gestation: Pregnant(gestationalAge)})
+}
+
+// AnalyzeMetricInContext analyzes a single health metric against the reference ranges for the
+// mother's gestational age or postpartum period
+func (s *Service) AnalyzeMetricInContext(metric *model.HealthMetric, ctx AnalysisContext) (*MetricAnalysis, error) {
 	if metric == nil {
 		return nil, errorx.New(errorx.BadRequest, "metric data required for analysis")
 	}
 
 	analysis := &MetricAnalysis{
-		MetricID:      metric.ID,
-		MotherID:      metric.MotherID,
-		AnalysisDate:  time.Now(),
-		Abnormalities: make(map[string]string),
-		Trends:        make(map[string]string),
+		MetricID:           metric.ID,
+		MotherID:           metric.MotherID,
+		AnalysisDate:       time.Now(),
+		Gestation:          ctx.Gestation,
+		Abnormalities:      make(map[string]string),
+		Trends:             make(map[string]string),
+		ReferenceRanges:    make(map[string]ReferenceRange),
 		RecommendedActions: []string{},
-		SeverityLevel: "normal",
+		SeverityLevel:      "normal",
 	}
 
 	// Check vital signs for abnormalities
-	s.analyzeBloodPressure(metric, analysis, gestationalAge)
-	s.analyzeFetalHeartRate(metric, analysis, gestationalAge)
-	s.analyzeFetalMovement(metric, analysis, gestationalAge)
-	s.analyzeBloodSugar(metric, analysis, gestationalAge)
-	s.analyzeHemoglobin(metric, analysis, gestationalAge)
-	s.analyzeWeight(metric, analysis, gestationalAge)
+	s.analyzeBloodPressure(metric, analysis, ctx)
+	s.analyzeFetalHeartRate(metric, analysis, ctx)
+	s.analyzeFetalMovement(metric, analysis, ctx)
+	s.analyzeBloodSugar(metric, analysis, ctx)
+	s.analyzeHemoglobin(metric, analysis, ctx)
+	s.analyzeWeight(metric, analysis, ctx)
 
 	// Determine overall severity level
 	s.determineSeverityLevel(analysis)
@@ -71,6 +113,12 @@ func (s *Service) AnalyzeMetric(metric *model.HealthMetric, gestationalAge int)
 
 // AnalyzeMetricHistory analyzes a series of health metrics to identify trends
 func (s *Service) AnalyzeMetricHistory(metrics []*model.HealthMetric, gestationalAge int) (*MetricAnalysis, error) {
+	return s.AnalyzeMetricHistoryInContext(metrics, AnalysisContext{Gestation: Pregnant(gestationalAge)})
+}
+
+// AnalyzeMetricHistoryInContext analyzes a series of health metrics, checking the latest
+// against the reference ranges for the mother's current gestational age
+func (s *Service) AnalyzeMetricHistoryInContext(metrics []*model.HealthMetric, ctx AnalysisContext) (*MetricAnalysis, error) {
 	if len(metrics) == 0 {
 		return nil, errorx.New(errorx.BadRequest, "metric history required for trend analysis")
 	}
@@ -82,7 +130,7 @@ func (s *Service) AnalyzeMetricHistory(metrics []*model.HealthMetric, gestationa
 
 	// Use the most recent metric as the basis for analysis
 	latestMetric := metrics[0]
-	analysis, err := s.AnalyzeMetric(latestMetric, gestationalAge)
+	analysis, err := s.AnalyzeMetricInContext(latestMetric, ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -96,140 +144,197 @@ func (s *Service) AnalyzeMetricHistory(metrics []*model.HealthMetric, gestationa
 }
 
 // analyzeBloodPressure analyzes blood pressure against reference ranges
-func (s *Service) analyzeBloodPressure(metric *model.HealthMetric, analysis *MetricAnalysis, gestationalAge int) {
+func (s *Service) analyzeBloodPressure(metric *model.HealthMetric, analysis *MetricAnalysis, ctx AnalysisContext) {
 	if metric.VitalSigns.BloodPressure == nil {
 		return
 	}
 
-	bp := metric.VitalSigns.BloodPressure
-	systolic := bp.Systolic
-	diastolic := bp.Diastolic
-
-	// Check for hypertension
-	if systolic >= 140 || diastolic >= 90 {
-		if systolic >= 160 || diastolic >= 110 {
-			analysis.Abnormalities["blood_pressure"] = "severe hypertension"
-			analysis.RecommendedActions = append(analysis.RecommendedActions, 
-				"Seek immediate medical attention for severe high blood pressure")
-		} else {
-			analysis.Abnormalities["blood_pressure"] = "hypertension"
-			analysis.RecommendedActions = append(analysis.RecommendedActions, 
-				"Schedule follow-up appointment to monitor blood pressure")
-		}
+	systolicRange, okSystolic := s.catalogue.Lookup(MetricSystolic, ctx.Gestation)
+	diastolicRange, okDiastolic := s.catalogue.Lookup(MetricDiastolic, ctx.Gestation)
+	if !okSystolic || !okDiastolic {
+		return
 	}
+	analysis.ReferenceRanges[MetricSystolic] = systolicRange
+	analysis.ReferenceRanges[MetricDiastolic] = diastolicRange
 
-	// Check for hypotension
-	if systolic < 90 || diastolic < 60 {
+	bp := metric.VitalSigns.BloodPressure
+	systolic := systolicRange.Classify(bp.Systolic)
+	diastolic := diastolicRange.Classify(bp.Diastolic)
+
+	switch {
+	case systolic == RangeSevereHigh || diastolic == RangeSevereHigh:
+		analysis.Abnormalities["blood_pressure"] = "severe hypertension"
+		analysis.RecommendedActions = append(analysis.RecommendedActions,
+			"Seek immediate medical attention for severe high blood pressure")
+	case systolic == RangeHigh || diastolic == RangeHigh:
+		analysis.Abnormalities["blood_pressure"] = "hypertension"
+		analysis.RecommendedActions = append(analysis.RecommendedActions,
+			"Schedule follow-up appointment to monitor blood pressure")
+	case systolic == RangeLow || diastolic == RangeLow:
 		analysis.Abnormalities["blood_pressure"] = "hypotension"
-		analysis.RecommendedActions = append(analysis.RecommendedActions, 
+		analysis.RecommendedActions = append(analysis.RecommendedActions,
 			"Monitor for dizziness and ensure adequate hydration")
 	}
 }
 
-// analyzeFetalHeartRate analyzes fetal heart rate against reference ranges
-func (s *Service) analyzeFetalHeartRate(metric *model.HealthMetric, analysis *MetricAnalysis, gestationalAge int) {
+// analyzeFetalHeartRate analyzes fetal heart rate against the range for the gestational age
+func (s *Service) analyzeFetalHeartRate(metric *model.HealthMetric, analysis *MetricAnalysis, ctx AnalysisContext) {
 	if metric.VitalSigns.FetalHeartRate == nil {
 		return
 	}
 
-	fhr := *metric.VitalSigns.FetalHeartRate
+	// No range before the heart rate settles, or after delivery
+	rng, ok := s.catalogue.Lookup(MetricFetalHeartRate, ctx.Gestation)
+	if !ok {
+		return
+	}
+	analysis.ReferenceRanges[MetricFetalHeartRate] = rng
 
-	// Normal fetal heart rate: 110-160 bpm
-	if fhr < 110 {
+	switch rng.Classify(*metric.VitalSigns.FetalHeartRate) {
+	case RangeSevereLow:
 		analysis.Abnormalities["fetal_heart_rate"] = "bradycardia"
-		analysis.RecommendedActions = append(analysis.RecommendedActions, 
+		analysis.RecommendedActions = append(analysis.RecommendedActions,
 			"Seek immediate medical attention for low fetal heart rate")
-	} else if fhr > 160 {
+	case RangeLow:
+		analysis.Abnormalities["fetal_heart_rate"] = "low"
+		analysis.RecommendedActions = append(analysis.RecommendedActions,
+			"Recheck fetal heart rate at the next contact")
+	case RangeHigh, RangeSevereHigh:
 		analysis.Abnormalities["fetal_heart_rate"] = "tachycardia"
-		analysis.RecommendedActions = append(analysis.RecommendedActions, 
+		analysis.RecommendedActions = append(analysis.RecommendedActions,
 			"Seek medical attention for high fetal heart rate")
 	}
 }
 
 // analyzeFetalMovement analyzes fetal movement against reference ranges
-func (s *Service) analyzeFetalMovement(metric *model.HealthMetric, analysis *MetricAnalysis, gestationalAge int) {
-	// Skip if no fetal movement data or gestational age < 24 weeks (movements typically felt after 20-24 weeks)
-	if metric.VitalSigns.FetalMovement == nil || gestationalAge < 24 {
+func (s *Service) analyzeFetalMovement(metric *model.HealthMetric, analysis *MetricAnalysis, ctx AnalysisContext) {
+	if metric.VitalSigns.FetalMovement == nil {
 		return
 	}
 
-	movement := *metric.VitalSigns.FetalMovement
-
-	// Normal is at least 10 movements in counting session
-	if movement < 10 {
-		analysis.Abnormalities["fetal_movement"] = "reduced"
-		analysis.RecommendedActions = append(analysis.RecommendedActions, 
-			"Continue monitoring fetal movement; seek medical attention if consistently decreased")
+	// No range until movements are felt regularly
+	rng, ok := s.catalogue.Lookup(MetricFetalMovement, ctx.Gestation)
+	if !ok {
+		return
 	}
-	
-	// Very low movement is a concern
-	if movement < 3 {
+	analysis.ReferenceRanges[MetricFetalMovement] = rng
+
+	switch rng.Classify(*metric.VitalSigns.FetalMovement) {
+	case RangeSevereLow:
 		analysis.Abnormalities["fetal_movement"] = "severely reduced"
-		analysis.RecommendedActions = append(analysis.RecommendedActions, 
+		analysis.RecommendedActions = append(analysis.RecommendedActions,
 			"Seek immediate medical attention for severely reduced fetal movement")
+	case RangeLow:
+		analysis.Abnormalities["fetal_movement"] = "reduced"
+		analysis.RecommendedActions = append(analysis.RecommendedActions,
+			"Continue monitoring fetal movement; seek medical attention if consistently decreased")
 	}
 }
 
-// analyzeBloodSugar analyzes blood sugar against reference ranges
-func (s *Service) analyzeBloodSugar(metric *model.HealthMetric, analysis *MetricAnalysis, gestationalAge int) {
+// analyzeBloodSugar analyzes blood sugar against the criteria for when the sample was taken
+func (s *Service) analyzeBloodSugar(metric *model.HealthMetric, analysis *MetricAnalysis, ctx AnalysisContext) {
 	if metric.VitalSigns.BloodSugar == nil {
 		return
 	}
 
-	bloodSugar := *metric.VitalSigns.BloodSugar
-
-	// Fasting blood sugar thresholds for pregnancy
-	// Different thresholds exist for different testing methods and conditions
-	// Using general guidelines here
-	if bloodSugar > 95 { // Fasting
-		analysis.Abnormalities["blood_sugar"] = "elevated"
-		analysis.RecommendedActions = append(analysis.RecommendedActions, 
-			"Follow up with healthcare provider to discuss blood sugar management")
+	rng, ok := s.catalogue.LookupGlucose(metric.VitalSigns.BloodSugarTiming, ctx.Gestation)
+	if !ok {
+		return
 	}
-	
-	// Very high values require immediate attention
-	if bloodSugar > 180 {
-		analysis.Abnormalities["blood_sugar"] = "severely elevated"
-		analysis.RecommendedActions = append(analysis.RecommendedActions, 
+	analysis.ReferenceRanges[MetricBloodSugar] = rng
+
+	switch rng.Classify(*metric.VitalSigns.BloodSugar) {
+	case RangeSevereHigh:
+		if ctx.Gestation.Postpartum {
+			analysis.Abnormalities["blood_sugar"] = "diabetes"
+		} else {
+			analysis.Abnormalities["blood_sugar"] = "overt diabetes"
+		}
+		analysis.RecommendedActions = append(analysis.RecommendedActions,
 			"Seek medical attention for very high blood sugar")
+	case RangeHigh:
+		if ctx.Gestation.Postpartum {
+			analysis.Abnormalities["blood_sugar"] = "elevated"
+			analysis.RecommendedActions = append(analysis.RecommendedActions,
+				"Repeat glucose testing after the postpartum period")
+		} else {
+			analysis.Abnormalities["blood_sugar"] = "gestational diabetes"
+			analysis.RecommendedActions = append(analysis.RecommendedActions,
+				"Follow up with healthcare provider to discuss blood sugar management")
+		}
+	case RangeLow, RangeSevereLow:
+		analysis.Abnormalities["blood_sugar"] = "low"
+		analysis.RecommendedActions = append(analysis.RecommendedActions,
+			"Eat something sugary now and recheck blood sugar")
 	}
 }
 
-// analyzeHemoglobin analyzes hemoglobin against reference ranges
-func (s *Service) analyzeHemoglobin(metric *model.HealthMetric, analysis *MetricAnalysis, gestationalAge int) {
+// analyzeHemoglobin analyzes hemoglobin against the trimester or postpartum threshold
+func (s *Service) analyzeHemoglobin(metric *model.HealthMetric, analysis *MetricAnalysis, ctx AnalysisContext) {
 	if metric.VitalSigns.HemoglobinLevel == nil {
 		return
 	}
 
-	hemoglobin := *metric.VitalSigns.HemoglobinLevel
+	rng, ok := s.catalogue.Lookup(MetricHemoglobin, ctx.Gestation)
+	if !ok {
+		return
+	}
+	analysis.ReferenceRanges[MetricHemoglobin] = rng
 
-	// Hemoglobin thresholds for anemia in pregnancy
-	if hemoglobin < 11 {
-		if hemoglobin < 7 {
-			analysis.Abnormalities["hemoglobin"] = "severe anemia"
-			analysis.RecommendedActions = append(analysis.RecommendedActions, 
-				"Seek medical attention for severe anemia")
-		} else {
-			analysis.Abnormalities["hemoglobin"] = "anemia"
-			analysis.RecommendedActions = append(analysis.RecommendedActions, 
-				"Discuss iron supplementation with healthcare provider")
-		}
+	switch rng.Classify(*metric.VitalSigns.HemoglobinLevel) {
+	case RangeSevereLow:
+		analysis.Abnormalities["hemoglobin"] = "severe anemia"
+		analysis.RecommendedActions = append(analysis.RecommendedActions,
+			"Seek medical attention for severe anemia")
+	case RangeLow:
+		analysis.Abnormalities["hemoglobin"] = "anemia"
+		analysis.RecommendedActions = append(analysis.RecommendedActions,
+			"Discuss iron supplementation with healthcare provider")
 	}
 }
 
-// analyzeWeight analyzes weight and weight changes
-func (s *Service) analyzeWeight(metric *model.HealthMetric, analysis *MetricAnalysis, gestationalAge int) {
+// analyzeWeight analyzes weight gain against the target for the pre-pregnancy BMI,
+// falling back to an absolute floor when height or pre-pregnancy weight is unknown
+func (s *Service) analyzeWeight(metric *model.HealthMetric, analysis *MetricAnalysis, ctx AnalysisContext) {
 	if metric.VitalSigns.Weight == nil {
 		return
 	}
 
 	weight := *metric.VitalSigns.Weight
 
-	// Simple weight check for now - in a real implementation, this would
-	// consider pre-pregnancy weight, BMI, and expected weight gain by trimester
-	if weight < 45 {
+	if ctx.HeightCm != nil && ctx.PrePregnancyWeight != nil && !ctx.Gestation.Postpartum {
+		bmi, err := s.calculator.CalculateBMI(*ctx.HeightCm, *ctx.PrePregnancyWeight, true)
+		if err == nil {
+			if rng, ok := s.catalogue.WeightGainRange(bmi.Category, ctx.Gestation); ok {
+				analysis.ReferenceRanges[MetricWeightGain] = rng
+
+				gain := weight - *ctx.PrePregnancyWeight
+				switch rng.Classify(gain) {
+				case RangeLow, RangeSevereLow:
+					analysis.Abnormalities["weight"] = "inadequate weight gain"
+					analysis.RecommendedActions = append(analysis.RecommendedActions,
+						fmt.Sprintf("Gained %.1f kg, below the %.1f kg expected for %s BMI; discuss nutrition with healthcare provider",
+							gain, rng.Low.Value, bmi.Category))
+				case RangeHigh, RangeSevereHigh:
+					analysis.Abnormalities["weight"] = "excessive weight gain"
+					analysis.RecommendedActions = append(analysis.RecommendedActions,
+						fmt.Sprintf("Gained %.1f kg, above the %.1f kg expected for %s BMI; discuss diet and activity with healthcare provider",
+							gain, rng.High.Value, bmi.Category))
+				}
+				return
+			}
+		}
+	}
+
+	rng, ok := s.catalogue.Lookup(MetricWeight, ctx.Gestation)
+	if !ok {
+		return
+	}
+	analysis.ReferenceRanges[MetricWeight] = rng
+
+	if rng.Classify(weight) == RangeLow {
 		analysis.Abnormalities["weight"] = "underweight"
-		analysis.RecommendedActions = append(analysis.RecommendedActions, 
+		analysis.RecommendedActions = append(analysis.RecommendedActions,
 			"Discuss nutrition and weight gain with healthcare provider")
 	}
 }
@@ -358,7 +463,10 @@ func (s *Service) determineSeverityLevel(analysis *MetricAnalysis) {
 			if abnormality == "hypertension" || 
 			   abnormality == "reduced" || 
 			   abnormality == "anemia" ||
-			   abnormality == "tachycardia" {
+			   abnormality == "tachycardia" ||
+			   abnormality == "gestational diabetes" ||
+			   abnormality == "overt diabetes" ||
+			   abnormality == "diabetes" {
 				severity = "concerning"
 				break
 			}
@@ -0,0 +1,149 @@
+package nutrition
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mamacare/services/internal/app/health/calculator"
+	"github.com/mamacare/services/internal/app/health/metrics"
+	"github.com/mamacare/services/internal/domain/model"
+)
+
+// WeightPoint is a weight measurement and the gain expected by IOM at that week
+type WeightPoint struct {
+	RecordedAt          time.Time           `json:"recorded_at"`
+	GestationalAgeWeeks int                 `json:"gestational_age_weeks"`
+	Weight              float64             `json:"weight"`
+	Gain                float64             `json:"gain"`
+	ExpectedMin         *float64            `json:"expected_min,omitempty"`
+	ExpectedMax         *float64            `json:"expected_max,omitempty"`
+	Status              metrics.RangeStatus `json:"status"`
+}
+
+// Assessment is a mother's nutrition status: BMI, weight gain week by week against the
+// IOM target for her BMI, MUAC, and any supplementation she is enrolled in
+type Assessment struct {
+	MotherID            uuid.UUID                        `json:"mother_id"`
+	GestationalAgeWeeks *int                             `json:"gestational_age_weeks,omitempty"`
+	BMI                 *calculator.BMIInfo              `json:"bmi,omitempty"`
+	Target              *metrics.WeightGainTarget        `json:"target,omitempty"`
+	WeightGain          []WeightPoint                    `json:"weight_gain"`
+	LatestMUACCm        *float64                         `json:"latest_muac_cm,omitempty"`
+	Flags               []model.NutritionFlag            `json:"flags"`
+	Recommendations     []string                         `json:"recommendations"`
+	Enrollment          *model.SupplementationEnrollment `json:"enrollment,omitempty"`
+	AssessedAt          time.Time                        `json:"assessed_at"`
+}
+
+// HasFlag checks if the assessment raised a flag
+func (a *Assessment) HasFlag(flag model.NutritionFlag) bool {
+	for _, f := range a.Flags {
+		if f == flag {
+			return true
+		}
+	}
+	return false
+}
+
+// UndernutritionFlags returns the flags that supplementation treats
+func (a *Assessment) UndernutritionFlags() []model.NutritionFlag {
+	var flags []model.NutritionFlag
+	for _, f := range a.Flags {
+		if f.IsUndernutrition() {
+			flags = append(flags, f)
+		}
+	}
+	return flags
+}
+
+// assess builds a nutrition assessment from the mother's weights, oldest first, and
+// her latest MUAC. Weight gain is only tracked when height and pre-pregnancy weight
+// are known, since the IOM target depends on pre-pregnancy BMI.
+func (s *Service) assess(mother *model.Mother, weights []model.WeightRecord, latestMUAC *float64, now time.Time) *Assessment {
+	assessment := &Assessment{
+		MotherID:        mother.ID,
+		WeightGain:      []WeightPoint{},
+		LatestMUACCm:    latestMUAC,
+		Flags:           []model.NutritionFlag{},
+		Recommendations: []string{},
+		AssessedAt:      now,
+	}
+
+	if !mother.IsPostpartum() {
+		weeks := mother.GetWeeksPregnant(now)
+		assessment.GestationalAgeWeeks = &weeks
+	}
+
+	if mother.HeightCm != nil && mother.PrePregnancyWeight != nil {
+		if bmi, err := s.calcService.CalculateBMI(*mother.HeightCm, *mother.PrePregnancyWeight, true); err == nil {
+			assessment.BMI = bmi
+			if target, ok := s.catalogue.WeightGainTarget(bmi.Category); ok {
+				assessment.Target = &target
+			}
+			if bmi.BMI < 18.5 {
+				assessment.Flags = append(assessment.Flags, model.NutritionFlagUnderweightBMI)
+			}
+		}
+	} else {
+		assessment.Recommendations = append(assessment.Recommendations,
+			"Record height and pre-pregnancy weight so weight gain can be tracked against her target")
+	}
+
+	if assessment.BMI != nil && !mother.IsPostpartum() {
+		for _, record := range weights {
+			if mother.DeliveryDate != nil && record.Date.After(*mother.DeliveryDate) {
+				continue
+			}
+			weeks := mother.GetWeeksPregnant(record.Date)
+			point := WeightPoint{
+				RecordedAt:          record.Date,
+				GestationalAgeWeeks: weeks,
+				Weight:              record.Weight,
+				Gain:                record.Weight - *mother.PrePregnancyWeight,
+				Status:              metrics.RangeNormal,
+			}
+			if rng, ok := s.catalogue.WeightGainRange(assessment.BMI.Category, metrics.Pregnant(weeks)); ok {
+				if rng.Low != nil {
+					low := rng.Low.Value
+					point.ExpectedMin = &low
+				}
+				if rng.High != nil {
+					high := rng.High.Value
+					point.ExpectedMax = &high
+				}
+				point.Status = rng.Classify(point.Gain)
+			}
+			assessment.WeightGain = append(assessment.WeightGain, point)
+		}
+
+		if n := len(assessment.WeightGain); n > 0 {
+			latest := assessment.WeightGain[n-1]
+			switch latest.Status {
+			case metrics.RangeLow, metrics.RangeSevereLow:
+				assessment.Flags = append(assessment.Flags, model.NutritionFlagInadequateGain)
+				assessment.Recommendations = append(assessment.Recommendations,
+					fmt.Sprintf("Gained %.1f kg by week %d, below the %.1f kg expected; counsel on an extra meal a day",
+						latest.Gain, latest.GestationalAgeWeeks, *latest.ExpectedMin))
+			case metrics.RangeHigh, metrics.RangeSevereHigh:
+				assessment.Flags = append(assessment.Flags, model.NutritionFlagExcessiveGain)
+				assessment.Recommendations = append(assessment.Recommendations,
+					fmt.Sprintf("Gained %.1f kg by week %d, above the %.1f kg expected; counsel on diet and activity and screen for diabetes",
+						latest.Gain, latest.GestationalAgeWeeks, *latest.ExpectedMax))
+			}
+		}
+	}
+
+	if latestMUAC != nil && *latestMUAC < model.MUACUndernutritionCm {
+		assessment.Flags = append(assessment.Flags, model.NutritionFlagLowMUAC)
+		assessment.Recommendations = append(assessment.Recommendations,
+			fmt.Sprintf("MUAC %.1f cm is below %.0f cm; she is acutely malnourished", *latestMUAC, model.MUACUndernutritionCm))
+	}
+
+	if len(assessment.UndernutritionFlags()) > 0 {
+		assessment.Recommendations = append(assessment.Recommendations,
+			"Enroll in balanced energy protein supplementation and review weight and MUAC at every visit")
+	}
+
+	return assessment
+}
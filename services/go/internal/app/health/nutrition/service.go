@@ -0,0 +1,442 @@
+package nutrition
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mamacare/services/internal/app/health/calculator"
+	"github.com/mamacare/services/internal/app/health/metrics"
+	"github.com/mamacare/services/internal/domain/model"
+	"github.com/mamacare/services/internal/domain/repository"
+	"github.com/mamacare/services/pkg/errorx"
+	"github.com/mamacare/services/pkg/logger"
+)
+
+// weightHistoryMonths covers a whole pregnancy when loading weight records
+const weightHistoryMonths = 10
+
+// MeasurementInput is a weight or MUAC taken at a visit
+type MeasurementInput struct {
+	WeightKg     *float64
+	MUACCm       *float64
+	RecordedAt   time.Time
+	VisitID      *uuid.UUID
+	FacilityID   *uuid.UUID // where she would be enrolled if flagged
+	RecordedByID *uuid.UUID
+}
+
+// CounsellingInput is a dietary counselling session
+type CounsellingInput struct {
+	Topics         []model.CounsellingTopic
+	Notes          string
+	VisitID        *uuid.UUID
+	CounselledByID *uuid.UUID
+	CounselledAt   time.Time
+}
+
+// DispenseInput is supplements handed to an enrolled mother at a visit
+type DispenseInput struct {
+	EnrollmentID  uuid.UUID
+	FacilityID    uuid.UUID
+	Item          model.SupplementItem
+	Quantity      int
+	VisitID       *uuid.UUID
+	DispensedByID *uuid.UUID
+}
+
+// DispenseResult is the recorded dispensing and the stock left at the facility
+type DispenseResult struct {
+	Dispensing *model.SupplementDispensing `json:"dispensing"`
+	Stock      *model.SupplementStock      `json:"stock"`
+}
+
+// Service tracks maternal nutrition, counselling and supplementation
+type Service struct {
+	calcService      *calculator.Service
+	catalogue        *metrics.Catalogue
+	motherRepo       repository.MotherRepository
+	healthMetricRepo repository.HealthMetricRepository
+	nutritionRepo    repository.NutritionRepository
+	log              logger.Logger
+}
+
+// NewService creates a new nutrition service
+func NewService(
+	calcService *calculator.Service,
+	catalogue *metrics.Catalogue,
+	motherRepo repository.MotherRepository,
+	healthMetricRepo repository.HealthMetricRepository,
+	nutritionRepo repository.NutritionRepository,
+	log logger.Logger,
+) *Service {
+	return &Service{
+		calcService:      calcService,
+		catalogue:        catalogue,
+		motherRepo:       motherRepo,
+		healthMetricRepo: healthMetricRepo,
+		nutritionRepo:    nutritionRepo,
+		log:              log,
+	}
+}
+
+// RecordBodyMeasurements stores a mother's height and pre-pregnancy weight, which set her
+// BMI category and weight gain target
+func (s *Service) RecordBodyMeasurements(ctx context.Context, motherID uuid.UUID, heightCm, prePregnancyWeight float64) (*Assessment, error) {
+	if heightCm < 100 || heightCm > 220 {
+		return nil, errorx.New(errorx.BadRequest, "height must be between 100 and 220 cm")
+	}
+	if prePregnancyWeight < 25 || prePregnancyWeight > 250 {
+		return nil, errorx.New(errorx.BadRequest, "pre-pregnancy weight must be between 25 and 250 kg")
+	}
+
+	mother, err := s.motherRepo.GetByID(ctx, motherID)
+	if err != nil {
+		s.log.Error("Failed to find mother", logger.Fields{
+			"error":     err.Error(),
+			"mother_id": motherID.String(),
+		})
+		return nil, errorx.Wrap(err, "failed to find mother")
+	}
+
+	mother.WithBodyMeasurements(heightCm, prePregnancyWeight)
+	mother.UpdatedAt = time.Now()
+
+	if err := s.motherRepo.Save(ctx, mother); err != nil {
+		s.log.Error("Failed to save body measurements", logger.Fields{
+			"error":     err.Error(),
+			"mother_id": motherID.String(),
+		})
+		return nil, errorx.Wrap(err, "failed to save body measurements")
+	}
+
+	return s.EvaluateMother(ctx, motherID, nil)
+}
+
+// RecordMeasurement stores a weight or MUAC as a health metric and re-evaluates the
+// mother's nutrition, enrolling her in supplementation if she is now undernourished
+func (s *Service) RecordMeasurement(ctx context.Context, motherID uuid.UUID, input *MeasurementInput) (*Assessment, error) {
+	if input.WeightKg == nil && input.MUACCm == nil {
+		return nil, errorx.New(errorx.BadRequest, "weight or MUAC is required")
+	}
+	if input.WeightKg != nil && (*input.WeightKg < 25 || *input.WeightKg > 250) {
+		return nil, errorx.New(errorx.BadRequest, "weight must be between 25 and 250 kg")
+	}
+	if input.MUACCm != nil && (*input.MUACCm < 10 || *input.MUACCm > 60) {
+		return nil, errorx.New(errorx.BadRequest, "MUAC must be between 10 and 60 cm")
+	}
+	if input.RecordedAt.IsZero() {
+		input.RecordedAt = time.Now()
+	}
+	if input.RecordedAt.After(time.Now()) {
+		return nil, errorx.New(errorx.BadRequest, "measurement date cannot be in the future")
+	}
+
+	metric := model.NewHealthMetric(uuid.New(), motherID)
+	metric.RecordedAt = input.RecordedAt
+	if input.VisitID != nil {
+		metric.WithVisit(*input.VisitID)
+	}
+	if input.RecordedByID != nil {
+		metric.WithRecordedBy(*input.RecordedByID)
+	}
+	if input.WeightKg != nil {
+		metric.WithWeight(*input.WeightKg)
+	}
+	if input.MUACCm != nil {
+		metric.WithMUAC(*input.MUACCm)
+	}
+
+	if err := s.healthMetricRepo.Save(ctx, metric); err != nil {
+		s.log.Error("Failed to save nutrition measurement", logger.Fields{
+			"error":     err.Error(),
+			"mother_id": motherID.String(),
+		})
+		return nil, errorx.Wrap(err, "failed to save nutrition measurement")
+	}
+
+	return s.EvaluateMother(ctx, motherID, input.FacilityID)
+}
+
+// EvaluateMother assesses a mother's nutrition and enrolls her in balanced energy protein
+// supplementation when she is undernourished and not already enrolled. Excessive gain is
+// flagged for counselling only.
+func (s *Service) EvaluateMother(ctx context.Context, motherID uuid.UUID, facilityID *uuid.UUID) (*Assessment, error) {
+	mother, err := s.motherRepo.GetByID(ctx, motherID)
+	if err != nil {
+		s.log.Error("Failed to find mother", logger.Fields{
+			"error":     err.Error(),
+			"mother_id": motherID.String(),
+		})
+		return nil, errorx.Wrap(err, "failed to find mother")
+	}
+
+	weights, err := s.healthMetricRepo.GetWeightTrend(ctx, motherID, weightHistoryMonths)
+	if err != nil {
+		s.log.Error("Failed to get weight trend", logger.Fields{
+			"error":     err.Error(),
+			"mother_id": motherID.String(),
+		})
+		return nil, errorx.Wrap(err, "failed to get weight trend")
+	}
+
+	healthMetrics, err := s.healthMetricRepo.FindByMother(ctx, motherID)
+	if err != nil {
+		s.log.Error("Failed to get health metrics", logger.Fields{
+			"error":     err.Error(),
+			"mother_id": motherID.String(),
+		})
+		return nil, errorx.Wrap(err, "failed to get health metrics")
+	}
+
+	assessment := s.assess(mother, weights, latestMUAC(healthMetrics), time.Now())
+
+	enrollment, err := s.nutritionRepo.GetActiveEnrollment(ctx, motherID)
+	if err != nil {
+		if !errorx.IsType(err, errorx.NotFound) {
+			s.log.Error("Failed to get supplementation enrollment", logger.Fields{
+				"error":     err.Error(),
+				"mother_id": motherID.String(),
+			})
+			return nil, errorx.Wrap(err, "failed to get supplementation enrollment")
+		}
+		enrollment = nil
+	}
+
+	reasons := assessment.UndernutritionFlags()
+	if enrollment == nil && len(reasons) > 0 && !mother.IsPostpartum() {
+		enrollment = model.NewSupplementationEnrollment(motherID, model.SupplementProgrammeBEP, reasons)
+		enrollment.FacilityID = facilityID
+
+		if err := s.nutritionRepo.SaveEnrollment(ctx, enrollment); err != nil {
+			s.log.Error("Failed to enroll mother in supplementation", logger.Fields{
+				"error":     err.Error(),
+				"mother_id": motherID.String(),
+			})
+			return nil, errorx.Wrap(err, "failed to enroll mother in supplementation")
+		}
+
+		s.log.Info("Mother enrolled in supplementation", logger.Fields{
+			"mother_id":     motherID.String(),
+			"enrollment_id": enrollment.ID.String(),
+			"reasons":       len(reasons),
+		})
+	}
+	assessment.Enrollment = enrollment
+
+	return assessment, nil
+}
+
+// RecordCounselling stores a dietary counselling session
+func (s *Service) RecordCounselling(ctx context.Context, motherID uuid.UUID, input *CounsellingInput) (*model.NutritionCounselling, error) {
+	if len(input.Topics) == 0 {
+		return nil, errorx.New(errorx.BadRequest, "at least one counselling topic is required")
+	}
+	for _, topic := range input.Topics {
+		if !topic.IsValid() {
+			return nil, errorx.Newf(errorx.BadRequest, "unknown counselling topic: %s", topic)
+		}
+	}
+	if input.CounselledAt.IsZero() {
+		input.CounselledAt = time.Now()
+	}
+
+	if _, err := s.motherRepo.GetByID(ctx, motherID); err != nil {
+		s.log.Error("Failed to find mother", logger.Fields{
+			"error":     err.Error(),
+			"mother_id": motherID.String(),
+		})
+		return nil, errorx.Wrap(err, "failed to find mother")
+	}
+
+	counselling := &model.NutritionCounselling{
+		ID:             uuid.New(),
+		MotherID:       motherID,
+		VisitID:        input.VisitID,
+		Topics:         input.Topics,
+		Notes:          input.Notes,
+		CounselledByID: input.CounselledByID,
+		CounselledAt:   input.CounselledAt,
+		CreatedAt:      time.Now(),
+	}
+
+	if err := s.nutritionRepo.CreateCounselling(ctx, counselling); err != nil {
+		s.log.Error("Failed to record nutrition counselling", logger.Fields{
+			"error":     err.Error(),
+			"mother_id": motherID.String(),
+		})
+		return nil, errorx.Wrap(err, "failed to record nutrition counselling")
+	}
+
+	return counselling, nil
+}
+
+// GetCounsellingHistory retrieves a mother's counselling sessions, newest first
+func (s *Service) GetCounsellingHistory(ctx context.Context, motherID uuid.UUID) ([]*model.NutritionCounselling, error) {
+	sessions, err := s.nutritionRepo.GetCounsellingByMotherID(ctx, motherID)
+	if err != nil {
+		s.log.Error("Failed to get nutrition counselling", logger.Fields{
+			"error":     err.Error(),
+			"mother_id": motherID.String(),
+		})
+		return nil, errorx.Wrap(err, "failed to get nutrition counselling")
+	}
+	return sessions, nil
+}
+
+// DispenseSupplement records supplements handed to an enrolled mother and takes them
+// off the facility's stock
+func (s *Service) DispenseSupplement(ctx context.Context, input *DispenseInput) (*DispenseResult, error) {
+	if !input.Item.IsValid() {
+		return nil, errorx.Newf(errorx.BadRequest, "unknown supplement: %s", input.Item)
+	}
+	if input.Quantity <= 0 {
+		return nil, errorx.New(errorx.BadRequest, "quantity must be positive")
+	}
+
+	enrollment, err := s.nutritionRepo.GetEnrollmentByID(ctx, input.EnrollmentID)
+	if err != nil {
+		s.log.Error("Failed to find supplementation enrollment", logger.Fields{
+			"error":         err.Error(),
+			"enrollment_id": input.EnrollmentID.String(),
+		})
+		return nil, errorx.Wrap(err, "failed to find supplementation enrollment")
+	}
+	if !enrollment.IsActive() {
+		return nil, errorx.New(errorx.BadRequest, "supplementation enrollment is not active")
+	}
+
+	now := time.Now()
+	dispensing := &model.SupplementDispensing{
+		ID:            uuid.New(),
+		EnrollmentID:  enrollment.ID,
+		MotherID:      enrollment.MotherID,
+		VisitID:       input.VisitID,
+		FacilityID:    input.FacilityID,
+		Item:          input.Item,
+		Quantity:      input.Quantity,
+		DispensedByID: input.DispensedByID,
+		DispensedAt:   now,
+		CreatedAt:     now,
+	}
+
+	stock, err := s.nutritionRepo.Dispense(ctx, dispensing)
+	if err != nil {
+		s.log.Error("Failed to dispense supplement", logger.Fields{
+			"error":         err.Error(),
+			"enrollment_id": enrollment.ID.String(),
+			"facility_id":   input.FacilityID.String(),
+			"item":          string(input.Item),
+		})
+		return nil, errorx.Wrap(err, "failed to dispense supplement")
+	}
+
+	s.log.Info("Supplement dispensed", logger.Fields{
+		"mother_id":   enrollment.MotherID.String(),
+		"facility_id": input.FacilityID.String(),
+		"item":        string(input.Item),
+		"quantity":    input.Quantity,
+		"remaining":   stock.QuantityOnHand,
+	})
+
+	return &DispenseResult{
+		Dispensing: dispensing,
+		Stock:      stock,
+	}, nil
+}
+
+// EndEnrollment closes a mother's supplementation enrollment as completed or withdrawn
+func (s *Service) EndEnrollment(ctx context.Context, enrollmentID uuid.UUID, status model.EnrollmentStatus, reason string) (*model.SupplementationEnrollment, error) {
+	if status != model.EnrollmentStatusCompleted && status != model.EnrollmentStatusWithdrawn {
+		return nil, errorx.New(errorx.BadRequest, "status must be completed or withdrawn")
+	}
+
+	enrollment, err := s.nutritionRepo.GetEnrollmentByID(ctx, enrollmentID)
+	if err != nil {
+		s.log.Error("Failed to find supplementation enrollment", logger.Fields{
+			"error":         err.Error(),
+			"enrollment_id": enrollmentID.String(),
+		})
+		return nil, errorx.Wrap(err, "failed to find supplementation enrollment")
+	}
+	if !enrollment.IsActive() {
+		return nil, errorx.New(errorx.BadRequest, "supplementation enrollment has already ended")
+	}
+
+	enrollment.End(status, reason)
+
+	if err := s.nutritionRepo.SaveEnrollment(ctx, enrollment); err != nil {
+		s.log.Error("Failed to end supplementation enrollment", logger.Fields{
+			"error":         err.Error(),
+			"enrollment_id": enrollmentID.String(),
+		})
+		return nil, errorx.Wrap(err, "failed to end supplementation enrollment")
+	}
+
+	return enrollment, nil
+}
+
+// GetEnrollmentDispensings retrieves the supplements dispensed for an enrollment
+func (s *Service) GetEnrollmentDispensings(ctx context.Context, enrollmentID uuid.UUID) ([]*model.SupplementDispensing, error) {
+	dispensings, err := s.nutritionRepo.GetDispensingsByEnrollment(ctx, enrollmentID)
+	if err != nil {
+		s.log.Error("Failed to get supplement dispensings", logger.Fields{
+			"error":         err.Error(),
+			"enrollment_id": enrollmentID.String(),
+		})
+		return nil, errorx.Wrap(err, "failed to get supplement dispensings")
+	}
+	return dispensings, nil
+}
+
+// ReceiveStock adds a delivery of supplements to a facility's stock
+func (s *Service) ReceiveStock(ctx context.Context, facilityID uuid.UUID, item model.SupplementItem, quantity int) (*model.SupplementStock, error) {
+	if !item.IsValid() {
+		return nil, errorx.Newf(errorx.BadRequest, "unknown supplement: %s", item)
+	}
+	if quantity <= 0 {
+		return nil, errorx.New(errorx.BadRequest, "quantity must be positive")
+	}
+
+	stock, err := s.nutritionRepo.AddStock(ctx, facilityID, item, quantity)
+	if err != nil {
+		s.log.Error("Failed to add supplement stock", logger.Fields{
+			"error":       err.Error(),
+			"facility_id": facilityID.String(),
+			"item":        string(item),
+		})
+		return nil, errorx.Wrap(err, "failed to add supplement stock")
+	}
+
+	return stock, nil
+}
+
+// GetFacilityStock retrieves a facility's supplement stock
+func (s *Service) GetFacilityStock(ctx context.Context, facilityID uuid.UUID) ([]*model.SupplementStock, error) {
+	stocks, err := s.nutritionRepo.GetStockByFacility(ctx, facilityID)
+	if err != nil {
+		s.log.Error("Failed to get supplement stock", logger.Fields{
+			"error":       err.Error(),
+			"facility_id": facilityID.String(),
+		})
+		return nil, errorx.Wrap(err, "failed to get supplement stock")
+	}
+	return stocks, nil
+}
+
+// latestMUAC returns the most recently recorded MUAC, if any
+func latestMUAC(healthMetrics []*model.HealthMetric) *float64 {
+	var latest *model.HealthMetric
+	for _, metric := range healthMetrics {
+		if metric.VitalSigns.MUACCm == nil {
+			continue
+		}
+		if latest == nil || metric.RecordedAt.After(latest.RecordedAt) {
+			latest = metric
+		}
+	}
+	if latest == nil {
+		return nil
+	}
+	return latest.VitalSigns.MUACCm
+}
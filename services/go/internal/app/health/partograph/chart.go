@@ -0,0 +1,82 @@
+package partograph
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mamacare/services/internal/domain/model"
+)
+
+// ChartPoint is a point on the partograph chart
+type ChartPoint struct {
+	Time  time.Time `json:"time"`
+	Hours float64   `json:"hours"` // hours since admission
+	Value float64   `json:"value"`
+}
+
+// Chart is the data needed to draw a partograph
+type Chart struct {
+	PartographID   uuid.UUID               `json:"partograph_id"`
+	AdmittedAt     time.Time               `json:"admitted_at"`
+	ActivePhaseAt  *time.Time              `json:"active_phase_at,omitempty"`
+	Status         model.PartographStatus  `json:"status"`
+	Progress       model.LabourProgress    `json:"progress"`
+	AlertLine      []ChartPoint            `json:"alert_line"`
+	ActionLine     []ChartPoint            `json:"action_line"`
+	Dilation       []ChartPoint            `json:"dilation"`
+	Descent        []ChartPoint            `json:"descent"`
+	FetalHeartRate []ChartPoint            `json:"fetal_heart_rate"`
+	Alerts         []model.PartographAlert `json:"alerts"`
+}
+
+// BuildChart plots a partograph's observations with its alert and action lines
+func BuildChart(partograph *model.Partograph) *Chart {
+	chart := &Chart{
+		PartographID:   partograph.ID,
+		AdmittedAt:     partograph.AdmittedAt,
+		ActivePhaseAt:  partograph.ActivePhaseAt,
+		Status:         partograph.Status,
+		Progress:       model.LabourProgressLatent,
+		AlertLine:      []ChartPoint{},
+		ActionLine:     []ChartPoint{},
+		Dilation:       []ChartPoint{},
+		Descent:        []ChartPoint{},
+		FetalHeartRate: []ChartPoint{},
+		Alerts:         partograph.Alerts,
+	}
+
+	point := func(at time.Time, value float64) ChartPoint {
+		return ChartPoint{Time: at, Hours: at.Sub(partograph.AdmittedAt).Hours(), Value: value}
+	}
+
+	for _, observation := range partograph.Observations {
+		if observation.CervicalDilationCm != nil {
+			chart.Dilation = append(chart.Dilation, point(observation.RecordedAt, *observation.CervicalDilationCm))
+		}
+		if observation.DescentFifths != nil {
+			chart.Descent = append(chart.Descent, point(observation.RecordedAt, float64(*observation.DescentFifths)))
+		}
+		if observation.FetalHeartRate != nil {
+			chart.FetalHeartRate = append(chart.FetalHeartRate, point(observation.RecordedAt, *observation.FetalHeartRate))
+		}
+	}
+
+	if dilation, at, ok := partograph.LatestDilation(); ok {
+		chart.Progress = partograph.ProgressAt(dilation, at)
+	}
+
+	// Both lines are straight, so their end points are enough to draw them
+	if start := partograph.ActivePhaseAt; start != nil {
+		hoursToFull := (model.FullDilationCm - model.ActivePhaseDilationCm) / model.AlertLineRateCmPerHour
+		alertEnd := start.Add(time.Duration(hoursToFull * float64(time.Hour)))
+
+		chart.AlertLine = append(chart.AlertLine,
+			point(*start, model.ActivePhaseDilationCm),
+			point(alertEnd, model.FullDilationCm))
+		chart.ActionLine = append(chart.ActionLine,
+			point(start.Add(model.ActionLineOffset), model.ActivePhaseDilationCm),
+			point(alertEnd.Add(model.ActionLineOffset), model.FullDilationCm))
+	}
+
+	return chart
+}
@@ -0,0 +1,648 @@
+package partograph
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mamacare/services/internal/app/geo/location"
+	"github.com/mamacare/services/internal/domain/model"
+	"github.com/mamacare/services/internal/domain/repository"
+	"github.com/mamacare/services/pkg/errorx"
+	"github.com/mamacare/services/pkg/logger"
+)
+
+// Alert codes raised from the partograph
+const (
+	AlertCodeAlertLine         = "alert_line_crossed"
+	AlertCodeActionLine        = "action_line_crossed"
+	AlertCodeProlongedLabour   = "prolonged_labour"
+	AlertCodeFetalHeartRate    = "fetal_heart_rate"
+	AlertCodeMeconium          = "meconium_liquor"
+	AlertCodeBloodLiquor       = "blood_stained_liquor"
+	AlertCodeBloodPressure     = "maternal_blood_pressure"
+	AlertCodePulse             = "maternal_pulse"
+	AlertCodeFever             = "maternal_fever"
+	AlertCodeExcessContraction = "excessive_contractions"
+)
+
+// maxActivePhase is how long the active phase can last before labour is prolonged
+const maxActivePhase = 12 * time.Hour
+
+// ReferralService defines the interface to the SOS flow used to refer labour to a hospital
+type ReferralService interface {
+	// ReportSOSEvent reports a new SOS event
+	ReportSOSEvent(
+		ctx context.Context,
+		motherID uuid.UUID,
+		reportedByID uuid.UUID,
+		lat, lng float64,
+		nature model.SOSEventNature,
+		description string,
+	) (*model.SOSEvent, error)
+
+	// AssignFacilityToSOSEvent assigns the receiving facility to an SOS event
+	AssignFacilityToSOSEvent(ctx context.Context, sosID, facilityID uuid.UUID) (*model.SOSEvent, error)
+}
+
+// NotificationService defines the interface for sending partograph alerts
+type NotificationService interface {
+	// SendPartographAlert tells the birth attendant about a partograph alert
+	SendPartographAlert(ctx context.Context, recipientID uuid.UUID, partograph *model.Partograph, alert model.PartographAlert) error
+}
+
+// ObservationInput is one set of labour observations
+type ObservationInput struct {
+	RecordedAt         time.Time
+	RecordedByID       uuid.UUID
+	CervicalDilationCm *float64
+	DescentFifths      *int
+	Contractions       *model.ContractionReading
+	FetalHeartRate     *float64
+	Liquor             *model.LiquorStatus
+	BloodPressure      *model.BloodPressure
+	PulseRate          *float64
+	TemperatureC       *float64
+	Notes              string
+}
+
+// ObservationResult is the partograph after an observation with anything it triggered
+type ObservationResult struct {
+	Partograph *model.Partograph       `json:"partograph"`
+	Progress   model.LabourProgress    `json:"progress"`
+	NewAlerts  []model.PartographAlert `json:"new_alerts"`
+	Referral   *model.SOSEvent         `json:"referral,omitempty"`
+	Chart      *Chart                  `json:"chart"`
+}
+
+// Service provides digital partograph functionality for facility deliveries
+type Service struct {
+	partographRepo   repository.PartographRepository
+	motherRepo       repository.MotherRepository
+	facilityRepo     repository.FacilityRepository
+	healthMetricRepo repository.HealthMetricRepository
+	referralService  ReferralService
+	notifyService    NotificationService
+	locationService  *location.Service
+	transactor       repository.Transactor
+	log              logger.Logger
+}
+
+// NewService creates a new partograph service
+func NewService(
+	partographRepo repository.PartographRepository,
+	motherRepo repository.MotherRepository,
+	facilityRepo repository.FacilityRepository,
+	healthMetricRepo repository.HealthMetricRepository,
+	referralService ReferralService,
+	notifyService NotificationService,
+	locationService *location.Service,
+	transactor repository.Transactor,
+	log logger.Logger,
+) *Service {
+	return &Service{
+		partographRepo:   partographRepo,
+		motherRepo:       motherRepo,
+		facilityRepo:     facilityRepo,
+		healthMetricRepo: healthMetricRepo,
+		referralService:  referralService,
+		notifyService:    notifyService,
+		locationService:  locationService,
+		transactor:       transactor,
+		log:              log,
+	}
+}
+
+// OpenPartograph starts labour monitoring for a mother admitted to a facility
+func (s *Service) OpenPartograph(
+	ctx context.Context,
+	motherID, facilityID, attendantID uuid.UUID,
+	admittedAt time.Time,
+) (*model.Partograph, error) {
+	if _, err := s.motherRepo.GetByID(ctx, motherID); err != nil {
+		s.log.Error("Failed to find mother", logger.Fields{
+			"error":     err.Error(),
+			"mother_id": motherID.String(),
+		})
+		return nil, errorx.Wrap(err, "failed to find mother")
+	}
+
+	if _, err := s.facilityRepo.GetByID(ctx, facilityID); err != nil {
+		s.log.Error("Failed to find facility", logger.Fields{
+			"error":       err.Error(),
+			"facility_id": facilityID.String(),
+		})
+		return nil, errorx.Wrap(err, "failed to find facility")
+	}
+
+	existing, err := s.partographRepo.GetActiveByMotherID(ctx, motherID)
+	if err == nil && existing != nil {
+		return nil, errorx.New(errorx.AlreadyExists, "mother already has an active partograph")
+	}
+	if err != nil && !errorx.IsType(err, errorx.NotFound) {
+		s.log.Error("Failed to check for an active partograph", logger.Fields{
+			"error":     err.Error(),
+			"mother_id": motherID.String(),
+		})
+		return nil, errorx.Wrap(err, "failed to check for an active partograph")
+	}
+
+	if admittedAt.After(time.Now()) {
+		return nil, errorx.New(errorx.BadRequest, "admission time cannot be in the future")
+	}
+
+	partograph := model.NewPartograph(uuid.New(), motherID, facilityID, attendantID, admittedAt)
+	if err := s.partographRepo.Create(ctx, partograph); err != nil {
+		s.log.Error("Failed to create partograph", logger.Fields{
+			"error":     err.Error(),
+			"mother_id": motherID.String(),
+		})
+		return nil, errorx.Wrap(err, "failed to create partograph")
+	}
+
+	s.log.Info("Partograph opened", logger.Fields{
+		"partograph_id": partograph.ID.String(),
+		"mother_id":     motherID.String(),
+		"facility_id":   facilityID.String(),
+	})
+
+	return partograph, nil
+}
+
+// RecordObservation plots a set of observations, raises alerts and refers the mother
+// to a hospital through the SOS flow if labour at a PHU crosses the action line. The
+// observation is saved in one transaction; alerts and referral follow the commit.
+func (s *Service) RecordObservation(ctx context.Context, partographID uuid.UUID, input *ObservationInput) (*ObservationResult, error) {
+	var result *ObservationResult
+	err := s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		result, err = s.recordObservation(ctx, partographID, input)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	partograph, newAlerts := result.Partograph, result.NewAlerts
+	s.notifyAlerts(ctx, partograph, newAlerts)
+
+	if reason, refer := referralReason(newAlerts); refer {
+		facility, err := s.facilityRepo.GetByID(ctx, partograph.FacilityID)
+		if err != nil {
+			s.log.Warn("Failed to get facility for referral check", logger.Fields{
+				"error":       err.Error(),
+				"facility_id": partograph.FacilityID.String(),
+			})
+		} else if facility.FacilityType != model.FacilityTypeHospital {
+			sosEvent, err := s.refer(ctx, partograph, facility, input.RecordedByID, reason)
+			if err != nil {
+				// The alerts are saved and the attendant notified, so the observation still stands
+				s.log.Error("Failed to refer labour from partograph", logger.Fields{
+					"error":         err.Error(),
+					"partograph_id": partographID.String(),
+				})
+			} else {
+				result.Referral = sosEvent
+			}
+		}
+	}
+
+	result.Chart = BuildChart(partograph)
+
+	s.log.Info("Partograph observation recorded", logger.Fields{
+		"partograph_id": partographID.String(),
+		"progress":      string(result.Progress),
+		"new_alerts":    len(newAlerts),
+		"referred":      result.Referral != nil,
+	})
+
+	return result, nil
+}
+
+// recordObservation saves an observation within a transaction. The partograph is locked
+// so concurrent observations cannot overwrite each other.
+func (s *Service) recordObservation(ctx context.Context, partographID uuid.UUID, input *ObservationInput) (*ObservationResult, error) {
+	partograph, err := s.partographRepo.GetByIDForUpdate(ctx, partographID)
+	if err != nil {
+		s.log.Error("Failed to get partograph", logger.Fields{
+			"error":         err.Error(),
+			"partograph_id": partographID.String(),
+		})
+		return nil, errorx.Wrap(err, "failed to get partograph")
+	}
+
+	if !partograph.IsActive() {
+		return nil, errorx.Newf(errorx.BadRequest, "partograph is %s", partograph.Status)
+	}
+	if err := validateObservation(partograph, input); err != nil {
+		return nil, err
+	}
+
+	observation := model.PartographObservation{
+		ID:                 uuid.New(),
+		RecordedAt:         input.RecordedAt,
+		RecordedByID:       input.RecordedByID,
+		CervicalDilationCm: input.CervicalDilationCm,
+		DescentFifths:      input.DescentFifths,
+		Contractions:       input.Contractions,
+		FetalHeartRate:     input.FetalHeartRate,
+		Liquor:             input.Liquor,
+		BloodPressure:      input.BloodPressure,
+		PulseRate:          input.PulseRate,
+		TemperatureC:       input.TemperatureC,
+		Notes:              input.Notes,
+	}
+
+	// Keep contractions, FHR and BP in the mother's health metrics as well
+	if metricID, err := s.saveHealthMetric(ctx, partograph.MotherID, &observation); err != nil {
+		return nil, err
+	} else if metricID != nil {
+		observation.HealthMetricID = metricID
+	}
+
+	partograph.AddObservation(observation)
+
+	progress := model.LabourProgressLatent
+	if observation.CervicalDilationCm != nil {
+		progress = partograph.ProgressAt(*observation.CervicalDilationCm, observation.RecordedAt)
+	}
+
+	newAlerts := evaluateObservation(partograph, &observation, progress)
+	for _, alert := range newAlerts {
+		partograph.RaiseAlert(alert)
+	}
+
+	if err := s.partographRepo.Update(ctx, partograph); err != nil {
+		s.log.Error("Failed to update partograph", logger.Fields{
+			"error":         err.Error(),
+			"partograph_id": partographID.String(),
+		})
+		return nil, errorx.Wrap(err, "failed to update partograph")
+	}
+
+	return &ObservationResult{
+		Partograph: partograph,
+		Progress:   progress,
+		NewAlerts:  newAlerts,
+	}, nil
+}
+
+// Refer refers labour to a hospital through the SOS flow
+func (s *Service) Refer(ctx context.Context, partographID, reportedByID uuid.UUID, reason string) (*model.SOSEvent, error) {
+	partograph, err := s.GetPartograph(ctx, partographID)
+	if err != nil {
+		return nil, err
+	}
+	if !partograph.IsActive() {
+		return nil, errorx.Newf(errorx.BadRequest, "partograph is %s", partograph.Status)
+	}
+
+	facility, err := s.facilityRepo.GetByID(ctx, partograph.FacilityID)
+	if err != nil {
+		s.log.Error("Failed to find facility", logger.Fields{
+			"error":       err.Error(),
+			"facility_id": partograph.FacilityID.String(),
+		})
+		return nil, errorx.Wrap(err, "failed to find facility")
+	}
+
+	return s.refer(ctx, partograph, facility, reportedByID, reason)
+}
+
+// ClosePartograph stops labour monitoring, e.g. after delivery
+func (s *Service) ClosePartograph(ctx context.Context, partographID uuid.UUID, status model.PartographStatus) (*model.Partograph, error) {
+	if status != model.PartographStatusDelivered && status != model.PartographStatusClosed {
+		return nil, errorx.Newf(errorx.BadRequest, "cannot close partograph as %s", status)
+	}
+
+	partograph, err := s.GetPartograph(ctx, partographID)
+	if err != nil {
+		return nil, err
+	}
+	if !partograph.IsActive() {
+		return nil, errorx.Newf(errorx.BadRequest, "partograph is %s", partograph.Status)
+	}
+
+	partograph.Close(status)
+	if err := s.partographRepo.Update(ctx, partograph); err != nil {
+		s.log.Error("Failed to close partograph", logger.Fields{
+			"error":         err.Error(),
+			"partograph_id": partographID.String(),
+		})
+		return nil, errorx.Wrap(err, "failed to close partograph")
+	}
+
+	return partograph, nil
+}
+
+// GetPartograph retrieves a partograph
+func (s *Service) GetPartograph(ctx context.Context, partographID uuid.UUID) (*model.Partograph, error) {
+	partograph, err := s.partographRepo.GetByID(ctx, partographID)
+	if err != nil {
+		s.log.Error("Failed to get partograph", logger.Fields{
+			"error":         err.Error(),
+			"partograph_id": partographID.String(),
+		})
+		return nil, errorx.Wrap(err, "failed to get partograph")
+	}
+	return partograph, nil
+}
+
+// GetFacilityPartographs retrieves the labours being monitored at a facility
+func (s *Service) GetFacilityPartographs(ctx context.Context, facilityID uuid.UUID) ([]*model.Partograph, error) {
+	partographs, err := s.partographRepo.GetActiveByFacilityID(ctx, facilityID)
+	if err != nil {
+		s.log.Error("Failed to get facility partographs", logger.Fields{
+			"error":       err.Error(),
+			"facility_id": facilityID.String(),
+		})
+		return nil, errorx.Wrap(err, "failed to get facility partographs")
+	}
+	return partographs, nil
+}
+
+// refer raises an SOS for the labour, sends it to the nearest hospital and closes the partograph
+func (s *Service) refer(
+	ctx context.Context,
+	partograph *model.Partograph,
+	facility *model.HealthcareFacility,
+	reportedByID uuid.UUID,
+	reason string,
+) (*model.SOSEvent, error) {
+	if s.referralService == nil {
+		return nil, errorx.New(errorx.InternalServerError, "referral service not configured")
+	}
+
+	description := fmt.Sprintf("Labour referral from %s: %s", facility.Name, reason)
+	sosEvent, err := s.referralService.ReportSOSEvent(
+		ctx,
+		partograph.MotherID,
+		reportedByID,
+		facility.Location.Latitude,
+		facility.Location.Longitude,
+		model.SOSEventNatureLabor,
+		description,
+	)
+	if err != nil {
+		return nil, errorx.Wrap(err, "failed to report referral SOS")
+	}
+
+	// Send the referral to the nearest hospital rather than the referring PHU
+	if hospital := s.nearestHospital(ctx, facility); hospital != nil {
+		if updated, err := s.referralService.AssignFacilityToSOSEvent(ctx, sosEvent.ID, hospital.ID); err != nil {
+			s.log.Warn("Failed to assign hospital to referral SOS", logger.Fields{
+				"error":       err.Error(),
+				"sos_id":      sosEvent.ID.String(),
+				"facility_id": hospital.ID.String(),
+			})
+		} else {
+			sosEvent = updated
+		}
+	}
+
+	partograph.Refer(sosEvent.ID, reason)
+	if err := s.partographRepo.Update(ctx, partograph); err != nil {
+		s.log.Error("Failed to mark partograph as referred", logger.Fields{
+			"error":         err.Error(),
+			"partograph_id": partograph.ID.String(),
+			"sos_id":        sosEvent.ID.String(),
+		})
+		return nil, errorx.Wrap(err, "failed to mark partograph as referred")
+	}
+
+	s.log.Info("Labour referred from partograph", logger.Fields{
+		"partograph_id": partograph.ID.String(),
+		"sos_id":        sosEvent.ID.String(),
+		"reason":        reason,
+	})
+
+	return sosEvent, nil
+}
+
+// nearestHospital finds the hospital closest to a facility
+func (s *Service) nearestHospital(ctx context.Context, from *model.HealthcareFacility) *model.HealthcareFacility {
+	hospitals, err := s.facilityRepo.FindByType(ctx, model.FacilityTypeHospital)
+	if err != nil {
+		s.log.Warn("Failed to find hospitals for referral", logger.Fields{
+			"error": err.Error(),
+		})
+		return nil
+	}
+
+	var nearest *model.HealthcareFacility
+	best := math.MaxFloat64
+	for _, hospital := range hospitals {
+		distance := s.locationService.CalculateDistance(
+			from.Location.Latitude, from.Location.Longitude,
+			hospital.Location.Latitude, hospital.Location.Longitude,
+		)
+		if distance < best {
+			best = distance
+			nearest = hospital
+		}
+	}
+	return nearest
+}
+
+// saveHealthMetric stores the observation's contractions, FHR and BP as a health metric
+func (s *Service) saveHealthMetric(ctx context.Context, motherID uuid.UUID, observation *model.PartographObservation) (*uuid.UUID, error) {
+	if observation.Contractions == nil && observation.FetalHeartRate == nil && observation.BloodPressure == nil {
+		return nil, nil
+	}
+
+	metric := model.NewHealthMetric(uuid.New(), motherID).
+		WithRecordedBy(observation.RecordedByID).
+		WithNotes("Partograph observation")
+	metric.RecordedAt = observation.RecordedAt
+
+	if c := observation.Contractions; c != nil {
+		metric.WithContractions(c.Duration, c.Interval, c.Intensity, c.FrequencyHour)
+	}
+	if observation.FetalHeartRate != nil {
+		metric.WithFetalHeartRate(*observation.FetalHeartRate)
+	}
+	if bp := observation.BloodPressure; bp != nil {
+		metric.WithBloodPressure(bp.Systolic, bp.Diastolic)
+	}
+
+	if err := s.healthMetricRepo.Save(ctx, metric); err != nil {
+		s.log.Error("Failed to save partograph health metric", logger.Fields{
+			"error":     err.Error(),
+			"mother_id": motherID.String(),
+		})
+		return nil, errorx.Wrap(err, "failed to save partograph health metric")
+	}
+
+	return &metric.ID, nil
+}
+
+// notifyAlerts sends new alerts to the birth attendant
+func (s *Service) notifyAlerts(ctx context.Context, partograph *model.Partograph, alerts []model.PartographAlert) {
+	if s.notifyService == nil {
+		return
+	}
+
+	for _, alert := range alerts {
+		if err := s.notifyService.SendPartographAlert(ctx, partograph.AttendantID, partograph, alert); err != nil {
+			s.log.Warn("Failed to send partograph alert", logger.Fields{
+				"error":         err.Error(),
+				"partograph_id": partograph.ID.String(),
+				"code":          alert.Code,
+			})
+		}
+	}
+}
+
+// validateObservation checks an observation against the partograph so far
+func validateObservation(partograph *model.Partograph, input *ObservationInput) error {
+	if input.RecordedAt.IsZero() {
+		input.RecordedAt = time.Now()
+	}
+	if input.RecordedAt.After(time.Now().Add(5 * time.Minute)) {
+		return errorx.New(errorx.BadRequest, "observation time cannot be in the future")
+	}
+	if input.RecordedAt.Before(partograph.AdmittedAt) {
+		return errorx.New(errorx.BadRequest, "observation time is before admission")
+	}
+
+	if input.CervicalDilationCm != nil {
+		dilation := *input.CervicalDilationCm
+		if dilation < 0 || dilation > model.FullDilationCm {
+			return errorx.New(errorx.BadRequest, "cervical dilation must be between 0 and 10 cm")
+		}
+		if previous, _, ok := partograph.LatestDilation(); ok && dilation < previous {
+			return errorx.Newf(errorx.BadRequest, "cervical dilation cannot go down from %.1f cm", previous)
+		}
+	}
+
+	if input.DescentFifths != nil && (*input.DescentFifths < 0 || *input.DescentFifths > 5) {
+		return errorx.New(errorx.BadRequest, "descent must be between 0 and 5 fifths")
+	}
+
+	if input.Liquor != nil {
+		switch *input.Liquor {
+		case model.LiquorIntact, model.LiquorClear, model.LiquorMeconium, model.LiquorBlood, model.LiquorAbsent:
+		default:
+			return errorx.Newf(errorx.BadRequest, "invalid liquor status: %s", *input.Liquor)
+		}
+	}
+
+	return nil
+}
+
+// evaluateObservation returns the alerts an observation raises. Line crossings and prolonged
+// labour are raised once; vital sign alerts are raised at every observation they occur in.
+func evaluateObservation(
+	partograph *model.Partograph,
+	observation *model.PartographObservation,
+	progress model.LabourProgress,
+) []model.PartographAlert {
+	var alerts []model.PartographAlert
+	raise := func(code string, severity model.PartographAlertSeverity, message string) {
+		alerts = append(alerts, model.PartographAlert{
+			Code:          code,
+			Severity:      severity,
+			Message:       message,
+			ObservationID: observation.ID,
+			RaisedAt:      time.Now(),
+		})
+	}
+
+	switch progress {
+	case model.LabourProgressAction:
+		if !partograph.HasAlert(AlertCodeActionLine) {
+			raise(AlertCodeActionLine, model.PartographAlertCritical,
+				"Cervical dilation has crossed the action line; assess for obstructed labour and refer or intervene now")
+		}
+	case model.LabourProgressAlert:
+		if !partograph.HasAlert(AlertCodeAlertLine) {
+			raise(AlertCodeAlertLine, model.PartographAlertWarning,
+				"Cervical dilation has crossed the alert line; labour is slower than 1 cm per hour")
+		}
+	}
+
+	if partograph.ActivePhaseAt != nil && !partograph.HasAlert(AlertCodeProlongedLabour) {
+		if dilation, _, ok := partograph.LatestDilation(); ok && dilation < model.FullDilationCm &&
+			observation.RecordedAt.Sub(*partograph.ActivePhaseAt) > maxActivePhase {
+			raise(AlertCodeProlongedLabour, model.PartographAlertCritical,
+				"Active phase has lasted more than 12 hours without full dilation")
+		}
+	}
+
+	if fhr := observation.FetalHeartRate; fhr != nil {
+		switch {
+		case *fhr < 100 || *fhr > 180:
+			raise(AlertCodeFetalHeartRate, model.PartographAlertCritical,
+				fmt.Sprintf("Fetal heart rate %.0f bpm suggests fetal distress", *fhr))
+		case *fhr < 110 || *fhr > 160:
+			raise(AlertCodeFetalHeartRate, model.PartographAlertWarning,
+				fmt.Sprintf("Fetal heart rate %.0f bpm is outside 110-160; recheck in 15 minutes", *fhr))
+		}
+	}
+
+	if liquor := observation.Liquor; liquor != nil {
+		switch *liquor {
+		case model.LiquorMeconium:
+			raise(AlertCodeMeconium, model.PartographAlertWarning,
+				"Meconium-stained liquor; monitor fetal heart rate every 15 minutes")
+		case model.LiquorBlood:
+			raise(AlertCodeBloodLiquor, model.PartographAlertCritical,
+				"Blood-stained liquor; assess for abruption or uterine rupture")
+		}
+	}
+
+	if bp := observation.BloodPressure; bp != nil {
+		switch {
+		case bp.Systolic >= 160 || bp.Diastolic >= 110:
+			raise(AlertCodeBloodPressure, model.PartographAlertCritical,
+				fmt.Sprintf("Severe hypertension %.0f/%.0f mmHg in labour", bp.Systolic, bp.Diastolic))
+		case bp.Systolic >= 140 || bp.Diastolic >= 90:
+			raise(AlertCodeBloodPressure, model.PartographAlertWarning,
+				fmt.Sprintf("Raised blood pressure %.0f/%.0f mmHg in labour", bp.Systolic, bp.Diastolic))
+		case bp.Systolic < 90:
+			raise(AlertCodeBloodPressure, model.PartographAlertCritical,
+				fmt.Sprintf("Low blood pressure %.0f/%.0f mmHg; assess for bleeding", bp.Systolic, bp.Diastolic))
+		}
+	}
+
+	if pulse := observation.PulseRate; pulse != nil {
+		switch {
+		case *pulse > 120:
+			raise(AlertCodePulse, model.PartographAlertCritical,
+				fmt.Sprintf("Maternal pulse %.0f bpm; assess for bleeding or infection", *pulse))
+		case *pulse > 100:
+			raise(AlertCodePulse, model.PartographAlertWarning,
+				fmt.Sprintf("Maternal pulse %.0f bpm is raised", *pulse))
+		}
+	}
+
+	if temp := observation.TemperatureC; temp != nil && *temp >= 38 {
+		raise(AlertCodeFever, model.PartographAlertCritical,
+			fmt.Sprintf("Maternal temperature %.1f°C; treat for intrapartum infection", *temp))
+	}
+
+	// More than 5 contractions in 10 minutes
+	if c := observation.Contractions; c != nil && c.FrequencyHour > 30 {
+		raise(AlertCodeExcessContraction, model.PartographAlertWarning,
+			fmt.Sprintf("%d contractions per hour; watch for uterine hyperstimulation", c.FrequencyHour))
+	}
+
+	return alerts
+}
+
+// referralReason decides if new alerts call for referral from a PHU
+func referralReason(alerts []model.PartographAlert) (string, bool) {
+	var reasons []string
+	for _, alert := range alerts {
+		if alert.Severity == model.PartographAlertCritical {
+			reasons = append(reasons, alert.Message)
+		}
+	}
+	if len(reasons) == 0 {
+		return "", false
+	}
+	return strings.Join(reasons, "; "), true
+}
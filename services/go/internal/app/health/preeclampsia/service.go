@@ -0,0 +1,563 @@
+package preeclampsia
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mamacare/services/internal/domain/model"
+	"github.com/mamacare/services/internal/domain/repository"
+	"github.com/mamacare/services/pkg/errorx"
+	"github.com/mamacare/services/pkg/logger"
+)
+
+// Classification is the hypertensive disorder a mother's readings point to
+type Classification string
+
+const (
+	// ClassificationNone means no hypertensive disorder was found
+	ClassificationNone Classification = "none"
+	// ClassificationWatch means a single raised reading or a rising BP that needs rechecking
+	ClassificationWatch Classification = "watch"
+	// ClassificationChronicHypertension means hypertension before 20 weeks or a known diagnosis
+	ClassificationChronicHypertension Classification = "chronic_hypertension"
+	// ClassificationGestationalHypertension means new hypertension from 20 weeks without proteinuria or severe features
+	ClassificationGestationalHypertension Classification = "gestational_hypertension"
+	// ClassificationPreeclampsia means new hypertension from 20 weeks with proteinuria
+	ClassificationPreeclampsia Classification = "preeclampsia"
+	// ClassificationSuperimposed means pre-eclampsia on top of chronic hypertension
+	ClassificationSuperimposed Classification = "superimposed_preeclampsia"
+	// ClassificationSevere means pre-eclampsia with severe features
+	ClassificationSevere Classification = "preeclampsia_severe"
+)
+
+// DangerSign is a symptom of pre-eclampsia with severe features
+type DangerSign string
+
+const (
+	// DangerSignHeadache is a severe or persistent headache
+	DangerSignHeadache DangerSign = "headache"
+	// DangerSignVisualDisturbance is blurred vision, flashing lights or spots
+	DangerSignVisualDisturbance DangerSign = "visual_disturbance"
+	// DangerSignEpigastricPain is epigastric or right upper quadrant pain
+	DangerSignEpigastricPain DangerSign = "epigastric_pain"
+)
+
+// ActionType is the kind of follow-up suggested for an assessment
+type ActionType string
+
+const (
+	// ActionNone means routine ANC is enough
+	ActionNone ActionType = "none"
+	// ActionMonitor means BP and urine should be rechecked more often
+	ActionMonitor ActionType = "monitor"
+	// ActionReferral means the mother should be referred to a clinician
+	ActionReferral ActionType = "referral"
+	// ActionSOS means an SOS should be raised for emergency transfer
+	ActionSOS ActionType = "sos"
+)
+
+// Urgency is how soon a suggested action should happen
+type Urgency string
+
+const (
+	// UrgencyRoutine means at the next scheduled contact
+	UrgencyRoutine Urgency = "routine"
+	// UrgencyWithinWeek means within 7 days
+	UrgencyWithinWeek Urgency = "within_week"
+	// UrgencyWithin24Hours means within 24 hours
+	UrgencyWithin24Hours Urgency = "within_24_hours"
+	// UrgencyImmediate means now
+	UrgencyImmediate Urgency = "immediate"
+)
+
+// Clinical thresholds
+const (
+	// hypertensionSystolic and hypertensionDiastolic define hypertension in pregnancy (mmHg)
+	hypertensionSystolic  = 140.0
+	hypertensionDiastolic = 90.0
+
+	// severeSystolic and severeDiastolic define severe-range BP (mmHg)
+	severeSystolic  = 160.0
+	severeDiastolic = 110.0
+
+	// riseSystolic and riseDiastolic are rises from baseline that warrant closer watch (mmHg)
+	riseSystolic  = 30.0
+	riseDiastolic = 15.0
+
+	// onsetWeeks is the gestational age from which new hypertension is gestational rather than chronic
+	onsetWeeks = 20
+
+	// persistenceInterval is how far apart two raised readings must be to confirm hypertension
+	persistenceInterval = 4 * time.Hour
+
+	// readingWindow is how far back BP and urine readings are considered
+	readingWindow = 14 * 24 * time.Hour
+
+	// symptomWindow is how far back screener symptoms are considered
+	symptomWindow = 7 * 24 * time.Hour
+
+	// postpartumWindow is how long after delivery postpartum pre-eclampsia is considered
+	postpartumWindow = 6 * 7 * 24 * time.Hour
+)
+
+// symptomCodes maps screener question codes and subcategories to danger signs
+var symptomCodes = map[string]DangerSign{
+	"headache":             DangerSignHeadache,
+	"severe_headache":      DangerSignHeadache,
+	"persistent_headache":  DangerSignHeadache,
+	"visual_disturbance":   DangerSignVisualDisturbance,
+	"blurred_vision":       DangerSignVisualDisturbance,
+	"vision_problems":      DangerSignVisualDisturbance,
+	"epigastric_pain":      DangerSignEpigastricPain,
+	"upper_abdominal_pain": DangerSignEpigastricPain,
+	"ruq_pain":             DangerSignEpigastricPain,
+}
+
+// chronicHypertensionConditions are health conditions that mean hypertension predates the pregnancy
+var chronicHypertensionConditions = map[string]bool{
+	"hypertension":         true,
+	"chronic hypertension": true,
+	"chronic_hypertension": true,
+	"high blood pressure":  true,
+}
+
+// Recommendation is the suggested follow-up for an assessment
+type Recommendation struct {
+	Action  ActionType `json:"action"`
+	Urgency Urgency    `json:"urgency"`
+	Reason  string     `json:"reason"`
+	Steps   []string   `json:"steps"`
+}
+
+// Assessment is the result of a pre-eclampsia assessment
+type Assessment struct {
+	MotherID            uuid.UUID            `json:"mother_id"`
+	AssessedAt          time.Time            `json:"assessed_at"`
+	GestationalAgeWeeks *int                 `json:"gestational_age_weeks,omitempty"`
+	Postpartum          bool                 `json:"postpartum"`
+	Classification      Classification       `json:"classification"`
+	SevereFeatures      []string             `json:"severe_features"`
+	Readings            int                  `json:"readings"`
+	RaisedReadings      int                  `json:"raised_readings"`
+	PersistentBP        bool                 `json:"persistent_bp"`
+	LatestBP            *model.BloodPressure `json:"latest_bp,omitempty"`
+	HighestBP           *model.BloodPressure `json:"highest_bp,omitempty"`
+	BaselineBP          *model.BloodPressure `json:"baseline_bp,omitempty"`
+	RisingBP            bool                 `json:"rising_bp"`
+	UrineProtein        *model.UrineProtein  `json:"urine_protein,omitempty"`
+	Proteinuria         bool                 `json:"proteinuria"`
+	ChronicHypertension bool                 `json:"chronic_hypertension"`
+	Symptoms            []DangerSign         `json:"symptoms"`
+	Recommendation      Recommendation       `json:"recommendation"`
+}
+
+// Service assesses mothers for gestational hypertension and pre-eclampsia
+type Service struct {
+	motherRepo       repository.MotherRepository
+	healthMetricRepo repository.HealthMetricRepository
+	screenerRepo     repository.ScreenerRepository
+	log              logger.Logger
+}
+
+// NewService creates a new pre-eclampsia service
+func NewService(
+	motherRepo repository.MotherRepository,
+	healthMetricRepo repository.HealthMetricRepository,
+	screenerRepo repository.ScreenerRepository,
+	log logger.Logger,
+) *Service {
+	return &Service{
+		motherRepo:       motherRepo,
+		healthMetricRepo: healthMetricRepo,
+		screenerRepo:     screenerRepo,
+		log:              log,
+	}
+}
+
+// AssessMother combines serial BP, urine protein and screener symptoms into a pre-eclampsia
+// assessment. Reported symptoms are danger signs captured outside a screener, e.g. at a visit.
+func (s *Service) AssessMother(ctx context.Context, motherID uuid.UUID, reported []DangerSign) (*Assessment, error) {
+	mother, err := s.motherRepo.GetByID(ctx, motherID)
+	if err != nil {
+		s.log.Error("Failed to find mother", logger.Fields{
+			"error":     err.Error(),
+			"mother_id": motherID.String(),
+		})
+		return nil, errorx.Wrap(err, "failed to find mother")
+	}
+
+	metrics, err := s.healthMetricRepo.FindByMother(ctx, motherID)
+	if err != nil {
+		s.log.Error("Failed to get health metrics", logger.Fields{
+			"error":     err.Error(),
+			"mother_id": motherID.String(),
+		})
+		return nil, errorx.Wrap(err, "failed to get health metrics")
+	}
+
+	now := time.Now()
+	symptoms := append([]DangerSign{}, reported...)
+
+	if s.screenerRepo != nil {
+		screened, err := s.screenerRepo.GetSymptomsByUserID(ctx, mother.UserID, now.Add(-symptomWindow))
+		if err != nil {
+			// Continue with BP and urine alone rather than failing
+			s.log.Warn("Failed to get screener symptoms", logger.Fields{
+				"error":     err.Error(),
+				"mother_id": motherID.String(),
+			})
+		} else {
+			symptoms = append(symptoms, SymptomsFromScreener(screened)...)
+		}
+	}
+
+	assessment := Assess(mother, metrics, symptoms, now)
+
+	s.log.Info("Pre-eclampsia assessment completed", logger.Fields{
+		"mother_id":      motherID.String(),
+		"classification": string(assessment.Classification),
+		"action":         string(assessment.Recommendation.Action),
+		"readings":       assessment.Readings,
+	})
+
+	return assessment, nil
+}
+
+// Assess classifies a mother's hypertensive status from her health metrics and danger signs
+func Assess(mother *model.Mother, metrics []*model.HealthMetric, symptoms []DangerSign, now time.Time) *Assessment {
+	assessment := &Assessment{
+		MotherID:       mother.ID,
+		AssessedAt:     now,
+		Postpartum:     mother.IsPostpartum(),
+		SevereFeatures: []string{},
+		Symptoms:       dedupeSymptoms(symptoms),
+	}
+
+	if !assessment.Postpartum {
+		weeks := mother.GetWeeksPregnant(now)
+		assessment.GestationalAgeWeeks = &weeks
+	}
+
+	// Oldest first so persistence and rises can be read in order
+	sorted := make([]*model.HealthMetric, 0, len(metrics))
+	for _, metric := range metrics {
+		if metric != nil && !metric.RecordedAt.After(now) {
+			sorted = append(sorted, metric)
+		}
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].RecordedAt.Before(sorted[j].RecordedAt)
+	})
+
+	assessment.ChronicHypertension = hasChronicHypertension(mother, sorted)
+	assessment.BaselineBP = baselineBP(mother, sorted)
+
+	windowStart := now.Add(-readingWindow)
+	var firstRaised *time.Time
+	severeRange := false
+
+	for _, metric := range sorted {
+		if metric.RecordedAt.Before(windowStart) {
+			continue
+		}
+
+		if protein := metric.VitalSigns.UrineProtein; protein != nil && protein.IsValid() {
+			if assessment.UrineProtein == nil || protein.Grade() > assessment.UrineProtein.Grade() {
+				value := *protein
+				assessment.UrineProtein = &value
+			}
+		}
+
+		bp := metric.VitalSigns.BloodPressure
+		if bp == nil {
+			continue
+		}
+
+		assessment.Readings++
+		reading := *bp
+		assessment.LatestBP = &reading
+		if assessment.HighestBP == nil {
+			highest := reading
+			assessment.HighestBP = &highest
+		} else {
+			if reading.Systolic > assessment.HighestBP.Systolic {
+				assessment.HighestBP.Systolic = reading.Systolic
+			}
+			if reading.Diastolic > assessment.HighestBP.Diastolic {
+				assessment.HighestBP.Diastolic = reading.Diastolic
+			}
+		}
+
+		if isSevereRange(reading) {
+			severeRange = true
+		}
+
+		if !isRaised(reading) {
+			continue
+		}
+
+		assessment.RaisedReadings++
+		recordedAt := metric.RecordedAt
+		if firstRaised == nil {
+			firstRaised = &recordedAt
+		} else if recordedAt.Sub(*firstRaised) >= persistenceInterval {
+			assessment.PersistentBP = true
+		}
+	}
+
+	// A severe-range reading is confirmed within minutes rather than hours
+	if severeRange {
+		assessment.PersistentBP = true
+		assessment.SevereFeatures = append(assessment.SevereFeatures, "severe-range blood pressure")
+	}
+
+	if assessment.UrineProtein != nil {
+		assessment.Proteinuria = assessment.UrineProtein.IsProteinuria()
+	}
+
+	for _, symptom := range assessment.Symptoms {
+		assessment.SevereFeatures = append(assessment.SevereFeatures, symptomDescription(symptom))
+	}
+
+	if assessment.LatestBP != nil && assessment.BaselineBP != nil {
+		assessment.RisingBP = assessment.LatestBP.Systolic-assessment.BaselineBP.Systolic >= riseSystolic ||
+			assessment.LatestBP.Diastolic-assessment.BaselineBP.Diastolic >= riseDiastolic
+	}
+
+	assessment.Classification = classify(assessment, mother, now)
+	assessment.Recommendation = recommend(assessment)
+
+	return assessment
+}
+
+// SymptomsFromScreener maps positive screener answers to pre-eclampsia danger signs
+func SymptomsFromScreener(symptoms []*model.ScreenerSymptom) []DangerSign {
+	var signs []DangerSign
+	for _, symptom := range symptoms {
+		if sign, ok := ParseDangerSign(symptom.QuestionCode); ok {
+			signs = append(signs, sign)
+		} else if sign, ok := ParseDangerSign(symptom.Subcategory); ok {
+			signs = append(signs, sign)
+		}
+	}
+	return signs
+}
+
+// ParseDangerSign maps a symptom code to a danger sign
+func ParseDangerSign(code string) (DangerSign, bool) {
+	key := strings.ToLower(strings.TrimSpace(code))
+	key = strings.ReplaceAll(key, " ", "_")
+	sign, ok := symptomCodes[key]
+	return sign, ok
+}
+
+// classify applies the hypertensive disorder definitions in order of severity
+func classify(a *Assessment, mother *model.Mother, now time.Time) Classification {
+	if !a.PersistentBP {
+		if a.RaisedReadings > 0 || a.RisingBP {
+			return ClassificationWatch
+		}
+		return ClassificationNone
+	}
+
+	// After 20 weeks, or within six weeks of delivery, new hypertension is gestational
+	afterOnset := a.GestationalAgeWeeks != nil && *a.GestationalAgeWeeks >= onsetWeeks
+	if a.Postpartum {
+		afterOnset = mother.DeliveryDate == nil || now.Sub(*mother.DeliveryDate) <= postpartumWindow
+	}
+	severe := len(a.SevereFeatures) > 0
+
+	if a.ChronicHypertension || !afterOnset {
+		if !afterOnset {
+			return ClassificationChronicHypertension
+		}
+		if severe {
+			return ClassificationSevere
+		}
+		if a.Proteinuria {
+			return ClassificationSuperimposed
+		}
+		return ClassificationChronicHypertension
+	}
+
+	switch {
+	case severe:
+		return ClassificationSevere
+	case a.Proteinuria:
+		return ClassificationPreeclampsia
+	default:
+		return ClassificationGestationalHypertension
+	}
+}
+
+// recommend suggests a referral or SOS for a classification
+func recommend(a *Assessment) Recommendation {
+	switch a.Classification {
+	case ClassificationSevere:
+		return Recommendation{
+			Action:  ActionSOS,
+			Urgency: UrgencyImmediate,
+			Reason:  "Pre-eclampsia with severe features: " + strings.Join(a.SevereFeatures, ", "),
+			Steps: []string{
+				"Raise an SOS for emergency transfer to a CEmONC facility",
+				"Give magnesium sulfate loading dose and antihypertensive if trained and available",
+				"Keep the mother lying on her left side and do not leave her alone",
+			},
+		}
+	case ClassificationPreeclampsia, ClassificationSuperimposed:
+		return Recommendation{
+			Action:  ActionReferral,
+			Urgency: UrgencyWithin24Hours,
+			Reason:  "Raised blood pressure with proteinuria",
+			Steps: []string{
+				"Refer to a facility with a clinician within 24 hours",
+				"Counsel the mother on headache, vision changes and upper abdominal pain and to use SOS if they start",
+			},
+		}
+	case ClassificationGestationalHypertension:
+		steps := []string{
+			"Refer for clinician review within a week",
+			"Check blood pressure and urine protein twice a week",
+		}
+		if a.UrineProtein == nil {
+			steps = append([]string{"Test urine protein today"}, steps...)
+		}
+		return Recommendation{
+			Action:  ActionReferral,
+			Urgency: UrgencyWithinWeek,
+			Reason:  "New hypertension after 20 weeks without proteinuria",
+			Steps:   steps,
+		}
+	case ClassificationChronicHypertension:
+		return Recommendation{
+			Action:  ActionMonitor,
+			Urgency: UrgencyWithinWeek,
+			Reason:  "Chronic hypertension needs monitoring for superimposed pre-eclampsia",
+			Steps: []string{
+				"Check blood pressure and urine protein at every contact",
+				"Confirm antihypertensive treatment with a clinician",
+			},
+		}
+	case ClassificationWatch:
+		reason := "A single raised blood pressure reading"
+		if a.RisingBP {
+			reason = "Blood pressure has risen from baseline"
+		}
+		return Recommendation{
+			Action:  ActionMonitor,
+			Urgency: UrgencyWithin24Hours,
+			Reason:  reason,
+			Steps: []string{
+				"Recheck blood pressure after resting, at least 4 hours later",
+				"Test urine protein",
+			},
+		}
+	}
+
+	if len(a.Symptoms) > 0 {
+		return Recommendation{
+			Action:  ActionMonitor,
+			Urgency: UrgencyWithin24Hours,
+			Reason:  "Danger signs reported without a recent raised blood pressure",
+			Steps: []string{
+				"Check blood pressure and urine protein today",
+			},
+		}
+	}
+
+	return Recommendation{
+		Action:  ActionNone,
+		Urgency: UrgencyRoutine,
+		Reason:  "No signs of a hypertensive disorder",
+		Steps:   []string{},
+	}
+}
+
+// hasChronicHypertension checks for a known diagnosis or raised BP before 20 weeks of this pregnancy
+func hasChronicHypertension(mother *model.Mother, metrics []*model.HealthMetric) bool {
+	for _, condition := range mother.HealthConditions {
+		if chronicHypertensionConditions[strings.ToLower(strings.TrimSpace(condition))] {
+			return true
+		}
+	}
+
+	for _, metric := range metrics {
+		bp := metric.VitalSigns.BloodPressure
+		if bp != nil && isRaised(*bp) && beforeOnset(mother, metric.RecordedAt) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// baselineBP averages the readings taken in this pregnancy before 20 weeks
+func baselineBP(mother *model.Mother, metrics []*model.HealthMetric) *model.BloodPressure {
+	var systolic, diastolic float64
+	count := 0
+
+	for _, metric := range metrics {
+		bp := metric.VitalSigns.BloodPressure
+		if bp == nil || !beforeOnset(mother, metric.RecordedAt) {
+			continue
+		}
+		systolic += bp.Systolic
+		diastolic += bp.Diastolic
+		count++
+	}
+
+	if count == 0 {
+		return nil
+	}
+
+	return &model.BloodPressure{
+		Systolic:  systolic / float64(count),
+		Diastolic: diastolic / float64(count),
+	}
+}
+
+// beforeOnset checks if a reading was taken in this pregnancy before 20 weeks. Readings
+// from before the LMP belong to an earlier pregnancy or to no pregnancy at all.
+func beforeOnset(mother *model.Mother, recordedAt time.Time) bool {
+	return !recordedAt.Before(mother.DatingLMP()) && mother.GetWeeksPregnant(recordedAt) < onsetWeeks
+}
+
+// isRaised checks if a reading is in the hypertensive range
+func isRaised(bp model.BloodPressure) bool {
+	return bp.Systolic >= hypertensionSystolic || bp.Diastolic >= hypertensionDiastolic
+}
+
+// isSevereRange checks if a reading is in the severe range
+func isSevereRange(bp model.BloodPressure) bool {
+	return bp.Systolic >= severeSystolic || bp.Diastolic >= severeDiastolic
+}
+
+// dedupeSymptoms removes repeated danger signs and keeps their first-seen order
+func dedupeSymptoms(symptoms []DangerSign) []DangerSign {
+	seen := make(map[DangerSign]bool)
+	result := []DangerSign{}
+	for _, symptom := range symptoms {
+		if !seen[symptom] {
+			seen[symptom] = true
+			result = append(result, symptom)
+		}
+	}
+	return result
+}
+
+// symptomDescription describes a danger sign as a severe feature
+func symptomDescription(sign DangerSign) string {
+	switch sign {
+	case DangerSignHeadache:
+		return "severe headache"
+	case DangerSignVisualDisturbance:
+		return "visual disturbance"
+	case DangerSignEpigastricPain:
+		return "epigastric pain"
+	default:
+		return string(sign)
+	}
+}
@@ -0,0 +1,190 @@
+package preeclampsia
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mamacare/services/internal/domain/model"
+)
+
+var testNow = time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+
+// weeksAgo returns the time a number of weeks before testNow
+func weeksAgo(weeks int) time.Time {
+	return testNow.AddDate(0, 0, -7*weeks)
+}
+
+// bp is a blood pressure reading
+func bp(recordedAt time.Time, systolic, diastolic float64) *model.HealthMetric {
+	return &model.HealthMetric{
+		RecordedAt: recordedAt,
+		VitalSigns: model.VitalSigns{BloodPressure: &model.BloodPressure{Systolic: systolic, Diastolic: diastolic}},
+	}
+}
+
+// dipstick is a urine protein reading
+func dipstick(recordedAt time.Time, protein model.UrineProtein) *model.HealthMetric {
+	return &model.HealthMetric{
+		RecordedAt: recordedAt,
+		VitalSigns: model.VitalSigns{UrineProtein: &protein},
+	}
+}
+
+// persistent is two raised readings taken four hours apart today
+func persistent(metrics ...*model.HealthMetric) []*model.HealthMetric {
+	return append([]*model.HealthMetric{
+		bp(testNow.Add(-persistenceInterval), 145, 95),
+		bp(testNow, 142, 92),
+	}, metrics...)
+}
+
+func TestAssessClassification(t *testing.T) {
+	tests := []struct {
+		name string
+		// weeks is how far along the mother is at testNow
+		weeks int
+		// deliveredWeeksAgo, if set, makes the mother postpartum
+		deliveredWeeksAgo int
+		conditions        []string
+		metrics           []*model.HealthMetric
+		symptoms          []DangerSign
+		want              Classification
+	}{
+		{
+			name:  "no readings",
+			weeks: 30,
+			want:  ClassificationNone,
+		},
+		{
+			name:    "normal readings",
+			weeks:   30,
+			metrics: []*model.HealthMetric{bp(weeksAgo(1), 118, 76), bp(testNow, 120, 78)},
+			want:    ClassificationNone,
+		},
+		{
+			name:    "single raised reading",
+			weeks:   30,
+			metrics: []*model.HealthMetric{bp(testNow, 145, 92)},
+			want:    ClassificationWatch,
+		},
+		{
+			name:    "raised readings less than four hours apart",
+			weeks:   30,
+			metrics: []*model.HealthMetric{bp(testNow.Add(-time.Hour), 145, 92), bp(testNow, 144, 91)},
+			want:    ClassificationWatch,
+		},
+		{
+			name:    "rise from the early pregnancy baseline",
+			weeks:   30,
+			metrics: []*model.HealthMetric{bp(weeksAgo(18), 105, 65), bp(testNow, 125, 82)},
+			want:    ClassificationWatch,
+		},
+		{
+			name:    "persistent hypertension from 20 weeks",
+			weeks:   30,
+			metrics: persistent(),
+			want:    ClassificationGestationalHypertension,
+		},
+		{
+			name:    "persistent hypertension with proteinuria",
+			weeks:   30,
+			metrics: persistent(dipstick(testNow, model.UrineProteinPlus2)),
+			want:    ClassificationPreeclampsia,
+		},
+		{
+			name:    "trace protein is not proteinuria",
+			weeks:   30,
+			metrics: persistent(dipstick(testNow, model.UrineProteinTrace)),
+			want:    ClassificationGestationalHypertension,
+		},
+		{
+			name:    "single severe-range reading",
+			weeks:   30,
+			metrics: []*model.HealthMetric{bp(testNow, 165, 100)},
+			want:    ClassificationSevere,
+		},
+		{
+			name:     "persistent hypertension with a danger sign",
+			weeks:    30,
+			metrics:  persistent(),
+			symptoms: []DangerSign{DangerSignHeadache},
+			want:     ClassificationSevere,
+		},
+		{
+			name:    "persistent hypertension before 20 weeks",
+			weeks:   16,
+			metrics: persistent(),
+			want:    ClassificationChronicHypertension,
+		},
+		{
+			name:    "raised before 20 weeks of this pregnancy",
+			weeks:   30,
+			metrics: persistent(bp(weeksAgo(18), 150, 95)),
+			want:    ClassificationChronicHypertension,
+		},
+		{
+			name:    "raised before this pregnancy",
+			weeks:   30,
+			metrics: persistent(bp(weeksAgo(35), 150, 95)),
+			want:    ClassificationGestationalHypertension,
+		},
+		{
+			name:       "known hypertension",
+			weeks:      30,
+			conditions: []string{"High blood pressure"},
+			metrics:    persistent(),
+			want:       ClassificationChronicHypertension,
+		},
+		{
+			name:       "known hypertension with proteinuria",
+			weeks:      30,
+			conditions: []string{"hypertension"},
+			metrics:    persistent(dipstick(testNow, model.UrineProteinPlus1)),
+			want:       ClassificationSuperimposed,
+		},
+		{
+			name:       "known hypertension with a danger sign",
+			weeks:      30,
+			conditions: []string{"hypertension"},
+			metrics:    persistent(),
+			symptoms:   []DangerSign{DangerSignVisualDisturbance},
+			want:       ClassificationSevere,
+		},
+		{
+			name:              "persistent hypertension within six weeks of delivery",
+			deliveredWeeksAgo: 2,
+			metrics:           persistent(),
+			want:              ClassificationGestationalHypertension,
+		},
+		{
+			name:              "persistent hypertension over six weeks after delivery",
+			deliveredWeeksAgo: 8,
+			metrics:           persistent(),
+			want:              ClassificationChronicHypertension,
+		},
+		{
+			name:    "readings older than two weeks are not counted",
+			weeks:   30,
+			metrics: []*model.HealthMetric{bp(weeksAgo(3).Add(-persistenceInterval), 145, 95), bp(weeksAgo(3), 145, 95)},
+			want:    ClassificationNone,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mother := &model.Mother{
+				ExpectedDeliveryDate: testNow.AddDate(0, 0, 7*(40-tt.weeks)),
+				HealthConditions:     tt.conditions,
+			}
+			if tt.deliveredWeeksAgo > 0 {
+				mother.ExpectedDeliveryDate = weeksAgo(tt.deliveredWeeksAgo)
+				mother.RecordDelivery(weeksAgo(tt.deliveredWeeksAgo))
+			}
+
+			assessment := Assess(mother, tt.metrics, tt.symptoms, testNow)
+			if assessment.Classification != tt.want {
+				t.Errorf("Assess() classification = %s, want %s", assessment.Classification, tt.want)
+			}
+		})
+	}
+}
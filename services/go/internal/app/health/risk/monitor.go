@@ -0,0 +1,304 @@
+package risk
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mamacare/services/internal/domain/model"
+	"github.com/mamacare/services/internal/domain/repository"
+	"github.com/mamacare/services/pkg/errorx"
+	"github.com/mamacare/services/pkg/logger"
+)
+
+// NotificationService defines the interface for sending risk notifications
+type NotificationService interface {
+	// SendRiskLevelChange tells a health worker that a mother's risk level has changed
+	SendRiskLevelChange(ctx context.Context, recipientID uuid.UUID, mother *model.Mother, assessment *model.RiskAssessment) error
+}
+
+// Monitor keeps risk assessments and Mother.RiskLevel up to date as a mother's data changes
+type Monitor struct {
+	riskService      *Service
+	assessmentRepo   repository.RiskAssessmentRepository
+	motherRepo       repository.MotherRepository
+	healthMetricRepo repository.HealthMetricRepository
+	visitRepo        repository.VisitRepository
+	notifyService    NotificationService
+	transactor       repository.Transactor
+	log              logger.Logger
+}
+
+// NewMonitor creates a new risk monitor
+func NewMonitor(
+	riskService *Service,
+	assessmentRepo repository.RiskAssessmentRepository,
+	motherRepo repository.MotherRepository,
+	healthMetricRepo repository.HealthMetricRepository,
+	visitRepo repository.VisitRepository,
+	notifyService NotificationService,
+	transactor repository.Transactor,
+	log logger.Logger,
+) *Monitor {
+	return &Monitor{
+		riskService:      riskService,
+		assessmentRepo:   assessmentRepo,
+		motherRepo:       motherRepo,
+		healthMetricRepo: healthMetricRepo,
+		visitRepo:        visitRepo,
+		notifyService:    notifyService,
+		transactor:       transactor,
+		log:              log,
+	}
+}
+
+// Reassess calculates and stores a new risk assessment for a mother, updates her
+// risk level and notifies her CHW and clinician if the level changed
+func (m *Monitor) Reassess(
+	ctx context.Context,
+	motherID uuid.UUID,
+	trigger model.RiskTrigger,
+	triggerRefID *uuid.UUID,
+) (*model.RiskAssessment, error) {
+	mother, err := m.motherRepo.GetByID(ctx, motherID)
+	if err != nil {
+		m.log.Error("Failed to find mother", logger.Fields{
+			"error":     err.Error(),
+			"mother_id": motherID.String(),
+		})
+		return nil, errorx.Wrap(err, "failed to find mother")
+	}
+
+	metrics, err := m.healthMetricRepo.FindByMother(ctx, motherID)
+	if err != nil {
+		m.log.Warn("Failed to retrieve health metrics", logger.Fields{
+			"error":     err.Error(),
+			"mother_id": motherID.String(),
+		})
+		// Continue with empty metrics rather than failing
+		metrics = []*model.HealthMetric{}
+	}
+
+	result, err := m.riskService.CalculateRisk(mother, metrics)
+	if err != nil {
+		return nil, err
+	}
+
+	previous := mother.RiskLevel
+	assessment := &model.RiskAssessment{
+		ID:                  uuid.New(),
+		MotherID:            mother.ID,
+		RiskLevel:           result.RiskLevel,
+		PreviousRiskLevel:   &previous,
+		RiskScore:           result.RiskScore,
+		RiskFactors:         result.RiskFactors,
+		FiredRules:          result.FiredRules,
+		RuleSetVersion:      result.RuleSetVersion,
+		Trigger:             trigger,
+		TriggerRefID:        triggerRefID,
+		MaternalAge:         result.MaternalAge,
+		GestationalAgeWeeks: result.GestationalAgeWeeks,
+		AssessedAt:          result.AssessedAt,
+		CreatedAt:           time.Now(),
+	}
+
+	// The assessment and the mother's risk level are saved together, and the care team is
+	// only told of a level change once both are committed
+	err = m.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		return m.storeAssessment(ctx, mother, assessment)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if assessment.LevelChanged() {
+		m.notifyLevelChange(ctx, mother, assessment)
+	}
+
+	m.log.Info("Risk assessment stored", logger.Fields{
+		"assessment_id":    assessment.ID.String(),
+		"mother_id":        motherID.String(),
+		"trigger":          string(trigger),
+		"risk_level":       string(assessment.RiskLevel),
+		"previous_level":   string(previous),
+		"risk_score":       assessment.RiskScore,
+		"rule_set_version": assessment.RuleSetVersion,
+	})
+
+	return assessment, nil
+}
+
+// storeAssessment stores an assessment and updates the mother's risk level if it changed
+func (m *Monitor) storeAssessment(ctx context.Context, mother *model.Mother, assessment *model.RiskAssessment) error {
+	if err := m.assessmentRepo.Create(ctx, assessment); err != nil {
+		m.log.Error("Failed to store risk assessment", logger.Fields{
+			"error":     err.Error(),
+			"mother_id": mother.ID.String(),
+		})
+		return errorx.Wrap(err, "failed to store risk assessment")
+	}
+
+	if !assessment.LevelChanged() {
+		return nil
+	}
+	mother.WithRiskLevel(assessment.RiskLevel)
+	if err := m.motherRepo.Save(ctx, mother); err != nil {
+		m.log.Error("Failed to update mother risk level", logger.Fields{
+			"error":     err.Error(),
+			"mother_id": mother.ID.String(),
+		})
+		return errorx.Wrap(err, "failed to update mother risk level")
+	}
+	return nil
+}
+
+// OnHealthMetricSaved reassesses a mother after a new health metric is saved
+func (m *Monitor) OnHealthMetricSaved(ctx context.Context, metric *model.HealthMetric) (*model.RiskAssessment, error) {
+	metricID := metric.ID
+	return m.Reassess(ctx, metric.MotherID, model.RiskTriggerHealthMetric, &metricID)
+}
+
+// OnConditionsChanged reassesses a mother after her health conditions or pregnancy history change
+func (m *Monitor) OnConditionsChanged(ctx context.Context, motherID uuid.UUID) (*model.RiskAssessment, error) {
+	return m.Reassess(ctx, motherID, model.RiskTriggerConditionsChanged, nil)
+}
+
+// ReassessGestationThresholds reassesses every pregnant mother whose gestational age
+// has crossed a rule threshold since her last assessment. It is meant to run daily.
+func (m *Monitor) ReassessGestationThresholds(ctx context.Context) (int, error) {
+	thresholds := m.riskService.RuleSet().GestationThresholds()
+	if len(thresholds) == 0 {
+		return 0, nil
+	}
+
+	mothers, err := m.motherRepo.FindAll(ctx)
+	if err != nil {
+		m.log.Error("Failed to list mothers", logger.Fields{
+			"error": err.Error(),
+		})
+		return 0, errorx.Wrap(err, "failed to list mothers")
+	}
+
+	now := time.Now()
+	reassessed := 0
+
+	for _, mother := range mothers {
+		if mother.IsPostpartum() {
+			continue
+		}
+
+		currentWeeks := mother.GetWeeksPregnant(now)
+		lastWeeks := -1
+
+		latest, err := m.assessmentRepo.GetLatestByMotherID(ctx, mother.ID)
+		if err == nil && latest.GestationalAgeWeeks != nil {
+			lastWeeks = *latest.GestationalAgeWeeks
+		} else if err != nil && !errorx.IsType(err, errorx.NotFound) {
+			m.log.Warn("Failed to get latest risk assessment", logger.Fields{
+				"error":     err.Error(),
+				"mother_id": mother.ID.String(),
+			})
+			continue
+		}
+
+		if !crossesThreshold(lastWeeks, currentWeeks, thresholds) {
+			continue
+		}
+
+		if _, err := m.Reassess(ctx, mother.ID, model.RiskTriggerGestationThreshold, nil); err != nil {
+			m.log.Warn("Failed to reassess mother at gestation threshold", logger.Fields{
+				"error":     err.Error(),
+				"mother_id": mother.ID.String(),
+				"weeks":     currentWeeks,
+			})
+			continue
+		}
+		reassessed++
+	}
+
+	m.log.Info("Reassessed mothers at gestation thresholds", logger.Fields{
+		"mothers":    len(mothers),
+		"reassessed": reassessed,
+	})
+
+	return reassessed, nil
+}
+
+// GetAssessmentHistory retrieves a mother's stored risk assessments, most recent first
+func (m *Monitor) GetAssessmentHistory(ctx context.Context, motherID uuid.UUID, limit int) ([]*model.RiskAssessment, error) {
+	assessments, err := m.assessmentRepo.GetByMotherID(ctx, motherID, limit)
+	if err != nil {
+		m.log.Error("Failed to get risk assessments", logger.Fields{
+			"error":     err.Error(),
+			"mother_id": motherID.String(),
+		})
+		return nil, errorx.Wrap(err, "failed to get risk assessments")
+	}
+
+	return assessments, nil
+}
+
+// notifyLevelChange notifies the CHW and clinician assigned to the mother's visits
+func (m *Monitor) notifyLevelChange(ctx context.Context, mother *model.Mother, assessment *model.RiskAssessment) {
+	if m.notifyService == nil {
+		return
+	}
+
+	for _, recipientID := range m.assignedCareTeam(ctx, mother.ID) {
+		if err := m.notifyService.SendRiskLevelChange(ctx, recipientID, mother, assessment); err != nil {
+			m.log.Warn("Failed to send risk level change notification", logger.Fields{
+				"error":        err.Error(),
+				"mother_id":    mother.ID.String(),
+				"recipient_id": recipientID.String(),
+			})
+		}
+	}
+}
+
+// assignedCareTeam returns the CHW and clinician on the mother's most recent active visits
+func (m *Monitor) assignedCareTeam(ctx context.Context, motherID uuid.UUID) []uuid.UUID {
+	options := repository.NewVisitQueryOptions().
+		WithOrder("scheduled_time", "DESC").
+		WithLimit(20)
+
+	visits, err := m.visitRepo.GetByMotherID(ctx, motherID, options)
+	if err != nil {
+		m.log.Warn("Failed to get visits for care team", logger.Fields{
+			"error":     err.Error(),
+			"mother_id": motherID.String(),
+		})
+		return nil
+	}
+
+	var chwID, clinicianID *uuid.UUID
+	for _, visit := range visits {
+		if visit.Status == model.VisitStatusCancelled {
+			continue
+		}
+		if chwID == nil && visit.CHWID != nil {
+			chwID = visit.CHWID
+		}
+		if clinicianID == nil && visit.ClinicianID != nil {
+			clinicianID = visit.ClinicianID
+		}
+	}
+
+	var team []uuid.UUID
+	if chwID != nil {
+		team = append(team, *chwID)
+	}
+	if clinicianID != nil && (chwID == nil || *clinicianID != *chwID) {
+		team = append(team, *clinicianID)
+	}
+	return team
+}
+
+// crossesThreshold checks if any threshold lies in (lastWeeks, currentWeeks]
+func crossesThreshold(lastWeeks, currentWeeks int, thresholds []int) bool {
+	for _, week := range thresholds {
+		if week > lastWeeks && week <= currentWeeks {
+			return true
+		}
+	}
+	return false
+}
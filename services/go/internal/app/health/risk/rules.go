@@ -0,0 +1,360 @@
+package risk
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/mamacare/services/internal/app/health/growth"
+	"github.com/mamacare/services/internal/domain/model"
+	"github.com/mamacare/services/pkg/errorx"
+	"gopkg.in/yaml.v3"
+)
+
+// defaultRuleSet is the built-in rule set used when no rules file is configured
+//
+//go:embed rules/default.yaml
+var defaultRuleSet []byte
+
+// Vital sign metrics that vitals rules can test
+const (
+	MetricSystolic       = "systolic"
+	MetricDiastolic      = "diastolic"
+	MetricFetalHeartRate = "fetal_heart_rate"
+	MetricHemoglobin     = "hemoglobin"
+	MetricBloodSugar     = "blood_sugar"
+	MetricWeight         = "weight"
+	MetricUrineProtein   = "urine_protein" // dipstick grade, 0 for negative or trace
+)
+
+// Pregnancy history fields that obstetric history rules can test
+const (
+	FieldPreviousPregnancies = "previous_pregnancies"
+	FieldPreviousDeliveries  = "previous_deliveries"
+	FieldPreviousCaesareans  = "previous_caesareans"
+)
+
+// Rule categories recorded against fired rules
+const (
+	CategoryAge              = "age"
+	CategoryCondition        = "condition"
+	CategoryBloodType        = "blood_type"
+	CategoryObstetricHistory = "obstetric_history"
+	CategoryComplication     = "complication"
+	CategoryVitals           = "vitals"
+	CategoryGestation        = "gestation"
+	CategoryFetalGrowth      = "fetal_growth"
+)
+
+// RuleSet is a versioned set of risk scoring rules that clinical leads maintain
+// as YAML or JSON
+type RuleSet struct {
+	Version          string          `json:"version" yaml:"version"`
+	Name             string          `json:"name" yaml:"name"`
+	EffectiveFrom    string          `json:"effective_from,omitempty" yaml:"effective_from,omitempty"`
+	Cutoffs          Cutoffs         `json:"cutoffs" yaml:"cutoffs"`
+	AgeBands         []AgeBandRule   `json:"age_bands,omitempty" yaml:"age_bands,omitempty"`
+	Conditions       []MatchRule     `json:"conditions,omitempty" yaml:"conditions,omitempty"`
+	BloodTypes       []BloodTypeRule `json:"blood_types,omitempty" yaml:"blood_types,omitempty"`
+	ObstetricHistory []ObstetricRule `json:"obstetric_history,omitempty" yaml:"obstetric_history,omitempty"`
+	Complications    []MatchRule     `json:"complications,omitempty" yaml:"complications,omitempty"`
+	Vitals           []VitalRule     `json:"vitals,omitempty" yaml:"vitals,omitempty"`
+	Gestation        []GestationRule `json:"gestation,omitempty" yaml:"gestation,omitempty"`
+	FetalGrowth      []GrowthRule    `json:"fetal_growth,omitempty" yaml:"fetal_growth,omitempty"`
+}
+
+// Cutoffs are the total scores at or above which a mother is medium or high risk
+type Cutoffs struct {
+	Medium int `json:"medium" yaml:"medium"`
+	High   int `json:"high" yaml:"high"`
+}
+
+// AgeBandRule scores maternal age within an inclusive range
+type AgeBandRule struct {
+	ID     string `json:"id" yaml:"id"`
+	MinAge *int   `json:"min_age,omitempty" yaml:"min_age,omitempty"`
+	MaxAge *int   `json:"max_age,omitempty" yaml:"max_age,omitempty"`
+	Weight int    `json:"weight" yaml:"weight"`
+	Factor string `json:"factor" yaml:"factor"`
+}
+
+// MatchRule scores a recorded health condition or previous complication by name
+type MatchRule struct {
+	ID     string `json:"id" yaml:"id"`
+	Match  string `json:"match" yaml:"match"`
+	Weight int    `json:"weight" yaml:"weight"`
+	Factor string `json:"factor,omitempty" yaml:"factor,omitempty"` // defaults to the matched name
+}
+
+// BloodTypeRule scores the mother's blood type
+type BloodTypeRule struct {
+	ID         string            `json:"id" yaml:"id"`
+	BloodTypes []model.BloodType `json:"blood_types" yaml:"blood_types"`
+	Weight     int               `json:"weight" yaml:"weight"`
+	Factor     string            `json:"factor" yaml:"factor"`
+}
+
+// ObstetricRule scores a count from the pregnancy history within an inclusive range
+type ObstetricRule struct {
+	ID    string `json:"id" yaml:"id"`
+	Field string `json:"field" yaml:"field"`
+	Min   *int   `json:"min,omitempty" yaml:"min,omitempty"`
+	Max   *int   `json:"max,omitempty" yaml:"max,omitempty"`
+	// PerUnit multiplies the weight by the count, e.g. per previous caesarean
+	PerUnit bool   `json:"per_unit,omitempty" yaml:"per_unit,omitempty"`
+	Weight  int    `json:"weight" yaml:"weight"`
+	Factor  string `json:"factor" yaml:"factor"`
+}
+
+// Threshold compares a vital sign metric against a value
+type Threshold struct {
+	Metric string  `json:"metric" yaml:"metric"`
+	Op     string  `json:"op" yaml:"op"` // lt, lte, gt or gte
+	Value  float64 `json:"value" yaml:"value"`
+}
+
+// VitalRule scores the latest vital signs. It fires if any threshold in Any is met and
+// every threshold in All is met, so All can bound a band such as 7 <= Hb < 11. It can be
+// limited to a gestational age range.
+type VitalRule struct {
+	ID                string      `json:"id" yaml:"id"`
+	Any               []Threshold `json:"any,omitempty" yaml:"any,omitempty"`
+	All               []Threshold `json:"all,omitempty" yaml:"all,omitempty"`
+	MinGestationWeeks *int        `json:"min_gestation_weeks,omitempty" yaml:"min_gestation_weeks,omitempty"`
+	MaxGestationWeeks *int        `json:"max_gestation_weeks,omitempty" yaml:"max_gestation_weeks,omitempty"`
+	Weight            int         `json:"weight" yaml:"weight"`
+	Factor            string      `json:"factor" yaml:"factor"`
+}
+
+// GestationRule scores the current gestational age within an inclusive range
+type GestationRule struct {
+	ID       string `json:"id" yaml:"id"`
+	MinWeeks *int   `json:"min_weeks,omitempty" yaml:"min_weeks,omitempty"`
+	MaxWeeks *int   `json:"max_weeks,omitempty" yaml:"max_weeks,omitempty"`
+	Weight   int    `json:"weight" yaml:"weight"`
+	Factor   string `json:"factor" yaml:"factor"`
+}
+
+// GrowthRule scores a fetal growth flag raised on the fundal height or fetal weight charts
+type GrowthRule struct {
+	ID     string      `json:"id" yaml:"id"`
+	Flag   growth.Flag `json:"flag" yaml:"flag"`
+	Weight int         `json:"weight" yaml:"weight"`
+	Factor string      `json:"factor" yaml:"factor"`
+}
+
+// DefaultRuleSet returns the built-in rule set
+func DefaultRuleSet() *RuleSet {
+	rules, err := ParseRuleSet(defaultRuleSet)
+	if err != nil {
+		// The embedded rule set is part of the build, so this is a programming error
+		panic("invalid built-in risk rule set: " + err.Error())
+	}
+	return rules
+}
+
+// LoadRuleSetFile reads and validates a rule set from a YAML or JSON file
+func LoadRuleSetFile(path string) (*RuleSet, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, errorx.Wrap(err, errorx.InternalServerError, "failed to read risk rules file")
+	}
+
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		return parseRuleSetJSON(data)
+	}
+	return ParseRuleSet(data)
+}
+
+// ParseRuleSet parses and validates a rule set. JSON documents are detected
+// by a leading brace; anything else is read as YAML.
+func ParseRuleSet(data []byte) (*RuleSet, error) {
+	if bytes.HasPrefix(bytes.TrimSpace(data), []byte("{")) {
+		return parseRuleSetJSON(data)
+	}
+
+	var rules RuleSet
+	if err := yaml.Unmarshal(data, &rules); err != nil {
+		return nil, errorx.Wrap(err, errorx.BadRequest, "invalid risk rule set YAML")
+	}
+	if err := rules.Validate(); err != nil {
+		return nil, err
+	}
+	return &rules, nil
+}
+
+// parseRuleSetJSON parses and validates a JSON rule set
+func parseRuleSetJSON(data []byte) (*RuleSet, error) {
+	var rules RuleSet
+	if err := json.Unmarshal(data, &rules); err != nil {
+		return nil, errorx.Wrap(err, errorx.BadRequest, "invalid risk rule set JSON")
+	}
+	if err := rules.Validate(); err != nil {
+		return nil, err
+	}
+	return &rules, nil
+}
+
+// Validate checks that the rule set is complete and every rule can be evaluated
+func (rs *RuleSet) Validate() error {
+	if strings.TrimSpace(rs.Version) == "" {
+		return errorx.New(errorx.BadRequest, "risk rule set must have a version")
+	}
+	if rs.Cutoffs.Medium <= 0 || rs.Cutoffs.High <= rs.Cutoffs.Medium {
+		return errorx.New(errorx.BadRequest, "risk rule set cutoffs must satisfy 0 < medium < high")
+	}
+
+	ids := make(map[string]bool)
+	checkID := func(id string) error {
+		if id == "" {
+			return errorx.New(errorx.BadRequest, "every risk rule must have an id")
+		}
+		if ids[id] {
+			return errorx.Newf(errorx.BadRequest, "duplicate risk rule id %q", id)
+		}
+		ids[id] = true
+		return nil
+	}
+
+	for _, rule := range rs.AgeBands {
+		if err := checkID(rule.ID); err != nil {
+			return err
+		}
+		if rule.MinAge == nil && rule.MaxAge == nil {
+			return errorx.Newf(errorx.BadRequest, "age band %q needs a min_age or max_age", rule.ID)
+		}
+	}
+	for _, rule := range append(append([]MatchRule{}, rs.Conditions...), rs.Complications...) {
+		if err := checkID(rule.ID); err != nil {
+			return err
+		}
+		if strings.TrimSpace(rule.Match) == "" {
+			return errorx.Newf(errorx.BadRequest, "rule %q needs a match", rule.ID)
+		}
+	}
+	for _, rule := range rs.BloodTypes {
+		if err := checkID(rule.ID); err != nil {
+			return err
+		}
+		if len(rule.BloodTypes) == 0 {
+			return errorx.Newf(errorx.BadRequest, "blood type rule %q needs blood_types", rule.ID)
+		}
+	}
+	for _, rule := range rs.ObstetricHistory {
+		if err := checkID(rule.ID); err != nil {
+			return err
+		}
+		switch rule.Field {
+		case FieldPreviousPregnancies, FieldPreviousDeliveries, FieldPreviousCaesareans:
+		default:
+			return errorx.Newf(errorx.BadRequest, "obstetric rule %q has unknown field %q", rule.ID, rule.Field)
+		}
+		if rule.Min == nil && rule.Max == nil {
+			return errorx.Newf(errorx.BadRequest, "obstetric rule %q needs a min or max", rule.ID)
+		}
+	}
+	for _, rule := range rs.Vitals {
+		if err := checkID(rule.ID); err != nil {
+			return err
+		}
+		if len(rule.Any) == 0 && len(rule.All) == 0 {
+			return errorx.Newf(errorx.BadRequest, "vitals rule %q needs at least one threshold", rule.ID)
+		}
+		for _, threshold := range append(append([]Threshold{}, rule.Any...), rule.All...) {
+			switch threshold.Metric {
+			case MetricSystolic, MetricDiastolic, MetricFetalHeartRate, MetricHemoglobin, MetricBloodSugar, MetricWeight, MetricUrineProtein:
+			default:
+				return errorx.Newf(errorx.BadRequest, "vitals rule %q has unknown metric %q", rule.ID, threshold.Metric)
+			}
+			switch threshold.Op {
+			case "lt", "lte", "gt", "gte":
+			default:
+				return errorx.Newf(errorx.BadRequest, "vitals rule %q has unknown operator %q", rule.ID, threshold.Op)
+			}
+		}
+	}
+	for _, rule := range rs.Gestation {
+		if err := checkID(rule.ID); err != nil {
+			return err
+		}
+		if rule.MinWeeks == nil && rule.MaxWeeks == nil {
+			return errorx.Newf(errorx.BadRequest, "gestation rule %q needs a min_weeks or max_weeks", rule.ID)
+		}
+	}
+	for _, rule := range rs.FetalGrowth {
+		if err := checkID(rule.ID); err != nil {
+			return err
+		}
+		switch rule.Flag {
+		case growth.FlagSmallForGestationalAge, growth.FlagLargeForGestationalAge, growth.FlagFallingCentiles,
+			growth.FlagRisingCentiles, growth.FlagStaticGrowth, growth.FlagMacrosomia:
+		default:
+			return errorx.Newf(errorx.BadRequest, "fetal growth rule %q has unknown flag %q", rule.ID, rule.Flag)
+		}
+	}
+
+	return nil
+}
+
+// inRange checks if a value lies within optional inclusive bounds
+func inRange(value int, min, max *int) bool {
+	if min != nil && value < *min {
+		return false
+	}
+	if max != nil && value > *max {
+		return false
+	}
+	return true
+}
+
+// compare applies a threshold operator
+func compare(value float64, op string, threshold float64) bool {
+	switch op {
+	case "lt":
+		return value < threshold
+	case "lte":
+		return value <= threshold
+	case "gt":
+		return value > threshold
+	case "gte":
+		return value >= threshold
+	}
+	return false
+}
+
+// normalizeName lower-cases and trims a condition or complication name for matching
+func normalizeName(name string) string {
+	return strings.ToLower(strings.TrimSpace(name))
+}
+
+// GestationThresholds returns the gestational weeks at which a rule starts or stops
+// applying, so a mother can be reassessed when her pregnancy crosses one
+func (rs *RuleSet) GestationThresholds() []int {
+	seen := make(map[int]bool)
+	add := func(min, max *int) {
+		if min != nil {
+			seen[*min] = true
+		}
+		if max != nil {
+			seen[*max+1] = true
+		}
+	}
+
+	for _, rule := range rs.Vitals {
+		add(rule.MinGestationWeeks, rule.MaxGestationWeeks)
+	}
+	for _, rule := range rs.Gestation {
+		add(rule.MinWeeks, rule.MaxWeeks)
+	}
+
+	thresholds := make([]int, 0, len(seen))
+	for week := range seen {
+		thresholds = append(thresholds, week)
+	}
+	sort.Ints(thresholds)
+	return thresholds
+}
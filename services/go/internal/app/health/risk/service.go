@@ -1,9 +1,12 @@
 package risk
 
 import (
+	"sort"
+	"sync"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/mamacare/services/internal/app/health/growth"
 	"github.com/mamacare/services/internal/domain/model"
 	"github.com/mamacare/services/pkg/errorx"
 	"github.com/mamacare/services/pkg/logger"
@@ -11,32 +14,92 @@ import (
 
 // Service provides risk calculation functionality for maternal health
 type Service struct {
-	log logger.Logger
+	mu    sync.RWMutex
+	rules *RuleSet
+	log   logger.Logger
 }
 
-// NewService creates a new risk calculation service
-func NewService(log logger.Logger) *Service {
+// NewService creates a new risk calculation service. A nil rule set uses the built-in rules.
+func NewService(rules *RuleSet, log logger.Logger) *Service {
+	if rules == nil {
+		rules = DefaultRuleSet()
+	}
 	return &Service{
-		log: log,
+		rules: rules,
+		log:   log,
 	}
 }
 
-// RiskFactors represents identified risk factors for a mother
-type RiskFactors struct {
-	AgeRelated       []string `json:"age_related,omitempty"`
-	MedicalHistory   []string `json:"medical_history,omitempty"`
-	ObstetricHistory []string `json:"obstetric_history,omitempty"`
-	CurrentVitals    []string `json:"current_vitals,omitempty"`
-	Lifestyle        []string `json:"lifestyle,omitempty"`
+// LoadService creates the risk service at startup with the rules file configured as
+// risk.rules_file. The built-in rules are used when no file is configured, or if the file
+// cannot be read or is invalid, so a bad edit cannot stop risk scoring.
+func LoadService(rulesFile string, log logger.Logger) *Service {
+	s := NewService(nil, log)
+	if rulesFile == "" {
+		return s
+	}
+
+	rules, err := LoadRuleSetFile(rulesFile)
+	if err == nil {
+		err = s.SetRuleSet(rules)
+	}
+	if err != nil {
+		log.Error("Failed to load risk rules file, using the built-in rules", logger.Fields{
+			"error":      err.Error(),
+			"rules_file": rulesFile,
+			"version":    s.RuleSet().Version,
+		})
+	}
+
+	return s
 }
 
+// RiskFactors represents identified risk factors for a mother
+type RiskFactors = model.RiskFactors
+
+// FiredRule records a rule that contributed to a risk score
+type FiredRule = model.FiredRule
+
 // RiskAssessment represents the result of a risk assessment
 type RiskAssessment struct {
-	MotherID    uuid.UUID   `json:"mother_id"`
-	RiskLevel   model.RiskLevel `json:"risk_level"`
-	RiskScore   int         `json:"risk_score"`
-	RiskFactors RiskFactors `json:"risk_factors"`
-	AssessedAt  time.Time   `json:"assessed_at"`
+	MotherID            uuid.UUID       `json:"mother_id"`
+	RiskLevel           model.RiskLevel `json:"risk_level"`
+	RiskScore           int             `json:"risk_score"`
+	RiskFactors         RiskFactors     `json:"risk_factors"`
+	RuleSetVersion      string          `json:"rule_set_version"`
+	FiredRules          []FiredRule     `json:"fired_rules"`
+	MaternalAge         *int            `json:"maternal_age,omitempty"`
+	GestationalAgeWeeks *int            `json:"gestational_age_weeks,omitempty"`
+	AssessedAt          time.Time       `json:"assessed_at"`
+}
+
+// RuleSet returns the rule set currently used for scoring
+func (s *Service) RuleSet() *RuleSet {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.rules
+}
+
+// SetRuleSet validates and switches to a new rule set, e.g. after clinical leads publish an update
+func (s *Service) SetRuleSet(rules *RuleSet) error {
+	if rules == nil {
+		return errorx.New(errorx.BadRequest, "risk rule set required")
+	}
+	if err := rules.Validate(); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	previous := s.rules.Version
+	s.rules = rules
+	s.mu.Unlock()
+
+	s.log.Info("Risk rule set updated", logger.Fields{
+		"previous_version": previous,
+		"version":          rules.Version,
+	})
+
+	return nil
 }
 
 // CalculateRisk performs a comprehensive risk assessment for a mother
@@ -45,8 +108,11 @@ func (s *Service) CalculateRisk(mother *model.Mother, recentMetrics []*model.Hea
 		return nil, errorx.New(errorx.BadRequest, "mother data required for risk assessment")
 	}
 
+	rules := s.RuleSet()
+	now := time.Now()
+
 	assessment := &RiskAssessment{
-		MotherID:   mother.ID,
+		MotherID: mother.ID,
 		RiskFactors: RiskFactors{
 			AgeRelated:       []string{},
 			MedicalHistory:   []string{},
@@ -54,238 +120,329 @@ func (s *Service) CalculateRisk(mother *model.Mother, recentMetrics []*model.Hea
 			CurrentVitals:    []string{},
 			Lifestyle:        []string{},
 		},
-		AssessedAt: time.Now(),
+		RuleSetVersion: rules.Version,
+		FiredRules:     []FiredRule{},
+		AssessedAt:     now,
+	}
+
+	if age, ok := mother.AgeAt(now); ok {
+		assessment.MaternalAge = &age
+	}
+	if !mother.IsPostpartum() {
+		weeks := mother.GetWeeksPregnant(now)
+		assessment.GestationalAgeWeeks = &weeks
 	}
 
 	// Calculate risk score based on various factors
 	score := 0
 
 	// Age-related risk factors
-	score += s.evaluateAgeRisk(mother, assessment)
+	score += s.evaluateAgeRisk(rules, assessment)
 
 	// Medical history risk factors
-	score += s.evaluateMedicalHistoryRisk(mother, assessment)
+	score += s.evaluateMedicalHistoryRisk(rules, mother, assessment)
 
 	// Obstetric history risk factors
-	score += s.evaluateObstetricHistoryRisk(mother, assessment)
+	score += s.evaluateObstetricHistoryRisk(rules, mother, assessment)
 
 	// Current health metrics risk factors
-	score += s.evaluateCurrentHealthRisk(mother, recentMetrics, assessment)
+	score += s.evaluateCurrentHealthRisk(rules, recentMetrics, assessment)
+
+	// Gestation-dependent risk factors
+	score += s.evaluateGestationRisk(rules, assessment)
+
+	// Fetal growth from fundal height and estimated fetal weight charts
+	score += s.evaluateFetalGrowthRisk(rules, mother, recentMetrics, assessment)
 
 	// Set final risk score and risk level
 	assessment.RiskScore = score
-	assessment.RiskLevel = s.determineRiskLevel(score)
+	assessment.RiskLevel = s.determineRiskLevel(rules, score)
 
 	return assessment, nil
 }
 
 // evaluateAgeRisk assesses risks based on maternal age
-func (s *Service) evaluateAgeRisk(mother *model.Mother, assessment *RiskAssessment) int {
-	// TODO: Get mother's age from user profile
-	// For now, we'll use a placeholder implementation
-	
-	// Example age-related risk factors:
-	// - Age < 18: Teenage pregnancy
-	// - Age > 35: Advanced maternal age
+func (s *Service) evaluateAgeRisk(rules *RuleSet, assessment *RiskAssessment) int {
+	// Without a date of birth there is nothing to score
+	if assessment.MaternalAge == nil {
+		return 0
+	}
 
 	riskScore := 0
-	
-	// For MVP, we'll return a placeholder score
-	// In a complete implementation, we would calculate based on actual age
-	
+	for _, rule := range rules.AgeBands {
+		if inRange(*assessment.MaternalAge, rule.MinAge, rule.MaxAge) {
+			riskScore += assessment.fire(rule.ID, CategoryAge, rule.Factor, rule.Weight)
+			assessment.RiskFactors.AgeRelated = append(assessment.RiskFactors.AgeRelated, rule.Factor)
+		}
+	}
+
 	return riskScore
 }
 
 // evaluateMedicalHistoryRisk assesses risks based on pre-existing health conditions
-func (s *Service) evaluateMedicalHistoryRisk(mother *model.Mother, assessment *RiskAssessment) int {
+func (s *Service) evaluateMedicalHistoryRisk(rules *RuleSet, mother *model.Mother, assessment *RiskAssessment) int {
 	riskScore := 0
-	
-	// Check for high-risk health conditions
-	highRiskConditions := map[string]int{
-		"diabetes":           3,
-		"hypertension":       3,
-		"heart disease":      4,
-		"kidney disease":     3,
-		"thyroid disorder":   2,
-		"autoimmune disease": 2,
-		"hiv":                3,
-		"hepatitis":          2,
-		"malaria":            2,
-		"anemia":             2,
-		"sickle cell":        3,
-	}
-	
+
 	for _, condition := range mother.HealthConditions {
-		if score, exists := highRiskConditions[condition]; exists {
-			riskScore += score
-			assessment.RiskFactors.MedicalHistory = append(
-				assessment.RiskFactors.MedicalHistory,
-				condition,
-			)
+		for _, rule := range rules.Conditions {
+			if normalizeName(condition) != normalizeName(rule.Match) {
+				continue
+			}
+			factor := rule.Factor
+			if factor == "" {
+				factor = condition
+			}
+			riskScore += assessment.fire(rule.ID, CategoryCondition, factor, rule.Weight)
+			assessment.RiskFactors.MedicalHistory = append(assessment.RiskFactors.MedicalHistory, factor)
 		}
 	}
-	
-	// Rh factor incompatibility check
-	if mother.BloodType == model.BloodTypeANeg || 
-	   mother.BloodType == model.BloodTypeBNeg || 
-	   mother.BloodType == model.BloodTypeABNeg || 
-	   mother.BloodType == model.BloodTypeONeg {
-		riskScore += 2
-		assessment.RiskFactors.MedicalHistory = append(
-			assessment.RiskFactors.MedicalHistory,
-			"rh negative blood type",
-		)
-	}
-	
+
+	// Blood type, e.g. Rh factor incompatibility
+	for _, rule := range rules.BloodTypes {
+		for _, bloodType := range rule.BloodTypes {
+			if mother.BloodType == bloodType {
+				riskScore += assessment.fire(rule.ID, CategoryBloodType, rule.Factor, rule.Weight)
+				assessment.RiskFactors.MedicalHistory = append(assessment.RiskFactors.MedicalHistory, rule.Factor)
+				break
+			}
+		}
+	}
+
 	return riskScore
 }
 
 // evaluateObstetricHistoryRisk assesses risks based on previous pregnancies
-func (s *Service) evaluateObstetricHistoryRisk(mother *model.Mother, assessment *RiskAssessment) int {
+func (s *Service) evaluateObstetricHistoryRisk(rules *RuleSet, mother *model.Mother, assessment *RiskAssessment) int {
 	riskScore := 0
 	history := mother.PregnancyHistory
-	
-	// Previous cesarean sections
-	if history.PreviousCaesareans > 0 {
-		riskScore += history.PreviousCaesareans
-		assessment.RiskFactors.ObstetricHistory = append(
-			assessment.RiskFactors.ObstetricHistory,
-			"previous cesarean delivery",
-		)
-	}
-	
-	// Grand multiparity (5+ previous deliveries)
-	if history.PreviousDeliveries >= 5 {
-		riskScore += 2
-		assessment.RiskFactors.ObstetricHistory = append(
-			assessment.RiskFactors.ObstetricHistory,
-			"grand multiparity",
-		)
-	}
-	
-	// No previous pregnancies (nulliparity)
-	if history.PreviousPregnancies == 0 {
-		riskScore += 1
-		assessment.RiskFactors.ObstetricHistory = append(
-			assessment.RiskFactors.ObstetricHistory,
-			"first pregnancy",
-		)
-	}
-	
+
+	for _, rule := range rules.ObstetricHistory {
+		var count int
+		switch rule.Field {
+		case FieldPreviousPregnancies:
+			count = history.PreviousPregnancies
+		case FieldPreviousDeliveries:
+			count = history.PreviousDeliveries
+		case FieldPreviousCaesareans:
+			count = history.PreviousCaesareans
+		}
+
+		if !inRange(count, rule.Min, rule.Max) {
+			continue
+		}
+
+		points := rule.Weight
+		if rule.PerUnit {
+			points *= count
+		}
+		riskScore += assessment.fire(rule.ID, CategoryObstetricHistory, rule.Factor, points)
+		assessment.RiskFactors.ObstetricHistory = append(assessment.RiskFactors.ObstetricHistory, rule.Factor)
+	}
+
 	// Previous pregnancy complications
-	complicationRisks := map[string]int{
-		"preeclampsia":        3,
-		"eclampsia":           4,
-		"gestational diabetes": 3,
-		"preterm birth":       3,
-		"placenta previa":     3,
-		"placental abruption": 4,
-		"postpartum hemorrhage": 3,
-		"stillbirth":          4,
-		"miscarriage":         2,
-	}
-	
 	for _, complication := range history.PreviousComplications {
-		if score, exists := complicationRisks[complication]; exists {
-			riskScore += score
-			assessment.RiskFactors.ObstetricHistory = append(
-				assessment.RiskFactors.ObstetricHistory,
-				"history of "+complication,
-			)
+		for _, rule := range rules.Complications {
+			if normalizeName(complication) != normalizeName(rule.Match) {
+				continue
+			}
+			factor := rule.Factor
+			if factor == "" {
+				factor = "history of " + complication
+			}
+			riskScore += assessment.fire(rule.ID, CategoryComplication, factor, rule.Weight)
+			assessment.RiskFactors.ObstetricHistory = append(assessment.RiskFactors.ObstetricHistory, factor)
 		}
 	}
-	
+
 	return riskScore
 }
 
 // evaluateCurrentHealthRisk assesses risks based on current health metrics
-func (s *Service) evaluateCurrentHealthRisk(mother *model.Mother, metrics []*model.HealthMetric, assessment *RiskAssessment) int {
+func (s *Service) evaluateCurrentHealthRisk(rules *RuleSet, metrics []*model.HealthMetric, assessment *RiskAssessment) int {
 	riskScore := 0
-	
+
 	if len(metrics) == 0 {
 		return riskScore
 	}
-	
-	// Use the most recent health metric
-	latestMetric := metrics[0]
-	
-	// Check blood pressure
-	if latestMetric.VitalSigns.BloodPressure != nil {
-		bp := latestMetric.VitalSigns.BloodPressure
-		
-		// Check for hypertension
-		if bp.Systolic >= 140 || bp.Diastolic >= 90 {
-			riskScore += 3
-			assessment.RiskFactors.CurrentVitals = append(
-				assessment.RiskFactors.CurrentVitals,
-				"elevated blood pressure",
-			)
+
+	// A metric row often records only some vitals, so each vital is taken from the most
+	// recent row that recorded it
+	vitals := latestVitals(metrics, assessment.AssessedAt.AddDate(0, 0, -recentVitalsDays))
+
+	for _, rule := range rules.Vitals {
+		// Gestation-dependent thresholds only apply within their window
+		if rule.MinGestationWeeks != nil || rule.MaxGestationWeeks != nil {
+			if assessment.GestationalAgeWeeks == nil ||
+				!inRange(*assessment.GestationalAgeWeeks, rule.MinGestationWeeks, rule.MaxGestationWeeks) {
+				continue
+			}
+		}
+
+		if vitalRuleMatches(rule, vitals) {
+			riskScore += assessment.fire(rule.ID, CategoryVitals, rule.Factor, rule.Weight)
+			assessment.RiskFactors.CurrentVitals = append(assessment.RiskFactors.CurrentVitals, rule.Factor)
+		}
+	}
+
+	return riskScore
+}
+
+// recentVitalsDays is how far back a vital sign can have been recorded and still be scored
+const recentVitalsDays = 28
+
+// latestVitals combines the most recent value of each vital sign recorded since a time.
+// A blood sugar keeps the timing recorded with it.
+func latestVitals(metrics []*model.HealthMetric, since time.Time) model.VitalSigns {
+	recent := make([]*model.HealthMetric, 0, len(metrics))
+	for _, metric := range metrics {
+		if !metric.RecordedAt.Before(since) {
+			recent = append(recent, metric)
 		}
-		
-		// Check for hypotension
-		if bp.Systolic < 90 || bp.Diastolic < 60 {
-			riskScore += 2
-			assessment.RiskFactors.CurrentVitals = append(
-				assessment.RiskFactors.CurrentVitals,
-				"low blood pressure",
-			)
+	}
+	sort.SliceStable(recent, func(i, j int) bool {
+		return recent[i].RecordedAt.After(recent[j].RecordedAt)
+	})
+
+	var vitals model.VitalSigns
+	latest := func(current, value *float64) *float64 {
+		if current != nil {
+			return current
 		}
+		return value
 	}
-	
-	// Check fetal heart rate
-	if latestMetric.VitalSigns.FetalHeartRate != nil {
-		fhr := *latestMetric.VitalSigns.FetalHeartRate
-		
-		if fhr < 110 || fhr > 160 {
-			riskScore += 3
-			assessment.RiskFactors.CurrentVitals = append(
-				assessment.RiskFactors.CurrentVitals,
-				"abnormal fetal heart rate",
-			)
+
+	for _, metric := range recent {
+		v := metric.VitalSigns
+		if vitals.BloodPressure == nil {
+			vitals.BloodPressure = v.BloodPressure
+		}
+		if vitals.BloodSugar == nil && v.BloodSugar != nil {
+			vitals.BloodSugar = v.BloodSugar
+			vitals.BloodSugarTiming = v.BloodSugarTiming
+		}
+		if vitals.UrineProtein == nil && v.UrineProtein != nil && v.UrineProtein.IsValid() {
+			vitals.UrineProtein = v.UrineProtein
 		}
+		vitals.FetalHeartRate = latest(vitals.FetalHeartRate, v.FetalHeartRate)
+		vitals.FetalMovement = latest(vitals.FetalMovement, v.FetalMovement)
+		vitals.HemoglobinLevel = latest(vitals.HemoglobinLevel, v.HemoglobinLevel)
+		vitals.IronLevel = latest(vitals.IronLevel, v.IronLevel)
+		vitals.Weight = latest(vitals.Weight, v.Weight)
+		vitals.FundalHeightCm = latest(vitals.FundalHeightCm, v.FundalHeightCm)
+		vitals.EstimatedFetalWeight = latest(vitals.EstimatedFetalWeight, v.EstimatedFetalWeight)
+		vitals.MUACCm = latest(vitals.MUACCm, v.MUACCm)
 	}
-	
-	// Check hemoglobin (anemia)
-	if latestMetric.VitalSigns.HemoglobinLevel != nil {
-		hemoglobin := *latestMetric.VitalSigns.HemoglobinLevel
-		
-		if hemoglobin < 11 {
-			riskScore += 2
-			assessment.RiskFactors.CurrentVitals = append(
-				assessment.RiskFactors.CurrentVitals,
-				"anemia",
-			)
+	return vitals
+}
+
+// vitalRuleMatches reports whether any of a rule's Any thresholds and all of its All
+// thresholds are met. A threshold on a metric that was not recorded is not met.
+func vitalRuleMatches(rule VitalRule, vitals model.VitalSigns) bool {
+	for _, threshold := range rule.All {
+		value, ok := vitalValue(vitals, threshold.Metric)
+		if !ok || !compare(value, threshold.Op, threshold.Value) {
+			return false
 		}
-		
-		if hemoglobin < 7 {
-			riskScore += 3 // Severe anemia is higher risk
+	}
+	if len(rule.Any) == 0 {
+		return true
+	}
+	for _, threshold := range rule.Any {
+		value, ok := vitalValue(vitals, threshold.Metric)
+		if ok && compare(value, threshold.Op, threshold.Value) {
+			return true
 		}
 	}
-	
-	// Check blood sugar
-	if latestMetric.VitalSigns.BloodSugar != nil {
-		bloodSugar := *latestMetric.VitalSigns.BloodSugar
-		
-		if bloodSugar > 95 { // Fasting blood sugar
-			riskScore += 2
-			assessment.RiskFactors.CurrentVitals = append(
-				assessment.RiskFactors.CurrentVitals,
-				"elevated blood sugar",
-			)
+	return false
+}
+
+// evaluateGestationRisk assesses risks based on the current gestational age
+func (s *Service) evaluateGestationRisk(rules *RuleSet, assessment *RiskAssessment) int {
+	if assessment.GestationalAgeWeeks == nil {
+		return 0
+	}
+
+	riskScore := 0
+	for _, rule := range rules.Gestation {
+		if inRange(*assessment.GestationalAgeWeeks, rule.MinWeeks, rule.MaxWeeks) {
+			riskScore += assessment.fire(rule.ID, CategoryGestation, rule.Factor, rule.Weight)
+			assessment.RiskFactors.ObstetricHistory = append(assessment.RiskFactors.ObstetricHistory, rule.Factor)
+		}
+	}
+
+	return riskScore
+}
+
+// evaluateFetalGrowthRisk assesses risks from the fetal growth charts of a current pregnancy
+func (s *Service) evaluateFetalGrowthRisk(rules *RuleSet, mother *model.Mother, metrics []*model.HealthMetric, assessment *RiskAssessment) int {
+	if len(rules.FetalGrowth) == 0 || mother.IsPostpartum() || len(metrics) == 0 {
+		return 0
+	}
+
+	fetalGrowth := growth.Assess(mother, metrics, assessment.AssessedAt)
+
+	riskScore := 0
+	for _, rule := range rules.FetalGrowth {
+		if fetalGrowth.HasFlag(rule.Flag) {
+			riskScore += assessment.fire(rule.ID, CategoryFetalGrowth, rule.Factor, rule.Weight)
+			assessment.RiskFactors.CurrentVitals = append(assessment.RiskFactors.CurrentVitals, rule.Factor)
 		}
 	}
-	
+
 	return riskScore
 }
 
 // determineRiskLevel converts a risk score to a risk level
-func (s *Service) determineRiskLevel(score int) model.RiskLevel {
+func (s *Service) determineRiskLevel(rules *RuleSet, score int) model.RiskLevel {
 	switch {
-	case score >= 10:
+	case score >= rules.Cutoffs.High:
 		return model.RiskLevelHigh
-	case score >= 5:
+	case score >= rules.Cutoffs.Medium:
 		return model.RiskLevelMedium
 	default:
 		return model.RiskLevelLow
 	}
 }
+
+// fire records a rule that fired and returns the points it contributed
+func (a *RiskAssessment) fire(id, category, factor string, points int) int {
+	a.FiredRules = append(a.FiredRules, FiredRule{
+		ID:       id,
+		Category: category,
+		Factor:   factor,
+		Points:   points,
+	})
+	return points
+}
+
+// vitalValue returns the value of a vital sign metric if it was recorded
+func vitalValue(vitals model.VitalSigns, metric string) (float64, bool) {
+	var value *float64
+	switch metric {
+	case MetricSystolic:
+		if vitals.BloodPressure != nil {
+			return vitals.BloodPressure.Systolic, true
+		}
+	case MetricDiastolic:
+		if vitals.BloodPressure != nil {
+			return vitals.BloodPressure.Diastolic, true
+		}
+	case MetricFetalHeartRate:
+		value = vitals.FetalHeartRate
+	case MetricHemoglobin:
+		value = vitals.HemoglobinLevel
+	case MetricBloodSugar:
+		value = vitals.BloodSugar
+	case MetricWeight:
+		value = vitals.Weight
+	case MetricUrineProtein:
+		if vitals.UrineProtein != nil && vitals.UrineProtein.IsValid() {
+			return float64(vitals.UrineProtein.Grade()), true
+		}
+	}
+
+	if value == nil {
+		return 0, false
+	}
+	return *value, true
+}
@@ -0,0 +1,375 @@
+package screener
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mamacare/services/internal/app/geo/facility"
+	"github.com/mamacare/services/internal/domain/model"
+	"github.com/mamacare/services/pkg/errorx"
+	"github.com/mamacare/services/pkg/logger"
+)
+
+// ActionType is what the system does about a red screening
+type ActionType string
+
+const (
+	// ActionSOS raises an SOS so the emergency flow dispatches help
+	ActionSOS ActionType = "sos"
+	// ActionReferral refers the mother or child to the nearest appropriate facility
+	ActionReferral ActionType = "referral"
+	// ActionFollowUpVisit schedules an urgent follow-up visit
+	ActionFollowUpVisit ActionType = "follow_up_visit"
+	// ActionNone takes no automatic action
+	ActionNone ActionType = "none"
+)
+
+// IsValid checks if the action type is known
+func (a ActionType) IsValid() bool {
+	switch a {
+	case ActionSOS, ActionReferral, ActionFollowUpVisit, ActionNone:
+		return true
+	}
+	return false
+}
+
+// actionFallbacks are tried in order when an action cannot be taken, e.g. an SOS for a
+// screening with no known location still gets a follow-up visit
+var actionFallbacks = map[ActionType][]ActionType{
+	ActionSOS:      {ActionReferral, ActionFollowUpVisit},
+	ActionReferral: {ActionFollowUpVisit},
+}
+
+// ActionPolicy configures what is done automatically when a screening is completed red
+type ActionPolicy struct {
+	// Default is the action for a red screening
+	Default ActionType
+	// ByConcern overrides the default for a screening's primary concern, such as an SOS
+	// for bleeding. Concerns are matched ignoring case.
+	ByConcern map[string]ActionType
+	// ReferralTypes are the facility types a red screening can be referred to
+	ReferralTypes []model.FacilityType
+	// ReferralRadiusKm is how far to look for a referral facility
+	ReferralRadiusKm float64
+	// FollowUpAfter is how soon an urgent follow-up visit is scheduled
+	FollowUpAfter time.Duration
+}
+
+// DefaultActionPolicy returns the default policy: an SOS for every red screening, with
+// referral to a hospital within 50 km and a visit within 4 hours as fallbacks
+func DefaultActionPolicy() ActionPolicy {
+	return ActionPolicy{
+		Default:          ActionSOS,
+		ByConcern:        map[string]ActionType{},
+		ReferralTypes:    []model.FacilityType{model.FacilityTypeHospital},
+		ReferralRadiusKm: 50,
+		FollowUpAfter:    4 * time.Hour,
+	}
+}
+
+// Validate checks the policy's actions and limits
+func (p ActionPolicy) Validate() error {
+	if !p.Default.IsValid() {
+		return errorx.Newf(errorx.BadRequest, "invalid screener action: %s", p.Default)
+	}
+	for concern, action := range p.ByConcern {
+		if !action.IsValid() {
+			return errorx.Newf(errorx.BadRequest, "invalid screener action for %s: %s", concern, action)
+		}
+	}
+	if p.ReferralRadiusKm <= 0 {
+		return errorx.New(errorx.BadRequest, "referral radius must be positive")
+	}
+	if p.FollowUpAfter <= 0 {
+		return errorx.New(errorx.BadRequest, "follow-up delay must be positive")
+	}
+	return nil
+}
+
+// NewActionPolicy builds an action policy from configuration, keeping the defaults for
+// settings left empty
+func NewActionPolicy(redAction string, byConcern map[string]string, referralTypes []string, radiusKm float64, followUpAfter time.Duration) (ActionPolicy, error) {
+	policy := DefaultActionPolicy()
+	if redAction != "" {
+		policy.Default = ActionType(strings.ToLower(redAction))
+	}
+	for concern, action := range byConcern {
+		policy.ByConcern[concern] = ActionType(strings.ToLower(action))
+	}
+	if len(referralTypes) > 0 {
+		policy.ReferralTypes = make([]model.FacilityType, 0, len(referralTypes))
+		for _, facilityType := range referralTypes {
+			policy.ReferralTypes = append(policy.ReferralTypes, model.FacilityType(strings.ToLower(facilityType)))
+		}
+	}
+	if radiusKm > 0 {
+		policy.ReferralRadiusKm = radiusKm
+	}
+	if followUpAfter > 0 {
+		policy.FollowUpAfter = followUpAfter
+	}
+
+	if err := policy.Validate(); err != nil {
+		return ActionPolicy{}, err
+	}
+	return policy, nil
+}
+
+// ActionFor returns the action the policy sets for a screening's primary concern
+func (p ActionPolicy) ActionFor(concern string) ActionType {
+	for key, action := range p.ByConcern {
+		if strings.EqualFold(key, concern) {
+			return action
+		}
+	}
+	return p.Default
+}
+
+// SOSService defines the interface to the SOS flow used for red screenings
+type SOSService interface {
+	// ReportSOSEvent reports a new SOS event
+	ReportSOSEvent(
+		ctx context.Context,
+		motherID uuid.UUID,
+		reportedByID uuid.UUID,
+		lat, lng float64,
+		nature model.SOSEventNature,
+		description string,
+	) (*model.SOSEvent, error)
+}
+
+// FacilityFinder defines the interface to the facility search used for referrals
+type FacilityFinder interface {
+	// FindNearbyFacilities finds healthcare facilities near a location, nearest first
+	FindNearbyFacilities(
+		ctx context.Context,
+		lat, lng float64,
+		radiusKm float64,
+		filter *facility.FacilityFilter,
+	) ([]facility.FacilityWithDistance, error)
+}
+
+// VisitScheduler defines the interface to the visit scheduler used for urgent follow-ups
+type VisitScheduler interface {
+	// ScheduleVisit schedules a new visit for a mother
+	ScheduleVisit(
+		ctx context.Context,
+		motherID uuid.UUID,
+		facilityID uuid.UUID,
+		scheduledTime time.Time,
+		visitType model.VisitType,
+		notes string,
+	) (*model.Visit, error)
+}
+
+// act takes the policy's action for a completed red screening and records what was done
+// on the result. An action that cannot be taken falls back to the next; if none can, the
+// reason is recorded so a health worker can follow up by hand.
+func (s *Service) act(ctx context.Context, result *model.ScreenerResult) {
+	action := s.policy.ActionFor(result.PrimaryConcern)
+	if action == ActionNone {
+		result.ActionTaken = string(ActionNone) + ": automatic action disabled by policy"
+		return
+	}
+
+	var reasons []string
+	for _, next := range append([]ActionType{action}, actionFallbacks[action]...) {
+		var err error
+		switch next {
+		case ActionSOS:
+			err = s.raiseSOS(ctx, result)
+		case ActionReferral:
+			err = s.refer(ctx, result)
+		case ActionFollowUpVisit:
+			err = s.scheduleFollowUp(ctx, result)
+		}
+		if err == nil {
+			s.log.Info("Acted on red screening", logger.Fields{
+				"result_id": result.ID.String(),
+				"action":    result.ActionTaken,
+			})
+			return
+		}
+
+		s.log.Warn("Failed to act on red screening", logger.Fields{
+			"error":     err.Error(),
+			"result_id": result.ID.String(),
+			"action":    string(next),
+		})
+		reasons = append(reasons, fmt.Sprintf("%s failed (%s)", next, err.Error()))
+	}
+
+	result.ActionTaken = string(ActionNone) + ": " + strings.Join(reasons, "; ")
+}
+
+// raiseSOS raises an SOS for the screened mother, or the mother of the screened child
+func (s *Service) raiseSOS(ctx context.Context, result *model.ScreenerResult) error {
+	if s.sosService == nil {
+		return errorx.New(errorx.InternalServerError, "SOS service not configured")
+	}
+	location, err := s.resultLocation(ctx, result)
+	if err != nil {
+		return err
+	}
+	mother, child, err := s.resultMother(ctx, result)
+	if err != nil {
+		return err
+	}
+
+	nature, description := model.SOSEventNatureOther, "Danger signs found in a screening: "+result.PrimaryConcern
+	switch {
+	case child != nil:
+		description = fmt.Sprintf("Danger signs found in a screening of %s: %s", child.FullName(), result.PrimaryConcern)
+		if time.Since(child.DateOfBirth) <= model.NeonatalPeriodDays*24*time.Hour {
+			nature = model.SOSEventNatureNewborn
+		}
+	case strings.EqualFold(result.PrimaryConcern, string(model.SOSEventNatureBleeding)):
+		nature = model.SOSEventNatureBleeding
+	}
+
+	reportedByID := mother.UserID
+	if result.ScreenedByID != nil {
+		reportedByID = *result.ScreenedByID
+	}
+
+	sosEvent, err := s.sosService.ReportSOSEvent(ctx, mother.ID, reportedByID, location.Latitude, location.Longitude, nature, description)
+	if err != nil {
+		return err
+	}
+
+	result.ReferralFacilityID = sosEvent.FacilityID
+	result.ActionTaken = fmt.Sprintf("%s: SOS %s raised", ActionSOS, sosEvent.ID)
+	return nil
+}
+
+// refer refers the screening to the nearest facility of a type the policy allows
+func (s *Service) refer(ctx context.Context, result *model.ScreenerResult) error {
+	if s.facilityFinder == nil {
+		return errorx.New(errorx.InternalServerError, "facility search not configured")
+	}
+	location, err := s.resultLocation(ctx, result)
+	if err != nil {
+		return err
+	}
+
+	facilities, err := s.facilityFinder.FindNearbyFacilities(ctx, location.Latitude, location.Longitude, s.policy.ReferralRadiusKm,
+		&facility.FacilityFilter{Types: s.policy.ReferralTypes})
+	if err != nil {
+		return err
+	}
+	if len(facilities) == 0 {
+		return errorx.Newf(errorx.NotFound, "no referral facility within %.0f km", s.policy.ReferralRadiusKm)
+	}
+
+	nearest := facilities[0]
+	result.ReferralFacilityID = &nearest.ID
+	result.ActionTaken = fmt.Sprintf("%s: referred to %s (%s)", ActionReferral, nearest.Name, nearest.DistanceFormatted)
+	return nil
+}
+
+// scheduleFollowUp schedules an urgent follow-up visit at the facility responsible for the screening
+func (s *Service) scheduleFollowUp(ctx context.Context, result *model.ScreenerResult) error {
+	if s.visitScheduler == nil {
+		return errorx.New(errorx.InternalServerError, "visit scheduler not configured")
+	}
+	mother, child, err := s.resultMother(ctx, result)
+	if err != nil {
+		return err
+	}
+	facilityID, err := s.followUpFacility(ctx, result, mother)
+	if err != nil {
+		return err
+	}
+
+	notes := "Urgent follow-up of a red screening: " + result.PrimaryConcern
+	if child != nil {
+		notes = fmt.Sprintf("Urgent follow-up of a red screening of %s: %s", child.FullName(), result.PrimaryConcern)
+	}
+
+	visit, err := s.visitScheduler.ScheduleVisit(ctx, mother.ID, facilityID, time.Now().Add(s.policy.FollowUpAfter), model.VisitTypeFollowUp, notes)
+	if err != nil {
+		return err
+	}
+
+	result.FollowupVisitID = &visit.ID
+	result.ActionTaken = fmt.Sprintf("%s: visit scheduled for %s", ActionFollowUpVisit, visit.ScheduledTime.Format(time.RFC3339))
+	return nil
+}
+
+// resultLocation is where the screening happened: its own coordinates, else the location
+// of its facility or the screener's facility
+func (s *Service) resultLocation(ctx context.Context, result *model.ScreenerResult) (*model.Location, error) {
+	if result.Latitude != nil && result.Longitude != nil {
+		return &model.Location{Latitude: *result.Latitude, Longitude: *result.Longitude}, nil
+	}
+
+	facilityID := result.FacilityID
+	if facilityID == nil && result.ScreenedByID != nil {
+		if screener, err := s.userRepo.GetByID(ctx, *result.ScreenedByID); err == nil {
+			facilityID = screener.FacilityID
+		}
+	}
+	if facilityID == nil {
+		return nil, errorx.New(errorx.BadRequest, "screening has no location")
+	}
+
+	screeningFacility, err := s.facilityRepo.GetByID(ctx, *facilityID)
+	if err != nil {
+		return nil, errorx.Wrap(err, "failed to find screening facility")
+	}
+	return &screeningFacility.Location, nil
+}
+
+// followUpFacility is where a follow-up visit is held: the screening's facility, else the
+// screener's, else the nearest facility to the screening
+func (s *Service) followUpFacility(ctx context.Context, result *model.ScreenerResult, mother *model.Mother) (uuid.UUID, error) {
+	if result.FacilityID != nil {
+		return *result.FacilityID, nil
+	}
+	for _, userID := range []*uuid.UUID{result.ScreenedByID, &mother.UserID} {
+		if userID == nil {
+			continue
+		}
+		if user, err := s.userRepo.GetByID(ctx, *userID); err == nil && user.FacilityID != nil {
+			return *user.FacilityID, nil
+		}
+	}
+
+	if s.facilityFinder != nil && result.Latitude != nil && result.Longitude != nil {
+		facilities, err := s.facilityFinder.FindNearbyFacilities(ctx, *result.Latitude, *result.Longitude, s.policy.ReferralRadiusKm, nil)
+		if err == nil && len(facilities) > 0 {
+			return facilities[0].ID, nil
+		}
+	}
+	return uuid.Nil, errorx.New(errorx.BadRequest, "no facility for a follow-up visit")
+}
+
+// resultMother is the mother record SOS events and visits are raised under: the screened
+// mother, or the mother of the screened child, who is also returned
+func (s *Service) resultMother(ctx context.Context, result *model.ScreenerResult) (*model.Mother, *model.Child, error) {
+	userID := result.MotherID
+	var child *model.Child
+	if result.ChildID != nil {
+		if result.ScreenedByID == nil {
+			return nil, nil, errorx.New(errorx.BadRequest, "screening has no screener to look up the child")
+		}
+		var err error
+		child, err = s.registryService.GetChild(ctx, *result.ScreenedByID, *result.ChildID)
+		if err != nil {
+			return nil, nil, err
+		}
+		if child.MotherID == nil {
+			return nil, nil, errorx.New(errorx.BadRequest, "child is not linked to a mother")
+		}
+		userID = child.MotherID
+	}
+
+	mother, err := s.motherRepo.FindByUserID(ctx, *userID)
+	if err != nil {
+		return nil, nil, errorx.Wrap(err, "failed to find mother")
+	}
+	return mother, child, nil
+}
@@ -0,0 +1,492 @@
+package screener
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mamacare/services/internal/domain/model"
+	"github.com/mamacare/services/internal/domain/repository"
+	"github.com/mamacare/services/pkg/errorx"
+	"github.com/mamacare/services/pkg/logger"
+)
+
+// DraftInput is a new questionnaire version. A draft based on another version starts with
+// copies of its questions and takes its subject.
+type DraftInput struct {
+	Subject   model.ScreenerSubject
+	Title     string
+	Notes     string
+	BasedOnID *uuid.UUID
+}
+
+// QuestionInput is a question as an admin writes it
+type QuestionInput struct {
+	Text                string
+	Code                string
+	AnswerType          model.ScreenerAnswerType
+	AnswerOptions       []model.ScreenerOption
+	IsDangerSign        bool
+	RiskCategory        *model.ScreenerRisk
+	Category            string
+	Subcategory         string
+	DisplayOrder        int
+	DependsOnQuestionID *uuid.UUID
+	DependsOnAnswer     *string
+	TranslationKey      string
+	IsActive            bool
+}
+
+// PreviewStep is one answer in a preview and whether the questionnaire accepted it
+type PreviewStep struct {
+	Question *model.ScreenerQuestion `json:"question,omitempty"`
+	Value    string                  `json:"value"`
+	// Error is why the answer was rejected; a rejected answer is left out of the flow
+	Error string `json:"error,omitempty"`
+}
+
+// Preview is how a questionnaire plays out for a set of answers, without saving a screening
+type Preview struct {
+	Steps []PreviewStep `json:"steps"`
+	// Next is the question that would be asked next, nil once the screening is complete
+	Next *model.ScreenerQuestion `json:"next,omitempty"`
+	// Skipped are the questions the answers branched past
+	Skipped []*model.ScreenerQuestion `json:"skipped"`
+	// Result is the risk the answers evaluate to
+	Result *model.ScreenerResult `json:"result"`
+}
+
+// AuthoringService lets admins author new questionnaire versions, check and preview them,
+// and publish them. Published versions can no longer be changed; they are replaced by
+// publishing a new version based on them.
+type AuthoringService struct {
+	screenerRepo repository.ScreenerRepository
+	userRepo     repository.UserRepository
+	log          logger.Logger
+}
+
+// NewAuthoringService creates a new questionnaire authoring service
+func NewAuthoringService(
+	screenerRepo repository.ScreenerRepository,
+	userRepo repository.UserRepository,
+	log logger.Logger,
+) *AuthoringService {
+	return &AuthoringService{
+		screenerRepo: screenerRepo,
+		userRepo:     userRepo,
+		log:          log,
+	}
+}
+
+// ListQuestionnaires returns the questionnaire versions, newest first, optionally only for one subject
+func (s *AuthoringService) ListQuestionnaires(ctx context.Context, requesterID uuid.UUID, subject *model.ScreenerSubject) ([]*model.ScreenerQuestionnaire, error) {
+	if err := s.authorize(ctx, requesterID); err != nil {
+		return nil, err
+	}
+	if subject != nil && !subject.IsValid() {
+		return nil, errorx.Newf(errorx.BadRequest, "invalid screening subject: %s", *subject)
+	}
+
+	questionnaires, err := s.screenerRepo.GetQuestionnaires(ctx, subject)
+	if err != nil {
+		s.log.Error("Failed to get screener questionnaires", logger.Fields{
+			"error": err.Error(),
+		})
+		return nil, errorx.Wrap(err, "failed to get screener questionnaires")
+	}
+	return questionnaires, nil
+}
+
+// GetQuestionnaire returns a questionnaire version with all its questions, including inactive ones
+func (s *AuthoringService) GetQuestionnaire(ctx context.Context, requesterID, questionnaireID uuid.UUID) (*model.ScreenerQuestionnaire, error) {
+	if err := s.authorize(ctx, requesterID); err != nil {
+		return nil, err
+	}
+	return s.getQuestionnaire(ctx, questionnaireID)
+}
+
+// CreateDraft starts a new questionnaire version
+func (s *AuthoringService) CreateDraft(ctx context.Context, requesterID uuid.UUID, input *DraftInput) (*model.ScreenerQuestionnaire, error) {
+	if err := s.authorize(ctx, requesterID); err != nil {
+		return nil, err
+	}
+	if input == nil || strings.TrimSpace(input.Title) == "" {
+		return nil, errorx.New(errorx.BadRequest, "questionnaire title is required")
+	}
+
+	subject := input.Subject
+	if input.BasedOnID != nil {
+		base, err := s.getQuestionnaire(ctx, *input.BasedOnID)
+		if err != nil {
+			return nil, err
+		}
+		if subject != "" && subject != base.Subject {
+			return nil, errorx.Newf(errorx.BadRequest, "a draft based on a %s questionnaire must also be for a %s", base.Subject, base.Subject)
+		}
+		subject = base.Subject
+	}
+	if !subject.IsValid() {
+		return nil, errorx.Newf(errorx.BadRequest, "invalid screening subject: %s", subject)
+	}
+
+	questionnaire := model.NewScreenerQuestionnaire(subject, strings.TrimSpace(input.Title))
+	questionnaire.Notes = input.Notes
+	questionnaire.BasedOnID = input.BasedOnID
+	questionnaire.CreatedByID = &requesterID
+
+	if err := s.screenerRepo.CreateQuestionnaire(ctx, questionnaire); err != nil {
+		s.log.Error("Failed to create screener questionnaire", logger.Fields{
+			"error":   err.Error(),
+			"subject": string(subject),
+		})
+		return nil, errorx.Wrap(err, "failed to create screener questionnaire")
+	}
+
+	return s.getQuestionnaire(ctx, questionnaire.ID)
+}
+
+// UpdateDraft changes a draft's title and notes
+func (s *AuthoringService) UpdateDraft(ctx context.Context, requesterID, questionnaireID uuid.UUID, title, notes string) (*model.ScreenerQuestionnaire, error) {
+	if err := s.authorize(ctx, requesterID); err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(title) == "" {
+		return nil, errorx.New(errorx.BadRequest, "questionnaire title is required")
+	}
+
+	questionnaire, err := s.getDraft(ctx, questionnaireID)
+	if err != nil {
+		return nil, err
+	}
+
+	questionnaire.Title = strings.TrimSpace(title)
+	questionnaire.Notes = notes
+	questionnaire.UpdatedAt = time.Now()
+
+	if err := s.screenerRepo.UpdateQuestionnaire(ctx, questionnaire); err != nil {
+		s.log.Error("Failed to update screener questionnaire", logger.Fields{
+			"error":            err.Error(),
+			"questionnaire_id": questionnaireID.String(),
+		})
+		return nil, errorx.Wrap(err, "failed to update screener questionnaire")
+	}
+
+	return questionnaire, nil
+}
+
+// AddQuestion adds a question to a draft. The draft is only checked as a whole when it is
+// validated or published, so questions can be added in any order.
+func (s *AuthoringService) AddQuestion(ctx context.Context, requesterID, questionnaireID uuid.UUID, input *QuestionInput) (*model.ScreenerQuestion, error) {
+	if err := s.authorize(ctx, requesterID); err != nil {
+		return nil, err
+	}
+
+	questionnaire, err := s.getDraft(ctx, questionnaireID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	question := &model.ScreenerQuestion{
+		ID:              uuid.New(),
+		QuestionnaireID: questionnaire.ID,
+		CreatedAt:       now,
+	}
+	if err := applyQuestionInput(question, input, questionnaire); err != nil {
+		return nil, err
+	}
+	question.UpdatedAt = now
+
+	if err := s.screenerRepo.CreateQuestion(ctx, question); err != nil {
+		s.log.Error("Failed to create screener question", logger.Fields{
+			"error":            err.Error(),
+			"questionnaire_id": questionnaireID.String(),
+			"question_code":    question.Code,
+		})
+		return nil, errorx.Wrap(err, "failed to create screener question")
+	}
+
+	return question, nil
+}
+
+// UpdateQuestion replaces a question of a draft
+func (s *AuthoringService) UpdateQuestion(ctx context.Context, requesterID, questionID uuid.UUID, input *QuestionInput) (*model.ScreenerQuestion, error) {
+	if err := s.authorize(ctx, requesterID); err != nil {
+		return nil, err
+	}
+
+	question, questionnaire, err := s.getDraftQuestion(ctx, questionID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := applyQuestionInput(question, input, questionnaire); err != nil {
+		return nil, err
+	}
+	question.UpdatedAt = time.Now()
+
+	if err := s.screenerRepo.UpdateQuestion(ctx, question); err != nil {
+		s.log.Error("Failed to update screener question", logger.Fields{
+			"error":       err.Error(),
+			"question_id": questionID.String(),
+		})
+		return nil, errorx.Wrap(err, "failed to update screener question")
+	}
+
+	return question, nil
+}
+
+// RemoveQuestion deletes a question from a draft. Questions that others depend on must
+// have those dependencies removed first.
+func (s *AuthoringService) RemoveQuestion(ctx context.Context, requesterID, questionID uuid.UUID) error {
+	if err := s.authorize(ctx, requesterID); err != nil {
+		return err
+	}
+
+	question, questionnaire, err := s.getDraftQuestion(ctx, questionID)
+	if err != nil {
+		return err
+	}
+
+	var dependents []string
+	for _, other := range questionnaire.Questions {
+		if other.DependsOnQuestionID != nil && *other.DependsOnQuestionID == question.ID && other.ID != question.ID {
+			dependents = append(dependents, other.Code)
+		}
+	}
+	if len(dependents) > 0 {
+		return errorx.Newf(errorx.BadRequest, "%s cannot be removed while %s depend on it", question.Code, strings.Join(dependents, ", "))
+	}
+
+	if err := s.screenerRepo.DeleteQuestion(ctx, questionID); err != nil {
+		s.log.Error("Failed to delete screener question", logger.Fields{
+			"error":       err.Error(),
+			"question_id": questionID.String(),
+		})
+		return errorx.Wrap(err, "failed to delete screener question")
+	}
+
+	return nil
+}
+
+// Validate checks a questionnaire version for problems that would stop it being published
+func (s *AuthoringService) Validate(ctx context.Context, requesterID, questionnaireID uuid.UUID) (*ValidationReport, error) {
+	if err := s.authorize(ctx, requesterID); err != nil {
+		return nil, err
+	}
+
+	questionnaire, err := s.getQuestionnaire(ctx, questionnaireID)
+	if err != nil {
+		return nil, err
+	}
+
+	return ValidateQuestionnaire(questionnaire), nil
+}
+
+// Preview plays answers through a questionnaire version without saving a screening, to
+// check its branching and risk evaluation. Answers that would be rejected in a real
+// screening are reported and left out, and the preview carries on with the rest.
+func (s *AuthoringService) Preview(ctx context.Context, requesterID, questionnaireID uuid.UUID, answers []AnswerInput) (*Preview, error) {
+	if err := s.authorize(ctx, requesterID); err != nil {
+		return nil, err
+	}
+
+	questionnaire, err := s.getQuestionnaire(ctx, questionnaireID)
+	if err != nil {
+		return nil, err
+	}
+
+	return PreviewQuestionnaire(questionnaire, answers), nil
+}
+
+// Publish makes a draft the version its subject is screened with and retires the version
+// published before it. Drafts with validation errors cannot be published.
+func (s *AuthoringService) Publish(ctx context.Context, requesterID, questionnaireID uuid.UUID) (*model.ScreenerQuestionnaire, error) {
+	if err := s.authorize(ctx, requesterID); err != nil {
+		return nil, err
+	}
+
+	questionnaire, err := s.getDraft(ctx, questionnaireID)
+	if err != nil {
+		return nil, err
+	}
+
+	if report := ValidateQuestionnaire(questionnaire); !report.Valid {
+		return nil, errorx.Newf(errorx.BadRequest, "questionnaire cannot be published: %s", strings.Join(report.Errors(), "; "))
+	}
+
+	if err := s.screenerRepo.PublishQuestionnaire(ctx, questionnaire.ID, requesterID, time.Now()); err != nil {
+		s.log.Error("Failed to publish screener questionnaire", logger.Fields{
+			"error":            err.Error(),
+			"questionnaire_id": questionnaireID.String(),
+		})
+		return nil, errorx.Wrap(err, "failed to publish screener questionnaire")
+	}
+
+	return s.getQuestionnaire(ctx, questionnaire.ID)
+}
+
+// PreviewQuestionnaire plays answers through a questionnaire in the order given
+func PreviewQuestionnaire(questionnaire *model.ScreenerQuestionnaire, answers []AnswerInput) *Preview {
+	flow := NewFlow(questionnaire.Questions, nil)
+	resultID := uuid.New()
+
+	preview := &Preview{
+		Steps:   make([]PreviewStep, 0, len(answers)),
+		Skipped: []*model.ScreenerQuestion{},
+	}
+	for _, input := range answers {
+		step := PreviewStep{Value: input.Value}
+		if question, ok := flow.Question(input.QuestionID); ok {
+			step.Question = question
+		}
+		if _, err := flow.Answer(resultID, input.QuestionID, input.Value); err != nil {
+			step.Error = err.Error()
+		}
+		preview.Steps = append(preview.Steps, step)
+	}
+
+	answered := make(map[uuid.UUID]bool, len(flow.Answers()))
+	for _, answer := range flow.Answers() {
+		answered[answer.QuestionID] = true
+	}
+	skipped := make(map[uuid.UUID]bool)
+	// Questions come in display order, but a dependency may be displayed after the question
+	// that depends on it, so repeat until no more questions are found to be skipped
+	for changed := true; changed; {
+		changed = false
+		for _, question := range flow.questions {
+			if answered[question.ID] || skipped[question.ID] || question.DependsOnQuestionID == nil {
+				continue
+			}
+			dependency := *question.DependsOnQuestionID
+			if (answered[dependency] && !flow.Applicable(question)) || skipped[dependency] {
+				skipped[question.ID] = true
+				preview.Skipped = append(preview.Skipped, question)
+				changed = true
+			}
+		}
+	}
+
+	preview.Result = model.NewScreenerResult(resultID, time.Now())
+	preview.Result.QuestionnaireID = &questionnaire.ID
+	flow.Evaluate(preview.Result)
+	preview.Next = flow.Next()
+
+	return preview
+}
+
+// applyQuestionInput sets a question from an admin's input. Only what is needed to store
+// the question is checked here; whether it fits the questionnaire is left to validation.
+func applyQuestionInput(question *model.ScreenerQuestion, input *QuestionInput, questionnaire *model.ScreenerQuestionnaire) error {
+	if input == nil {
+		return errorx.New(errorx.BadRequest, "question details are required")
+	}
+	if strings.TrimSpace(input.Text) == "" {
+		return errorx.New(errorx.BadRequest, "question text is required")
+	}
+	if strings.TrimSpace(input.Code) == "" {
+		return errorx.New(errorx.BadRequest, "question code is required")
+	}
+	if strings.TrimSpace(input.Category) == "" {
+		return errorx.New(errorx.BadRequest, "question category is required")
+	}
+	if strings.TrimSpace(input.TranslationKey) == "" {
+		return errorx.New(errorx.BadRequest, "question translation key is required")
+	}
+	switch input.AnswerType {
+	case model.ScreenerAnswerBoolean, model.ScreenerAnswerMultipleChoice, model.ScreenerAnswerNumeric, model.ScreenerAnswerText:
+	default:
+		return errorx.Newf(errorx.BadRequest, "invalid answer type: %s", input.AnswerType)
+	}
+	if input.RiskCategory != nil && !input.RiskCategory.IsValid() {
+		return errorx.Newf(errorx.BadRequest, "invalid risk category: %s", *input.RiskCategory)
+	}
+	if input.DependsOnQuestionID != nil {
+		found := false
+		for _, other := range questionnaire.Questions {
+			if other.ID == *input.DependsOnQuestionID {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return errorx.New(errorx.BadRequest, "a question can only depend on another question of the same questionnaire")
+		}
+	}
+
+	question.Text = strings.TrimSpace(input.Text)
+	question.Code = strings.TrimSpace(input.Code)
+	question.AnswerType = input.AnswerType
+	question.AnswerOptions = input.AnswerOptions
+	question.IsDangerSign = input.IsDangerSign
+	question.RiskCategory = input.RiskCategory
+	question.Category = strings.TrimSpace(input.Category)
+	question.Subcategory = strings.TrimSpace(input.Subcategory)
+	question.DisplayOrder = input.DisplayOrder
+	question.DependsOnQuestionID = input.DependsOnQuestionID
+	question.DependsOnAnswer = input.DependsOnAnswer
+	question.TranslationKey = strings.TrimSpace(input.TranslationKey)
+	question.IsActive = input.IsActive
+	return nil
+}
+
+// authorize checks the requester is an admin
+func (s *AuthoringService) authorize(ctx context.Context, requesterID uuid.UUID) error {
+	requester, err := s.userRepo.GetByID(ctx, requesterID)
+	if err != nil {
+		s.log.Error("Failed to find requester", logger.Fields{
+			"error":   err.Error(),
+			"user_id": requesterID.String(),
+		})
+		return errorx.Wrap(err, "failed to find requester")
+	}
+	if !requester.HasAdminAccess() {
+		return errorx.New(errorx.Forbidden, "only admins can author screener questionnaires")
+	}
+	return nil
+}
+
+// getQuestionnaire loads a questionnaire version with all its questions
+func (s *AuthoringService) getQuestionnaire(ctx context.Context, questionnaireID uuid.UUID) (*model.ScreenerQuestionnaire, error) {
+	questionnaire, err := s.screenerRepo.GetQuestionnaireByID(ctx, questionnaireID)
+	if err != nil {
+		s.log.Error("Failed to get screener questionnaire", logger.Fields{
+			"error":            err.Error(),
+			"questionnaire_id": questionnaireID.String(),
+		})
+		return nil, errorx.Wrap(err, "failed to get screener questionnaire")
+	}
+	return questionnaire, nil
+}
+
+// getDraft loads a questionnaire version that can still be changed
+func (s *AuthoringService) getDraft(ctx context.Context, questionnaireID uuid.UUID) (*model.ScreenerQuestionnaire, error) {
+	questionnaire, err := s.getQuestionnaire(ctx, questionnaireID)
+	if err != nil {
+		return nil, err
+	}
+	if !questionnaire.IsDraft() {
+		return nil, errorx.New(errorx.BadRequest, "published questionnaires cannot be changed; create a new draft based on it")
+	}
+	return questionnaire, nil
+}
+
+// getDraftQuestion loads a question and the draft it belongs to
+func (s *AuthoringService) getDraftQuestion(ctx context.Context, questionID uuid.UUID) (*model.ScreenerQuestion, *model.ScreenerQuestionnaire, error) {
+	question, err := s.screenerRepo.GetQuestionByID(ctx, questionID)
+	if err != nil {
+		s.log.Error("Failed to get screener question", logger.Fields{
+			"error":       err.Error(),
+			"question_id": questionID.String(),
+		})
+		return nil, nil, errorx.Wrap(err, "failed to get screener question")
+	}
+
+	questionnaire, err := s.getDraft(ctx, question.QuestionnaireID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return question, questionnaire, nil
+}
@@ -0,0 +1,239 @@
+package screener
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mamacare/services/internal/domain/model"
+	"github.com/mamacare/services/pkg/errorx"
+)
+
+// Flow is a questionnaire part way through being answered. Questions are asked in display
+// order; a question that depends on another is asked once that question has been given the
+// answer it depends on, and skipped when it was given any other answer.
+type Flow struct {
+	questions []*model.ScreenerQuestion
+	byID      map[uuid.UUID]*model.ScreenerQuestion
+	answers   []*model.ScreenerAnswer
+	answered  map[uuid.UUID]*model.ScreenerAnswer
+}
+
+// NewFlow creates a flow over the questions, resuming after the answers already given
+func NewFlow(questions []*model.ScreenerQuestion, answers []*model.ScreenerAnswer) *Flow {
+	ordered := make([]*model.ScreenerQuestion, 0, len(questions))
+	for _, question := range questions {
+		if question.IsActive {
+			ordered = append(ordered, question)
+		}
+	}
+	sort.SliceStable(ordered, func(i, j int) bool {
+		return ordered[i].DisplayOrder < ordered[j].DisplayOrder
+	})
+
+	flow := &Flow{
+		questions: ordered,
+		byID:      make(map[uuid.UUID]*model.ScreenerQuestion, len(ordered)),
+		answers:   []*model.ScreenerAnswer{},
+		answered:  make(map[uuid.UUID]*model.ScreenerAnswer, len(answers)),
+	}
+	for _, question := range ordered {
+		flow.byID[question.ID] = question
+	}
+	for _, answer := range answers {
+		flow.answers = append(flow.answers, answer)
+		flow.answered[answer.QuestionID] = answer
+	}
+	return flow
+}
+
+// Answers returns the answers given so far in the order they were given
+func (f *Flow) Answers() []*model.ScreenerAnswer {
+	return f.answers
+}
+
+// Question returns a question in the flow by ID
+func (f *Flow) Question(id uuid.UUID) (*model.ScreenerQuestion, bool) {
+	question, ok := f.byID[id]
+	return question, ok
+}
+
+// Applicable reports whether a question is asked given the answers so far. A question whose
+// dependency has not been answered yet is not applicable until it is.
+func (f *Flow) Applicable(question *model.ScreenerQuestion) bool {
+	if question.DependsOnQuestionID == nil {
+		return true
+	}
+	answer, ok := f.answered[*question.DependsOnQuestionID]
+	if !ok {
+		return false
+	}
+	if question.DependsOnAnswer == nil {
+		return true
+	}
+	return strings.EqualFold(answer.Value(), strings.TrimSpace(*question.DependsOnAnswer))
+}
+
+// Next returns the next question to ask, or nil when the screening is complete
+func (f *Flow) Next() *model.ScreenerQuestion {
+	for _, question := range f.questions {
+		if _, done := f.answered[question.ID]; done {
+			continue
+		}
+		if f.Applicable(question) {
+			return question
+		}
+	}
+	return nil
+}
+
+// Complete reports whether every question that applies has been answered
+func (f *Flow) Complete() bool {
+	return f.Next() == nil
+}
+
+// Answer validates a raw answer to a question and adds it to the flow. Questions can be
+// answered in any order, but only once and only when they apply.
+func (f *Flow) Answer(resultID, questionID uuid.UUID, raw string) (*model.ScreenerAnswer, error) {
+	question, ok := f.byID[questionID]
+	if !ok {
+		return nil, errorx.Newf(errorx.BadRequest, "question %s is not in this questionnaire", questionID)
+	}
+	if _, done := f.answered[questionID]; done {
+		return nil, errorx.Newf(errorx.AlreadyExists, "question %s has already been answered", question.Code)
+	}
+	if !f.Applicable(question) {
+		return nil, errorx.Newf(errorx.BadRequest, "question %s does not apply given the earlier answers", question.Code)
+	}
+
+	answer, err := ParseAnswer(question, raw)
+	if err != nil {
+		return nil, err
+	}
+	answer.ID = uuid.New()
+	answer.ScreenerResultID = resultID
+	answer.Sequence = len(f.answers) + 1
+	answer.CreatedAt = time.Now()
+
+	f.answers = append(f.answers, answer)
+	f.answered[questionID] = answer
+	return answer, nil
+}
+
+// ParseAnswer validates a raw answer against the question's answer type and options and
+// works out the risk it indicates
+func ParseAnswer(question *model.ScreenerQuestion, raw string) (*model.ScreenerAnswer, error) {
+	value := strings.TrimSpace(raw)
+	answer := &model.ScreenerAnswer{
+		QuestionID:   question.ID,
+		QuestionText: question.Text,
+	}
+
+	switch question.AnswerType {
+	case model.ScreenerAnswerBoolean:
+		var b bool
+		switch strings.ToLower(value) {
+		case "true", "yes", "y":
+			b = true
+		case "false", "no", "n":
+			b = false
+		default:
+			return nil, errorx.Newf(errorx.BadRequest, "%s must be answered yes or no", question.Code)
+		}
+		answer.Boolean = &b
+		if b {
+			answer.IndividualRiskLevel = positiveRisk(question)
+		}
+
+	case model.ScreenerAnswerMultipleChoice:
+		option, ok := question.Option(value)
+		if !ok {
+			return nil, errorx.Newf(errorx.BadRequest, "%q is not an option for %s", value, question.Code)
+		}
+		answer.Option = &option.Value
+		answer.IndividualRiskLevel = option.RiskLevel
+
+	case model.ScreenerAnswerNumeric:
+		n, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return nil, errorx.Newf(errorx.BadRequest, "%s must be answered with a number", question.Code)
+		}
+		answer.Numeric = &n
+
+	case model.ScreenerAnswerText:
+		if value == "" {
+			return nil, errorx.Newf(errorx.BadRequest, "%s needs an answer", question.Code)
+		}
+		answer.Text = &value
+
+	default:
+		return nil, errorx.Newf(errorx.InternalServerError, "question %s has unknown answer type %s", question.Code, question.AnswerType)
+	}
+
+	answer.ContributedToRisk = answer.IndividualRiskLevel != nil && answer.IndividualRiskLevel.Rank() > 0
+	return answer, nil
+}
+
+// positiveRisk is the risk a yes to a question indicates. Danger signs without a risk
+// category are treated as red.
+func positiveRisk(question *model.ScreenerQuestion) *model.ScreenerRisk {
+	if question.RiskCategory != nil {
+		risk := *question.RiskCategory
+		return &risk
+	}
+	if question.IsDangerSign {
+		risk := model.ScreenerRiskRed
+		return &risk
+	}
+	return nil
+}
+
+// Evaluate sets a result's overall risk from the answers so far. The result is red if any
+// danger sign was reported, otherwise the highest risk of any answer. The primary concern
+// is the most serious answer, preferring danger signs and then the earliest given.
+func (f *Flow) Evaluate(result *model.ScreenerResult) {
+	result.RiskLevel = model.ScreenerRiskGreen
+	result.DangerSignsDetected = false
+	result.PrimaryConcern = ""
+
+	var concern *model.ScreenerAnswer
+	concernDanger := false
+	for _, answer := range f.answers {
+		if !answer.ContributedToRisk || answer.IndividualRiskLevel == nil {
+			continue
+		}
+		question, known := f.byID[answer.QuestionID]
+		danger := known && question.IsDangerSign
+
+		if answer.IndividualRiskLevel.Rank() > result.RiskLevel.Rank() {
+			result.RiskLevel = *answer.IndividualRiskLevel
+		}
+		if danger {
+			result.DangerSignsDetected = true
+		}
+
+		if concern == nil ||
+			(danger && !concernDanger) ||
+			(danger == concernDanger && answer.IndividualRiskLevel.Rank() > concern.IndividualRiskLevel.Rank()) {
+			concern = answer
+			concernDanger = danger
+		}
+	}
+
+	if result.DangerSignsDetected {
+		result.RiskLevel = model.ScreenerRiskRed
+	}
+	if concern != nil {
+		result.PrimaryConcern = concern.QuestionText
+		if question, ok := f.byID[concern.QuestionID]; ok && question.Subcategory != "" {
+			result.PrimaryConcern = question.Subcategory
+		}
+	}
+
+	result.Completed = f.Complete()
+	result.FollowupRecommended = result.RiskLevel != model.ScreenerRiskGreen
+	result.Answers = f.answers
+	result.UpdatedAt = time.Now()
+}
@@ -0,0 +1,486 @@
+package screener
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mamacare/services/internal/app/child/registry"
+	"github.com/mamacare/services/internal/domain/model"
+	"github.com/mamacare/services/internal/domain/repository"
+	"github.com/mamacare/services/pkg/errorx"
+	"github.com/mamacare/services/pkg/logger"
+)
+
+// StartInput is who is being screened, where and by whom
+type StartInput struct {
+	// ResultID lets an app choose the result's ID so that retries are idempotent
+	ResultID *uuid.UUID
+	// MotherID is the mother's user ID; exactly one of MotherID and ChildID is set
+	MotherID       *uuid.UUID
+	ChildID        *uuid.UUID
+	ScreenedAt     time.Time
+	ScreenedByName string
+	FacilityID     *uuid.UUID
+	Latitude       *float64
+	Longitude      *float64
+}
+
+// AnswerInput is the raw answer to a question
+type AnswerInput struct {
+	QuestionID uuid.UUID
+	Value      string
+}
+
+// SubmissionInput is a screening done offline, with its answers in the order they were given
+type SubmissionInput struct {
+	StartInput
+	// QuestionnaireID is the questionnaire version the app screened with. Apps that were
+	// offline may still be using a retired version; without it the published version is used.
+	QuestionnaireID *uuid.UUID
+	Answers         []AnswerInput
+}
+
+// Progress is a screening in progress and the question to ask next
+type Progress struct {
+	Result *model.ScreenerResult `json:"result"`
+	// Next is the next question to ask, nil once the screening is complete
+	Next *model.ScreenerQuestion `json:"next,omitempty"`
+}
+
+// Service runs health screenings: it serves questions in order, following the branches
+// set by earlier answers, records the answers and the risk they indicate, and acts on
+// red screenings as its action policy sets
+type Service struct {
+	registryService *registry.Service
+	screenerRepo    repository.ScreenerRepository
+	userRepo        repository.UserRepository
+	motherRepo      repository.MotherRepository
+	facilityRepo    repository.FacilityRepository
+	sosService      SOSService
+	facilityFinder  FacilityFinder
+	visitScheduler  VisitScheduler
+	policy          ActionPolicy
+	transactor      repository.Transactor
+	log             logger.Logger
+}
+
+// NewService creates a new screener service
+func NewService(
+	registryService *registry.Service,
+	screenerRepo repository.ScreenerRepository,
+	userRepo repository.UserRepository,
+	motherRepo repository.MotherRepository,
+	facilityRepo repository.FacilityRepository,
+	sosService SOSService,
+	facilityFinder FacilityFinder,
+	visitScheduler VisitScheduler,
+	policy ActionPolicy,
+	transactor repository.Transactor,
+	log logger.Logger,
+) *Service {
+	return &Service{
+		registryService: registryService,
+		screenerRepo:    screenerRepo,
+		userRepo:        userRepo,
+		motherRepo:      motherRepo,
+		facilityRepo:    facilityRepo,
+		sosService:      sosService,
+		facilityFinder:  facilityFinder,
+		visitScheduler:  visitScheduler,
+		policy:          policy,
+		transactor:      transactor,
+		log:             log,
+	}
+}
+
+// GetQuestionnaire returns the published questionnaire for a subject with its active
+// questions, for apps that screen offline
+func (s *Service) GetQuestionnaire(ctx context.Context, subject model.ScreenerSubject) (*model.ScreenerQuestionnaire, error) {
+	if !subject.IsValid() {
+		return nil, errorx.Newf(errorx.BadRequest, "invalid screening subject: %s", subject)
+	}
+
+	questionnaire, err := s.getPublished(ctx, subject)
+	if err != nil {
+		return nil, err
+	}
+
+	questions := make([]*model.ScreenerQuestion, 0, len(questionnaire.Questions))
+	for _, question := range questionnaire.Questions {
+		if question.IsActive {
+			questions = append(questions, question)
+		}
+	}
+	questionnaire.Questions = questions
+
+	return questionnaire, nil
+}
+
+// StartScreening starts a screening and returns its first question
+func (s *Service) StartScreening(ctx context.Context, requesterID uuid.UUID, input *StartInput) (*Progress, error) {
+	if _, err := validateStart(input); err != nil {
+		return nil, err
+	}
+	if err := s.authorize(ctx, requesterID, input.MotherID, input.ChildID); err != nil {
+		return nil, err
+	}
+
+	result := newResult(requesterID, input)
+	flow, err := s.resume(ctx, result)
+	if err != nil {
+		return nil, err
+	}
+	flow.Evaluate(result)
+
+	if err := s.screenerRepo.CreateResult(ctx, result); err != nil {
+		s.log.Error("Failed to create screener result", logger.Fields{
+			"error":     err.Error(),
+			"result_id": result.ID.String(),
+		})
+		return nil, errorx.Wrap(err, "failed to create screener result")
+	}
+
+	return &Progress{Result: result, Next: flow.Next()}, nil
+}
+
+// AnswerQuestion records the answer to a question in a screening and returns the next question.
+// The screening's risk is re-evaluated after every answer.
+func (s *Service) AnswerQuestion(ctx context.Context, requesterID, resultID uuid.UUID, input AnswerInput) (*Progress, error) {
+	result, err := s.getResult(ctx, resultID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.authorize(ctx, requesterID, result.MotherID, result.ChildID); err != nil {
+		return nil, err
+	}
+	if result.Completed {
+		return nil, errorx.New(errorx.BadRequest, "screening is already complete")
+	}
+
+	flow, err := s.resume(ctx, result)
+	if err != nil {
+		return nil, err
+	}
+
+	answer, err := flow.Answer(result.ID, input.QuestionID, input.Value)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.saveAnswers(ctx, result, flow, []*model.ScreenerAnswer{answer}); err != nil {
+		return nil, err
+	}
+
+	return &Progress{Result: result, Next: flow.Next()}, nil
+}
+
+// SubmitScreening records a screening done offline. The answers are replayed through the
+// questionnaire in the order given, so they must follow its branches. Submitting the same
+// screening again only records the answers that were not saved the first time.
+func (s *Service) SubmitScreening(ctx context.Context, requesterID uuid.UUID, input *SubmissionInput) (*model.ScreenerResult, error) {
+	if input == nil || input.ResultID == nil {
+		return nil, errorx.New(errorx.BadRequest, "offline screenings need a result ID")
+	}
+	subject, err := validateStart(&input.StartInput)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.authorize(ctx, requesterID, input.MotherID, input.ChildID); err != nil {
+		return nil, err
+	}
+
+	result, err := s.screenerRepo.GetResultByID(ctx, *input.ResultID)
+	exists := err == nil
+	if err != nil && !errorx.IsType(err, errorx.NotFound) {
+		s.log.Error("Failed to get screener result", logger.Fields{
+			"error":     err.Error(),
+			"result_id": input.ResultID.String(),
+		})
+		return nil, errorx.Wrap(err, "failed to get screener result")
+	}
+	if exists {
+		if !sameSubject(result, input.MotherID, input.ChildID) {
+			return nil, errorx.New(errorx.AlreadyExists, "a different screening already has this ID")
+		}
+		if result.Completed {
+			// A screening saved just before a failure may not have been acted on yet
+			s.actOnRed(ctx, result)
+			return result, nil
+		}
+		if input.QuestionnaireID != nil && (result.QuestionnaireID == nil || *result.QuestionnaireID != *input.QuestionnaireID) {
+			return nil, errorx.New(errorx.BadRequest, "screening was started with a different questionnaire version")
+		}
+	} else {
+		result = newResult(requesterID, &input.StartInput)
+		result.QuestionnaireID = input.QuestionnaireID
+	}
+
+	questionnaire, err := s.questionnaireFor(ctx, result)
+	if err != nil {
+		return nil, err
+	}
+	if questionnaire.Subject != subject {
+		return nil, errorx.Newf(errorx.BadRequest, "questionnaire is for screening a %s", questionnaire.Subject)
+	}
+	if questionnaire.IsDraft() {
+		return nil, errorx.New(errorx.BadRequest, "draft questionnaires cannot be used for screenings")
+	}
+	flow := NewFlow(questionnaire.Questions, result.Answers)
+	saved := len(result.Answers)
+
+	for i, submitted := range input.Answers {
+		if i < saved {
+			if result.Answers[i].QuestionID != submitted.QuestionID {
+				return nil, errorx.Newf(errorx.BadRequest, "answer %d does not match the answer already saved", i+1)
+			}
+			continue
+		}
+		if _, err := flow.Answer(result.ID, submitted.QuestionID, submitted.Value); err != nil {
+			return nil, errorx.Newf(errorx.BadRequest, "answer %d does not fit questionnaire version %d: %s",
+				i+1, questionnaire.Version, err.Error())
+		}
+	}
+
+	if !exists {
+		// The result is only marked complete once its answers are saved, so a retry after a
+		// failure part way through saves the rest
+		flow.Evaluate(result)
+		result.Completed = false
+		if err := s.screenerRepo.CreateResult(ctx, result); err != nil {
+			s.log.Error("Failed to create screener result", logger.Fields{
+				"error":     err.Error(),
+				"result_id": result.ID.String(),
+			})
+			return nil, errorx.Wrap(err, "failed to create screener result")
+		}
+	}
+
+	if err := s.saveAnswers(ctx, result, flow, flow.Answers()[saved:]); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// GetResult returns a screening with its answers
+func (s *Service) GetResult(ctx context.Context, requesterID, resultID uuid.UUID) (*model.ScreenerResult, error) {
+	result, err := s.getResult(ctx, resultID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.authorize(ctx, requesterID, result.MotherID, result.ChildID); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// saveAnswers stores new answers and the result's re-evaluated risk in one transaction, so
+// a failure part way through leaves no answers the risk does not reflect. A screening that
+// completes red is acted on after the commit, as an SOS, referral or visit raised inside
+// the transaction would be lost with a rollback while its notifications had already gone.
+func (s *Service) saveAnswers(ctx context.Context, result *model.ScreenerResult, flow *Flow, answers []*model.ScreenerAnswer) error {
+	err := s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		return s.storeAnswers(ctx, result, flow, answers)
+	})
+	if err != nil {
+		return err
+	}
+
+	s.actOnRed(ctx, result)
+	return nil
+}
+
+// actOnRed acts once on a completed red screening and records the action on the result.
+// The answers are already saved, so failing to record the action is logged, not returned.
+func (s *Service) actOnRed(ctx context.Context, result *model.ScreenerResult) {
+	if !result.Completed || result.RiskLevel != model.ScreenerRiskRed || result.ActionTaken != "" {
+		return
+	}
+
+	s.act(ctx, result)
+	if err := s.screenerRepo.UpdateResult(ctx, result); err != nil {
+		s.log.Error("Failed to record screener action", logger.Fields{
+			"error":     err.Error(),
+			"result_id": result.ID.String(),
+			"action":    result.ActionTaken,
+		})
+	}
+}
+
+// storeAnswers saves the answers and updates the result
+func (s *Service) storeAnswers(ctx context.Context, result *model.ScreenerResult, flow *Flow, answers []*model.ScreenerAnswer) error {
+	for _, answer := range answers {
+		if err := s.screenerRepo.CreateAnswer(ctx, answer); err != nil {
+			s.log.Error("Failed to create screener answer", logger.Fields{
+				"error":       err.Error(),
+				"result_id":   result.ID.String(),
+				"question_id": answer.QuestionID.String(),
+			})
+			return errorx.Wrap(err, "failed to create screener answer")
+		}
+	}
+
+	flow.Evaluate(result)
+	if err := s.screenerRepo.UpdateResult(ctx, result); err != nil {
+		s.log.Error("Failed to update screener result", logger.Fields{
+			"error":     err.Error(),
+			"result_id": result.ID.String(),
+		})
+		return errorx.Wrap(err, "failed to update screener result")
+	}
+	return nil
+}
+
+// resume rebuilds the flow of a screening from its saved answers
+func (s *Service) resume(ctx context.Context, result *model.ScreenerResult) (*Flow, error) {
+	questionnaire, err := s.questionnaireFor(ctx, result)
+	if err != nil {
+		return nil, err
+	}
+	return NewFlow(questionnaire.Questions, result.Answers), nil
+}
+
+// questionnaireFor loads the questionnaire version a screening is done with. A screening
+// without one, either new or started before questionnaires were versioned, is given the
+// subject's published version.
+func (s *Service) questionnaireFor(ctx context.Context, result *model.ScreenerResult) (*model.ScreenerQuestionnaire, error) {
+	if result.QuestionnaireID != nil {
+		questionnaire, err := s.screenerRepo.GetQuestionnaireByID(ctx, *result.QuestionnaireID)
+		if err != nil {
+			s.log.Error("Failed to get screener questionnaire", logger.Fields{
+				"error":            err.Error(),
+				"questionnaire_id": result.QuestionnaireID.String(),
+			})
+			return nil, errorx.Wrap(err, "failed to get screener questionnaire")
+		}
+		return questionnaire, nil
+	}
+
+	subject := model.ScreenerSubjectMother
+	if result.ChildID != nil {
+		subject = model.ScreenerSubjectChild
+	}
+	questionnaire, err := s.getPublished(ctx, subject)
+	if err != nil {
+		return nil, err
+	}
+	result.QuestionnaireID = &questionnaire.ID
+	return questionnaire, nil
+}
+
+// authorize checks the requester may screen the subject. Health workers can screen anyone;
+// mothers can screen themselves and the children they have access to.
+func (s *Service) authorize(ctx context.Context, requesterID uuid.UUID, motherID, childID *uuid.UUID) error {
+	requester, err := s.userRepo.GetByID(ctx, requesterID)
+	if err != nil {
+		s.log.Error("Failed to find requester", logger.Fields{
+			"error":   err.Error(),
+			"user_id": requesterID.String(),
+		})
+		return errorx.Wrap(err, "failed to find requester")
+	}
+
+	if childID != nil {
+		_, err := s.registryService.GetChild(ctx, requesterID, *childID)
+		return err
+	}
+
+	switch requester.Role {
+	case model.RoleCHW, model.RoleClinician, model.RoleAdmin:
+		mother, err := s.userRepo.GetByID(ctx, *motherID)
+		if err != nil {
+			s.log.Error("Failed to find mother", logger.Fields{
+				"error":   err.Error(),
+				"user_id": motherID.String(),
+			})
+			return errorx.Wrap(err, "failed to find mother")
+		}
+		if mother.Role != model.RoleMother {
+			return errorx.New(errorx.BadRequest, "only mothers can be given a maternal screening")
+		}
+		return nil
+	case model.RoleMother:
+		if *motherID != requester.ID {
+			return errorx.New(errorx.Forbidden, "mothers can only screen themselves")
+		}
+		return nil
+	default:
+		return errorx.New(errorx.Forbidden, "not allowed to record screenings")
+	}
+}
+
+// getPublished loads the questionnaire version a subject is screened with
+func (s *Service) getPublished(ctx context.Context, subject model.ScreenerSubject) (*model.ScreenerQuestionnaire, error) {
+	questionnaire, err := s.screenerRepo.GetPublishedQuestionnaire(ctx, subject)
+	if err != nil {
+		s.log.Error("Failed to get published screener questionnaire", logger.Fields{
+			"error":   err.Error(),
+			"subject": string(subject),
+		})
+		return nil, errorx.Wrap(err, "failed to get published screener questionnaire")
+	}
+	return questionnaire, nil
+}
+
+// getResult loads a screening with its answers
+func (s *Service) getResult(ctx context.Context, resultID uuid.UUID) (*model.ScreenerResult, error) {
+	result, err := s.screenerRepo.GetResultByID(ctx, resultID)
+	if err != nil {
+		s.log.Error("Failed to get screener result", logger.Fields{
+			"error":     err.Error(),
+			"result_id": resultID.String(),
+		})
+		return nil, errorx.Wrap(err, "failed to get screener result")
+	}
+	return result, nil
+}
+
+// newResult creates the result for a new screening
+func newResult(requesterID uuid.UUID, input *StartInput) *model.ScreenerResult {
+	id := uuid.New()
+	if input.ResultID != nil {
+		id = *input.ResultID
+	}
+	screenedAt := input.ScreenedAt
+	if screenedAt.IsZero() {
+		screenedAt = time.Now()
+	}
+
+	result := model.NewScreenerResult(id, screenedAt)
+	result.MotherID = input.MotherID
+	result.ChildID = input.ChildID
+	result.ScreenedByID = &requesterID
+	result.ScreenedByName = input.ScreenedByName
+	result.FacilityID = input.FacilityID
+	result.Latitude = input.Latitude
+	result.Longitude = input.Longitude
+	return result
+}
+
+// validateStart checks exactly one subject is being screened and returns which
+func validateStart(input *StartInput) (model.ScreenerSubject, error) {
+	if input == nil {
+		return "", errorx.New(errorx.BadRequest, "screening details are required")
+	}
+	if (input.MotherID == nil) == (input.ChildID == nil) {
+		return "", errorx.New(errorx.BadRequest, "a screening is for either a mother or a child")
+	}
+	if (input.Latitude == nil) != (input.Longitude == nil) {
+		return "", errorx.New(errorx.BadRequest, "latitude and longitude must be given together")
+	}
+	if input.ScreenedAt.After(time.Now().Add(time.Hour)) {
+		return "", errorx.New(errorx.BadRequest, "screening time cannot be in the future")
+	}
+	if input.ChildID != nil {
+		return model.ScreenerSubjectChild, nil
+	}
+	return model.ScreenerSubjectMother, nil
+}
+
+// sameSubject reports whether a result is a screening of the given mother or child
+func sameSubject(result *model.ScreenerResult, motherID, childID *uuid.UUID) bool {
+	if motherID != nil {
+		return result.MotherID != nil && *result.MotherID == *motherID
+	}
+	return result.ChildID != nil && *result.ChildID == *childID
+}
@@ -0,0 +1,248 @@
+package screener
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mamacare/services/internal/domain/model"
+	"github.com/mamacare/services/pkg/errorx"
+	"github.com/mamacare/services/pkg/logger"
+)
+
+// USSD replies start with CON when the session continues and END when it is over
+const (
+	ussdContinue = "CON "
+	ussdEnd      = "END "
+)
+
+// ussdNamespace derives a screening's result ID from its USSD session ID, so every request
+// in a session updates the same screening
+var ussdNamespace = uuid.MustParse("5b0c1f4e-2a7d-4c3e-9f61-8d2b7a4e6c15")
+
+// USSDRequest is a request from the USSD gateway. Text is every input of the session so
+// far joined by "*", so the session can be replayed without keeping any state.
+type USSDRequest struct {
+	SessionID   string
+	PhoneNumber string
+	Text        string
+}
+
+// ussdSubject is someone a mother can screen from her phone
+type ussdSubject struct {
+	label    string
+	motherID *uuid.UUID
+	childID  *uuid.UUID
+}
+
+// HandleUSSD runs a screening over USSD for a registered mother and returns the reply to
+// show on her phone. A mother with children first picks who the screening is for; each
+// question is then shown with numbered options and the answers are saved as they are given.
+func (s *Service) HandleUSSD(ctx context.Context, req *USSDRequest) (string, error) {
+	user, err := s.userRepo.FindByPhoneNumber(ctx, req.PhoneNumber)
+	if err != nil {
+		if errorx.IsType(err, errorx.NotFound) {
+			return ussdEnd + "This number is not registered with MamaCare.", nil
+		}
+		s.log.Error("Failed to find USSD user", logger.Fields{
+			"error":      err.Error(),
+			"session_id": req.SessionID,
+		})
+		return "", errorx.Wrap(err, "failed to find user")
+	}
+	if user.Role != model.RoleMother {
+		return ussdEnd + "USSD screening is for registered mothers. Please use the MamaCare app.", nil
+	}
+
+	subjects, err := s.ussdSubjects(ctx, user)
+	if err != nil {
+		return "", err
+	}
+
+	inputs := []string{}
+	if req.Text != "" {
+		inputs = strings.Split(req.Text, "*")
+	}
+
+	// Replay the choice of who is being screened
+	subject := subjects[0]
+	if len(subjects) > 1 {
+		chosen := false
+		invalid := false
+		for len(inputs) > 0 && !chosen {
+			choice, err := strconv.Atoi(strings.TrimSpace(inputs[0]))
+			inputs = inputs[1:]
+			if err == nil && choice >= 1 && choice <= len(subjects) {
+				subject, chosen = subjects[choice-1], true
+			} else {
+				invalid = true
+			}
+		}
+		if !chosen {
+			return ussdContinue + invalidPrefix(invalid) + subjectMenu(subjects), nil
+		}
+	}
+
+	return s.ussdScreening(ctx, user, req.SessionID, subject, inputs)
+}
+
+// ussdScreening replays the answers given so far, saves any new ones and returns the next question
+func (s *Service) ussdScreening(ctx context.Context, user *model.User, sessionID string, subject ussdSubject, inputs []string) (string, error) {
+	resultID := uuid.NewSHA1(ussdNamespace, []byte(sessionID))
+	result, err := s.screenerRepo.GetResultByID(ctx, resultID)
+	exists := err == nil
+	if err != nil && !errorx.IsType(err, errorx.NotFound) {
+		s.log.Error("Failed to get screener result", logger.Fields{
+			"error":      err.Error(),
+			"session_id": sessionID,
+		})
+		return "", errorx.Wrap(err, "failed to get screener result")
+	}
+	if exists && !sameSubject(result, subject.motherID, subject.childID) {
+		return ussdEnd + "This session has already been used for another screening.", nil
+	}
+	if !exists {
+		result = newResult(user.ID, &StartInput{
+			MotherID:   subject.motherID,
+			ChildID:    subject.childID,
+			ScreenedAt: time.Now(),
+		})
+	}
+
+	questionnaire, err := s.questionnaireFor(ctx, result)
+	if err != nil {
+		return "", err
+	}
+
+	// Replay from scratch: the gateway resends every input, including ones that were
+	// invalid and asked again, so the saved answers are only used to skip re-saving
+	flow := NewFlow(questionnaire.Questions, nil)
+	invalid := false
+	for _, input := range inputs {
+		question := flow.Next()
+		if question == nil {
+			break
+		}
+		value, ok := ussdValue(question, input)
+		if ok {
+			if _, err := flow.Answer(result.ID, question.ID, value); err != nil {
+				ok = false
+			}
+		}
+		invalid = !ok
+	}
+
+	saved := len(result.Answers)
+	if saved > len(flow.Answers()) {
+		saved = len(flow.Answers())
+	}
+	if !exists {
+		flow.Evaluate(result)
+		if err := s.screenerRepo.CreateResult(ctx, result); err != nil {
+			s.log.Error("Failed to create screener result", logger.Fields{
+				"error":     err.Error(),
+				"result_id": result.ID.String(),
+			})
+			return "", errorx.Wrap(err, "failed to create screener result")
+		}
+	}
+	if !exists || saved < len(flow.Answers()) {
+		if err := s.saveAnswers(ctx, result, flow, flow.Answers()[saved:]); err != nil {
+			return "", err
+		}
+	}
+
+	if next := flow.Next(); next != nil {
+		return ussdContinue + invalidPrefix(invalid) + questionPrompt(next), nil
+	}
+	return ussdEnd + outcomeMessage(result.RiskLevel), nil
+}
+
+// ussdSubjects lists who a mother can screen: herself, then each of her children
+func (s *Service) ussdSubjects(ctx context.Context, user *model.User) ([]ussdSubject, error) {
+	motherID := user.ID
+	subjects := []ussdSubject{{label: "Myself", motherID: &motherID}}
+
+	children, err := s.registryService.GetChildrenByParent(ctx, user.ID, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	for _, child := range children {
+		childID := child.ID
+		subjects = append(subjects, ussdSubject{label: child.FirstName, childID: &childID})
+	}
+	return subjects, nil
+}
+
+// ussdValue turns a numbered USSD input into an answer for the question
+func ussdValue(question *model.ScreenerQuestion, input string) (string, bool) {
+	input = strings.TrimSpace(input)
+	switch question.AnswerType {
+	case model.ScreenerAnswerBoolean:
+		switch input {
+		case "1":
+			return "true", true
+		case "2":
+			return "false", true
+		}
+		return "", false
+	case model.ScreenerAnswerMultipleChoice:
+		choice, err := strconv.Atoi(input)
+		if err != nil || choice < 1 || choice > len(question.AnswerOptions) {
+			return "", false
+		}
+		return question.AnswerOptions[choice-1].Value, true
+	default:
+		return input, input != ""
+	}
+}
+
+// questionPrompt shows a question with its numbered options
+func questionPrompt(question *model.ScreenerQuestion) string {
+	var b strings.Builder
+	b.WriteString(question.Text)
+	switch question.AnswerType {
+	case model.ScreenerAnswerBoolean:
+		b.WriteString("\n1. Yes\n2. No")
+	case model.ScreenerAnswerMultipleChoice:
+		for i, option := range question.AnswerOptions {
+			fmt.Fprintf(&b, "\n%d. %s", i+1, option.Value)
+		}
+	case model.ScreenerAnswerNumeric:
+		b.WriteString("\nEnter a number")
+	}
+	return b.String()
+}
+
+// subjectMenu asks who the screening is for
+func subjectMenu(subjects []ussdSubject) string {
+	var b strings.Builder
+	b.WriteString("Who is this health check for?")
+	for i, subject := range subjects {
+		fmt.Fprintf(&b, "\n%d. %s", i+1, subject.label)
+	}
+	return b.String()
+}
+
+// invalidPrefix tells the user their last input was not accepted
+func invalidPrefix(invalid bool) string {
+	if invalid {
+		return "Invalid choice. "
+	}
+	return ""
+}
+
+// outcomeMessage is the advice shown at the end of a USSD screening
+func outcomeMessage(risk model.ScreenerRisk) string {
+	switch risk {
+	case model.ScreenerRiskRed:
+		return "DANGER SIGN. Go to the nearest health facility now."
+	case model.ScreenerRiskYellow:
+		return "Please visit your health facility or CHW within 24 hours."
+	default:
+		return "Thank you. No danger signs found. Keep going to your clinic visits."
+	}
+}
@@ -0,0 +1,285 @@
+package screener
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/mamacare/services/internal/domain/model"
+)
+
+// translationKeyPattern is the format of translation keys, e.g. question.maternal.bleeding
+var translationKeyPattern = regexp.MustCompile(`^[a-z0-9_]+(\.[a-z0-9_]+)+$`)
+
+// questionTranslationPrefix starts every question's translation key
+const questionTranslationPrefix = "question."
+
+// IssueSeverity is how serious a problem in a questionnaire is
+type IssueSeverity string
+
+const (
+	// IssueError stops a questionnaire from being published
+	IssueError IssueSeverity = "error"
+	// IssueWarning is worth checking but does not stop publishing
+	IssueWarning IssueSeverity = "warning"
+)
+
+// Issue is a problem found in a questionnaire
+type Issue struct {
+	Severity IssueSeverity `json:"severity"`
+	// QuestionCode is the question the issue is about, empty for the whole questionnaire
+	QuestionCode string `json:"question_code,omitempty"`
+	Message      string `json:"message"`
+}
+
+// ValidationReport lists the problems found in a questionnaire
+type ValidationReport struct {
+	// Valid is true when there are no errors; warnings do not make a questionnaire invalid
+	Valid  bool    `json:"valid"`
+	Issues []Issue `json:"issues"`
+}
+
+// add records an issue
+func (r *ValidationReport) add(severity IssueSeverity, code, format string, args ...interface{}) {
+	if severity == IssueError {
+		r.Valid = false
+	}
+	r.Issues = append(r.Issues, Issue{
+		Severity:     severity,
+		QuestionCode: code,
+		Message:      fmt.Sprintf(format, args...),
+	})
+}
+
+// Errors returns the messages of the issues that stop publishing
+func (r *ValidationReport) Errors() []string {
+	var messages []string
+	for _, issue := range r.Issues {
+		if issue.Severity != IssueError {
+			continue
+		}
+		if issue.QuestionCode != "" {
+			messages = append(messages, issue.QuestionCode+": "+issue.Message)
+		} else {
+			messages = append(messages, issue.Message)
+		}
+	}
+	return messages
+}
+
+// ValidateQuestionnaire checks a questionnaire can be screened with: its branching has no
+// cycles or dependencies on questions that are never asked, every dependency can be met,
+// answers and risks are set up for the answer type, and translation keys are well formed
+// and unique. Inactive questions are never asked, so only their codes and keys are checked.
+func ValidateQuestionnaire(questionnaire *model.ScreenerQuestionnaire) *ValidationReport {
+	report := &ValidationReport{Valid: true, Issues: []Issue{}}
+
+	byID := make(map[uuid.UUID]*model.ScreenerQuestion, len(questionnaire.Questions))
+	codes := make(map[string]bool, len(questionnaire.Questions))
+	keys := make(map[string]string)
+	orders := make(map[int]string)
+	active := 0
+
+	for _, question := range questionnaire.Questions {
+		byID[question.ID] = question
+		if question.IsActive {
+			active++
+		}
+
+		switch {
+		case strings.TrimSpace(question.Code) == "":
+			report.add(IssueError, "", "question %q has no code", question.Text)
+		case codes[question.Code]:
+			report.add(IssueError, question.Code, "code is used by more than one question")
+		}
+		codes[question.Code] = true
+
+		checkTranslationKey(report, question.Code, question.TranslationKey, "translation key", keys)
+		if question.TranslationKey != "" && !strings.HasPrefix(question.TranslationKey, questionTranslationPrefix) {
+			report.add(IssueError, question.Code, "translation key %q must start with %q", question.TranslationKey, questionTranslationPrefix)
+		}
+
+		if !question.IsActive {
+			continue
+		}
+
+		if strings.TrimSpace(question.Text) == "" {
+			report.add(IssueError, question.Code, "question has no text")
+		}
+		if strings.TrimSpace(question.Category) == "" {
+			report.add(IssueError, question.Code, "question has no category")
+		}
+		if other, ok := orders[question.DisplayOrder]; ok {
+			report.add(IssueWarning, question.Code, "shares display order %d with %s, so their order is by code", question.DisplayOrder, other)
+		} else {
+			orders[question.DisplayOrder] = question.Code
+		}
+
+		validateAnswers(report, question, keys)
+	}
+
+	if active == 0 {
+		report.add(IssueError, "", "questionnaire has no active questions")
+	}
+
+	for _, question := range questionnaire.Questions {
+		if question.IsActive {
+			validateDependency(report, question, byID)
+		}
+	}
+	validateCycles(report, questionnaire.Questions, byID)
+
+	return report
+}
+
+// validateAnswers checks the question's answer options and risk suit its answer type
+func validateAnswers(report *ValidationReport, question *model.ScreenerQuestion, keys map[string]string) {
+	if question.RiskCategory != nil && !question.RiskCategory.IsValid() {
+		report.add(IssueError, question.Code, "invalid risk category %q", *question.RiskCategory)
+	}
+
+	switch question.AnswerType {
+	case model.ScreenerAnswerBoolean:
+	case model.ScreenerAnswerMultipleChoice:
+		if len(question.AnswerOptions) < 2 {
+			report.add(IssueError, question.Code, "multiple choice questions need at least two options")
+		}
+		values := make(map[string]bool, len(question.AnswerOptions))
+		risky := false
+		for _, option := range question.AnswerOptions {
+			value := strings.ToLower(strings.TrimSpace(option.Value))
+			switch {
+			case value == "":
+				report.add(IssueError, question.Code, "an answer option has no value")
+			case values[value]:
+				report.add(IssueError, question.Code, "answer option %q is listed more than once", option.Value)
+			}
+			values[value] = true
+
+			if option.RiskLevel != nil {
+				if !option.RiskLevel.IsValid() {
+					report.add(IssueError, question.Code, "answer option %q has invalid risk level %q", option.Value, *option.RiskLevel)
+				} else if option.RiskLevel.Rank() > 0 {
+					risky = true
+				}
+			}
+
+			if option.TranslationKey == "" {
+				report.add(IssueWarning, question.Code, "answer option %q has no translation key, so it is always shown in English", option.Value)
+			} else {
+				checkTranslationKey(report, question.Code, option.TranslationKey, fmt.Sprintf("answer option %q translation key", option.Value), keys)
+			}
+		}
+		if question.IsDangerSign && !risky {
+			report.add(IssueWarning, question.Code, "danger sign has no answer option with a risk level, so it never raises the risk")
+		}
+	case model.ScreenerAnswerNumeric, model.ScreenerAnswerText:
+		if question.IsDangerSign {
+			report.add(IssueWarning, question.Code, "only yes/no and multiple choice answers can report a danger sign")
+		}
+	default:
+		report.add(IssueError, question.Code, "invalid answer type %q", question.AnswerType)
+		return
+	}
+
+	if question.AnswerType != model.ScreenerAnswerMultipleChoice && len(question.AnswerOptions) > 0 {
+		report.add(IssueError, question.Code, "only multiple choice questions have answer options")
+	}
+	if question.AnswerType != model.ScreenerAnswerBoolean && question.RiskCategory != nil {
+		report.add(IssueWarning, question.Code, "risk category only applies to yes/no answers; set risk levels on the answer options instead")
+	}
+}
+
+// validateDependency checks the question depends on an active question of the same
+// questionnaire, with an answer that question can be given
+func validateDependency(report *ValidationReport, question *model.ScreenerQuestion, byID map[uuid.UUID]*model.ScreenerQuestion) {
+	if question.DependsOnQuestionID == nil {
+		if question.DependsOnAnswer != nil {
+			report.add(IssueError, question.Code, "depends on answer %q but not on a question", *question.DependsOnAnswer)
+		}
+		return
+	}
+
+	dependency, ok := byID[*question.DependsOnQuestionID]
+	if !ok {
+		report.add(IssueError, question.Code, "depends on question %s, which is not in this questionnaire", question.DependsOnQuestionID)
+		return
+	}
+	if dependency.ID == question.ID {
+		return // reported as a cycle
+	}
+	if !dependency.IsActive {
+		report.add(IssueError, question.Code, "depends on %s, which is inactive, so it is never asked", dependency.Code)
+		return
+	}
+	if dependency.DisplayOrder > question.DisplayOrder {
+		report.add(IssueWarning, question.Code, "is displayed before %s, which it depends on, so it is asked out of order", dependency.Code)
+	}
+
+	if question.DependsOnAnswer == nil {
+		return
+	}
+	answer, err := ParseAnswer(dependency, *question.DependsOnAnswer)
+	if err != nil {
+		report.add(IssueError, question.Code, "depends on answer %q, which %s can never be given", *question.DependsOnAnswer, dependency.Code)
+		return
+	}
+	if !strings.EqualFold(answer.Value(), strings.TrimSpace(*question.DependsOnAnswer)) {
+		report.add(IssueError, question.Code, "depends on answer %q, which never matches; use %q", *question.DependsOnAnswer, answer.Value())
+	}
+}
+
+// validateCycles reports every chain of dependencies that leads back to where it started.
+// Each question depends on at most one other, so following the chain is enough.
+func validateCycles(report *ValidationReport, questions []*model.ScreenerQuestion, byID map[uuid.UUID]*model.ScreenerQuestion) {
+	reported := make(map[uuid.UUID]bool)
+	for _, start := range questions {
+		if reported[start.ID] {
+			continue
+		}
+
+		path := []*model.ScreenerQuestion{start}
+		seen := map[uuid.UUID]bool{start.ID: true}
+		current := start
+		for current.DependsOnQuestionID != nil {
+			next, ok := byID[*current.DependsOnQuestionID]
+			if !ok || reported[next.ID] {
+				break
+			}
+			if next.ID == start.ID {
+				codes := make([]string, 0, len(path)+1)
+				for _, question := range path {
+					codes = append(codes, question.Code)
+					reported[question.ID] = true
+				}
+				codes = append(codes, start.Code)
+				report.add(IssueError, start.Code, "dependencies form a cycle: %s", strings.Join(codes, " -> "))
+				break
+			}
+			if seen[next.ID] {
+				break // a cycle further along the chain, reported from one of its own questions
+			}
+			seen[next.ID] = true
+			path = append(path, next)
+			current = next
+		}
+	}
+}
+
+// checkTranslationKey checks a translation key is well formed and not used elsewhere in
+// the questionnaire. keys maps the keys seen so far to where they were used.
+func checkTranslationKey(report *ValidationReport, code, key, label string, keys map[string]string) {
+	if key == "" {
+		report.add(IssueError, code, "%s is required", label)
+		return
+	}
+	if !translationKeyPattern.MatchString(key) {
+		report.add(IssueError, code, "%s %q must be lower case words separated by dots, e.g. question.maternal.bleeding", label, key)
+	}
+	if other, ok := keys[key]; ok {
+		report.add(IssueError, code, "%s %q is also used by %s", label, key, other)
+		return
+	}
+	keys[key] = code
+}
@@ -0,0 +1,109 @@
+package screener
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/mamacare/services/internal/domain/model"
+)
+
+// dependency is a question code and the code of the question it depends on, if any
+type dependency struct {
+	code, dependsOn string
+}
+
+// newQuestions builds questions in the order given, depending on each other by code.
+// A dependency on a code that is not listed points at a question outside the questionnaire.
+func newQuestions(dependencies []dependency) ([]*model.ScreenerQuestion, map[uuid.UUID]*model.ScreenerQuestion) {
+	ids := make(map[string]uuid.UUID, len(dependencies))
+	for _, d := range dependencies {
+		ids[d.code] = uuid.New()
+	}
+
+	questions := make([]*model.ScreenerQuestion, 0, len(dependencies))
+	byID := make(map[uuid.UUID]*model.ScreenerQuestion, len(dependencies))
+	for _, d := range dependencies {
+		question := &model.ScreenerQuestion{ID: ids[d.code], Code: d.code, IsActive: true}
+		if d.dependsOn != "" {
+			id, ok := ids[d.dependsOn]
+			if !ok {
+				id = uuid.New()
+			}
+			question.DependsOnQuestionID = &id
+		}
+		questions = append(questions, question)
+		byID[question.ID] = question
+	}
+	return questions, byID
+}
+
+func TestValidateCycles(t *testing.T) {
+	tests := []struct {
+		name         string
+		dependencies []dependency
+		want         []string
+	}{
+		{
+			name:         "no dependencies",
+			dependencies: []dependency{{"a", ""}, {"b", ""}},
+		},
+		{
+			name:         "chain without a cycle",
+			dependencies: []dependency{{"a", ""}, {"b", "a"}, {"c", "b"}},
+		},
+		{
+			name:         "dependency outside the questionnaire",
+			dependencies: []dependency{{"a", "missing"}},
+		},
+		{
+			name:         "question depends on itself",
+			dependencies: []dependency{{"a", "a"}},
+			want:         []string{"a: dependencies form a cycle: a -> a"},
+		},
+		{
+			name:         "two questions depend on each other",
+			dependencies: []dependency{{"a", "b"}, {"b", "a"}},
+			want:         []string{"a: dependencies form a cycle: a -> b -> a"},
+		},
+		{
+			name:         "three question cycle",
+			dependencies: []dependency{{"a", "c"}, {"b", "a"}, {"c", "b"}},
+			want:         []string{"a: dependencies form a cycle: a -> c -> b -> a"},
+		},
+		{
+			name:         "chain into a cycle listed first",
+			dependencies: []dependency{{"c", "a"}, {"a", "b"}, {"b", "a"}},
+			want:         []string{"a: dependencies form a cycle: a -> b -> a"},
+		},
+		{
+			name:         "chain into a cycle listed last",
+			dependencies: []dependency{{"a", "b"}, {"b", "a"}, {"c", "a"}},
+			want:         []string{"a: dependencies form a cycle: a -> b -> a"},
+		},
+		{
+			name:         "two separate cycles",
+			dependencies: []dependency{{"a", "b"}, {"b", "a"}, {"c", "c"}},
+			want: []string{
+				"a: dependencies form a cycle: a -> b -> a",
+				"c: dependencies form a cycle: c -> c",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			questions, byID := newQuestions(tt.dependencies)
+			report := &ValidationReport{Valid: true}
+
+			validateCycles(report, questions, byID)
+
+			if got := report.Errors(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("validateCycles() errors = %q, want %q", got, tt.want)
+			}
+			if report.Valid != (len(tt.want) == 0) {
+				t.Errorf("validateCycles() valid = %v, want %v", report.Valid, len(tt.want) == 0)
+			}
+		})
+	}
+}
@@ -0,0 +1,237 @@
+package trend
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mamacare/services/internal/domain/model"
+	"github.com/mamacare/services/internal/domain/repository"
+	"github.com/mamacare/services/pkg/errorx"
+	"github.com/mamacare/services/pkg/logger"
+)
+
+const (
+	// DefaultLookbackDays is how much history the batch job analyses per mother
+	DefaultLookbackDays = 90
+	// postpartumFollowUp is how long after delivery a mother's vitals are still followed
+	postpartumFollowUp = 42 * 24 * time.Hour
+	// notifyWindow is how recent a mother's latest reading must be for her CHW to be notified,
+	// so a run only notifies about deteriorations that new readings revealed
+	notifyWindow = 24 * time.Hour
+)
+
+// NotificationService defines the interface for sending trend deterioration notifications
+type NotificationService interface {
+	// SendTrendDeterioration tells a health worker about a deteriorating trend
+	SendTrendDeterioration(ctx context.Context, recipientID uuid.UUID, mother *model.Mother, deterioration *Deterioration) error
+}
+
+// Deterioration is a mother whose trends need attention
+type Deterioration struct {
+	MotherID     uuid.UUID     `json:"mother_id"`
+	HighestAlert AlertLevel    `json:"highest_alert"`
+	LastReading  time.Time     `json:"last_reading"`
+	Trends       []TrendResult `json:"trends"`
+	Notified     bool          `json:"notified"`
+}
+
+// BatchReport is the outcome of a trend batch run
+type BatchReport struct {
+	RunAt           time.Time        `json:"run_at"`
+	LookbackDays    int              `json:"lookback_days"`
+	MothersAnalyzed int              `json:"mothers_analyzed"`
+	MothersSkipped  int              `json:"mothers_skipped"`
+	Deteriorations  []*Deterioration `json:"deteriorations"`
+}
+
+// BatchJob runs trend analysis across all active mothers
+type BatchJob struct {
+	trendService     *Service
+	motherRepo       repository.MotherRepository
+	healthMetricRepo repository.HealthMetricRepository
+	visitRepo        repository.VisitRepository
+	notifyService    NotificationService
+	log              logger.Logger
+}
+
+// NewBatchJob creates a new trend batch job
+func NewBatchJob(
+	trendService *Service,
+	motherRepo repository.MotherRepository,
+	healthMetricRepo repository.HealthMetricRepository,
+	visitRepo repository.VisitRepository,
+	notifyService NotificationService,
+	log logger.Logger,
+) *BatchJob {
+	return &BatchJob{
+		trendService:     trendService,
+		motherRepo:       motherRepo,
+		healthMetricRepo: healthMetricRepo,
+		visitRepo:        visitRepo,
+		notifyService:    notifyService,
+		log:              log,
+	}
+}
+
+// Run analyses the trends of every pregnant or recently delivered mother and reports
+// those whose readings are deteriorating, most urgent first. It is meant to run daily.
+func (j *BatchJob) Run(ctx context.Context, lookbackDays int) (*BatchReport, error) {
+	if lookbackDays <= 0 {
+		lookbackDays = DefaultLookbackDays
+	}
+
+	mothers, err := j.motherRepo.FindAll(ctx)
+	if err != nil {
+		j.log.Error("Failed to list mothers", logger.Fields{
+			"error": err.Error(),
+		})
+		return nil, errorx.Wrap(err, "failed to list mothers")
+	}
+
+	now := time.Now()
+	report := &BatchReport{
+		RunAt:          now,
+		LookbackDays:   lookbackDays,
+		Deteriorations: []*Deterioration{},
+	}
+
+	for _, mother := range mothers {
+		if !isActive(mother, now) {
+			continue
+		}
+
+		metrics, err := j.healthMetricRepo.FindByDateRange(ctx, mother.ID, now.AddDate(0, 0, -lookbackDays), now)
+		if err != nil {
+			j.log.Warn("Failed to get health metrics for trend analysis", logger.Fields{
+				"error":     err.Error(),
+				"mother_id": mother.ID.String(),
+			})
+			report.MothersSkipped++
+			continue
+		}
+		if len(metrics) < MinDataPoints {
+			report.MothersSkipped++
+			continue
+		}
+
+		analysis, err := j.trendService.AnalyzeTrends(mother.ID, metrics)
+		if err != nil {
+			report.MothersSkipped++
+			continue
+		}
+		report.MothersAnalyzed++
+
+		deterioration := deteriorationFrom(analysis)
+		if deterioration == nil {
+			continue
+		}
+
+		if now.Sub(deterioration.LastReading) <= notifyWindow {
+			deterioration.Notified = j.notify(ctx, mother, deterioration)
+		}
+		report.Deteriorations = append(report.Deteriorations, deterioration)
+	}
+
+	sort.SliceStable(report.Deteriorations, func(a, b int) bool {
+		return alertRank(report.Deteriorations[a].HighestAlert) > alertRank(report.Deteriorations[b].HighestAlert)
+	})
+
+	j.log.Info("Trend batch finished", logger.Fields{
+		"mothers":        len(mothers),
+		"analyzed":       report.MothersAnalyzed,
+		"skipped":        report.MothersSkipped,
+		"deteriorations": len(report.Deteriorations),
+	})
+
+	return report, nil
+}
+
+// isActive checks if a mother is pregnant or still in postpartum follow-up
+func isActive(mother *model.Mother, now time.Time) bool {
+	if !mother.IsPostpartum() {
+		return true
+	}
+	return mother.DeliveryDate != nil && now.Sub(*mother.DeliveryDate) <= postpartumFollowUp
+}
+
+// deteriorationFrom keeps the trends that need attention and can be relied on
+func deteriorationFrom(analysis *TrendAnalysis) *Deterioration {
+	deterioration := &Deterioration{
+		MotherID:     analysis.MotherID,
+		HighestAlert: AlertNone,
+		LastReading:  analysis.DataEndDate,
+	}
+
+	for _, trend := range analysis.Trends {
+		if trend.AlertLevel == AlertNone {
+			continue
+		}
+		// Urgent readings and jumps in the newest reading are reported however little data there is
+		if trend.Confidence == ConfidenceLow && trend.AlertLevel != AlertUrgent && trend.LatestJump == nil {
+			continue
+		}
+		deterioration.Trends = append(deterioration.Trends, trend)
+		if alertRank(trend.AlertLevel) > alertRank(deterioration.HighestAlert) {
+			deterioration.HighestAlert = trend.AlertLevel
+		}
+	}
+
+	if len(deterioration.Trends) == 0 {
+		return nil
+	}
+	return deterioration
+}
+
+// notify sends a deterioration to the mother's CHW, or her clinician if she has no CHW
+func (j *BatchJob) notify(ctx context.Context, mother *model.Mother, deterioration *Deterioration) bool {
+	if j.notifyService == nil {
+		return false
+	}
+
+	recipientID := j.careWorker(ctx, mother.ID)
+	if recipientID == nil {
+		return false
+	}
+
+	if err := j.notifyService.SendTrendDeterioration(ctx, *recipientID, mother, deterioration); err != nil {
+		j.log.Warn("Failed to send trend deterioration notification", logger.Fields{
+			"error":        err.Error(),
+			"mother_id":    mother.ID.String(),
+			"recipient_id": recipientID.String(),
+		})
+		return false
+	}
+	return true
+}
+
+// careWorker returns the CHW, or failing that the clinician, on the mother's most recent visits
+func (j *BatchJob) careWorker(ctx context.Context, motherID uuid.UUID) *uuid.UUID {
+	options := repository.NewVisitQueryOptions().
+		WithOrder("scheduled_time", "DESC").
+		WithLimit(20)
+
+	visits, err := j.visitRepo.GetByMotherID(ctx, motherID, options)
+	if err != nil {
+		j.log.Warn("Failed to get visits for care worker", logger.Fields{
+			"error":     err.Error(),
+			"mother_id": motherID.String(),
+		})
+		return nil
+	}
+
+	var clinicianID *uuid.UUID
+	for _, visit := range visits {
+		if visit.Status == model.VisitStatusCancelled {
+			continue
+		}
+		if visit.CHWID != nil {
+			return visit.CHWID
+		}
+		if clinicianID == nil && visit.ClinicianID != nil {
+			clinicianID = visit.ClinicianID
+		}
+	}
+	return clinicianID
+}
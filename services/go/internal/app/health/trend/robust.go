@@ -0,0 +1,391 @@
+package trend
+
+import (
+	"math"
+	"sort"
+	"time"
+)
+
+// ConfidenceLevel defines how much a trend result can be relied on
+type ConfidenceLevel string
+
+const (
+	// ConfidenceLow indicates few, short or noisy readings
+	ConfidenceLow ConfidenceLevel = "low"
+	// ConfidenceMedium indicates a usable but limited series
+	ConfidenceMedium ConfidenceLevel = "medium"
+	// ConfidenceHigh indicates enough clean readings over a long enough period
+	ConfidenceHigh ConfidenceLevel = "high"
+)
+
+// OutlierReason defines why a reading was left out of the trend
+type OutlierReason string
+
+const (
+	// OutlierImplausible marks a value that cannot be physiological, usually a typo
+	OutlierImplausible OutlierReason = "implausible"
+	// OutlierSpike marks a value far from the mother's other readings
+	OutlierSpike OutlierReason = "spike"
+)
+
+// Outlier is a reading left out of the trend
+type Outlier struct {
+	Date   time.Time     `json:"date"`
+	Value  float64       `json:"value"`
+	Reason OutlierReason `json:"reason"`
+}
+
+// LatestJump is a newest reading far from the mother's recent readings. It stays in
+// the trend, since only later readings can show whether it was a spike.
+type LatestJump struct {
+	Date      time.Time `json:"date"`
+	Value     float64   `json:"value"`
+	Expected  float64   `json:"expected"`  // median of the readings before it
+	Deviation float64   `json:"deviation"` // value minus expected
+}
+
+// Baseline is the mother's own usual level for a metric
+type Baseline struct {
+	Value  float64   `json:"value"`  // median
+	Spread float64   `json:"spread"` // robust standard deviation
+	Points int       `json:"points"`
+	From   time.Time `json:"from"`
+	To     time.Time `json:"to"`
+}
+
+// ChangePoint is a point where a metric shifted to a new level
+type ChangePoint struct {
+	Date   time.Time `json:"date"`
+	Before float64   `json:"before"` // median level before the change
+	After  float64   `json:"after"`  // median level after the change
+	Shift  float64   `json:"shift"`
+	Score  float64   `json:"score"` // shift in units of noise, adjusted for segment sizes
+}
+
+// metricLimits holds the per-metric settings for robust trend detection
+type metricLimits struct {
+	plausibleMin float64
+	plausibleMax float64
+	// minChange is the smallest change that is clinically meaningful
+	minChange float64
+	// baselineAlert is the change from the mother's baseline that needs review, 0 for none
+	baselineAlert float64
+}
+
+const (
+	// spikeThreshold is how many robust standard deviations from the median make a spike
+	spikeThreshold = 4.0
+	// minSpikeSeries is the fewest readings needed before spikes can be judged
+	minSpikeSeries = 5
+	// changePointScore is the score a level shift needs to count as a change point
+	changePointScore = 3.0
+	// minSegment is the fewest readings on each side of a change point
+	minSegment = 2
+	// maxChangePoints limits how many change points are reported per metric
+	maxChangePoints = 2
+	// baselinePoints is how many early readings form the baseline when there is no change point
+	baselinePoints = 5
+	// madScale converts a median absolute deviation to a standard deviation
+	madScale = 1.4826
+)
+
+// rejectOutliers removes implausible values and isolated spikes from a series
+func rejectOutliers(data TimeSeriesData, limits metricLimits) (TimeSeriesData, []Outlier) {
+	var clean TimeSeriesData
+	var outliers []Outlier
+
+	for i, value := range data.Values {
+		if value < limits.plausibleMin || value > limits.plausibleMax {
+			outliers = append(outliers, Outlier{Date: data.Dates[i], Value: value, Reason: OutlierImplausible})
+			continue
+		}
+		clean.Dates = append(clean.Dates, data.Dates[i])
+		clean.Values = append(clean.Values, value)
+	}
+
+	if len(clean.Values) < minSpikeSeries {
+		return clean, outliers
+	}
+
+	// A spike is judged against its neighbours so that a real change in level is kept,
+	// and only once later readings have gone back to the earlier level. The newest
+	// reading is never a spike since no later reading contradicts it.
+	var kept TimeSeriesData
+	for i, value := range clean.Values {
+		if i < len(clean.Values)-1 && isSpike(clean.Values, i, limits) {
+			outliers = append(outliers, Outlier{Date: clean.Dates[i], Value: value, Reason: OutlierSpike})
+			continue
+		}
+		kept.Dates = append(kept.Dates, clean.Dates[i])
+		kept.Values = append(kept.Values, value)
+	}
+
+	return kept, outliers
+}
+
+// isSpike reports whether values[i] is far from its neighbours and from the readings after it
+func isSpike(values []float64, i int, limits metricLimits) bool {
+	center, spread := neighbourLevel(values, i, limits)
+	if math.Abs(values[i]-center) <= spikeThreshold*spread {
+		return false
+	}
+
+	end := i + 4
+	if end > len(values) {
+		end = len(values)
+	}
+	return math.Abs(values[i]-median(values[i+1:end])) > spikeThreshold*spread
+}
+
+// detectLatestJump returns the newest reading when it is as far from the readings before
+// it as a spike would be, or nil
+func detectLatestJump(data TimeSeriesData, limits metricLimits) *LatestJump {
+	n := len(data.Values)
+	if n < minSpikeSeries {
+		return nil
+	}
+
+	center, spread := neighbourLevel(data.Values, n-1, limits)
+	deviation := data.Values[n-1] - center
+	if math.Abs(deviation) <= spikeThreshold*spread {
+		return nil
+	}
+
+	return &LatestJump{
+		Date:      data.Dates[n-1],
+		Value:     data.Values[n-1],
+		Expected:  center,
+		Deviation: deviation,
+	}
+}
+
+// neighbourLevel returns the median and robust spread of the readings around values[i],
+// with the spread floored at half the smallest meaningful change
+func neighbourLevel(values []float64, i int, limits metricLimits) (float64, float64) {
+	neighbours := windowAround(values, i, 3)
+	center := median(neighbours)
+	spread := robustStdDev(neighbours, center)
+	if spread < limits.minChange/2 {
+		spread = limits.minChange / 2
+	}
+	return center, spread
+}
+
+// windowAround returns up to size values either side of index i, excluding i
+func windowAround(values []float64, i, size int) []float64 {
+	start := i - size
+	if start < 0 {
+		start = 0
+	}
+	end := i + size + 1
+	if end > len(values) {
+		end = len(values)
+	}
+
+	window := make([]float64, 0, end-start)
+	for j := start; j < end; j++ {
+		if j != i {
+			window = append(window, values[j])
+		}
+	}
+	return window
+}
+
+// theilSenSlope returns the median of pairwise slopes in units per day, which copes
+// with irregular spacing and is not pulled by a single bad reading
+func theilSenSlope(data TimeSeriesData) float64 {
+	var slopes []float64
+	for i := 0; i < len(data.Values); i++ {
+		for j := i + 1; j < len(data.Values); j++ {
+			days := data.Dates[j].Sub(data.Dates[i]).Hours() / 24
+			if days < 1.0/24 {
+				// Readings taken within the hour say nothing about the slope
+				continue
+			}
+			slopes = append(slopes, (data.Values[j]-data.Values[i])/days)
+		}
+	}
+	if len(slopes) == 0 {
+		return 0
+	}
+	return median(slopes)
+}
+
+// residualNoise returns the robust spread of readings around the Theil-Sen line
+func residualNoise(data TimeSeriesData, slopePerDay float64) float64 {
+	if len(data.Values) < 3 {
+		return 0
+	}
+
+	start := data.Dates[0]
+	detrended := make([]float64, len(data.Values))
+	for i, value := range data.Values {
+		detrended[i] = value - slopePerDay*data.Dates[i].Sub(start).Hours()/24
+	}
+	return robustStdDev(detrended, median(detrended))
+}
+
+// detectChangePoints finds level shifts by binary segmentation on medians
+func detectChangePoints(data TimeSeriesData, limits metricLimits) []ChangePoint {
+	var points []ChangePoint
+	segmentChangePoints(data, 0, len(data.Values), limits, &points)
+
+	sort.Slice(points, func(i, j int) bool {
+		return points[i].Date.Before(points[j].Date)
+	})
+	if len(points) > maxChangePoints {
+		// Keep the most recent shifts, they matter most for current care
+		points = points[len(points)-maxChangePoints:]
+	}
+	return points
+}
+
+// segmentChangePoints looks for the strongest shift in values[start:end] and recurses on both sides
+func segmentChangePoints(data TimeSeriesData, start, end int, limits metricLimits, points *[]ChangePoint) {
+	if end-start < 2*minSegment || len(*points) >= maxChangePoints*2 {
+		return
+	}
+
+	values := data.Values[start:end]
+	bestScore := 0.0
+	bestSplit := -1
+	var bestBefore, bestAfter float64
+
+	for split := minSegment; split <= len(values)-minSegment; split++ {
+		before := values[:split]
+		after := values[split:]
+		beforeMedian := median(before)
+		afterMedian := median(after)
+
+		noise := pooledNoise(before, beforeMedian, after, afterMedian)
+		if noise < limits.minChange/2 {
+			noise = limits.minChange / 2
+		}
+
+		n1 := float64(len(before))
+		n2 := float64(len(after))
+		score := math.Abs(afterMedian-beforeMedian) / noise * math.Sqrt(n1*n2/(n1+n2))
+		if score > bestScore {
+			bestScore = score
+			bestSplit = split
+			bestBefore = beforeMedian
+			bestAfter = afterMedian
+		}
+	}
+
+	if bestSplit < 0 || bestScore < changePointScore || math.Abs(bestAfter-bestBefore) < limits.minChange {
+		return
+	}
+
+	*points = append(*points, ChangePoint{
+		Date:   data.Dates[start+bestSplit],
+		Before: bestBefore,
+		After:  bestAfter,
+		Shift:  bestAfter - bestBefore,
+		Score:  bestScore,
+	})
+
+	segmentChangePoints(data, start, start+bestSplit, limits, points)
+	segmentChangePoints(data, start+bestSplit, end, limits, points)
+}
+
+// pooledNoise combines the robust spread of two segments
+func pooledNoise(a []float64, aMedian float64, b []float64, bMedian float64) float64 {
+	sa := robustStdDev(a, aMedian)
+	sb := robustStdDev(b, bMedian)
+	na := float64(len(a))
+	nb := float64(len(b))
+	return math.Sqrt((sa*sa*na + sb*sb*nb) / (na + nb))
+}
+
+// calculateBaseline takes the mother's usual level from the readings before the first
+// change point, or from her earliest readings when her level has not shifted
+func calculateBaseline(data TimeSeriesData, changePoints []ChangePoint) *Baseline {
+	if len(data.Values) == 0 {
+		return nil
+	}
+
+	end := len(data.Values)
+	if len(changePoints) > 0 {
+		for i, date := range data.Dates {
+			if !date.Before(changePoints[0].Date) {
+				end = i
+				break
+			}
+		}
+	} else if end > baselinePoints {
+		end = baselinePoints
+	}
+	if end == 0 {
+		end = 1
+	}
+
+	values := data.Values[:end]
+	center := median(values)
+	return &Baseline{
+		Value:  center,
+		Spread: robustStdDev(values, center),
+		Points: len(values),
+		From:   data.Dates[0],
+		To:     data.Dates[end-1],
+	}
+}
+
+// calculateConfidence scores how far a trend can be trusted from the number of readings,
+// the period they cover, how clearly the signal stands out from the noise and how many
+// readings had to be rejected
+func calculateConfidence(points, outliers int, spanDays, signal, noise float64) (ConfidenceLevel, float64) {
+	sizeFactor := math.Min(float64(points)/8, 1)
+	spanFactor := math.Min(spanDays/28, 1)
+
+	signalFactor := 1.0
+	if noise > 0 {
+		signalFactor = math.Abs(signal) / (math.Abs(signal) + 2*noise)
+		// A flat, quiet series is a confident "stable"
+		if math.Abs(signal) < noise {
+			signalFactor = 1 - signalFactor
+		}
+	}
+
+	score := 0.4*sizeFactor + 0.3*spanFactor + 0.3*signalFactor
+	if total := points + outliers; total > 0 && outliers > 0 {
+		score *= 1 - 0.5*float64(outliers)/float64(total)
+	}
+	score = math.Round(score*100) / 100
+
+	switch {
+	case score >= 0.7:
+		return ConfidenceHigh, score
+	case score >= 0.4:
+		return ConfidenceMedium, score
+	default:
+		return ConfidenceLow, score
+	}
+}
+
+// median returns the median of values without modifying them
+func median(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+
+	mid := len(sorted) / 2
+	if len(sorted)%2 == 0 {
+		return (sorted[mid-1] + sorted[mid]) / 2
+	}
+	return sorted[mid]
+}
+
+// robustStdDev estimates the standard deviation from the median absolute deviation
+func robustStdDev(values []float64, center float64) float64 {
+	if len(values) < 2 {
+		return 0
+	}
+	deviations := make([]float64, len(values))
+	for i, value := range values {
+		deviations[i] = math.Abs(value - center)
+	}
+	return madScale * median(deviations)
+}
@@ -1,6 +1,7 @@
 package trend
 
 import (
+	"fmt"
 	"math"
 	"sort"
 	"time"
@@ -43,15 +44,24 @@ const (
 
 // TrendResult represents the detected trend in a specific metric
 type TrendResult struct {
-	MetricName       string    `json:"metric_name"`
-	TrendType        TrendType `json:"trend_type"`
-	AlertLevel       AlertLevel `json:"alert_level"`
-	Description      string    `json:"description"`
-	RecommendedAction string    `json:"recommended_action,omitempty"`
-	FirstValue       float64   `json:"first_value"`
-	LastValue        float64   `json:"last_value"`
-	ChangeRate       float64   `json:"change_rate"`       // Percentage change
-	ChangePerDay     float64   `json:"change_per_day"`    // Absolute change per day
+	MetricName        string          `json:"metric_name"`
+	TrendType         TrendType       `json:"trend_type"`
+	AlertLevel        AlertLevel      `json:"alert_level"`
+	Description       string          `json:"description"`
+	RecommendedAction string          `json:"recommended_action,omitempty"`
+	FirstValue        float64         `json:"first_value"`
+	LastValue         float64         `json:"last_value"`
+	ChangeRate        float64         `json:"change_rate"`    // Percentage change over the series
+	ChangePerDay      float64         `json:"change_per_day"` // Absolute change per day
+	DataPoints        int             `json:"data_points"`
+	Noise             float64         `json:"noise"` // Robust spread of readings around the trend line
+	Confidence        ConfidenceLevel `json:"confidence"`
+	ConfidenceScore   float64         `json:"confidence_score"`
+	Baseline          *Baseline       `json:"baseline,omitempty"`
+	BaselineDeviation float64         `json:"baseline_deviation"` // Recent level minus baseline
+	ChangePoints      []ChangePoint   `json:"change_points,omitempty"`
+	Outliers          []Outlier       `json:"outliers,omitempty"`
+	LatestJump        *LatestJump     `json:"latest_jump,omitempty"`
 }
 
 // TrendAnalysis represents a comprehensive trend analysis for a mother's health metrics
@@ -71,34 +81,48 @@ type TimeSeriesData struct {
 	Values []float64
 }
 
+// Metric names used in trend results
+const (
+	MetricSystolicBP     = "systolic_blood_pressure"
+	MetricDiastolicBP    = "diastolic_blood_pressure"
+	MetricWeight         = "weight"
+	MetricFetalHeartRate = "fetal_heart_rate"
+)
+
+// MinDataPoints is the fewest readings of a metric needed to describe its trend
+const MinDataPoints = 2
+
 // Service provides trend detection functionality
 type Service struct {
 	log logger.Logger
-	
+
 	// Configuration values for trend detection
-	minDataPoints           int
-	significantChangePercent float64
-	significantChangeBP     float64
-	significantChangeWeight float64
-	significantChangeFHR    float64
+	minDataPoints int
+	limits        map[string]metricLimits
 }
 
 // NewService creates a new trend detection service
 func NewService(log logger.Logger) *Service {
 	return &Service{
-		log:                     log,
-		minDataPoints:           3,
-		significantChangePercent: 10.0,
-		significantChangeBP:     10.0,  // 10 mmHg change in blood pressure is significant
-		significantChangeWeight: 2.0,   // 2 kg change in weight in short period is significant
-		significantChangeFHR:    10.0,  // 10 bpm change in fetal heart rate is significant
+		log:           log,
+		minDataPoints: MinDataPoints,
+		limits: map[string]metricLimits{
+			// 10 mmHg change in blood pressure is significant; a rise of 30/15 mmHg
+			// over the mother's own baseline is a warning sign even below 140/90
+			MetricSystolicBP:  {plausibleMin: 50, plausibleMax: 260, minChange: 10, baselineAlert: 30},
+			MetricDiastolicBP: {plausibleMin: 30, plausibleMax: 160, minChange: 10, baselineAlert: 15},
+			// 2 kg change in weight in short period is significant
+			MetricWeight: {plausibleMin: 30, plausibleMax: 200, minChange: 2},
+			// 10 bpm change in fetal heart rate is significant
+			MetricFetalHeartRate: {plausibleMin: 50, plausibleMax: 240, minChange: 10, baselineAlert: 20},
+		},
 	}
 }
 
 // AnalyzeTrends performs trend analysis on a series of health metrics
 func (s *Service) AnalyzeTrends(motherID uuid.UUID, metrics []*model.HealthMetric) (*TrendAnalysis, error) {
 	if len(metrics) < s.minDataPoints {
-		return nil, errorx.New(errorx.BadRequest, "insufficient data points for trend analysis")
+		return nil, errorx.Newf(errorx.BadRequest, "insufficient data points for trend analysis, at least %d are required", s.minDataPoints)
 	}
 
 	// Sort metrics by date (oldest first for time series analysis)
@@ -208,17 +232,12 @@ func (s *Service) extractFetalHeartRate(metrics []*model.HealthMetric) TimeSerie
 
 // analyzeSystolicTrend analyzes trends in systolic blood pressure
 func (s *Service) analyzeSystolicTrend(data TimeSeriesData) TrendResult {
-	result := TrendResult{
-		MetricName: "systolic_blood_pressure",
-		FirstValue: data.Values[0],
-		LastValue:  data.Values[len(data.Values)-1],
+	// Calculate trend type on the readings left after outlier rejection
+	result := s.calculateTrend(MetricSystolicBP, data)
+	if result.TrendType == TrendInsufficient {
+		return result
 	}
-	
-	// Calculate trend type
-	trendType, changeRate, changePerDay := s.calculateTrend(data)
-	result.TrendType = trendType
-	result.ChangeRate = changeRate
-	result.ChangePerDay = changePerDay
+	trendType, changeRate := result.TrendType, result.ChangeRate
 	
 	// Determine alert level and recommendations based on trend
 	if trendType == TrendIncreasing {
@@ -265,22 +284,20 @@ func (s *Service) analyzeSystolicTrend(data TimeSeriesData) TrendResult {
 		}
 	}
 	
+	// A rise from her own baseline can matter before the absolute limits are reached
+	s.applyLevelShift(&result, func(shift float64) bool { return shift > 0 })
+
 	return result
 }
 
 // analyzeDiastolicTrend analyzes trends in diastolic blood pressure
 func (s *Service) analyzeDiastolicTrend(data TimeSeriesData) TrendResult {
-	result := TrendResult{
-		MetricName: "diastolic_blood_pressure",
-		FirstValue: data.Values[0],
-		LastValue:  data.Values[len(data.Values)-1],
+	// Calculate trend type on the readings left after outlier rejection
+	result := s.calculateTrend(MetricDiastolicBP, data)
+	if result.TrendType == TrendInsufficient {
+		return result
 	}
-	
-	// Calculate trend type
-	trendType, changeRate, changePerDay := s.calculateTrend(data)
-	result.TrendType = trendType
-	result.ChangeRate = changeRate
-	result.ChangePerDay = changePerDay
+	trendType, changeRate := result.TrendType, result.ChangeRate
 	
 	// Determine alert level and recommendations based on trend
 	if trendType == TrendIncreasing {
@@ -327,22 +344,19 @@ func (s *Service) analyzeDiastolicTrend(data TimeSeriesData) TrendResult {
 		}
 	}
 	
+	s.applyLevelShift(&result, func(shift float64) bool { return shift > 0 })
+
 	return result
 }
 
 // analyzeWeightTrend analyzes trends in weight
 func (s *Service) analyzeWeightTrend(data TimeSeriesData) TrendResult {
-	result := TrendResult{
-		MetricName: "weight",
-		FirstValue: data.Values[0],
-		LastValue:  data.Values[len(data.Values)-1],
+	// Calculate trend type on the readings left after outlier rejection
+	result := s.calculateTrend(MetricWeight, data)
+	if result.TrendType == TrendInsufficient {
+		return result
 	}
-	
-	// Calculate trend type
-	trendType, changeRate, changePerDay := s.calculateTrend(data)
-	result.TrendType = trendType
-	result.ChangeRate = changeRate
-	result.ChangePerDay = changePerDay
+	trendType, changePerDay := result.TrendType, result.ChangePerDay
 	
 	// Determine alert level and recommendations for weight trends
 	// For pregnant women, weight should increase gradually
@@ -371,22 +385,20 @@ func (s *Service) analyzeWeightTrend(data TimeSeriesData) TrendResult {
 		result.RecommendedAction = "Discuss weight progression with healthcare provider"
 	}
 	
+	// Sudden loss or sudden gain (e.g. oedema) both need review
+	s.applyLevelShift(&result, func(shift float64) bool { return true })
+
 	return result
 }
 
 // analyzeFetalHeartRateTrend analyzes trends in fetal heart rate
 func (s *Service) analyzeFetalHeartRateTrend(data TimeSeriesData) TrendResult {
-	result := TrendResult{
-		MetricName: "fetal_heart_rate",
-		FirstValue: data.Values[0],
-		LastValue:  data.Values[len(data.Values)-1],
+	// Calculate trend type on the readings left after outlier rejection
+	result := s.calculateTrend(MetricFetalHeartRate, data)
+	if result.TrendType == TrendInsufficient {
+		return result
 	}
-	
-	// Calculate trend type
-	trendType, changeRate, changePerDay := s.calculateTrend(data)
-	result.TrendType = trendType
-	result.ChangeRate = changeRate
-	result.ChangePerDay = changePerDay
+	trendType, changeRate := result.TrendType, result.ChangeRate
 	
 	// Normal fetal heart rate range is typically 110-160 bpm
 	if trendType == TrendDecreasing {
@@ -394,11 +406,11 @@ func (s *Service) analyzeFetalHeartRateTrend(data TimeSeriesData) TrendResult {
 			result.AlertLevel = AlertUrgent
 			result.Description = "Fetal heart rate decreasing and below normal range"
 			result.RecommendedAction = "Seek immediate medical attention"
-		} else if result.LastValue < 120 && changeRate > 5 {
+		} else if result.LastValue < 120 && math.Abs(changeRate) > 5 {
 			result.AlertLevel = AlertConcern
 			result.Description = "Fetal heart rate decreasing and approaching lower limit of normal range"
 			result.RecommendedAction = "Consult healthcare provider promptly"
-		} else if changeRate > 10 {
+		} else if math.Abs(changeRate) > 10 {
 			result.AlertLevel = AlertMonitor
 			result.Description = "Significant decrease in fetal heart rate, still within normal range"
 			result.RecommendedAction = "Monitor fetal movement and heart rate closely"
@@ -431,54 +443,130 @@ func (s *Service) analyzeFetalHeartRateTrend(data TimeSeriesData) TrendResult {
 		}
 	}
 	
+	s.applyLevelShift(&result, func(shift float64) bool { return true })
+
 	return result
 }
 
-// calculateTrend determines the type of trend in the time series data
-func (s *Service) calculateTrend(data TimeSeriesData) (TrendType, float64, float64) {
-	if len(data.Values) < s.minDataPoints {
-		return TrendInsufficient, 0, 0
+// calculateTrend rejects outliers and fits a robust trend to the remaining readings,
+// returning a result with the trend type, baseline, change points and confidence set
+func (s *Service) calculateTrend(metricName string, data TimeSeriesData) TrendResult {
+	limits := s.limits[metricName]
+	clean, outliers := rejectOutliers(data, limits)
+
+	result := TrendResult{
+		MetricName: metricName,
+		AlertLevel: AlertNone,
+		DataPoints: len(clean.Values),
+		Confidence: ConfidenceLow,
+		Outliers:   outliers,
+		LatestJump: detectLatestJump(clean, limits),
 	}
-	
-	// Calculate simple linear regression to determine trend
-	n := len(data.Values)
-	firstValue := data.Values[0]
-	lastValue := data.Values[n-1]
-	
-	// Calculate total days elapsed
-	totalDays := data.Dates[n-1].Sub(data.Dates[0]).Hours() / 24
+
+	n := len(clean.Values)
+	if n < s.minDataPoints {
+		result.TrendType = TrendInsufficient
+		result.Description = "Not enough valid readings to determine a trend"
+		if n > 0 {
+			result.FirstValue = clean.Values[0]
+			result.LastValue = clean.Values[n-1]
+		}
+		return result
+	}
+
+	result.FirstValue = clean.Values[0]
+	result.LastValue = clean.Values[n-1]
+
+	// Readings are irregularly spaced, so the slope is fitted against real time
+	totalDays := clean.Dates[n-1].Sub(clean.Dates[0]).Hours() / 24
 	if totalDays < 1 {
 		totalDays = 1 // Avoid division by zero
 	}
-	
-	// Calculate absolute and percentage change
-	absoluteChange := lastValue - firstValue
-	changePerDay := absoluteChange / totalDays
-	
-	var percentageChange float64
-	if firstValue != 0 {
-		percentageChange = (absoluteChange / firstValue) * 100
+
+	slopePerDay := theilSenSlope(clean)
+	fittedChange := slopePerDay * totalDays
+	noise := residualNoise(clean, slopePerDay)
+
+	result.ChangePerDay = slopePerDay
+	if level := median(clean.Values); level != 0 {
+		result.ChangeRate = (fittedChange / level) * 100
 	}
-	
-	// Calculate mean and standard deviation to assess stability
-	mean := calculateMean(data.Values)
-	stdDev := calculateStdDev(data.Values, mean)
-	
-	// Coefficient of variation as a measure of stability
-	var cv float64
-	if mean != 0 {
-		cv = stdDev / mean
+	result.Noise = noise
+
+	result.ChangePoints = detectChangePoints(clean, limits)
+	result.Baseline = calculateBaseline(clean, result.ChangePoints)
+	recent := clean.Values
+	if len(recent) > 3 {
+		recent = recent[len(recent)-3:]
 	}
-	
-	// Determine trend type based on changes and variation
-	if math.Abs(percentageChange) < s.significantChangePercent && cv < 0.1 {
-		return TrendStable, percentageChange, changePerDay
-	} else if cv > 0.2 {
-		return TrendFluctuating, percentageChange, changePerDay
-	} else if percentageChange > 0 {
-		return TrendIncreasing, percentageChange, changePerDay
-	} else {
-		return TrendDecreasing, percentageChange, changePerDay
+	result.BaselineDeviation = median(recent) - result.Baseline.Value
+
+	// A change counts when it is clinically meaningful and stands out from the mother's own noise
+	signal := fittedChange
+	if len(result.ChangePoints) > 0 {
+		if shift := result.ChangePoints[len(result.ChangePoints)-1].Shift; math.Abs(shift) > math.Abs(signal) {
+			signal = shift
+		}
+	}
+	result.Confidence, result.ConfidenceScore = calculateConfidence(n, len(outliers), totalDays, signal, noise)
+
+	significant := math.Abs(fittedChange) >= limits.minChange && math.Abs(fittedChange) >= 2*noise
+	switch {
+	case significant && fittedChange > 0:
+		result.TrendType = TrendIncreasing
+	case significant:
+		result.TrendType = TrendDecreasing
+	case noise > limits.minChange:
+		result.TrendType = TrendFluctuating
+	default:
+		result.TrendType = TrendStable
+	}
+
+	return result
+}
+
+// applyLevelShift raises the alert for a concerning shift from the mother's own level,
+// which a slope over the whole series can miss. A concerning jump in the newest reading
+// is always raised since it may be an acute change; other shifts in low confidence
+// results are left alone.
+func (s *Service) applyLevelShift(result *TrendResult, concerning func(shift float64) bool) {
+	if jump := result.LatestJump; jump != nil && concerning(jump.Deviation) {
+		if alertRank(AlertConcern) > alertRank(result.AlertLevel) {
+			result.AlertLevel = AlertConcern
+			result.RecommendedAction = "Recheck the reading now and consult healthcare provider if it is confirmed"
+		}
+		result.Description += fmt.Sprintf("; latest reading of %.0f is %+.0f from her recent readings of %.0f",
+			jump.Value, jump.Deviation, jump.Expected)
+	}
+
+	if result.Confidence == ConfidenceLow {
+		return
+	}
+	limits := s.limits[result.MetricName]
+
+	if len(result.ChangePoints) > 0 {
+		latest := result.ChangePoints[len(result.ChangePoints)-1]
+		if concerning(latest.Shift) {
+			level := AlertMonitor
+			if math.Abs(latest.Shift) >= 2*limits.minChange {
+				level = AlertConcern
+			}
+			if alertRank(level) > alertRank(result.AlertLevel) {
+				result.AlertLevel = level
+				if result.RecommendedAction == "" {
+					result.RecommendedAction = "Review recent readings with the mother and recheck at the next contact"
+				}
+			}
+			result.Description += fmt.Sprintf("; level shifted from %.1f to %.1f on %s",
+				latest.Before, latest.After, latest.Date.Format("2006-01-02"))
+		}
+	}
+
+	if limits.baselineAlert > 0 && math.Abs(result.BaselineDeviation) >= limits.baselineAlert &&
+		concerning(result.BaselineDeviation) && alertRank(AlertConcern) > alertRank(result.AlertLevel) {
+		result.AlertLevel = AlertConcern
+		result.Description += fmt.Sprintf("; %+.0f from her baseline of %.0f", result.BaselineDeviation, result.Baseline.Value)
+		result.RecommendedAction = "Consult healthcare provider about the change from her usual readings"
 	}
 }
 
@@ -498,22 +586,16 @@ func (s *Service) updateHighestAlert(analysis *TrendAnalysis, alertLevel AlertLe
 	}
 }
 
-// calculateMean calculates the mean of a series of values
-func calculateMean(values []float64) float64 {
-	sum := 0.0
-	for _, v := range values {
-		sum += v
-	}
-	return sum / float64(len(values))
-}
-
-// calculateStdDev calculates the standard deviation of a series of values
-func calculateStdDev(values []float64, mean float64) float64 {
-	sumSquaredDiff := 0.0
-	for _, v := range values {
-		diff := v - mean
-		sumSquaredDiff += diff * diff
+// alertRank orders alert levels from none to urgent
+func alertRank(level AlertLevel) int {
+	switch level {
+	case AlertUrgent:
+		return 3
+	case AlertConcern:
+		return 2
+	case AlertMonitor:
+		return 1
+	default:
+		return 0
 	}
-	variance := sumSquaredDiff / float64(len(values))
-	return math.Sqrt(variance)
 }
@@ -1,10 +1,12 @@
 package action
 
 import (
+	"fmt"
 	"net/http"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/mamacare/services/internal/app/geo/routing"
 	"github.com/mamacare/services/internal/app/visit/assignment"
 	"github.com/mamacare/services/internal/port/hasura"
 	"github.com/mamacare/services/internal/port/response"
@@ -38,10 +40,44 @@ type AssignVisitsByCatchmentAreaRequest struct {
 	EndDate    string `json:"end_date" validate:"required,rfc3339"`
 }
 
+// AvailabilityInput is a weekly period in which a mother or village can be visited
+type AvailabilityInput struct {
+	Weekday string `json:"weekday" validate:"required,oneof=sunday monday tuesday wednesday thursday friday saturday"`
+	From    string `json:"from" validate:"required,datetime=15:04"`
+	To      string `json:"to" validate:"required,datetime=15:04"`
+}
+
+// MotherAvailabilityInput is when a mother can receive home visits
+type MotherAvailabilityInput struct {
+	MotherID  string              `json:"mother_id" validate:"required,uuid"`
+	Available []AvailabilityInput `json:"available" validate:"required,dive"`
+}
+
+// VillageAvailabilityInput is when the mothers of a village can be visited
+type VillageAvailabilityInput struct {
+	Name      string              `json:"name" validate:"required"`
+	MotherIDs []string            `json:"mother_ids" validate:"required,dive,uuid"`
+	Available []AvailabilityInput `json:"available" validate:"required,dive"`
+}
+
 // OptimizeCHWRoutesRequest is the request for optimizing CHW routes
 type OptimizeCHWRoutesRequest struct {
-	CHWID string `json:"chw_id" validate:"required,uuid"`
-	Date  string `json:"date" validate:"required,rfc3339"`
+	CHWID              string                     `json:"chw_id" validate:"required,uuid"`
+	Date               string                     `json:"date" validate:"required,rfc3339"`
+	TransportMode      string                     `json:"transport_mode,omitempty" validate:"omitempty,oneof=walking bicycling driving"`
+	MotherAvailability []MotherAvailabilityInput  `json:"mother_availability,omitempty" validate:"omitempty,dive"`
+	Villages           []VillageAvailabilityInput `json:"villages,omitempty" validate:"omitempty,dive"`
+}
+
+// PlanCHWWeekRequest is the request for planning a CHW's weekly routes
+type PlanCHWWeekRequest struct {
+	CHWID              string                     `json:"chw_id" validate:"required,uuid"`
+	WeekStart          string                     `json:"week_start" validate:"required,rfc3339"`
+	TransportMode      string                     `json:"transport_mode,omitempty" validate:"omitempty,oneof=walking bicycling driving"`
+	MaxVisitsPerDay    int                        `json:"max_visits_per_day,omitempty" validate:"omitempty,min=1,max=30"`
+	Format             string                     `json:"format,omitempty" validate:"omitempty,oneof=json pdf gpx"`
+	MotherAvailability []MotherAvailabilityInput  `json:"mother_availability,omitempty" validate:"omitempty,dive"`
+	Villages           []VillageAvailabilityInput `json:"villages,omitempty" validate:"omitempty,dive"`
 }
 
 // BalanceWorkloadRequest is the request for balancing CHW workload
@@ -365,8 +401,17 @@ func (h *AssignmentHandler) OptimizeCHWRoutes(w http.ResponseWriter, r *http.Req
 		return
 	}
 
+	opts := assignment.DefaultRoutePlanOptions()
+	if req.TransportMode != "" {
+		opts.TransportMode = routing.TransportMode(req.TransportMode)
+	}
+	if err := applyAvailability(opts, req.MotherAvailability, req.Villages); err != nil {
+		response.WriteErrorResponse(w, reqID, err)
+		return
+	}
+
 	// Optimize routes
-	optimizedRoute, err := h.assignmentService.OptimizeCHWRoutes(ctx, chwID, date)
+	optimizedRoute, err := h.assignmentService.OptimizeCHWRoutes(ctx, chwID, date, opts)
 	if err != nil {
 		h.log.Error("Failed to optimize CHW routes", logger.Fields{
 			"request_id": reqID,
@@ -386,6 +431,106 @@ func (h *AssignmentHandler) OptimizeCHWRoutes(w http.ResponseWriter, r *http.Req
 	response.WriteJSONResponse(w, reqID, optimizedRoute)
 }
 
+// PlanCHWWeek plans a CHW's routes for a week and returns them as JSON, or as a printable
+// route sheet or GPX file encoded in a file response
+func (h *AssignmentHandler) PlanCHWWeek(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	reqID := response.GetRequestID(ctx)
+
+	var req PlanCHWWeekRequest
+	if err := h.ParseRequest(r, &req); err != nil {
+		h.log.Error("Failed to parse request", logger.Fields{
+			"request_id": reqID,
+			"error":      err.Error(),
+		})
+		response.WriteErrorResponse(w, reqID, err)
+		return
+	}
+
+	// Validate request
+	if err := h.validator.Validate(req); err != nil {
+		h.log.Error("Invalid request", logger.Fields{
+			"request_id": reqID,
+			"error":      err.Error(),
+		})
+		response.WriteErrorResponse(w, reqID, err)
+		return
+	}
+
+	// Parse UUID
+	chwID, err := uuid.Parse(req.CHWID)
+	if err != nil {
+		response.WriteErrorResponse(w, reqID, errorx.New(errorx.BadRequest, "Invalid CHW ID"))
+		return
+	}
+
+	// Parse week start
+	weekStart, err := time.Parse(time.RFC3339, req.WeekStart)
+	if err != nil {
+		response.WriteErrorResponse(w, reqID, errorx.New(errorx.BadRequest, "Invalid week start format"))
+		return
+	}
+
+	opts := assignment.DefaultRoutePlanOptions()
+	if req.TransportMode != "" {
+		opts.TransportMode = routing.TransportMode(req.TransportMode)
+	}
+	if req.MaxVisitsPerDay > 0 {
+		opts.MaxVisitsPerDay = req.MaxVisitsPerDay
+	}
+	if err := applyAvailability(opts, req.MotherAvailability, req.Villages); err != nil {
+		response.WriteErrorResponse(w, reqID, err)
+		return
+	}
+
+	plan, err := h.assignmentService.PlanCHWWeek(ctx, chwID, weekStart, opts)
+	if err != nil {
+		h.log.Error("Failed to plan CHW week", logger.Fields{
+			"request_id": reqID,
+			"error":      err.Error(),
+			"chw_id":     req.CHWID,
+		})
+		response.WriteErrorResponse(w, reqID, err)
+		return
+	}
+
+	h.log.Info("Planned CHW week", logger.Fields{
+		"request_id": reqID,
+		"chw_id":     req.CHWID,
+		"days":       len(plan.Routes),
+		"format":     req.Format,
+	})
+
+	var (
+		body        []byte
+		contentType string
+		extension   string
+	)
+	switch req.Format {
+	case "pdf":
+		body, err = assignment.RenderRouteSheetPDF(plan)
+		contentType, extension = "application/pdf", "pdf"
+	case "gpx":
+		body, err = assignment.RenderRouteSheetGPX(plan)
+		contentType, extension = "application/gpx+xml", "gpx"
+	default:
+		response.WriteJSONResponse(w, reqID, plan)
+		return
+	}
+	if err != nil {
+		h.log.Error("Failed to render route sheet", logger.Fields{
+			"request_id": reqID,
+			"error":      err.Error(),
+			"format":     req.Format,
+		})
+		response.WriteErrorResponse(w, reqID, errorx.Wrap(err, "failed to render route sheet"))
+		return
+	}
+
+	filename := fmt.Sprintf("route-sheet-%s-%s.%s", chwID.String()[:8], plan.StartDate.Format("2006-01-02"), extension)
+	response.WriteJSONResponse(w, reqID, newFileResponse(filename, contentType, body))
+}
+
 // BalanceWorkload balances workload across CHWs
 func (h *AssignmentHandler) BalanceWorkload(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
@@ -515,3 +660,78 @@ func (h *AssignmentHandler) UpdateVisitOrder(w http.ResponseWriter, r *http.Requ
 		Success: true,
 	})
 }
+
+// weekdays maps weekday names in requests to weekdays
+var weekdays = map[string]time.Weekday{
+	"sunday":    time.Sunday,
+	"monday":    time.Monday,
+	"tuesday":   time.Tuesday,
+	"wednesday": time.Wednesday,
+	"thursday":  time.Thursday,
+	"friday":    time.Friday,
+	"saturday":  time.Saturday,
+}
+
+// applyAvailability adds mother and village availability from a request to route plan options
+func applyAvailability(opts *assignment.RoutePlanOptions, mothers []MotherAvailabilityInput, villages []VillageAvailabilityInput) error {
+	if len(mothers) > 0 {
+		opts.MotherAvailability = make(map[uuid.UUID][]assignment.Availability, len(mothers))
+	}
+	for _, m := range mothers {
+		motherID, err := uuid.Parse(m.MotherID)
+		if err != nil {
+			return errorx.New(errorx.BadRequest, "Invalid mother ID")
+		}
+		available, err := parseAvailability(m.Available)
+		if err != nil {
+			return err
+		}
+		opts.MotherAvailability[motherID] = append(opts.MotherAvailability[motherID], available...)
+	}
+
+	for _, v := range villages {
+		village := assignment.VillageAvailability{
+			Name:      v.Name,
+			MotherIDs: make([]uuid.UUID, 0, len(v.MotherIDs)),
+		}
+		for _, id := range v.MotherIDs {
+			motherID, err := uuid.Parse(id)
+			if err != nil {
+				return errorx.New(errorx.BadRequest, "Invalid mother ID")
+			}
+			village.MotherIDs = append(village.MotherIDs, motherID)
+		}
+		available, err := parseAvailability(v.Available)
+		if err != nil {
+			return err
+		}
+		village.Available = available
+		opts.Villages = append(opts.Villages, village)
+	}
+
+	return nil
+}
+
+// parseAvailability converts weekday and HH:MM periods to availability
+func parseAvailability(inputs []AvailabilityInput) ([]assignment.Availability, error) {
+	available := make([]assignment.Availability, 0, len(inputs))
+	for _, input := range inputs {
+		from, err := time.Parse("15:04", input.From)
+		if err != nil {
+			return nil, errorx.New(errorx.BadRequest, "Invalid availability start time")
+		}
+		to, err := time.Parse("15:04", input.To)
+		if err != nil {
+			return nil, errorx.New(errorx.BadRequest, "Invalid availability end time")
+		}
+		if !to.After(from) {
+			return nil, errorx.New(errorx.BadRequest, "Availability must end after it starts")
+		}
+		available = append(available, assignment.Availability{
+			Weekday: weekdays[input.Weekday],
+			From:    time.Duration(from.Hour())*time.Hour + time.Duration(from.Minute())*time.Minute,
+			To:      time.Duration(to.Hour())*time.Hour + time.Duration(to.Minute())*time.Minute,
+		})
+	}
+	return available, nil
+}
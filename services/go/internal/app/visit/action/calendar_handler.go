@@ -0,0 +1,277 @@
+package action
+
+import (
+	"fmt"
+	"net/http"
+	"path"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/mamacare/services/internal/app/visit/calendar"
+	"github.com/mamacare/services/internal/domain/model"
+	"github.com/mamacare/services/internal/port/hasura"
+	"github.com/mamacare/services/internal/port/response"
+	"github.com/mamacare/services/internal/port/validation"
+	"github.com/mamacare/services/pkg/errorx"
+	"github.com/mamacare/services/pkg/logger"
+)
+
+// CreateCalendarFeedRequest is the request for creating a calendar feed
+type CreateCalendarFeedRequest struct {
+	Scope   string `json:"scope" validate:"required,oneof=user facility"`
+	OwnerID string `json:"owner_id" validate:"required,uuid"`
+}
+
+// ListCalendarFeedsRequest is the request for listing calendar feeds
+type ListCalendarFeedsRequest struct {
+	Scope   string `json:"scope" validate:"required,oneof=user facility"`
+	OwnerID string `json:"owner_id" validate:"required,uuid"`
+}
+
+// RevokeCalendarFeedRequest is the request for revoking a calendar feed
+type RevokeCalendarFeedRequest struct {
+	FeedID string `json:"feed_id" validate:"required,uuid"`
+}
+
+// CalendarHandler handles calendar feed requests
+type CalendarHandler struct {
+	hasura.BaseActionHandler
+	calendarService *calendar.Service
+	validator       *validation.Validator
+	log             logger.Logger
+}
+
+// NewCalendarHandler creates a new calendar handler
+func NewCalendarHandler(
+	log logger.Logger,
+	calendarService *calendar.Service,
+	validator *validation.Validator,
+) *CalendarHandler {
+	return &CalendarHandler{
+		BaseActionHandler: hasura.BaseActionHandler{},
+		calendarService:   calendarService,
+		validator:         validator,
+		log:               log,
+	}
+}
+
+// CreateCalendarFeed creates a calendar feed and returns its signed URL.
+// Only the feed's owner or an admin can create it.
+func (h *CalendarHandler) CreateCalendarFeed(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	reqID := response.GetRequestID(ctx)
+
+	var req CreateCalendarFeedRequest
+	actionReq, err := h.ParseRequest(r, &req)
+	if err != nil {
+		h.log.Error("Failed to parse request", logger.Fields{
+			"request_id": reqID,
+			"error":      err.Error(),
+		})
+		response.WriteErrorResponse(w, reqID, err)
+		return
+	}
+
+	// Validate request
+	if err := h.validator.Validate(req); err != nil {
+		h.log.Error("Invalid request", logger.Fields{
+			"request_id": reqID,
+			"error":      err.Error(),
+		})
+		response.WriteErrorResponse(w, reqID, err)
+		return
+	}
+
+	// Parse UUIDs
+	ownerID, err := uuid.Parse(req.OwnerID)
+	if err != nil {
+		response.WriteErrorResponse(w, reqID, errorx.New(errorx.BadRequest, "Invalid owner ID"))
+		return
+	}
+
+	// The feed is created by the caller, never by an ID in the input
+	createdByID, err := actionReq.UserID()
+	if err != nil {
+		response.WriteErrorResponse(w, reqID, err)
+		return
+	}
+
+	// Create feed
+	link, err := h.calendarService.CreateFeed(ctx, model.CalendarFeedScope(req.Scope), ownerID, createdByID)
+	if err != nil {
+		h.log.Error("Failed to create calendar feed", logger.Fields{
+			"request_id": reqID,
+			"error":      err.Error(),
+			"scope":      req.Scope,
+			"owner_id":   req.OwnerID,
+		})
+		response.WriteErrorResponse(w, reqID, err)
+		return
+	}
+
+	h.log.Info("Calendar feed created successfully", logger.Fields{
+		"request_id": reqID,
+		"feed_id":    link.Feed.ID.String(),
+	})
+
+	response.WriteJSONResponse(w, reqID, link)
+}
+
+// ListCalendarFeeds lists the calendar feeds for a user or facility.
+// Only the feeds' owner or an admin can list them.
+func (h *CalendarHandler) ListCalendarFeeds(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	reqID := response.GetRequestID(ctx)
+
+	var req ListCalendarFeedsRequest
+	actionReq, err := h.ParseRequest(r, &req)
+	if err != nil {
+		h.log.Error("Failed to parse request", logger.Fields{
+			"request_id": reqID,
+			"error":      err.Error(),
+		})
+		response.WriteErrorResponse(w, reqID, err)
+		return
+	}
+
+	// Validate request
+	if err := h.validator.Validate(req); err != nil {
+		h.log.Error("Invalid request", logger.Fields{
+			"request_id": reqID,
+			"error":      err.Error(),
+		})
+		response.WriteErrorResponse(w, reqID, err)
+		return
+	}
+
+	// Parse UUID
+	ownerID, err := uuid.Parse(req.OwnerID)
+	if err != nil {
+		response.WriteErrorResponse(w, reqID, errorx.New(errorx.BadRequest, "Invalid owner ID"))
+		return
+	}
+
+	requesterID, err := actionReq.UserID()
+	if err != nil {
+		response.WriteErrorResponse(w, reqID, err)
+		return
+	}
+
+	links, err := h.calendarService.ListFeeds(ctx, model.CalendarFeedScope(req.Scope), ownerID, requesterID)
+	if err != nil {
+		h.log.Error("Failed to list calendar feeds", logger.Fields{
+			"request_id": reqID,
+			"error":      err.Error(),
+			"owner_id":   req.OwnerID,
+		})
+		response.WriteErrorResponse(w, reqID, err)
+		return
+	}
+
+	response.WriteJSONResponse(w, reqID, links)
+}
+
+// RevokeCalendarFeed revokes a calendar feed.
+// Only the feed's owner or an admin can revoke it.
+func (h *CalendarHandler) RevokeCalendarFeed(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	reqID := response.GetRequestID(ctx)
+
+	var req RevokeCalendarFeedRequest
+	actionReq, err := h.ParseRequest(r, &req)
+	if err != nil {
+		h.log.Error("Failed to parse request", logger.Fields{
+			"request_id": reqID,
+			"error":      err.Error(),
+		})
+		response.WriteErrorResponse(w, reqID, err)
+		return
+	}
+
+	// Validate request
+	if err := h.validator.Validate(req); err != nil {
+		h.log.Error("Invalid request", logger.Fields{
+			"request_id": reqID,
+			"error":      err.Error(),
+		})
+		response.WriteErrorResponse(w, reqID, err)
+		return
+	}
+
+	// Parse UUID
+	feedID, err := uuid.Parse(req.FeedID)
+	if err != nil {
+		response.WriteErrorResponse(w, reqID, errorx.New(errorx.BadRequest, "Invalid feed ID"))
+		return
+	}
+
+	requesterID, err := actionReq.UserID()
+	if err != nil {
+		response.WriteErrorResponse(w, reqID, err)
+		return
+	}
+
+	feed, err := h.calendarService.RevokeFeed(ctx, feedID, requesterID)
+	if err != nil {
+		h.log.Error("Failed to revoke calendar feed", logger.Fields{
+			"request_id": reqID,
+			"error":      err.Error(),
+			"feed_id":    req.FeedID,
+		})
+		response.WriteErrorResponse(w, reqID, err)
+		return
+	}
+
+	h.log.Info("Calendar feed revoked successfully", logger.Fields{
+		"request_id": reqID,
+		"feed_id":    req.FeedID,
+	})
+
+	response.WriteJSONResponse(w, reqID, feed)
+}
+
+// ServeCalendarFeed serves a feed to calendar apps at /calendar/{feed_id}.ics?token=...
+// This is a plain GET endpoint since calendar clients can't send auth headers;
+// the signed token in the URL authorizes the request.
+func (h *CalendarHandler) ServeCalendarFeed(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	reqID := response.GetRequestID(ctx)
+
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		response.WriteErrorResponse(w, reqID, errorx.New(errorx.BadRequest, "Calendar feeds only support GET"))
+		return
+	}
+
+	feedID, err := uuid.Parse(strings.TrimSuffix(path.Base(r.URL.Path), ".ics"))
+	if err != nil {
+		response.WriteErrorResponse(w, reqID, errorx.New(errorx.NotFound, "calendar feed not found"))
+		return
+	}
+
+	feed, err := h.calendarService.RenderFeed(ctx, feedID, r.URL.Query().Get("token"))
+	if err != nil {
+		h.log.Error("Failed to render calendar feed", logger.Fields{
+			"request_id": reqID,
+			"error":      err.Error(),
+			"feed_id":    feedID.String(),
+		})
+		response.WriteErrorResponse(w, reqID, err)
+		return
+	}
+
+	etag := fmt.Sprintf(`"%d-%d"`, feed.LastModified.Unix(), feed.EventCount)
+	if r.Header.Get("If-None-Match") == etag {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/calendar; charset=utf-8")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("inline; filename=\"%s.ics\"", feedID.String()))
+	w.Header().Set("Cache-Control", "private, max-age=900")
+	w.Header().Set("ETag", etag)
+	w.Header().Set("Last-Modified", feed.LastModified.UTC().Format(http.TimeFormat))
+	w.WriteHeader(http.StatusOK)
+	if r.Method == http.MethodGet {
+		w.Write(feed.Content)
+	}
+}
@@ -0,0 +1,20 @@
+package action
+
+import "encoding/base64"
+
+// FileResponse carries a generated document through a Hasura action, which can only
+// return JSON. Clients decode the content and save it under the filename.
+type FileResponse struct {
+	Filename    string `json:"filename"`
+	ContentType string `json:"content_type"`
+	Content     string `json:"content"` // base64 encoded
+}
+
+// newFileResponse wraps a document's bytes for returning from an action
+func newFileResponse(filename, contentType string, data []byte) *FileResponse {
+	return &FileResponse{
+		Filename:    filename,
+		ContentType: contentType,
+		Content:     base64.StdEncoding.EncodeToString(data),
+	}
+}
@@ -0,0 +1,400 @@
+package action
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mamacare/services/internal/app/visit/groupanc"
+	"github.com/mamacare/services/internal/port/hasura"
+	"github.com/mamacare/services/internal/port/response"
+	"github.com/mamacare/services/internal/port/validation"
+	"github.com/mamacare/services/pkg/errorx"
+	"github.com/mamacare/services/pkg/logger"
+)
+
+// CreateGroupSessionRequest is the request for scheduling a group ANC session
+type CreateGroupSessionRequest struct {
+	FacilityID      string   `json:"facility_id" validate:"required,uuid"`
+	Title           string   `json:"title,omitempty" validate:"omitempty,max=255"`
+	TopicContentID  string   `json:"topic_content_id,omitempty" validate:"omitempty,uuid"`
+	ScheduledTime   string   `json:"scheduled_time" validate:"required,rfc3339"`
+	DurationMinutes int      `json:"duration_minutes,omitempty" validate:"omitempty,min=15,max=480"`
+	Capacity        int      `json:"capacity" validate:"required,min=2,max=20"`
+	Notes           string   `json:"notes,omitempty"`
+	MotherIDs       []string `json:"mother_ids,omitempty" validate:"omitempty,dive,uuid"`
+}
+
+// EnrollGroupSessionRequest is the request for adding mothers to a group session
+type EnrollGroupSessionRequest struct {
+	SessionID string   `json:"session_id" validate:"required,uuid"`
+	MotherIDs []string `json:"mother_ids" validate:"required,min=1,dive,uuid"`
+}
+
+// GroupAttendanceEntry is one mother's attendance in a group session register
+type GroupAttendanceEntry struct {
+	MotherID string `json:"mother_id" validate:"required,uuid"`
+	Attended bool   `json:"attended"`
+}
+
+// RecordGroupAttendanceRequest is the request for recording group session attendance
+type RecordGroupAttendanceRequest struct {
+	SessionID  string                 `json:"session_id" validate:"required,uuid"`
+	Attendance []GroupAttendanceEntry `json:"attendance" validate:"required,min=1,dive"`
+}
+
+// CancelGroupSessionRequest is the request for cancelling a group session
+type CancelGroupSessionRequest struct {
+	SessionID string `json:"session_id" validate:"required,uuid"`
+	Reason    string `json:"reason,omitempty"`
+}
+
+// SuggestGroupCohortsRequest is the request for suggesting group ANC cohorts
+type SuggestGroupCohortsRequest struct {
+	FacilityID  string `json:"facility_id" validate:"required,uuid"`
+	SessionTime string `json:"session_time" validate:"required,rfc3339"`
+	Capacity    int    `json:"capacity" validate:"required,min=2,max=20"`
+	BandWeeks   int    `json:"band_weeks,omitempty" validate:"omitempty,min=1,max=12"`
+}
+
+// GroupANCHandler handles group antenatal care session requests
+type GroupANCHandler struct {
+	hasura.BaseActionHandler
+	groupService *groupanc.Service
+	validator    *validation.Validator
+	log          logger.Logger
+}
+
+// NewGroupANCHandler creates a new group ANC handler
+func NewGroupANCHandler(
+	log logger.Logger,
+	groupService *groupanc.Service,
+	validator *validation.Validator,
+) *GroupANCHandler {
+	return &GroupANCHandler{
+		BaseActionHandler: hasura.BaseActionHandler{},
+		groupService:      groupService,
+		validator:         validator,
+		log:               log,
+	}
+}
+
+// CreateGroupSession schedules a group ANC session
+func (h *GroupANCHandler) CreateGroupSession(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	reqID := response.GetRequestID(ctx)
+
+	var req CreateGroupSessionRequest
+	actionReq, err := h.ParseRequest(r, &req)
+	if err != nil {
+		h.log.Error("Failed to parse request", logger.Fields{
+			"request_id": reqID,
+			"error":      err.Error(),
+		})
+		response.WriteErrorResponse(w, reqID, err)
+		return
+	}
+
+	// The session is facilitated by the caller, never by an ID in the input
+	facilitatorID, err := actionReq.UserID()
+	if err != nil {
+		response.WriteErrorResponse(w, reqID, err)
+		return
+	}
+
+	// Validate request
+	if err := h.validator.Validate(req); err != nil {
+		h.log.Error("Invalid request", logger.Fields{
+			"request_id": reqID,
+			"error":      err.Error(),
+		})
+		response.WriteErrorResponse(w, reqID, err)
+		return
+	}
+
+	// Parse UUIDs
+	facilityID, err := uuid.Parse(req.FacilityID)
+	if err != nil {
+		response.WriteErrorResponse(w, reqID, errorx.New(errorx.BadRequest, "Invalid facility ID"))
+		return
+	}
+
+	// Parse scheduled time
+	scheduledTime, err := time.Parse(time.RFC3339, req.ScheduledTime)
+	if err != nil {
+		response.WriteErrorResponse(w, reqID, errorx.New(errorx.BadRequest, "Invalid scheduled time format"))
+		return
+	}
+
+	input := &groupanc.SessionInput{
+		FacilityID:      facilityID,
+		FacilitatorID:   facilitatorID,
+		Title:           req.Title,
+		ScheduledTime:   scheduledTime,
+		DurationMinutes: req.DurationMinutes,
+		Capacity:        req.Capacity,
+		Notes:           req.Notes,
+	}
+
+	if req.TopicContentID != "" {
+		topicID, err := uuid.Parse(req.TopicContentID)
+		if err != nil {
+			response.WriteErrorResponse(w, reqID, errorx.New(errorx.BadRequest, "Invalid topic content ID"))
+			return
+		}
+		input.TopicContentID = &topicID
+	}
+
+	input.MotherIDs, err = parseUUIDs(req.MotherIDs)
+	if err != nil {
+		response.WriteErrorResponse(w, reqID, errorx.New(errorx.BadRequest, "Invalid mother ID"))
+		return
+	}
+
+	result, err := h.groupService.CreateSession(ctx, input)
+	if err != nil {
+		h.log.Error("Failed to create group session", logger.Fields{
+			"request_id":  reqID,
+			"error":       err.Error(),
+			"facility_id": req.FacilityID,
+		})
+		response.WriteErrorResponse(w, reqID, err)
+		return
+	}
+
+	h.log.Info("Group session created successfully", logger.Fields{
+		"request_id": reqID,
+		"session_id": result.Session.ID.String(),
+	})
+
+	response.WriteJSONResponse(w, reqID, result)
+}
+
+// EnrollGroupSession adds mothers to a group session roster
+func (h *GroupANCHandler) EnrollGroupSession(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	reqID := response.GetRequestID(ctx)
+
+	var req EnrollGroupSessionRequest
+	if err := h.ParseRequest(r, &req); err != nil {
+		h.log.Error("Failed to parse request", logger.Fields{
+			"request_id": reqID,
+			"error":      err.Error(),
+		})
+		response.WriteErrorResponse(w, reqID, err)
+		return
+	}
+
+	// Validate request
+	if err := h.validator.Validate(req); err != nil {
+		h.log.Error("Invalid request", logger.Fields{
+			"request_id": reqID,
+			"error":      err.Error(),
+		})
+		response.WriteErrorResponse(w, reqID, err)
+		return
+	}
+
+	// Parse UUIDs
+	sessionID, err := uuid.Parse(req.SessionID)
+	if err != nil {
+		response.WriteErrorResponse(w, reqID, errorx.New(errorx.BadRequest, "Invalid session ID"))
+		return
+	}
+
+	motherIDs, err := parseUUIDs(req.MotherIDs)
+	if err != nil {
+		response.WriteErrorResponse(w, reqID, errorx.New(errorx.BadRequest, "Invalid mother ID"))
+		return
+	}
+
+	result, err := h.groupService.EnrollMothers(ctx, sessionID, motherIDs)
+	if err != nil {
+		h.log.Error("Failed to enroll mothers in group session", logger.Fields{
+			"request_id": reqID,
+			"error":      err.Error(),
+			"session_id": req.SessionID,
+		})
+		response.WriteErrorResponse(w, reqID, err)
+		return
+	}
+
+	response.WriteJSONResponse(w, reqID, result)
+}
+
+// RecordGroupAttendance records attendance for a group session
+func (h *GroupANCHandler) RecordGroupAttendance(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	reqID := response.GetRequestID(ctx)
+
+	var req RecordGroupAttendanceRequest
+	if err := h.ParseRequest(r, &req); err != nil {
+		h.log.Error("Failed to parse request", logger.Fields{
+			"request_id": reqID,
+			"error":      err.Error(),
+		})
+		response.WriteErrorResponse(w, reqID, err)
+		return
+	}
+
+	// Validate request
+	if err := h.validator.Validate(req); err != nil {
+		h.log.Error("Invalid request", logger.Fields{
+			"request_id": reqID,
+			"error":      err.Error(),
+		})
+		response.WriteErrorResponse(w, reqID, err)
+		return
+	}
+
+	// Parse UUIDs
+	sessionID, err := uuid.Parse(req.SessionID)
+	if err != nil {
+		response.WriteErrorResponse(w, reqID, errorx.New(errorx.BadRequest, "Invalid session ID"))
+		return
+	}
+
+	attendance := make([]groupanc.AttendanceInput, 0, len(req.Attendance))
+	for _, entry := range req.Attendance {
+		motherID, err := uuid.Parse(entry.MotherID)
+		if err != nil {
+			response.WriteErrorResponse(w, reqID, errorx.New(errorx.BadRequest, "Invalid mother ID"))
+			return
+		}
+		attendance = append(attendance, groupanc.AttendanceInput{
+			MotherID: motherID,
+			Attended: entry.Attended,
+		})
+	}
+
+	result, err := h.groupService.RecordAttendance(ctx, sessionID, attendance)
+	if err != nil {
+		h.log.Error("Failed to record group session attendance", logger.Fields{
+			"request_id": reqID,
+			"error":      err.Error(),
+			"session_id": req.SessionID,
+		})
+		response.WriteErrorResponse(w, reqID, err)
+		return
+	}
+
+	h.log.Info("Group session attendance recorded successfully", logger.Fields{
+		"request_id":   reqID,
+		"session_id":   req.SessionID,
+		"anc_contacts": len(result.ANCContacts),
+	})
+
+	response.WriteJSONResponse(w, reqID, result)
+}
+
+// CancelGroupSession cancels a group session
+func (h *GroupANCHandler) CancelGroupSession(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	reqID := response.GetRequestID(ctx)
+
+	var req CancelGroupSessionRequest
+	if err := h.ParseRequest(r, &req); err != nil {
+		h.log.Error("Failed to parse request", logger.Fields{
+			"request_id": reqID,
+			"error":      err.Error(),
+		})
+		response.WriteErrorResponse(w, reqID, err)
+		return
+	}
+
+	// Validate request
+	if err := h.validator.Validate(req); err != nil {
+		h.log.Error("Invalid request", logger.Fields{
+			"request_id": reqID,
+			"error":      err.Error(),
+		})
+		response.WriteErrorResponse(w, reqID, err)
+		return
+	}
+
+	// Parse UUID
+	sessionID, err := uuid.Parse(req.SessionID)
+	if err != nil {
+		response.WriteErrorResponse(w, reqID, errorx.New(errorx.BadRequest, "Invalid session ID"))
+		return
+	}
+
+	session, err := h.groupService.CancelSession(ctx, sessionID, req.Reason)
+	if err != nil {
+		h.log.Error("Failed to cancel group session", logger.Fields{
+			"request_id": reqID,
+			"error":      err.Error(),
+			"session_id": req.SessionID,
+		})
+		response.WriteErrorResponse(w, reqID, err)
+		return
+	}
+
+	response.WriteJSONResponse(w, reqID, session)
+}
+
+// SuggestGroupCohorts suggests cohorts of mothers for a group session
+func (h *GroupANCHandler) SuggestGroupCohorts(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	reqID := response.GetRequestID(ctx)
+
+	var req SuggestGroupCohortsRequest
+	if err := h.ParseRequest(r, &req); err != nil {
+		h.log.Error("Failed to parse request", logger.Fields{
+			"request_id": reqID,
+			"error":      err.Error(),
+		})
+		response.WriteErrorResponse(w, reqID, err)
+		return
+	}
+
+	// Validate request
+	if err := h.validator.Validate(req); err != nil {
+		h.log.Error("Invalid request", logger.Fields{
+			"request_id": reqID,
+			"error":      err.Error(),
+		})
+		response.WriteErrorResponse(w, reqID, err)
+		return
+	}
+
+	// Parse UUID
+	facilityID, err := uuid.Parse(req.FacilityID)
+	if err != nil {
+		response.WriteErrorResponse(w, reqID, errorx.New(errorx.BadRequest, "Invalid facility ID"))
+		return
+	}
+
+	// Parse session time
+	sessionTime, err := time.Parse(time.RFC3339, req.SessionTime)
+	if err != nil {
+		response.WriteErrorResponse(w, reqID, errorx.New(errorx.BadRequest, "Invalid session time format"))
+		return
+	}
+
+	cohorts, err := h.groupService.SuggestCohorts(ctx, facilityID, sessionTime, req.Capacity, req.BandWeeks)
+	if err != nil {
+		h.log.Error("Failed to suggest group cohorts", logger.Fields{
+			"request_id":  reqID,
+			"error":       err.Error(),
+			"facility_id": req.FacilityID,
+		})
+		response.WriteErrorResponse(w, reqID, err)
+		return
+	}
+
+	response.WriteJSONResponse(w, reqID, cohorts)
+}
+
+// parseUUIDs parses a list of UUID strings
+func parseUUIDs(values []string) ([]uuid.UUID, error) {
+	ids := make([]uuid.UUID, 0, len(values))
+	for _, value := range values {
+		id, err := uuid.Parse(value)
+		if err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
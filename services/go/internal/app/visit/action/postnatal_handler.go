@@ -0,0 +1,257 @@
+package action
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mamacare/services/internal/app/visit/postnatal"
+	"github.com/mamacare/services/internal/domain/model"
+	"github.com/mamacare/services/internal/port/hasura"
+	"github.com/mamacare/services/internal/port/response"
+	"github.com/mamacare/services/internal/port/validation"
+	"github.com/mamacare/services/pkg/errorx"
+	"github.com/mamacare/services/pkg/logger"
+)
+
+// NewbornOutcomeRequest describes a single baby in a delivery outcome
+type NewbornOutcomeRequest struct {
+	Outcome          string `json:"outcome" validate:"required,oneof=live_birth fresh_stillbirth macerated_stillbirth neonatal_death"`
+	Sex              string `json:"sex,omitempty" validate:"omitempty,oneof=male female"`
+	BirthWeightGrams int    `json:"birth_weight_grams,omitempty" validate:"omitempty,min=300,max=7000"`
+	Notes            string `json:"notes,omitempty"`
+}
+
+// RecordDeliveryOutcomeRequest is the request for recording a delivery outcome
+type RecordDeliveryOutcomeRequest struct {
+	MotherID              string                  `json:"mother_id" validate:"required,uuid"`
+	DeliveryDate          string                  `json:"delivery_date" validate:"required,rfc3339"`
+	Place                 string                  `json:"place" validate:"required,oneof=facility home in_transit other"`
+	DeliveryFacilityID    string                  `json:"delivery_facility_id,omitempty" validate:"omitempty,uuid"`
+	Mode                  string                  `json:"mode" validate:"required,oneof=vaginal assisted caesarean"`
+	GestationalAgeWeeks   int                     `json:"gestational_age_weeks,omitempty" validate:"omitempty,min=20,max=45"`
+	MaternalOutcome       string                  `json:"maternal_outcome" validate:"required,oneof=well complications referred died"`
+	MaternalComplications []string                `json:"maternal_complications,omitempty"`
+	Newborns              []NewbornOutcomeRequest `json:"newborns" validate:"required,min=1,dive"`
+	AttendedByID          string                  `json:"attended_by_id,omitempty" validate:"omitempty,uuid"`
+	PNCFacilityID         string                  `json:"pnc_facility_id" validate:"required,uuid"`
+	Notes                 string                  `json:"notes,omitempty"`
+}
+
+// GetPostnatalScheduleRequest is the request for getting a mother's postnatal contacts
+type GetPostnatalScheduleRequest struct {
+	MotherID string `json:"mother_id" validate:"required,uuid"`
+}
+
+// PostnatalScheduleResponse is the response for a mother's postnatal contacts
+type PostnatalScheduleResponse struct {
+	MotherID string                   `json:"mother_id"`
+	Outcomes []*model.DeliveryOutcome `json:"outcomes"`
+	Visits   []*model.Visit           `json:"visits"`
+	Overdue  int                      `json:"overdue"`
+}
+
+// PostnatalHandler handles delivery outcome and postnatal care requests
+type PostnatalHandler struct {
+	hasura.BaseActionHandler
+	postnatalService *postnatal.Service
+	validator        *validation.Validator
+	log              logger.Logger
+}
+
+// NewPostnatalHandler creates a new postnatal handler
+func NewPostnatalHandler(
+	log logger.Logger,
+	postnatalService *postnatal.Service,
+	validator *validation.Validator,
+) *PostnatalHandler {
+	return &PostnatalHandler{
+		BaseActionHandler: hasura.BaseActionHandler{},
+		postnatalService:  postnatalService,
+		validator:         validator,
+		log:               log,
+	}
+}
+
+// RecordDeliveryOutcome records a delivery and schedules postnatal contacts. The caller
+// is recorded as the health worker who recorded the delivery, and as its attendant unless
+// another attendant is given.
+func (h *PostnatalHandler) RecordDeliveryOutcome(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	reqID := response.GetRequestID(ctx)
+
+	var req RecordDeliveryOutcomeRequest
+	actionReq, err := h.ParseRequest(r, &req)
+	if err != nil {
+		h.log.Error("Failed to parse request", logger.Fields{
+			"request_id": reqID,
+			"error":      err.Error(),
+		})
+		response.WriteErrorResponse(w, reqID, err)
+		return
+	}
+
+	// Validate request
+	if err := h.validator.Validate(req); err != nil {
+		h.log.Error("Invalid request", logger.Fields{
+			"request_id": reqID,
+			"error":      err.Error(),
+		})
+		response.WriteErrorResponse(w, reqID, err)
+		return
+	}
+
+	// Parse UUIDs
+	motherID, err := uuid.Parse(req.MotherID)
+	if err != nil {
+		response.WriteErrorResponse(w, reqID, errorx.New(errorx.BadRequest, "Invalid mother ID"))
+		return
+	}
+
+	// The delivery is recorded by the caller, never by an ID in the input
+	recordedByID, err := actionReq.UserID()
+	if err != nil {
+		response.WriteErrorResponse(w, reqID, err)
+		return
+	}
+
+	pncFacilityID, err := uuid.Parse(req.PNCFacilityID)
+	if err != nil {
+		response.WriteErrorResponse(w, reqID, errorx.New(errorx.BadRequest, "Invalid PNC facility ID"))
+		return
+	}
+
+	// Parse delivery date
+	deliveryDate, err := time.Parse(time.RFC3339, req.DeliveryDate)
+	if err != nil {
+		response.WriteErrorResponse(w, reqID, errorx.New(errorx.BadRequest, "Invalid delivery date format"))
+		return
+	}
+
+	input := &postnatal.DeliveryOutcomeInput{
+		MotherID:              motherID,
+		RecordedByID:          recordedByID,
+		DeliveryDate:          deliveryDate,
+		Place:                 model.DeliveryPlace(req.Place),
+		Mode:                  model.DeliveryMode(req.Mode),
+		GestationalAgeWeeks:   req.GestationalAgeWeeks,
+		MaternalOutcome:       model.MaternalOutcome(req.MaternalOutcome),
+		MaternalComplications: req.MaternalComplications,
+		AttendedByID:          &recordedByID,
+		PNCFacilityID:         pncFacilityID,
+		Notes:                 req.Notes,
+	}
+
+	if req.DeliveryFacilityID != "" {
+		facilityID, err := uuid.Parse(req.DeliveryFacilityID)
+		if err != nil {
+			response.WriteErrorResponse(w, reqID, errorx.New(errorx.BadRequest, "Invalid delivery facility ID"))
+			return
+		}
+		input.DeliveryFacilityID = &facilityID
+	}
+
+	if req.AttendedByID != "" {
+		attendedByID, err := uuid.Parse(req.AttendedByID)
+		if err != nil {
+			response.WriteErrorResponse(w, reqID, errorx.New(errorx.BadRequest, "Invalid attendant ID"))
+			return
+		}
+		input.AttendedByID = &attendedByID
+	}
+
+	for _, newborn := range req.Newborns {
+		input.Newborns = append(input.Newborns, model.NewbornOutcome{
+			Outcome:          model.BirthOutcome(newborn.Outcome),
+			Sex:              newborn.Sex,
+			BirthWeightGrams: newborn.BirthWeightGrams,
+			Notes:            newborn.Notes,
+		})
+	}
+
+	// Record delivery outcome
+	result, err := h.postnatalService.RecordDeliveryOutcome(ctx, input)
+	if err != nil {
+		h.log.Error("Failed to record delivery outcome", logger.Fields{
+			"request_id": reqID,
+			"error":      err.Error(),
+			"mother_id":  req.MotherID,
+		})
+		response.WriteErrorResponse(w, reqID, err)
+		return
+	}
+
+	h.log.Info("Delivery outcome recorded successfully", logger.Fields{
+		"request_id": reqID,
+		"mother_id":  req.MotherID,
+		"outcome_id": result.Outcome.ID.String(),
+		"pnc_visits": len(result.PNCVisits),
+	})
+
+	response.WriteJSONResponse(w, reqID, result)
+}
+
+// GetPostnatalSchedule gets the postnatal contacts for a mother and her newborns
+func (h *PostnatalHandler) GetPostnatalSchedule(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	reqID := response.GetRequestID(ctx)
+
+	var req GetPostnatalScheduleRequest
+	if err := h.ParseRequest(r, &req); err != nil {
+		h.log.Error("Failed to parse request", logger.Fields{
+			"request_id": reqID,
+			"error":      err.Error(),
+		})
+		response.WriteErrorResponse(w, reqID, err)
+		return
+	}
+
+	// Validate request
+	if err := h.validator.Validate(req); err != nil {
+		h.log.Error("Invalid request", logger.Fields{
+			"request_id": reqID,
+			"error":      err.Error(),
+		})
+		response.WriteErrorResponse(w, reqID, err)
+		return
+	}
+
+	// Parse UUID
+	motherID, err := uuid.Parse(req.MotherID)
+	if err != nil {
+		response.WriteErrorResponse(w, reqID, errorx.New(errorx.BadRequest, "Invalid mother ID"))
+		return
+	}
+
+	outcomes, err := h.postnatalService.GetDeliveryOutcomes(ctx, motherID)
+	if err != nil {
+		response.WriteErrorResponse(w, reqID, err)
+		return
+	}
+
+	visits, err := h.postnatalService.GetPostnatalSchedule(ctx, motherID)
+	if err != nil {
+		h.log.Error("Failed to get postnatal schedule", logger.Fields{
+			"request_id": reqID,
+			"error":      err.Error(),
+			"mother_id":  req.MotherID,
+		})
+		response.WriteErrorResponse(w, reqID, err)
+		return
+	}
+
+	now := time.Now()
+	overdue := 0
+	for _, visit := range visits {
+		if visit.IsMissed(now) {
+			overdue++
+		}
+	}
+
+	response.WriteJSONResponse(w, reqID, PostnatalScheduleResponse{
+		MotherID: req.MotherID,
+		Outcomes: outcomes,
+		Visits:   visits,
+		Overdue:  overdue,
+	})
+}
@@ -16,6 +16,7 @@ import (
 // GenerateVisitReportRequest is the request for generating a visit report
 type GenerateVisitReportRequest struct {
 	VisitID string `json:"visit_id" validate:"required,uuid"`
+	Format  string `json:"format,omitempty" validate:"omitempty,oneof=json pdf csv"`
 }
 
 // GenerateFacilitySummaryRequest is the request for generating a facility summary
@@ -23,6 +24,7 @@ type GenerateFacilitySummaryRequest struct {
 	FacilityID string `json:"facility_id" validate:"required,uuid"`
 	StartDate  string `json:"start_date" validate:"required,rfc3339"`
 	EndDate    string `json:"end_date" validate:"required,rfc3339"`
+	Format     string `json:"format,omitempty" validate:"omitempty,oneof=json pdf csv"`
 }
 
 // GenerateCHWSummaryRequest is the request for generating a CHW summary
@@ -30,6 +32,7 @@ type GenerateCHWSummaryRequest struct {
 	CHWID     string `json:"chw_id" validate:"required,uuid"`
 	StartDate string `json:"start_date" validate:"required,rfc3339"`
 	EndDate   string `json:"end_date" validate:"required,rfc3339"`
+	Format    string `json:"format,omitempty" validate:"omitempty,oneof=json pdf csv"`
 }
 
 // GenerateDistrictSummaryRequest is the request for generating a district summary
@@ -37,6 +40,13 @@ type GenerateDistrictSummaryRequest struct {
 	District  string `json:"district" validate:"required"`
 	StartDate string `json:"start_date" validate:"required,rfc3339"`
 	EndDate   string `json:"end_date" validate:"required,rfc3339"`
+	Format    string `json:"format,omitempty" validate:"omitempty,oneof=json pdf csv"`
+}
+
+// GenerateANCCardRequest is the request for generating a mother's antenatal card
+type GenerateANCCardRequest struct {
+	MotherID string `json:"mother_id" validate:"required,uuid"`
+	Format   string `json:"format,omitempty" validate:"omitempty,oneof=json pdf csv"`
 }
 
 // ReportHandler handles visit report requests
@@ -110,7 +120,9 @@ func (h *ReportHandler) GenerateVisitReport(w http.ResponseWriter, r *http.Reque
 		"visit_id":   req.VisitID,
 	})
 
-	response.WriteJSONResponse(w, reqID, visitReport)
+	h.writeReport(w, reqID, req.Format, visitReport, func(format report.ExportFormat) (*report.ExportFile, error) {
+		return report.ExportVisitReport(visitReport, format)
+	})
 }
 
 // GenerateFacilitySummary generates a summary report for a facility
@@ -177,7 +189,9 @@ func (h *ReportHandler) GenerateFacilitySummary(w http.ResponseWriter, r *http.R
 		"end_date":    req.EndDate,
 	})
 
-	response.WriteJSONResponse(w, reqID, summary)
+	h.writeReport(w, reqID, req.Format, summary, func(format report.ExportFormat) (*report.ExportFile, error) {
+		return report.ExportFacilitySummary(summary, format)
+	})
 }
 
 // GenerateCHWSummary generates a summary report for a CHW
@@ -244,7 +258,9 @@ func (h *ReportHandler) GenerateCHWSummary(w http.ResponseWriter, r *http.Reques
 		"end_date":   req.EndDate,
 	})
 
-	response.WriteJSONResponse(w, reqID, summary)
+	h.writeReport(w, reqID, req.Format, summary, func(format report.ExportFormat) (*report.ExportFile, error) {
+		return report.ExportCHWSummary(summary, format)
+	})
 }
 
 // GenerateDistrictSummary generates a summary report for a district
@@ -304,5 +320,90 @@ func (h *ReportHandler) GenerateDistrictSummary(w http.ResponseWriter, r *http.R
 		"end_date":   req.EndDate,
 	})
 
-	response.WriteJSONResponse(w, reqID, summary)
+	h.writeReport(w, reqID, req.Format, summary, func(format report.ExportFormat) (*report.ExportFile, error) {
+		return report.ExportDistrictSummary(summary, format)
+	})
+}
+
+// GenerateANCCard generates a mother's printable antenatal card
+func (h *ReportHandler) GenerateANCCard(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	reqID := response.GetRequestID(ctx)
+
+	var req GenerateANCCardRequest
+	if err := h.ParseRequest(r, &req); err != nil {
+		h.log.Error("Failed to parse request", logger.Fields{
+			"request_id": reqID,
+			"error":      err.Error(),
+		})
+		response.WriteErrorResponse(w, reqID, err)
+		return
+	}
+
+	// Validate request
+	if err := h.validator.Validate(req); err != nil {
+		h.log.Error("Invalid request", logger.Fields{
+			"request_id": reqID,
+			"error":      err.Error(),
+		})
+		response.WriteErrorResponse(w, reqID, err)
+		return
+	}
+
+	// Parse UUID
+	motherID, err := uuid.Parse(req.MotherID)
+	if err != nil {
+		response.WriteErrorResponse(w, reqID, errorx.New(errorx.BadRequest, "Invalid mother ID"))
+		return
+	}
+
+	// Generate card
+	card, err := h.reportService.GenerateANCCard(ctx, motherID)
+	if err != nil {
+		h.log.Error("Failed to generate ANC card", logger.Fields{
+			"request_id": reqID,
+			"error":      err.Error(),
+			"mother_id":  req.MotherID,
+		})
+		response.WriteErrorResponse(w, reqID, err)
+		return
+	}
+
+	h.log.Info("Generated ANC card", logger.Fields{
+		"request_id": reqID,
+		"mother_id":  req.MotherID,
+		"format":     req.Format,
+	})
+
+	h.writeReport(w, reqID, req.Format, card, func(format report.ExportFormat) (*report.ExportFile, error) {
+		return report.ExportANCCard(card, format)
+	})
+}
+
+// writeReport writes a report as JSON, or as a base64 encoded PDF/CSV file response when a
+// file format is requested, since Hasura actions can only return JSON
+func (h *ReportHandler) writeReport(
+	w http.ResponseWriter,
+	reqID string,
+	format string,
+	data interface{},
+	export func(format report.ExportFormat) (*report.ExportFile, error),
+) {
+	if format == "" || format == "json" {
+		response.WriteJSONResponse(w, reqID, data)
+		return
+	}
+
+	file, err := export(report.ExportFormat(format))
+	if err != nil {
+		h.log.Error("Failed to export report", logger.Fields{
+			"request_id": reqID,
+			"error":      err.Error(),
+			"format":     format,
+		})
+		response.WriteErrorResponse(w, reqID, err)
+		return
+	}
+
+	response.WriteJSONResponse(w, reqID, newFileResponse(file.Filename, file.ContentType, file.Data))
 }
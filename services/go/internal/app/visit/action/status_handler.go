@@ -15,6 +15,15 @@ import (
 
 // CheckInVisitRequest is the request for checking in a visit
 type CheckInVisitRequest struct {
+	VisitID        string   `json:"visit_id" validate:"required,uuid"`
+	Latitude       *float64 `json:"latitude,omitempty" validate:"omitempty,min=-90,max=90"`
+	Longitude      *float64 `json:"longitude,omitempty" validate:"omitempty,min=-180,max=180"`
+	AccuracyMeters *float64 `json:"accuracy_meters,omitempty" validate:"omitempty,min=0"`
+	Code           string   `json:"code,omitempty"` // OTP or scanned QR payload from the mother's app
+}
+
+// GetCheckInCodeRequest is the request for getting the rotating check-in code for a visit
+type GetCheckInCodeRequest struct {
 	VisitID string `json:"visit_id" validate:"required,uuid"`
 }
 
@@ -76,7 +85,8 @@ func (h *StatusHandler) CheckInVisit(w http.ResponseWriter, r *http.Request) {
 	reqID := response.GetRequestID(ctx)
 
 	var req CheckInVisitRequest
-	if err := h.ParseRequest(r, &req); err != nil {
+	actionReq, err := h.ParseRequest(r, &req)
+	if err != nil {
 		h.log.Error("Failed to parse request", logger.Fields{
 			"request_id": reqID,
 			"error":      err.Error(),
@@ -102,8 +112,23 @@ func (h *StatusHandler) CheckInVisit(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// The check-in is attributed to the caller, never to an ID in the input
+	checkedInByID, err := actionReq.UserID()
+	if err != nil {
+		response.WriteErrorResponse(w, reqID, err)
+		return
+	}
+
+	evidence := &status.CheckInEvidence{
+		CheckedInByID:  checkedInByID,
+		Latitude:       req.Latitude,
+		Longitude:      req.Longitude,
+		AccuracyMeters: req.AccuracyMeters,
+		Code:           req.Code,
+	}
+
 	// Check in visit
-	visit, err := h.statusService.CheckInVisit(ctx, visitID)
+	visit, err := h.statusService.CheckInVisit(ctx, visitID, evidence)
 	if err != nil {
 		h.log.Error("Failed to check in visit", logger.Fields{
 			"request_id": reqID,
@@ -117,11 +142,66 @@ func (h *StatusHandler) CheckInVisit(w http.ResponseWriter, r *http.Request) {
 	h.log.Info("Visit checked in successfully", logger.Fields{
 		"request_id": reqID,
 		"visit_id":   req.VisitID,
+		"verified":   visit.IsCheckInVerified(),
 	})
 
 	response.WriteJSONResponse(w, reqID, visit)
 }
 
+// GetCheckInCode gets the rotating check-in code the mother's app shows as a QR code or OTP.
+// Only the visit's mother can get it.
+func (h *StatusHandler) GetCheckInCode(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	reqID := response.GetRequestID(ctx)
+
+	var req GetCheckInCodeRequest
+	actionReq, err := h.ParseRequest(r, &req)
+	if err != nil {
+		h.log.Error("Failed to parse request", logger.Fields{
+			"request_id": reqID,
+			"error":      err.Error(),
+		})
+		response.WriteErrorResponse(w, reqID, err)
+		return
+	}
+
+	// Validate request
+	if err := h.validator.Validate(req); err != nil {
+		h.log.Error("Invalid request", logger.Fields{
+			"request_id": reqID,
+			"error":      err.Error(),
+		})
+		response.WriteErrorResponse(w, reqID, err)
+		return
+	}
+
+	// Parse UUID
+	visitID, err := uuid.Parse(req.VisitID)
+	if err != nil {
+		response.WriteErrorResponse(w, reqID, errorx.New(errorx.BadRequest, "Invalid visit ID"))
+		return
+	}
+
+	requesterID, err := actionReq.UserID()
+	if err != nil {
+		response.WriteErrorResponse(w, reqID, err)
+		return
+	}
+
+	code, err := h.statusService.GenerateCheckInCode(ctx, visitID, requesterID)
+	if err != nil {
+		h.log.Error("Failed to generate check-in code", logger.Fields{
+			"request_id": reqID,
+			"error":      err.Error(),
+			"visit_id":   req.VisitID,
+		})
+		response.WriteErrorResponse(w, reqID, err)
+		return
+	}
+
+	response.WriteJSONResponse(w, reqID, code)
+}
+
 // CompleteVisit completes a visit
 func (h *StatusHandler) CompleteVisit(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
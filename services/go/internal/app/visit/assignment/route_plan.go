@@ -0,0 +1,432 @@
+package assignment
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mamacare/services/internal/app/geo/routing"
+	"github.com/mamacare/services/internal/domain/model"
+	"github.com/mamacare/services/internal/domain/repository"
+	"github.com/mamacare/services/pkg/errorx"
+	"github.com/mamacare/services/pkg/logger"
+)
+
+// defaultStartLocation is used when a CHW has no facility with a known location (Freetown)
+var defaultStartLocation = model.GeoPoint{Latitude: 8.4657, Longitude: -13.2317}
+
+// RoutePlanOptions controls how CHW routes are planned
+type RoutePlanOptions struct {
+	TransportMode   routing.TransportMode `json:"transport_mode"`
+	DayStart        time.Duration         `json:"day_start"`      // offset from midnight
+	DayEnd          time.Duration         `json:"day_end"`        // offset from midnight
+	VisitDuration   time.Duration         `json:"visit_duration"` // time spent per home visit
+	WorkingDays     []time.Weekday        `json:"working_days,omitempty"`
+	MaxVisitsPerDay int                   `json:"max_visits_per_day,omitempty"`
+	ReturnToBase    bool                  `json:"return_to_base"`
+	// MotherAvailability is when each mother can receive visits; mothers not listed can be visited any time
+	MotherAvailability map[uuid.UUID][]Availability `json:"mother_availability,omitempty"`
+	// Villages limits when the mothers living in each village can be visited
+	Villages []VillageAvailability `json:"villages,omitempty"`
+}
+
+// Availability is a weekly period in which visits can be made, as offsets from midnight
+type Availability struct {
+	Weekday time.Weekday  `json:"weekday"`
+	From    time.Duration `json:"from"`
+	To      time.Duration `json:"to"`
+}
+
+// VillageAvailability is when the mothers of a village can be visited, for example
+// outside market days or when the road is passable
+type VillageAvailability struct {
+	Name      string         `json:"name"`
+	MotherIDs []uuid.UUID    `json:"mother_ids"`
+	Available []Availability `json:"available"`
+}
+
+// Reasons a visit could not be planned
+const (
+	unassignedNoMother      = "mother record not found"
+	unassignedNoLocation    = "no home location recorded"
+	unassignedUnavailable   = "mother or village not available in the visit window"
+	unassignedNoFeasibleDay = "outside working hours"
+)
+
+// DefaultRoutePlanOptions returns the standard CHW working pattern: on foot,
+// 08:00-17:00 Monday to Saturday, 30 minutes per visit, returning to base
+func DefaultRoutePlanOptions() *RoutePlanOptions {
+	return &RoutePlanOptions{
+		TransportMode: routing.TransportModeWalking,
+		DayStart:      8 * time.Hour,
+		DayEnd:        17 * time.Hour,
+		VisitDuration: 30 * time.Minute,
+		WorkingDays: []time.Weekday{
+			time.Monday, time.Tuesday, time.Wednesday,
+			time.Thursday, time.Friday, time.Saturday,
+		},
+		MaxVisitsPerDay: 8,
+		ReturnToBase:    true,
+	}
+}
+
+// WeeklyRoutePlan holds one route per working day for a CHW
+type WeeklyRoutePlan struct {
+	CHWID         uuid.UUID             `json:"chw_id"`
+	CHWName       string                `json:"chw_name"`
+	StartDate     time.Time             `json:"start_date"`
+	EndDate       time.Time             `json:"end_date"`
+	TransportMode routing.TransportMode `json:"transport_mode"`
+	StartLocation *model.GeoPoint       `json:"start_location"`
+	ReturnToBase  bool                  `json:"return_to_base"`
+	Routes        []*OptimizedRoute     `json:"routes"`
+	Unassigned    []*VisitWithLocation  `json:"unassigned"`
+	TotalTime     int                   `json:"total_time_minutes"`
+	Distance      float64               `json:"distance_km"`
+}
+
+// planCHWRoutes loads a CHW's scheduled visits for the period and plans them across working days
+func (s *Service) planCHWRoutes(
+	ctx context.Context,
+	chwID uuid.UUID,
+	start time.Time,
+	days int,
+	opts *RoutePlanOptions,
+) (*WeeklyRoutePlan, error) {
+	if opts.DayEnd <= opts.DayStart {
+		return nil, errorx.New(errorx.BadRequest, "day end must be after day start")
+	}
+	if opts.TransportMode == "" {
+		opts.TransportMode = routing.TransportModeWalking
+	}
+
+	end := start.AddDate(0, 0, days)
+
+	user, err := s.userRepo.GetByID(ctx, chwID)
+	if err != nil {
+		s.log.Error("Failed to find CHW", logger.Fields{
+			"error":  err.Error(),
+			"chw_id": chwID.String(),
+		})
+		return nil, errorx.Wrap(err, "failed to find CHW")
+	}
+
+	options := repository.NewVisitQueryOptions().
+		WithDateRange(start, end).
+		WithStatus(model.VisitStatusScheduled).
+		WithOrder("scheduled_time", "ASC")
+
+	visits, err := s.visitRepo.GetByCHW(ctx, chwID, options)
+	if err != nil {
+		s.log.Error("Failed to get CHW visits", logger.Fields{
+			"error":      err.Error(),
+			"chw_id":     chwID.String(),
+			"start_date": start.Format("2006-01-02"),
+			"end_date":   end.Format("2006-01-02"),
+		})
+		return nil, errorx.Wrap(err, "failed to get CHW visits")
+	}
+
+	startLocation := s.resolveStartLocation(ctx, user)
+
+	// Build one shift per working day in the period
+	shiftDates := make([]time.Time, 0, days)
+	shifts := make([]routing.VRPShift, 0, days)
+	for i := 0; i < days; i++ {
+		day := start.AddDate(0, 0, i)
+		if !isWorkingDay(day.Weekday(), opts.WorkingDays) {
+			continue
+		}
+		shiftDates = append(shiftDates, day)
+		shifts = append(shifts, routing.VRPShift{
+			Start: day.Add(opts.DayStart),
+			End:   day.Add(opts.DayEnd),
+		})
+	}
+
+	plan := &WeeklyRoutePlan{
+		CHWID:         chwID,
+		CHWName:       user.Name,
+		StartDate:     start,
+		EndDate:       end,
+		TransportMode: opts.TransportMode,
+		StartLocation: startLocation,
+		ReturnToBase:  opts.ReturnToBase,
+		Routes:        make([]*OptimizedRoute, 0, len(shifts)),
+		Unassigned:    []*VisitWithLocation{},
+	}
+
+	for _, day := range shiftDates {
+		plan.Routes = append(plan.Routes, &OptimizedRoute{
+			CHWId:         chwID,
+			Date:          day,
+			TransportMode: opts.TransportMode,
+			StartLocation: startLocation,
+			Visits:        []*VisitWithLocation{},
+			EndTime:       day.Add(opts.DayStart),
+		})
+	}
+
+	if len(visits) == 0 || len(shifts) == 0 {
+		s.log.Info("No visits to plan for CHW", logger.Fields{
+			"chw_id":     chwID.String(),
+			"start_date": start.Format("2006-01-02"),
+			"visits":     len(visits),
+			"shifts":     len(shifts),
+		})
+		return plan, nil
+	}
+
+	// Collect mother locations for each visit
+	// TODO: Replace with batch query to get all mothers at once
+	located := make([]*VisitWithLocation, 0, len(visits))
+	stops := make([]routing.VRPStop, 0, len(visits))
+	for _, visit := range visits {
+		mother, err := s.motherRepo.GetByID(ctx, visit.MotherID)
+		if err != nil {
+			s.log.Error("Failed to find mother", logger.Fields{
+				"error":     err.Error(),
+				"mother_id": visit.MotherID.String(),
+			})
+			plan.Unassigned = append(plan.Unassigned, &VisitWithLocation{
+				Visit:            visit,
+				UnassignedReason: unassignedNoMother,
+			})
+			continue
+		}
+
+		vwl := &VisitWithLocation{
+			Visit:  visit,
+			Mother: mother,
+		}
+		if motherUser, err := s.userRepo.GetByID(ctx, mother.UserID); err == nil {
+			vwl.MotherName = motherUser.Name
+			vwl.MotherPhone = motherUser.Phone
+		}
+
+		// Visits without a home location can't be routed
+		if mother.Location == nil {
+			vwl.UnassignedReason = unassignedNoLocation
+			plan.Unassigned = append(plan.Unassigned, vwl)
+			continue
+		}
+		vwl.Location = mother.Location
+
+		windowStart, windowEnd := visitWindow(visit, start, end, opts)
+		windows, ok := availableWindows(mother.ID, windowStart, windowEnd, opts)
+		if !ok {
+			vwl.UnassignedReason = unassignedUnavailable
+			plan.Unassigned = append(plan.Unassigned, vwl)
+			continue
+		}
+		located = append(located, vwl)
+		stops = append(stops, routing.VRPStop{
+			ID:   visit.ID.String(),
+			Name: vwl.MotherName,
+			Location: model.Location{
+				Latitude:  mother.Location.Latitude,
+				Longitude: mother.Location.Longitude,
+			},
+			WindowStart:     windowStart,
+			WindowEnd:       windowEnd,
+			Windows:         windows,
+			ServiceDuration: opts.VisitDuration,
+			Priority:        visitPriority(visit, mother),
+		})
+	}
+
+	solution, err := s.routePlanner.PlanRoutes(ctx, &routing.VRPRequest{
+		Depot: model.Location{
+			Latitude:  startLocation.Latitude,
+			Longitude: startLocation.Longitude,
+		},
+		ReturnToDepot:  opts.ReturnToBase,
+		Stops:          stops,
+		Shifts:         shifts,
+		TransportMode:  opts.TransportMode,
+		MaxStopsPerDay: opts.MaxVisitsPerDay,
+	})
+	if err != nil {
+		s.log.Error("Failed to plan routes", logger.Fields{
+			"error":  err.Error(),
+			"chw_id": chwID.String(),
+		})
+		return nil, errorx.Wrap(err, "failed to plan routes")
+	}
+
+	byID := make(map[string]*VisitWithLocation, len(located))
+	for _, vwl := range located {
+		byID[vwl.Visit.ID.String()] = vwl
+	}
+
+	for i, day := range solution.Days {
+		route := plan.Routes[i]
+		for _, stop := range day.Stops {
+			vwl := byID[stop.Stop.ID]
+			vwl.EstimatedArrival = stop.Arrival
+			vwl.EstimatedDeparture = stop.Departure
+			vwl.DistanceKm = stop.DistanceFromPrevious
+			route.Visits = append(route.Visits, vwl)
+		}
+		route.Distance = day.TotalDistance
+		route.TotalTime = day.TotalDuration / 60
+		route.EndTime = day.EndTime
+
+		plan.Distance += route.Distance
+		plan.TotalTime += route.TotalTime
+	}
+
+	for _, stop := range solution.Unassigned {
+		vwl := byID[stop.ID]
+		vwl.UnassignedReason = unassignedNoFeasibleDay
+		plan.Unassigned = append(plan.Unassigned, vwl)
+	}
+
+	return plan, nil
+}
+
+// resolveStartLocation uses the CHW's facility as the base, falling back to the default
+func (s *Service) resolveStartLocation(ctx context.Context, user *model.User) *model.GeoPoint {
+	if user.FacilityID != nil {
+		facility, err := s.facilityRepo.GetByID(ctx, *user.FacilityID)
+		if err == nil {
+			return &model.GeoPoint{
+				Latitude:  facility.Location.Latitude,
+				Longitude: facility.Location.Longitude,
+			}
+		}
+		s.log.Warn("Failed to find CHW facility, using default start location", logger.Fields{
+			"error":       err.Error(),
+			"chw_id":      user.ID.String(),
+			"facility_id": user.FacilityID.String(),
+		})
+	}
+
+	location := defaultStartLocation
+	return &location
+}
+
+// visitWindow returns the period in which a visit may be made. Emergency visits
+// must happen within two hours of the scheduled time; follow-ups may slip a day;
+// routine visits may move up to two days either side. Windows are clipped to the plan period.
+func visitWindow(visit *model.Visit, periodStart, periodEnd time.Time, opts *RoutePlanOptions) (time.Time, time.Time) {
+	scheduled := visit.ScheduledTime
+	scheduledDay := time.Date(scheduled.Year(), scheduled.Month(), scheduled.Day(), 0, 0, 0, 0, periodStart.Location())
+
+	var windowStart, windowEnd time.Time
+	switch visit.VisitType {
+	case model.VisitTypeEmergency:
+		windowStart = scheduled
+		windowEnd = scheduled.Add(2 * time.Hour)
+	case model.VisitTypeFollowUp:
+		windowStart = scheduledDay.Add(opts.DayStart)
+		windowEnd = scheduledDay.AddDate(0, 0, 1).Add(opts.DayEnd)
+	default:
+		windowStart = scheduledDay.AddDate(0, 0, -2).Add(opts.DayStart)
+		windowEnd = scheduledDay.AddDate(0, 0, 2).Add(opts.DayEnd)
+	}
+
+	if windowStart.Before(periodStart) {
+		windowStart = periodStart
+	}
+	if windowEnd.After(periodEnd) {
+		windowEnd = periodEnd
+	}
+
+	return windowStart, windowEnd
+}
+
+// availableWindows intersects a visit window with when the mother and her village can be
+// visited. It returns nil windows if neither has availability set, meaning the visit window
+// alone applies, and false if they are set but never overlap the visit window.
+func availableWindows(motherID uuid.UUID, windowStart, windowEnd time.Time, opts *RoutePlanOptions) ([]routing.TimeWindow, bool) {
+	var constraints [][]Availability
+	if slots, ok := opts.MotherAvailability[motherID]; ok {
+		constraints = append(constraints, slots)
+	}
+	for _, village := range opts.Villages {
+		for _, id := range village.MotherIDs {
+			if id == motherID {
+				constraints = append(constraints, village.Available)
+				break
+			}
+		}
+	}
+	if len(constraints) == 0 {
+		return nil, true
+	}
+
+	var windows []routing.TimeWindow
+	firstDay := time.Date(windowStart.Year(), windowStart.Month(), windowStart.Day(), 0, 0, 0, 0, windowStart.Location())
+	for day := firstDay; day.Before(windowEnd); day = day.AddDate(0, 0, 1) {
+		dayWindows := []routing.TimeWindow{{Start: windowStart, End: windowEnd}}
+		for _, slots := range constraints {
+			dayWindows = intersectWindows(dayWindows, weeklyWindows(day, slots))
+		}
+		windows = append(windows, dayWindows...)
+	}
+
+	return windows, len(windows) > 0
+}
+
+// weeklyWindows returns the periods of weekly availability that fall on a day
+func weeklyWindows(day time.Time, slots []Availability) []routing.TimeWindow {
+	var windows []routing.TimeWindow
+	for _, slot := range slots {
+		if slot.Weekday == day.Weekday() && slot.To > slot.From {
+			windows = append(windows, routing.TimeWindow{
+				Start: day.Add(slot.From),
+				End:   day.Add(slot.To),
+			})
+		}
+	}
+	return windows
+}
+
+// intersectWindows returns the periods covered by both sets of windows
+func intersectWindows(a, b []routing.TimeWindow) []routing.TimeWindow {
+	var overlap []routing.TimeWindow
+	for _, wa := range a {
+		for _, wb := range b {
+			start, end := wa.Start, wa.End
+			if wb.Start.After(start) {
+				start = wb.Start
+			}
+			if wb.End.Before(end) {
+				end = wb.End
+			}
+			if end.After(start) {
+				overlap = append(overlap, routing.TimeWindow{Start: start, End: end})
+			}
+		}
+	}
+	return overlap
+}
+
+// visitPriority ranks visits so urgent and high-risk mothers are planned first
+func visitPriority(visit *model.Visit, mother *model.Mother) int {
+	priority := 1
+	switch visit.VisitType {
+	case model.VisitTypeEmergency:
+		priority += 3
+	case model.VisitTypeFollowUp:
+		priority++
+	}
+	if mother.IsHighRisk() {
+		priority += 2
+	}
+	return priority
+}
+
+// isWorkingDay reports whether a weekday is in the working days; no list means every day
+func isWorkingDay(day time.Weekday, workingDays []time.Weekday) bool {
+	if len(workingDays) == 0 {
+		return true
+	}
+	for _, d := range workingDays {
+		if d == day {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,229 @@
+package assignment
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"time"
+
+	"github.com/mamacare/services/internal/app/geo/routing"
+	"github.com/mamacare/services/pkg/pdf"
+)
+
+// gpxDocument is the root element of a GPX 1.1 file
+type gpxDocument struct {
+	XMLName   xml.Name      `xml:"gpx"`
+	Version   string        `xml:"version,attr"`
+	Creator   string        `xml:"creator,attr"`
+	Namespace string        `xml:"xmlns,attr"`
+	Metadata  gpxMetadata   `xml:"metadata"`
+	Waypoints []gpxWaypoint `xml:"wpt"`
+	Routes    []gpxRoute    `xml:"rte"`
+}
+
+type gpxMetadata struct {
+	Name string `xml:"name"`
+	Time string `xml:"time"`
+}
+
+type gpxWaypoint struct {
+	Lat         float64 `xml:"lat,attr"`
+	Lon         float64 `xml:"lon,attr"`
+	Time        string  `xml:"time,omitempty"`
+	Name        string  `xml:"name,omitempty"`
+	Description string  `xml:"desc,omitempty"`
+	Type        string  `xml:"type,omitempty"`
+}
+
+type gpxRoute struct {
+	Name   string        `xml:"name"`
+	Points []gpxWaypoint `xml:"rtept"`
+}
+
+// RenderRouteSheetGPX renders a route plan as GPX with one route per day, starting at
+// the base and ending there if the plan returns to base, so CHWs can load it into
+// offline map apps on their phones
+func RenderRouteSheetGPX(plan *WeeklyRoutePlan) ([]byte, error) {
+	doc := gpxDocument{
+		Version:   "1.1",
+		Creator:   "MamaCare SL",
+		Namespace: "http://www.topografix.com/GPX/1/1",
+		Metadata: gpxMetadata{
+			Name: fmt.Sprintf("Route sheet for %s", plan.CHWName),
+			Time: time.Now().UTC().Format(time.RFC3339),
+		},
+	}
+
+	base := gpxWaypoint{
+		Lat:  plan.StartLocation.Latitude,
+		Lon:  plan.StartLocation.Longitude,
+		Name: "Base",
+		Type: "base",
+	}
+	doc.Waypoints = append(doc.Waypoints, base)
+
+	for _, route := range plan.Routes {
+		if len(route.Visits) == 0 {
+			continue
+		}
+
+		rte := gpxRoute{
+			Name: route.Date.Format("Mon 02 Jan 2006"),
+		}
+		rte.Points = append(rte.Points, base)
+
+		for i, v := range route.Visits {
+			wpt := gpxWaypoint{
+				Lat:         v.Location.Latitude,
+				Lon:         v.Location.Longitude,
+				Time:        v.EstimatedArrival.UTC().Format(time.RFC3339),
+				Name:        fmt.Sprintf("%d. %s", i+1, visitLabel(v)),
+				Description: fmt.Sprintf("%s visit, arrive %s", v.Visit.VisitType, v.EstimatedArrival.Format("15:04")),
+				Type:        string(v.Visit.VisitType),
+			}
+			doc.Waypoints = append(doc.Waypoints, wpt)
+			rte.Points = append(rte.Points, wpt)
+		}
+
+		if route.TransportMode != "" {
+			rte.Name += " (" + string(route.TransportMode) + ")"
+		}
+		if plan.ReturnToBase {
+			rte.Points = append(rte.Points, base)
+		}
+		doc.Routes = append(doc.Routes, rte)
+	}
+
+	buf := &bytes.Buffer{}
+	buf.WriteString(xml.Header)
+	enc := xml.NewEncoder(buf)
+	enc.Indent("", "  ")
+	if err := enc.Encode(doc); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// RenderRouteSheetPDF renders a printable route sheet with one page per working day
+func RenderRouteSheetPDF(plan *WeeklyRoutePlan) ([]byte, error) {
+	doc := pdf.NewA4().SetInfo(
+		fmt.Sprintf("Route sheet - %s - week of %s", plan.CHWName, plan.StartDate.Format("02 Jan 2006")),
+		"MamaCare SL",
+	)
+
+	const margin = 40.0
+	columns := []struct {
+		title string
+		x     float64
+		width float64
+	}{
+		{"#", margin, 20},
+		{"Arrive", margin + 20, 45},
+		{"Leave", margin + 65, 45},
+		{"Mother", margin + 110, 130},
+		{"Phone", margin + 240, 85},
+		{"Visit", margin + 325, 60},
+		{"Dist.", margin + 385, 45},
+		{"Done", margin + 430, 85},
+	}
+
+	for _, route := range plan.Routes {
+		doc.AddPage()
+
+		y := margin + 10
+		doc.Text(margin, y, 16, true, "MamaCare SL - CHW Daily Route Sheet")
+		y += 20
+		doc.Text(margin, y, 10, false, fmt.Sprintf("CHW: %s", plan.CHWName))
+		doc.Text(margin+260, y, 10, false, fmt.Sprintf("Date: %s", route.Date.Format("Monday 02 January 2006")))
+		y += 14
+		doc.Text(margin, y, 10, false, fmt.Sprintf("Travel: %s", transportLabel(route.TransportMode)))
+		doc.Text(margin+260, y, 10, false, fmt.Sprintf("Visits: %d   Distance: %.1f km   Time: %s",
+			len(route.Visits), route.Distance, routing.FormatDuration(route.TotalTime*60)))
+		y += 10
+		doc.Line(margin, y, doc.Width()-margin, y)
+		y += 18
+
+		for _, col := range columns {
+			doc.Text(col.x, y, 9, true, col.title)
+		}
+		y += 6
+		doc.Line(margin, y, doc.Width()-margin, y)
+		y += 14
+
+		if len(route.Visits) == 0 {
+			doc.Text(margin, y, 10, false, "No visits planned for this day.")
+			continue
+		}
+
+		for i, v := range route.Visits {
+			if y > doc.Height()-margin-40 {
+				doc.AddPage()
+				y = margin + 10
+			}
+
+			cells := []string{
+				fmt.Sprintf("%d", i+1),
+				v.EstimatedArrival.Format("15:04"),
+				v.EstimatedDeparture.Format("15:04"),
+				visitLabel(v),
+				v.MotherPhone,
+				string(v.Visit.VisitType),
+				fmt.Sprintf("%.1f km", v.DistanceKm),
+			}
+			for c, text := range cells {
+				doc.Text(columns[c].x, y, 9, false, pdf.Truncate(text, 9, columns[c].width-4))
+			}
+			// Box for the CHW to tick or sign once the visit is done
+			doc.Rect(columns[7].x, y-9, 12, 12)
+			y += 20
+		}
+
+		y += 10
+		if plan.ReturnToBase {
+			doc.Text(margin, y, 9, false, "Return to base and hand this sheet to your supervisor at the end of the day.")
+		} else {
+			doc.Text(margin, y, 9, false, "Hand this sheet to your supervisor at the end of the day.")
+		}
+	}
+
+	if len(plan.Unassigned) > 0 {
+		doc.AddPage()
+		y := margin + 10
+		doc.Text(margin, y, 14, true, "Visits that could not be planned this week")
+		y += 24
+		for _, v := range plan.Unassigned {
+			if y > doc.Height()-margin {
+				doc.AddPage()
+				y = margin + 10
+			}
+			doc.Text(margin, y, 9, false, fmt.Sprintf("%s - %s visit scheduled %s (%s)",
+				visitLabel(v), v.Visit.VisitType, v.Visit.ScheduledTime.Format("02 Jan 15:04"), v.UnassignedReason))
+			y += 14
+		}
+	}
+
+	return doc.Bytes()
+}
+
+// visitLabel returns a printable name for a visit's mother
+func visitLabel(v *VisitWithLocation) string {
+	if v.MotherName != "" {
+		return v.MotherName
+	}
+	return "Mother " + v.Visit.MotherID.String()[:8]
+}
+
+// transportLabel describes a transport mode for route sheets
+func transportLabel(mode routing.TransportMode) string {
+	switch mode {
+	case routing.TransportModeWalking:
+		return "On foot"
+	case routing.TransportModeBicycling:
+		return "Bicycle"
+	case routing.TransportModeDriving:
+		return "Motorbike / vehicle"
+	default:
+		return string(mode)
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/mamacare/services/internal/app/geo/routing"
 	"github.com/mamacare/services/internal/domain/model"
 	"github.com/mamacare/services/internal/domain/repository"
 	"github.com/mamacare/services/pkg/errorx"
@@ -18,17 +19,14 @@ type Service struct {
 	motherRepo    repository.MotherRepository
 	userRepo      repository.UserRepository
 	facilityRepo  repository.FacilityRepository
-	routingClient RoutingClient
+	routePlanner  RoutePlanner
 	log           logger.Logger
 }
 
-// RoutingClient defines the interface for route optimization
-type RoutingClient interface {
-	// OptimizeRoute optimizes a route for a set of locations
-	OptimizeRoute(ctx context.Context, startLocation *model.GeoPoint, destinations []*model.GeoPoint) ([]int, error)
-	
-	// CalculateETA calculates the estimated time of arrival
-	CalculateETA(ctx context.Context, from, to *model.GeoPoint, departureTime time.Time) (time.Time, error)
+// RoutePlanner defines the interface for route optimization
+type RoutePlanner interface {
+	// PlanRoutes plans visits across one or more shifts honouring time windows
+	PlanRoutes(ctx context.Context, req *routing.VRPRequest) (*routing.VRPSolution, error)
 }
 
 // NewService creates a new visit assignment service
@@ -37,7 +35,7 @@ func NewService(
 	motherRepo repository.MotherRepository,
 	userRepo repository.UserRepository,
 	facilityRepo repository.FacilityRepository,
-	routingClient RoutingClient,
+	routePlanner RoutePlanner,
 	log logger.Logger,
 ) *Service {
 	return &Service{
@@ -45,7 +43,7 @@ func NewService(
 		motherRepo:    motherRepo,
 		userRepo:      userRepo,
 		facilityRepo:  facilityRepo,
-		routingClient: routingClient,
+		routePlanner:  routePlanner,
 		log:           log,
 	}
 }
@@ -288,155 +286,64 @@ func (s *Service) AssignVisitsByCatchmentArea(
 	return assignedCount, nil
 }
 
-// OptimizeCHWRoutes optimizes routes for CHWs based on visit locations
+// OptimizeCHWRoutes optimizes a single day's route for a CHW based on visit locations
 func (s *Service) OptimizeCHWRoutes(
 	ctx context.Context,
 	chwID uuid.UUID,
 	date time.Time,
+	opts *RoutePlanOptions,
 ) (*OptimizedRoute, error) {
-	// Create date range for the specified day
-	startOfDay := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, date.Location())
-	endOfDay := startOfDay.Add(24 * time.Hour)
-
-	// Get visits for the CHW on the specified date
-	options := repository.NewVisitQueryOptions().
-		WithDateRange(startOfDay, endOfDay).
-		WithStatus(model.VisitStatusScheduled).
-		WithOrder("scheduled_time", "ASC")
-
-	visits, err := s.visitRepo.GetByCHW(ctx, chwID, options)
-	if err != nil {
-		s.log.Error("Failed to get CHW visits", logger.Fields{
-			"error":  err.Error(),
-			"chw_id": chwID.String(),
-			"date":   date.Format("2006-01-02"),
-		})
-		return nil, errorx.Wrap(err, "failed to get CHW visits")
+	if opts == nil {
+		opts = DefaultRoutePlanOptions()
 	}
 
-	if len(visits) == 0 {
-		s.log.Info("No visits found for CHW on specified date", logger.Fields{
-			"chw_id": chwID.String(),
-			"date":   date.Format("2006-01-02"),
-		})
-		return &OptimizedRoute{
-			CHWId:     chwID,
-			Date:      date,
-			Visits:    []*VisitWithLocation{},
-			TotalTime: 0,
-			Distance:  0,
-		}, nil
-	}
-
-	// Get CHW information including starting location
-	user, err := s.userRepo.GetByID(ctx, chwID)
-	if err != nil {
-		s.log.Error("Failed to find CHW", logger.Fields{
-			"error":  err.Error(),
-			"chw_id": chwID.String(),
-		})
-		return nil, errorx.Wrap(err, "failed to find CHW")
-	}
+	startOfDay := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, date.Location())
 
-	// Get CHW's starting location (e.g., facility or home)
-	// TODO: Replace with actual CHW location lookup
-	startLocation := &model.GeoPoint{Latitude: 8.4657, Longitude: -13.2317} // Example: Freetown, Sierra Leone
+	// A single-day plan ignores working days; the caller asked for this date
+	dayOpts := *opts
+	dayOpts.WorkingDays = nil
 
-	// Collect mother locations for each visit
-	visitsWithLocations := make([]*VisitWithLocation, 0, len(visits))
-	motherIDs := make([]uuid.UUID, 0, len(visits))
-	
-	for _, visit := range visits {
-		motherIDs = append(motherIDs, visit.MotherID)
+	plan, err := s.planCHWRoutes(ctx, chwID, startOfDay, 1, &dayOpts)
+	if err != nil {
+		return nil, err
 	}
 
-	// Get mothers' information to get locations
-	// TODO: Replace with batch query to get all mothers at once
-	for i, visit := range visits {
-		mother, err := s.motherRepo.GetByID(ctx, visit.MotherID)
-		if err != nil {
-			s.log.Error("Failed to find mother", logger.Fields{
-				"error":     err.Error(),
-				"mother_id": visit.MotherID.String(),
-			})
-			continue
-		}
+	route := plan.Routes[0]
+	route.Unassigned = plan.Unassigned
 
-		// Skip if mother doesn't have location
-		if mother.Location == nil {
-			continue
-		}
-
-		visitsWithLocations = append(visitsWithLocations, &VisitWithLocation{
-			Visit:    visit,
-			Location: mother.Location,
-			Mother:   mother,
-		})
-	}
+	return route, nil
+}
 
-	if len(visitsWithLocations) == 0 {
-		return nil, errorx.New(errorx.BadRequest, "no visits with valid locations found")
+// PlanCHWWeek plans daily routes for a CHW over the week starting at weekStart.
+// Visits may move within their time window to another working day if that
+// gives a feasible, shorter plan; visits that cannot be fitted are returned as unassigned.
+func (s *Service) PlanCHWWeek(
+	ctx context.Context,
+	chwID uuid.UUID,
+	weekStart time.Time,
+	opts *RoutePlanOptions,
+) (*WeeklyRoutePlan, error) {
+	if opts == nil {
+		opts = DefaultRoutePlanOptions()
 	}
 
-	// Collect destination points for routing
-	destinations := make([]*model.GeoPoint, len(visitsWithLocations))
-	for i, vwl := range visitsWithLocations {
-		destinations[i] = vwl.Location
-	}
+	startOfWeek := time.Date(weekStart.Year(), weekStart.Month(), weekStart.Day(), 0, 0, 0, 0, weekStart.Location())
 
-	// Optimize route
-	visitOrder, err := s.routingClient.OptimizeRoute(ctx, startLocation, destinations)
+	plan, err := s.planCHWRoutes(ctx, chwID, startOfWeek, 7, opts)
 	if err != nil {
-		s.log.Error("Failed to optimize route", logger.Fields{
-			"error":  err.Error(),
-			"chw_id": chwID.String(),
-		})
-		return nil, errorx.Wrap(err, "failed to optimize route")
-	}
-
-	// Reorder visits based on optimized route
-	optimizedVisits := make([]*VisitWithLocation, len(visitOrder))
-	for i, idx := range visitOrder {
-		optimizedVisits[i] = visitsWithLocations[idx]
+		return nil, err
 	}
 
-	// Calculate ETAs for each visit
-	currentTime := startOfDay.Add(8 * time.Hour) // Assume 8 AM start
-	currentLocation := startLocation
-
-	for i, visit := range optimizedVisits {
-		// Calculate ETA
-		eta, err := s.routingClient.CalculateETA(ctx, currentLocation, visit.Location, currentTime)
-		if err != nil {
-			s.log.Error("Failed to calculate ETA", logger.Fields{
-				"error":    err.Error(),
-				"visit_id": visit.Visit.ID.String(),
-			})
-			// Use a rough estimate if calculation fails
-			eta = currentTime.Add(30 * time.Minute)
-		}
-
-		// Set ETA
-		visit.EstimatedArrival = eta
-
-		// Update current state for next calculation
-		currentTime = eta.Add(30 * time.Minute) // Assume 30 minutes per visit
-		currentLocation = visit.Location
-	}
-
-	// Calculate total distance and time (this would normally come from the routing engine)
-	// For now, we'll just use a placeholder calculation
-	totalDistance := 0.0
-	totalTimeMinutes := 0
+	s.log.Info("Planned weekly CHW routes", logger.Fields{
+		"chw_id":         chwID.String(),
+		"week_start":     startOfWeek.Format("2006-01-02"),
+		"days":           len(plan.Routes),
+		"unassigned":     len(plan.Unassigned),
+		"distance_km":    plan.Distance,
+		"transport_mode": string(opts.TransportMode),
+	})
 
-	// Return the optimized route
-	return &OptimizedRoute{
-		CHWId:     chwID,
-		Date:      date,
-		Visits:    optimizedVisits,
-		TotalTime: totalTimeMinutes,
-		Distance:  totalDistance,
-	}, nil
+	return plan, nil
 }
 
 // BalanceWorkload balances workload across CHWs by redistributing visits
@@ -610,19 +517,29 @@ func getCapacity(chws []struct {
 
 // OptimizedRoute represents an optimized route for a CHW
 type OptimizedRoute struct {
-	CHWId     uuid.UUID            `json:"chw_id"`
-	Date      time.Time            `json:"date"`
-	Visits    []*VisitWithLocation `json:"visits"`
-	TotalTime int                  `json:"total_time_minutes"`
-	Distance  float64              `json:"distance_km"`
+	CHWId         uuid.UUID             `json:"chw_id"`
+	Date          time.Time             `json:"date"`
+	TransportMode routing.TransportMode `json:"transport_mode"`
+	StartLocation *model.GeoPoint       `json:"start_location"`
+	Visits        []*VisitWithLocation  `json:"visits"`
+	Unassigned    []*VisitWithLocation  `json:"unassigned,omitempty"`
+	TotalTime     int                   `json:"total_time_minutes"`
+	Distance      float64               `json:"distance_km"`
+	EndTime       time.Time             `json:"end_time"`
 }
 
 // VisitWithLocation combines a visit with its location information
 type VisitWithLocation struct {
-	Visit           *model.Visit     `json:"visit"`
-	Location        *model.GeoPoint  `json:"location"`
-	Mother          *model.Mother    `json:"mother"`
-	EstimatedArrival time.Time       `json:"estimated_arrival"`
+	Visit              *model.Visit    `json:"visit"`
+	Location           *model.GeoPoint `json:"location"`
+	Mother             *model.Mother   `json:"mother"`
+	MotherName         string          `json:"mother_name,omitempty"`
+	MotherPhone        string          `json:"mother_phone,omitempty"`
+	EstimatedArrival   time.Time       `json:"estimated_arrival"`
+	EstimatedDeparture time.Time       `json:"estimated_departure"`
+	DistanceKm         float64         `json:"distance_km"` // from the previous stop
+	// UnassignedReason explains why a visit could not be planned
+	UnassignedReason string `json:"unassigned_reason,omitempty"`
 }
 
 // UpdateVisitOrder updates the order of visits for a CHW
@@ -0,0 +1,554 @@
+package calendar
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mamacare/services/internal/domain/model"
+	"github.com/mamacare/services/internal/domain/repository"
+	"github.com/mamacare/services/pkg/errorx"
+	"github.com/mamacare/services/pkg/ical"
+	"github.com/mamacare/services/pkg/logger"
+)
+
+const (
+	// feedPastWindow is how far back a feed includes visits
+	feedPastWindow = 30 * 24 * time.Hour
+	// feedFutureWindow is how far ahead a feed includes visits
+	feedFutureWindow = 180 * 24 * time.Hour
+	// defaultVisitLength is the calendar duration given to a visit
+	defaultVisitLength = 30 * time.Minute
+	// feedPageSize is how many visits are read per query when building a feed
+	feedPageSize = 500
+)
+
+// FeedLink is a calendar feed together with its signed subscription URL
+type FeedLink struct {
+	Feed *model.CalendarFeed `json:"feed"`
+	URL  string              `json:"url"`
+}
+
+// RenderedFeed is an iCalendar document ready to be served
+type RenderedFeed struct {
+	Name         string
+	Content      []byte
+	LastModified time.Time
+	EventCount   int
+}
+
+// Service provides iCalendar feeds of visits for users and facilities
+type Service struct {
+	feedRepo     repository.CalendarFeedRepository
+	visitRepo    repository.VisitRepository
+	motherRepo   repository.MotherRepository
+	userRepo     repository.UserRepository
+	facilityRepo repository.FacilityRepository
+	signingKey   []byte
+	baseURL      string
+	log          logger.Logger
+}
+
+// NewService creates a new calendar feed service. It fails if no signing key is
+// configured, since feed URLs could otherwise be forged.
+func NewService(
+	feedRepo repository.CalendarFeedRepository,
+	visitRepo repository.VisitRepository,
+	motherRepo repository.MotherRepository,
+	userRepo repository.UserRepository,
+	facilityRepo repository.FacilityRepository,
+	signingKey string,
+	baseURL string,
+	log logger.Logger,
+) (*Service, error) {
+	if strings.TrimSpace(signingKey) == "" {
+		return nil, errorx.New(errorx.InternalServerError, "calendar signing key is not configured")
+	}
+
+	return &Service{
+		feedRepo:     feedRepo,
+		visitRepo:    visitRepo,
+		motherRepo:   motherRepo,
+		userRepo:     userRepo,
+		facilityRepo: facilityRepo,
+		signingKey:   []byte(signingKey),
+		baseURL:      strings.TrimRight(baseURL, "/"),
+		log:          log,
+	}, nil
+}
+
+// CreateFeed creates a calendar feed for a user or facility and returns its signed URL.
+// The creator must own the feed or be an admin.
+func (s *Service) CreateFeed(
+	ctx context.Context,
+	scope model.CalendarFeedScope,
+	ownerID uuid.UUID,
+	createdByID uuid.UUID,
+) (*FeedLink, error) {
+	if err := s.authorize(ctx, scope, ownerID, createdByID); err != nil {
+		return nil, err
+	}
+
+	var name string
+
+	switch scope {
+	case model.CalendarFeedScopeUser:
+		user, err := s.userRepo.GetByID(ctx, ownerID)
+		if err != nil {
+			s.log.Error("Failed to find user", logger.Fields{
+				"error":   err.Error(),
+				"user_id": ownerID.String(),
+			})
+			return nil, errorx.Wrap(err, "failed to find user")
+		}
+		if !user.IsHealthcareProvider() {
+			return nil, errorx.New(errorx.BadRequest, "calendar feeds are only available for CHWs and clinicians")
+		}
+		name = fmt.Sprintf("MamaCare visits - %s", user.Name)
+	case model.CalendarFeedScopeFacility:
+		facility, err := s.facilityRepo.GetByID(ctx, ownerID)
+		if err != nil {
+			s.log.Error("Failed to find facility", logger.Fields{
+				"error":       err.Error(),
+				"facility_id": ownerID.String(),
+			})
+			return nil, errorx.Wrap(err, "failed to find facility")
+		}
+		name = fmt.Sprintf("MamaCare visits - %s", facility.Name)
+	default:
+		return nil, errorx.Newf(errorx.BadRequest, "unsupported calendar feed scope %s", scope)
+	}
+
+	feed := model.NewCalendarFeed(uuid.New(), scope, ownerID, createdByID, name)
+
+	if err := s.feedRepo.Create(ctx, feed); err != nil {
+		s.log.Error("Failed to create calendar feed", logger.Fields{
+			"error":    err.Error(),
+			"scope":    string(scope),
+			"owner_id": ownerID.String(),
+		})
+		return nil, errorx.Wrap(err, "failed to create calendar feed")
+	}
+
+	s.log.Info("Calendar feed created successfully", logger.Fields{
+		"feed_id":       feed.ID.String(),
+		"scope":         string(scope),
+		"owner_id":      ownerID.String(),
+		"created_by_id": createdByID.String(),
+	})
+
+	return &FeedLink{Feed: feed, URL: s.feedURL(feed)}, nil
+}
+
+// ListFeeds lists the calendar feeds for a user or facility with their URLs.
+// The requester must own the feeds or be an admin.
+func (s *Service) ListFeeds(
+	ctx context.Context,
+	scope model.CalendarFeedScope,
+	ownerID uuid.UUID,
+	requesterID uuid.UUID,
+) ([]*FeedLink, error) {
+	if err := s.authorize(ctx, scope, ownerID, requesterID); err != nil {
+		return nil, err
+	}
+
+	feeds, err := s.feedRepo.GetByOwner(ctx, scope, ownerID)
+	if err != nil {
+		s.log.Error("Failed to get calendar feeds", logger.Fields{
+			"error":    err.Error(),
+			"scope":    string(scope),
+			"owner_id": ownerID.String(),
+		})
+		return nil, errorx.Wrap(err, "failed to get calendar feeds")
+	}
+
+	links := make([]*FeedLink, 0, len(feeds))
+	for _, feed := range feeds {
+		link := &FeedLink{Feed: feed}
+		// Revoked feeds are listed for auditing but no longer get a usable URL
+		if feed.IsActive() {
+			link.URL = s.feedURL(feed)
+		}
+		links = append(links, link)
+	}
+
+	return links, nil
+}
+
+// RevokeFeed revokes a calendar feed so its URL stops working.
+// The requester must own the feed or be an admin.
+func (s *Service) RevokeFeed(
+	ctx context.Context,
+	feedID uuid.UUID,
+	requesterID uuid.UUID,
+) (*model.CalendarFeed, error) {
+	feed, err := s.feedRepo.GetByID(ctx, feedID)
+	if err != nil {
+		s.log.Error("Failed to find calendar feed", logger.Fields{
+			"error":   err.Error(),
+			"feed_id": feedID.String(),
+		})
+		return nil, errorx.Wrap(err, "failed to find calendar feed")
+	}
+
+	if err := s.authorize(ctx, feed.Scope, feed.OwnerID, requesterID); err != nil {
+		return nil, err
+	}
+
+	if !feed.IsActive() {
+		return feed, nil
+	}
+
+	feed.Revoke()
+
+	if err := s.feedRepo.Update(ctx, feed); err != nil {
+		s.log.Error("Failed to revoke calendar feed", logger.Fields{
+			"error":   err.Error(),
+			"feed_id": feedID.String(),
+		})
+		return nil, errorx.Wrap(err, "failed to revoke calendar feed")
+	}
+
+	s.log.Info("Calendar feed revoked successfully", logger.Fields{
+		"feed_id":  feedID.String(),
+		"scope":    string(feed.Scope),
+		"owner_id": feed.OwnerID.String(),
+	})
+
+	return feed, nil
+}
+
+// authorize checks that the requester owns a feed, or is an admin. A facility's
+// feeds are owned by the staff assigned to that facility.
+func (s *Service) authorize(
+	ctx context.Context,
+	scope model.CalendarFeedScope,
+	ownerID uuid.UUID,
+	requesterID uuid.UUID,
+) error {
+	requester, err := s.userRepo.GetByID(ctx, requesterID)
+	if err != nil {
+		s.log.Error("Failed to find requester", logger.Fields{
+			"error":   err.Error(),
+			"user_id": requesterID.String(),
+		})
+		return errorx.Wrap(err, "failed to find requester")
+	}
+
+	if requester.HasAdminAccess() {
+		return nil
+	}
+
+	switch scope {
+	case model.CalendarFeedScopeUser:
+		if requester.ID == ownerID {
+			return nil
+		}
+	case model.CalendarFeedScopeFacility:
+		if requester.IsHealthcareProvider() && requester.FacilityID != nil && *requester.FacilityID == ownerID {
+			return nil
+		}
+	}
+
+	return errorx.New(errorx.Forbidden, "not authorized to manage this calendar feed")
+}
+
+// RenderFeed verifies a feed's signature and renders its visits as iCalendar.
+// Rescheduled visits carry a higher SEQUENCE and cancelled visits stay in the
+// feed with STATUS:CANCELLED, so subscribed calendars update in place.
+func (s *Service) RenderFeed(
+	ctx context.Context,
+	feedID uuid.UUID,
+	token string,
+) (*RenderedFeed, error) {
+	if !hmac.Equal([]byte(token), []byte(s.sign(feedID))) {
+		return nil, errorx.New(errorx.Unauthorized, "invalid calendar feed token")
+	}
+
+	feed, err := s.feedRepo.GetByID(ctx, feedID)
+	if err != nil {
+		return nil, errorx.Wrap(err, "failed to find calendar feed")
+	}
+
+	if !feed.IsActive() {
+		return nil, errorx.New(errorx.NotFound, "calendar feed has been revoked")
+	}
+
+	visits, err := s.feedVisits(ctx, feed)
+	if err != nil {
+		return nil, err
+	}
+
+	cal := ical.New(feed.Name)
+	lastModified := feed.CreatedAt
+	resolver := newVisitResolver(s)
+
+	for _, visit := range visits {
+		cal.AddEvent(resolver.event(ctx, visit))
+		if visit.UpdatedAt.After(lastModified) {
+			lastModified = visit.UpdatedAt
+		}
+	}
+
+	// Recording access is best effort and must not break the feed
+	feed.MarkAccessed()
+	if err := s.feedRepo.Update(ctx, feed); err != nil {
+		s.log.Warn("Failed to record calendar feed access", logger.Fields{
+			"error":   err.Error(),
+			"feed_id": feedID.String(),
+		})
+	}
+
+	return &RenderedFeed{
+		Name:         feed.Name,
+		Content:      cal.Bytes(),
+		LastModified: lastModified,
+		EventCount:   len(visits),
+	}, nil
+}
+
+// feedVisits retrieves the visits published by a feed
+func (s *Service) feedVisits(ctx context.Context, feed *model.CalendarFeed) ([]*model.Visit, error) {
+	now := time.Now()
+	start := now.Add(-feedPastWindow)
+	end := now.Add(feedFutureWindow)
+
+	// Cancelled visits are included so clients can remove them from their calendars
+	options := repository.NewVisitQueryOptions().
+		WithDateRange(start, end).
+		WithOrder("scheduled_time", "ASC")
+
+	var visits []*model.Visit
+	var err error
+
+	switch feed.Scope {
+	case model.CalendarFeedScopeFacility:
+		visits, err = allVisits(options, func(options *repository.VisitQueryOptions) ([]*model.Visit, error) {
+			return s.visitRepo.GetByFacilityID(ctx, feed.OwnerID, options)
+		})
+	case model.CalendarFeedScopeUser:
+		user, userErr := s.userRepo.GetByID(ctx, feed.OwnerID)
+		if userErr != nil {
+			return nil, errorx.Wrap(userErr, "failed to find feed owner")
+		}
+
+		switch user.Role {
+		case model.RoleCHW:
+			visits, err = allVisits(options, func(options *repository.VisitQueryOptions) ([]*model.Visit, error) {
+				return s.visitRepo.GetByCHW(ctx, user.ID, options)
+			})
+		case model.RoleClinician:
+			// Clinicians see the visits assigned to them at their facility
+			if user.FacilityID == nil {
+				return nil, nil
+			}
+			var facilityVisits []*model.Visit
+			facilityVisits, err = allVisits(options, func(options *repository.VisitQueryOptions) ([]*model.Visit, error) {
+				return s.visitRepo.GetByFacilityID(ctx, *user.FacilityID, options)
+			})
+			for _, visit := range facilityVisits {
+				if visit.ClinicianID != nil && *visit.ClinicianID == user.ID {
+					visits = append(visits, visit)
+				}
+			}
+		default:
+			return nil, errorx.New(errorx.BadRequest, "calendar feeds are only available for CHWs and clinicians")
+		}
+	default:
+		return nil, errorx.Newf(errorx.BadRequest, "unsupported calendar feed scope %s", feed.Scope)
+	}
+
+	if err != nil {
+		s.log.Error("Failed to get visits for calendar feed", logger.Fields{
+			"error":    err.Error(),
+			"feed_id":  feed.ID.String(),
+			"scope":    string(feed.Scope),
+			"owner_id": feed.OwnerID.String(),
+		})
+		return nil, errorx.Wrap(err, "failed to get visits for calendar feed")
+	}
+
+	return visits, nil
+}
+
+// allVisits reads every page of a visit query, as the query options otherwise stop at
+// their default limit
+func allVisits(
+	options *repository.VisitQueryOptions,
+	query func(options *repository.VisitQueryOptions) ([]*model.Visit, error),
+) ([]*model.Visit, error) {
+	var visits []*model.Visit
+	options.WithLimit(feedPageSize)
+
+	for offset := 0; ; offset += feedPageSize {
+		page, err := query(options.WithOffset(offset))
+		if err != nil {
+			return nil, err
+		}
+		visits = append(visits, page...)
+		if len(page) < feedPageSize {
+			return visits, nil
+		}
+	}
+}
+
+// feedURL builds the signed subscription URL for a feed
+func (s *Service) feedURL(feed *model.CalendarFeed) string {
+	return fmt.Sprintf("%s/%s.ics?token=%s", s.baseURL, feed.ID.String(), s.sign(feed.ID))
+}
+
+// sign returns the URL token for a feed
+func (s *Service) sign(feedID uuid.UUID) string {
+	mac := hmac.New(sha256.New, s.signingKey)
+	mac.Write([]byte("calendar-feed:" + feedID.String()))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// visitResolver looks up the people and places shown on calendar events,
+// caching lookups since feeds list many visits for the same mothers and facilities
+type visitResolver struct {
+	s          *Service
+	mothers    map[uuid.UUID]*motherContact
+	facilities map[uuid.UUID]*model.HealthcareFacility
+}
+
+// motherContact is the mother's name, phone and home shown on an event
+type motherContact struct {
+	name  string
+	phone string
+	home  *model.GeoPoint
+}
+
+// newVisitResolver creates a new visit resolver
+func newVisitResolver(s *Service) *visitResolver {
+	return &visitResolver{
+		s:          s,
+		mothers:    make(map[uuid.UUID]*motherContact),
+		facilities: make(map[uuid.UUID]*model.HealthcareFacility),
+	}
+}
+
+// mother resolves a mother's contact details
+func (r *visitResolver) mother(ctx context.Context, motherID uuid.UUID) *motherContact {
+	if contact, ok := r.mothers[motherID]; ok {
+		return contact
+	}
+
+	contact := &motherContact{name: "Mother " + motherID.String()[:8]}
+	if mother, err := r.s.motherRepo.GetByID(ctx, motherID); err == nil {
+		contact.home = mother.Location
+		if user, err := r.s.userRepo.GetByID(ctx, mother.UserID); err == nil {
+			contact.name = user.Name
+			contact.phone = user.Phone
+		}
+	}
+
+	r.mothers[motherID] = contact
+	return contact
+}
+
+// facility resolves a facility, returning nil if it can't be found
+func (r *visitResolver) facility(ctx context.Context, facilityID uuid.UUID) *model.HealthcareFacility {
+	if facility, ok := r.facilities[facilityID]; ok {
+		return facility
+	}
+
+	facility, err := r.s.facilityRepo.GetByID(ctx, facilityID)
+	if err != nil {
+		facility = nil
+	}
+
+	r.facilities[facilityID] = facility
+	return facility
+}
+
+// event converts a visit to a calendar event
+func (r *visitResolver) event(ctx context.Context, visit *model.Visit) ical.Event {
+	contact := r.mother(ctx, visit.MotherID)
+
+	subject := contact.name
+	if visit.ChildID != nil {
+		subject = "Baby of " + contact.name
+	}
+
+	event := ical.Event{
+		UID:          visit.ID.String() + "@mamacare.sl",
+		Sequence:     visit.Sequence,
+		Start:        visit.ScheduledTime,
+		End:          visit.ScheduledTime.Add(defaultVisitLength),
+		Summary:      fmt.Sprintf("%s - %s", visitTypeLabel(visit.VisitType), subject),
+		Status:       ical.StatusConfirmed,
+		Categories:   []string{"MamaCare", visitTypeLabel(visit.VisitType)},
+		Created:      visit.CreatedAt,
+		LastModified: visit.UpdatedAt,
+		Alarms: []ical.Alarm{
+			{Before: 24 * time.Hour, Description: "MamaCare visit tomorrow: " + subject},
+			{Before: time.Hour, Description: "MamaCare visit in 1 hour: " + subject},
+		},
+	}
+
+	if visit.Status == model.VisitStatusCancelled {
+		event.Status = ical.StatusCancelled
+		event.Summary = "Cancelled: " + event.Summary
+	}
+
+	lines := []string{
+		fmt.Sprintf("Visit type: %s", visitTypeLabel(visit.VisitType)),
+		fmt.Sprintf("Status: %s", visit.Status),
+		fmt.Sprintf("Mother: %s", contact.name),
+	}
+	if contact.phone != "" {
+		lines = append(lines, fmt.Sprintf("Phone: %s", contact.phone))
+	}
+	if visit.ChildID != nil {
+		lines = append(lines, fmt.Sprintf("Child ID: %s", visit.ChildID.String()))
+	}
+	if visit.DueBy != nil {
+		lines = append(lines, fmt.Sprintf("Complete by: %s", visit.DueBy.Format("Mon 02 Jan 2006 15:04")))
+	}
+	if visit.VisitNotes != "" {
+		lines = append(lines, "", visit.VisitNotes)
+	}
+	event.Description = strings.Join(lines, "\n")
+
+	// Home visits take place at the mother's home, others at the facility
+	if visit.IsHomeVisit() {
+		event.Location = "Home of " + contact.name
+		if contact.home != nil {
+			lat, lng := contact.home.Latitude, contact.home.Longitude
+			event.Latitude = &lat
+			event.Longitude = &lng
+		}
+	} else if facility := r.facility(ctx, visit.FacilityID); facility != nil {
+		event.Location = facility.Name
+		if facility.Address != "" {
+			event.Location += ", " + facility.Address
+		}
+		lat, lng := facility.Location.Latitude, facility.Location.Longitude
+		event.Latitude = &lat
+		event.Longitude = &lng
+	}
+
+	return event
+}
+
+// visitTypeLabel returns a readable label for a visit type
+func visitTypeLabel(visitType model.VisitType) string {
+	switch visitType {
+	case model.VisitTypeRoutine:
+		return "ANC visit"
+	case model.VisitTypeEmergency:
+		return "Emergency visit"
+	case model.VisitTypeFollowUp:
+		return "Follow-up visit"
+	case model.VisitTypePostnatal:
+		return "PNC contact"
+	default:
+		return string(visitType)
+	}
+}
@@ -0,0 +1,204 @@
+package groupanc
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mamacare/services/internal/domain/model"
+	"github.com/mamacare/services/pkg/errorx"
+	"github.com/mamacare/services/pkg/logger"
+)
+
+const (
+	// MinCohortGestationalWeeks is the earliest gestational age mothers join group ANC
+	MinCohortGestationalWeeks = 12
+	// MaxCohortGestationalWeeks is the latest gestational age mothers join group ANC
+	MaxCohortGestationalWeeks = 36
+	// DefaultCohortBandWeeks is the spread of gestational ages within one cohort
+	DefaultCohortBandWeeks = 4
+)
+
+// CohortMother is a mother suggested for a cohort
+type CohortMother struct {
+	MotherID            uuid.UUID       `json:"mother_id"`
+	Name                string          `json:"name"`
+	GestationalAgeWeeks int             `json:"gestational_age_weeks"`
+	ExpectedDelivery    time.Time       `json:"expected_delivery_date"`
+	RiskLevel           model.RiskLevel `json:"risk_level"`
+}
+
+// Cohort is a suggested group of mothers at a similar gestational age
+type Cohort struct {
+	MinGestationalWeeks int            `json:"min_gestational_weeks"`
+	MaxGestationalWeeks int            `json:"max_gestational_weeks"`
+	SuggestedTopicID    *uuid.UUID     `json:"suggested_topic_id,omitempty"`
+	SuggestedTopic      string         `json:"suggested_topic,omitempty"`
+	Mothers             []CohortMother `json:"mothers"`
+}
+
+// SuggestCohorts suggests cohorts of mothers in the facility's district for a session
+// at sessionTime. Mothers are found by district and due date and grouped into gestational age
+// bands, each split into cohorts no larger than capacity. Mothers already enrolled
+// in an upcoming session at the facility are left out.
+func (s *Service) SuggestCohorts(
+	ctx context.Context,
+	facilityID uuid.UUID,
+	sessionTime time.Time,
+	capacity int,
+	bandWeeks int,
+) ([]Cohort, error) {
+	if capacity < 2 || capacity > MaxCapacity {
+		return nil, errorx.Newf(errorx.BadRequest, "capacity must be between 2 and %d mothers", MaxCapacity)
+	}
+	if bandWeeks <= 0 {
+		bandWeeks = DefaultCohortBandWeeks
+	}
+
+	facility, err := s.facilityRepo.GetByID(ctx, facilityID)
+	if err != nil {
+		s.log.Error("Failed to find facility", logger.Fields{
+			"error":       err.Error(),
+			"facility_id": facilityID.String(),
+		})
+		return nil, errorx.Wrap(err, "failed to find facility")
+	}
+
+	if facility.District == "" {
+		return nil, errorx.New(errorx.BadRequest, "facility has no district to suggest cohorts from")
+	}
+
+	// Mothers in the facility's catchment district between the minimum and maximum
+	// gestational age on the session day
+	startDue := sessionTime.AddDate(0, 0, (40-MaxCohortGestationalWeeks)*7)
+	endDue := sessionTime.AddDate(0, 0, (40-MinCohortGestationalWeeks)*7)
+
+	mothers, err := s.motherRepo.FindByDistrictAndDueDate(ctx, facility.District, startDue, endDue)
+	if err != nil {
+		s.log.Error("Failed to find mothers by due date", logger.Fields{
+			"error":       err.Error(),
+			"facility_id": facilityID.String(),
+			"district":    facility.District,
+		})
+		return nil, errorx.Wrap(err, "failed to find mothers by due date")
+	}
+
+	// Names come from one lookup of the district's users
+	users, err := s.userRepo.FindByDistrict(ctx, facility.District)
+	if err != nil {
+		s.log.Error("Failed to find district users", logger.Fields{
+			"error":    err.Error(),
+			"district": facility.District,
+		})
+		return nil, errorx.Wrap(err, "failed to find district users")
+	}
+	names := make(map[uuid.UUID]string, len(users))
+	for _, user := range users {
+		names[user.ID] = user.Name
+	}
+
+	// Leave out mothers already on an upcoming roster at this facility
+	enrolled := make(map[uuid.UUID]bool)
+	upcoming, err := s.sessionRepo.GetByFacilityID(ctx, facilityID, time.Now(), endDue)
+	if err != nil {
+		s.log.Error("Failed to get upcoming group sessions", logger.Fields{
+			"error":       err.Error(),
+			"facility_id": facilityID.String(),
+		})
+		return nil, errorx.Wrap(err, "failed to get upcoming group sessions")
+	}
+	for _, session := range upcoming {
+		if session.Status != model.GroupSessionStatusScheduled {
+			continue
+		}
+		for _, member := range session.Members {
+			enrolled[member.MotherID] = true
+		}
+	}
+
+	bands := make(map[int][]CohortMother)
+	for _, mother := range mothers {
+		if mother.IsPostpartum() || enrolled[mother.ID] {
+			continue
+		}
+
+		name := names[mother.UserID]
+		if name == "" {
+			name = "Mother " + mother.ID.String()[:8]
+		}
+
+		weeks := mother.GetWeeksPregnant(sessionTime)
+		if weeks < MinCohortGestationalWeeks || weeks > MaxCohortGestationalWeeks {
+			continue
+		}
+
+		band := MinCohortGestationalWeeks + (weeks-MinCohortGestationalWeeks)/bandWeeks*bandWeeks
+		bands[band] = append(bands[band], CohortMother{
+			MotherID:            mother.ID,
+			Name:                name,
+			GestationalAgeWeeks: weeks,
+			ExpectedDelivery:    mother.ExpectedDeliveryDate,
+			RiskLevel:           mother.RiskLevel,
+		})
+	}
+
+	bandStarts := make([]int, 0, len(bands))
+	for band := range bands {
+		bandStarts = append(bandStarts, band)
+	}
+	sort.Ints(bandStarts)
+
+	var cohorts []Cohort
+	for _, band := range bandStarts {
+		members := bands[band]
+		sort.Slice(members, func(i, j int) bool {
+			return members[i].ExpectedDelivery.Before(members[j].ExpectedDelivery)
+		})
+
+		topicID, topic := s.suggestTopic(ctx, band)
+
+		// Split the band into cohorts no larger than the session capacity
+		for start := 0; start < len(members); start += capacity {
+			end := start + capacity
+			if end > len(members) {
+				end = len(members)
+			}
+			cohorts = append(cohorts, Cohort{
+				MinGestationalWeeks: band,
+				MaxGestationalWeeks: band + bandWeeks - 1,
+				SuggestedTopicID:    topicID,
+				SuggestedTopic:      topic,
+				Mothers:             members[start:end],
+			})
+		}
+	}
+
+	s.log.Info("Suggested group ANC cohorts", logger.Fields{
+		"facility_id":  facilityID.String(),
+		"session_time": sessionTime.Format(time.RFC3339),
+		"candidates":   len(mothers),
+		"cohorts":      len(cohorts),
+	})
+
+	return cohorts, nil
+}
+
+// suggestTopic picks education content for the trimester a cohort's band starts in
+func (s *Service) suggestTopic(ctx context.Context, bandStartWeeks int) (*uuid.UUID, string) {
+	trimester := 3
+	switch {
+	case bandStartWeeks < 13:
+		trimester = 1
+	case bandStartWeeks < 27:
+		trimester = 2
+	}
+
+	content, err := s.contentRepo.FindByTrimester(ctx, trimester)
+	if err != nil || len(content) == 0 {
+		return nil, ""
+	}
+
+	id := content[0].ID
+	return &id, content[0].Title
+}
@@ -0,0 +1,572 @@
+package groupanc
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mamacare/services/internal/domain/model"
+	"github.com/mamacare/services/internal/domain/repository"
+	"github.com/mamacare/services/pkg/errorx"
+	"github.com/mamacare/services/pkg/logger"
+)
+
+// DefaultDurationMinutes is the length of a group ANC session when none is given
+const DefaultDurationMinutes = 120
+
+// MaxCapacity is the largest group that can be run as a single session
+const MaxCapacity = 20
+
+// SessionInput contains the details for creating a group session
+type SessionInput struct {
+	FacilityID      uuid.UUID
+	FacilitatorID   uuid.UUID
+	Title           string
+	TopicContentID  *uuid.UUID
+	ScheduledTime   time.Time
+	DurationMinutes int
+	Capacity        int
+	Notes           string
+	MotherIDs       []uuid.UUID // optional initial roster, e.g. from a suggested cohort
+}
+
+// AttendanceInput records whether a mother on the roster attended
+type AttendanceInput struct {
+	MotherID uuid.UUID
+	Attended bool
+}
+
+// EnrollmentResult is the result of adding mothers to a session roster
+type EnrollmentResult struct {
+	Session  *model.GroupSession `json:"session"`
+	Enrolled []uuid.UUID         `json:"enrolled"`
+	Rejected map[string]string   `json:"rejected,omitempty"` // mother ID to reason
+}
+
+// AttendanceResult is the result of recording attendance for a session
+type AttendanceResult struct {
+	Session     *model.GroupSession `json:"session"`
+	ANCContacts []*model.Visit      `json:"anc_contacts"`
+}
+
+// Service provides group antenatal care session scheduling
+type Service struct {
+	sessionRepo  repository.GroupSessionRepository
+	visitRepo    repository.VisitRepository
+	motherRepo   repository.MotherRepository
+	facilityRepo repository.FacilityRepository
+	userRepo     repository.UserRepository
+	contentRepo  repository.EducationContentRepository
+	transactor   repository.Transactor
+	log          logger.Logger
+}
+
+// NewService creates a new group ANC service
+func NewService(
+	sessionRepo repository.GroupSessionRepository,
+	visitRepo repository.VisitRepository,
+	motherRepo repository.MotherRepository,
+	facilityRepo repository.FacilityRepository,
+	userRepo repository.UserRepository,
+	contentRepo repository.EducationContentRepository,
+	transactor repository.Transactor,
+	log logger.Logger,
+) *Service {
+	return &Service{
+		sessionRepo:  sessionRepo,
+		visitRepo:    visitRepo,
+		motherRepo:   motherRepo,
+		facilityRepo: facilityRepo,
+		userRepo:     userRepo,
+		contentRepo:  contentRepo,
+		transactor:   transactor,
+		log:          log,
+	}
+}
+
+// CreateSession schedules a group ANC session, optionally enrolling an initial roster
+func (s *Service) CreateSession(
+	ctx context.Context,
+	input *SessionInput,
+) (*EnrollmentResult, error) {
+	if err := validateSessionInput(input); err != nil {
+		return nil, err
+	}
+
+	if _, err := s.facilityRepo.GetByID(ctx, input.FacilityID); err != nil {
+		s.log.Error("Failed to find facility", logger.Fields{
+			"error":       err.Error(),
+			"facility_id": input.FacilityID.String(),
+		})
+		return nil, errorx.Wrap(err, "failed to find facility")
+	}
+
+	// The facilitator must be a health worker
+	facilitator, err := s.userRepo.GetByID(ctx, input.FacilitatorID)
+	if err != nil {
+		s.log.Error("Failed to find facilitator", logger.Fields{
+			"error":          err.Error(),
+			"facilitator_id": input.FacilitatorID.String(),
+		})
+		return nil, errorx.Wrap(err, "failed to find facilitator")
+	}
+	if !facilitator.IsHealthcareProvider() {
+		return nil, errorx.New(errorx.BadRequest, "facilitator must be a CHW or clinician")
+	}
+
+	duration := input.DurationMinutes
+	if duration == 0 {
+		duration = DefaultDurationMinutes
+	}
+
+	session := model.NewGroupSession(
+		uuid.New(),
+		input.FacilityID,
+		input.FacilitatorID,
+		input.Title,
+		input.ScheduledTime,
+		duration,
+		input.Capacity,
+	).WithNotes(input.Notes)
+
+	if input.TopicContentID != nil {
+		content, err := s.contentRepo.FindByID(ctx, *input.TopicContentID)
+		if err != nil {
+			s.log.Error("Failed to find topic content", logger.Fields{
+				"error":      err.Error(),
+				"content_id": input.TopicContentID.String(),
+			})
+			return nil, errorx.Wrap(err, "failed to find topic content")
+		}
+		session.WithTopic(content.ID)
+		if session.Title == "" {
+			session.Title = content.Title
+		}
+	}
+
+	if session.Title == "" {
+		session.Title = "Group ANC session"
+	}
+
+	result := &EnrollmentResult{
+		Session:  session,
+		Enrolled: []uuid.UUID{},
+		Rejected: map[string]string{},
+	}
+	s.enroll(ctx, session, input.MotherIDs, result)
+
+	if err := s.sessionRepo.Create(ctx, session); err != nil {
+		s.log.Error("Failed to create group session", logger.Fields{
+			"error":       err.Error(),
+			"facility_id": input.FacilityID.String(),
+		})
+		return nil, errorx.Wrap(err, "failed to create group session")
+	}
+
+	s.log.Info("Group session created successfully", logger.Fields{
+		"session_id":     session.ID.String(),
+		"facility_id":    session.FacilityID.String(),
+		"facilitator_id": session.FacilitatorID.String(),
+		"scheduled_time": session.ScheduledTime.Format(time.RFC3339),
+		"capacity":       session.Capacity,
+		"enrolled":       len(result.Enrolled),
+		"rejected":       len(result.Rejected),
+	})
+
+	return result, nil
+}
+
+// EnrollMothers adds mothers to the roster of a scheduled session
+func (s *Service) EnrollMothers(
+	ctx context.Context,
+	sessionID uuid.UUID,
+	motherIDs []uuid.UUID,
+) (*EnrollmentResult, error) {
+	var result *EnrollmentResult
+	err := s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		result, err = s.enrollMothers(ctx, sessionID, motherIDs)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.log.Info("Mothers enrolled in group session", logger.Fields{
+		"session_id": sessionID.String(),
+		"enrolled":   len(result.Enrolled),
+		"rejected":   len(result.Rejected),
+		"seats_left": result.Session.SeatsAvailable(),
+	})
+
+	return result, nil
+}
+
+// enrollMothers adds mothers to the roster within a transaction, with the session locked
+// so concurrent enrolments cannot fill it beyond capacity
+func (s *Service) enrollMothers(
+	ctx context.Context,
+	sessionID uuid.UUID,
+	motherIDs []uuid.UUID,
+) (*EnrollmentResult, error) {
+	session, err := s.getScheduledSession(ctx, sessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &EnrollmentResult{
+		Session:  session,
+		Enrolled: []uuid.UUID{},
+		Rejected: map[string]string{},
+	}
+	s.enroll(ctx, session, motherIDs, result)
+
+	if len(result.Enrolled) > 0 {
+		if err := s.sessionRepo.Update(ctx, session); err != nil {
+			s.log.Error("Failed to update group session roster", logger.Fields{
+				"error":      err.Error(),
+				"session_id": sessionID.String(),
+			})
+			return nil, errorx.Wrap(err, "failed to update group session roster")
+		}
+	}
+
+	return result, nil
+}
+
+// RemoveMother removes a mother from the roster of a scheduled session
+func (s *Service) RemoveMother(
+	ctx context.Context,
+	sessionID uuid.UUID,
+	motherID uuid.UUID,
+) (*model.GroupSession, error) {
+	var session *model.GroupSession
+	err := s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		session, err = s.removeMother(ctx, sessionID, motherID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return session, nil
+}
+
+// removeMother removes a mother from the roster within a transaction
+func (s *Service) removeMother(
+	ctx context.Context,
+	sessionID uuid.UUID,
+	motherID uuid.UUID,
+) (*model.GroupSession, error) {
+	session, err := s.getScheduledSession(ctx, sessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !session.RemoveMember(motherID) {
+		return nil, errorx.New(errorx.NotFound, "mother is not enrolled in this session")
+	}
+
+	if err := s.sessionRepo.Update(ctx, session); err != nil {
+		s.log.Error("Failed to update group session roster", logger.Fields{
+			"error":      err.Error(),
+			"session_id": sessionID.String(),
+			"mother_id":  motherID.String(),
+		})
+		return nil, errorx.Wrap(err, "failed to update group session roster")
+	}
+
+	return session, nil
+}
+
+// RecordAttendance records who attended a session. Each mother who attended gets a
+// completed ANC visit so the session counts towards her ANC contacts. The visits and the
+// register are saved in one transaction, so a failure part way leaves nothing behind.
+func (s *Service) RecordAttendance(
+	ctx context.Context,
+	sessionID uuid.UUID,
+	attendance []AttendanceInput,
+) (*AttendanceResult, error) {
+	var result *AttendanceResult
+	err := s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		result, err = s.recordAttendance(ctx, sessionID, attendance)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.log.Info("Group session attendance recorded", logger.Fields{
+		"session_id":   sessionID.String(),
+		"roster":       len(result.Session.Members),
+		"attended":     result.Session.AttendedCount(),
+		"anc_contacts": len(result.ANCContacts),
+	})
+
+	return result, nil
+}
+
+// recordAttendance records attendance within a transaction. The session is locked so
+// concurrent submissions of the register cannot record the same contact twice.
+func (s *Service) recordAttendance(
+	ctx context.Context,
+	sessionID uuid.UUID,
+	attendance []AttendanceInput,
+) (*AttendanceResult, error) {
+	session, err := s.sessionRepo.GetByIDForUpdate(ctx, sessionID)
+	if err != nil {
+		s.log.Error("Failed to find group session", logger.Fields{
+			"error":      err.Error(),
+			"session_id": sessionID.String(),
+		})
+		return nil, errorx.Wrap(err, "failed to find group session")
+	}
+
+	if session.Status == model.GroupSessionStatusCancelled {
+		return nil, errorx.New(errorx.BadRequest, "cannot record attendance for a cancelled session")
+	}
+	if session.ScheduledTime.After(time.Now()) {
+		return nil, errorx.New(errorx.BadRequest, "cannot record attendance before the session has started")
+	}
+
+	// Validate the whole register before creating any visits
+	for _, entry := range attendance {
+		if session.GetMember(entry.MotherID) == nil {
+			return nil, errorx.Newf(errorx.BadRequest, "mother %s is not enrolled in this session", entry.MotherID)
+		}
+	}
+
+	var contacts []*model.Visit
+	for _, entry := range attendance {
+		member := session.GetMember(entry.MotherID)
+
+		if !entry.Attended {
+			// Attendance can be corrected, but an ANC contact that was recorded stays on her record
+			if member.VisitID == nil {
+				member.Attendance = model.AttendanceStatusAbsent
+			}
+			continue
+		}
+
+		member.Attendance = model.AttendanceStatusAttended
+		if member.VisitID != nil {
+			continue
+		}
+
+		visit := model.NewVisit(uuid.New(), member.MotherID, session.FacilityID, session.ScheduledTime, model.VisitTypeRoutine).
+			WithClinician(session.FacilitatorID)
+		visit.CheckIn()
+		visit.Complete(groupContactNotes(session))
+
+		if err := s.visitRepo.Create(ctx, visit); err != nil {
+			s.log.Error("Failed to record group ANC contact", logger.Fields{
+				"error":      err.Error(),
+				"session_id": sessionID.String(),
+				"mother_id":  member.MotherID.String(),
+			})
+			return nil, errorx.Wrap(err, "failed to record group ANC contact")
+		}
+
+		visitID := visit.ID
+		member.VisitID = &visitID
+		contacts = append(contacts, visit)
+	}
+
+	session.Complete()
+	if err := s.sessionRepo.Update(ctx, session); err != nil {
+		s.log.Error("Failed to update group session attendance", logger.Fields{
+			"error":      err.Error(),
+			"session_id": sessionID.String(),
+		})
+		return nil, errorx.Wrap(err, "failed to update group session attendance")
+	}
+
+	return &AttendanceResult{
+		Session:     session,
+		ANCContacts: contacts,
+	}, nil
+}
+
+// CancelSession cancels a scheduled session
+func (s *Service) CancelSession(
+	ctx context.Context,
+	sessionID uuid.UUID,
+	reason string,
+) (*model.GroupSession, error) {
+	var session *model.GroupSession
+	err := s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		session, err = s.cancelSession(ctx, sessionID, reason)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.log.Info("Group session cancelled", logger.Fields{
+		"session_id": sessionID.String(),
+		"roster":     len(session.Members),
+		"reason":     reason,
+	})
+
+	return session, nil
+}
+
+// cancelSession cancels a session within a transaction
+func (s *Service) cancelSession(
+	ctx context.Context,
+	sessionID uuid.UUID,
+	reason string,
+) (*model.GroupSession, error) {
+	session, err := s.getScheduledSession(ctx, sessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	session.Cancel()
+	if reason != "" {
+		session.Notes = fmt.Sprintf("%s\nCancellation reason: %s", session.Notes, reason)
+	}
+
+	if err := s.sessionRepo.Update(ctx, session); err != nil {
+		s.log.Error("Failed to cancel group session", logger.Fields{
+			"error":      err.Error(),
+			"session_id": sessionID.String(),
+		})
+		return nil, errorx.Wrap(err, "failed to cancel group session")
+	}
+
+	return session, nil
+}
+
+// GetSession retrieves a group session with its roster
+func (s *Service) GetSession(
+	ctx context.Context,
+	sessionID uuid.UUID,
+) (*model.GroupSession, error) {
+	session, err := s.sessionRepo.GetByID(ctx, sessionID)
+	if err != nil {
+		s.log.Error("Failed to find group session", logger.Fields{
+			"error":      err.Error(),
+			"session_id": sessionID.String(),
+		})
+		return nil, errorx.Wrap(err, "failed to find group session")
+	}
+
+	return session, nil
+}
+
+// GetFacilitySessions retrieves the group sessions at a facility in a date range
+func (s *Service) GetFacilitySessions(
+	ctx context.Context,
+	facilityID uuid.UUID,
+	startDate, endDate time.Time,
+) ([]*model.GroupSession, error) {
+	sessions, err := s.sessionRepo.GetByFacilityID(ctx, facilityID, startDate, endDate)
+	if err != nil {
+		s.log.Error("Failed to get facility group sessions", logger.Fields{
+			"error":       err.Error(),
+			"facility_id": facilityID.String(),
+		})
+		return nil, errorx.Wrap(err, "failed to get facility group sessions")
+	}
+
+	return sessions, nil
+}
+
+// GetMotherSessions retrieves the group sessions a mother is enrolled in
+func (s *Service) GetMotherSessions(
+	ctx context.Context,
+	motherID uuid.UUID,
+) ([]*model.GroupSession, error) {
+	sessions, err := s.sessionRepo.GetByMotherID(ctx, motherID)
+	if err != nil {
+		s.log.Error("Failed to get mother group sessions", logger.Fields{
+			"error":     err.Error(),
+			"mother_id": motherID.String(),
+		})
+		return nil, errorx.Wrap(err, "failed to get mother group sessions")
+	}
+
+	return sessions, nil
+}
+
+// enroll adds eligible mothers to the roster, recording why the others were rejected
+func (s *Service) enroll(
+	ctx context.Context,
+	session *model.GroupSession,
+	motherIDs []uuid.UUID,
+	result *EnrollmentResult,
+) {
+	for _, motherID := range motherIDs {
+		if session.GetMember(motherID) != nil {
+			result.Rejected[motherID.String()] = "already enrolled"
+			continue
+		}
+		if session.IsFull() {
+			result.Rejected[motherID.String()] = "session is full"
+			continue
+		}
+
+		mother, err := s.motherRepo.GetByID(ctx, motherID)
+		if err != nil {
+			result.Rejected[motherID.String()] = "mother not found"
+			continue
+		}
+		if mother.IsPostpartum() {
+			result.Rejected[motherID.String()] = "mother has already delivered"
+			continue
+		}
+		if mother.ExpectedDeliveryDate.Before(session.ScheduledTime) {
+			result.Rejected[motherID.String()] = "mother is due before the session"
+			continue
+		}
+
+		session.AddMember(motherID)
+		result.Enrolled = append(result.Enrolled, motherID)
+	}
+}
+
+// getScheduledSession locks a session that can still be changed. It must be called
+// within a transaction so the lock is held until the change is saved.
+func (s *Service) getScheduledSession(ctx context.Context, sessionID uuid.UUID) (*model.GroupSession, error) {
+	session, err := s.sessionRepo.GetByIDForUpdate(ctx, sessionID)
+	if err != nil {
+		s.log.Error("Failed to find group session", logger.Fields{
+			"error":      err.Error(),
+			"session_id": sessionID.String(),
+		})
+		return nil, errorx.Wrap(err, "failed to find group session")
+	}
+
+	if session.Status != model.GroupSessionStatusScheduled {
+		return nil, errorx.Newf(errorx.BadRequest, "cannot change a group session with status %s", session.Status)
+	}
+
+	return session, nil
+}
+
+// validateSessionInput checks the session details before anything is saved
+func validateSessionInput(input *SessionInput) error {
+	if input == nil {
+		return errorx.New(errorx.BadRequest, "session details are required")
+	}
+	if input.ScheduledTime.Before(time.Now()) {
+		return errorx.New(errorx.BadRequest, "scheduled time must be in the future")
+	}
+	if input.Capacity < 2 || input.Capacity > MaxCapacity {
+		return errorx.Newf(errorx.BadRequest, "capacity must be between 2 and %d mothers", MaxCapacity)
+	}
+	if input.DurationMinutes < 0 || input.DurationMinutes > 8*60 {
+		return errorx.New(errorx.BadRequest, "duration must be at most 8 hours")
+	}
+	return nil
+}
+
+// groupContactNotes describes a group session on the ANC visit it produced
+func groupContactNotes(session *model.GroupSession) string {
+	return fmt.Sprintf("Group ANC session: %s (session %s)", session.Title, session.ID.String())
+}
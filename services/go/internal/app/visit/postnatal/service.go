@@ -0,0 +1,402 @@
+package postnatal
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mamacare/services/internal/domain/model"
+	"github.com/mamacare/services/internal/domain/repository"
+	"github.com/mamacare/services/pkg/errorx"
+	"github.com/mamacare/services/pkg/logger"
+)
+
+// PNCContact describes one WHO postnatal care contact
+type PNCContact struct {
+	Number      int    `json:"number"`
+	Description string `json:"description"`
+	// StartOffset and EndOffset bound the contact window relative to the delivery time
+	StartOffset time.Duration `json:"start_offset"`
+	EndOffset   time.Duration `json:"end_offset"`
+	// ScheduleHour is the preferred hour of day for the visit; zero schedules at the window start
+	ScheduleHour int `json:"schedule_hour"`
+}
+
+// WHOContacts is the WHO recommended PNC schedule for mother and newborn:
+// within 24 hours, day 3, days 7-14 and six weeks after birth
+var WHOContacts = []PNCContact{
+	{1, "First postnatal check within 24 hours of birth", 0, 24 * time.Hour, 0},
+	{2, "Postnatal check on day 3", 48 * time.Hour, 72 * time.Hour, 10},
+	{3, "Postnatal check between days 7 and 14", 7 * 24 * time.Hour, 14 * 24 * time.Hour, 10},
+	{4, "Six-week postnatal check", 42 * 24 * time.Hour, 49 * 24 * time.Hour, 10},
+}
+
+// DeliveryOutcomeInput contains the details captured when recording a birth
+type DeliveryOutcomeInput struct {
+	MotherID              uuid.UUID
+	RecordedByID          uuid.UUID
+	DeliveryDate          time.Time
+	Place                 model.DeliveryPlace
+	DeliveryFacilityID    *uuid.UUID
+	Mode                  model.DeliveryMode
+	GestationalAgeWeeks   int
+	MaternalOutcome       model.MaternalOutcome
+	MaternalComplications []string
+	Newborns              []model.NewbornOutcome
+	AttendedByID          *uuid.UUID
+	Notes                 string
+	// PNCFacilityID is the facility responsible for postnatal follow-up
+	PNCFacilityID uuid.UUID
+}
+
+// DeliveryRecordResult is the result of recording a delivery
+type DeliveryRecordResult struct {
+	Outcome      *model.DeliveryOutcome `json:"outcome"`
+	Mother       *model.Mother          `json:"mother"`
+	PNCVisits    []*model.Visit         `json:"pnc_visits"`
+	MissedVisits int                    `json:"missed_visits"` // contacts created after their window had closed
+}
+
+// Service provides delivery outcome capture and postnatal care scheduling
+type Service struct {
+	deliveryRepo repository.DeliveryOutcomeRepository
+	visitRepo    repository.VisitRepository
+	motherRepo   repository.MotherRepository
+	facilityRepo repository.FacilityRepository
+	transactor   repository.Transactor
+	log          logger.Logger
+}
+
+// NewService creates a new postnatal care service
+func NewService(
+	deliveryRepo repository.DeliveryOutcomeRepository,
+	visitRepo repository.VisitRepository,
+	motherRepo repository.MotherRepository,
+	facilityRepo repository.FacilityRepository,
+	transactor repository.Transactor,
+	log logger.Logger,
+) *Service {
+	return &Service{
+		deliveryRepo: deliveryRepo,
+		visitRepo:    visitRepo,
+		motherRepo:   motherRepo,
+		facilityRepo: facilityRepo,
+		transactor:   transactor,
+		log:          log,
+	}
+}
+
+// RecordDeliveryOutcome records a birth, moves the mother to postpartum and schedules PNC
+// contacts. All three are saved in one transaction, so a failure part way leaves nothing
+// behind and the delivery can be recorded again.
+func (s *Service) RecordDeliveryOutcome(
+	ctx context.Context,
+	input *DeliveryOutcomeInput,
+) (*DeliveryRecordResult, error) {
+	if err := validateDeliveryInput(input); err != nil {
+		return nil, err
+	}
+
+	var result *DeliveryRecordResult
+	err := s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		result, err = s.recordDeliveryOutcome(ctx, input)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.log.Info("Delivery outcome recorded successfully", logger.Fields{
+		"outcome_id":       result.Outcome.ID.String(),
+		"mother_id":        result.Mother.ID.String(),
+		"delivery_date":    input.DeliveryDate.Format(time.RFC3339),
+		"place":            string(input.Place),
+		"mode":             string(input.Mode),
+		"maternal_outcome": string(input.MaternalOutcome),
+		"live_births":      len(result.Outcome.LiveBirths()),
+		"pnc_visits":       len(result.PNCVisits),
+		"missed_visits":    result.MissedVisits,
+	})
+
+	return result, nil
+}
+
+// recordDeliveryOutcome records a delivery within a transaction
+func (s *Service) recordDeliveryOutcome(
+	ctx context.Context,
+	input *DeliveryOutcomeInput,
+) (*DeliveryRecordResult, error) {
+	mother, err := s.motherRepo.GetByID(ctx, input.MotherID)
+	if err != nil {
+		s.log.Error("Failed to find mother", logger.Fields{
+			"error":     err.Error(),
+			"mother_id": input.MotherID.String(),
+		})
+		return nil, errorx.Wrap(err, "failed to find mother")
+	}
+
+	if _, err := s.facilityRepo.GetByID(ctx, input.PNCFacilityID); err != nil {
+		s.log.Error("Failed to find facility", logger.Fields{
+			"error":       err.Error(),
+			"facility_id": input.PNCFacilityID.String(),
+		})
+		return nil, errorx.Wrap(err, "failed to find facility")
+	}
+
+	// Guard against recording the same birth twice
+	existing, err := s.deliveryRepo.GetByMotherID(ctx, mother.ID)
+	if err != nil {
+		s.log.Error("Failed to get existing delivery outcomes", logger.Fields{
+			"error":     err.Error(),
+			"mother_id": mother.ID.String(),
+		})
+		return nil, errorx.Wrap(err, "failed to get existing delivery outcomes")
+	}
+	for _, previous := range existing {
+		if absDuration(previous.DeliveryDate.Sub(input.DeliveryDate)) < 30*24*time.Hour {
+			return nil, errorx.New(errorx.AlreadyExists, "a delivery has already been recorded for this mother around this date")
+		}
+	}
+
+	outcome := model.NewDeliveryOutcome(
+		uuid.New(),
+		mother.ID,
+		input.RecordedByID,
+		input.DeliveryDate,
+		input.Place,
+		input.Mode,
+		input.MaternalOutcome,
+	).WithGestationalAge(input.GestationalAgeWeeks).WithNotes(input.Notes)
+
+	if input.DeliveryFacilityID != nil {
+		outcome.WithFacility(*input.DeliveryFacilityID)
+	}
+	if input.AttendedByID != nil {
+		outcome.WithAttendant(*input.AttendedByID)
+	}
+	if len(input.MaternalComplications) > 0 {
+		outcome.WithComplications(input.MaternalComplications)
+	}
+	for _, newborn := range input.Newborns {
+		outcome.AddNewborn(newborn)
+	}
+
+	if err := s.deliveryRepo.Create(ctx, outcome); err != nil {
+		s.log.Error("Failed to create delivery outcome", logger.Fields{
+			"error":     err.Error(),
+			"mother_id": mother.ID.String(),
+		})
+		return nil, errorx.Wrap(err, "failed to create delivery outcome")
+	}
+
+	// Switch the mother to postpartum
+	mother.RecordDelivery(input.DeliveryDate)
+	if input.Mode == model.DeliveryModeCaesarean {
+		mother.PregnancyHistory.PreviousCaesareans++
+	}
+	if err := s.motherRepo.Save(ctx, mother); err != nil {
+		s.log.Error("Failed to update mother pregnancy stage", logger.Fields{
+			"error":     err.Error(),
+			"mother_id": mother.ID.String(),
+		})
+		return nil, errorx.Wrap(err, "failed to update mother")
+	}
+
+	visits, missed, err := s.schedulePNCContacts(ctx, outcome, input.PNCFacilityID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &DeliveryRecordResult{
+		Outcome:      outcome,
+		Mother:       mother,
+		PNCVisits:    visits,
+		MissedVisits: missed,
+	}, nil
+}
+
+// schedulePNCContacts creates the WHO PNC contacts for the mother and each live newborn.
+// Contacts whose window closed before the delivery was recorded keep that window, so
+// they show as missed rather than disappearing from the schedule.
+func (s *Service) schedulePNCContacts(
+	ctx context.Context,
+	outcome *model.DeliveryOutcome,
+	facilityID uuid.UUID,
+) ([]*model.Visit, int, error) {
+	// Avoid duplicating contacts that already exist after this delivery
+	options := repository.NewVisitQueryOptions().
+		WithType(model.VisitTypePostnatal).
+		WithDateRange(outcome.DeliveryDate, outcome.DeliveryDate.Add(WHOContacts[len(WHOContacts)-1].EndOffset))
+
+	existingVisits, err := s.visitRepo.GetByMotherID(ctx, outcome.MotherID, options)
+	if err != nil {
+		s.log.Error("Failed to get existing postnatal visits", logger.Fields{
+			"error":     err.Error(),
+			"mother_id": outcome.MotherID.String(),
+		})
+		return nil, 0, errorx.Wrap(err, "failed to get existing postnatal visits")
+	}
+
+	existing := make(map[string]bool, len(existingVisits))
+	for _, visit := range existingVisits {
+		existing[contactKey(visit.ChildID, visit.VisitNotes)] = true
+	}
+
+	// Subjects are the mother (nil child) and every live newborn
+	var subjects []*uuid.UUID
+	if outcome.IsMotherAlive() {
+		subjects = append(subjects, nil)
+	}
+	for _, newborn := range outcome.LiveBirths() {
+		id := newborn.ID
+		subjects = append(subjects, &id)
+	}
+
+	now := time.Now()
+	var visits []*model.Visit
+	missed := 0
+
+	for _, contact := range WHOContacts {
+		scheduledTime, dueBy := contactWindow(outcome.DeliveryDate, contact)
+
+		windowClosed := dueBy.Before(now)
+		if !windowClosed && scheduledTime.Before(now) {
+			scheduledTime = now
+		}
+
+		for _, childID := range subjects {
+			notes := contactNotes(contact, childID != nil)
+			if existing[contactKey(childID, notes)] {
+				continue
+			}
+
+			visit := model.NewVisit(uuid.New(), outcome.MotherID, facilityID, scheduledTime, model.VisitTypePostnatal).
+				WithDueBy(dueBy).
+				WithNotes(notes)
+			if childID != nil {
+				visit.WithChild(*childID)
+			}
+
+			if err := s.visitRepo.Create(ctx, visit); err != nil {
+				s.log.Error("Failed to create postnatal visit", logger.Fields{
+					"error":       err.Error(),
+					"mother_id":   outcome.MotherID.String(),
+					"contact":     contact.Number,
+					"for_newborn": childID != nil,
+				})
+				return nil, missed, errorx.Wrap(err, "failed to create postnatal visit")
+			}
+
+			visits = append(visits, visit)
+			if windowClosed {
+				missed++
+			}
+		}
+	}
+
+	return visits, missed, nil
+}
+
+// GetPostnatalSchedule retrieves the PNC contacts for a mother and her newborns
+func (s *Service) GetPostnatalSchedule(
+	ctx context.Context,
+	motherID uuid.UUID,
+) ([]*model.Visit, error) {
+	options := repository.NewVisitQueryOptions().
+		WithType(model.VisitTypePostnatal).
+		WithOrder("scheduled_time", "ASC")
+
+	visits, err := s.visitRepo.GetByMotherID(ctx, motherID, options)
+	if err != nil {
+		s.log.Error("Failed to get postnatal visits", logger.Fields{
+			"error":     err.Error(),
+			"mother_id": motherID.String(),
+		})
+		return nil, errorx.Wrap(err, "failed to get postnatal visits")
+	}
+
+	return visits, nil
+}
+
+// GetDeliveryOutcomes retrieves the recorded deliveries for a mother
+func (s *Service) GetDeliveryOutcomes(
+	ctx context.Context,
+	motherID uuid.UUID,
+) ([]*model.DeliveryOutcome, error) {
+	outcomes, err := s.deliveryRepo.GetByMotherID(ctx, motherID)
+	if err != nil {
+		s.log.Error("Failed to get delivery outcomes", logger.Fields{
+			"error":     err.Error(),
+			"mother_id": motherID.String(),
+		})
+		return nil, errorx.Wrap(err, "failed to get delivery outcomes")
+	}
+
+	return outcomes, nil
+}
+
+// validateDeliveryInput checks the delivery details before anything is saved
+func validateDeliveryInput(input *DeliveryOutcomeInput) error {
+	if input == nil {
+		return errorx.New(errorx.BadRequest, "delivery details are required")
+	}
+	if input.DeliveryDate.IsZero() || input.DeliveryDate.After(time.Now()) {
+		return errorx.New(errorx.BadRequest, "delivery date must be in the past")
+	}
+	if input.Place == model.DeliveryPlaceFacility && input.DeliveryFacilityID == nil {
+		return errorx.New(errorx.BadRequest, "facility deliveries must include the delivery facility")
+	}
+	if input.GestationalAgeWeeks != 0 && (input.GestationalAgeWeeks < 20 || input.GestationalAgeWeeks > 45) {
+		return errorx.New(errorx.BadRequest, "gestational age at delivery must be between 20 and 45 weeks")
+	}
+	if len(input.Newborns) == 0 {
+		return errorx.New(errorx.BadRequest, "at least one newborn outcome is required")
+	}
+	return nil
+}
+
+// contactWindow returns the scheduled time and deadline for a contact
+func contactWindow(deliveryDate time.Time, contact PNCContact) (time.Time, time.Time) {
+	start := deliveryDate.Add(contact.StartOffset)
+	dueBy := deliveryDate.Add(contact.EndOffset)
+
+	if contact.ScheduleHour > 0 {
+		day := start
+		if start.Hour() >= contact.ScheduleHour {
+			day = start.AddDate(0, 0, 1)
+		}
+		scheduled := time.Date(day.Year(), day.Month(), day.Day(), contact.ScheduleHour, 0, 0, 0, start.Location())
+		if scheduled.Before(dueBy) {
+			start = scheduled
+		}
+	}
+
+	return start, dueBy
+}
+
+// contactNotes describes a contact for the visit notes
+func contactNotes(contact PNCContact, forNewborn bool) string {
+	subject := "mother"
+	if forNewborn {
+		subject = "newborn"
+	}
+	return fmt.Sprintf("PNC contact %d (%s): %s", contact.Number, subject, contact.Description)
+}
+
+// contactKey identifies a contact for a subject so existing visits aren't duplicated
+func contactKey(childID *uuid.UUID, notes string) string {
+	if childID == nil {
+		return "mother|" + notes
+	}
+	return childID.String() + "|" + notes
+}
+
+// absDuration returns the absolute value of a duration
+func absDuration(d time.Duration) time.Duration {
+	if d < 0 {
+		return -d
+	}
+	return d
+}
@@ -0,0 +1,189 @@
+package report
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mamacare/services/internal/app/health/growth"
+	"github.com/mamacare/services/internal/domain/model"
+	"github.com/mamacare/services/internal/domain/repository"
+	"github.com/mamacare/services/pkg/errorx"
+	"github.com/mamacare/services/pkg/logger"
+)
+
+// ANCCard is the printable antenatal card for a mother
+type ANCCard struct {
+	MotherID            uuid.UUID              `json:"mother_id"`
+	MotherName          string                 `json:"mother_name"`
+	MotherContact       string                 `json:"mother_contact"`
+	District            string                 `json:"district"`
+	BloodType           model.BloodType        `json:"blood_type"`
+	RiskLevel           model.RiskLevel        `json:"risk_level"`
+	PregnancyStage      model.PregnancyStage   `json:"pregnancy_stage"`
+	ExpectedDelivery    time.Time              `json:"expected_delivery_date"`
+	DeliveryDate        *time.Time             `json:"delivery_date,omitempty"`
+	GestationalAgeWeeks int                    `json:"gestational_age_weeks,omitempty"`
+	Gravida             int                    `json:"gravida"`
+	Para                int                    `json:"para"`
+	PregnancyHistory    model.PregnancyHistory `json:"pregnancy_history"`
+	HealthConditions    []string               `json:"health_conditions"`
+	Visits              []ANCCardVisit         `json:"visits"`
+	Vitals              []ANCCardVitals        `json:"vitals"`
+	FetalGrowth         *growth.Assessment     `json:"fetal_growth,omitempty"`
+	GeneratedAt         time.Time              `json:"generated_at"`
+}
+
+// ANCCardVisit is a visit row on the ANC card
+type ANCCardVisit struct {
+	Date         time.Time         `json:"date"`
+	VisitType    model.VisitType   `json:"visit_type"`
+	Status       model.VisitStatus `json:"status"`
+	FacilityName string            `json:"facility_name"`
+	Notes        string            `json:"notes,omitempty"`
+}
+
+// ANCCardVitals is a row of vital signs on the ANC card
+type ANCCardVitals struct {
+	Date         time.Time `json:"date"`
+	Systolic     *float64  `json:"systolic,omitempty"`
+	Diastolic    *float64  `json:"diastolic,omitempty"`
+	Weight       *float64  `json:"weight,omitempty"`
+	Hemoglobin   *float64  `json:"hemoglobin,omitempty"`
+	FetalHR      *float64  `json:"fetal_heart_rate,omitempty"`
+	BloodSugar   *float64  `json:"blood_sugar,omitempty"`
+	FundalHeight *float64  `json:"fundal_height_cm,omitempty"`
+	FetalWeight  *float64  `json:"estimated_fetal_weight,omitempty"`
+	IsAbnormal   bool      `json:"is_abnormal"`
+}
+
+// GenerateANCCard generates the antenatal card for a mother with her visits, vitals and risk level
+func (s *Service) GenerateANCCard(
+	ctx context.Context,
+	motherID uuid.UUID,
+) (*ANCCard, error) {
+	// Retrieve mother
+	mother, err := s.motherRepo.GetByID(ctx, motherID)
+	if err != nil {
+		s.log.Error("Failed to find mother", logger.Fields{
+			"error":     err.Error(),
+			"mother_id": motherID.String(),
+		})
+		return nil, errorx.Wrap(err, "failed to find mother")
+	}
+
+	card := &ANCCard{
+		MotherID:         mother.ID,
+		MotherName:       "Mother " + mother.ID.String()[:8],
+		BloodType:        mother.BloodType,
+		RiskLevel:        mother.RiskLevel,
+		PregnancyStage:   mother.PregnancyStage,
+		ExpectedDelivery: mother.ExpectedDeliveryDate,
+		DeliveryDate:     mother.DeliveryDate,
+		Gravida:          mother.PregnancyHistory.PreviousPregnancies,
+		Para:             mother.PregnancyHistory.PreviousDeliveries,
+		PregnancyHistory: mother.PregnancyHistory,
+		HealthConditions: mother.HealthConditions,
+		Visits:           []ANCCardVisit{},
+		Vitals:           []ANCCardVitals{},
+		GeneratedAt:      time.Now(),
+	}
+
+	if user, err := s.userRepo.GetByID(ctx, mother.UserID); err == nil {
+		card.MotherName = user.Name
+		card.MotherContact = user.Phone
+		card.District = user.District
+	}
+
+	// Gravida counts the current pregnancy until it has been delivered
+	if !mother.IsPostpartum() {
+		card.Gravida++
+		card.GestationalAgeWeeks = mother.GetWeeksPregnant(card.GeneratedAt)
+	}
+
+	// Visits, oldest first as on the paper card
+	options := repository.NewVisitQueryOptions().
+		WithOrder("scheduled_time", "ASC")
+
+	visits, err := s.visitRepo.GetByMotherID(ctx, motherID, options)
+	if err != nil {
+		s.log.Error("Failed to get mother visits", logger.Fields{
+			"error":     err.Error(),
+			"mother_id": motherID.String(),
+		})
+		return nil, errorx.Wrap(err, "failed to get mother visits")
+	}
+
+	facilityNames := make(map[uuid.UUID]string)
+	for _, visit := range visits {
+		name, ok := facilityNames[visit.FacilityID]
+		if !ok {
+			name = "Unknown Facility"
+			if facility, err := s.facilityRepo.GetByID(ctx, visit.FacilityID); err == nil {
+				name = facility.Name
+			}
+			facilityNames[visit.FacilityID] = name
+		}
+
+		date := visit.ScheduledTime
+		if visit.CheckInTime != nil {
+			date = *visit.CheckInTime
+		}
+
+		card.Visits = append(card.Visits, ANCCardVisit{
+			Date:         date,
+			VisitType:    visit.VisitType,
+			Status:       visit.Status,
+			FacilityName: name,
+			Notes:        visit.VisitNotes,
+		})
+	}
+
+	// Vitals
+	metrics, err := s.healthMetricRepo.FindByMother(ctx, motherID)
+	if err != nil {
+		s.log.Error("Failed to get mother health metrics", logger.Fields{
+			"error":     err.Error(),
+			"mother_id": motherID.String(),
+		})
+		return nil, errorx.Wrap(err, "failed to get mother health metrics")
+	}
+
+	sort.Slice(metrics, func(i, j int) bool {
+		return metrics[i].RecordedAt.Before(metrics[j].RecordedAt)
+	})
+
+	for _, metric := range metrics {
+		row := ANCCardVitals{
+			Date:         metric.RecordedAt,
+			Weight:       metric.VitalSigns.Weight,
+			Hemoglobin:   metric.VitalSigns.HemoglobinLevel,
+			FetalHR:      metric.VitalSigns.FetalHeartRate,
+			BloodSugar:   metric.VitalSigns.BloodSugar,
+			FundalHeight: metric.VitalSigns.FundalHeightCm,
+			FetalWeight:  metric.VitalSigns.EstimatedFetalWeight,
+			IsAbnormal:   metric.IsAbnormal,
+		}
+		if bp := metric.VitalSigns.BloodPressure; bp != nil {
+			systolic, diastolic := bp.Systolic, bp.Diastolic
+			row.Systolic = &systolic
+			row.Diastolic = &diastolic
+		}
+		card.Vitals = append(card.Vitals, row)
+	}
+
+	// Fetal growth is charted for the pregnancy the card is for
+	fetalGrowth := growth.Assess(mother, metrics, card.GeneratedAt)
+	if len(fetalGrowth.FundalHeight.Points) > 0 || len(fetalGrowth.FetalWeight.Points) > 0 {
+		card.FetalGrowth = fetalGrowth
+	}
+
+	s.log.Info("Generated ANC card", logger.Fields{
+		"mother_id": motherID.String(),
+		"visits":    len(card.Visits),
+		"vitals":    len(card.Vitals),
+	})
+
+	return card, nil
+}
@@ -0,0 +1,636 @@
+package report
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mamacare/services/internal/app/health/growth"
+	"github.com/mamacare/services/pkg/errorx"
+	"github.com/mamacare/services/pkg/pdf"
+)
+
+// ExportFormat represents a downloadable report format
+type ExportFormat string
+
+const (
+	// ExportFormatPDF renders a printable PDF
+	ExportFormatPDF ExportFormat = "pdf"
+	// ExportFormatCSV renders a spreadsheet-friendly CSV
+	ExportFormatCSV ExportFormat = "csv"
+)
+
+const (
+	reportAuthority = "Government of Sierra Leone - Ministry of Health and Sanitation"
+	reportProgramme = "MamaCare SL - Maternal and Child Health"
+	reportDate      = "02 Jan 2006"
+	reportDateTime  = "02 Jan 2006 15:04"
+)
+
+// ExportFile is a rendered report ready to download
+type ExportFile struct {
+	Filename    string
+	ContentType string
+	Data        []byte
+}
+
+// reportSection is a titled block of a report: either label/value fields or a table
+type reportSection struct {
+	title   string
+	fields  [][2]string
+	columns []reportColumn
+	rows    [][]string
+	empty   string // shown when a table has no rows
+}
+
+// reportColumn is a table column with its share of the page width
+type reportColumn struct {
+	title string
+	width float64 // fraction of the printable width
+}
+
+// reportDocument is the format-independent content of a report
+type reportDocument struct {
+	title    string
+	subtitle string
+	filename string
+	sections []reportSection
+	footer   string
+}
+
+// ExportVisitReport renders a visit report as PDF or CSV
+func ExportVisitReport(r *VisitReport, format ExportFormat) (*ExportFile, error) {
+	fields := [][2]string{
+		{"Visit ID", r.Visit.ID.String()},
+		{"Visit type", string(r.Visit.VisitType)},
+		{"Status", string(r.Visit.Status)},
+		{"Scheduled", r.Visit.ScheduledTime.Format(reportDateTime)},
+		{"Check-in", formatOptionalTime(r.CheckInTime)},
+		{"Check-out", formatOptionalTime(r.CheckOutTime)},
+		{"Duration", formatDuration(r.VisitDuration)},
+	}
+	if r.Visit.CheckInVerification != nil {
+		verified := "No"
+		if r.Visit.CheckInVerification.Verified {
+			verified = "Yes"
+		}
+		fields = append(fields, [2]string{"Check-in verified", fmt.Sprintf("%s (%s)", verified, r.Visit.CheckInVerification.Method)})
+	}
+
+	pregnancy := [][2]string{
+		{"Last menstrual period", formatOptionalDate(r.LastMenstrualPeriod)},
+		{"Expected delivery", formatOptionalDate(r.EstimatedDeliveryDate)},
+	}
+	if r.GestationalAge > 0 {
+		pregnancy = append(pregnancy, [2]string{"Gestational age", fmt.Sprintf("%d weeks", r.GestationalAge)})
+	}
+
+	doc := &reportDocument{
+		title:    "Visit Report",
+		subtitle: fmt.Sprintf("%s - %s", r.MotherName, r.Visit.ScheduledTime.Format(reportDate)),
+		filename: fmt.Sprintf("visit-report-%s", r.Visit.ID.String()[:8]),
+		sections: []reportSection{
+			{title: "Visit", fields: fields},
+			{title: "Mother", fields: [][2]string{
+				{"Name", r.MotherName},
+				{"Age", formatInt(r.MotherAge)},
+				{"Contact", r.MotherContact},
+			}},
+			{title: "Pregnancy", fields: pregnancy},
+			{title: "Care team", fields: [][2]string{
+				{"Facility", r.FacilityName},
+				{"Facility type", r.FacilityType},
+				{"Facility contact", r.FacilityContact},
+				{"CHW", r.CHWName},
+				{"CHW contact", r.CHWContact},
+				{"Clinician", r.ClinicianName},
+			}},
+			{title: "Notes", fields: [][2]string{{"Visit notes", r.Visit.VisitNotes}}},
+		},
+	}
+
+	return doc.export(format, r.GeneratedAt)
+}
+
+// ExportFacilitySummary renders a facility summary as PDF or CSV
+func ExportFacilitySummary(r *FacilitySummaryReport, format ExportFormat) (*ExportFile, error) {
+	doc := &reportDocument{
+		title:    "Facility Visit Summary",
+		subtitle: fmt.Sprintf("%s - %s to %s", r.FacilityName, r.StartDate.Format(reportDate), r.EndDate.Format(reportDate)),
+		filename: fmt.Sprintf("facility-summary-%s-%s", r.FacilityID.String()[:8], r.StartDate.Format("2006-01-02")),
+		sections: []reportSection{
+			{title: "Summary", fields: [][2]string{
+				{"Total visits", formatInt(r.TotalVisits)},
+				{"Completion rate", formatPercent(r.CompletionRate)},
+				{"Average visit duration", fmt.Sprintf("%.0f min", r.AverageVisitDuration)},
+			}},
+			countSection("Visits by type", "Visit type", stringCounts(r.VisitsByType)),
+			countSection("Visits by status", "Status", stringCounts(r.VisitsByStatus)),
+			countSection("Visits by day", "Date", r.VisitsByDay),
+		},
+	}
+
+	return doc.export(format, r.GeneratedAt)
+}
+
+// ExportCHWSummary renders a CHW summary, including unverified check-ins, as PDF or CSV
+func ExportCHWSummary(r *CHWSummaryReport, format ExportFormat) (*ExportFile, error) {
+	unverified := reportSection{
+		title: "Unverified check-ins",
+		columns: []reportColumn{
+			{"Scheduled", 0.18}, {"Checked in", 0.18}, {"Mother", 0.16}, {"Method", 0.12}, {"Reason", 0.36},
+		},
+		empty: "All check-ins in this period were verified.",
+	}
+	for _, u := range r.UnverifiedVisits {
+		unverified.rows = append(unverified.rows, []string{
+			u.ScheduledTime.Format(reportDateTime),
+			u.CheckInTime.Format(reportDateTime),
+			u.MotherID.String()[:8],
+			string(u.Method),
+			u.Reason,
+		})
+	}
+
+	doc := &reportDocument{
+		title:    "CHW Performance Summary",
+		subtitle: fmt.Sprintf("%s - %s to %s", r.CHWName, r.StartDate.Format(reportDate), r.EndDate.Format(reportDate)),
+		filename: fmt.Sprintf("chw-summary-%s-%s", r.CHWID.String()[:8], r.StartDate.Format("2006-01-02")),
+		sections: []reportSection{
+			{title: "Summary", fields: [][2]string{
+				{"Total visits", formatInt(r.TotalVisits)},
+				{"Completed", formatInt(r.CompletedVisits)},
+				{"Cancelled", formatInt(r.CancelledVisits)},
+				{"Missed", formatInt(r.MissedVisits)},
+				{"Completion rate", formatPercent(r.CompletionRate)},
+				{"Average visit duration", fmt.Sprintf("%.0f min", r.AverageVisitDuration)},
+			}},
+			{title: "Check-in verification", fields: [][2]string{
+				{"Checked-in visits", formatInt(r.CheckedInVisits)},
+				{"Verified", formatInt(r.VerifiedCheckIns)},
+				{"Unverified", formatInt(r.UnverifiedCheckIns)},
+				{"Verification rate", formatPercent(r.VerificationRate)},
+			}},
+			unverified,
+			countSection("Visits by day", "Date", r.VisitsByDay),
+		},
+		footer: "Supervisor signature: ______________________    Date: ____________",
+	}
+
+	return doc.export(format, r.GeneratedAt)
+}
+
+// ExportDistrictSummary renders a district summary as PDF or CSV
+func ExportDistrictSummary(r *DistrictSummaryReport, format ExportFormat) (*ExportFile, error) {
+	facilities := reportSection{
+		title:   "Facilities",
+		columns: []reportColumn{{"Facility", 0.55}, {"Visits", 0.2}, {"Completion rate", 0.25}},
+		empty:   "No facility visits in this period.",
+	}
+
+	ids := make([]uuid.UUID, 0, len(r.VisitsByFacility))
+	for id := range r.VisitsByFacility {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		return r.FacilityNames[ids[i]] < r.FacilityNames[ids[j]]
+	})
+	for _, id := range ids {
+		facilities.rows = append(facilities.rows, []string{
+			r.FacilityNames[id],
+			formatInt(r.VisitsByFacility[id]),
+			formatPercent(r.CompletionRates[id]),
+		})
+	}
+
+	doc := &reportDocument{
+		title:    "District Visit Summary",
+		subtitle: fmt.Sprintf("%s District - %s to %s", r.District, r.StartDate.Format(reportDate), r.EndDate.Format(reportDate)),
+		filename: fmt.Sprintf("district-summary-%s-%s", slug(r.District), r.StartDate.Format("2006-01-02")),
+		sections: []reportSection{
+			{title: "Summary", fields: [][2]string{
+				{"Total visits", formatInt(r.TotalVisits)},
+				{"Mothers seen", formatInt(r.TotalMothers)},
+				{"Overall completion rate", formatPercent(r.OverallCompletionRate)},
+			}},
+			facilities,
+			countSection("Visits by type", "Visit type", stringCounts(r.VisitsByType)),
+			countSection("Visits by status", "Status", stringCounts(r.VisitsByStatus)),
+		},
+	}
+
+	return doc.export(format, r.GeneratedAt)
+}
+
+// ExportANCCard renders a mother's antenatal card as PDF or CSV
+func ExportANCCard(c *ANCCard, format ExportFormat) (*ExportFile, error) {
+	profile := [][2]string{
+		{"Name", c.MotherName},
+		{"Phone", c.MotherContact},
+		{"District", c.District},
+		{"MamaCare ID", c.MotherID.String()},
+		{"Blood group", string(c.BloodType)},
+		{"Risk level", strings.ToUpper(string(c.RiskLevel))},
+	}
+
+	pregnancy := [][2]string{
+		{"Stage", strings.ReplaceAll(string(c.PregnancyStage), "_", " ")},
+		{"Expected delivery", c.ExpectedDelivery.Format(reportDate)},
+		{"Gravida / Para", fmt.Sprintf("G%d P%d", c.Gravida, c.Para)},
+		{"Previous caesareans", formatInt(c.PregnancyHistory.PreviousCaesareans)},
+		{"Previous complications", strings.Join(c.PregnancyHistory.PreviousComplications, ", ")},
+		{"Health conditions", strings.Join(c.HealthConditions, ", ")},
+	}
+	if c.GestationalAgeWeeks > 0 {
+		pregnancy = append(pregnancy, [2]string{"Gestational age", fmt.Sprintf("%d weeks", c.GestationalAgeWeeks)})
+	}
+	if c.DeliveryDate != nil {
+		pregnancy = append(pregnancy, [2]string{"Delivered", c.DeliveryDate.Format(reportDate)})
+	}
+
+	visits := reportSection{
+		title:   "Visits",
+		columns: []reportColumn{{"Date", 0.17}, {"Type", 0.13}, {"Status", 0.13}, {"Facility", 0.25}, {"Notes", 0.32}},
+		empty:   "No visits recorded.",
+	}
+	for _, v := range c.Visits {
+		visits.rows = append(visits.rows, []string{
+			v.Date.Format(reportDate),
+			string(v.VisitType),
+			string(v.Status),
+			v.FacilityName,
+			v.Notes,
+		})
+	}
+
+	vitals := reportSection{
+		title: "Vital signs",
+		columns: []reportColumn{
+			{"Date", 0.17}, {"BP (mmHg)", 0.15}, {"Weight (kg)", 0.14}, {"Hb (g/dL)", 0.13},
+			{"FHR (bpm)", 0.13}, {"Sugar (mg/dL)", 0.15}, {"Flag", 0.13},
+		},
+		empty: "No vital signs recorded.",
+	}
+	for _, v := range c.Vitals {
+		bp := ""
+		if v.Systolic != nil && v.Diastolic != nil {
+			bp = fmt.Sprintf("%.0f/%.0f", *v.Systolic, *v.Diastolic)
+		}
+		flag := ""
+		if v.IsAbnormal {
+			flag = "ABNORMAL"
+		}
+		vitals.rows = append(vitals.rows, []string{
+			v.Date.Format(reportDate),
+			bp,
+			formatOptionalFloat(v.Weight, "%.1f"),
+			formatOptionalFloat(v.Hemoglobin, "%.1f"),
+			formatOptionalFloat(v.FetalHR, "%.0f"),
+			formatOptionalFloat(v.BloodSugar, "%.0f"),
+			flag,
+		})
+	}
+
+	sections := []reportSection{
+		{title: "Mother", fields: profile},
+		{title: "Pregnancy", fields: pregnancy},
+		visits,
+		vitals,
+	}
+	if c.FetalGrowth != nil {
+		sections = append(sections, fetalGrowthSections(c.FetalGrowth)...)
+	}
+
+	doc := &reportDocument{
+		title:    "Antenatal Care Card",
+		subtitle: c.MotherName,
+		filename: fmt.Sprintf("anc-card-%s", c.MotherID.String()[:8]),
+		sections: sections,
+		footer:   "Bring this card to every visit. In an emergency, use the SOS button in the MamaCare app.",
+	}
+
+	return doc.export(format, c.GeneratedAt)
+}
+
+// fetalGrowthSections renders the fetal growth chart points and any growth concern
+func fetalGrowthSections(g *growth.Assessment) []reportSection {
+	type row struct {
+		date  time.Time
+		cells []string
+	}
+
+	var rows []row
+	for _, chart := range []*growth.Chart{g.FundalHeight, g.FetalWeight} {
+		label := "Fundal height (cm)"
+		format := "%.1f"
+		if chart.Measure == growth.MeasureFetalWeight {
+			label = "Fetal weight (g)"
+			format = "%.0f"
+		}
+		for _, p := range chart.Points {
+			rows = append(rows, row{date: p.RecordedAt, cells: []string{
+				p.RecordedAt.Format(reportDate),
+				fmt.Sprintf("%.1f", p.GestationalAgeWeeks),
+				label,
+				fmt.Sprintf(format, p.Value),
+				fmt.Sprintf("%.0f", p.Centile),
+				fmt.Sprintf(format+" - "+format, p.P10, p.P90),
+			}})
+		}
+	}
+	sort.SliceStable(rows, func(i, j int) bool { return rows[i].date.Before(rows[j].date) })
+
+	chart := reportSection{
+		title: "Fetal growth",
+		columns: []reportColumn{
+			{"Date", 0.17}, {"GA (weeks)", 0.12}, {"Measure", 0.21}, {"Value", 0.13},
+			{"Centile", 0.12}, {"10th - 90th", 0.25},
+		},
+		empty: "No fundal height or fetal weight recorded.",
+	}
+	for _, r := range rows {
+		chart.rows = append(chart.rows, r.cells)
+	}
+
+	sections := []reportSection{chart}
+	if g.Concern != growth.ConcernNone {
+		findings := make([]string, 0, len(g.Findings))
+		for _, finding := range g.Findings {
+			findings = append(findings, finding.Message)
+		}
+		sections = append(sections, reportSection{
+			title: "Growth concern",
+			fields: [][2]string{
+				{"Concern", strings.ToUpper(strings.ReplaceAll(string(g.Concern), "_", " "))},
+				{"Findings", strings.Join(findings, "; ")},
+				{"Recommendation", g.Recommendation},
+			},
+		})
+	}
+
+	return sections
+}
+
+// export renders the document in the requested format
+func (d *reportDocument) export(format ExportFormat, generatedAt time.Time) (*ExportFile, error) {
+	switch format {
+	case ExportFormatPDF:
+		data, err := d.renderPDF(generatedAt)
+		if err != nil {
+			return nil, errorx.Wrap(err, "failed to render PDF report")
+		}
+		return &ExportFile{Filename: d.filename + ".pdf", ContentType: "application/pdf", Data: data}, nil
+	case ExportFormatCSV:
+		data, err := d.renderCSV(generatedAt)
+		if err != nil {
+			return nil, errorx.Wrap(err, "failed to render CSV report")
+		}
+		return &ExportFile{Filename: d.filename + ".csv", ContentType: "text/csv; charset=utf-8", Data: data}, nil
+	default:
+		return nil, errorx.Newf(errorx.BadRequest, "unsupported export format %s", format)
+	}
+}
+
+// renderCSV writes the header, then each section separated by a blank line
+func (d *reportDocument) renderCSV(generatedAt time.Time) ([]byte, error) {
+	buf := &bytes.Buffer{}
+	w := csv.NewWriter(buf)
+
+	header := [][]string{
+		{reportAuthority},
+		{reportProgramme},
+		{d.title},
+		{d.subtitle},
+		{"Generated", generatedAt.Format(reportDateTime)},
+	}
+	if err := w.WriteAll(header); err != nil {
+		return nil, err
+	}
+
+	for _, section := range d.sections {
+		_ = w.Write([]string{})
+		_ = w.Write([]string{section.title})
+
+		if section.columns != nil {
+			titles := make([]string, len(section.columns))
+			for i, col := range section.columns {
+				titles[i] = col.title
+			}
+			_ = w.Write(titles)
+			for _, row := range section.rows {
+				_ = w.Write(row)
+			}
+			continue
+		}
+
+		for _, field := range section.fields {
+			_ = w.Write([]string{field[0], field[1]})
+		}
+	}
+
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// renderPDF lays the document out on A4 pages with the MoHS header on each page
+func (d *reportDocument) renderPDF(generatedAt time.Time) ([]byte, error) {
+	doc := pdf.NewA4().SetInfo(d.title+" - "+d.subtitle, "MamaCare SL")
+	p := &pdfPage{doc: doc, report: d, generatedAt: generatedAt}
+	p.newPage()
+
+	for _, section := range d.sections {
+		p.ensure(40)
+		p.y += 8
+		doc.FillRect(pdfMargin, p.y-11, p.width(), 16, 0.9)
+		doc.Text(pdfMargin+4, p.y+1, 11, true, section.title)
+		p.y += 20
+
+		if section.columns != nil {
+			p.table(section)
+			continue
+		}
+
+		for _, field := range section.fields {
+			p.ensure(14)
+			doc.Text(pdfMargin, p.y, 9, true, field[0])
+			doc.Text(pdfMargin+150, p.y, 9, false, pdf.Truncate(field[1], 9, p.width()-150))
+			p.y += 14
+		}
+	}
+
+	if d.footer != "" {
+		p.ensure(30)
+		p.y += 16
+		doc.Text(pdfMargin, p.y, 9, false, d.footer)
+	}
+
+	return doc.Bytes()
+}
+
+const pdfMargin = 40.0
+
+// pdfPage tracks the layout position while rendering a report
+type pdfPage struct {
+	doc         *pdf.Document
+	report      *reportDocument
+	generatedAt time.Time
+	y           float64
+}
+
+// width returns the printable width
+func (p *pdfPage) width() float64 {
+	return p.doc.Width() - 2*pdfMargin
+}
+
+// newPage starts a page with the MoHS/MamaCare header and footer
+func (p *pdfPage) newPage() {
+	doc := p.doc
+	doc.AddPage()
+
+	y := pdfMargin
+	doc.Text(pdfMargin, y, 8, false, reportAuthority)
+	y += 16
+	doc.Text(pdfMargin, y, 15, true, reportProgramme)
+	y += 20
+	doc.Text(pdfMargin, y, 13, true, p.report.title)
+	y += 14
+	doc.Text(pdfMargin, y, 10, false, p.report.subtitle)
+	y += 8
+	doc.Line(pdfMargin, y, doc.Width()-pdfMargin, y)
+
+	footerY := doc.Height() - pdfMargin/2
+	doc.Text(pdfMargin, footerY, 7, false, fmt.Sprintf("Generated %s by MamaCare SL", p.generatedAt.Format(reportDateTime)))
+	doc.Text(doc.Width()-pdfMargin-40, footerY, 7, false, fmt.Sprintf("Page %d", doc.PageCount()))
+
+	p.y = y + 18
+}
+
+// ensure starts a new page if there is less than the given space left
+func (p *pdfPage) ensure(space float64) bool {
+	if p.y+space > p.doc.Height()-pdfMargin {
+		p.newPage()
+		return true
+	}
+	return false
+}
+
+// table draws a table, repeating the header row after page breaks
+func (p *pdfPage) table(section reportSection) {
+	header := func() {
+		x := pdfMargin
+		for _, col := range section.columns {
+			p.doc.Text(x, p.y, 8, true, col.title)
+			x += col.width * p.width()
+		}
+		p.y += 4
+		p.doc.Line(pdfMargin, p.y, pdfMargin+p.width(), p.y)
+		p.y += 12
+	}
+
+	header()
+
+	if len(section.rows) == 0 {
+		p.doc.Text(pdfMargin, p.y, 9, false, section.empty)
+		p.y += 14
+		return
+	}
+
+	for _, row := range section.rows {
+		if p.ensure(14) {
+			header()
+		}
+		x := pdfMargin
+		for i, col := range section.columns {
+			if i < len(row) {
+				p.doc.Text(x, p.y, 8, false, pdf.Truncate(row[i], 8, col.width*p.width()-4))
+			}
+			x += col.width * p.width()
+		}
+		p.y += 13
+	}
+}
+
+// countSection builds a two-column table from a map of counts, sorted by key
+func countSection(title, keyTitle string, counts map[string]int) reportSection {
+	section := reportSection{
+		title:   title,
+		columns: []reportColumn{{keyTitle, 0.6}, {"Visits", 0.4}},
+		empty:   "No visits in this period.",
+	}
+
+	keys := make([]string, 0, len(counts))
+	for key := range counts {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		section.rows = append(section.rows, []string{key, formatInt(counts[key])})
+	}
+
+	return section
+}
+
+// stringCounts converts a map keyed by a string type to plain string keys
+func stringCounts[K ~string](counts map[K]int) map[string]int {
+	out := make(map[string]int, len(counts))
+	for key, count := range counts {
+		out[string(key)] = count
+	}
+	return out
+}
+
+// formatInt formats an integer
+func formatInt(value int) string {
+	return fmt.Sprintf("%d", value)
+}
+
+// formatPercent formats a percentage
+func formatPercent(value float64) string {
+	return fmt.Sprintf("%.1f%%", value)
+}
+
+// formatDuration formats a visit duration in minutes
+func formatDuration(d time.Duration) string {
+	if d <= 0 {
+		return ""
+	}
+	return fmt.Sprintf("%.0f min", d.Minutes())
+}
+
+// formatOptionalTime formats a time, leaving zero times blank
+func formatOptionalTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format(reportDateTime)
+}
+
+// formatOptionalDate formats a date, leaving zero dates blank
+func formatOptionalDate(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format(reportDate)
+}
+
+// formatOptionalFloat formats an optional measurement
+func formatOptionalFloat(value *float64, format string) string {
+	if value == nil {
+		return ""
+	}
+	return fmt.Sprintf(format, *value)
+}
+
+// slug makes a value safe to use in a filename
+func slug(value string) string {
+	return strings.ToLower(strings.Join(strings.Fields(value), "-"))
+}
@@ -14,11 +14,12 @@ import (
 
 // Service provides functionality for generating visit reports
 type Service struct {
-	visitRepo    repository.VisitRepository
-	motherRepo   repository.MotherRepository
-	facilityRepo repository.FacilityRepository
-	userRepo     repository.UserRepository
-	log          logger.Logger
+	visitRepo        repository.VisitRepository
+	motherRepo       repository.MotherRepository
+	facilityRepo     repository.FacilityRepository
+	userRepo         repository.UserRepository
+	healthMetricRepo repository.HealthMetricRepository
+	log              logger.Logger
 }
 
 // NewService creates a new visit report service
@@ -27,14 +28,16 @@ func NewService(
 	motherRepo repository.MotherRepository,
 	facilityRepo repository.FacilityRepository,
 	userRepo repository.UserRepository,
+	healthMetricRepo repository.HealthMetricRepository,
 	log logger.Logger,
 ) *Service {
 	return &Service{
-		visitRepo:    visitRepo,
-		motherRepo:   motherRepo,
-		facilityRepo: facilityRepo,
-		userRepo:     userRepo,
-		log:          log,
+		visitRepo:        visitRepo,
+		motherRepo:       motherRepo,
+		facilityRepo:     facilityRepo,
+		userRepo:         userRepo,
+		healthMetricRepo: healthMetricRepo,
+		log:              log,
 	}
 }
 
@@ -265,6 +268,7 @@ func (s *Service) GenerateCHWSummary(
 		MissedVisits:     0,
 		VisitsByDay:      make(map[string]int),
 		AverageVisitDuration: 0,
+		UnverifiedVisits: []UnverifiedCheckIn{},
 	}
 
 	// Counters
@@ -299,6 +303,17 @@ func (s *Service) GenerateCHWSummary(
 			totalVisitDuration += duration
 			completedVisitsCount++
 		}
+
+		// Flag check-ins without geofence or code evidence for supervisor audit
+		if visit.CheckInTime != nil {
+			summary.CheckedInVisits++
+			if visit.IsCheckInVerified() {
+				summary.VerifiedCheckIns++
+			} else {
+				summary.UnverifiedCheckIns++
+				summary.UnverifiedVisits = append(summary.UnverifiedVisits, newUnverifiedCheckIn(visit))
+			}
+		}
 	}
 
 	// Calculate average visit duration
@@ -311,12 +326,18 @@ func (s *Service) GenerateCHWSummary(
 		summary.CompletionRate = float64(summary.CompletedVisits) / float64(summary.TotalVisits) * 100
 	}
 
+	// Calculate check-in verification rate
+	if summary.CheckedInVisits > 0 {
+		summary.VerificationRate = float64(summary.VerifiedCheckIns) / float64(summary.CheckedInVisits) * 100
+	}
+
 	s.log.Info("Generated CHW summary report", logger.Fields{
 		"chw_id":         chwID.String(),
 		"start_date":     startDate.Format("2006-01-02"),
 		"end_date":       endDate.Format("2006-01-02"),
 		"total_visits":   summary.TotalVisits,
 		"completed_visits": summary.CompletedVisits,
+		"unverified_check_ins": summary.UnverifiedCheckIns,
 	})
 
 	return summary, nil
@@ -475,6 +496,42 @@ type CHWSummaryReport struct {
 	VisitsByDay        map[string]int `json:"visits_by_day"`
 	AverageVisitDuration float64     `json:"average_visit_duration_minutes"`
 	CompletionRate     float64       `json:"completion_rate_percentage"`
+	CheckedInVisits    int           `json:"checked_in_visits"`
+	VerifiedCheckIns   int           `json:"verified_check_ins"`
+	UnverifiedCheckIns int           `json:"unverified_check_ins"`
+	VerificationRate   float64       `json:"verification_rate_percentage"`
+	UnverifiedVisits   []UnverifiedCheckIn `json:"unverified_visits"`
+}
+
+// UnverifiedCheckIn flags a check-in that lacked location or code evidence
+type UnverifiedCheckIn struct {
+	VisitID        uuid.UUID           `json:"visit_id"`
+	MotherID       uuid.UUID           `json:"mother_id"`
+	ScheduledTime  time.Time           `json:"scheduled_time"`
+	CheckInTime    time.Time           `json:"check_in_time"`
+	Method         model.CheckInMethod `json:"method"`
+	DistanceMeters *float64            `json:"distance_meters,omitempty"`
+	Reason         string              `json:"reason"`
+}
+
+// newUnverifiedCheckIn builds the audit entry for an unverified check-in
+func newUnverifiedCheckIn(visit *model.Visit) UnverifiedCheckIn {
+	entry := UnverifiedCheckIn{
+		VisitID:       visit.ID,
+		MotherID:      visit.MotherID,
+		ScheduledTime: visit.ScheduledTime,
+		CheckInTime:   *visit.CheckInTime,
+		Method:        model.CheckInMethodNone,
+		Reason:        "checked in before verification was required",
+	}
+
+	if v := visit.CheckInVerification; v != nil {
+		entry.Method = v.Method
+		entry.DistanceMeters = v.DistanceMeters
+		entry.Reason = v.Reason
+	}
+
+	return entry
 }
 
 // DistrictSummaryReport represents a summary report for a district
@@ -135,7 +135,8 @@ func (s *Service) RescheduleVisit(
 		return nil, errorx.New(errorx.BadRequest, "new scheduled time must be in the future")
 	}
 
-	// Reschedule visit
+	// Reschedule visit; this bumps the visit sequence so calendar feeds pick up the new time
+	oldScheduledTime := visit.ScheduledTime
 	visit.Reschedule(newScheduledTime)
 
 	// Update visit
@@ -152,8 +153,9 @@ func (s *Service) RescheduleVisit(
 		"mother_id":       visit.MotherID.String(),
 		"facility_id":     visit.FacilityID.String(),
 		"new_scheduled_at": newScheduledTime.Format(time.RFC3339),
-		"old_scheduled_at": visit.ScheduledTime.Format(time.RFC3339),
+		"old_scheduled_at": oldScheduledTime.Format(time.RFC3339),
 		"visit_type":      string(visit.VisitType),
+		"sequence":        visit.Sequence,
 	})
 
 	return visit, nil
@@ -179,7 +181,7 @@ func (s *Service) CancelVisit(
 		return nil, errorx.Newf(errorx.BadRequest, "cannot cancel visit with status %s", visit.Status)
 	}
 
-	// Cancel visit
+	// Cancel visit; calendar feeds keep showing it as cancelled
 	visit.Cancel()
 
 	// Update visit
@@ -197,6 +199,7 @@ func (s *Service) CancelVisit(
 		"facility_id": visit.FacilityID.String(),
 		"scheduled_at": visit.ScheduledTime.Format(time.RFC3339),
 		"visit_type":  string(visit.VisitType),
+		"sequence":    visit.Sequence,
 	})
 
 	return visit, nil
@@ -332,10 +335,13 @@ func (s *Service) GenerateAutomaticVisits(
 	}
 
 	// Check if mother is pregnant
-	if mother.LMP == nil {
-		return nil, errorx.New(errorx.BadRequest, "mother does not have a recorded LMP (Last Menstrual Period)")
+	if mother.IsPostpartum() {
+		return nil, errorx.New(errorx.BadRequest, "mother has already delivered")
 	}
 
+	// Count gestation from the dating EDD so ultrasound corrections are respected
+	lmp := mother.DatingLMP()
+
 	// Check if facility exists
 	_, err = s.facilityRepo.GetByID(ctx, facilityID)
 	if err != nil {
@@ -346,12 +352,11 @@ func (s *Service) GenerateAutomaticVisits(
 		return nil, errorx.Wrap(err, "failed to find facility")
 	}
 
-	// Calculate expected delivery date (EDD) - about 40 weeks after LMP
-	edd := mother.LMP.AddDate(0, 0, 280) // 280 days = 40 weeks
+	edd := mother.ExpectedDeliveryDate
 
 	// Calculate current gestational age in weeks
 	now := time.Now()
-	gestationalAgeInDays := int(now.Sub(*mother.LMP).Hours() / 24)
+	gestationalAgeInDays := int(now.Sub(lmp).Hours() / 24)
 	gestationalAgeInWeeks := gestationalAgeInDays / 7
 
 	// Check if already delivered or too early in pregnancy
@@ -394,7 +399,7 @@ func (s *Service) GenerateAutomaticVisits(
 	existingVisitsByWeek := make(map[int]bool)
 	for _, visit := range existingVisits {
 		// Calculate which week of pregnancy this visit was scheduled for
-		daysFromLMP := int(visit.ScheduledTime.Sub(*mother.LMP).Hours() / 24)
+		daysFromLMP := int(visit.ScheduledTime.Sub(lmp).Hours() / 24)
 		weekOfPregnancy := daysFromLMP / 7
 		existingVisitsByWeek[weekOfPregnancy] = true
 	}
@@ -414,7 +419,7 @@ func (s *Service) GenerateAutomaticVisits(
 		}
 
 		// Calculate the date for this visit (LMP + weeks * 7 days)
-		visitDate := mother.LMP.AddDate(0, 0, scheduleItem.Week*7)
+		visitDate := lmp.AddDate(0, 0, scheduleItem.Week*7)
 		
 		// Default visit hour (10:00 AM)
 		visitDate = time.Date(
@@ -451,13 +456,77 @@ func (s *Service) GenerateAutomaticVisits(
 		"new_visits":      len(newVisits),
 		"existing_visits": len(existingVisits),
 		"edd":             edd.Format(time.RFC3339),
-		"lmp":             mother.LMP.Format(time.RFC3339),
+		"lmp":             lmp.Format(time.RFC3339),
 		"current_week":    gestationalAgeInWeeks,
 	})
 
 	return newVisits, nil
 }
 
+// RealignVisitPlan moves a mother's upcoming routine visits after her EDD has been
+// corrected, so each stays at the same week of pregnancy. It returns how many moved.
+func (s *Service) RealignVisitPlan(ctx context.Context, motherID uuid.UUID, previousEDD time.Time) (int, error) {
+	mother, err := s.motherRepo.GetByID(ctx, motherID)
+	if err != nil {
+		s.log.Error("Failed to find mother", logger.Fields{
+			"error":     err.Error(),
+			"mother_id": motherID.String(),
+		})
+		return 0, errorx.Wrap(err, "failed to find mother")
+	}
+
+	shiftDays := int(mother.ExpectedDeliveryDate.Sub(previousEDD).Hours() / 24)
+	if shiftDays == 0 {
+		return 0, nil
+	}
+
+	options := repository.NewVisitQueryOptions().
+		WithStatus(model.VisitStatusScheduled).
+		WithType(model.VisitTypeRoutine).
+		WithOrder("scheduled_time", "ASC")
+
+	visits, err := s.visitRepo.GetByMotherID(ctx, motherID, options)
+	if err != nil {
+		s.log.Error("Failed to get visits to realign", logger.Fields{
+			"error":     err.Error(),
+			"mother_id": motherID.String(),
+		})
+		return 0, errorx.Wrap(err, "failed to get visits to realign")
+	}
+
+	now := time.Now()
+	moved := 0
+	for _, visit := range visits {
+		if visit.Status != model.VisitStatusScheduled || visit.VisitType != model.VisitTypeRoutine ||
+			visit.ScheduledTime.Before(now) {
+			continue
+		}
+
+		newTime := visit.ScheduledTime.AddDate(0, 0, shiftDays)
+		if !newTime.After(now) {
+			// The visit is now overdue by the new dating; keep it at its current time
+			continue
+		}
+
+		if _, err := s.RescheduleVisit(ctx, visit.ID, newTime); err != nil {
+			s.log.Warn("Failed to realign visit", logger.Fields{
+				"error":    err.Error(),
+				"visit_id": visit.ID.String(),
+			})
+			continue
+		}
+		moved++
+	}
+
+	s.log.Info("Visit plan realigned to corrected EDD", logger.Fields{
+		"mother_id":    motherID.String(),
+		"shift_days":   shiftDays,
+		"visits_moved": moved,
+	})
+
+	return moved, nil
+}
+
 // GetVisitsByFacility retrieves visits for a facility
 func (s *Service) GetVisitsByFacility(
 	ctx context.Context,
@@ -0,0 +1,284 @@
+package status
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha1"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mamacare/services/internal/domain/model"
+	"github.com/mamacare/services/pkg/errorx"
+	"github.com/mamacare/services/pkg/logger"
+)
+
+// checkInQRPrefix prefixes the payload encoded in the mother's check-in QR code
+const checkInQRPrefix = "mamacare-checkin"
+
+// CheckInConfig configures how check-ins are verified
+type CheckInConfig struct {
+	// GeofenceRadiusMeters is how close the device must be to the mother's home or facility
+	GeofenceRadiusMeters float64
+	// MaxAccuracyMeters rejects location fixes that are too imprecise to prove presence
+	MaxAccuracyMeters float64
+	// CodeSecret signs the rotating QR/OTP codes shown in the mother's app
+	CodeSecret string
+	// CodeStep is how long each code is valid before it rotates
+	CodeStep time.Duration
+	// CodeDigits is the length of the OTP
+	CodeDigits int
+}
+
+// DefaultCheckInConfig returns the default check-in verification settings
+func DefaultCheckInConfig() CheckInConfig {
+	return CheckInConfig{
+		GeofenceRadiusMeters: 200,
+		MaxAccuracyMeters:    150,
+		CodeStep:             5 * time.Minute,
+		CodeDigits:           6,
+	}
+}
+
+// CheckInEvidence is what the CHW's device submits when checking in a visit
+type CheckInEvidence struct {
+	CheckedInByID  uuid.UUID
+	Latitude       *float64
+	Longitude      *float64
+	AccuracyMeters *float64
+	// Code is either the OTP typed in or the scanned QR payload
+	Code string
+}
+
+// CheckInCode is the rotating code shown in the mother's app
+type CheckInCode struct {
+	VisitID   uuid.UUID `json:"visit_id"`
+	Code      string    `json:"code"`
+	QRPayload string    `json:"qr_payload"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// GenerateCheckInCode generates the current check-in code for a visit so the
+// mother's app can show it as a QR code or OTP. Only the visit's mother is given
+// the code, since holding it is what proves the CHW was with her.
+func (s *Service) GenerateCheckInCode(
+	ctx context.Context,
+	visitID uuid.UUID,
+	requesterID uuid.UUID,
+) (*CheckInCode, error) {
+	visit, err := s.visitRepo.GetByID(ctx, visitID)
+	if err != nil {
+		s.log.Error("Failed to find visit", logger.Fields{
+			"error":    err.Error(),
+			"visit_id": visitID.String(),
+		})
+		return nil, errorx.Wrap(err, "failed to find visit")
+	}
+
+	mother, err := s.motherRepo.GetByID(ctx, visit.MotherID)
+	if err != nil {
+		s.log.Error("Failed to find mother", logger.Fields{
+			"error":     err.Error(),
+			"mother_id": visit.MotherID.String(),
+		})
+		return nil, errorx.Wrap(err, "failed to find mother")
+	}
+	if mother.UserID != requesterID {
+		return nil, errorx.New(errorx.Forbidden, "check-in codes are only shown to the visit's mother")
+	}
+
+	if visit.Status != model.VisitStatusScheduled {
+		return nil, errorx.Newf(errorx.BadRequest, "cannot generate a check-in code for visit with status %s", visit.Status)
+	}
+
+	if s.checkInConfig.CodeSecret == "" {
+		return nil, errorx.New(errorx.InternalServerError, "check-in codes are not configured")
+	}
+
+	now := time.Now()
+	counter := s.codeCounter(now)
+	code := s.checkInCode(visit.ID, counter)
+
+	return &CheckInCode{
+		VisitID:   visit.ID,
+		Code:      code,
+		QRPayload: fmt.Sprintf("%s:%s:%s", checkInQRPrefix, visit.ID.String(), code),
+		ExpiresAt: time.Unix(int64(counter+1)*int64(s.checkInConfig.CodeStep/time.Second), 0),
+	}, nil
+}
+
+// verifyCheckIn checks the submitted evidence and records why a check-in could not be verified.
+// Unverified check-ins are still accepted so care isn't blocked, but they are flagged for audit.
+func (s *Service) verifyCheckIn(
+	ctx context.Context,
+	visit *model.Visit,
+	evidence *CheckInEvidence,
+) model.CheckInVerification {
+	verification := model.CheckInVerification{
+		Method: model.CheckInMethodNone,
+	}
+
+	if evidence == nil {
+		verification.Reason = "no check-in evidence provided"
+		return verification
+	}
+
+	checkedInByID := evidence.CheckedInByID
+	verification.CheckedInByID = &checkedInByID
+	verification.Latitude = evidence.Latitude
+	verification.Longitude = evidence.Longitude
+	verification.AccuracyMeters = evidence.AccuracyMeters
+
+	// A valid code proves the CHW was with the mother
+	if evidence.Code != "" {
+		verification.Method = model.CheckInMethodCode
+		if s.validCheckInCode(visit.ID, evidence.Code, time.Now()) {
+			verification.Verified = true
+		} else {
+			verification.Reason = "invalid or expired check-in code"
+		}
+		return verification
+	}
+
+	if evidence.Latitude == nil || evidence.Longitude == nil {
+		verification.Reason = "no device location or check-in code provided"
+		return verification
+	}
+
+	verification.Method = model.CheckInMethodGeofence
+	verification.RadiusMeters = s.checkInConfig.GeofenceRadiusMeters
+
+	if evidence.AccuracyMeters != nil && s.checkInConfig.MaxAccuracyMeters > 0 &&
+		*evidence.AccuracyMeters > s.checkInConfig.MaxAccuracyMeters {
+		verification.Reason = fmt.Sprintf("device location accuracy of %.0f m is too low", *evidence.AccuracyMeters)
+		return verification
+	}
+
+	point, distance, ok := s.referencePoint(ctx, visit, *evidence.Latitude, *evidence.Longitude)
+	if !ok {
+		verification.Reason = fmt.Sprintf("no %s location recorded to check against", point)
+		return verification
+	}
+
+	verification.ReferencePoint = point
+	verification.DistanceMeters = &distance
+
+	if distance <= s.checkInConfig.GeofenceRadiusMeters {
+		verification.Verified = true
+	} else {
+		verification.Reason = fmt.Sprintf("device was %.0f m from the visit's %s", distance, point)
+	}
+
+	return verification
+}
+
+// referencePoint returns where the visit takes place, the mother's home for a home
+// visit or the visit facility otherwise, with the device's distance from it in meters.
+// It returns false if that location is not recorded.
+func (s *Service) referencePoint(
+	ctx context.Context,
+	visit *model.Visit,
+	lat, lng float64,
+) (string, float64, bool) {
+	if visit.IsHomeVisit() {
+		mother, err := s.motherRepo.GetByID(ctx, visit.MotherID)
+		if err != nil || mother.Location == nil {
+			return "home", 0, false
+		}
+		return "home", s.distanceMeters(lat, lng, mother.Location.Latitude, mother.Location.Longitude), true
+	}
+
+	facility, err := s.facilityRepo.GetByID(ctx, visit.FacilityID)
+	if err != nil {
+		return "facility", 0, false
+	}
+	return "facility", s.distanceMeters(lat, lng, facility.Location.Latitude, facility.Location.Longitude), true
+}
+
+// validCheckInCode checks a code or QR payload, allowing one step of clock drift either way
+func (s *Service) validCheckInCode(visitID uuid.UUID, code string, now time.Time) bool {
+	if s.checkInConfig.CodeSecret == "" {
+		return false
+	}
+
+	code = strings.TrimSpace(code)
+	if strings.HasPrefix(code, checkInQRPrefix+":") {
+		parts := strings.Split(code, ":")
+		if len(parts) != 3 || parts[1] != visitID.String() {
+			return false
+		}
+		code = parts[2]
+	}
+
+	counter := s.codeCounter(now)
+	for _, c := range []uint64{counter - 1, counter, counter + 1} {
+		if hmac.Equal([]byte(code), []byte(s.checkInCode(visitID, c))) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// codeCounter returns the time step used to rotate codes
+func (s *Service) codeCounter(t time.Time) uint64 {
+	step := int64(s.checkInConfig.CodeStep / time.Second)
+	if step <= 0 {
+		step = 300
+	}
+	return uint64(t.Unix() / step)
+}
+
+// checkInCode computes the TOTP-style code (RFC 4226 truncation) for a visit and time step
+func (s *Service) checkInCode(visitID uuid.UUID, counter uint64) string {
+	msg := make([]byte, 0, len(visitID)+8)
+	msg = append(msg, visitID[:]...)
+	msg = binary.BigEndian.AppendUint64(msg, counter)
+
+	mac := hmac.New(sha1.New, []byte(s.checkInConfig.CodeSecret))
+	mac.Write(msg)
+	sum := mac.Sum(nil)
+
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+
+	digits := s.checkInConfig.CodeDigits
+	if digits <= 0 {
+		digits = 6
+	}
+	return fmt.Sprintf("%0*d", digits, value%uint32(math.Pow10(digits)))
+}
+
+// isAssigned checks if a user is the CHW or clinician assigned to a visit. A facility
+// visit no clinician has been assigned to can be checked in by the facility's health
+// workers; a home visit only by its CHW.
+func (s *Service) isAssigned(ctx context.Context, visit *model.Visit, userID uuid.UUID) bool {
+	if visit.CHWID != nil && *visit.CHWID == userID {
+		return true
+	}
+	if visit.ClinicianID != nil {
+		return *visit.ClinicianID == userID
+	}
+	if visit.IsHomeVisit() {
+		return false
+	}
+
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		s.log.Warn("Failed to find user checking in visit", logger.Fields{
+			"error":    err.Error(),
+			"user_id":  userID.String(),
+			"visit_id": visit.ID.String(),
+		})
+		return false
+	}
+	return user.IsHealthcareProvider() && user.FacilityID != nil && *user.FacilityID == visit.FacilityID
+}
+
+// distanceMeters returns the distance between two coordinates in meters
+func (s *Service) distanceMeters(lat1, lng1, lat2, lng2 float64) float64 {
+	return s.locationService.CalculateDistance(lat1, lng1, lat2, lng2) * 1000
+}
@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/mamacare/services/internal/app/geo/location"
 	"github.com/mamacare/services/internal/domain/model"
 	"github.com/mamacare/services/internal/domain/repository"
 	"github.com/mamacare/services/pkg/errorx"
@@ -13,11 +14,13 @@ import (
 
 // Service provides visit status tracking functionality
 type Service struct {
-	visitRepo    repository.VisitRepository
-	motherRepo   repository.MotherRepository
-	userRepo     repository.UserRepository
-	facilityRepo repository.FacilityRepository
-	log          logger.Logger
+	visitRepo       repository.VisitRepository
+	motherRepo      repository.MotherRepository
+	userRepo        repository.UserRepository
+	facilityRepo    repository.FacilityRepository
+	locationService *location.Service
+	checkInConfig   CheckInConfig
+	log             logger.Logger
 }
 
 // NewService creates a new visit status service
@@ -26,21 +29,27 @@ func NewService(
 	motherRepo repository.MotherRepository,
 	userRepo repository.UserRepository,
 	facilityRepo repository.FacilityRepository,
+	locationService *location.Service,
+	checkInConfig CheckInConfig,
 	log logger.Logger,
 ) *Service {
 	return &Service{
-		visitRepo:    visitRepo,
-		motherRepo:   motherRepo,
-		userRepo:     userRepo,
-		facilityRepo: facilityRepo,
-		log:          log,
+		visitRepo:       visitRepo,
+		motherRepo:      motherRepo,
+		userRepo:        userRepo,
+		facilityRepo:    facilityRepo,
+		locationService: locationService,
+		checkInConfig:   checkInConfig,
+		log:             log,
 	}
 }
 
-// CheckInVisit marks a visit as checked in
+// CheckInVisit marks a visit as checked in, verifying the CHW's presence from the
+// device location or the mother's rotating check-in code
 func (s *Service) CheckInVisit(
 	ctx context.Context,
 	visitID uuid.UUID,
+	evidence *CheckInEvidence,
 ) (*model.Visit, error) {
 	// Retrieve visit
 	visit, err := s.visitRepo.GetByID(ctx, visitID)
@@ -57,8 +66,15 @@ func (s *Service) CheckInVisit(
 		return nil, errorx.Newf(errorx.BadRequest, "cannot check in visit with status %s", visit.Status)
 	}
 
-	// Check in visit
-	visit.CheckIn()
+	// Only the CHW or clinician assigned to the visit, or the facility's health workers
+	// for an unassigned facility visit, can check it in
+	if evidence == nil || !s.isAssigned(ctx, visit, evidence.CheckedInByID) {
+		return nil, errorx.New(errorx.Forbidden, "only the health worker assigned to this visit can check it in")
+	}
+
+	// Check in visit; unverified check-ins are accepted but flagged for audit
+	verification := s.verifyCheckIn(ctx, visit, evidence)
+	visit.CheckInWithVerification(verification)
 
 	// Update visit
 	if err := s.visitRepo.Update(ctx, visit); err != nil {
@@ -75,8 +91,19 @@ func (s *Service) CheckInVisit(
 		"facility_id":  visit.FacilityID.String(),
 		"check_in_time": visit.CheckInTime.Format(time.RFC3339),
 		"visit_type":   string(visit.VisitType),
+		"method":       string(verification.Method),
+		"verified":     verification.Verified,
 	})
 
+	if !verification.Verified {
+		s.log.Warn("Visit check-in could not be verified", logger.Fields{
+			"visit_id": visitID.String(),
+			"chw_id":   uuidString(visit.CHWID),
+			"method":   string(verification.Method),
+			"reason":   verification.Reason,
+		})
+	}
+
 	return visit, nil
 }
 
@@ -134,20 +161,21 @@ func (s *Service) MarkMissedVisits(ctx context.Context) (int, error) {
 	yesterday := now.AddDate(0, 0, -1)
 	endOfYesterday := time.Date(yesterday.Year(), yesterday.Month(), yesterday.Day(), 23, 59, 59, 0, yesterday.Location())
 	
-	// Create a date 7 days ago for limiting the search window
-	sevenDaysAgo := now.AddDate(0, 0, -7)
+	// Create a date 14 days ago for limiting the search window; postnatal contacts
+	// stay open for up to a week after their scheduled time
+	twoWeeksAgo := now.AddDate(0, 0, -14)
 	
-	// Find scheduled visits between 7 days ago and yesterday that weren't checked in
+	// Find scheduled visits between 14 days ago and yesterday that weren't checked in
 	options := repository.NewVisitQueryOptions().
 		WithStatus(model.VisitStatusScheduled).
-		WithDateRange(sevenDaysAgo, endOfYesterday).
+		WithDateRange(twoWeeksAgo, endOfYesterday).
 		WithLimit(100) // Process in batches
 
-	visits, err := s.visitRepo.GetByDateRange(ctx, sevenDaysAgo, endOfYesterday, options)
+	visits, err := s.visitRepo.GetByDateRange(ctx, twoWeeksAgo, endOfYesterday, options)
 	if err != nil {
 		s.log.Error("Failed to get scheduled visits to mark as missed", logger.Fields{
 			"error": err.Error(),
-			"from":  sevenDaysAgo.Format(time.RFC3339),
+			"from":  twoWeeksAgo.Format(time.RFC3339),
 			"to":    endOfYesterday.Format(time.RFC3339),
 		})
 		return 0, errorx.Wrap(err, "failed to get scheduled visits")
@@ -165,6 +193,11 @@ func (s *Service) MarkMissedVisits(ctx context.Context) (int, error) {
 		if visit.Status == model.VisitStatusCancelled {
 			continue
 		}
+
+		// Skip visits whose window is still open (e.g. postnatal contacts)
+		if !visit.IsMissed(endOfYesterday) {
+			continue
+		}
 		
 		// Mark as missed
 		visit.Status = model.VisitStatusCancelled // For now we'll use cancelled, but add a note
@@ -189,7 +222,7 @@ func (s *Service) MarkMissedVisits(ctx context.Context) (int, error) {
 		"total_visits": len(visits),
 		"missed_count": missedCount,
 		"error_count":  errorCount,
-		"date_range":   sevenDaysAgo.Format("2006-01-02") + " to " + endOfYesterday.Format("2006-01-02"),
+		"date_range":   twoWeeksAgo.Format("2006-01-02") + " to " + endOfYesterday.Format("2006-01-02"),
 	})
 
 	if errorCount > 0 {
@@ -267,7 +300,8 @@ func (s *Service) GetOverdueVisits(
 	// Create a date for the start of the window (e.g., 30 days ago to avoid very old visits)
 	thirtyDaysAgo := now.AddDate(0, 0, -30)
 	
-	// Find scheduled visits between 30 days ago and now that weren't checked in
+	// Find visits scheduled between 30 days ago and now whose window has closed without a
+	// check-in; the repository applies the deadline so pages hold only overdue visits
 	options := repository.NewVisitQueryOptions().
 		WithStatus(model.VisitStatusScheduled).
 		WithDateRange(thirtyDaysAgo, now).
@@ -275,7 +309,7 @@ func (s *Service) GetOverdueVisits(
 		WithOffset(offset).
 		WithOrder("scheduled_time", "ASC")
 
-	visits, err := s.visitRepo.GetByDateRange(ctx, thirtyDaysAgo, now, options)
+	visits, err := s.visitRepo.GetOverdue(ctx, options)
 	if err != nil {
 		s.log.Error("Failed to get overdue visits", logger.Fields{
 			"error": err.Error(),
@@ -436,3 +470,11 @@ func (s *Service) UpdateVisitNotes(
 
 	return visit, nil
 }
+
+// uuidString formats an optional UUID for logging
+func uuidString(id *uuid.UUID) string {
+	if id == nil {
+		return ""
+	}
+	return id.String()
+}
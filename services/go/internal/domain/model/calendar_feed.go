@@ -0,0 +1,62 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// CalendarFeedScope represents whose visits a calendar feed publishes
+type CalendarFeedScope string
+
+const (
+	// CalendarFeedScopeUser represents a feed of visits assigned to a CHW or clinician
+	CalendarFeedScopeUser CalendarFeedScope = "user"
+	// CalendarFeedScopeFacility represents a feed of all visits at a facility
+	CalendarFeedScopeFacility CalendarFeedScope = "facility"
+)
+
+// CalendarFeed represents a subscribable iCalendar feed of visits
+type CalendarFeed struct {
+	ID             uuid.UUID         `json:"id"`
+	Scope          CalendarFeedScope `json:"scope"`
+	OwnerID        uuid.UUID         `json:"owner_id"` // user or facility ID depending on scope
+	Name           string            `json:"name"`
+	CreatedByID    uuid.UUID         `json:"created_by_id"`
+	RevokedAt      *time.Time        `json:"revoked_at,omitempty"`
+	LastAccessedAt *time.Time        `json:"last_accessed_at,omitempty"`
+	CreatedAt      time.Time         `json:"created_at"`
+	UpdatedAt      time.Time         `json:"updated_at"`
+}
+
+// NewCalendarFeed creates a new calendar feed
+func NewCalendarFeed(id uuid.UUID, scope CalendarFeedScope, ownerID, createdByID uuid.UUID, name string) *CalendarFeed {
+	now := time.Now()
+	return &CalendarFeed{
+		ID:          id,
+		Scope:       scope,
+		OwnerID:     ownerID,
+		Name:        name,
+		CreatedByID: createdByID,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+}
+
+// IsActive checks if the feed can still be fetched
+func (f *CalendarFeed) IsActive() bool {
+	return f.RevokedAt == nil
+}
+
+// Revoke revokes the feed so its URL stops working
+func (f *CalendarFeed) Revoke() {
+	now := time.Now()
+	f.RevokedAt = &now
+	f.UpdatedAt = now
+}
+
+// MarkAccessed records that a calendar client fetched the feed
+func (f *CalendarFeed) MarkAccessed() {
+	now := time.Now()
+	f.LastAccessedAt = &now
+}
@@ -0,0 +1,223 @@
+package model
+
+import (
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ChildDeliveryType represents how a child was delivered, as stored on the child record
+type ChildDeliveryType string
+
+const (
+	// ChildDeliveryVaginal represents a vaginal delivery
+	ChildDeliveryVaginal ChildDeliveryType = "VAGINAL"
+	// ChildDeliveryCSection represents a caesarean section
+	ChildDeliveryCSection ChildDeliveryType = "C_SECTION"
+	// ChildDeliveryAssisted represents an assisted vaginal delivery
+	ChildDeliveryAssisted ChildDeliveryType = "ASSISTED"
+)
+
+// ChildDeliveryTypeFromMode converts a delivery outcome's mode to the child's delivery type
+func ChildDeliveryTypeFromMode(mode DeliveryMode) ChildDeliveryType {
+	switch mode {
+	case DeliveryModeCaesarean:
+		return ChildDeliveryCSection
+	case DeliveryModeAssisted:
+		return ChildDeliveryAssisted
+	default:
+		return ChildDeliveryVaginal
+	}
+}
+
+// GrowthStatus represents a child's growth classification against WHO standards
+type GrowthStatus string
+
+const (
+	// GrowthStatusNormal represents normal growth
+	GrowthStatusNormal GrowthStatus = "NORMAL"
+	// GrowthStatusUnderweight represents low weight for age
+	GrowthStatusUnderweight GrowthStatus = "UNDERWEIGHT"
+	// GrowthStatusOverweight represents high weight for height
+	GrowthStatusOverweight GrowthStatus = "OVERWEIGHT"
+	// GrowthStatusStunted represents low height for age
+	GrowthStatusStunted GrowthStatus = "STUNTED"
+	// GrowthStatusWasted represents low weight for height
+	GrowthStatusWasted GrowthStatus = "WASTED"
+)
+
+// Child represents a child registered in the system. MotherID and FatherID are user
+// IDs, matching the children table and its row-level security policies; a father who
+// is not a user is recorded by name.
+type Child struct {
+	ID                  uuid.UUID         `json:"id"`
+	FirstName           string            `json:"first_name"`
+	MiddleName          string            `json:"middle_name,omitempty"`
+	LastName            string            `json:"last_name"`
+	Sex                 string            `json:"sex,omitempty"`
+	DateOfBirth         time.Time         `json:"date_of_birth"`
+	BirthWeightGrams    *int              `json:"birth_weight_grams,omitempty"`
+	BirthLengthCm       *int              `json:"birth_length_cm,omitempty"`
+	PlaceOfBirth        string            `json:"place_of_birth,omitempty"`
+	DeliveryType        ChildDeliveryType `json:"delivery_type,omitempty"`
+	GestationalAgeWeeks *int              `json:"gestational_age_weeks,omitempty"`
+	DeliveryOutcomeID   *uuid.UUID        `json:"delivery_outcome_id,omitempty"`
+	MotherID            *uuid.UUID        `json:"mother_id,omitempty"`
+	FatherID            *uuid.UUID        `json:"father_id,omitempty"`
+	FatherName          string            `json:"father_name,omitempty"`
+	CHWID               *uuid.UUID        `json:"chw_id,omitempty"`
+	BloodType           string            `json:"blood_type,omitempty"`
+	Allergies           string            `json:"allergies,omitempty"`
+	Disabilities        string            `json:"disabilities,omitempty"`
+	ChronicConditions   string            `json:"chronic_conditions,omitempty"`
+	CurrentGrowthStatus *GrowthStatus     `json:"current_growth_status,omitempty"`
+	IsActive            bool              `json:"is_active"`
+	MergedIntoID        *uuid.UUID        `json:"merged_into_id,omitempty"`
+	DeactivationReason  string            `json:"deactivation_reason,omitempty"`
+	CreatedAt           time.Time         `json:"created_at"`
+	UpdatedAt           time.Time         `json:"updated_at"`
+}
+
+// NewChild creates a new active child record
+func NewChild(id uuid.UUID, firstName, lastName string, dateOfBirth time.Time) *Child {
+	now := time.Now()
+	return &Child{
+		ID:          id,
+		FirstName:   firstName,
+		LastName:    lastName,
+		DateOfBirth: dateOfBirth,
+		IsActive:    true,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+}
+
+// WithMother links the child to the mother's user account
+func (c *Child) WithMother(userID uuid.UUID) *Child {
+	c.MotherID = &userID
+	return c
+}
+
+// WithFather links the child to the father's user account
+func (c *Child) WithFather(userID uuid.UUID) *Child {
+	c.FatherID = &userID
+	return c
+}
+
+// WithFatherName records the father by name when he is not a user
+func (c *Child) WithFatherName(name string) *Child {
+	c.FatherName = name
+	return c
+}
+
+// WithBirthDetails sets the birth measurements and gestational age at birth
+func (c *Child) WithBirthDetails(weightGrams, lengthCm, gestationalAgeWeeks int) *Child {
+	if weightGrams > 0 {
+		c.BirthWeightGrams = &weightGrams
+	}
+	if lengthCm > 0 {
+		c.BirthLengthCm = &lengthCm
+	}
+	if gestationalAgeWeeks > 0 {
+		c.GestationalAgeWeeks = &gestationalAgeWeeks
+	}
+	return c
+}
+
+// WithDeliveryOutcome links the child to the delivery outcome they were registered from
+func (c *Child) WithDeliveryOutcome(outcome *DeliveryOutcome) *Child {
+	c.DeliveryOutcomeID = &outcome.ID
+	c.DeliveryType = ChildDeliveryTypeFromMode(outcome.Mode)
+	c.PlaceOfBirth = string(outcome.Place)
+	return c
+}
+
+// FullName returns the child's names joined together
+func (c *Child) FullName() string {
+	return strings.Join(strings.Fields(c.FirstName+" "+c.MiddleName+" "+c.LastName), " ")
+}
+
+// HasParent checks if the child is linked to at least one parent, as the children table requires
+func (c *Child) HasParent() bool {
+	return c.MotherID != nil || c.FatherID != nil || c.FatherName != ""
+}
+
+// IsParent checks if the user is the child's mother or father
+func (c *Child) IsParent(userID uuid.UUID) bool {
+	return (c.MotherID != nil && *c.MotherID == userID) || (c.FatherID != nil && *c.FatherID == userID)
+}
+
+// AgeInDays returns the child's age in completed days at the reference date
+func (c *Child) AgeInDays(referenceDate time.Time) int {
+	return int(referenceDate.Sub(c.DateOfBirth).Hours() / 24)
+}
+
+// Deactivate marks the child record inactive
+func (c *Child) Deactivate(reason string) {
+	c.IsActive = false
+	c.DeactivationReason = reason
+	c.UpdatedAt = time.Now()
+}
+
+// MergeInto deactivates the record as a duplicate of another child
+func (c *Child) MergeInto(primaryID uuid.UUID) {
+	c.MergedIntoID = &primaryID
+	c.Deactivate("duplicate of " + primaryID.String())
+}
+
+// FillFrom copies details the child is missing from a duplicate record of the same child
+func (c *Child) FillFrom(duplicate *Child) {
+	if c.MiddleName == "" {
+		c.MiddleName = duplicate.MiddleName
+	}
+	if c.Sex == "" {
+		c.Sex = duplicate.Sex
+	}
+	if c.BirthWeightGrams == nil {
+		c.BirthWeightGrams = duplicate.BirthWeightGrams
+	}
+	if c.BirthLengthCm == nil {
+		c.BirthLengthCm = duplicate.BirthLengthCm
+	}
+	if c.PlaceOfBirth == "" {
+		c.PlaceOfBirth = duplicate.PlaceOfBirth
+	}
+	if c.DeliveryType == "" {
+		c.DeliveryType = duplicate.DeliveryType
+	}
+	if c.GestationalAgeWeeks == nil {
+		c.GestationalAgeWeeks = duplicate.GestationalAgeWeeks
+	}
+	if c.DeliveryOutcomeID == nil {
+		c.DeliveryOutcomeID = duplicate.DeliveryOutcomeID
+	}
+	if c.MotherID == nil {
+		c.MotherID = duplicate.MotherID
+	}
+	if c.FatherID == nil {
+		c.FatherID = duplicate.FatherID
+	}
+	if c.FatherName == "" {
+		c.FatherName = duplicate.FatherName
+	}
+	if c.CHWID == nil {
+		c.CHWID = duplicate.CHWID
+	}
+	if c.BloodType == "" {
+		c.BloodType = duplicate.BloodType
+	}
+	if c.Allergies == "" {
+		c.Allergies = duplicate.Allergies
+	}
+	if c.Disabilities == "" {
+		c.Disabilities = duplicate.Disabilities
+	}
+	if c.ChronicConditions == "" {
+		c.ChronicConditions = duplicate.ChronicConditions
+	}
+	if c.CurrentGrowthStatus == nil {
+		c.CurrentGrowthStatus = duplicate.CurrentGrowthStatus
+	}
+	c.UpdatedAt = time.Now()
+}
@@ -0,0 +1,217 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Community management of acute malnutrition (CMAM) admission and discharge thresholds
+const (
+	// CMAMSevereMUACCm is the arm circumference below which a child is severely malnourished
+	CMAMSevereMUACCm = 11.5
+	// CMAMSevereWHZ is the weight-for-height z-score below which a child is severely malnourished
+	CMAMSevereWHZ = -3.0
+	// CMAMDischargeMUACCm is the arm circumference a child admitted on MUAC must reach to be cured
+	CMAMDischargeMUACCm = 12.5
+	// CMAMDischargeWHZ is the weight-for-height z-score a child admitted on WHZ must reach to be cured
+	CMAMDischargeWHZ = -2.0
+	// CMAMTargetWeightGain is the fraction of admission weight a child is expected to gain before discharge
+	CMAMTargetWeightGain = 0.15
+)
+
+// CMAMProgramme is where a severely malnourished child is treated
+type CMAMProgramme string
+
+const (
+	// CMAMProgrammeOTP is the outpatient therapeutic programme, with weekly RUTF rations taken home
+	CMAMProgrammeOTP CMAMProgramme = "otp"
+	// CMAMProgrammeSC is the inpatient stabilisation centre for children with complications
+	CMAMProgrammeSC CMAMProgramme = "sc"
+)
+
+// IsValid checks if the programme is known
+func (p CMAMProgramme) IsValid() bool {
+	return p == CMAMProgrammeOTP || p == CMAMProgrammeSC
+}
+
+// CMAMCriterion is an admission criterion for severe acute malnutrition
+type CMAMCriterion string
+
+const (
+	// CMAMCriterionMUAC means arm circumference was below 11.5 cm
+	CMAMCriterionMUAC CMAMCriterion = "muac"
+	// CMAMCriterionWHZ means weight-for-height was below -3 z-scores
+	CMAMCriterionWHZ CMAMCriterion = "whz"
+	// CMAMCriterionOedema means the child had bilateral pitting oedema
+	CMAMCriterionOedema CMAMCriterion = "oedema"
+)
+
+// CMAMStatus is the state of a child's CMAM enrollment. The statuses after active are
+// the programme outcomes reported per facility.
+type CMAMStatus string
+
+const (
+	// CMAMStatusReferred means a growth measurement showed acute malnutrition and the child
+	// is waiting to be assessed for admission
+	CMAMStatusReferred CMAMStatus = "referred"
+	// CMAMStatusNotAdmitted means the child was assessed and did not need treatment
+	CMAMStatusNotAdmitted CMAMStatus = "not_admitted"
+	// CMAMStatusActive means the child is in treatment
+	CMAMStatusActive CMAMStatus = "active"
+	// CMAMStatusCured means the child met the discharge criteria
+	CMAMStatusCured CMAMStatus = "cured"
+	// CMAMStatusDefaulted means the child missed two follow-ups in a row
+	CMAMStatusDefaulted CMAMStatus = "defaulted"
+	// CMAMStatusDied means the child died in treatment
+	CMAMStatusDied CMAMStatus = "died"
+	// CMAMStatusNonResponder means the child did not meet the discharge criteria in time
+	CMAMStatusNonResponder CMAMStatus = "non_responder"
+)
+
+// IsOutcome checks if the status ends treatment
+func (s CMAMStatus) IsOutcome() bool {
+	switch s {
+	case CMAMStatusCured, CMAMStatusDefaulted, CMAMStatusDied, CMAMStatusNonResponder:
+		return true
+	default:
+		return false
+	}
+}
+
+// CMAMEnrollment is a child's referral to and treatment for severe acute malnutrition
+type CMAMEnrollment struct {
+	ID         uuid.UUID     `json:"id"`
+	ChildID    uuid.UUID     `json:"child_id"`
+	FacilityID *uuid.UUID    `json:"facility_id,omitempty"`
+	Programme  CMAMProgramme `json:"programme,omitempty"`
+	Status     CMAMStatus    `json:"status"`
+	// GrowthMeasurementID is the measurement the child was referred from, if any
+	GrowthMeasurementID *uuid.UUID `json:"growth_measurement_id,omitempty"`
+	ReferredAt          time.Time  `json:"referred_at"`
+
+	AdmittedAt           *time.Time      `json:"admitted_at,omitempty"`
+	AdmissionCriteria    []CMAMCriterion `json:"admission_criteria"`
+	AdmissionWeightGrams *int            `json:"admission_weight_grams,omitempty"`
+	AdmissionHeightCm    *float64        `json:"admission_height_cm,omitempty"`
+	AdmissionMUACCm      *float64        `json:"admission_muac_cm,omitempty"`
+	AdmissionWHZ         *float64        `json:"admission_whz,omitempty"`
+	// AdmissionOedemaGrade is bilateral pitting oedema from 0 (none) to 3 (+++)
+	AdmissionOedemaGrade int  `json:"admission_oedema_grade"`
+	Complications        bool `json:"complications"`
+	// TargetWeightGrams is the admission weight plus 15%
+	TargetWeightGrams *int       `json:"target_weight_grams,omitempty"`
+	AdmittedByID      *uuid.UUID `json:"admitted_by_id,omitempty"`
+
+	ExitedAt        *time.Time `json:"exited_at,omitempty"`
+	ExitWeightGrams *int       `json:"exit_weight_grams,omitempty"`
+	ExitNotes       string     `json:"exit_notes,omitempty"`
+	CreatedAt       time.Time  `json:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at"`
+}
+
+// NewCMAMReferral creates a referral for a child to be assessed for CMAM admission
+func NewCMAMReferral(childID uuid.UUID, referredAt time.Time) *CMAMEnrollment {
+	now := time.Now()
+	return &CMAMEnrollment{
+		ID:                uuid.New(),
+		ChildID:           childID,
+		Status:            CMAMStatusReferred,
+		ReferredAt:        referredAt,
+		AdmissionCriteria: []CMAMCriterion{},
+		CreatedAt:         now,
+		UpdatedAt:         now,
+	}
+}
+
+// IsOpen checks if the child is still referred or in treatment
+func (e *CMAMEnrollment) IsOpen() bool {
+	return e.Status == CMAMStatusReferred || e.Status == CMAMStatusActive
+}
+
+// HasCriterion checks if the child was admitted on the criterion
+func (e *CMAMEnrollment) HasCriterion(criterion CMAMCriterion) bool {
+	for _, c := range e.AdmissionCriteria {
+		if c == criterion {
+			return true
+		}
+	}
+	return false
+}
+
+// Exit closes the enrollment with an outcome
+func (e *CMAMEnrollment) Exit(status CMAMStatus, exitedAt time.Time, weightGrams *int, notes string) {
+	e.Status = status
+	e.ExitedAt = &exitedAt
+	e.ExitWeightGrams = weightGrams
+	e.ExitNotes = notes
+	e.UpdatedAt = time.Now()
+}
+
+// CMAMFollowUpStatus is the state of a weekly CMAM follow-up
+type CMAMFollowUpStatus string
+
+const (
+	// CMAMFollowUpScheduled means the follow-up is due
+	CMAMFollowUpScheduled CMAMFollowUpStatus = "scheduled"
+	// CMAMFollowUpAttended means the child was seen
+	CMAMFollowUpAttended CMAMFollowUpStatus = "attended"
+	// CMAMFollowUpMissed means the child did not come
+	CMAMFollowUpMissed CMAMFollowUpStatus = "missed"
+)
+
+// CMAMFollowUp is a weekly follow-up of a child in CMAM treatment, where progress is
+// measured and the next week's RUTF ration is dispensed
+type CMAMFollowUp struct {
+	ID            uuid.UUID          `json:"id"`
+	EnrollmentID  uuid.UUID          `json:"enrollment_id"`
+	Week          int                `json:"week"`
+	ScheduledDate time.Time          `json:"scheduled_date"`
+	Status        CMAMFollowUpStatus `json:"status"`
+	VisitedAt     *time.Time         `json:"visited_at,omitempty"`
+	WeightGrams   *int               `json:"weight_grams,omitempty"`
+	HeightCm      *float64           `json:"height_cm,omitempty"`
+	MUACCm        *float64           `json:"muac_cm,omitempty"`
+	WHZ           *float64           `json:"whz,omitempty"`
+	OedemaGrade   *int               `json:"oedema_grade,omitempty"`
+	// RUTFSachets is the ready-to-use therapeutic food handed out for the coming week
+	RUTFSachets  int        `json:"rutf_sachets"`
+	RecordedByID *uuid.UUID `json:"recorded_by_id,omitempty"`
+	Notes        string     `json:"notes,omitempty"`
+	CreatedAt    time.Time  `json:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at"`
+}
+
+// NewCMAMFollowUp schedules a weekly follow-up for an enrollment
+func NewCMAMFollowUp(enrollmentID uuid.UUID, week int, scheduledDate time.Time) *CMAMFollowUp {
+	now := time.Now()
+	return &CMAMFollowUp{
+		ID:            uuid.New(),
+		EnrollmentID:  enrollmentID,
+		Week:          week,
+		ScheduledDate: scheduledDate,
+		Status:        CMAMFollowUpScheduled,
+		CreatedAt:     now,
+		UpdatedAt:     now,
+	}
+}
+
+// CMAMFacilityOutcomes is the count of CMAM exits by outcome at a facility over a period
+type CMAMFacilityOutcomes struct {
+	FacilityID   uuid.UUID     `json:"facility_id"`
+	FacilityName string        `json:"facility_name"`
+	Programme    CMAMProgramme `json:"programme"`
+	Admissions   int           `json:"admissions"`
+	Cured        int           `json:"cured"`
+	Defaulted    int           `json:"defaulted"`
+	Died         int           `json:"died"`
+	NonResponder int           `json:"non_responder"`
+	// MeanLengthOfStayDays and MeanWeightGainGKgDay are over the children who were cured
+	MeanLengthOfStayDays *float64 `json:"mean_length_of_stay_days,omitempty"`
+	MeanWeightGainGKgDay *float64 `json:"mean_weight_gain_g_kg_day,omitempty"`
+}
+
+// Exits returns the number of children who left treatment
+func (o *CMAMFacilityOutcomes) Exits() int {
+	return o.Cured + o.Defaulted + o.Died + o.NonResponder
+}
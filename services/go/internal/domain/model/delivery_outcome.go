@@ -0,0 +1,178 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// DeliveryPlace represents where a birth took place
+type DeliveryPlace string
+
+const (
+	// DeliveryPlaceFacility represents a birth at a health facility
+	DeliveryPlaceFacility DeliveryPlace = "facility"
+	// DeliveryPlaceHome represents a birth at home
+	DeliveryPlaceHome DeliveryPlace = "home"
+	// DeliveryPlaceInTransit represents a birth on the way to a facility
+	DeliveryPlaceInTransit DeliveryPlace = "in_transit"
+	// DeliveryPlaceOther represents a birth elsewhere
+	DeliveryPlaceOther DeliveryPlace = "other"
+)
+
+// DeliveryMode represents how the baby was delivered
+type DeliveryMode string
+
+const (
+	// DeliveryModeVaginal represents a spontaneous vaginal delivery
+	DeliveryModeVaginal DeliveryMode = "vaginal"
+	// DeliveryModeAssisted represents an assisted vaginal delivery (vacuum or forceps)
+	DeliveryModeAssisted DeliveryMode = "assisted"
+	// DeliveryModeCaesarean represents a caesarean section
+	DeliveryModeCaesarean DeliveryMode = "caesarean"
+)
+
+// MaternalOutcome represents the condition of the mother after delivery
+type MaternalOutcome string
+
+const (
+	// MaternalOutcomeWell represents a mother who is well after delivery
+	MaternalOutcomeWell MaternalOutcome = "well"
+	// MaternalOutcomeComplications represents a mother with complications after delivery
+	MaternalOutcomeComplications MaternalOutcome = "complications"
+	// MaternalOutcomeReferred represents a mother referred for further care
+	MaternalOutcomeReferred MaternalOutcome = "referred"
+	// MaternalOutcomeDied represents a maternal death
+	MaternalOutcomeDied MaternalOutcome = "died"
+)
+
+// BirthOutcome represents the outcome for a baby
+type BirthOutcome string
+
+const (
+	// BirthOutcomeLiveBirth represents a live birth
+	BirthOutcomeLiveBirth BirthOutcome = "live_birth"
+	// BirthOutcomeFreshStillbirth represents a fresh stillbirth
+	BirthOutcomeFreshStillbirth BirthOutcome = "fresh_stillbirth"
+	// BirthOutcomeMaceratedStillbirth represents a macerated stillbirth
+	BirthOutcomeMaceratedStillbirth BirthOutcome = "macerated_stillbirth"
+	// BirthOutcomeNeonatalDeath represents a baby who died shortly after birth
+	BirthOutcomeNeonatalDeath BirthOutcome = "neonatal_death"
+)
+
+// NewbornOutcome records the outcome for a single baby
+type NewbornOutcome struct {
+	ID               uuid.UUID    `json:"id"` // reused as the child ID when the newborn is registered
+	Outcome          BirthOutcome `json:"outcome"`
+	Sex              string       `json:"sex,omitempty"`
+	BirthWeightGrams int          `json:"birth_weight_grams,omitempty"`
+	Notes            string       `json:"notes,omitempty"`
+}
+
+// IsAlive checks if the newborn was born alive and survived delivery
+func (n NewbornOutcome) IsAlive() bool {
+	return n.Outcome == BirthOutcomeLiveBirth
+}
+
+// DeliveryOutcome records a birth and its outcome for mother and baby
+type DeliveryOutcome struct {
+	ID                    uuid.UUID        `json:"id"`
+	MotherID              uuid.UUID        `json:"mother_id"`
+	DeliveryDate          time.Time        `json:"delivery_date"`
+	Place                 DeliveryPlace    `json:"place"`
+	FacilityID            *uuid.UUID       `json:"facility_id,omitempty"`
+	Mode                  DeliveryMode     `json:"mode"`
+	GestationalAgeWeeks   int              `json:"gestational_age_weeks,omitempty"`
+	MaternalOutcome       MaternalOutcome  `json:"maternal_outcome"`
+	MaternalComplications []string         `json:"maternal_complications,omitempty"`
+	Newborns              []NewbornOutcome `json:"newborns"`
+	AttendedByID          *uuid.UUID       `json:"attended_by_id,omitempty"`
+	RecordedByID          uuid.UUID        `json:"recorded_by_id"`
+	Notes                 string           `json:"notes,omitempty"`
+	CreatedAt             time.Time        `json:"created_at"`
+	UpdatedAt             time.Time        `json:"updated_at"`
+}
+
+// NewDeliveryOutcome creates a new delivery outcome
+func NewDeliveryOutcome(
+	id, motherID, recordedByID uuid.UUID,
+	deliveryDate time.Time,
+	place DeliveryPlace,
+	mode DeliveryMode,
+	maternalOutcome MaternalOutcome,
+) *DeliveryOutcome {
+	now := time.Now()
+	return &DeliveryOutcome{
+		ID:                    id,
+		MotherID:              motherID,
+		DeliveryDate:          deliveryDate,
+		Place:                 place,
+		Mode:                  mode,
+		MaternalOutcome:       maternalOutcome,
+		MaternalComplications: []string{},
+		Newborns:              []NewbornOutcome{},
+		RecordedByID:          recordedByID,
+		CreatedAt:             now,
+		UpdatedAt:             now,
+	}
+}
+
+// WithFacility sets the facility where the delivery took place
+func (d *DeliveryOutcome) WithFacility(facilityID uuid.UUID) *DeliveryOutcome {
+	d.FacilityID = &facilityID
+	return d
+}
+
+// WithAttendant sets the health worker who attended the delivery
+func (d *DeliveryOutcome) WithAttendant(attendantID uuid.UUID) *DeliveryOutcome {
+	d.AttendedByID = &attendantID
+	return d
+}
+
+// WithGestationalAge sets the gestational age at delivery in weeks
+func (d *DeliveryOutcome) WithGestationalAge(weeks int) *DeliveryOutcome {
+	d.GestationalAgeWeeks = weeks
+	return d
+}
+
+// WithComplications sets the maternal complications
+func (d *DeliveryOutcome) WithComplications(complications []string) *DeliveryOutcome {
+	d.MaternalComplications = complications
+	return d
+}
+
+// WithNotes adds notes to the delivery outcome
+func (d *DeliveryOutcome) WithNotes(notes string) *DeliveryOutcome {
+	d.Notes = notes
+	return d
+}
+
+// AddNewborn adds a baby to the delivery outcome, assigning an ID if missing
+func (d *DeliveryOutcome) AddNewborn(newborn NewbornOutcome) *DeliveryOutcome {
+	if newborn.ID == uuid.Nil {
+		newborn.ID = uuid.New()
+	}
+	d.Newborns = append(d.Newborns, newborn)
+	return d
+}
+
+// LiveBirths returns the newborns who were born alive
+func (d *DeliveryOutcome) LiveBirths() []NewbornOutcome {
+	live := make([]NewbornOutcome, 0, len(d.Newborns))
+	for _, n := range d.Newborns {
+		if n.IsAlive() {
+			live = append(live, n)
+		}
+	}
+	return live
+}
+
+// IsMotherAlive checks if the mother survived the delivery
+func (d *DeliveryOutcome) IsMotherAlive() bool {
+	return d.MaternalOutcome != MaternalOutcomeDied
+}
+
+// IsPreterm checks if the delivery was before 37 completed weeks
+func (d *DeliveryOutcome) IsPreterm() bool {
+	return d.GestationalAgeWeeks > 0 && d.GestationalAgeWeeks < 37
+}
@@ -0,0 +1,174 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Postpartum family planning intervals, following the WHO medical eligibility criteria
+// and the national family planning guidelines
+const (
+	// FPInjectableIntervalDays is the time between DMPA injections (13 weeks)
+	FPInjectableIntervalDays = 91
+	// FPInjectableGraceDays is how late an injection can be given without backup contraception
+	FPInjectableGraceDays = 28
+	// FPPillCycleDays is the length of one cycle of pills
+	FPPillCycleDays = 28
+	// FPMaxPillCycles is the most cycles of pills given at one visit
+	FPMaxPillCycles = 13
+	// FPImplantYears is how long an implant lasts unless another duration is recorded
+	FPImplantYears = 3
+	// FPIUDYears is how long a copper IUD is replaced after
+	FPIUDYears = 10
+	// FPLAMMaxMonths is how long after delivery the lactational amenorrhoea method protects
+	FPLAMMaxMonths = 6
+	// FPPostpartumIUDDays is the window after delivery in which an IUD can be inserted, the
+	// first 48 hours counted in whole days
+	FPPostpartumIUDDays = 2
+	// FPIntervalIUDDays is how long after delivery an IUD can be inserted again once the
+	// immediate postpartum window has passed
+	FPIntervalIUDDays = 28
+	// FPPostpartumCounsellingDays is the postnatal period in which counselling is counted as postpartum
+	FPPostpartumCounsellingDays = 42
+	// FPPostpartumUptakeDays is the first year after delivery, in which a method is counted as postpartum uptake
+	FPPostpartumUptakeDays = 365
+	// FPBirthSpacingMonths is the interval from a birth to the next pregnancy WHO recommends
+	FPBirthSpacingMonths = 24
+)
+
+// FPMethod is a contraceptive method offered after delivery
+type FPMethod string
+
+const (
+	// FPMethodImplant is a contraceptive implant in the upper arm
+	FPMethodImplant FPMethod = "implant"
+	// FPMethodInjectable is a three-monthly DMPA injection
+	FPMethodInjectable FPMethod = "injectable"
+	// FPMethodIUD is a copper intrauterine device
+	FPMethodIUD FPMethod = "iud"
+	// FPMethodPills is progestogen-only pills while breastfeeding, or combined pills
+	FPMethodPills FPMethod = "pills"
+	// FPMethodLAM is the lactational amenorrhoea method: exclusive breastfeeding with no return of periods
+	FPMethodLAM FPMethod = "lam"
+)
+
+// IsValid checks if the method is known
+func (m FPMethod) IsValid() bool {
+	switch m {
+	case FPMethodImplant, FPMethodInjectable, FPMethodIUD, FPMethodPills, FPMethodLAM:
+		return true
+	}
+	return false
+}
+
+// NeedsResupply reports whether the method is given again at regular visits
+func (m FPMethod) NeedsResupply() bool {
+	return m == FPMethodInjectable || m == FPMethodPills
+}
+
+// FPTopic is a topic covered in family planning counselling
+type FPTopic string
+
+const (
+	// FPTopicBirthSpacing is waiting at least two years before the next pregnancy
+	FPTopicBirthSpacing FPTopic = "birth_spacing"
+	// FPTopicReturnToFertility is that fertility can return before periods do
+	FPTopicReturnToFertility FPTopic = "return_to_fertility"
+	// FPTopicLAM is the conditions under which breastfeeding protects against pregnancy
+	FPTopicLAM FPTopic = "lam"
+	// FPTopicMethodOptions is the methods available and which suit breastfeeding
+	FPTopicMethodOptions FPTopic = "method_options"
+	// FPTopicSideEffects is what to expect from the chosen method
+	FPTopicSideEffects FPTopic = "side_effects"
+	// FPTopicDualProtection is using condoms as well to prevent infections
+	FPTopicDualProtection FPTopic = "dual_protection"
+)
+
+// IsValid checks if the topic is known
+func (t FPTopic) IsValid() bool {
+	switch t {
+	case FPTopicBirthSpacing, FPTopicReturnToFertility, FPTopicLAM,
+		FPTopicMethodOptions, FPTopicSideEffects, FPTopicDualProtection:
+		return true
+	}
+	return false
+}
+
+// FPReminderKind distinguishes reminders for a re-supply coming due from overdue ones
+type FPReminderKind string
+
+const (
+	// FPReminderDue is sent when a re-supply or injection is coming due
+	FPReminderDue FPReminderKind = "due"
+	// FPReminderOverdue is sent once it is late
+	FPReminderOverdue FPReminderKind = "overdue"
+)
+
+// FamilyPlanningVisit records a family planning visit: counselling, the method the
+// mother chose, and the method given or re-supplied, or that she stopped using it
+type FamilyPlanningVisit struct {
+	ID         uuid.UUID `json:"id"`
+	MotherID   uuid.UUID `json:"mother_id"`
+	FacilityID uuid.UUID `json:"facility_id"`
+	VisitDate  time.Time `json:"visit_date"`
+	// PostpartumDays is the days since her last delivery, if she has delivered
+	PostpartumDays *int      `json:"postpartum_days,omitempty"`
+	Topics         []FPTopic `json:"topics"`
+	// Method is the method she chose, whether or not it was given at this visit
+	Method      *FPMethod `json:"method,omitempty"`
+	MethodGiven bool      `json:"method_given"`
+	// Resupply is whether the method given continues the one she was already using
+	Resupply bool `json:"resupply"`
+	// Quantity is the injections or pill cycles given
+	Quantity int `json:"quantity"`
+	// NextDueDate is when the method is next due to be re-supplied or replaced
+	NextDueDate           *time.Time `json:"next_due_date,omitempty"`
+	Discontinued          bool       `json:"discontinued"`
+	DiscontinuationReason string     `json:"discontinuation_reason,omitempty"`
+	DueReminderSentAt     *time.Time `json:"due_reminder_sent_at,omitempty"`
+	OverdueReminderSentAt *time.Time `json:"overdue_reminder_sent_at,omitempty"`
+	RecordedByID          uuid.UUID  `json:"recorded_by_id"`
+	Notes                 string     `json:"notes,omitempty"`
+	CreatedAt             time.Time  `json:"created_at"`
+	UpdatedAt             time.Time  `json:"updated_at"`
+}
+
+// NewFamilyPlanningVisit creates a new family planning visit
+func NewFamilyPlanningVisit(motherID, facilityID, recordedByID uuid.UUID, visitDate time.Time) *FamilyPlanningVisit {
+	now := time.Now()
+	return &FamilyPlanningVisit{
+		ID:           uuid.New(),
+		MotherID:     motherID,
+		FacilityID:   facilityID,
+		VisitDate:    visitDate,
+		Topics:       []FPTopic{},
+		RecordedByID: recordedByID,
+		CreatedAt:    now,
+		UpdatedAt:    now,
+	}
+}
+
+// Counselled reports whether the mother was counselled at the visit
+func (v *FamilyPlanningVisit) Counselled() bool {
+	return len(v.Topics) > 0
+}
+
+// FamilyPlanningCoverage is the count of deliveries and family planning services at a
+// facility over a period. Postpartum counts follow the women who delivered in the period.
+type FamilyPlanningCoverage struct {
+	FacilityID   uuid.UUID `json:"facility_id"`
+	FacilityName string    `json:"facility_name"`
+	District     string    `json:"district"`
+	Deliveries   int       `json:"deliveries"`
+	// CounselledPostpartum is the women who delivered and were counselled within 42 days
+	CounselledPostpartum int `json:"counselled_postpartum"`
+	// PostpartumUptake is the women who delivered and were given a method within a year
+	PostpartumUptake int `json:"postpartum_uptake"`
+	// CounsellingVisits, NewAcceptors, Resupplies and Discontinuations are the visits in the period
+	CounsellingVisits int              `json:"counselling_visits"`
+	NewAcceptors      int              `json:"new_acceptors"`
+	Resupplies        int              `json:"resupplies"`
+	Discontinuations  int              `json:"discontinuations"`
+	MethodsGiven      map[FPMethod]int `json:"methods_given"`
+}
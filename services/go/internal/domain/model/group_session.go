@@ -0,0 +1,172 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// GroupSessionStatus represents the status of a group ANC session
+type GroupSessionStatus string
+
+const (
+	// GroupSessionStatusScheduled represents a session that has not taken place yet
+	GroupSessionStatusScheduled GroupSessionStatus = "scheduled"
+	// GroupSessionStatusCompleted represents a session whose attendance has been recorded
+	GroupSessionStatusCompleted GroupSessionStatus = "completed"
+	// GroupSessionStatusCancelled represents a cancelled session
+	GroupSessionStatusCancelled GroupSessionStatus = "cancelled"
+)
+
+// AttendanceStatus represents whether a mother attended a group session
+type AttendanceStatus string
+
+const (
+	// AttendanceStatusEnrolled represents a mother on the roster whose attendance is not recorded yet
+	AttendanceStatusEnrolled AttendanceStatus = "enrolled"
+	// AttendanceStatusAttended represents a mother who attended the session
+	AttendanceStatusAttended AttendanceStatus = "attended"
+	// AttendanceStatusAbsent represents a mother who did not attend the session
+	AttendanceStatusAbsent AttendanceStatus = "absent"
+)
+
+// GroupSessionMember is a mother on the roster of a group session
+type GroupSessionMember struct {
+	MotherID   uuid.UUID        `json:"mother_id"`
+	Attendance AttendanceStatus `json:"attendance"`
+	VisitID    *uuid.UUID       `json:"visit_id,omitempty"` // the ANC contact recorded for her attendance
+	EnrolledAt time.Time        `json:"enrolled_at"`
+}
+
+// GroupSession represents a group antenatal care session run by a facilitator
+// for a cohort of mothers at a similar gestational age
+type GroupSession struct {
+	ID              uuid.UUID            `json:"id"`
+	FacilityID      uuid.UUID            `json:"facility_id"`
+	FacilitatorID   uuid.UUID            `json:"facilitator_id"`
+	Title           string               `json:"title"`
+	TopicContentID  *uuid.UUID           `json:"topic_content_id,omitempty"` // education content discussed in the session
+	ScheduledTime   time.Time            `json:"scheduled_time"`
+	DurationMinutes int                  `json:"duration_minutes"`
+	Capacity        int                  `json:"capacity"`
+	Status          GroupSessionStatus   `json:"status"`
+	Members         []GroupSessionMember `json:"members"`
+	Notes           string               `json:"notes,omitempty"`
+	CreatedAt       time.Time            `json:"created_at"`
+	UpdatedAt       time.Time            `json:"updated_at"`
+}
+
+// NewGroupSession creates a new group session
+func NewGroupSession(
+	id, facilityID, facilitatorID uuid.UUID,
+	title string,
+	scheduledTime time.Time,
+	durationMinutes, capacity int,
+) *GroupSession {
+	now := time.Now()
+	return &GroupSession{
+		ID:              id,
+		FacilityID:      facilityID,
+		FacilitatorID:   facilitatorID,
+		Title:           title,
+		ScheduledTime:   scheduledTime,
+		DurationMinutes: durationMinutes,
+		Capacity:        capacity,
+		Status:          GroupSessionStatusScheduled,
+		Members:         []GroupSessionMember{},
+		CreatedAt:       now,
+		UpdatedAt:       now,
+	}
+}
+
+// WithTopic links the session to the education content it covers
+func (g *GroupSession) WithTopic(contentID uuid.UUID) *GroupSession {
+	g.TopicContentID = &contentID
+	return g
+}
+
+// WithNotes adds notes to the session
+func (g *GroupSession) WithNotes(notes string) *GroupSession {
+	g.Notes = notes
+	return g
+}
+
+// EndTime returns when the session is expected to finish
+func (g *GroupSession) EndTime() time.Time {
+	return g.ScheduledTime.Add(time.Duration(g.DurationMinutes) * time.Minute)
+}
+
+// IsFull checks if the roster has reached the session capacity
+func (g *GroupSession) IsFull() bool {
+	return len(g.Members) >= g.Capacity
+}
+
+// SeatsAvailable returns how many more mothers can be enrolled
+func (g *GroupSession) SeatsAvailable() int {
+	if g.IsFull() {
+		return 0
+	}
+	return g.Capacity - len(g.Members)
+}
+
+// GetMember returns the roster entry for a mother, or nil if she isn't enrolled
+func (g *GroupSession) GetMember(motherID uuid.UUID) *GroupSessionMember {
+	for i := range g.Members {
+		if g.Members[i].MotherID == motherID {
+			return &g.Members[i]
+		}
+	}
+	return nil
+}
+
+// AddMember adds a mother to the roster. It returns false if she is already
+// enrolled or the session is full.
+func (g *GroupSession) AddMember(motherID uuid.UUID) bool {
+	if g.GetMember(motherID) != nil || g.IsFull() {
+		return false
+	}
+
+	now := time.Now()
+	g.Members = append(g.Members, GroupSessionMember{
+		MotherID:   motherID,
+		Attendance: AttendanceStatusEnrolled,
+		EnrolledAt: now,
+	})
+	g.UpdatedAt = now
+	return true
+}
+
+// RemoveMember removes a mother from the roster. It returns false if she wasn't enrolled.
+func (g *GroupSession) RemoveMember(motherID uuid.UUID) bool {
+	for i := range g.Members {
+		if g.Members[i].MotherID == motherID {
+			g.Members = append(g.Members[:i], g.Members[i+1:]...)
+			g.UpdatedAt = time.Now()
+			return true
+		}
+	}
+	return false
+}
+
+// AttendedCount returns the number of mothers who attended
+func (g *GroupSession) AttendedCount() int {
+	count := 0
+	for _, member := range g.Members {
+		if member.Attendance == AttendanceStatusAttended {
+			count++
+		}
+	}
+	return count
+}
+
+// Complete marks the session as having taken place
+func (g *GroupSession) Complete() {
+	g.Status = GroupSessionStatusCompleted
+	g.UpdatedAt = time.Now()
+}
+
+// Cancel marks the session as cancelled
+func (g *GroupSession) Cancel() {
+	g.Status = GroupSessionStatusCancelled
+	g.UpdatedAt = time.Now()
+}
@@ -0,0 +1,60 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// GrowthMeasurement is a child's anthropometry at a visit, with the WHO z-scores
+// computed from it and the growth status they classify to
+type GrowthMeasurement struct {
+	ID          uuid.UUID `json:"id"`
+	ChildID     uuid.UUID `json:"child_id"`
+	MeasuredAt  time.Time `json:"measured_at"`
+	WeightGrams *int      `json:"weight_grams,omitempty"`
+	// HeightCm is recumbent length or standing height, as MeasuredRecumbent says
+	HeightCm            *float64 `json:"height_cm,omitempty"`
+	MeasuredRecumbent   *bool    `json:"measured_recumbent,omitempty"`
+	HeadCircumferenceCm *float64 `json:"head_circumference_cm,omitempty"`
+	MUACCm              *float64 `json:"mid_upper_arm_circumference_cm,omitempty"`
+
+	WeightForAgeZ            *float64 `json:"weight_for_age_z,omitempty"`
+	HeightForAgeZ            *float64 `json:"height_for_age_z,omitempty"`
+	WeightForHeightZ         *float64 `json:"weight_for_height_z,omitempty"`
+	HeadCircumferenceForAgeZ *float64 `json:"head_circumference_for_age_z,omitempty"`
+	MUACForAgeZ              *float64 `json:"muac_for_age_z,omitempty"`
+	// ImplausibleZ means a z-score is beyond the WHO limits, so it was left out of the
+	// growth status and the child should be measured again
+	ImplausibleZ bool         `json:"implausible_z"`
+	GrowthStatus GrowthStatus `json:"growth_status"`
+
+	MeasuredByID   *uuid.UUID `json:"measured_by_user_id,omitempty"`
+	MeasuredByName string     `json:"measured_by_name,omitempty"`
+	FacilityID     *uuid.UUID `json:"measured_at_facility_id,omitempty"`
+	Notes          string     `json:"notes,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+}
+
+// NewGrowthMeasurement creates a new growth measurement for a child
+func NewGrowthMeasurement(id, childID uuid.UUID, measuredAt time.Time) *GrowthMeasurement {
+	now := time.Now()
+	return &GrowthMeasurement{
+		ID:           id,
+		ChildID:      childID,
+		MeasuredAt:   measuredAt,
+		GrowthStatus: GrowthStatusNormal,
+		CreatedAt:    now,
+		UpdatedAt:    now,
+	}
+}
+
+// WeightKg returns the weight in kg, or nil if it was not measured
+func (m *GrowthMeasurement) WeightKg() *float64 {
+	if m.WeightGrams == nil {
+		return nil
+	}
+	kg := float64(*m.WeightGrams) / 1000
+	return &kg
+}
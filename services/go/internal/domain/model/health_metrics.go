@@ -8,13 +8,80 @@ import (
 
 // VitalSigns represents a collection of vital health measurements
 type VitalSigns struct {
-	BloodPressure   *BloodPressure `json:"blood_pressure,omitempty"`
-	FetalHeartRate  *float64       `json:"fetal_heart_rate,omitempty"`
-	FetalMovement   *float64       `json:"fetal_movement,omitempty"`
-	BloodSugar      *float64       `json:"blood_sugar,omitempty"`
-	HemoglobinLevel *float64       `json:"hemoglobin_level,omitempty"`
-	IronLevel       *float64       `json:"iron_level,omitempty"`
-	Weight          *float64       `json:"weight,omitempty"`
+	BloodPressure    *BloodPressure `json:"blood_pressure,omitempty"`
+	FetalHeartRate   *float64       `json:"fetal_heart_rate,omitempty"`
+	FetalMovement    *float64       `json:"fetal_movement,omitempty"`
+	BloodSugar       *float64       `json:"blood_sugar,omitempty"`
+	BloodSugarTiming *GlucoseTiming `json:"blood_sugar_timing,omitempty"`
+	HemoglobinLevel  *float64       `json:"hemoglobin_level,omitempty"`
+	IronLevel        *float64       `json:"iron_level,omitempty"`
+	Weight           *float64       `json:"weight,omitempty"`
+	UrineProtein     *UrineProtein  `json:"urine_protein,omitempty"`
+	// Fetal growth: symphysis-fundal height in cm and ultrasound estimated fetal weight in grams
+	FundalHeightCm       *float64 `json:"fundal_height_cm,omitempty"`
+	EstimatedFetalWeight *float64 `json:"estimated_fetal_weight,omitempty"`
+	// Mid-upper arm circumference in cm, screens for maternal undernutrition
+	MUACCm *float64 `json:"muac_cm,omitempty"`
+}
+
+// GlucoseTiming represents when a blood glucose sample was taken
+type GlucoseTiming string
+
+const (
+	// GlucoseTimingFasting represents a fasting sample
+	GlucoseTimingFasting GlucoseTiming = "fasting"
+	// GlucoseTimingOGTT1h represents a sample 1 hour into a 75 g oral glucose tolerance test
+	GlucoseTimingOGTT1h GlucoseTiming = "ogtt_1h"
+	// GlucoseTimingOGTT2h represents a sample 2 hours into a 75 g oral glucose tolerance test
+	GlucoseTimingOGTT2h GlucoseTiming = "ogtt_2h"
+	// GlucoseTimingRandom represents a random (non-fasting) sample
+	GlucoseTimingRandom GlucoseTiming = "random"
+)
+
+// UrineProtein represents a urine protein dipstick result
+type UrineProtein string
+
+const (
+	// UrineProteinNegative represents a negative dipstick
+	UrineProteinNegative UrineProtein = "negative"
+	// UrineProteinTrace represents a trace result
+	UrineProteinTrace UrineProtein = "trace"
+	// UrineProteinPlus1 represents a 1+ result (about 30 mg/dL)
+	UrineProteinPlus1 UrineProtein = "1+"
+	// UrineProteinPlus2 represents a 2+ result (about 100 mg/dL)
+	UrineProteinPlus2 UrineProtein = "2+"
+	// UrineProteinPlus3 represents a 3+ result (about 300 mg/dL)
+	UrineProteinPlus3 UrineProtein = "3+"
+	// UrineProteinPlus4 represents a 4+ result (2000 mg/dL or more)
+	UrineProteinPlus4 UrineProtein = "4+"
+)
+
+// Grade returns the number of pluses on the dipstick, 0 for negative or trace and -1 if unknown
+func (u UrineProtein) Grade() int {
+	switch u {
+	case UrineProteinNegative, UrineProteinTrace:
+		return 0
+	case UrineProteinPlus1:
+		return 1
+	case UrineProteinPlus2:
+		return 2
+	case UrineProteinPlus3:
+		return 3
+	case UrineProteinPlus4:
+		return 4
+	default:
+		return -1
+	}
+}
+
+// IsValid checks if the dipstick result is a known value
+func (u UrineProtein) IsValid() bool {
+	return u.Grade() >= 0
+}
+
+// IsProteinuria checks if the result counts as proteinuria (1+ or more)
+func (u UrineProtein) IsProteinuria() bool {
+	return u.Grade() >= 1
 }
 
 // BloodPressure represents a blood pressure measurement
@@ -128,6 +195,26 @@ func (h *HealthMetric) WithBloodSugar(bloodSugar float64) *HealthMetric {
 	return h
 }
 
+// WithGlucoseTest adds a blood glucose measurement taken at a known time
+func (h *HealthMetric) WithGlucoseTest(bloodSugar float64, timing GlucoseTiming) *HealthMetric {
+	h.VitalSigns.BloodSugar = &bloodSugar
+	h.VitalSigns.BloodSugarTiming = &timing
+
+	// IADPSG/WHO 2013 thresholds for a 75 g OGTT in pregnancy (mg/dL)
+	switch timing {
+	case GlucoseTimingFasting:
+		h.IsAbnormal = h.IsAbnormal || bloodSugar >= 92
+	case GlucoseTimingOGTT1h:
+		h.IsAbnormal = h.IsAbnormal || bloodSugar >= 180
+	case GlucoseTimingOGTT2h:
+		h.IsAbnormal = h.IsAbnormal || bloodSugar >= 153
+	case GlucoseTimingRandom:
+		h.IsAbnormal = h.IsAbnormal || bloodSugar >= 200
+	}
+
+	return h
+}
+
 // WithHemoglobinLevel adds hemoglobin level measurement
 func (h *HealthMetric) WithHemoglobinLevel(hemoglobinLevel float64) *HealthMetric {
 	h.VitalSigns.HemoglobinLevel = &hemoglobinLevel
@@ -158,6 +245,43 @@ func (h *HealthMetric) WithWeight(weight float64) *HealthMetric {
 	return h
 }
 
+// WithUrineProtein adds a urine protein dipstick result
+func (h *HealthMetric) WithUrineProtein(result UrineProtein) *HealthMetric {
+	h.VitalSigns.UrineProtein = &result
+
+	// Proteinuria in pregnancy needs review for pre-eclampsia
+	if result.IsProteinuria() {
+		h.IsAbnormal = true
+	}
+
+	return h
+}
+
+// WithFundalHeight adds a symphysis-fundal height measurement in cm. Whether it is
+// abnormal depends on gestational age, so it is judged against the growth chart.
+func (h *HealthMetric) WithFundalHeight(heightCm float64) *HealthMetric {
+	h.VitalSigns.FundalHeightCm = &heightCm
+	return h
+}
+
+// WithEstimatedFetalWeight adds an ultrasound estimated fetal weight in grams
+func (h *HealthMetric) WithEstimatedFetalWeight(weightGrams float64) *HealthMetric {
+	h.VitalSigns.EstimatedFetalWeight = &weightGrams
+	return h
+}
+
+// WithMUAC adds a mid-upper arm circumference measurement in cm
+func (h *HealthMetric) WithMUAC(muacCm float64) *HealthMetric {
+	h.VitalSigns.MUACCm = &muacCm
+
+	// Pregnant and lactating women under 23 cm are acutely malnourished
+	if muacCm < 23.0 {
+		h.IsAbnormal = true
+	}
+
+	return h
+}
+
 // WithContractions adds contraction measurements
 func (h *HealthMetric) WithContractions(duration, interval, intensity, frequency int) *HealthMetric {
 	h.Contractions = &ContractionReading{
@@ -0,0 +1,114 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// VaccineSchedule is one dose of the national EPI schedule. Ages are in days, as the
+// primary series is given at 6, 10 and 14 weeks. The window offsets are days relative
+// to the recommended age, so a negative start allows the dose early.
+type VaccineSchedule struct {
+	ID              uuid.UUID `json:"id"`
+	VaccineName     string    `json:"vaccine_name"`
+	Dose            string    `json:"vaccine_dose"`
+	AgeDays         int       `json:"age_days"`
+	WindowStartDays int       `json:"window_start_days"`
+	WindowEndDays   int       `json:"window_end_days"`
+	// MinIntervalDays is the shortest gap after the previous dose of the series for this dose to count
+	MinIntervalDays int `json:"min_interval_days"`
+	// MaxAgeDays is the age after which the dose is no longer given, if there is one
+	MaxAgeDays    *int   `json:"max_age_days,omitempty"`
+	DiseaseTarget string `json:"disease_target"`
+	IsRequired    bool   `json:"is_required"`
+	IsActive      bool   `json:"is_active"`
+}
+
+// AgeWeeks returns the recommended age in whole weeks, as the EPI schedule is written
+func (v *VaccineSchedule) AgeWeeks() int {
+	return v.AgeDays / 7
+}
+
+// RecommendedDate returns the date the dose is recommended for a child born on dateOfBirth
+func (v *VaccineSchedule) RecommendedDate(dateOfBirth time.Time) time.Time {
+	return dateOfBirth.AddDate(0, 0, v.AgeDays)
+}
+
+// WindowStart returns the earliest date the dose counts as valid
+func (v *VaccineSchedule) WindowStart(dateOfBirth time.Time) time.Time {
+	return v.RecommendedDate(dateOfBirth).AddDate(0, 0, v.WindowStartDays)
+}
+
+// WindowEnd returns the last date the dose is on time; after it the dose is overdue
+func (v *VaccineSchedule) WindowEnd(dateOfBirth time.Time) time.Time {
+	return v.RecommendedDate(dateOfBirth).AddDate(0, 0, v.WindowEndDays)
+}
+
+// LastDate returns the last date the dose may be given, or nil if there is no maximum age
+func (v *VaccineSchedule) LastDate(dateOfBirth time.Time) *time.Time {
+	if v.MaxAgeDays == nil {
+		return nil
+	}
+	last := dateOfBirth.AddDate(0, 0, *v.MaxAgeDays)
+	return &last
+}
+
+// EarliestAfter returns the earliest date the dose counts after a previous dose of the series
+func (v *VaccineSchedule) EarliestAfter(previous time.Time) time.Time {
+	return previous.AddDate(0, 0, v.MinIntervalDays)
+}
+
+// ImmunizationRecord is a vaccine dose given to a child. Doses given too early or too
+// soon after the previous dose are kept but marked invalid, and must be repeated.
+type ImmunizationRecord struct {
+	ID                 uuid.UUID  `json:"id"`
+	ChildID            uuid.UUID  `json:"child_id"`
+	VaccineName        string     `json:"vaccine_name"`
+	Dose               string     `json:"vaccine_dose"`
+	AdministeredDate   time.Time  `json:"administered_date"`
+	AdministeredByID   *uuid.UUID `json:"administered_by_user_id,omitempty"`
+	AdministeredByName string     `json:"administered_by_name,omitempty"`
+	FacilityID         *uuid.UUID `json:"administered_at_facility_id,omitempty"`
+	BatchNumber        string     `json:"batch_number,omitempty"`
+	Manufacturer       string     `json:"manufacturer,omitempty"`
+	AdverseReactions   string     `json:"adverse_reactions,omitempty"`
+	Notes              string     `json:"notes,omitempty"`
+	IsVerified         bool       `json:"is_verified"`
+	IsValid            bool       `json:"is_valid"`
+	InvalidReason      string     `json:"invalid_reason,omitempty"`
+	CreatedAt          time.Time  `json:"created_at"`
+	UpdatedAt          time.Time  `json:"updated_at"`
+}
+
+// NewImmunizationRecord creates a new verified, valid immunization record
+func NewImmunizationRecord(id, childID uuid.UUID, vaccineName, dose string, administeredDate time.Time) *ImmunizationRecord {
+	now := time.Now()
+	return &ImmunizationRecord{
+		ID:               id,
+		ChildID:          childID,
+		VaccineName:      vaccineName,
+		Dose:             dose,
+		AdministeredDate: administeredDate,
+		IsVerified:       true,
+		IsValid:          true,
+		CreatedAt:        now,
+		UpdatedAt:        now,
+	}
+}
+
+// MarkInvalid marks the dose as not counting towards the schedule
+func (r *ImmunizationRecord) MarkInvalid(reason string) {
+	r.IsValid = false
+	r.InvalidReason = reason
+}
+
+// VaccineReminderKind distinguishes reminders for doses coming due from overdue ones
+type VaccineReminderKind string
+
+const (
+	// VaccineReminderDue is sent when a dose is coming due
+	VaccineReminderDue VaccineReminderKind = "due"
+	// VaccineReminderOverdue is sent once a dose has passed its window
+	VaccineReminderOverdue VaccineReminderKind = "overdue"
+)
@@ -42,6 +42,20 @@ const (
 	RiskLevelHigh RiskLevel = "high"
 )
 
+// PregnancyStage represents where a mother is in her pregnancy journey
+type PregnancyStage string
+
+const (
+	// PregnancyStageFirstTrimester represents weeks 0-12
+	PregnancyStageFirstTrimester PregnancyStage = "first_trimester"
+	// PregnancyStageSecondTrimester represents weeks 13-26
+	PregnancyStageSecondTrimester PregnancyStage = "second_trimester"
+	// PregnancyStageThirdTrimester represents week 27 until delivery
+	PregnancyStageThirdTrimester PregnancyStage = "third_trimester"
+	// PregnancyStagePostpartum represents the period after delivery
+	PregnancyStagePostpartum PregnancyStage = "postpartum"
+)
+
 // PregnancyHistory represents the pregnancy history of a mother
 type PregnancyHistory struct {
 	PreviousPregnancies   int      `json:"previous_pregnancies"`
@@ -59,6 +73,13 @@ type Mother struct {
 	HealthConditions     []string         `json:"health_conditions"`
 	PregnancyHistory     PregnancyHistory `json:"pregnancy_history"`
 	RiskLevel            RiskLevel        `json:"risk_level"`
+	PregnancyStage       PregnancyStage   `json:"pregnancy_stage"`
+	DeliveryDate         *time.Time       `json:"delivery_date,omitempty"`
+	DateOfBirth          *time.Time       `json:"date_of_birth,omitempty"`
+	HeightCm             *float64         `json:"height_cm,omitempty"`
+	PrePregnancyWeight   *float64         `json:"pre_pregnancy_weight,omitempty"` // kg
+	LMP                  *time.Time       `json:"lmp,omitempty"`                  // last menstrual period, if known
+	DatingMethod         DatingMethod     `json:"dating_method"`                  // how ExpectedDeliveryDate was set
 	CreatedAt            time.Time        `json:"created_at"`
 	UpdatedAt            time.Time        `json:"updated_at"`
 }
@@ -73,6 +94,8 @@ func NewMother(id, userID uuid.UUID, expectedDeliveryDate time.Time) *Mother {
 		BloodType:            BloodTypeUnknown,
 		HealthConditions:     []string{},
 		RiskLevel:            RiskLevelLow,
+		PregnancyStage:       PregnancyStageFirstTrimester,
+		DatingMethod:         DatingMethodLMP,
 		CreatedAt:            now,
 		UpdatedAt:            now,
 		PregnancyHistory:     PregnancyHistory{},
@@ -139,7 +162,87 @@ func (m *Mother) GetWeeksPregnant(referenceDate time.Time) int {
 	return weeksPregnant
 }
 
+// WithDateOfBirth sets the date of birth of the mother
+func (m *Mother) WithDateOfBirth(dateOfBirth time.Time) *Mother {
+	m.DateOfBirth = &dateOfBirth
+	return m
+}
+
+// WithBodyMeasurements sets the mother's height and pre-pregnancy weight, used for BMI
+func (m *Mother) WithBodyMeasurements(heightCm, prePregnancyWeight float64) *Mother {
+	m.HeightCm = &heightCm
+	m.PrePregnancyWeight = &prePregnancyWeight
+	return m
+}
+
+// WithLMP sets the mother's last menstrual period
+func (m *Mother) WithLMP(lmp time.Time) *Mother {
+	m.LMP = &lmp
+	return m
+}
+
+// ApplyDating sets a corrected expected delivery date and the method it came from
+func (m *Mother) ApplyDating(edd time.Time, method DatingMethod) {
+	m.ExpectedDeliveryDate = edd
+	m.DatingMethod = method
+	m.UpdatedAt = time.Now()
+	m.UpdatePregnancyStage(time.Now())
+}
+
+// DatingLMP returns the LMP implied by the expected delivery date, which is what
+// gestational age should be counted from once the pregnancy has been redated
+func (m *Mother) DatingLMP() time.Time {
+	return m.ExpectedDeliveryDate.AddDate(0, 0, -280)
+}
+
+// AgeAt returns the mother's age in completed years at the reference date.
+// The second value is false if her date of birth is not recorded.
+func (m *Mother) AgeAt(referenceDate time.Time) (int, bool) {
+	if m.DateOfBirth == nil {
+		return 0, false
+	}
+
+	dob := *m.DateOfBirth
+	age := referenceDate.Year() - dob.Year()
+	if referenceDate.Month() < dob.Month() ||
+		(referenceDate.Month() == dob.Month() && referenceDate.Day() < dob.Day()) {
+		age--
+	}
+	return age, true
+}
+
 // IsHighRisk checks if the mother is classified as high risk
 func (m *Mother) IsHighRisk() bool {
 	return m.RiskLevel == RiskLevelHigh
 }
+
+// IsPostpartum checks if the mother has delivered
+func (m *Mother) IsPostpartum() bool {
+	return m.PregnancyStage == PregnancyStagePostpartum
+}
+
+// RecordDelivery marks the mother as postpartum from the given delivery date
+func (m *Mother) RecordDelivery(deliveryDate time.Time) {
+	m.DeliveryDate = &deliveryDate
+	m.PregnancyStage = PregnancyStagePostpartum
+	m.PregnancyHistory.PreviousPregnancies++
+	m.PregnancyHistory.PreviousDeliveries++
+	m.UpdatedAt = time.Now()
+}
+
+// UpdatePregnancyStage derives the trimester from the expected delivery date.
+// Postpartum mothers are left unchanged.
+func (m *Mother) UpdatePregnancyStage(referenceDate time.Time) {
+	if m.IsPostpartum() {
+		return
+	}
+
+	switch weeks := m.GetWeeksPregnant(referenceDate); {
+	case weeks < 13:
+		m.PregnancyStage = PregnancyStageFirstTrimester
+	case weeks < 27:
+		m.PregnancyStage = PregnancyStageSecondTrimester
+	default:
+		m.PregnancyStage = PregnancyStageThirdTrimester
+	}
+}
@@ -0,0 +1,300 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Newborn thresholds from the WHO birth weight categories and IMNCI young infant guidelines
+const (
+	// LowBirthWeightGrams is the birth weight below which a baby is low birth weight
+	LowBirthWeightGrams = 2500
+	// VeryLowBirthWeightGrams is the birth weight below which a baby is very low birth weight
+	VeryLowBirthWeightGrams = 1500
+	// ExtremelyLowBirthWeightGrams is the birth weight below which a baby is extremely low birth weight
+	ExtremelyLowBirthWeightGrams = 1000
+	// NeonatalHypothermiaC is the axillary temperature below which a newborn is hypothermic
+	NeonatalHypothermiaC = 36.5
+	// NeonatalSevereHypothermiaC is the axillary temperature below which hypothermia is severe
+	NeonatalSevereHypothermiaC = 35.5
+	// NeonatalFeverC is the axillary temperature from which a newborn has a fever
+	NeonatalFeverC = 37.5
+	// NeonatalFastBreathingRate is the breaths a minute from which a newborn is breathing fast
+	NeonatalFastBreathingRate = 60
+	// NeonatalPeriodDays is the length of the neonatal period
+	NeonatalPeriodDays = 28
+)
+
+// BirthWeightCategory is the WHO classification of a baby's weight at birth
+type BirthWeightCategory string
+
+const (
+	// BirthWeightNormal is 2500 g or more
+	BirthWeightNormal BirthWeightCategory = "normal"
+	// BirthWeightLow is 1500 g to under 2500 g
+	BirthWeightLow BirthWeightCategory = "low"
+	// BirthWeightVeryLow is 1000 g to under 1500 g
+	BirthWeightVeryLow BirthWeightCategory = "very_low"
+	// BirthWeightExtremelyLow is under 1000 g
+	BirthWeightExtremelyLow BirthWeightCategory = "extremely_low"
+)
+
+// ClassifyBirthWeight returns the category of a birth weight
+func ClassifyBirthWeight(grams int) BirthWeightCategory {
+	switch {
+	case grams < ExtremelyLowBirthWeightGrams:
+		return BirthWeightExtremelyLow
+	case grams < VeryLowBirthWeightGrams:
+		return BirthWeightVeryLow
+	case grams < LowBirthWeightGrams:
+		return BirthWeightLow
+	default:
+		return BirthWeightNormal
+	}
+}
+
+// IsLow checks if the category is any kind of low birth weight
+func (c BirthWeightCategory) IsLow() bool {
+	return c == BirthWeightLow || c == BirthWeightVeryLow || c == BirthWeightExtremelyLow
+}
+
+// IsVeryLow checks if the category is very or extremely low birth weight
+func (c BirthWeightCategory) IsVeryLow() bool {
+	return c == BirthWeightVeryLow || c == BirthWeightExtremelyLow
+}
+
+// CordCare is how the umbilical cord stump was cared for after birth
+type CordCare string
+
+const (
+	// CordCareChlorhexidine is 7.1% chlorhexidine digluconate applied to the stump
+	CordCareChlorhexidine CordCare = "chlorhexidine"
+	// CordCareDry is clean, dry cord care with nothing applied
+	CordCareDry CordCare = "dry"
+)
+
+// IsValid checks if the cord care method is known
+func (c CordCare) IsValid() bool {
+	return c == CordCareChlorhexidine || c == CordCareDry
+}
+
+// NeonatalRecord is the essential newborn care given to a child at and after birth
+type NeonatalRecord struct {
+	ID      uuid.UUID `json:"id"`
+	ChildID uuid.UUID `json:"child_id"`
+	// Apgar1Min, Apgar5Min and Apgar10Min are the APGAR scores, from 0 to 10
+	Apgar1Min           *int                `json:"apgar_1_min,omitempty"`
+	Apgar5Min           *int                `json:"apgar_5_min,omitempty"`
+	Apgar10Min          *int                `json:"apgar_10_min,omitempty"`
+	BirthWeightGrams    *int                `json:"birth_weight_grams,omitempty"`
+	BirthWeightCategory BirthWeightCategory `json:"birth_weight_category,omitempty"`
+	// BreastfeedingInitiatedAt is when the baby was first put to the breast
+	BreastfeedingInitiatedAt *time.Time `json:"breastfeeding_initiated_at,omitempty"`
+	// EarlyBreastfeeding is whether breastfeeding started within an hour of birth
+	EarlyBreastfeeding bool       `json:"early_breastfeeding"`
+	CordCare           CordCare   `json:"cord_care,omitempty"`
+	KMCStartedAt       *time.Time `json:"kmc_started_at,omitempty"`
+	KMCEndedAt         *time.Time `json:"kmc_ended_at,omitempty"`
+	KMCEndReason       string     `json:"kmc_end_reason,omitempty"`
+	RecordedByID       uuid.UUID  `json:"recorded_by_id"`
+	Notes              string     `json:"notes,omitempty"`
+	CreatedAt          time.Time  `json:"created_at"`
+	UpdatedAt          time.Time  `json:"updated_at"`
+}
+
+// NewNeonatalRecord creates a new neonatal record for a child
+func NewNeonatalRecord(childID, recordedByID uuid.UUID) *NeonatalRecord {
+	now := time.Now()
+	return &NeonatalRecord{
+		ID:           uuid.New(),
+		ChildID:      childID,
+		RecordedByID: recordedByID,
+		CreatedAt:    now,
+		UpdatedAt:    now,
+	}
+}
+
+// SetBirthWeight records the birth weight and its category
+func (r *NeonatalRecord) SetBirthWeight(grams int) {
+	r.BirthWeightGrams = &grams
+	r.BirthWeightCategory = ClassifyBirthWeight(grams)
+}
+
+// KMCEligible checks if the baby should receive kangaroo mother care
+func (r *NeonatalRecord) KMCEligible() bool {
+	return r.BirthWeightCategory.IsLow()
+}
+
+// InKMC checks if kangaroo mother care has started and not ended
+func (r *NeonatalRecord) InKMC() bool {
+	return r.KMCStartedAt != nil && r.KMCEndedAt == nil
+}
+
+// LowApgar checks if the five-minute APGAR score points to birth asphyxia
+func (r *NeonatalRecord) LowApgar() bool {
+	return r.Apgar5Min != nil && *r.Apgar5Min < 7
+}
+
+// KMCSession is one day of kangaroo mother care
+type KMCSession struct {
+	ID      uuid.UUID `json:"id"`
+	ChildID uuid.UUID `json:"child_id"`
+	Date    time.Time `json:"date"`
+	// SkinToSkinHours is how long the baby was held skin-to-skin that day
+	SkinToSkinHours        float64   `json:"skin_to_skin_hours"`
+	TemperatureC           *float64  `json:"temperature_c,omitempty"`
+	WeightGrams            *int      `json:"weight_grams,omitempty"`
+	ExclusiveBreastfeeding bool      `json:"exclusive_breastfeeding"`
+	RecordedByID           uuid.UUID `json:"recorded_by_id"`
+	Notes                  string    `json:"notes,omitempty"`
+	CreatedAt              time.Time `json:"created_at"`
+}
+
+// NewKMCSession creates a new day of kangaroo mother care
+func NewKMCSession(childID uuid.UUID, date time.Time, skinToSkinHours float64, recordedByID uuid.UUID) *KMCSession {
+	return &KMCSession{
+		ID:              uuid.New(),
+		ChildID:         childID,
+		Date:            date,
+		SkinToSkinHours: skinToSkinHours,
+		RecordedByID:    recordedByID,
+		CreatedAt:       time.Now(),
+	}
+}
+
+// NewbornFeeding is how a newborn is feeding
+type NewbornFeeding string
+
+const (
+	// NewbornFeedingGood means the baby attaches and suckles well
+	NewbornFeedingGood NewbornFeeding = "good"
+	// NewbornFeedingPoor means the baby feeds, but attaches or suckles poorly
+	NewbornFeedingPoor NewbornFeeding = "poor"
+	// NewbornFeedingNone means the baby is not able to feed
+	NewbornFeedingNone NewbornFeeding = "not_feeding"
+)
+
+// IsValid checks if the feeding status is known
+func (f NewbornFeeding) IsValid() bool {
+	return f == NewbornFeedingGood || f == NewbornFeedingPoor || f == NewbornFeedingNone
+}
+
+// JaundiceExtent is how far down the body a newborn's skin is yellow
+type JaundiceExtent string
+
+const (
+	// JaundiceNone means no yellow skin
+	JaundiceNone JaundiceExtent = "none"
+	// JaundiceFace means the face and eyes are yellow
+	JaundiceFace JaundiceExtent = "face"
+	// JaundiceTrunk means the chest and belly are yellow
+	JaundiceTrunk JaundiceExtent = "trunk"
+	// JaundicePalmsSoles means the palms and soles are yellow, which is severe at any age
+	JaundicePalmsSoles JaundiceExtent = "palms_soles"
+)
+
+// IsValid checks if the jaundice extent is known
+func (j JaundiceExtent) IsValid() bool {
+	switch j {
+	case JaundiceNone, JaundiceFace, JaundiceTrunk, JaundicePalmsSoles:
+		return true
+	}
+	return false
+}
+
+// NeonatalDangerSign is a sign of possible serious illness in a newborn
+type NeonatalDangerSign string
+
+const (
+	// NeonatalSignNotFeeding means the baby is not able to feed
+	NeonatalSignNotFeeding NeonatalDangerSign = "not_feeding"
+	// NeonatalSignPoorFeeding means the baby attaches or suckles poorly
+	NeonatalSignPoorFeeding NeonatalDangerSign = "poor_feeding"
+	// NeonatalSignConvulsions means the baby has had fits
+	NeonatalSignConvulsions NeonatalDangerSign = "convulsions"
+	// NeonatalSignLethargy means the baby moves only when stimulated, or not at all
+	NeonatalSignLethargy NeonatalDangerSign = "lethargy"
+	// NeonatalSignFastBreathing means 60 or more breaths a minute
+	NeonatalSignFastBreathing NeonatalDangerSign = "fast_breathing"
+	// NeonatalSignChestIndrawing means severe lower chest wall indrawing
+	NeonatalSignChestIndrawing NeonatalDangerSign = "chest_indrawing"
+	// NeonatalSignFever means a temperature of 37.5 C or more
+	NeonatalSignFever NeonatalDangerSign = "fever"
+	// NeonatalSignSevereHypothermia means a temperature below 35.5 C
+	NeonatalSignSevereHypothermia NeonatalDangerSign = "severe_hypothermia"
+	// NeonatalSignHypothermia means a temperature from 35.5 C to below 36.5 C
+	NeonatalSignHypothermia NeonatalDangerSign = "hypothermia"
+	// NeonatalSignSevereJaundice means jaundice in the first day of life or on the palms and soles
+	NeonatalSignSevereJaundice NeonatalDangerSign = "severe_jaundice"
+	// NeonatalSignJaundice means jaundice of the face or trunk after the first day
+	NeonatalSignJaundice NeonatalDangerSign = "jaundice"
+	// NeonatalSignUmbilicalInfection means a red or draining umbilicus
+	NeonatalSignUmbilicalInfection NeonatalDangerSign = "umbilical_infection"
+)
+
+// NeonatalSeverity is the IMNCI classification of a newborn check
+type NeonatalSeverity string
+
+const (
+	// NeonatalSeverityNone means no danger signs
+	NeonatalSeverityNone NeonatalSeverity = "none"
+	// NeonatalSeverityModerate means signs that can be managed at home with a follow-up check
+	NeonatalSeverityModerate NeonatalSeverity = "moderate"
+	// NeonatalSeveritySevere means possible serious illness needing urgent referral
+	NeonatalSeveritySevere NeonatalSeverity = "severe"
+)
+
+// NeonatalCheck is a newborn examined for danger signs
+type NeonatalCheck struct {
+	ID         uuid.UUID `json:"id"`
+	ChildID    uuid.UUID `json:"child_id"`
+	FacilityID uuid.UUID `json:"facility_id"`
+	// VisitID is the scheduled visit the check was made at, if any
+	VisitID              *uuid.UUID           `json:"visit_id,omitempty"`
+	CheckedAt            time.Time            `json:"checked_at"`
+	CheckedByID          uuid.UUID            `json:"checked_by_id"`
+	TemperatureC         *float64             `json:"temperature_c,omitempty"`
+	RespiratoryRate      *int                 `json:"respiratory_rate,omitempty"`
+	WeightGrams          *int                 `json:"weight_grams,omitempty"`
+	Feeding              NewbornFeeding       `json:"feeding"`
+	Jaundice             JaundiceExtent       `json:"jaundice"`
+	Convulsions          bool                 `json:"convulsions"`
+	Lethargic            bool                 `json:"lethargic"`
+	SevereChestIndrawing bool                 `json:"severe_chest_indrawing"`
+	UmbilicalInfection   bool                 `json:"umbilical_infection"`
+	DangerSigns          []NeonatalDangerSign `json:"danger_signs"`
+	Severity             NeonatalSeverity     `json:"severity"`
+	// SOSEventID is the referral raised for severe danger signs
+	SOSEventID *uuid.UUID `json:"sos_event_id,omitempty"`
+	// FollowUpVisitID is the recheck scheduled for moderate danger signs
+	FollowUpVisitID *uuid.UUID `json:"follow_up_visit_id,omitempty"`
+	Notes           string     `json:"notes,omitempty"`
+	CreatedAt       time.Time  `json:"created_at"`
+}
+
+// NewNeonatalCheck creates a new newborn check
+func NewNeonatalCheck(childID, facilityID, checkedByID uuid.UUID, checkedAt time.Time) *NeonatalCheck {
+	return &NeonatalCheck{
+		ID:          uuid.New(),
+		ChildID:     childID,
+		FacilityID:  facilityID,
+		CheckedAt:   checkedAt,
+		CheckedByID: checkedByID,
+		Feeding:     NewbornFeedingGood,
+		Jaundice:    JaundiceNone,
+		DangerSigns: []NeonatalDangerSign{},
+		Severity:    NeonatalSeverityNone,
+		CreatedAt:   time.Now(),
+	}
+}
+
+// HasSign checks if the check found a danger sign
+func (c *NeonatalCheck) HasSign(sign NeonatalDangerSign) bool {
+	for _, s := range c.DangerSigns {
+		if s == sign {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,179 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// MUACUndernutritionCm is the mid-upper arm circumference below which a pregnant or
+// lactating woman is acutely malnourished
+const MUACUndernutritionCm = 23.0
+
+// NutritionFlag is a nutrition problem found in a mother's measurements
+type NutritionFlag string
+
+const (
+	// NutritionFlagUnderweightBMI means pre-pregnancy BMI was below 18.5
+	NutritionFlagUnderweightBMI NutritionFlag = "underweight_bmi"
+	// NutritionFlagLowMUAC means the latest MUAC is below 23 cm
+	NutritionFlagLowMUAC NutritionFlag = "low_muac"
+	// NutritionFlagInadequateGain means weight gain is below the IOM target for the week
+	NutritionFlagInadequateGain NutritionFlag = "inadequate_weight_gain"
+	// NutritionFlagExcessiveGain means weight gain is above the IOM target for the week
+	NutritionFlagExcessiveGain NutritionFlag = "excessive_weight_gain"
+)
+
+// IsUndernutrition checks if the flag is a sign of undernutrition that supplementation treats
+func (f NutritionFlag) IsUndernutrition() bool {
+	return f == NutritionFlagUnderweightBMI || f == NutritionFlagLowMUAC || f == NutritionFlagInadequateGain
+}
+
+// CounsellingTopic is a dietary counselling topic covered with a mother
+type CounsellingTopic string
+
+const (
+	// CounsellingTopicBalancedDiet covers eating a varied diet from all food groups
+	CounsellingTopicBalancedDiet CounsellingTopic = "balanced_diet"
+	// CounsellingTopicExtraMeal covers eating one extra meal a day in pregnancy
+	CounsellingTopicExtraMeal CounsellingTopic = "extra_meal"
+	// CounsellingTopicIronRichFoods covers iron-rich foods and anaemia prevention
+	CounsellingTopicIronRichFoods CounsellingTopic = "iron_rich_foods"
+	// CounsellingTopicSupplements covers taking iron-folic acid and other supplements
+	CounsellingTopicSupplements CounsellingTopic = "supplements"
+	// CounsellingTopicWeightGain covers healthy weight gain for her BMI
+	CounsellingTopicWeightGain CounsellingTopic = "weight_gain"
+	// CounsellingTopicFoodTaboos covers food taboos and restrictions in pregnancy
+	CounsellingTopicFoodTaboos CounsellingTopic = "food_taboos"
+	// CounsellingTopicPhysicalActivity covers physical activity and workload
+	CounsellingTopicPhysicalActivity CounsellingTopic = "physical_activity"
+)
+
+// IsValid checks if the counselling topic is known
+func (t CounsellingTopic) IsValid() bool {
+	switch t {
+	case CounsellingTopicBalancedDiet, CounsellingTopicExtraMeal, CounsellingTopicIronRichFoods,
+		CounsellingTopicSupplements, CounsellingTopicWeightGain, CounsellingTopicFoodTaboos,
+		CounsellingTopicPhysicalActivity:
+		return true
+	default:
+		return false
+	}
+}
+
+// NutritionCounselling is a dietary counselling session with a mother
+type NutritionCounselling struct {
+	ID             uuid.UUID          `json:"id"`
+	MotherID       uuid.UUID          `json:"mother_id"`
+	VisitID        *uuid.UUID         `json:"visit_id,omitempty"`
+	Topics         []CounsellingTopic `json:"topics"`
+	Notes          string             `json:"notes,omitempty"`
+	CounselledByID *uuid.UUID         `json:"counselled_by_id,omitempty"`
+	CounselledAt   time.Time          `json:"counselled_at"`
+	CreatedAt      time.Time          `json:"created_at"`
+}
+
+// SupplementProgramme is a maternal nutrition supplementation programme
+type SupplementProgramme string
+
+const (
+	// SupplementProgrammeBEP is balanced energy protein supplementation for undernourished mothers
+	SupplementProgrammeBEP SupplementProgramme = "balanced_energy_protein"
+)
+
+// SupplementItem is a supplement that facilities stock and dispense
+type SupplementItem string
+
+const (
+	// SupplementItemBEP is a balanced energy protein ration (fortified blended food, 1 kg)
+	SupplementItemBEP SupplementItem = "bep_ration"
+	// SupplementItemMMS is a multiple micronutrient supplement tablet
+	SupplementItemMMS SupplementItem = "mms_tablet"
+	// SupplementItemIFA is an iron-folic acid tablet
+	SupplementItemIFA SupplementItem = "ifa_tablet"
+	// SupplementItemRUTF is a 92 g sachet of ready-to-use therapeutic food for severely malnourished children
+	SupplementItemRUTF SupplementItem = "rutf_sachet"
+)
+
+// IsValid checks if the supplement item is known
+func (i SupplementItem) IsValid() bool {
+	return i == SupplementItemBEP || i == SupplementItemMMS || i == SupplementItemIFA || i == SupplementItemRUTF
+}
+
+// EnrollmentStatus is the state of a supplementation enrollment
+type EnrollmentStatus string
+
+const (
+	// EnrollmentStatusActive means the mother is receiving supplements
+	EnrollmentStatusActive EnrollmentStatus = "active"
+	// EnrollmentStatusCompleted means the mother recovered or delivered
+	EnrollmentStatusCompleted EnrollmentStatus = "completed"
+	// EnrollmentStatusWithdrawn means the mother left the programme
+	EnrollmentStatusWithdrawn EnrollmentStatus = "withdrawn"
+)
+
+// SupplementationEnrollment is a mother's enrollment in a supplementation programme
+type SupplementationEnrollment struct {
+	ID         uuid.UUID           `json:"id"`
+	MotherID   uuid.UUID           `json:"mother_id"`
+	Programme  SupplementProgramme `json:"programme"`
+	Reasons    []NutritionFlag     `json:"reasons"`
+	Status     EnrollmentStatus    `json:"status"`
+	FacilityID *uuid.UUID          `json:"facility_id,omitempty"`
+	EnrolledAt time.Time           `json:"enrolled_at"`
+	EndedAt    *time.Time          `json:"ended_at,omitempty"`
+	EndReason  string              `json:"end_reason,omitempty"`
+	CreatedAt  time.Time           `json:"created_at"`
+	UpdatedAt  time.Time           `json:"updated_at"`
+}
+
+// NewSupplementationEnrollment creates an active enrollment
+func NewSupplementationEnrollment(motherID uuid.UUID, programme SupplementProgramme, reasons []NutritionFlag) *SupplementationEnrollment {
+	now := time.Now()
+	return &SupplementationEnrollment{
+		ID:         uuid.New(),
+		MotherID:   motherID,
+		Programme:  programme,
+		Reasons:    reasons,
+		Status:     EnrollmentStatusActive,
+		EnrolledAt: now,
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	}
+}
+
+// End closes the enrollment with a final status
+func (e *SupplementationEnrollment) End(status EnrollmentStatus, reason string) {
+	now := time.Now()
+	e.Status = status
+	e.EndedAt = &now
+	e.EndReason = reason
+	e.UpdatedAt = now
+}
+
+// IsActive checks if the mother is still receiving supplements
+func (e *SupplementationEnrollment) IsActive() bool {
+	return e.Status == EnrollmentStatusActive
+}
+
+// SupplementDispensing is stock handed to an enrolled mother at a visit
+type SupplementDispensing struct {
+	ID            uuid.UUID      `json:"id"`
+	EnrollmentID  uuid.UUID      `json:"enrollment_id"`
+	MotherID      uuid.UUID      `json:"mother_id"`
+	VisitID       *uuid.UUID     `json:"visit_id,omitempty"`
+	FacilityID    uuid.UUID      `json:"facility_id"`
+	Item          SupplementItem `json:"item"`
+	Quantity      int            `json:"quantity"`
+	DispensedByID *uuid.UUID     `json:"dispensed_by_id,omitempty"`
+	DispensedAt   time.Time      `json:"dispensed_at"`
+	CreatedAt     time.Time      `json:"created_at"`
+}
+
+// SupplementStock is a facility's stock on hand of a supplement
+type SupplementStock struct {
+	FacilityID     uuid.UUID      `json:"facility_id"`
+	Item           SupplementItem `json:"item"`
+	QuantityOnHand int            `json:"quantity_on_hand"`
+	UpdatedAt      time.Time      `json:"updated_at"`
+}
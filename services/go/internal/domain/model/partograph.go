@@ -0,0 +1,255 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// PartographStatus represents the status of a labour partograph
+type PartographStatus string
+
+const (
+	// PartographStatusActive represents labour that is being monitored
+	PartographStatusActive PartographStatus = "active"
+	// PartographStatusReferred represents labour referred to another facility
+	PartographStatusReferred PartographStatus = "referred"
+	// PartographStatusDelivered represents labour that ended in delivery
+	PartographStatusDelivered PartographStatus = "delivered"
+	// PartographStatusClosed represents monitoring stopped for another reason
+	PartographStatusClosed PartographStatus = "closed"
+)
+
+// LiquorStatus represents the state of the membranes and amniotic fluid
+type LiquorStatus string
+
+const (
+	// LiquorIntact represents intact membranes
+	LiquorIntact LiquorStatus = "intact"
+	// LiquorClear represents ruptured membranes with clear fluid
+	LiquorClear LiquorStatus = "clear"
+	// LiquorMeconium represents meconium-stained fluid
+	LiquorMeconium LiquorStatus = "meconium"
+	// LiquorBlood represents blood-stained fluid
+	LiquorBlood LiquorStatus = "blood"
+	// LiquorAbsent represents ruptured membranes with no fluid draining
+	LiquorAbsent LiquorStatus = "absent"
+)
+
+// LabourProgress represents where cervical dilation sits against the alert and action lines
+type LabourProgress string
+
+const (
+	// LabourProgressLatent represents labour before the active phase
+	LabourProgressLatent LabourProgress = "latent"
+	// LabourProgressNormal represents dilation on or left of the alert line
+	LabourProgressNormal LabourProgress = "normal"
+	// LabourProgressAlert represents dilation right of the alert line
+	LabourProgressAlert LabourProgress = "alert"
+	// LabourProgressAction represents dilation on or right of the action line
+	LabourProgressAction LabourProgress = "action"
+)
+
+// PartographAlertSeverity represents how urgent a partograph alert is
+type PartographAlertSeverity string
+
+const (
+	// PartographAlertWarning needs closer observation
+	PartographAlertWarning PartographAlertSeverity = "warning"
+	// PartographAlertCritical needs intervention or referral now
+	PartographAlertCritical PartographAlertSeverity = "critical"
+)
+
+// Modified WHO partograph constants
+const (
+	// ActivePhaseDilationCm is the dilation at which the active phase and the alert line start
+	ActivePhaseDilationCm = 4.0
+	// FullDilationCm is full cervical dilation
+	FullDilationCm = 10.0
+	// AlertLineRateCmPerHour is the expected rate of dilation in the active phase
+	AlertLineRateCmPerHour = 1.0
+	// ActionLineOffset is how far the action line runs to the right of the alert line
+	ActionLineOffset = 4 * time.Hour
+)
+
+// PartographObservation is one set of labour observations plotted on the partograph
+type PartographObservation struct {
+	ID                 uuid.UUID           `json:"id"`
+	RecordedAt         time.Time           `json:"recorded_at"`
+	RecordedByID       uuid.UUID           `json:"recorded_by_id"`
+	CervicalDilationCm *float64            `json:"cervical_dilation_cm,omitempty"`
+	DescentFifths      *int                `json:"descent_fifths,omitempty"` // fifths of the head palpable above the brim, 5 to 0
+	Contractions       *ContractionReading `json:"contractions,omitempty"`
+	FetalHeartRate     *float64            `json:"fetal_heart_rate,omitempty"`
+	Liquor             *LiquorStatus       `json:"liquor,omitempty"`
+	BloodPressure      *BloodPressure      `json:"blood_pressure,omitempty"`
+	PulseRate          *float64            `json:"pulse_rate,omitempty"`
+	TemperatureC       *float64            `json:"temperature_c,omitempty"`
+	HealthMetricID     *uuid.UUID          `json:"health_metric_id,omitempty"` // the vitals stored as a health metric
+	Notes              string              `json:"notes,omitempty"`
+}
+
+// PartographAlert is an alert raised from the partograph
+type PartographAlert struct {
+	Code          string                  `json:"code"`
+	Severity      PartographAlertSeverity `json:"severity"`
+	Message       string                  `json:"message"`
+	ObservationID uuid.UUID               `json:"observation_id"`
+	RaisedAt      time.Time               `json:"raised_at"`
+}
+
+// Partograph represents the labour record of a mother at a facility
+type Partograph struct {
+	ID             uuid.UUID               `json:"id"`
+	MotherID       uuid.UUID               `json:"mother_id"`
+	FacilityID     uuid.UUID               `json:"facility_id"`
+	AttendantID    uuid.UUID               `json:"attendant_id"`
+	AdmittedAt     time.Time               `json:"admitted_at"`
+	ActivePhaseAt  *time.Time              `json:"active_phase_at,omitempty"` // where the alert line starts
+	Status         PartographStatus        `json:"status"`
+	Observations   []PartographObservation `json:"observations"`
+	Alerts         []PartographAlert       `json:"alerts"`
+	ReferralSOSID  *uuid.UUID              `json:"referral_sos_id,omitempty"`
+	ReferralReason string                  `json:"referral_reason,omitempty"`
+	ClosedAt       *time.Time              `json:"closed_at,omitempty"`
+	CreatedAt      time.Time               `json:"created_at"`
+	UpdatedAt      time.Time               `json:"updated_at"`
+}
+
+// NewPartograph creates a new partograph for a mother admitted in labour
+func NewPartograph(id, motherID, facilityID, attendantID uuid.UUID, admittedAt time.Time) *Partograph {
+	now := time.Now()
+	return &Partograph{
+		ID:           id,
+		MotherID:     motherID,
+		FacilityID:   facilityID,
+		AttendantID:  attendantID,
+		AdmittedAt:   admittedAt,
+		Status:       PartographStatusActive,
+		Observations: []PartographObservation{},
+		Alerts:       []PartographAlert{},
+		CreatedAt:    now,
+		UpdatedAt:    now,
+	}
+}
+
+// IsActive checks if labour is still being monitored on this partograph
+func (p *Partograph) IsActive() bool {
+	return p.Status == PartographStatusActive
+}
+
+// AddObservation plots an observation, starting the alert line when the active phase is reached
+func (p *Partograph) AddObservation(observation PartographObservation) {
+	p.Observations = append(p.Observations, observation)
+
+	if p.ActivePhaseAt == nil && observation.CervicalDilationCm != nil &&
+		*observation.CervicalDilationCm >= ActivePhaseDilationCm {
+		// Plot the alert line from when the cervix reached 4 cm, moving it
+		// back if the mother was admitted already further dilated
+		start := observation.RecordedAt
+		if *observation.CervicalDilationCm > ActivePhaseDilationCm {
+			hours := (*observation.CervicalDilationCm - ActivePhaseDilationCm) / AlertLineRateCmPerHour
+			start = start.Add(-time.Duration(hours * float64(time.Hour)))
+		}
+		p.ActivePhaseAt = &start
+	}
+
+	p.UpdatedAt = time.Now()
+}
+
+// AlertLineDilation returns the dilation on the alert line at a time
+func (p *Partograph) AlertLineDilation(at time.Time) (float64, bool) {
+	if p.ActivePhaseAt == nil {
+		return 0, false
+	}
+	hours := at.Sub(*p.ActivePhaseAt).Hours()
+	if hours < 0 {
+		hours = 0
+	}
+	return capDilation(ActivePhaseDilationCm + hours*AlertLineRateCmPerHour), true
+}
+
+// ActionLineDilation returns the dilation on the action line at a time
+func (p *Partograph) ActionLineDilation(at time.Time) (float64, bool) {
+	if p.ActivePhaseAt == nil {
+		return 0, false
+	}
+	hours := at.Sub(p.ActivePhaseAt.Add(ActionLineOffset)).Hours()
+	if hours < 0 {
+		// The action line has not started yet
+		return 0, true
+	}
+	return capDilation(ActivePhaseDilationCm + hours*AlertLineRateCmPerHour), true
+}
+
+// ProgressAt returns where a dilation recorded at a time sits against the alert and action lines
+func (p *Partograph) ProgressAt(dilationCm float64, at time.Time) LabourProgress {
+	alert, ok := p.AlertLineDilation(at)
+	if !ok || dilationCm < ActivePhaseDilationCm {
+		return LabourProgressLatent
+	}
+	if dilationCm >= FullDilationCm || dilationCm >= alert {
+		return LabourProgressNormal
+	}
+
+	// The action line only exists once it has started
+	if at.Sub(*p.ActivePhaseAt) >= ActionLineOffset {
+		action, _ := p.ActionLineDilation(at)
+		if dilationCm <= action {
+			return LabourProgressAction
+		}
+	}
+	return LabourProgressAlert
+}
+
+// LatestDilation returns the most recent cervical dilation and when it was recorded
+func (p *Partograph) LatestDilation() (float64, time.Time, bool) {
+	for i := len(p.Observations) - 1; i >= 0; i-- {
+		if p.Observations[i].CervicalDilationCm != nil {
+			return *p.Observations[i].CervicalDilationCm, p.Observations[i].RecordedAt, true
+		}
+	}
+	return 0, time.Time{}, false
+}
+
+// HasAlert checks if an alert with the code has already been raised
+func (p *Partograph) HasAlert(code string) bool {
+	for _, alert := range p.Alerts {
+		if alert.Code == code {
+			return true
+		}
+	}
+	return false
+}
+
+// RaiseAlert records an alert
+func (p *Partograph) RaiseAlert(alert PartographAlert) {
+	p.Alerts = append(p.Alerts, alert)
+	p.UpdatedAt = time.Now()
+}
+
+// Refer marks the labour as referred through an SOS event
+func (p *Partograph) Refer(sosID uuid.UUID, reason string) {
+	now := time.Now()
+	p.Status = PartographStatusReferred
+	p.ReferralSOSID = &sosID
+	p.ReferralReason = reason
+	p.ClosedAt = &now
+	p.UpdatedAt = now
+}
+
+// Close stops monitoring with a final status, e.g. after delivery
+func (p *Partograph) Close(status PartographStatus) {
+	now := time.Now()
+	p.Status = status
+	p.ClosedAt = &now
+	p.UpdatedAt = now
+}
+
+// capDilation limits a line to full dilation
+func capDilation(cm float64) float64 {
+	if cm > FullDilationCm {
+		return FullDilationCm
+	}
+	return cm
+}
@@ -0,0 +1,62 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// DatingMethod represents how a pregnancy was dated
+type DatingMethod string
+
+const (
+	// DatingMethodLMP represents dating from the last menstrual period (Naegele's rule)
+	DatingMethodLMP DatingMethod = "lmp"
+	// DatingMethodCRL represents ultrasound dating from crown-rump length, up to 14 weeks
+	DatingMethodCRL DatingMethod = "ultrasound_crl"
+	// DatingMethodBPD represents ultrasound dating from biparietal diameter, from 14 weeks
+	DatingMethodBPD DatingMethod = "ultrasound_bpd"
+	// DatingMethodFundalHeight represents clinical dating from symphysis-fundal height
+	DatingMethodFundalHeight DatingMethod = "fundal_height"
+)
+
+// IsUltrasound checks if the method is an ultrasound measurement
+func (d DatingMethod) IsUltrasound() bool {
+	return d == DatingMethodCRL || d == DatingMethodBPD
+}
+
+// Reliability ranks dating methods, higher is more reliable
+func (d DatingMethod) Reliability() int {
+	switch d {
+	case DatingMethodCRL:
+		return 4
+	case DatingMethodBPD:
+		return 3
+	case DatingMethodLMP:
+		return 2
+	case DatingMethodFundalHeight:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// PregnancyDating is one dating estimate for a pregnancy and whether it changed the EDD
+type PregnancyDating struct {
+	ID                 uuid.UUID     `json:"id"`
+	MotherID           uuid.UUID     `json:"mother_id"`
+	Method             DatingMethod  `json:"method"`
+	ExamDate           time.Time     `json:"exam_date"`                  // when the LMP was reported or the measurement taken
+	LMP                *time.Time    `json:"lmp,omitempty"`              // for LMP dating
+	MeasurementMm      *float64      `json:"measurement_mm,omitempty"`   // CRL or BPD
+	FundalHeightCm     *float64      `json:"fundal_height_cm,omitempty"` // for fundal height dating
+	GestationalAgeDays int           `json:"gestational_age_days"`       // at the exam date
+	EstimatedEDD       time.Time     `json:"estimated_edd"`              // EDD by this estimate
+	PreviousEDD        *time.Time    `json:"previous_edd,omitempty"`     // EDD before this estimate
+	PreviousMethod     *DatingMethod `json:"previous_method,omitempty"`
+	DiscrepancyDays    int           `json:"discrepancy_days"` // estimate minus previous gestational age
+	Applied            bool          `json:"applied"`          // whether this estimate became the EDD
+	Reason             string        `json:"reason"`
+	RecordedByID       *uuid.UUID    `json:"recorded_by_id,omitempty"`
+	CreatedAt          time.Time     `json:"created_at"`
+}
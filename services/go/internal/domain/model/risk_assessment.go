@@ -0,0 +1,78 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// RiskTrigger represents what caused a risk assessment to be run
+type RiskTrigger string
+
+const (
+	// RiskTriggerManual represents an assessment requested by a health worker
+	RiskTriggerManual RiskTrigger = "manual"
+	// RiskTriggerHealthMetric represents an assessment after a new health metric was saved
+	RiskTriggerHealthMetric RiskTrigger = "health_metric"
+	// RiskTriggerConditionsChanged represents an assessment after the mother's conditions changed
+	RiskTriggerConditionsChanged RiskTrigger = "conditions_changed"
+	// RiskTriggerGestationThreshold represents an assessment after gestation crossed a rule threshold
+	RiskTriggerGestationThreshold RiskTrigger = "gestation_threshold"
+)
+
+// RiskFactors represents identified risk factors for a mother
+type RiskFactors struct {
+	AgeRelated       []string `json:"age_related,omitempty"`
+	MedicalHistory   []string `json:"medical_history,omitempty"`
+	ObstetricHistory []string `json:"obstetric_history,omitempty"`
+	CurrentVitals    []string `json:"current_vitals,omitempty"`
+	Lifestyle        []string `json:"lifestyle,omitempty"`
+}
+
+// FiredRule records a risk rule that contributed to a risk score
+type FiredRule struct {
+	ID       string `json:"id"`
+	Category string `json:"category"`
+	Factor   string `json:"factor"`
+	Points   int    `json:"points"`
+}
+
+// RiskAssessment is a stored risk assessment for a mother
+type RiskAssessment struct {
+	ID                  uuid.UUID   `json:"id"`
+	MotherID            uuid.UUID   `json:"mother_id"`
+	RiskLevel           RiskLevel   `json:"risk_level"`
+	PreviousRiskLevel   *RiskLevel  `json:"previous_risk_level,omitempty"`
+	RiskScore           int         `json:"risk_score"`
+	RiskFactors         RiskFactors `json:"risk_factors"`
+	FiredRules          []FiredRule `json:"fired_rules"`
+	RuleSetVersion      string      `json:"rule_set_version"`
+	Trigger             RiskTrigger `json:"trigger"`
+	TriggerRefID        *uuid.UUID  `json:"trigger_ref_id,omitempty"` // e.g. the health metric that caused the assessment
+	MaternalAge         *int        `json:"maternal_age,omitempty"`
+	GestationalAgeWeeks *int        `json:"gestational_age_weeks,omitempty"`
+	AssessedAt          time.Time   `json:"assessed_at"`
+	CreatedAt           time.Time   `json:"created_at"`
+}
+
+// LevelChanged checks if the assessment moved the mother to a different risk level
+func (r *RiskAssessment) LevelChanged() bool {
+	return r.PreviousRiskLevel != nil && *r.PreviousRiskLevel != r.RiskLevel
+}
+
+// IsEscalation checks if the assessment moved the mother to a higher risk level
+func (r *RiskAssessment) IsEscalation() bool {
+	return r.LevelChanged() && riskLevelRank(r.RiskLevel) > riskLevelRank(*r.PreviousRiskLevel)
+}
+
+// riskLevelRank orders risk levels from low to high
+func riskLevelRank(level RiskLevel) int {
+	switch level {
+	case RiskLevelHigh:
+		return 2
+	case RiskLevelMedium:
+		return 1
+	default:
+		return 0
+	}
+}
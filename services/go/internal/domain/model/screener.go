@@ -0,0 +1,264 @@
+package model
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ScreenerSymptom is a positive answer to a screener question, e.g. a reported danger sign
+type ScreenerSymptom struct {
+	ScreenerResultID uuid.UUID `json:"screener_result_id"`
+	QuestionID       uuid.UUID `json:"question_id"`
+	QuestionCode     string    `json:"question_code"`
+	Category         string    `json:"category"`
+	Subcategory      string    `json:"subcategory,omitempty"`
+	IsDangerSign     bool      `json:"is_danger_sign"`
+	ScreenedAt       time.Time `json:"screened_at"`
+}
+
+// ScreenerRisk is the traffic-light risk level of a screening or answer
+type ScreenerRisk string
+
+const (
+	// ScreenerRiskGreen means no risk found
+	ScreenerRiskGreen ScreenerRisk = "GREEN"
+	// ScreenerRiskYellow means moderate risk needing follow-up
+	ScreenerRiskYellow ScreenerRisk = "YELLOW"
+	// ScreenerRiskRed means high risk or an emergency
+	ScreenerRiskRed ScreenerRisk = "RED"
+)
+
+// IsValid checks if the risk level is known
+func (r ScreenerRisk) IsValid() bool {
+	return r == ScreenerRiskGreen || r == ScreenerRiskYellow || r == ScreenerRiskRed
+}
+
+// Rank orders risk levels from green (0) to red (2)
+func (r ScreenerRisk) Rank() int {
+	switch r {
+	case ScreenerRiskRed:
+		return 2
+	case ScreenerRiskYellow:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// ScreenerSubject is who a questionnaire screens
+type ScreenerSubject string
+
+const (
+	// ScreenerSubjectMother is a screening of a pregnant or postpartum mother
+	ScreenerSubjectMother ScreenerSubject = "mother"
+	// ScreenerSubjectChild is a screening of a newborn or child
+	ScreenerSubjectChild ScreenerSubject = "child"
+)
+
+// IsValid checks if the subject is known
+func (s ScreenerSubject) IsValid() bool {
+	return s == ScreenerSubjectMother || s == ScreenerSubjectChild
+}
+
+// QuestionnaireStatus is where a questionnaire version is in its life cycle
+type QuestionnaireStatus string
+
+const (
+	// QuestionnaireStatusDraft is a version still being authored
+	QuestionnaireStatusDraft QuestionnaireStatus = "DRAFT"
+	// QuestionnaireStatusPublished is the version screenings are done with. Published
+	// versions can no longer be changed.
+	QuestionnaireStatusPublished QuestionnaireStatus = "PUBLISHED"
+	// QuestionnaireStatusRetired is a version replaced by a later one, kept for the
+	// screenings done with it
+	QuestionnaireStatusRetired QuestionnaireStatus = "RETIRED"
+)
+
+// ScreenerQuestionnaire is a version of the questions asked of a subject
+type ScreenerQuestionnaire struct {
+	ID      uuid.UUID           `json:"id"`
+	Subject ScreenerSubject     `json:"subject"`
+	Version int                 `json:"version"`
+	Title   string              `json:"title"`
+	Status  QuestionnaireStatus `json:"status"`
+	// BasedOnID is the version this one was copied from
+	BasedOnID     *uuid.UUID          `json:"based_on_id,omitempty"`
+	Notes         string              `json:"notes,omitempty"`
+	CreatedByID   *uuid.UUID          `json:"created_by_id,omitempty"`
+	PublishedByID *uuid.UUID          `json:"published_by_id,omitempty"`
+	PublishedAt   *time.Time          `json:"published_at,omitempty"`
+	Questions     []*ScreenerQuestion `json:"questions,omitempty"`
+	CreatedAt     time.Time           `json:"created_at"`
+	UpdatedAt     time.Time           `json:"updated_at"`
+}
+
+// NewScreenerQuestionnaire creates a new draft questionnaire. The version is assigned when it is saved.
+func NewScreenerQuestionnaire(subject ScreenerSubject, title string) *ScreenerQuestionnaire {
+	now := time.Now()
+	return &ScreenerQuestionnaire{
+		ID:        uuid.New(),
+		Subject:   subject,
+		Title:     title,
+		Status:    QuestionnaireStatusDraft,
+		Questions: []*ScreenerQuestion{},
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+}
+
+// IsDraft reports whether the questionnaire can still be changed
+func (q *ScreenerQuestionnaire) IsDraft() bool {
+	return q.Status == QuestionnaireStatusDraft
+}
+
+// ScreenerAnswerType is the kind of answer a screener question takes
+type ScreenerAnswerType string
+
+const (
+	// ScreenerAnswerBoolean is a yes or no answer
+	ScreenerAnswerBoolean ScreenerAnswerType = "BOOLEAN"
+	// ScreenerAnswerMultipleChoice is one of the question's answer options
+	ScreenerAnswerMultipleChoice ScreenerAnswerType = "MULTIPLE_CHOICE"
+	// ScreenerAnswerNumeric is a number
+	ScreenerAnswerNumeric ScreenerAnswerType = "NUMERIC"
+	// ScreenerAnswerText is free text
+	ScreenerAnswerText ScreenerAnswerType = "TEXT"
+)
+
+// ScreenerOption is an answer option of a multiple choice question. Options are stored
+// in answer_options either as plain strings or as objects that can carry a risk level.
+type ScreenerOption struct {
+	Value          string        `json:"value"`
+	TranslationKey string        `json:"translation_key,omitempty"`
+	RiskLevel      *ScreenerRisk `json:"risk_level,omitempty"`
+}
+
+// UnmarshalJSON reads an option from either a string or an object
+func (o *ScreenerOption) UnmarshalJSON(data []byte) error {
+	var value string
+	if err := json.Unmarshal(data, &value); err == nil {
+		*o = ScreenerOption{Value: value}
+		return nil
+	}
+
+	type option ScreenerOption
+	var decoded option
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return err
+	}
+	*o = ScreenerOption(decoded)
+	return nil
+}
+
+// ScreenerQuestion is a question in a health screening
+type ScreenerQuestion struct {
+	ID              uuid.UUID          `json:"id"`
+	QuestionnaireID uuid.UUID          `json:"questionnaire_id"`
+	Text            string             `json:"question_text"`
+	Code            string             `json:"question_code"`
+	AnswerType      ScreenerAnswerType `json:"answer_type"`
+	AnswerOptions   []ScreenerOption   `json:"answer_options,omitempty"`
+	IsDangerSign    bool               `json:"is_danger_sign"`
+	// RiskCategory is the risk a positive answer indicates
+	RiskCategory *ScreenerRisk `json:"risk_category,omitempty"`
+	Category     string        `json:"category"`
+	Subcategory  string        `json:"subcategory,omitempty"`
+	DisplayOrder int           `json:"display_order"`
+	// DependsOnQuestionID and DependsOnAnswer make the question only asked when an
+	// earlier question was given that answer
+	DependsOnQuestionID *uuid.UUID `json:"depends_on_question_id,omitempty"`
+	DependsOnAnswer     *string    `json:"depends_on_answer,omitempty"`
+	TranslationKey      string     `json:"translation_key"`
+	IsActive            bool       `json:"is_active"`
+	CreatedAt           time.Time  `json:"created_at"`
+	UpdatedAt           time.Time  `json:"updated_at"`
+}
+
+// Option returns the answer option with a value, ignoring case
+func (q *ScreenerQuestion) Option(value string) (*ScreenerOption, bool) {
+	for i := range q.AnswerOptions {
+		if strings.EqualFold(q.AnswerOptions[i].Value, strings.TrimSpace(value)) {
+			return &q.AnswerOptions[i], true
+		}
+	}
+	return nil, false
+}
+
+// ScreenerAnswer is the answer to one question in a screening. Exactly one of the
+// typed answer fields is set, matching the question's answer type.
+type ScreenerAnswer struct {
+	ID               uuid.UUID `json:"id"`
+	ScreenerResultID uuid.UUID `json:"screener_result_id"`
+	QuestionID       uuid.UUID `json:"question_id"`
+	// QuestionText is the question as it was asked
+	QuestionText string   `json:"question_text"`
+	Boolean      *bool    `json:"answer_boolean,omitempty"`
+	Text         *string  `json:"answer_text,omitempty"`
+	Numeric      *float64 `json:"answer_numeric,omitempty"`
+	Option       *string  `json:"answer_option,omitempty"`
+	// ContributedToRisk is whether the answer raised the screening's risk level
+	ContributedToRisk   bool          `json:"contributed_to_risk"`
+	IndividualRiskLevel *ScreenerRisk `json:"individual_risk_level,omitempty"`
+	// Sequence is the order the question was answered in
+	Sequence  int       `json:"answer_sequence"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Value returns the answer as the string that depends_on_answer is compared with
+func (a *ScreenerAnswer) Value() string {
+	switch {
+	case a.Boolean != nil:
+		return strconv.FormatBool(*a.Boolean)
+	case a.Option != nil:
+		return *a.Option
+	case a.Numeric != nil:
+		return strconv.FormatFloat(*a.Numeric, 'f', -1, 64)
+	case a.Text != nil:
+		return *a.Text
+	}
+	return ""
+}
+
+// ScreenerResult is a screening of a mother or a child. MotherID is the mother's user ID.
+type ScreenerResult struct {
+	ID uuid.UUID `json:"id"`
+	// QuestionnaireID is the questionnaire version the screening was done with
+	QuestionnaireID *uuid.UUID `json:"questionnaire_id,omitempty"`
+	MotherID        *uuid.UUID `json:"mother_id,omitempty"`
+	ChildID         *uuid.UUID `json:"child_id,omitempty"`
+	ScreenedAt      time.Time  `json:"screened_at"`
+	ScreenedByID    *uuid.UUID `json:"screened_by_user_id,omitempty"`
+	ScreenedByName  string     `json:"screened_by_name,omitempty"`
+	// Completed is false while questions in the flow are still unanswered
+	Completed           bool              `json:"completed"`
+	Latitude            *float64          `json:"location_latitude,omitempty"`
+	Longitude           *float64          `json:"location_longitude,omitempty"`
+	FacilityID          *uuid.UUID        `json:"facility_id,omitempty"`
+	RiskLevel           ScreenerRisk      `json:"risk_level"`
+	PrimaryConcern      string            `json:"primary_concern,omitempty"`
+	DangerSignsDetected bool              `json:"danger_signs_detected"`
+	FollowupRecommended bool              `json:"followup_recommended"`
+	ActionTaken         string            `json:"action_taken,omitempty"`
+	ReferralFacilityID  *uuid.UUID        `json:"referral_facility_id,omitempty"`
+	FollowupVisitID     *uuid.UUID        `json:"followup_visit_id,omitempty"`
+	Answers             []*ScreenerAnswer `json:"answers"`
+	CreatedAt           time.Time         `json:"created_at"`
+	UpdatedAt           time.Time         `json:"updated_at"`
+}
+
+// NewScreenerResult creates a new, incomplete screening
+func NewScreenerResult(id uuid.UUID, screenedAt time.Time) *ScreenerResult {
+	now := time.Now()
+	return &ScreenerResult{
+		ID:         id,
+		ScreenedAt: screenedAt,
+		RiskLevel:  ScreenerRiskGreen,
+		Answers:    []*ScreenerAnswer{},
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	}
+}
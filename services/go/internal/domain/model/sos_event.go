@@ -16,6 +16,8 @@ const (
 	SOSEventNatureBleeding SOSEventNature = "bleeding"
 	// SOSEventNatureAccident represents an accident emergency
 	SOSEventNatureAccident SOSEventNature = "accident"
+	// SOSEventNatureNewborn represents a newborn with danger signs
+	SOSEventNatureNewborn SOSEventNature = "newborn"
 	// SOSEventNatureOther represents other types of emergencies
 	SOSEventNatureOther SOSEventNature = "other"
 )
@@ -142,7 +144,7 @@ func calculatePriority(nature SOSEventNature) int {
 	switch nature {
 	case SOSEventNatureLabor:
 		return 3
-	case SOSEventNatureBleeding:
+	case SOSEventNatureBleeding, SOSEventNatureNewborn:
 		return 4
 	case SOSEventNatureAccident:
 		return 5
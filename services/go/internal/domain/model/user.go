@@ -32,6 +32,7 @@ type User struct {
 	Phone     string    `json:"phone_number"`
 	Role      UserRole  `json:"role"`
 	District  string    `json:"district,omitempty"`
+	AssignedArea string `json:"assigned_area,omitempty"`
 	FacilityID *uuid.UUID `json:"facility_id,omitempty"`
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
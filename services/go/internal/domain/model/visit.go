@@ -16,6 +16,8 @@ const (
 	VisitTypeEmergency VisitType = "emergency"
 	// VisitTypeFollowUp represents a follow-up visit
 	VisitTypeFollowUp VisitType = "follow_up"
+	// VisitTypePostnatal represents a postnatal care (PNC) contact for mother or newborn
+	VisitTypePostnatal VisitType = "postnatal"
 )
 
 // VisitStatus represents the status of a visit
@@ -32,19 +34,49 @@ const (
 	VisitStatusCancelled VisitStatus = "cancelled"
 )
 
+// CheckInMethod represents how a check-in was verified
+type CheckInMethod string
+
+const (
+	// CheckInMethodNone represents a check-in without any verification evidence
+	CheckInMethodNone CheckInMethod = "none"
+	// CheckInMethodGeofence represents a check-in verified by the device location
+	CheckInMethodGeofence CheckInMethod = "geofence"
+	// CheckInMethodCode represents a check-in verified by the mother's rotating QR/OTP code
+	CheckInMethodCode CheckInMethod = "code"
+)
+
+// CheckInVerification records the evidence captured when a visit was checked in
+type CheckInVerification struct {
+	Method         CheckInMethod `json:"method"`
+	Verified       bool          `json:"verified"`
+	CheckedInByID  *uuid.UUID    `json:"checked_in_by_id,omitempty"`
+	Latitude       *float64      `json:"latitude,omitempty"`
+	Longitude      *float64      `json:"longitude,omitempty"`
+	AccuracyMeters *float64      `json:"accuracy_meters,omitempty"`
+	ReferencePoint string        `json:"reference_point,omitempty"` // "home" or "facility" for geofence check-ins
+	DistanceMeters *float64      `json:"distance_meters,omitempty"`
+	RadiusMeters   float64       `json:"radius_meters,omitempty"`
+	Reason         string        `json:"reason,omitempty"` // why the check-in could not be verified
+}
+
 // Visit represents a healthcare visit/appointment
 type Visit struct {
 	ID           uuid.UUID   `json:"id"`
 	MotherID     uuid.UUID   `json:"mother_id"`
+	ChildID      *uuid.UUID  `json:"child_id,omitempty"` // set when the visit is for the newborn/child
 	FacilityID   uuid.UUID   `json:"facility_id"`
 	CHWID        *uuid.UUID  `json:"chw_id,omitempty"`
 	ClinicianID  *uuid.UUID  `json:"clinician_id,omitempty"`
 	ScheduledTime time.Time   `json:"scheduled_time"`
+	DueBy         *time.Time  `json:"due_by,omitempty"` // end of the acceptable window, if wider than the scheduled time
 	CheckInTime   *time.Time  `json:"check_in_time,omitempty"`
 	CheckOutTime  *time.Time  `json:"check_out_time,omitempty"`
+	CheckInVerification *CheckInVerification `json:"check_in_verification,omitempty"`
 	VisitType     VisitType   `json:"visit_type"`
 	VisitNotes    string      `json:"visit_notes,omitempty"`
 	Status        VisitStatus `json:"status"`
+	Sequence      int         `json:"sequence"` // revision number, bumped whenever the visit is rescheduled or cancelled
 	CreatedAt     time.Time   `json:"created_at"`
 	UpdatedAt     time.Time   `json:"updated_at"`
 }
@@ -76,6 +108,18 @@ func (v *Visit) WithClinician(clinicianID uuid.UUID) *Visit {
 	return v
 }
 
+// WithChild marks the visit as being for a child of the mother
+func (v *Visit) WithChild(childID uuid.UUID) *Visit {
+	v.ChildID = &childID
+	return v
+}
+
+// WithDueBy sets the latest time the visit can happen before it counts as missed
+func (v *Visit) WithDueBy(dueBy time.Time) *Visit {
+	v.DueBy = &dueBy
+	return v
+}
+
 // WithNotes adds notes to the visit
 func (v *Visit) WithNotes(notes string) *Visit {
 	v.VisitNotes = notes
@@ -90,6 +134,17 @@ func (v *Visit) CheckIn() {
 	v.UpdatedAt = now
 }
 
+// CheckInWithVerification marks the visit as checked in and records the verification evidence
+func (v *Visit) CheckInWithVerification(verification CheckInVerification) {
+	v.CheckIn()
+	v.CheckInVerification = &verification
+}
+
+// IsCheckInVerified checks if the visit was checked in with verified evidence
+func (v *Visit) IsCheckInVerified() bool {
+	return v.CheckInVerification != nil && v.CheckInVerification.Verified
+}
+
 // Complete marks the visit as completed
 func (v *Visit) Complete(notes string) {
 	now := time.Now()
@@ -103,12 +158,14 @@ func (v *Visit) Complete(notes string) {
 func (v *Visit) Cancel() {
 	now := time.Now()
 	v.Status = VisitStatusCancelled
+	v.Sequence++
 	v.UpdatedAt = now
 }
 
 // Reschedule changes the scheduled time of the visit
 func (v *Visit) Reschedule(newTime time.Time) {
 	v.ScheduledTime = newTime
+	v.Sequence++
 	v.UpdatedAt = time.Now()
 	
 	// If the visit was cancelled, mark it as scheduled again
@@ -117,6 +174,12 @@ func (v *Visit) Reschedule(newTime time.Time) {
 	}
 }
 
+// IsHomeVisit checks if the visit takes place at the mother's home. Visits assigned to
+// a CHW are home visits, as on CHW route sheets; others take place at the facility.
+func (v *Visit) IsHomeVisit() bool {
+	return v.CHWID != nil
+}
+
 // IsUpcoming checks if the visit is upcoming
 func (v *Visit) IsUpcoming(referenceTime time.Time) bool {
 	return v.Status == VisitStatusScheduled && v.ScheduledTime.After(referenceTime)
@@ -124,8 +187,16 @@ func (v *Visit) IsUpcoming(referenceTime time.Time) bool {
 
 // IsMissed checks if the visit was missed
 func (v *Visit) IsMissed(referenceTime time.Time) bool {
-	// A visit is considered missed if it's still scheduled but the scheduled time has passed
-	return v.Status == VisitStatusScheduled && v.ScheduledTime.Before(referenceTime)
+	// A visit is considered missed if it's still scheduled but its deadline has passed
+	return v.Status == VisitStatusScheduled && v.Deadline().Before(referenceTime)
+}
+
+// Deadline returns the latest time the visit can take place
+func (v *Visit) Deadline() time.Time {
+	if v.DueBy != nil {
+		return *v.DueBy
+	}
+	return v.ScheduledTime
 }
 
 // GetDuration returns the duration of the visit if completed
@@ -0,0 +1,23 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/mamacare/services/internal/domain/model"
+)
+
+// CalendarFeedRepository defines the interface for calendar feed data access
+type CalendarFeedRepository interface {
+	// Create creates a new calendar feed
+	Create(ctx context.Context, feed *model.CalendarFeed) error
+
+	// GetByID retrieves a calendar feed by its ID
+	GetByID(ctx context.Context, id uuid.UUID) (*model.CalendarFeed, error)
+
+	// GetByOwner retrieves the calendar feeds for a user or facility
+	GetByOwner(ctx context.Context, scope model.CalendarFeedScope, ownerID uuid.UUID) ([]*model.CalendarFeed, error)
+
+	// Update updates an existing calendar feed
+	Update(ctx context.Context, feed *model.CalendarFeed) error
+}
@@ -0,0 +1,42 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mamacare/services/internal/domain/model"
+)
+
+// ChildRepository defines the interface for child registry data access
+type ChildRepository interface {
+	// Create creates a new child record
+	Create(ctx context.Context, child *model.Child) error
+
+	// GetByID retrieves a child by ID, including inactive records
+	GetByID(ctx context.Context, id uuid.UUID) (*model.Child, error)
+
+	// GetByParentID retrieves the active children whose mother or father is the user, youngest first
+	GetByParentID(ctx context.Context, userID uuid.UUID) ([]*model.Child, error)
+
+	// GetActiveBornAfter retrieves the active children born on or after the date, oldest first
+	GetActiveBornAfter(ctx context.Context, date time.Time) ([]*model.Child, error)
+
+	// GetByDeliveryOutcomeID retrieves the children registered from a delivery outcome
+	GetByDeliveryOutcomeID(ctx context.Context, outcomeID uuid.UUID) ([]*model.Child, error)
+
+	// FindPossibleDuplicates retrieves active children, other than the given one, with the same
+	// mother or the same names born within the window around the date of birth
+	FindPossibleDuplicates(ctx context.Context, child *model.Child, window time.Duration) ([]*model.Child, error)
+
+	// Update updates an existing child record
+	Update(ctx context.Context, child *model.Child) error
+
+	// UpdateGrowthStatus sets a child's current growth status, or clears it when status is nil
+	UpdateGrowthStatus(ctx context.Context, childID uuid.UUID, status *model.GrowthStatus) error
+
+	// Merge saves the primary record and the deactivated duplicate, and moves the
+	// duplicate's visits and every other record kept per child to the primary. It is run
+	// within the caller's transaction.
+	Merge(ctx context.Context, primary, duplicate *model.Child) error
+}
@@ -0,0 +1,48 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mamacare/services/internal/domain/model"
+)
+
+// CMAMRepository defines the interface for acute malnutrition treatment data access
+type CMAMRepository interface {
+	// CreateEnrollment stores a referral or enrollment
+	CreateEnrollment(ctx context.Context, enrollment *model.CMAMEnrollment) error
+
+	// UpdateEnrollment updates an enrollment's admission details, status and exit
+	UpdateEnrollment(ctx context.Context, enrollment *model.CMAMEnrollment) error
+
+	// GetEnrollmentByID retrieves an enrollment
+	GetEnrollmentByID(ctx context.Context, id uuid.UUID) (*model.CMAMEnrollment, error)
+
+	// GetOpenEnrollment retrieves a child's referred or active enrollment, or a NotFound error if they have none
+	GetOpenEnrollment(ctx context.Context, childID uuid.UUID) (*model.CMAMEnrollment, error)
+
+	// GetEnrollmentsByChildID retrieves a child's enrollments, newest first
+	GetEnrollmentsByChildID(ctx context.Context, childID uuid.UUID) ([]*model.CMAMEnrollment, error)
+
+	// CreateFollowUp schedules a follow-up
+	CreateFollowUp(ctx context.Context, followUp *model.CMAMFollowUp) error
+
+	// UpdateFollowUp updates a follow-up's status and measurements
+	UpdateFollowUp(ctx context.Context, followUp *model.CMAMFollowUp) error
+
+	// RecordFollowUp updates an attended follow-up and takes its RUTF ration off the
+	// facility's stock in one statement. It returns the stock left, or a BadRequest
+	// error if there is not enough.
+	RecordFollowUp(ctx context.Context, followUp *model.CMAMFollowUp, facilityID uuid.UUID) (*model.SupplementStock, error)
+
+	// GetFollowUpsByEnrollment retrieves an enrollment's follow-ups in week order
+	GetFollowUpsByEnrollment(ctx context.Context, enrollmentID uuid.UUID) ([]*model.CMAMFollowUp, error)
+
+	// GetOverdueFollowUps retrieves scheduled follow-ups of active enrollments due before the given date
+	GetOverdueFollowUps(ctx context.Context, before time.Time) ([]*model.CMAMFollowUp, error)
+
+	// GetFacilityOutcomes counts admissions and exits by outcome per facility and programme
+	// over a period, for one facility or all of them if facilityID is nil
+	GetFacilityOutcomes(ctx context.Context, from, to time.Time, facilityID *uuid.UUID) ([]*model.CMAMFacilityOutcomes, error)
+}
@@ -0,0 +1,27 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mamacare/services/internal/domain/model"
+)
+
+// DeliveryOutcomeRepository defines the interface for delivery outcome data access
+type DeliveryOutcomeRepository interface {
+	// Create creates a new delivery outcome
+	Create(ctx context.Context, outcome *model.DeliveryOutcome) error
+
+	// GetByID retrieves a delivery outcome by its ID
+	GetByID(ctx context.Context, id uuid.UUID) (*model.DeliveryOutcome, error)
+
+	// GetByMotherID retrieves delivery outcomes for a mother, most recent first
+	GetByMotherID(ctx context.Context, motherID uuid.UUID) ([]*model.DeliveryOutcome, error)
+
+	// GetByDateRange retrieves delivery outcomes with a delivery date in the range
+	GetByDateRange(ctx context.Context, start, end time.Time) ([]*model.DeliveryOutcome, error)
+
+	// Update updates an existing delivery outcome
+	Update(ctx context.Context, outcome *model.DeliveryOutcome) error
+}
@@ -0,0 +1,34 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mamacare/services/internal/domain/model"
+)
+
+// FamilyPlanningRepository defines the interface for family planning data access
+type FamilyPlanningRepository interface {
+	// CreateVisit stores a family planning visit
+	CreateVisit(ctx context.Context, visit *model.FamilyPlanningVisit) error
+
+	// GetVisitsByMotherID retrieves a mother's family planning visits, newest first
+	GetVisitsByMotherID(ctx context.Context, motherID uuid.UUID) ([]*model.FamilyPlanningVisit, error)
+
+	// GetRemindersDue retrieves each mother's latest visit at which a method was given or
+	// stopped, where a method was given with a next due date on or before the given date
+	// and the reminder of the given kind has not been sent
+	GetRemindersDue(ctx context.Context, kind model.FPReminderKind, before time.Time) ([]*model.FamilyPlanningVisit, error)
+
+	// MarkReminderSent records a reminder for a visit, returning false if it was already sent
+	MarkReminderSent(ctx context.Context, visitID uuid.UUID, kind model.FPReminderKind) (bool, error)
+
+	// ClearReminderSent forgets a reminder recorded for a visit, so it is sent on the next run
+	ClearReminderSent(ctx context.Context, visitID uuid.UUID, kind model.FPReminderKind) error
+
+	// GetFacilityCoverage counts deliveries and family planning services per facility over
+	// a period, for one facility or all of them if facilityID is nil, and one district or
+	// all of them if district is empty
+	GetFacilityCoverage(ctx context.Context, from, to time.Time, facilityID *uuid.UUID, district string) ([]*model.FamilyPlanningCoverage, error)
+}
@@ -0,0 +1,30 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mamacare/services/internal/domain/model"
+)
+
+// GroupSessionRepository defines the interface for group ANC session data access
+type GroupSessionRepository interface {
+	// Create creates a new group session
+	Create(ctx context.Context, session *model.GroupSession) error
+
+	// GetByID retrieves a group session by its ID
+	GetByID(ctx context.Context, id uuid.UUID) (*model.GroupSession, error)
+
+	// GetByIDForUpdate retrieves a group session and locks it until the surrounding transaction ends
+	GetByIDForUpdate(ctx context.Context, id uuid.UUID) (*model.GroupSession, error)
+
+	// GetByFacilityID retrieves the group sessions at a facility scheduled in the range
+	GetByFacilityID(ctx context.Context, facilityID uuid.UUID, start, end time.Time) ([]*model.GroupSession, error)
+
+	// GetByMotherID retrieves the group sessions a mother is enrolled in, most recent first
+	GetByMotherID(ctx context.Context, motherID uuid.UUID) ([]*model.GroupSession, error)
+
+	// Update updates an existing group session, including its roster
+	Update(ctx context.Context, session *model.GroupSession) error
+}
@@ -0,0 +1,20 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/mamacare/services/internal/domain/model"
+)
+
+// GrowthMeasurementRepository defines the interface for child growth measurement data access
+type GrowthMeasurementRepository interface {
+	// Create stores a growth measurement
+	Create(ctx context.Context, measurement *model.GrowthMeasurement) error
+
+	// GetByChildID retrieves a child's measurements, oldest first
+	GetByChildID(ctx context.Context, childID uuid.UUID) ([]*model.GrowthMeasurement, error)
+
+	// GetLatestByChildID retrieves a child's most recent measurement
+	GetLatestByChildID(ctx context.Context, childID uuid.UUID) (*model.GrowthMeasurement, error)
+}
@@ -0,0 +1,24 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/mamacare/services/internal/domain/model"
+)
+
+// ImmunizationRepository defines the interface for EPI schedule and immunization data access
+type ImmunizationRepository interface {
+	// GetSchedules retrieves the active vaccine schedule, youngest age first
+	GetSchedules(ctx context.Context) ([]*model.VaccineSchedule, error)
+
+	// CreateRecord stores a vaccine dose given to a child
+	CreateRecord(ctx context.Context, record *model.ImmunizationRecord) error
+
+	// GetRecordsByChildID retrieves a child's doses, oldest first
+	GetRecordsByChildID(ctx context.Context, childID uuid.UUID) ([]*model.ImmunizationRecord, error)
+
+	// MarkReminderSent records a reminder for a child's dose. It returns false if that
+	// reminder was already sent, so each reminder goes out once.
+	MarkReminderSent(ctx context.Context, childID uuid.UUID, vaccineName, dose string, kind model.VaccineReminderKind) (bool, error)
+}
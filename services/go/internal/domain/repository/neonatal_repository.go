@@ -0,0 +1,35 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/mamacare/services/internal/domain/model"
+)
+
+// NeonatalRepository defines the interface for newborn care data access
+type NeonatalRepository interface {
+	// CreateRecord stores a child's neonatal record, or returns an AlreadyExists error if they have one
+	CreateRecord(ctx context.Context, record *model.NeonatalRecord) error
+
+	// UpdateRecord updates a child's neonatal record
+	UpdateRecord(ctx context.Context, record *model.NeonatalRecord) error
+
+	// GetRecordByChildID retrieves a child's neonatal record
+	GetRecordByChildID(ctx context.Context, childID uuid.UUID) (*model.NeonatalRecord, error)
+
+	// SaveKMCSession stores a day of kangaroo mother care, replacing any already recorded for that day
+	SaveKMCSession(ctx context.Context, session *model.KMCSession) error
+
+	// GetKMCSessions retrieves a child's kangaroo mother care in date order
+	GetKMCSessions(ctx context.Context, childID uuid.UUID) ([]*model.KMCSession, error)
+
+	// CreateCheck stores a newborn check
+	CreateCheck(ctx context.Context, check *model.NeonatalCheck) error
+
+	// UpdateCheck updates the referral and follow-up raised by a newborn check
+	UpdateCheck(ctx context.Context, check *model.NeonatalCheck) error
+
+	// GetChecksByChildID retrieves a child's newborn checks, newest first
+	GetChecksByChildID(ctx context.Context, childID uuid.UUID) ([]*model.NeonatalCheck, error)
+}
@@ -0,0 +1,39 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/mamacare/services/internal/domain/model"
+)
+
+// NutritionRepository defines the interface for maternal nutrition data access
+type NutritionRepository interface {
+	// CreateCounselling stores a dietary counselling session
+	CreateCounselling(ctx context.Context, counselling *model.NutritionCounselling) error
+
+	// GetCounsellingByMotherID retrieves a mother's counselling sessions, newest first
+	GetCounsellingByMotherID(ctx context.Context, motherID uuid.UUID) ([]*model.NutritionCounselling, error)
+
+	// SaveEnrollment creates or updates a supplementation enrollment
+	SaveEnrollment(ctx context.Context, enrollment *model.SupplementationEnrollment) error
+
+	// GetEnrollmentByID retrieves a supplementation enrollment
+	GetEnrollmentByID(ctx context.Context, id uuid.UUID) (*model.SupplementationEnrollment, error)
+
+	// GetActiveEnrollment retrieves a mother's active enrollment, or a NotFound error if she has none
+	GetActiveEnrollment(ctx context.Context, motherID uuid.UUID) (*model.SupplementationEnrollment, error)
+
+	// Dispense records supplements handed out and takes them off the facility's stock in one
+	// statement. It returns the stock left, or a BadRequest error if there is not enough.
+	Dispense(ctx context.Context, dispensing *model.SupplementDispensing) (*model.SupplementStock, error)
+
+	// GetDispensingsByEnrollment retrieves the supplements dispensed for an enrollment, oldest first
+	GetDispensingsByEnrollment(ctx context.Context, enrollmentID uuid.UUID) ([]*model.SupplementDispensing, error)
+
+	// AddStock adds received supplements to a facility's stock and returns the new level
+	AddStock(ctx context.Context, facilityID uuid.UUID, item model.SupplementItem, quantity int) (*model.SupplementStock, error)
+
+	// GetStockByFacility retrieves a facility's stock of every supplement it holds
+	GetStockByFacility(ctx context.Context, facilityID uuid.UUID) ([]*model.SupplementStock, error)
+}
@@ -0,0 +1,29 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/mamacare/services/internal/domain/model"
+)
+
+// PartographRepository defines the interface for partograph data access
+type PartographRepository interface {
+	// Create creates a new partograph
+	Create(ctx context.Context, partograph *model.Partograph) error
+
+	// GetByID retrieves a partograph by its ID
+	GetByID(ctx context.Context, id uuid.UUID) (*model.Partograph, error)
+
+	// GetByIDForUpdate retrieves a partograph and locks it until the surrounding transaction ends
+	GetByIDForUpdate(ctx context.Context, id uuid.UUID) (*model.Partograph, error)
+
+	// GetActiveByMotherID retrieves the mother's partograph that is still being monitored
+	GetActiveByMotherID(ctx context.Context, motherID uuid.UUID) (*model.Partograph, error)
+
+	// GetActiveByFacilityID retrieves the partographs being monitored at a facility
+	GetActiveByFacilityID(ctx context.Context, facilityID uuid.UUID) ([]*model.Partograph, error)
+
+	// Update updates an existing partograph, including its observations and alerts
+	Update(ctx context.Context, partograph *model.Partograph) error
+}
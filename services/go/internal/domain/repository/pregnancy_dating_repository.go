@@ -0,0 +1,17 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/mamacare/services/internal/domain/model"
+)
+
+// PregnancyDatingRepository defines the interface for pregnancy dating history data access
+type PregnancyDatingRepository interface {
+	// Create stores a new dating estimate
+	Create(ctx context.Context, dating *model.PregnancyDating) error
+
+	// GetByMotherID retrieves a mother's dating history, oldest first
+	GetByMotherID(ctx context.Context, motherID uuid.UUID) ([]*model.PregnancyDating, error)
+}
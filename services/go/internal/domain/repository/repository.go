@@ -28,6 +28,7 @@ type MotherRepository interface {
 	FindByUserID(ctx context.Context, userID uuid.UUID) (*model.Mother, error)
 	FindByDueDate(ctx context.Context, startDate, endDate time.Time) ([]*model.Mother, error)
 	FindByDistrict(ctx context.Context, district string) ([]*model.Mother, error)
+	FindByDistrictAndDueDate(ctx context.Context, district string, startDate, endDate time.Time) ([]*model.Mother, error)
 	FindByRiskLevel(ctx context.Context, riskLevel model.RiskLevel) ([]*model.Mother, error)
 	FindAll(ctx context.Context) ([]*model.Mother, error)
 	Save(ctx context.Context, mother *model.Mother) error
@@ -0,0 +1,23 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/mamacare/services/internal/domain/model"
+)
+
+// RiskAssessmentRepository defines the interface for risk assessment data access
+type RiskAssessmentRepository interface {
+	// Create stores a new risk assessment
+	Create(ctx context.Context, assessment *model.RiskAssessment) error
+
+	// GetByID retrieves a risk assessment by its ID
+	GetByID(ctx context.Context, id uuid.UUID) (*model.RiskAssessment, error)
+
+	// GetLatestByMotherID retrieves the most recent risk assessment for a mother
+	GetLatestByMotherID(ctx context.Context, motherID uuid.UUID) (*model.RiskAssessment, error)
+
+	// GetByMotherID retrieves a mother's risk assessments, most recent first
+	GetByMotherID(ctx context.Context, motherID uuid.UUID, limit int) ([]*model.RiskAssessment, error)
+}
@@ -0,0 +1,62 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mamacare/services/internal/domain/model"
+)
+
+// ScreenerRepository defines the interface for screener data access
+type ScreenerRepository interface {
+	// GetSymptomsByUserID retrieves the positive answers recorded in a mother's screenings
+	// since the given time, most recent first. Screener results reference the mother's user ID.
+	GetSymptomsByUserID(ctx context.Context, userID uuid.UUID, since time.Time) ([]*model.ScreenerSymptom, error)
+
+	// GetQuestionnaires retrieves the questionnaire versions, newest first and without their
+	// questions. A nil subject returns the versions of both subjects.
+	GetQuestionnaires(ctx context.Context, subject *model.ScreenerSubject) ([]*model.ScreenerQuestionnaire, error)
+
+	// GetQuestionnaireByID retrieves a questionnaire version with all its questions in display order
+	GetQuestionnaireByID(ctx context.Context, id uuid.UUID) (*model.ScreenerQuestionnaire, error)
+
+	// GetPublishedQuestionnaire retrieves the version a subject is screened with, with all its
+	// questions in display order
+	GetPublishedQuestionnaire(ctx context.Context, subject model.ScreenerSubject) (*model.ScreenerQuestionnaire, error)
+
+	// CreateQuestionnaire creates a draft questionnaire, numbering it after the subject's
+	// latest version. A draft based on another version starts with copies of its questions.
+	CreateQuestionnaire(ctx context.Context, questionnaire *model.ScreenerQuestionnaire) error
+
+	// UpdateQuestionnaire updates a draft questionnaire's title and notes
+	UpdateQuestionnaire(ctx context.Context, questionnaire *model.ScreenerQuestionnaire) error
+
+	// PublishQuestionnaire publishes a draft questionnaire and retires the version of the
+	// same subject published before it
+	PublishQuestionnaire(ctx context.Context, id, publishedByID uuid.UUID, publishedAt time.Time) error
+
+	// GetQuestionByID retrieves a screener question by ID
+	GetQuestionByID(ctx context.Context, id uuid.UUID) (*model.ScreenerQuestion, error)
+
+	// CreateQuestion creates a question in a draft questionnaire
+	CreateQuestion(ctx context.Context, question *model.ScreenerQuestion) error
+
+	// UpdateQuestion updates a question of a draft questionnaire
+	UpdateQuestion(ctx context.Context, question *model.ScreenerQuestion) error
+
+	// DeleteQuestion deletes a question of a draft questionnaire
+	DeleteQuestion(ctx context.Context, id uuid.UUID) error
+
+	// CreateResult creates a new screening result without its answers
+	CreateResult(ctx context.Context, result *model.ScreenerResult) error
+
+	// UpdateResult updates a screening result's completion, risk evaluation and actions
+	UpdateResult(ctx context.Context, result *model.ScreenerResult) error
+
+	// GetResultByID retrieves a screening result with its answers in the order they were given
+	GetResultByID(ctx context.Context, id uuid.UUID) (*model.ScreenerResult, error)
+
+	// CreateAnswer records an answer in a screening. Each question can only be answered once.
+	CreateAnswer(ctx context.Context, answer *model.ScreenerAnswer) error
+}
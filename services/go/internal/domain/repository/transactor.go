@@ -0,0 +1,10 @@
+package repository
+
+import "context"
+
+// Transactor runs work in a single database transaction. Repository calls made with
+// the context passed to fn take part in the transaction, which is committed if fn
+// returns nil and rolled back otherwise.
+type Transactor interface {
+	WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error
+}
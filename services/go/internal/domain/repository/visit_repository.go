@@ -28,7 +28,9 @@ type VisitRepository interface {
 	// GetUpcoming retrieves upcoming visits
 	GetUpcoming(ctx context.Context, options *VisitQueryOptions) ([]*model.Visit, error)
 
-	// GetOverdue retrieves overdue visits (missed)
+	// GetOverdue retrieves overdue visits (missed): scheduled visits whose deadline, the due
+	// by time or else the scheduled time, has passed. The date range filters the scheduled
+	// time and the limit and offset page through the overdue visits only.
 	GetOverdue(ctx context.Context, options *VisitQueryOptions) ([]*model.Visit, error)
 
 	// Update updates a visit
@@ -3,7 +3,11 @@ package database
 import (
 	"context"
 	"fmt"
+	"io/fs"
+	"regexp"
 	"sort"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/jackc/pgx/v5/pgxpool"
@@ -44,6 +48,45 @@ func (mm *MigrationManager) AddMigration(version int, description, sql string) {
 	})
 }
 
+// migrationFilePattern matches a migration file name, e.g. 000002_postnatal_care.sql
+var migrationFilePattern = regexp.MustCompile(`^(\d+)_(\w+)\.sql$`)
+
+// AddMigrationsFS adds every migration file in a file system, named by version and
+// description like 000002_postnatal_care.sql. Rollback files ending in _down.sql are skipped.
+func (mm *MigrationManager) AddMigrationsFS(fsys fs.FS) error {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return errorx.Wrap(err, errorx.InternalServerError, "failed to read migrations")
+	}
+
+	versions := make(map[int]string)
+	for _, entry := range entries {
+		match := migrationFilePattern.FindStringSubmatch(entry.Name())
+		if entry.IsDir() || match == nil || strings.HasSuffix(match[2], "_down") {
+			continue
+		}
+
+		version, err := strconv.Atoi(match[1])
+		if err != nil {
+			return errorx.Wrap(err, errorx.InternalServerError, fmt.Sprintf("invalid migration version in %s", entry.Name()))
+		}
+		if other, ok := versions[version]; ok {
+			return errorx.New(errorx.InternalServerError, fmt.Sprintf("migrations %s and %s have the same version", other, entry.Name()))
+		}
+		versions[version] = entry.Name()
+
+		sql, err := fs.ReadFile(fsys, entry.Name())
+		if err != nil {
+			return errorx.Wrap(err, errorx.InternalServerError, fmt.Sprintf("failed to read migration %s", entry.Name()))
+		}
+
+		description := strings.ReplaceAll(match[2], "_", " ")
+		mm.AddMigration(version, strings.ToUpper(description[:1])+description[1:], string(sql))
+	}
+
+	return nil
+}
+
 // Initialize sets up the migrations table if it doesn't exist
 func (mm *MigrationManager) Initialize(ctx context.Context) error {
 	if mm.initialized {
@@ -0,0 +1,55 @@
+-- Postnatal Care Migration for MamaCare
+-- Records delivery outcomes and supports postnatal (PNC) contacts for mother and newborn
+
+ALTER TYPE visit_type ADD VALUE IF NOT EXISTS 'POSTNATAL';
+
+CREATE TYPE pregnancy_stage AS ENUM (
+  'FIRST_TRIMESTER',
+  'SECOND_TRIMESTER',
+  'THIRD_TRIMESTER',
+  'POSTPARTUM'
+);
+
+ALTER TABLE mothers
+  ADD COLUMN pregnancy_stage pregnancy_stage NOT NULL DEFAULT 'FIRST_TRIMESTER',
+  ADD COLUMN delivery_date DATE;
+
+-- Existing mothers take their stage from the expected delivery date, with weeks pregnant
+-- counted as Mother.GetWeeksPregnant does; no delivery has been recorded for them yet
+UPDATE mothers
+SET pregnancy_stage = CASE
+  WHEN 40 - (expected_delivery_date - CURRENT_DATE) / 7 < 13 THEN 'FIRST_TRIMESTER'::pregnancy_stage
+  WHEN 40 - (expected_delivery_date - CURRENT_DATE) / 7 < 27 THEN 'SECOND_TRIMESTER'::pregnancy_stage
+  ELSE 'THIRD_TRIMESTER'::pregnancy_stage
+END;
+
+-- Newborn PNC contacts are tracked against the mother with the child recorded alongside;
+-- due_by holds the end of the contact window so overdue tracking respects it
+ALTER TABLE visits
+  ADD COLUMN child_id UUID,
+  ADD COLUMN due_by TIMESTAMP WITH TIME ZONE;
+
+CREATE TABLE delivery_outcomes (
+  id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+  mother_id UUID NOT NULL REFERENCES mothers(id),
+  delivery_date TIMESTAMP WITH TIME ZONE NOT NULL,
+  place VARCHAR(20) NOT NULL CHECK (place IN ('facility', 'home', 'in_transit', 'other')),
+  facility_id UUID REFERENCES facilities(id),
+  mode VARCHAR(20) NOT NULL CHECK (mode IN ('vaginal', 'assisted', 'caesarean')),
+  gestational_age_weeks INTEGER CHECK (gestational_age_weeks BETWEEN 20 AND 45),
+  maternal_outcome VARCHAR(20) NOT NULL CHECK (maternal_outcome IN ('well', 'complications', 'referred', 'died')),
+  maternal_complications TEXT[],
+  newborns JSONB NOT NULL DEFAULT '[]',
+  attended_by_id UUID REFERENCES users(id),
+  recorded_by_id UUID NOT NULL REFERENCES users(id),
+  notes TEXT,
+  created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+  updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+  CONSTRAINT facility_delivery_has_facility CHECK (place != 'facility' OR facility_id IS NOT NULL)
+);
+
+CREATE INDEX idx_mothers_pregnancy_stage ON mothers (pregnancy_stage);
+CREATE INDEX idx_visits_child_id ON visits (child_id);
+CREATE INDEX idx_visits_due_by ON visits (due_by);
+CREATE INDEX idx_delivery_outcomes_mother_id ON delivery_outcomes (mother_id);
+CREATE INDEX idx_delivery_outcomes_delivery_date ON delivery_outcomes (delivery_date);
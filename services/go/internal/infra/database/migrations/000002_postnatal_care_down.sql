@@ -0,0 +1,14 @@
+-- Rollback Migration for Postnatal Care
+-- Note: PostgreSQL cannot remove the POSTNATAL value from visit_type
+
+DROP TABLE IF EXISTS delivery_outcomes;
+
+ALTER TABLE visits
+  DROP COLUMN IF EXISTS due_by,
+  DROP COLUMN IF EXISTS child_id;
+
+ALTER TABLE mothers
+  DROP COLUMN IF EXISTS delivery_date,
+  DROP COLUMN IF EXISTS pregnancy_stage;
+
+DROP TYPE IF EXISTS pregnancy_stage;
@@ -0,0 +1,20 @@
+-- Calendar Feeds Migration for MamaCare
+-- Subscribable iCalendar feeds of visits per user and per facility
+
+-- Visits carry a revision number so calendar clients pick up reschedules and cancellations
+ALTER TABLE visits
+  ADD COLUMN sequence INTEGER NOT NULL DEFAULT 0;
+
+CREATE TABLE calendar_feeds (
+  id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+  scope VARCHAR(20) NOT NULL CHECK (scope IN ('user', 'facility')),
+  owner_id UUID NOT NULL,
+  name VARCHAR(255) NOT NULL,
+  created_by_id UUID NOT NULL REFERENCES users(id),
+  revoked_at TIMESTAMP WITH TIME ZONE,
+  last_accessed_at TIMESTAMP WITH TIME ZONE,
+  created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+  updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_calendar_feeds_owner ON calendar_feeds (scope, owner_id);
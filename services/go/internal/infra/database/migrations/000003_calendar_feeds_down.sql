@@ -0,0 +1,6 @@
+-- Rollback Migration for Calendar Feeds
+
+DROP TABLE IF EXISTS calendar_feeds;
+
+ALTER TABLE visits
+  DROP COLUMN IF EXISTS sequence;
@@ -0,0 +1,8 @@
+-- Verified Check-ins Migration for MamaCare
+-- Stores the geofence or QR/OTP evidence captured when a visit is checked in
+
+ALTER TABLE visits
+  ADD COLUMN check_in_verification JSONB;
+
+CREATE INDEX idx_visits_check_in_verified ON visits (((check_in_verification->>'verified')::BOOLEAN))
+  WHERE check_in_verification IS NOT NULL;
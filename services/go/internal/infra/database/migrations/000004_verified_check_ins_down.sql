@@ -0,0 +1,6 @@
+-- Rollback Migration for Verified Check-ins
+
+DROP INDEX IF EXISTS idx_visits_check_in_verified;
+
+ALTER TABLE visits
+  DROP COLUMN IF EXISTS check_in_verification;
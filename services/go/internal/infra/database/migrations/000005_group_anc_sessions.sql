@@ -0,0 +1,23 @@
+-- Group ANC Sessions Migration for MamaCare
+-- Group antenatal care sessions run by a facilitator for a cohort of mothers
+
+CREATE TABLE group_sessions (
+  id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+  facility_id UUID NOT NULL REFERENCES facilities(id),
+  facilitator_id UUID NOT NULL REFERENCES users(id),
+  title VARCHAR(255) NOT NULL,
+  topic_content_id UUID REFERENCES education_content(id),
+  scheduled_time TIMESTAMP WITH TIME ZONE NOT NULL,
+  duration_minutes INTEGER NOT NULL CHECK (duration_minutes > 0),
+  capacity INTEGER NOT NULL CHECK (capacity > 0),
+  status VARCHAR(20) NOT NULL DEFAULT 'scheduled' CHECK (status IN ('scheduled', 'completed', 'cancelled')),
+  -- Roster of mothers with their attendance and the ANC contact recorded for it
+  members JSONB NOT NULL DEFAULT '[]',
+  notes TEXT,
+  created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+  updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_group_sessions_facility_time ON group_sessions (facility_id, scheduled_time);
+CREATE INDEX idx_group_sessions_facilitator_id ON group_sessions (facilitator_id);
+CREATE INDEX idx_group_sessions_members ON group_sessions USING GIN (members jsonb_path_ops);
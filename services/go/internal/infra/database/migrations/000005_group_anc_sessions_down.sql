@@ -0,0 +1,3 @@
+-- Rollback Migration for Group ANC Sessions
+
+DROP TABLE IF EXISTS group_sessions;
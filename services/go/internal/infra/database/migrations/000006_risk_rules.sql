@@ -0,0 +1,5 @@
+-- Risk Rules Migration for MamaCare
+-- Date of birth is needed for the age bands in the risk rule sets
+
+ALTER TABLE mothers
+  ADD COLUMN date_of_birth DATE;
@@ -0,0 +1,4 @@
+-- Rollback Migration for Risk Rules
+
+ALTER TABLE mothers
+  DROP COLUMN IF EXISTS date_of_birth;
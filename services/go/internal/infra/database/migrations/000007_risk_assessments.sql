@@ -0,0 +1,22 @@
+-- Risk Assessments Migration for MamaCare
+-- Every risk assessment is stored with its factors, the rule-set version and the rules that fired
+
+CREATE TABLE risk_assessments (
+  id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+  mother_id UUID NOT NULL REFERENCES mothers(id),
+  risk_level risk_level NOT NULL,
+  previous_risk_level risk_level,
+  risk_score INTEGER NOT NULL,
+  risk_factors JSONB NOT NULL DEFAULT '{}',
+  fired_rules JSONB NOT NULL DEFAULT '[]',
+  rule_set_version VARCHAR(50) NOT NULL,
+  trigger VARCHAR(30) NOT NULL CHECK (trigger IN ('manual', 'health_metric', 'conditions_changed', 'gestation_threshold')),
+  trigger_ref_id UUID,
+  maternal_age INTEGER,
+  gestational_age_weeks INTEGER,
+  assessed_at TIMESTAMP WITH TIME ZONE NOT NULL,
+  created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_risk_assessments_mother_assessed ON risk_assessments (mother_id, assessed_at DESC);
+CREATE INDEX idx_risk_assessments_rule_set_version ON risk_assessments (rule_set_version);
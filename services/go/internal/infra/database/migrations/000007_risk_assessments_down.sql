@@ -0,0 +1,3 @@
+-- Rollback Migration for Risk Assessments
+
+DROP TABLE IF EXISTS risk_assessments;
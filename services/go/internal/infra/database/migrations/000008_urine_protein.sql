@@ -0,0 +1,6 @@
+-- Urine Protein Migration for MamaCare
+-- Dipstick results are needed to screen for pre-eclampsia
+
+ALTER TABLE health_metrics
+  ADD COLUMN urine_protein TEXT
+  CHECK (urine_protein IN ('negative', 'trace', '1+', '2+', '3+', '4+'));
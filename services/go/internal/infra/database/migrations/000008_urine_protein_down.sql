@@ -0,0 +1,4 @@
+-- Rollback Migration for Urine Protein
+
+ALTER TABLE health_metrics
+  DROP COLUMN IF EXISTS urine_protein;
@@ -0,0 +1,11 @@
+-- Reference Ranges Migration for MamaCare
+-- Glucose sample timing separates fasting and OGTT values, and height with
+-- pre-pregnancy weight give the BMI that sets weight-gain targets
+
+ALTER TABLE health_metrics
+  ADD COLUMN blood_sugar_timing TEXT
+  CHECK (blood_sugar_timing IN ('fasting', 'ogtt_1h', 'ogtt_2h', 'random'));
+
+ALTER TABLE mothers
+  ADD COLUMN height_cm DECIMAL(5,1),
+  ADD COLUMN pre_pregnancy_weight_kg DECIMAL(5,1);
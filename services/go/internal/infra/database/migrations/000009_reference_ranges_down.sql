@@ -0,0 +1,8 @@
+-- Rollback Migration for Reference Ranges
+
+ALTER TABLE mothers
+  DROP COLUMN IF EXISTS pre_pregnancy_weight_kg,
+  DROP COLUMN IF EXISTS height_cm;
+
+ALTER TABLE health_metrics
+  DROP COLUMN IF EXISTS blood_sugar_timing;
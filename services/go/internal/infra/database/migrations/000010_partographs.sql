@@ -0,0 +1,26 @@
+-- Partographs Migration for MamaCare
+-- Labour monitoring records with observations plotted against alert and action lines
+
+CREATE TABLE partographs (
+  id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+  mother_id UUID NOT NULL REFERENCES mothers(id) ON DELETE CASCADE,
+  facility_id UUID NOT NULL REFERENCES facilities(id),
+  attendant_id UUID NOT NULL REFERENCES users(id),
+  admitted_at TIMESTAMP WITH TIME ZONE NOT NULL,
+  active_phase_at TIMESTAMP WITH TIME ZONE,
+  status VARCHAR(20) NOT NULL DEFAULT 'active' CHECK (status IN ('active', 'referred', 'delivered', 'closed')),
+  -- Observations and alerts in the order they were recorded
+  observations JSONB NOT NULL DEFAULT '[]',
+  alerts JSONB NOT NULL DEFAULT '[]',
+  referral_sos_id UUID REFERENCES sos_events(id),
+  referral_reason TEXT,
+  closed_at TIMESTAMP WITH TIME ZONE,
+  created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+  updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_partographs_mother_id ON partographs (mother_id);
+CREATE INDEX idx_partographs_facility_status ON partographs (facility_id, status);
+
+-- Only one labour can be monitored at a time per mother
+CREATE UNIQUE INDEX idx_partographs_active_mother ON partographs (mother_id) WHERE status = 'active';
@@ -0,0 +1,3 @@
+-- Rollback Migration for Partographs
+
+DROP TABLE IF EXISTS partographs;
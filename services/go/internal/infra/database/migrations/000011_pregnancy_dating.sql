@@ -0,0 +1,29 @@
+-- Pregnancy Dating Migration for MamaCare
+-- Mothers keep their LMP and the method their EDD came from, and every
+-- LMP, ultrasound or fundal height estimate is kept as dating history
+
+ALTER TABLE mothers
+  ADD COLUMN lmp DATE,
+  ADD COLUMN dating_method VARCHAR(20) NOT NULL DEFAULT 'lmp'
+  CHECK (dating_method IN ('lmp', 'ultrasound_crl', 'ultrasound_bpd', 'fundal_height'));
+
+CREATE TABLE pregnancy_datings (
+  id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+  mother_id UUID NOT NULL REFERENCES mothers(id),
+  method VARCHAR(20) NOT NULL CHECK (method IN ('lmp', 'ultrasound_crl', 'ultrasound_bpd', 'fundal_height')),
+  exam_date DATE NOT NULL,
+  lmp DATE,
+  measurement_mm DECIMAL(5,1),
+  fundal_height_cm DECIMAL(4,1),
+  gestational_age_days INTEGER NOT NULL,
+  estimated_edd DATE NOT NULL,
+  previous_edd DATE,
+  previous_method VARCHAR(20),
+  discrepancy_days INTEGER NOT NULL DEFAULT 0,
+  applied BOOLEAN NOT NULL,
+  reason TEXT NOT NULL,
+  recorded_by_id UUID REFERENCES users(id),
+  created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_pregnancy_datings_mother ON pregnancy_datings (mother_id, created_at);
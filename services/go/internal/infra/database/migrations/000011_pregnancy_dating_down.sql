@@ -0,0 +1,7 @@
+-- Rollback Migration for Pregnancy Dating
+
+DROP TABLE IF EXISTS pregnancy_datings;
+
+ALTER TABLE mothers
+  DROP COLUMN IF EXISTS dating_method,
+  DROP COLUMN IF EXISTS lmp;
@@ -0,0 +1,8 @@
+-- Fetal Growth Migration for MamaCare
+-- Fundal height and estimated fetal weight are plotted against growth standards
+
+ALTER TABLE health_metrics
+  ADD COLUMN fundal_height_cm FLOAT
+  CHECK (fundal_height_cm BETWEEN 5 AND 60),
+  ADD COLUMN estimated_fetal_weight FLOAT
+  CHECK (estimated_fetal_weight BETWEEN 50 AND 7000);
@@ -0,0 +1,5 @@
+-- Rollback Migration for Fetal Growth
+
+ALTER TABLE health_metrics
+  DROP COLUMN IF EXISTS estimated_fetal_weight,
+  DROP COLUMN IF EXISTS fundal_height_cm;
@@ -0,0 +1,62 @@
+-- Nutrition Migration for MamaCare
+-- MUAC is recorded with other vitals; dietary counselling, supplementation
+-- enrollments and the supplements dispensed from facility stock are tracked
+
+ALTER TABLE health_metrics
+  ADD COLUMN muac_cm FLOAT
+  CHECK (muac_cm BETWEEN 10 AND 60);
+
+CREATE TABLE nutrition_counsellings (
+  id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+  mother_id UUID NOT NULL REFERENCES mothers(id),
+  visit_id UUID REFERENCES visits(id),
+  topics TEXT[] NOT NULL DEFAULT '{}',
+  notes TEXT NOT NULL DEFAULT '',
+  counselled_by_id UUID REFERENCES users(id),
+  counselled_at TIMESTAMP WITH TIME ZONE NOT NULL,
+  created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_nutrition_counsellings_mother ON nutrition_counsellings (mother_id, counselled_at);
+
+CREATE TABLE supplementation_enrollments (
+  id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+  mother_id UUID NOT NULL REFERENCES mothers(id),
+  programme VARCHAR(30) NOT NULL CHECK (programme IN ('balanced_energy_protein')),
+  reasons TEXT[] NOT NULL DEFAULT '{}',
+  status VARCHAR(20) NOT NULL DEFAULT 'active' CHECK (status IN ('active', 'completed', 'withdrawn')),
+  facility_id UUID REFERENCES facilities(id),
+  enrolled_at TIMESTAMP WITH TIME ZONE NOT NULL,
+  ended_at TIMESTAMP WITH TIME ZONE,
+  end_reason TEXT NOT NULL DEFAULT '',
+  created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+  updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+-- A mother is in at most one active programme at a time
+CREATE UNIQUE INDEX idx_supplementation_enrollments_active
+  ON supplementation_enrollments (mother_id) WHERE status = 'active';
+
+CREATE TABLE supplement_stocks (
+  facility_id UUID NOT NULL REFERENCES facilities(id),
+  item VARCHAR(20) NOT NULL CHECK (item IN ('bep_ration', 'mms_tablet', 'ifa_tablet')),
+  quantity_on_hand INTEGER NOT NULL DEFAULT 0 CHECK (quantity_on_hand >= 0),
+  updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+  PRIMARY KEY (facility_id, item)
+);
+
+CREATE TABLE supplement_dispensings (
+  id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+  enrollment_id UUID NOT NULL REFERENCES supplementation_enrollments(id),
+  mother_id UUID NOT NULL REFERENCES mothers(id),
+  visit_id UUID REFERENCES visits(id),
+  facility_id UUID NOT NULL REFERENCES facilities(id),
+  item VARCHAR(20) NOT NULL,
+  quantity INTEGER NOT NULL CHECK (quantity > 0),
+  dispensed_by_id UUID REFERENCES users(id),
+  dispensed_at TIMESTAMP WITH TIME ZONE NOT NULL,
+  created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_supplement_dispensings_enrollment ON supplement_dispensings (enrollment_id, dispensed_at);
+CREATE INDEX idx_supplement_dispensings_facility ON supplement_dispensings (facility_id, dispensed_at);
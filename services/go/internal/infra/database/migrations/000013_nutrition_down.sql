@@ -0,0 +1,9 @@
+-- Rollback Migration for Nutrition
+
+DROP TABLE IF EXISTS supplement_dispensings;
+DROP TABLE IF EXISTS supplement_stocks;
+DROP TABLE IF EXISTS supplementation_enrollments;
+DROP TABLE IF EXISTS nutrition_counsellings;
+
+ALTER TABLE health_metrics
+  DROP COLUMN IF EXISTS muac_cm;
@@ -0,0 +1,79 @@
+-- Children Migration for MamaCare
+-- Child registry; the table matches the Hasura children schema, with the delivery
+-- outcome a newborn was registered from and links for deactivated duplicates.
+-- Parents are user IDs so the row-level security policies can match the session user.
+
+CREATE TABLE IF NOT EXISTS children (
+  id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+  first_name TEXT NOT NULL CHECK (first_name <> ''),
+  middle_name TEXT,
+  last_name TEXT NOT NULL CHECK (last_name <> ''),
+  date_of_birth DATE NOT NULL,
+  birth_weight_grams INTEGER,
+  birth_length_cm INTEGER,
+  place_of_birth TEXT,
+  delivery_type TEXT CHECK (delivery_type IN ('VAGINAL', 'C_SECTION', 'ASSISTED')),
+  gestational_age_weeks INTEGER CHECK (gestational_age_weeks BETWEEN 20 AND 45),
+  mother_id UUID REFERENCES users(id) ON DELETE SET NULL,
+  father_id UUID REFERENCES users(id) ON DELETE SET NULL,
+  father_name TEXT,
+  blood_type TEXT,
+  allergies TEXT,
+  disabilities TEXT,
+  chronic_conditions TEXT,
+  current_growth_status VARCHAR(20) CHECK (current_growth_status IN ('NORMAL', 'UNDERWEIGHT', 'OVERWEIGHT', 'STUNTED', 'WASTED')),
+  created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+  updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+  is_active BOOLEAN NOT NULL DEFAULT true,
+  CONSTRAINT valid_parent_info CHECK (
+    mother_id IS NOT NULL OR father_id IS NOT NULL OR father_name IS NOT NULL
+  )
+);
+
+ALTER TABLE children
+  ADD COLUMN IF NOT EXISTS sex VARCHAR(10) CHECK (sex IN ('male', 'female')),
+  ADD COLUMN IF NOT EXISTS chw_id UUID REFERENCES users(id) ON DELETE SET NULL,
+  ADD COLUMN IF NOT EXISTS delivery_outcome_id UUID REFERENCES delivery_outcomes(id),
+  ADD COLUMN IF NOT EXISTS merged_into_id UUID REFERENCES children(id),
+  ADD COLUMN IF NOT EXISTS deactivation_reason TEXT;
+
+CREATE INDEX IF NOT EXISTS idx_children_mother_id ON children (mother_id);
+CREATE INDEX IF NOT EXISTS idx_children_father_id ON children (father_id);
+CREATE INDEX IF NOT EXISTS idx_children_dob ON children (date_of_birth);
+CREATE INDEX IF NOT EXISTS idx_children_delivery_outcome_id ON children (delivery_outcome_id);
+
+ALTER TABLE children ENABLE ROW LEVEL SECURITY;
+
+DROP POLICY IF EXISTS parents_manage_children ON children;
+CREATE POLICY parents_manage_children ON children
+  USING (
+    mother_id::text = current_setting('hasura.user.id', true) OR
+    father_id::text = current_setting('hasura.user.id', true)
+  )
+  WITH CHECK (
+    mother_id::text = current_setting('hasura.user.id', true) OR
+    father_id::text = current_setting('hasura.user.id', true)
+  );
+
+-- CHWs see children whose mother lives in their assigned area
+ALTER TABLE users ADD COLUMN IF NOT EXISTS assigned_area TEXT;
+
+DROP POLICY IF EXISTS chw_view_area_children ON children;
+CREATE POLICY chw_view_area_children ON children
+  USING (
+    current_setting('hasura.user.role', true) = 'CHW' AND
+    EXISTS (
+      SELECT 1 FROM users u
+      WHERE u.id = children.mother_id
+      AND u.assigned_area = (
+        SELECT assigned_area FROM users
+        WHERE id::text = current_setting('hasura.user.id', true)
+      )
+    )
+  );
+
+DROP POLICY IF EXISTS admin_clinician_view_all_children ON children;
+CREATE POLICY admin_clinician_view_all_children ON children
+  USING (
+    current_setting('hasura.user.role', true) IN ('ADMIN', 'CLINICIAN')
+  );
@@ -0,0 +1,12 @@
+-- Rollback Migration for Children
+-- The children table, its policies and users.assigned_area can predate this migration,
+-- so only the columns and index it added are dropped
+
+DROP INDEX IF EXISTS idx_children_delivery_outcome_id;
+
+ALTER TABLE children
+  DROP COLUMN IF EXISTS deactivation_reason,
+  DROP COLUMN IF EXISTS merged_into_id,
+  DROP COLUMN IF EXISTS delivery_outcome_id,
+  DROP COLUMN IF EXISTS chw_id,
+  DROP COLUMN IF EXISTS sex;
@@ -0,0 +1,61 @@
+-- Immunizations Migration for MamaCare
+-- EPI vaccine schedule and the doses given to children, matching the Hasura schema.
+-- Doses given too early or too soon after the previous dose are kept but marked
+-- invalid, so only valid doses are unique per child, vaccine and dose.
+
+CREATE TABLE IF NOT EXISTS vaccine_schedules (
+  id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+  vaccine_name TEXT NOT NULL CHECK (vaccine_name <> ''),
+  vaccine_dose TEXT NOT NULL,
+  age_months INTEGER NOT NULL,
+  window_start_days INTEGER NOT NULL,
+  window_end_days INTEGER NOT NULL,
+  disease_target TEXT NOT NULL,
+  is_required BOOLEAN NOT NULL DEFAULT TRUE,
+  created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+  updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+  is_active BOOLEAN NOT NULL DEFAULT TRUE,
+  CONSTRAINT valid_window CHECK (window_end_days >= window_start_days),
+  UNIQUE (vaccine_name, vaccine_dose)
+);
+
+CREATE TABLE IF NOT EXISTS immunization_records (
+  id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+  child_id UUID NOT NULL REFERENCES children(id) ON DELETE CASCADE,
+  vaccine_name TEXT NOT NULL CHECK (vaccine_name <> ''),
+  vaccine_dose TEXT NOT NULL,
+  administered_date DATE NOT NULL,
+  administered_by_user_id UUID REFERENCES users(id),
+  administered_by_name TEXT,
+  administered_at_facility_id UUID REFERENCES facilities(id),
+  batch_number TEXT,
+  manufacturer TEXT,
+  adverse_reactions TEXT,
+  notes TEXT,
+  created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+  updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+  is_verified BOOLEAN NOT NULL DEFAULT true,
+  CONSTRAINT valid_administrator_info CHECK (
+    administered_by_user_id IS NOT NULL OR administered_by_name IS NOT NULL
+  )
+);
+
+ALTER TABLE immunization_records
+  ADD COLUMN IF NOT EXISTS is_valid BOOLEAN NOT NULL DEFAULT true,
+  ADD COLUMN IF NOT EXISTS invalid_reason TEXT;
+
+ALTER TABLE immunization_records
+  DROP CONSTRAINT IF EXISTS immunization_records_child_id_vaccine_name_vaccine_dose_key;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_immunization_records_valid_dose
+  ON immunization_records (child_id, vaccine_name, vaccine_dose) WHERE is_valid;
+CREATE INDEX IF NOT EXISTS idx_immunization_records_child_id ON immunization_records (child_id);
+
+CREATE TABLE immunization_reminders (
+  child_id UUID NOT NULL REFERENCES children(id) ON DELETE CASCADE,
+  vaccine_name TEXT NOT NULL,
+  vaccine_dose TEXT NOT NULL,
+  kind VARCHAR(10) NOT NULL CHECK (kind IN ('due', 'overdue')),
+  sent_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+  PRIMARY KEY (child_id, vaccine_name, vaccine_dose, kind)
+);
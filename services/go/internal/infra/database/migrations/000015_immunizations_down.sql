@@ -0,0 +1,17 @@
+-- Rollback Migration for Immunizations
+-- vaccine_schedules and immunization_records can predate this migration, so only the
+-- reminders table, the validity columns and the valid dose index it added are dropped.
+-- Restoring the unique dose constraint fails while a child has an invalid repeat dose,
+-- rather than deleting the record.
+
+DROP TABLE IF EXISTS immunization_reminders;
+
+DROP INDEX IF EXISTS idx_immunization_records_valid_dose;
+
+ALTER TABLE immunization_records
+  ADD CONSTRAINT immunization_records_child_id_vaccine_name_vaccine_dose_key
+  UNIQUE (child_id, vaccine_name, vaccine_dose);
+
+ALTER TABLE immunization_records
+  DROP COLUMN IF EXISTS invalid_reason,
+  DROP COLUMN IF EXISTS is_valid;
@@ -0,0 +1,63 @@
+-- Vaccine Schedule Ages Migration for MamaCare
+-- age_months is an INTEGER, so the 6, 10 and 14 week EPI doses were stored rounded to
+-- whole months. Schedule ages move to days, with the minimum interval after the previous
+-- dose of the series and the age after which the dose is no longer given.
+
+ALTER TABLE vaccine_schedules
+  ADD COLUMN IF NOT EXISTS age_days INTEGER,
+  ADD COLUMN IF NOT EXISTS min_interval_days INTEGER NOT NULL DEFAULT 0,
+  ADD COLUMN IF NOT EXISTS max_age_days INTEGER;
+
+-- The EPI schedule ages in weeks, which the rounded months can no longer tell apart
+UPDATE vaccine_schedules SET age_days = CASE
+  WHEN age_months = 0 THEN 0
+  WHEN vaccine_name IN ('OPV', 'Penta', 'PCV', 'Rotavirus') AND vaccine_dose = '1' THEN 42
+  WHEN vaccine_name IN ('OPV', 'Penta', 'PCV', 'Rotavirus') AND vaccine_dose = '2' THEN 70
+  WHEN vaccine_name IN ('OPV', 'Penta', 'PCV') AND vaccine_dose = '3' THEN 98
+  WHEN vaccine_name = 'IPV' AND vaccine_dose = '1' THEN 98
+  ELSE ROUND(age_months * 30.4375)
+END
+WHERE age_days IS NULL AND age_months IS NOT NULL;
+
+-- Later doses of a series must follow the previous dose by at least four weeks; OPV 1
+-- follows the birth dose, while the first dose of Penta, PCV and rotavirus starts its series
+UPDATE vaccine_schedules SET min_interval_days = 28
+WHERE (vaccine_name = 'OPV' AND vaccine_dose IN ('1', '2', '3', 'Booster'))
+  OR (vaccine_name IN ('Penta', 'PCV', 'Rotavirus') AND vaccine_dose IN ('2', '3'))
+  OR (vaccine_name = 'Measles/Rubella' AND vaccine_dose = '2');
+
+UPDATE vaccine_schedules SET min_interval_days = 180
+WHERE (vaccine_name = 'DTP' AND vaccine_dose = 'Booster')
+  OR (vaccine_name = 'Vitamin A' AND vaccine_dose = '2');
+
+-- The birth dose of OPV only counts in the first two weeks; rotavirus is not started
+-- after 15 weeks nor completed after 32; BCG is not given routinely after the first year
+UPDATE vaccine_schedules SET max_age_days = CASE
+  WHEN vaccine_name = 'OPV' AND vaccine_dose = '0' THEN 14
+  WHEN vaccine_name = 'Rotavirus' AND vaccine_dose = '1' THEN 105
+  WHEN vaccine_name = 'Rotavirus' AND vaccine_dose = '2' THEN 224
+  WHEN vaccine_name = 'BCG' THEN 365
+END
+WHERE max_age_days IS NULL;
+
+ALTER TABLE vaccine_schedules
+  ALTER COLUMN age_months TYPE NUMERIC(4,1),
+  ALTER COLUMN age_days SET NOT NULL,
+  ALTER COLUMN age_months DROP NOT NULL,
+  DROP CONSTRAINT IF EXISTS valid_age_days,
+  ADD CONSTRAINT valid_age_days CHECK (age_days >= 0),
+  DROP CONSTRAINT IF EXISTS valid_min_interval,
+  ADD CONSTRAINT valid_min_interval CHECK (min_interval_days >= 0),
+  DROP CONSTRAINT IF EXISTS valid_max_age,
+  ADD CONSTRAINT valid_max_age CHECK (max_age_days IS NULL OR max_age_days >= age_days);
+
+-- age_months is kept for display only, with the weekly doses shown as fractions of a month
+UPDATE vaccine_schedules SET age_months = age_days / 28.0
+WHERE age_days IN (42, 70, 98);
+
+CREATE INDEX IF NOT EXISTS idx_vaccine_schedules_age_days ON vaccine_schedules (age_days);
+
+COMMENT ON COLUMN vaccine_schedules.age_months IS 'Deprecated, approximate age in months for display; use age_days';
+COMMENT ON COLUMN vaccine_schedules.age_days IS 'Recommended age in days for this vaccine';
+COMMENT ON COLUMN vaccine_schedules.min_interval_days IS 'Minimum days after the previous dose of the series for this dose to count';
+COMMENT ON COLUMN vaccine_schedules.max_age_days IS 'Age in days after which this dose is no longer given';
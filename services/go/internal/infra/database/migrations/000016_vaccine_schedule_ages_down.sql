@@ -0,0 +1,19 @@
+-- Rollback Migration for Vaccine Schedule Ages
+
+DROP INDEX IF EXISTS idx_vaccine_schedules_age_days;
+
+-- Schedules added since store only days, so give them the nearest whole month
+UPDATE vaccine_schedules SET age_months = ROUND(age_days / 30.4375)
+WHERE age_months IS NULL;
+
+ALTER TABLE vaccine_schedules
+  DROP CONSTRAINT IF EXISTS valid_max_age,
+  DROP CONSTRAINT IF EXISTS valid_min_interval,
+  DROP CONSTRAINT IF EXISTS valid_age_days,
+  DROP COLUMN IF EXISTS max_age_days,
+  DROP COLUMN IF EXISTS min_interval_days,
+  DROP COLUMN IF EXISTS age_days,
+  ALTER COLUMN age_months TYPE INTEGER USING ROUND(age_months),
+  ALTER COLUMN age_months SET NOT NULL;
+
+COMMENT ON COLUMN vaccine_schedules.age_months IS 'Recommended age in months for this vaccine';
@@ -0,0 +1,59 @@
+-- Growth Measurements Migration for MamaCare
+-- Child anthropometry with the WHO z-scores the service computes, matching the Hasura
+-- schema. Head circumference and arm circumference z-scores, how length was measured
+-- and a flag for z-scores beyond the WHO plausibility limits are added.
+
+CREATE TABLE IF NOT EXISTS growth_measurements (
+  id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+  child_id UUID NOT NULL REFERENCES children(id) ON DELETE CASCADE,
+  measured_at DATE NOT NULL DEFAULT CURRENT_DATE,
+  weight_grams INTEGER CHECK (weight_grams BETWEEN 500 AND 30000),
+  height_cm DECIMAL(5,2) CHECK (height_cm BETWEEN 20 AND 150),
+  head_circumference_cm DECIMAL(4,1) CHECK (head_circumference_cm BETWEEN 10 AND 60),
+  mid_upper_arm_circumference_cm DECIMAL(4,1),
+  weight_for_age_z DECIMAL(3,2),
+  height_for_age_z DECIMAL(3,2),
+  weight_for_height_z DECIMAL(3,2),
+  growth_status VARCHAR(20) NOT NULL CHECK (growth_status IN ('NORMAL', 'UNDERWEIGHT', 'OVERWEIGHT', 'STUNTED', 'WASTED')),
+  measured_by_user_id UUID REFERENCES users(id),
+  measured_by_name TEXT,
+  measured_at_facility_id UUID REFERENCES facilities(id),
+  notes TEXT,
+  created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+  updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+  CONSTRAINT measurement_has_weight_or_height CHECK (
+    weight_grams IS NOT NULL OR
+    height_cm IS NOT NULL OR
+    head_circumference_cm IS NOT NULL
+  ),
+  CONSTRAINT valid_measurer_info CHECK (
+    measured_by_user_id IS NOT NULL OR measured_by_name IS NOT NULL
+  )
+);
+
+ALTER TABLE growth_measurements
+  ADD COLUMN IF NOT EXISTS measured_recumbent BOOLEAN,
+  ADD COLUMN IF NOT EXISTS head_circumference_for_age_z DECIMAL(4,2),
+  ADD COLUMN IF NOT EXISTS muac_for_age_z DECIMAL(4,2),
+  ADD COLUMN IF NOT EXISTS implausible_z BOOLEAN NOT NULL DEFAULT false;
+
+-- Implausible measurements can score beyond ±10, and are kept so they can be checked
+ALTER TABLE growth_measurements
+  ALTER COLUMN weight_for_age_z TYPE DECIMAL(4,2),
+  ALTER COLUMN height_for_age_z TYPE DECIMAL(4,2),
+  ALTER COLUMN weight_for_height_z TYPE DECIMAL(4,2);
+
+-- An arm circumference alone is enough to screen for wasting
+ALTER TABLE growth_measurements
+  DROP CONSTRAINT IF EXISTS measurement_has_weight_or_height,
+  ADD CONSTRAINT measurement_has_weight_or_height CHECK (
+    weight_grams IS NOT NULL OR
+    height_cm IS NOT NULL OR
+    head_circumference_cm IS NOT NULL OR
+    mid_upper_arm_circumference_cm IS NOT NULL
+  );
+
+CREATE INDEX IF NOT EXISTS idx_growth_measurements_child_id ON growth_measurements (child_id, measured_at);
+
+COMMENT ON COLUMN growth_measurements.measured_recumbent IS 'Whether length was measured lying down; WHO uses length under 24 months and height after';
+COMMENT ON COLUMN growth_measurements.implausible_z IS 'A z-score is beyond the WHO plausibility limits and the measurement should be checked';
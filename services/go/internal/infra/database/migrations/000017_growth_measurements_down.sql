@@ -0,0 +1,11 @@
+-- Rollback Migration for Growth Measurements
+-- growth_measurements can predate this migration, so only the columns it added are
+-- dropped. The z-score columns keep their wider type, as stored implausible z-scores
+-- would not fit the old one, and the measurement check keeps allowing arm circumference
+-- alone, as the Hasura schema does.
+
+ALTER TABLE growth_measurements
+  DROP COLUMN IF EXISTS implausible_z,
+  DROP COLUMN IF EXISTS muac_for_age_z,
+  DROP COLUMN IF EXISTS head_circumference_for_age_z,
+  DROP COLUMN IF EXISTS measured_recumbent;
@@ -0,0 +1,72 @@
+-- CMAM Migration for MamaCare
+-- Community management of acute malnutrition: children referred from growth monitoring,
+-- their admission to outpatient or inpatient treatment, weekly follow-ups with the RUTF
+-- dispensed from facility stock, and how treatment ended
+
+CREATE TABLE cmam_enrollments (
+  id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+  child_id UUID NOT NULL REFERENCES children(id) ON DELETE CASCADE,
+  facility_id UUID REFERENCES facilities(id),
+  programme VARCHAR(3) CHECK (programme IN ('otp', 'sc')),
+  status VARCHAR(20) NOT NULL DEFAULT 'referred' CHECK (status IN (
+    'referred', 'not_admitted', 'active', 'cured', 'defaulted', 'died', 'non_responder'
+  )),
+  growth_measurement_id UUID REFERENCES growth_measurements(id) ON DELETE SET NULL,
+  referred_at DATE NOT NULL,
+  admitted_at DATE,
+  admission_criteria TEXT[] NOT NULL DEFAULT '{}',
+  admission_weight_grams INTEGER CHECK (admission_weight_grams BETWEEN 500 AND 30000),
+  admission_height_cm DECIMAL(5,2),
+  admission_muac_cm DECIMAL(4,1),
+  admission_whz DECIMAL(4,2),
+  admission_oedema_grade SMALLINT NOT NULL DEFAULT 0 CHECK (admission_oedema_grade BETWEEN 0 AND 3),
+  complications BOOLEAN NOT NULL DEFAULT false,
+  target_weight_grams INTEGER,
+  admitted_by_id UUID REFERENCES users(id),
+  exited_at DATE,
+  exit_weight_grams INTEGER,
+  exit_notes TEXT NOT NULL DEFAULT '',
+  created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+  updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+  CONSTRAINT cmam_admitted_has_programme CHECK (
+    status IN ('referred', 'not_admitted') OR
+    (programme IS NOT NULL AND facility_id IS NOT NULL AND admitted_at IS NOT NULL)
+  )
+);
+
+-- A child has at most one open referral or treatment at a time
+CREATE UNIQUE INDEX idx_cmam_enrollments_open
+  ON cmam_enrollments (child_id) WHERE status IN ('referred', 'active');
+CREATE INDEX idx_cmam_enrollments_facility ON cmam_enrollments (facility_id, admitted_at);
+
+CREATE TABLE cmam_follow_ups (
+  id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+  enrollment_id UUID NOT NULL REFERENCES cmam_enrollments(id) ON DELETE CASCADE,
+  week INTEGER NOT NULL CHECK (week >= 0),
+  scheduled_date DATE NOT NULL,
+  status VARCHAR(10) NOT NULL DEFAULT 'scheduled' CHECK (status IN ('scheduled', 'attended', 'missed')),
+  visited_at DATE,
+  weight_grams INTEGER CHECK (weight_grams BETWEEN 500 AND 30000),
+  height_cm DECIMAL(5,2),
+  muac_cm DECIMAL(4,1),
+  whz DECIMAL(4,2),
+  oedema_grade SMALLINT CHECK (oedema_grade BETWEEN 0 AND 3),
+  rutf_sachets INTEGER NOT NULL DEFAULT 0 CHECK (rutf_sachets >= 0),
+  recorded_by_id UUID REFERENCES users(id),
+  notes TEXT NOT NULL DEFAULT '',
+  created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+  updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+  UNIQUE (enrollment_id, week)
+);
+
+CREATE INDEX idx_cmam_follow_ups_due ON cmam_follow_ups (scheduled_date) WHERE status = 'scheduled';
+
+-- RUTF is stocked and dispensed like the maternal supplements
+ALTER TABLE supplement_stocks
+  DROP CONSTRAINT IF EXISTS supplement_stocks_item_check,
+  ADD CONSTRAINT supplement_stocks_item_check CHECK (item IN ('bep_ration', 'mms_tablet', 'ifa_tablet', 'rutf_sachet'));
+
+COMMENT ON COLUMN cmam_enrollments.admission_oedema_grade IS 'Bilateral pitting oedema from 0 (none) to 3 (+++)';
+COMMENT ON COLUMN cmam_enrollments.target_weight_grams IS 'Admission weight plus 15 percent';
+COMMENT ON COLUMN cmam_follow_ups.week IS 'Weeks since admission; week 0 is the admission visit';
+COMMENT ON COLUMN cmam_follow_ups.rutf_sachets IS '92 g RUTF sachets dispensed for the coming week';
@@ -0,0 +1,10 @@
+-- Rollback Migration for CMAM
+
+DELETE FROM supplement_stocks WHERE item = 'rutf_sachet';
+
+ALTER TABLE supplement_stocks
+  DROP CONSTRAINT IF EXISTS supplement_stocks_item_check,
+  ADD CONSTRAINT supplement_stocks_item_check CHECK (item IN ('bep_ration', 'mms_tablet', 'ifa_tablet'));
+
+DROP TABLE IF EXISTS cmam_follow_ups;
+DROP TABLE IF EXISTS cmam_enrollments;
@@ -0,0 +1,72 @@
+-- Neonatal Migration for MamaCare
+-- Essential newborn care recorded at birth, daily kangaroo mother care for low birth
+-- weight babies, and newborn checks with the danger signs they found
+
+ALTER TYPE sos_event_nature ADD VALUE IF NOT EXISTS 'NEWBORN';
+
+CREATE TABLE neonatal_records (
+  id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+  child_id UUID NOT NULL UNIQUE REFERENCES children(id) ON DELETE CASCADE,
+  apgar_1_min SMALLINT CHECK (apgar_1_min BETWEEN 0 AND 10),
+  apgar_5_min SMALLINT CHECK (apgar_5_min BETWEEN 0 AND 10),
+  apgar_10_min SMALLINT CHECK (apgar_10_min BETWEEN 0 AND 10),
+  birth_weight_grams INTEGER CHECK (birth_weight_grams BETWEEN 300 AND 7000),
+  birth_weight_category VARCHAR(15) CHECK (birth_weight_category IN ('normal', 'low', 'very_low', 'extremely_low')),
+  breastfeeding_initiated_at TIMESTAMP WITH TIME ZONE,
+  early_breastfeeding BOOLEAN NOT NULL DEFAULT false,
+  cord_care VARCHAR(15) CHECK (cord_care IN ('chlorhexidine', 'dry')),
+  kmc_started_at TIMESTAMP WITH TIME ZONE,
+  kmc_ended_at TIMESTAMP WITH TIME ZONE,
+  kmc_end_reason TEXT NOT NULL DEFAULT '',
+  recorded_by_id UUID NOT NULL REFERENCES users(id),
+  notes TEXT NOT NULL DEFAULT '',
+  created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+  updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+  CONSTRAINT neonatal_kmc_ends_after_start CHECK (
+    kmc_ended_at IS NULL OR (kmc_started_at IS NOT NULL AND kmc_ended_at >= kmc_started_at)
+  )
+);
+
+CREATE TABLE kmc_sessions (
+  id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+  child_id UUID NOT NULL REFERENCES children(id) ON DELETE CASCADE,
+  date DATE NOT NULL,
+  skin_to_skin_hours DECIMAL(4,1) NOT NULL CHECK (skin_to_skin_hours BETWEEN 0 AND 24),
+  temperature_c DECIMAL(3,1),
+  weight_grams INTEGER CHECK (weight_grams BETWEEN 300 AND 7000),
+  exclusive_breastfeeding BOOLEAN NOT NULL DEFAULT false,
+  recorded_by_id UUID NOT NULL REFERENCES users(id),
+  notes TEXT NOT NULL DEFAULT '',
+  created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+  UNIQUE (child_id, date)
+);
+
+CREATE TABLE neonatal_checks (
+  id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+  child_id UUID NOT NULL REFERENCES children(id) ON DELETE CASCADE,
+  facility_id UUID NOT NULL REFERENCES facilities(id),
+  visit_id UUID REFERENCES visits(id) ON DELETE SET NULL,
+  checked_at TIMESTAMP WITH TIME ZONE NOT NULL,
+  checked_by_id UUID NOT NULL REFERENCES users(id),
+  temperature_c DECIMAL(3,1),
+  respiratory_rate SMALLINT CHECK (respiratory_rate BETWEEN 0 AND 150),
+  weight_grams INTEGER CHECK (weight_grams BETWEEN 300 AND 7000),
+  feeding VARCHAR(15) NOT NULL CHECK (feeding IN ('good', 'poor', 'not_feeding')),
+  jaundice VARCHAR(15) NOT NULL CHECK (jaundice IN ('none', 'face', 'trunk', 'palms_soles')),
+  convulsions BOOLEAN NOT NULL DEFAULT false,
+  lethargic BOOLEAN NOT NULL DEFAULT false,
+  severe_chest_indrawing BOOLEAN NOT NULL DEFAULT false,
+  umbilical_infection BOOLEAN NOT NULL DEFAULT false,
+  danger_signs TEXT[] NOT NULL DEFAULT '{}',
+  severity VARCHAR(10) NOT NULL CHECK (severity IN ('none', 'moderate', 'severe')),
+  sos_event_id UUID REFERENCES sos_events(id) ON DELETE SET NULL,
+  follow_up_visit_id UUID REFERENCES visits(id) ON DELETE SET NULL,
+  notes TEXT NOT NULL DEFAULT '',
+  created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_neonatal_checks_child ON neonatal_checks (child_id, checked_at DESC);
+CREATE INDEX idx_neonatal_checks_severe ON neonatal_checks (facility_id, checked_at) WHERE severity = 'severe';
+
+COMMENT ON COLUMN neonatal_records.early_breastfeeding IS 'Breastfeeding started within an hour of birth';
+COMMENT ON COLUMN neonatal_checks.danger_signs IS 'IMNCI young infant danger signs found at the check';
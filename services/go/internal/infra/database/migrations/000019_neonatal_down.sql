@@ -0,0 +1,6 @@
+-- Rollback Migration for Neonatal
+-- Note: PostgreSQL cannot remove the NEWBORN value from sos_event_nature
+
+DROP TABLE IF EXISTS neonatal_checks;
+DROP TABLE IF EXISTS kmc_sessions;
+DROP TABLE IF EXISTS neonatal_records;
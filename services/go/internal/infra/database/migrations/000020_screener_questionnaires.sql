@@ -0,0 +1,142 @@
+-- Screener Questionnaires Migration for MamaCare
+-- Screener questions were replaced by truncating and re-seeding them, which destroyed the
+-- questions earlier answers were given to. Questions now belong to a numbered questionnaire
+-- version per subject. Only drafts can be edited; publishing a version retires the one
+-- before it, and every screening records the version it was done with.
+
+CREATE TABLE IF NOT EXISTS screener_questionnaires (
+  id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+  subject TEXT NOT NULL CHECK (subject IN ('mother', 'child')),
+  version INTEGER NOT NULL CHECK (version > 0),
+  title TEXT NOT NULL,
+  status TEXT NOT NULL DEFAULT 'DRAFT' CHECK (status IN ('DRAFT', 'PUBLISHED', 'RETIRED')),
+  based_on_id UUID REFERENCES screener_questionnaires(id),
+  notes TEXT,
+  created_by_id UUID REFERENCES users(id),
+  published_by_id UUID REFERENCES users(id),
+  published_at TIMESTAMP WITH TIME ZONE,
+  created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  UNIQUE (subject, version),
+  CONSTRAINT published_questionnaire_has_date CHECK (status = 'DRAFT' OR published_at IS NOT NULL),
+  -- Deferred so that publishing can retire the previous version in the same statement
+  CONSTRAINT one_published_questionnaire EXCLUDE (subject WITH =) WHERE (status = 'PUBLISHED')
+    DEFERRABLE INITIALLY DEFERRED
+);
+
+-- Existing questions become version 1 of each subject's questionnaire
+INSERT INTO screener_questionnaires (subject, version, title, status, notes, published_at)
+SELECT DISTINCT
+  CASE WHEN sq.category IN ('Child', 'Newborn') THEN 'child' ELSE 'mother' END,
+  1,
+  CASE WHEN sq.category IN ('Child', 'Newborn') THEN 'Child danger sign screening' ELSE 'Maternal danger sign screening' END,
+  'PUBLISHED',
+  'Questions in use before questionnaires were versioned',
+  CURRENT_TIMESTAMP
+FROM screener_questions sq
+ON CONFLICT (subject, version) DO NOTHING;
+
+ALTER TABLE screener_questions
+  ADD COLUMN IF NOT EXISTS questionnaire_id UUID REFERENCES screener_questionnaires(id) ON DELETE CASCADE;
+
+UPDATE screener_questions sq SET questionnaire_id = q.id
+FROM screener_questionnaires q
+WHERE sq.questionnaire_id IS NULL
+  AND q.version = 1
+  AND q.subject = CASE WHEN sq.category IN ('Child', 'Newborn') THEN 'child' ELSE 'mother' END;
+
+-- Question codes are unique within a version rather than across all of them
+ALTER TABLE screener_questions
+  ALTER COLUMN questionnaire_id SET NOT NULL,
+  DROP CONSTRAINT IF EXISTS screener_questions_question_code_key,
+  DROP CONSTRAINT IF EXISTS unique_questionnaire_question_code,
+  ADD CONSTRAINT unique_questionnaire_question_code UNIQUE (questionnaire_id, question_code);
+
+CREATE INDEX IF NOT EXISTS idx_screener_questions_questionnaire_id ON screener_questions (questionnaire_id, display_order);
+
+ALTER TABLE screener_results
+  ADD COLUMN IF NOT EXISTS questionnaire_id UUID REFERENCES screener_questionnaires(id);
+
+UPDATE screener_results sr SET questionnaire_id = (
+  SELECT sq.questionnaire_id
+  FROM screener_answers sa
+  JOIN screener_questions sq ON sq.id = sa.question_id
+  WHERE sa.screener_result_id = sr.id
+  LIMIT 1
+)
+WHERE sr.questionnaire_id IS NULL;
+
+CREATE INDEX IF NOT EXISTS idx_screener_results_questionnaire_id ON screener_results (questionnaire_id);
+
+-- Answers keep the questions they were given to
+ALTER TABLE screener_answers
+  DROP CONSTRAINT IF EXISTS screener_answers_question_id_fkey,
+  ADD CONSTRAINT screener_answers_question_id_fkey
+    FOREIGN KEY (question_id) REFERENCES screener_questions(id) ON DELETE RESTRICT;
+
+-- Published and retired versions cannot be edited or deleted; only their status moves on
+CREATE OR REPLACE FUNCTION protect_published_screener_questionnaire()
+RETURNS TRIGGER AS $$
+BEGIN
+  IF TG_OP = 'DELETE' THEN
+    IF OLD.status <> 'DRAFT' THEN
+      RAISE EXCEPTION 'screener questionnaire % version % is published and cannot be deleted', OLD.subject, OLD.version;
+    END IF;
+    RETURN OLD;
+  END IF;
+
+  IF OLD.status <> 'DRAFT' AND (
+    NEW.status = 'DRAFT' OR
+    (OLD.status = 'RETIRED' AND NEW.status <> 'RETIRED') OR
+    ROW(NEW.subject, NEW.version, NEW.title, NEW.notes, NEW.based_on_id, NEW.published_at)
+      IS DISTINCT FROM ROW(OLD.subject, OLD.version, OLD.title, OLD.notes, OLD.based_on_id, OLD.published_at)
+  ) THEN
+    RAISE EXCEPTION 'screener questionnaire % version % is published and cannot be changed', OLD.subject, OLD.version;
+  END IF;
+  RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS protect_published_screener_questionnaire ON screener_questionnaires;
+CREATE TRIGGER protect_published_screener_questionnaire
+BEFORE UPDATE OR DELETE ON screener_questionnaires
+FOR EACH ROW
+EXECUTE FUNCTION protect_published_screener_questionnaire();
+
+CREATE OR REPLACE FUNCTION protect_published_screener_question()
+RETURNS TRIGGER AS $$
+DECLARE
+  questionnaire_ids UUID[];
+BEGIN
+  IF TG_OP = 'INSERT' THEN
+    questionnaire_ids := ARRAY[NEW.questionnaire_id];
+  ELSIF TG_OP = 'UPDATE' THEN
+    questionnaire_ids := ARRAY[OLD.questionnaire_id, NEW.questionnaire_id];
+  ELSE
+    questionnaire_ids := ARRAY[OLD.questionnaire_id];
+  END IF;
+
+  IF EXISTS (
+    SELECT 1 FROM screener_questionnaires q
+    WHERE q.id = ANY(questionnaire_ids) AND q.status <> 'DRAFT'
+  ) THEN
+    RAISE EXCEPTION 'questions of a published screener questionnaire cannot be changed';
+  END IF;
+
+  IF TG_OP = 'DELETE' THEN
+    RETURN OLD;
+  END IF;
+  RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS protect_published_screener_question ON screener_questions;
+CREATE TRIGGER protect_published_screener_question
+BEFORE INSERT OR UPDATE OR DELETE ON screener_questions
+FOR EACH ROW
+EXECUTE FUNCTION protect_published_screener_question();
+
+COMMENT ON TABLE screener_questionnaires IS 'Versions of the screening questions asked of mothers and children';
+COMMENT ON COLUMN screener_questionnaires.status IS 'DRAFT while being authored, PUBLISHED while in use, RETIRED once replaced';
+COMMENT ON COLUMN screener_questions.questionnaire_id IS 'Questionnaire version the question belongs to';
+COMMENT ON COLUMN screener_results.questionnaire_id IS 'Questionnaire version the screening was done with';
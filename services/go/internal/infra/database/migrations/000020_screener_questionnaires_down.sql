@@ -0,0 +1,30 @@
+-- Rollback Migration for Screener Questionnaires
+
+DROP TRIGGER IF EXISTS protect_published_screener_question ON screener_questions;
+DROP FUNCTION IF EXISTS protect_published_screener_question();
+DROP TRIGGER IF EXISTS protect_published_screener_questionnaire ON screener_questionnaires;
+DROP FUNCTION IF EXISTS protect_published_screener_questionnaire();
+
+ALTER TABLE screener_answers
+  DROP CONSTRAINT IF EXISTS screener_answers_question_id_fkey,
+  ADD CONSTRAINT screener_answers_question_id_fkey
+    FOREIGN KEY (question_id) REFERENCES screener_questions(id);
+
+DROP INDEX IF EXISTS idx_screener_results_questionnaire_id;
+ALTER TABLE screener_results DROP COLUMN IF EXISTS questionnaire_id;
+
+-- Question codes must be unique again, so only the published versions' questions are
+-- kept. This fails if answers were given to questions of a retired version.
+DELETE FROM screener_questions sq
+USING screener_questionnaires q
+WHERE sq.questionnaire_id = q.id
+  AND q.status <> 'PUBLISHED'
+  AND NOT EXISTS (SELECT 1 FROM screener_answers sa WHERE sa.question_id = sq.id);
+
+DROP INDEX IF EXISTS idx_screener_questions_questionnaire_id;
+ALTER TABLE screener_questions
+  DROP CONSTRAINT IF EXISTS unique_questionnaire_question_code,
+  DROP COLUMN IF EXISTS questionnaire_id,
+  ADD CONSTRAINT screener_questions_question_code_key UNIQUE (question_code);
+
+DROP TABLE IF EXISTS screener_questionnaires;
@@ -0,0 +1,37 @@
+-- Family Planning Migration for MamaCare
+-- Postpartum family planning and birth spacing: counselling, the method each mother chose,
+-- methods given and re-supplied with the date they are next due, and discontinuations
+
+CREATE TABLE family_planning_visits (
+  id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+  mother_id UUID NOT NULL REFERENCES mothers(id) ON DELETE CASCADE,
+  facility_id UUID NOT NULL REFERENCES facilities(id),
+  visit_date DATE NOT NULL,
+  postpartum_days INTEGER CHECK (postpartum_days >= 0),
+  topics TEXT[] NOT NULL DEFAULT '{}',
+  method VARCHAR(12) CHECK (method IN ('implant', 'injectable', 'iud', 'pills', 'lam')),
+  method_given BOOLEAN NOT NULL DEFAULT false,
+  resupply BOOLEAN NOT NULL DEFAULT false,
+  quantity INTEGER NOT NULL DEFAULT 0 CHECK (quantity >= 0),
+  next_due_date DATE,
+  discontinued BOOLEAN NOT NULL DEFAULT false,
+  discontinuation_reason TEXT NOT NULL DEFAULT '',
+  due_reminder_sent_at TIMESTAMP WITH TIME ZONE,
+  overdue_reminder_sent_at TIMESTAMP WITH TIME ZONE,
+  recorded_by_id UUID NOT NULL REFERENCES users(id),
+  notes TEXT NOT NULL DEFAULT '',
+  created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+  updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+  CONSTRAINT family_planning_given_has_method CHECK (NOT method_given OR method IS NOT NULL),
+  CONSTRAINT family_planning_given_or_discontinued CHECK (NOT (method_given AND discontinued))
+);
+
+CREATE INDEX idx_family_planning_visits_mother ON family_planning_visits (mother_id, visit_date DESC);
+CREATE INDEX idx_family_planning_visits_facility ON family_planning_visits (facility_id, visit_date);
+CREATE INDEX idx_family_planning_visits_due ON family_planning_visits (next_due_date)
+  WHERE next_due_date IS NOT NULL;
+
+COMMENT ON COLUMN family_planning_visits.method IS 'Method chosen, whether or not it was given at this visit';
+COMMENT ON COLUMN family_planning_visits.resupply IS 'Whether the method given continues the one she was already using';
+COMMENT ON COLUMN family_planning_visits.quantity IS 'Injections or pill cycles given';
+COMMENT ON COLUMN family_planning_visits.next_due_date IS 'When the method given is next due to be re-supplied or replaced';
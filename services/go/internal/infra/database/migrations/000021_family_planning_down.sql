@@ -0,0 +1,3 @@
+-- Rollback Migration for Family Planning
+
+DROP TABLE IF EXISTS family_planning_visits;
@@ -0,0 +1,10 @@
+// Package migrations embeds the SQL migrations so they ship with the service binaries.
+// Each version is a NNNNNN_name.sql file, with its rollback in NNNNNN_name_down.sql.
+package migrations
+
+import "embed"
+
+// Files holds every migration and rollback file
+//
+//go:embed *.sql
+var Files embed.FS
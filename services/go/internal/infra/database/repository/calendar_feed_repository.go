@@ -0,0 +1,172 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/mamacare/services/internal/domain/model"
+	"github.com/mamacare/services/internal/domain/repository"
+	"github.com/mamacare/services/internal/infra/database"
+	"github.com/mamacare/services/pkg/errorx"
+	"github.com/mamacare/services/pkg/logger"
+)
+
+// calendarFeedColumns is the column list shared by calendar feed queries
+const calendarFeedColumns = `
+	f.id,
+	f.scope,
+	f.owner_id,
+	f.name,
+	f.created_by_id,
+	f.revoked_at,
+	f.last_accessed_at,
+	f.created_at,
+	f.updated_at
+`
+
+// CalendarFeedRepository implements repository.CalendarFeedRepository interface
+type CalendarFeedRepository struct {
+	pool   *pgxpool.Pool
+	logger logger.Logger
+}
+
+// NewCalendarFeedRepository creates a new calendar feed repository
+func NewCalendarFeedRepository(pool *pgxpool.Pool, logger logger.Logger) repository.CalendarFeedRepository {
+	return &CalendarFeedRepository{
+		pool:   pool,
+		logger: logger,
+	}
+}
+
+// scanCalendarFeed scans a calendar feed from a row
+func scanCalendarFeed(row pgx.Row) (*model.CalendarFeed, error) {
+	var feed model.CalendarFeed
+
+	err := row.Scan(
+		&feed.ID,
+		&feed.Scope,
+		&feed.OwnerID,
+		&feed.Name,
+		&feed.CreatedByID,
+		&feed.RevokedAt,
+		&feed.LastAccessedAt,
+		&feed.CreatedAt,
+		&feed.UpdatedAt,
+	)
+
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, errorx.New(errorx.NotFound, "calendar feed not found")
+		}
+		return nil, errorx.Wrap(err, errorx.InternalServerError, "failed to scan calendar feed")
+	}
+
+	return &feed, nil
+}
+
+// scanCalendarFeeds scans multiple calendar feeds from rows
+func scanCalendarFeeds(rows pgx.Rows) ([]*model.CalendarFeed, error) {
+	var feeds []*model.CalendarFeed
+
+	for rows.Next() {
+		feed, err := scanCalendarFeed(rows)
+		if err != nil {
+			return nil, err
+		}
+		feeds = append(feeds, feed)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, errorx.Wrap(err, errorx.InternalServerError, "error iterating over calendar feed rows")
+	}
+
+	return feeds, nil
+}
+
+// Create creates a new calendar feed
+func (r *CalendarFeedRepository) Create(ctx context.Context, feed *model.CalendarFeed) error {
+	query := `
+		INSERT INTO calendar_feeds (
+			id, scope, owner_id, name, created_by_id, revoked_at, last_accessed_at, created_at, updated_at
+		) VALUES (
+			$1, $2, $3, $4, $5, $6, $7, $8, $9
+		)
+	`
+
+	_, err := database.GetQuerier(ctx, r.pool).Exec(ctx, query,
+		feed.ID,
+		feed.Scope,
+		feed.OwnerID,
+		feed.Name,
+		feed.CreatedByID,
+		feed.RevokedAt,
+		feed.LastAccessedAt,
+		feed.CreatedAt,
+		feed.UpdatedAt,
+	)
+
+	if err != nil {
+		return errorx.Wrap(err, errorx.InternalServerError, "failed to create calendar feed")
+	}
+
+	return nil
+}
+
+// GetByID retrieves a calendar feed by its ID
+func (r *CalendarFeedRepository) GetByID(ctx context.Context, id uuid.UUID) (*model.CalendarFeed, error) {
+	query := `SELECT ` + calendarFeedColumns + ` FROM calendar_feeds f WHERE f.id = $1`
+
+	row := database.GetQuerier(ctx, r.pool).QueryRow(ctx, query, id)
+	return scanCalendarFeed(row)
+}
+
+// GetByOwner retrieves the calendar feeds for a user or facility
+func (r *CalendarFeedRepository) GetByOwner(ctx context.Context, scope model.CalendarFeedScope, ownerID uuid.UUID) ([]*model.CalendarFeed, error) {
+	query := `SELECT ` + calendarFeedColumns + `
+		FROM calendar_feeds f
+		WHERE f.scope = $1 AND f.owner_id = $2
+		ORDER BY f.created_at DESC
+	`
+
+	rows, err := database.GetQuerier(ctx, r.pool).Query(ctx, query, scope, ownerID)
+	if err != nil {
+		return nil, errorx.Wrap(err, errorx.InternalServerError, "failed to query calendar feeds by owner")
+	}
+	defer rows.Close()
+
+	return scanCalendarFeeds(rows)
+}
+
+// Update updates an existing calendar feed
+func (r *CalendarFeedRepository) Update(ctx context.Context, feed *model.CalendarFeed) error {
+	feed.UpdatedAt = time.Now()
+
+	query := `
+		UPDATE calendar_feeds SET
+			name = $2,
+			revoked_at = $3,
+			last_accessed_at = $4,
+			updated_at = $5
+		WHERE id = $1
+	`
+
+	tag, err := database.GetQuerier(ctx, r.pool).Exec(ctx, query,
+		feed.ID,
+		feed.Name,
+		feed.RevokedAt,
+		feed.LastAccessedAt,
+		feed.UpdatedAt,
+	)
+
+	if err != nil {
+		return errorx.Wrap(err, errorx.InternalServerError, "failed to update calendar feed")
+	}
+	if tag.RowsAffected() == 0 {
+		return errorx.New(errorx.NotFound, "calendar feed not found")
+	}
+
+	return nil
+}
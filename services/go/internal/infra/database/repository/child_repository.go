@@ -0,0 +1,461 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/mamacare/services/internal/domain/model"
+	"github.com/mamacare/services/internal/domain/repository"
+	"github.com/mamacare/services/internal/infra/database"
+	"github.com/mamacare/services/pkg/errorx"
+	"github.com/mamacare/services/pkg/logger"
+)
+
+// childColumns is the column list shared by child queries
+const childColumns = `
+	c.id,
+	c.first_name,
+	c.middle_name,
+	c.last_name,
+	c.sex,
+	c.date_of_birth,
+	c.birth_weight_grams,
+	c.birth_length_cm,
+	c.place_of_birth,
+	c.delivery_type,
+	c.gestational_age_weeks,
+	c.delivery_outcome_id,
+	c.mother_id,
+	c.father_id,
+	c.father_name,
+	c.chw_id,
+	c.blood_type,
+	c.allergies,
+	c.disabilities,
+	c.chronic_conditions,
+	c.current_growth_status,
+	c.is_active,
+	c.merged_into_id,
+	c.deactivation_reason,
+	c.created_at,
+	c.updated_at
+`
+
+// childUpdateSet is the SET clause shared by Update and Merge, with the child ID as $1
+const childUpdateSet = `
+	first_name = $2,
+	middle_name = $3,
+	last_name = $4,
+	sex = $5,
+	date_of_birth = $6,
+	birth_weight_grams = $7,
+	birth_length_cm = $8,
+	place_of_birth = $9,
+	delivery_type = $10,
+	gestational_age_weeks = $11,
+	delivery_outcome_id = $12,
+	mother_id = $13,
+	father_id = $14,
+	father_name = $15,
+	chw_id = $16,
+	blood_type = $17,
+	allergies = $18,
+	disabilities = $19,
+	chronic_conditions = $20,
+	current_growth_status = $21,
+	is_active = $22,
+	merged_into_id = $23,
+	deactivation_reason = $24,
+	updated_at = $25
+`
+
+// ChildRepository implements repository.ChildRepository interface
+type ChildRepository struct {
+	pool   *pgxpool.Pool
+	logger logger.Logger
+}
+
+// NewChildRepository creates a new child repository
+func NewChildRepository(pool *pgxpool.Pool, logger logger.Logger) repository.ChildRepository {
+	return &ChildRepository{
+		pool:   pool,
+		logger: logger,
+	}
+}
+
+// scanChild scans a child from a row
+func scanChild(row pgx.Row) (*model.Child, error) {
+	var child model.Child
+	var middleName, sex, placeOfBirth, deliveryType, fatherName *string
+	var bloodType, allergies, disabilities, chronicConditions, growthStatus, deactivationReason *string
+
+	err := row.Scan(
+		&child.ID,
+		&child.FirstName,
+		&middleName,
+		&child.LastName,
+		&sex,
+		&child.DateOfBirth,
+		&child.BirthWeightGrams,
+		&child.BirthLengthCm,
+		&placeOfBirth,
+		&deliveryType,
+		&child.GestationalAgeWeeks,
+		&child.DeliveryOutcomeID,
+		&child.MotherID,
+		&child.FatherID,
+		&fatherName,
+		&child.CHWID,
+		&bloodType,
+		&allergies,
+		&disabilities,
+		&chronicConditions,
+		&growthStatus,
+		&child.IsActive,
+		&child.MergedIntoID,
+		&deactivationReason,
+		&child.CreatedAt,
+		&child.UpdatedAt,
+	)
+
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, errorx.New(errorx.NotFound, "child not found")
+		}
+		return nil, errorx.Wrap(err, errorx.InternalServerError, "failed to scan child")
+	}
+
+	child.MiddleName = stringValue(middleName)
+	child.Sex = stringValue(sex)
+	child.PlaceOfBirth = stringValue(placeOfBirth)
+	child.DeliveryType = model.ChildDeliveryType(stringValue(deliveryType))
+	child.FatherName = stringValue(fatherName)
+	child.BloodType = stringValue(bloodType)
+	child.Allergies = stringValue(allergies)
+	child.Disabilities = stringValue(disabilities)
+	child.ChronicConditions = stringValue(chronicConditions)
+	child.DeactivationReason = stringValue(deactivationReason)
+	if growthStatus != nil {
+		status := model.GrowthStatus(*growthStatus)
+		child.CurrentGrowthStatus = &status
+	}
+
+	return &child, nil
+}
+
+// scanChildren scans multiple children from rows
+func scanChildren(rows pgx.Rows) ([]*model.Child, error) {
+	var children []*model.Child
+
+	for rows.Next() {
+		child, err := scanChild(rows)
+		if err != nil {
+			return nil, err
+		}
+		children = append(children, child)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, errorx.Wrap(err, errorx.InternalServerError, "error iterating over child rows")
+	}
+
+	return children, nil
+}
+
+// childArgs returns the child's fields in the order of childUpdateSet
+func childArgs(child *model.Child) []interface{} {
+	var growthStatus *string
+	if child.CurrentGrowthStatus != nil {
+		status := string(*child.CurrentGrowthStatus)
+		growthStatus = &status
+	}
+
+	return []interface{}{
+		child.ID,
+		child.FirstName,
+		nullableString(child.MiddleName),
+		child.LastName,
+		nullableString(child.Sex),
+		child.DateOfBirth,
+		child.BirthWeightGrams,
+		child.BirthLengthCm,
+		nullableString(child.PlaceOfBirth),
+		nullableString(string(child.DeliveryType)),
+		child.GestationalAgeWeeks,
+		child.DeliveryOutcomeID,
+		child.MotherID,
+		child.FatherID,
+		nullableString(child.FatherName),
+		child.CHWID,
+		nullableString(child.BloodType),
+		nullableString(child.Allergies),
+		nullableString(child.Disabilities),
+		nullableString(child.ChronicConditions),
+		growthStatus,
+		child.IsActive,
+		child.MergedIntoID,
+		nullableString(child.DeactivationReason),
+		child.UpdatedAt,
+	}
+}
+
+// Create creates a new child record
+func (r *ChildRepository) Create(ctx context.Context, child *model.Child) error {
+	query := `
+		INSERT INTO children (
+			id, first_name, middle_name, last_name, sex, date_of_birth,
+			birth_weight_grams, birth_length_cm, place_of_birth, delivery_type,
+			gestational_age_weeks, delivery_outcome_id, mother_id, father_id, father_name,
+			chw_id, blood_type, allergies, disabilities, chronic_conditions,
+			current_growth_status, is_active, merged_into_id, deactivation_reason,
+			updated_at, created_at
+		) VALUES (
+			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13,
+			$14, $15, $16, $17, $18, $19, $20, $21, $22, $23, $24, $25, $26
+		)
+	`
+
+	args := append(childArgs(child), child.CreatedAt)
+	_, err := database.GetQuerier(ctx, r.pool).Exec(ctx, query, args...)
+	if err != nil {
+		return errorx.Wrap(err, errorx.InternalServerError, "failed to create child")
+	}
+
+	return nil
+}
+
+// GetByID retrieves a child by ID
+func (r *ChildRepository) GetByID(ctx context.Context, id uuid.UUID) (*model.Child, error) {
+	query := `SELECT ` + childColumns + ` FROM children c WHERE c.id = $1`
+
+	row := database.GetQuerier(ctx, r.pool).QueryRow(ctx, query, id)
+	return scanChild(row)
+}
+
+// GetByParentID retrieves the active children of a mother or father, youngest first
+func (r *ChildRepository) GetByParentID(ctx context.Context, userID uuid.UUID) ([]*model.Child, error) {
+	query := `SELECT ` + childColumns + `
+		FROM children c
+		WHERE (c.mother_id = $1 OR c.father_id = $1) AND c.is_active
+		ORDER BY c.date_of_birth DESC
+	`
+
+	rows, err := database.GetQuerier(ctx, r.pool).Query(ctx, query, userID)
+	if err != nil {
+		return nil, errorx.Wrap(err, errorx.InternalServerError, "failed to query children by parent")
+	}
+	defer rows.Close()
+
+	return scanChildren(rows)
+}
+
+// GetActiveBornAfter retrieves the active children born on or after the date, oldest first
+func (r *ChildRepository) GetActiveBornAfter(ctx context.Context, date time.Time) ([]*model.Child, error) {
+	query := `SELECT ` + childColumns + `
+		FROM children c
+		WHERE c.is_active AND c.date_of_birth >= $1
+		ORDER BY c.date_of_birth ASC
+	`
+
+	rows, err := database.GetQuerier(ctx, r.pool).Query(ctx, query, date)
+	if err != nil {
+		return nil, errorx.Wrap(err, errorx.InternalServerError, "failed to query children by date of birth")
+	}
+	defer rows.Close()
+
+	return scanChildren(rows)
+}
+
+// GetByDeliveryOutcomeID retrieves the children registered from a delivery outcome
+func (r *ChildRepository) GetByDeliveryOutcomeID(ctx context.Context, outcomeID uuid.UUID) ([]*model.Child, error) {
+	query := `SELECT ` + childColumns + `
+		FROM children c
+		WHERE c.delivery_outcome_id = $1
+		ORDER BY c.created_at ASC
+	`
+
+	rows, err := database.GetQuerier(ctx, r.pool).Query(ctx, query, outcomeID)
+	if err != nil {
+		return nil, errorx.Wrap(err, errorx.InternalServerError, "failed to query children by delivery outcome")
+	}
+	defer rows.Close()
+
+	return scanChildren(rows)
+}
+
+// FindPossibleDuplicates retrieves active children that may be the same child
+func (r *ChildRepository) FindPossibleDuplicates(ctx context.Context, child *model.Child, window time.Duration) ([]*model.Child, error) {
+	// Siblings registered from the same delivery are twins, not duplicates
+	query := `SELECT ` + childColumns + `
+		FROM children c
+		WHERE c.is_active
+			AND c.id <> $1
+			AND c.date_of_birth BETWEEN $2 AND $3
+			AND (
+				(c.mother_id IS NOT NULL AND c.mother_id = $4)
+				OR (lower(c.first_name) = lower($5) AND lower(c.last_name) = lower($6))
+			)
+			AND (c.delivery_outcome_id IS NULL OR $7::uuid IS NULL OR c.delivery_outcome_id <> $7)
+		ORDER BY c.created_at ASC
+	`
+
+	rows, err := database.GetQuerier(ctx, r.pool).Query(ctx, query,
+		child.ID,
+		child.DateOfBirth.Add(-window),
+		child.DateOfBirth.Add(window),
+		child.MotherID,
+		child.FirstName,
+		child.LastName,
+		child.DeliveryOutcomeID,
+	)
+	if err != nil {
+		return nil, errorx.Wrap(err, errorx.InternalServerError, "failed to query possible duplicate children")
+	}
+	defer rows.Close()
+
+	return scanChildren(rows)
+}
+
+// Update updates an existing child record
+func (r *ChildRepository) Update(ctx context.Context, child *model.Child) error {
+	child.UpdatedAt = time.Now()
+
+	query := `UPDATE children SET ` + childUpdateSet + ` WHERE id = $1`
+
+	tag, err := database.GetQuerier(ctx, r.pool).Exec(ctx, query, childArgs(child)...)
+	if err != nil {
+		return errorx.Wrap(err, errorx.InternalServerError, "failed to update child")
+	}
+	if tag.RowsAffected() == 0 {
+		return errorx.New(errorx.NotFound, "child not found")
+	}
+
+	return nil
+}
+
+// UpdateGrowthStatus sets a child's current growth status
+func (r *ChildRepository) UpdateGrowthStatus(ctx context.Context, childID uuid.UUID, status *model.GrowthStatus) error {
+	query := `UPDATE children SET current_growth_status = $2, updated_at = NOW() WHERE id = $1`
+
+	var value *string
+	if status != nil {
+		s := string(*status)
+		value = &s
+	}
+
+	tag, err := database.GetQuerier(ctx, r.pool).Exec(ctx, query, childID, value)
+	if err != nil {
+		return errorx.Wrap(err, errorx.InternalServerError, "failed to update child growth status")
+	}
+	if tag.RowsAffected() == 0 {
+		return errorx.New(errorx.NotFound, "child not found")
+	}
+
+	return nil
+}
+
+// childMoves move a merged duplicate's records ($2) to the primary child ($1), in order.
+// Where a table allows one row per child, date or dose, the primary's row is kept: a
+// dose both records hold stays on the primary as valid and the duplicate's copy is
+// moved as invalid, and neonatal records and KMC days the primary already has stay
+// with the retired duplicate.
+var childMoves = []struct {
+	table string
+	query string
+}{
+	{"visits", `UPDATE visits SET child_id = $1 WHERE child_id = $2`},
+	{"immunization_records", `
+		UPDATE immunization_records d SET
+			is_valid = false,
+			invalid_reason = 'Duplicate of a dose recorded for the merged child',
+			updated_at = NOW()
+		WHERE d.child_id = $2 AND d.is_valid AND EXISTS (
+			SELECT 1 FROM immunization_records p
+			WHERE p.child_id = $1 AND p.is_valid
+				AND p.vaccine_name = d.vaccine_name AND p.vaccine_dose = d.vaccine_dose
+		)`},
+	{"immunization_records", `UPDATE immunization_records SET child_id = $1 WHERE child_id = $2`},
+	{"immunization_reminders", `
+		DELETE FROM immunization_reminders d
+		WHERE d.child_id = $2 AND EXISTS (
+			SELECT 1 FROM immunization_reminders p
+			WHERE p.child_id = $1 AND p.vaccine_name = d.vaccine_name
+				AND p.vaccine_dose = d.vaccine_dose AND p.kind = d.kind
+		)`},
+	{"immunization_reminders", `UPDATE immunization_reminders SET child_id = $1 WHERE child_id = $2`},
+	{"growth_measurements", `UPDATE growth_measurements SET child_id = $1 WHERE child_id = $2`},
+	{"cmam_enrollments", `UPDATE cmam_enrollments SET child_id = $1, updated_at = NOW() WHERE child_id = $2`},
+	{"neonatal_records", `
+		UPDATE neonatal_records SET child_id = $1, updated_at = NOW()
+		WHERE child_id = $2 AND NOT EXISTS (SELECT 1 FROM neonatal_records WHERE child_id = $1)`},
+	{"kmc_sessions", `
+		UPDATE kmc_sessions d SET child_id = $1
+		WHERE d.child_id = $2 AND NOT EXISTS (
+			SELECT 1 FROM kmc_sessions p WHERE p.child_id = $1 AND p.date = d.date
+		)`},
+	{"neonatal_checks", `UPDATE neonatal_checks SET child_id = $1 WHERE child_id = $2`},
+}
+
+// Merge saves the primary record and the deactivated duplicate and moves the duplicate's
+// visits, immunizations, growth measurements, CMAM and neonatal records to the primary.
+// It must run in the caller's transaction so a failed move leaves nothing merged.
+func (r *ChildRepository) Merge(ctx context.Context, primary, duplicate *model.Child) error {
+	primary.UpdatedAt = time.Now()
+	querier := database.GetQuerier(ctx, r.pool)
+
+	// The duplicate is only retired if it is still active, and the primary is only
+	// touched if it was, so a merge cannot be applied twice
+	query := `
+		WITH retired AS (
+			UPDATE children SET
+				is_active = false,
+				merged_into_id = $1,
+				deactivation_reason = $27,
+				updated_at = $25
+			WHERE id = $26 AND is_active
+			RETURNING id
+		)
+		UPDATE children SET ` + childUpdateSet + `
+		WHERE id = $1 AND EXISTS (SELECT 1 FROM retired)
+	`
+
+	args := append(childArgs(primary), duplicate.ID, nullableString(duplicate.DeactivationReason))
+	tag, err := querier.Exec(ctx, query, args...)
+	if err != nil {
+		return errorx.Wrap(err, errorx.InternalServerError, "failed to merge children")
+	}
+	if tag.RowsAffected() == 0 {
+		return errorx.New(errorx.BadRequest, "duplicate child is no longer active")
+	}
+
+	for _, move := range childMoves {
+		if _, err := querier.Exec(ctx, move.query, primary.ID, duplicate.ID); err != nil {
+			if pgErr, ok := err.(*pgconn.PgError); ok && pgErr.Code == "23505" && move.table == "cmam_enrollments" { // Unique violation
+				return errorx.New(errorx.BadRequest, "both children have an open malnutrition referral or treatment; close one before merging")
+			}
+			return errorx.Wrap(err, errorx.InternalServerError, "failed to move "+move.table+" to the merged child")
+		}
+	}
+
+	return nil
+}
+
+// nullableString returns nil for an empty string so optional text columns are stored as NULL
+func nullableString(value string) *string {
+	if value == "" {
+		return nil
+	}
+	return &value
+}
+
+// stringValue returns the string or an empty string for NULL
+func stringValue(value *string) string {
+	if value == nil {
+		return ""
+	}
+	return *value
+}
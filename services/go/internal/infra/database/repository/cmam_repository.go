@@ -0,0 +1,565 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/mamacare/services/internal/domain/model"
+	"github.com/mamacare/services/internal/domain/repository"
+	"github.com/mamacare/services/internal/infra/database"
+	"github.com/mamacare/services/pkg/errorx"
+	"github.com/mamacare/services/pkg/logger"
+)
+
+// cmamEnrollmentColumns is the column list shared by CMAM enrollment queries
+const cmamEnrollmentColumns = `
+	ce.id,
+	ce.child_id,
+	ce.facility_id,
+	ce.programme,
+	ce.status,
+	ce.growth_measurement_id,
+	ce.referred_at,
+	ce.admitted_at,
+	ce.admission_criteria,
+	ce.admission_weight_grams,
+	ce.admission_height_cm::float8,
+	ce.admission_muac_cm::float8,
+	ce.admission_whz::float8,
+	ce.admission_oedema_grade,
+	ce.complications,
+	ce.target_weight_grams,
+	ce.admitted_by_id,
+	ce.exited_at,
+	ce.exit_weight_grams,
+	ce.exit_notes,
+	ce.created_at,
+	ce.updated_at
+`
+
+// cmamFollowUpColumns is the column list shared by CMAM follow-up queries
+const cmamFollowUpColumns = `
+	cf.id,
+	cf.enrollment_id,
+	cf.week,
+	cf.scheduled_date,
+	cf.status,
+	cf.visited_at,
+	cf.weight_grams,
+	cf.height_cm::float8,
+	cf.muac_cm::float8,
+	cf.whz::float8,
+	cf.oedema_grade,
+	cf.rutf_sachets,
+	cf.recorded_by_id,
+	cf.notes,
+	cf.created_at,
+	cf.updated_at
+`
+
+// CMAMRepository implements repository.CMAMRepository interface
+type CMAMRepository struct {
+	pool   *pgxpool.Pool
+	logger logger.Logger
+}
+
+// NewCMAMRepository creates a new CMAM repository
+func NewCMAMRepository(pool *pgxpool.Pool, logger logger.Logger) repository.CMAMRepository {
+	return &CMAMRepository{
+		pool:   pool,
+		logger: logger,
+	}
+}
+
+// scanCMAMEnrollment scans a CMAM enrollment from a row
+func scanCMAMEnrollment(row pgx.Row) (*model.CMAMEnrollment, error) {
+	var enrollment model.CMAMEnrollment
+	var programme *string
+	var criteria []string
+
+	err := row.Scan(
+		&enrollment.ID,
+		&enrollment.ChildID,
+		&enrollment.FacilityID,
+		&programme,
+		&enrollment.Status,
+		&enrollment.GrowthMeasurementID,
+		&enrollment.ReferredAt,
+		&enrollment.AdmittedAt,
+		&criteria,
+		&enrollment.AdmissionWeightGrams,
+		&enrollment.AdmissionHeightCm,
+		&enrollment.AdmissionMUACCm,
+		&enrollment.AdmissionWHZ,
+		&enrollment.AdmissionOedemaGrade,
+		&enrollment.Complications,
+		&enrollment.TargetWeightGrams,
+		&enrollment.AdmittedByID,
+		&enrollment.ExitedAt,
+		&enrollment.ExitWeightGrams,
+		&enrollment.ExitNotes,
+		&enrollment.CreatedAt,
+		&enrollment.UpdatedAt,
+	)
+
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, errorx.New(errorx.NotFound, "CMAM enrollment not found")
+		}
+		return nil, errorx.Wrap(err, errorx.InternalServerError, "failed to scan CMAM enrollment")
+	}
+
+	enrollment.Programme = model.CMAMProgramme(stringValue(programme))
+	enrollment.AdmissionCriteria = make([]model.CMAMCriterion, 0, len(criteria))
+	for _, criterion := range criteria {
+		enrollment.AdmissionCriteria = append(enrollment.AdmissionCriteria, model.CMAMCriterion(criterion))
+	}
+
+	return &enrollment, nil
+}
+
+// scanCMAMFollowUp scans a CMAM follow-up from a row
+func scanCMAMFollowUp(row pgx.Row) (*model.CMAMFollowUp, error) {
+	var followUp model.CMAMFollowUp
+
+	err := row.Scan(
+		&followUp.ID,
+		&followUp.EnrollmentID,
+		&followUp.Week,
+		&followUp.ScheduledDate,
+		&followUp.Status,
+		&followUp.VisitedAt,
+		&followUp.WeightGrams,
+		&followUp.HeightCm,
+		&followUp.MUACCm,
+		&followUp.WHZ,
+		&followUp.OedemaGrade,
+		&followUp.RUTFSachets,
+		&followUp.RecordedByID,
+		&followUp.Notes,
+		&followUp.CreatedAt,
+		&followUp.UpdatedAt,
+	)
+
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, errorx.New(errorx.NotFound, "CMAM follow-up not found")
+		}
+		return nil, errorx.Wrap(err, errorx.InternalServerError, "failed to scan CMAM follow-up")
+	}
+
+	return &followUp, nil
+}
+
+// CreateEnrollment stores a referral or enrollment
+func (r *CMAMRepository) CreateEnrollment(ctx context.Context, enrollment *model.CMAMEnrollment) error {
+	query := `
+		INSERT INTO cmam_enrollments (
+			id, child_id, facility_id, programme, status, growth_measurement_id,
+			referred_at, admitted_at, admission_criteria, admission_weight_grams,
+			admission_height_cm, admission_muac_cm, admission_whz, admission_oedema_grade,
+			complications, target_weight_grams, admitted_by_id, exited_at,
+			exit_weight_grams, exit_notes, created_at, updated_at
+		) VALUES (
+			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11,
+			$12, $13, $14, $15, $16, $17, $18, $19, $20, $21, $22
+		)
+	`
+
+	_, err := database.GetQuerier(ctx, r.pool).Exec(ctx, query,
+		enrollment.ID,
+		enrollment.ChildID,
+		enrollment.FacilityID,
+		nullableString(string(enrollment.Programme)),
+		enrollment.Status,
+		enrollment.GrowthMeasurementID,
+		enrollment.ReferredAt,
+		enrollment.AdmittedAt,
+		criteriaStrings(enrollment.AdmissionCriteria),
+		enrollment.AdmissionWeightGrams,
+		enrollment.AdmissionHeightCm,
+		enrollment.AdmissionMUACCm,
+		enrollment.AdmissionWHZ,
+		enrollment.AdmissionOedemaGrade,
+		enrollment.Complications,
+		enrollment.TargetWeightGrams,
+		enrollment.AdmittedByID,
+		enrollment.ExitedAt,
+		enrollment.ExitWeightGrams,
+		enrollment.ExitNotes,
+		enrollment.CreatedAt,
+		enrollment.UpdatedAt,
+	)
+
+	if err != nil {
+		if pgErr, ok := err.(*pgconn.PgError); ok && pgErr.Code == "23505" { // Unique violation
+			return errorx.New(errorx.AlreadyExists, "child already has an open CMAM referral or enrollment")
+		}
+		return errorx.Wrap(err, errorx.InternalServerError, "failed to create CMAM enrollment")
+	}
+
+	return nil
+}
+
+// UpdateEnrollment updates an enrollment's admission details, status and exit
+func (r *CMAMRepository) UpdateEnrollment(ctx context.Context, enrollment *model.CMAMEnrollment) error {
+	query := `
+		UPDATE cmam_enrollments SET
+			facility_id = $2,
+			programme = $3,
+			status = $4,
+			admitted_at = $5,
+			admission_criteria = $6,
+			admission_weight_grams = $7,
+			admission_height_cm = $8,
+			admission_muac_cm = $9,
+			admission_whz = $10,
+			admission_oedema_grade = $11,
+			complications = $12,
+			target_weight_grams = $13,
+			admitted_by_id = $14,
+			exited_at = $15,
+			exit_weight_grams = $16,
+			exit_notes = $17,
+			updated_at = $18
+		WHERE id = $1
+	`
+
+	result, err := database.GetQuerier(ctx, r.pool).Exec(ctx, query,
+		enrollment.ID,
+		enrollment.FacilityID,
+		nullableString(string(enrollment.Programme)),
+		enrollment.Status,
+		enrollment.AdmittedAt,
+		criteriaStrings(enrollment.AdmissionCriteria),
+		enrollment.AdmissionWeightGrams,
+		enrollment.AdmissionHeightCm,
+		enrollment.AdmissionMUACCm,
+		enrollment.AdmissionWHZ,
+		enrollment.AdmissionOedemaGrade,
+		enrollment.Complications,
+		enrollment.TargetWeightGrams,
+		enrollment.AdmittedByID,
+		enrollment.ExitedAt,
+		enrollment.ExitWeightGrams,
+		enrollment.ExitNotes,
+		enrollment.UpdatedAt,
+	)
+
+	if err != nil {
+		return errorx.Wrap(err, errorx.InternalServerError, "failed to update CMAM enrollment")
+	}
+
+	if result.RowsAffected() == 0 {
+		return errorx.New(errorx.NotFound, "CMAM enrollment not found")
+	}
+
+	return nil
+}
+
+// GetEnrollmentByID retrieves an enrollment
+func (r *CMAMRepository) GetEnrollmentByID(ctx context.Context, id uuid.UUID) (*model.CMAMEnrollment, error) {
+	query := `SELECT ` + cmamEnrollmentColumns + `
+		FROM cmam_enrollments ce
+		WHERE ce.id = $1
+	`
+
+	row := database.GetQuerier(ctx, r.pool).QueryRow(ctx, query, id)
+	return scanCMAMEnrollment(row)
+}
+
+// GetOpenEnrollment retrieves a child's referred or active enrollment
+func (r *CMAMRepository) GetOpenEnrollment(ctx context.Context, childID uuid.UUID) (*model.CMAMEnrollment, error) {
+	query := `SELECT ` + cmamEnrollmentColumns + `
+		FROM cmam_enrollments ce
+		WHERE ce.child_id = $1 AND ce.status IN ('referred', 'active')
+	`
+
+	row := database.GetQuerier(ctx, r.pool).QueryRow(ctx, query, childID)
+	return scanCMAMEnrollment(row)
+}
+
+// GetEnrollmentsByChildID retrieves a child's enrollments, newest first
+func (r *CMAMRepository) GetEnrollmentsByChildID(ctx context.Context, childID uuid.UUID) ([]*model.CMAMEnrollment, error) {
+	query := `SELECT ` + cmamEnrollmentColumns + `
+		FROM cmam_enrollments ce
+		WHERE ce.child_id = $1
+		ORDER BY ce.referred_at DESC, ce.created_at DESC
+	`
+
+	rows, err := database.GetQuerier(ctx, r.pool).Query(ctx, query, childID)
+	if err != nil {
+		return nil, errorx.Wrap(err, errorx.InternalServerError, "failed to query CMAM enrollments by child")
+	}
+	defer rows.Close()
+
+	var enrollments []*model.CMAMEnrollment
+	for rows.Next() {
+		enrollment, err := scanCMAMEnrollment(rows)
+		if err != nil {
+			return nil, err
+		}
+		enrollments = append(enrollments, enrollment)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, errorx.Wrap(err, errorx.InternalServerError, "error iterating over CMAM enrollment rows")
+	}
+
+	return enrollments, nil
+}
+
+// CreateFollowUp schedules a follow-up
+func (r *CMAMRepository) CreateFollowUp(ctx context.Context, followUp *model.CMAMFollowUp) error {
+	query := `
+		INSERT INTO cmam_follow_ups (
+			id, enrollment_id, week, scheduled_date, status, visited_at,
+			weight_grams, height_cm, muac_cm, whz, oedema_grade, rutf_sachets,
+			recorded_by_id, notes, created_at, updated_at
+		) VALUES (
+			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16
+		)
+	`
+
+	_, err := database.GetQuerier(ctx, r.pool).Exec(ctx, query,
+		followUp.ID,
+		followUp.EnrollmentID,
+		followUp.Week,
+		followUp.ScheduledDate,
+		followUp.Status,
+		followUp.VisitedAt,
+		followUp.WeightGrams,
+		followUp.HeightCm,
+		followUp.MUACCm,
+		followUp.WHZ,
+		followUp.OedemaGrade,
+		followUp.RUTFSachets,
+		followUp.RecordedByID,
+		followUp.Notes,
+		followUp.CreatedAt,
+		followUp.UpdatedAt,
+	)
+
+	if err != nil {
+		if pgErr, ok := err.(*pgconn.PgError); ok && pgErr.Code == "23505" { // Unique violation
+			return errorx.Newf(errorx.AlreadyExists, "week %d follow-up is already scheduled", followUp.Week)
+		}
+		return errorx.Wrap(err, errorx.InternalServerError, "failed to create CMAM follow-up")
+	}
+
+	return nil
+}
+
+// UpdateFollowUp updates a follow-up's status and measurements
+func (r *CMAMRepository) UpdateFollowUp(ctx context.Context, followUp *model.CMAMFollowUp) error {
+	query := `
+		UPDATE cmam_follow_ups SET
+			status = $2,
+			visited_at = $3,
+			weight_grams = $4,
+			height_cm = $5,
+			muac_cm = $6,
+			whz = $7,
+			oedema_grade = $8,
+			rutf_sachets = $9,
+			recorded_by_id = $10,
+			notes = $11,
+			updated_at = $12
+		WHERE id = $1
+	`
+
+	result, err := database.GetQuerier(ctx, r.pool).Exec(ctx, query, followUpValues(followUp)...)
+	if err != nil {
+		return errorx.Wrap(err, errorx.InternalServerError, "failed to update CMAM follow-up")
+	}
+
+	if result.RowsAffected() == 0 {
+		return errorx.New(errorx.NotFound, "CMAM follow-up not found")
+	}
+
+	return nil
+}
+
+// RecordFollowUp updates an attended follow-up and takes its RUTF ration off the facility's stock
+func (r *CMAMRepository) RecordFollowUp(ctx context.Context, followUp *model.CMAMFollowUp, facilityID uuid.UUID) (*model.SupplementStock, error) {
+	// As with maternal supplements, the stock update and the follow-up are one statement
+	// so a ration is never recorded without being taken off stock
+	query := `
+		WITH stock AS (
+			UPDATE supplement_stocks
+			SET quantity_on_hand = quantity_on_hand - $9, updated_at = $12
+			WHERE facility_id = $13 AND item = 'rutf_sachet' AND quantity_on_hand >= $9
+			RETURNING facility_id, item, quantity_on_hand, updated_at
+		), follow_up AS (
+			UPDATE cmam_follow_ups SET
+				status = $2,
+				visited_at = $3,
+				weight_grams = $4,
+				height_cm = $5,
+				muac_cm = $6,
+				whz = $7,
+				oedema_grade = $8,
+				rutf_sachets = $9,
+				recorded_by_id = $10,
+				notes = $11,
+				updated_at = $12
+			WHERE id = $1 AND EXISTS (SELECT 1 FROM stock)
+		)
+		SELECT facility_id, item, quantity_on_hand, updated_at FROM stock
+	`
+
+	args := append(followUpValues(followUp), facilityID)
+
+	var stock model.SupplementStock
+	err := database.GetQuerier(ctx, r.pool).QueryRow(ctx, query, args...).
+		Scan(&stock.FacilityID, &stock.Item, &stock.QuantityOnHand, &stock.UpdatedAt)
+
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, errorx.New(errorx.BadRequest, "not enough RUTF in stock at the facility")
+		}
+		return nil, errorx.Wrap(err, errorx.InternalServerError, "failed to record CMAM follow-up")
+	}
+
+	return &stock, nil
+}
+
+// GetFollowUpsByEnrollment retrieves an enrollment's follow-ups in week order
+func (r *CMAMRepository) GetFollowUpsByEnrollment(ctx context.Context, enrollmentID uuid.UUID) ([]*model.CMAMFollowUp, error) {
+	query := `SELECT ` + cmamFollowUpColumns + `
+		FROM cmam_follow_ups cf
+		WHERE cf.enrollment_id = $1
+		ORDER BY cf.week
+	`
+
+	return r.queryFollowUps(ctx, query, enrollmentID)
+}
+
+// GetOverdueFollowUps retrieves scheduled follow-ups of active enrollments due before the given date
+func (r *CMAMRepository) GetOverdueFollowUps(ctx context.Context, before time.Time) ([]*model.CMAMFollowUp, error) {
+	query := `SELECT ` + cmamFollowUpColumns + `
+		FROM cmam_follow_ups cf
+		JOIN cmam_enrollments ce ON ce.id = cf.enrollment_id
+		WHERE cf.status = 'scheduled' AND cf.scheduled_date < $1 AND ce.status = 'active'
+		ORDER BY cf.scheduled_date
+	`
+
+	return r.queryFollowUps(ctx, query, before)
+}
+
+// GetFacilityOutcomes counts admissions and exits by outcome per facility and programme over a period
+func (r *CMAMRepository) GetFacilityOutcomes(ctx context.Context, from, to time.Time, facilityID *uuid.UUID) ([]*model.CMAMFacilityOutcomes, error) {
+	query := `
+		SELECT
+			ce.facility_id,
+			f.name,
+			ce.programme,
+			COUNT(*) FILTER (WHERE ce.admitted_at BETWEEN $1 AND $2),
+			COUNT(*) FILTER (WHERE ce.status = 'cured' AND ce.exited_at BETWEEN $1 AND $2),
+			COUNT(*) FILTER (WHERE ce.status = 'defaulted' AND ce.exited_at BETWEEN $1 AND $2),
+			COUNT(*) FILTER (WHERE ce.status = 'died' AND ce.exited_at BETWEEN $1 AND $2),
+			COUNT(*) FILTER (WHERE ce.status = 'non_responder' AND ce.exited_at BETWEEN $1 AND $2),
+			AVG(ce.exited_at - ce.admitted_at)
+				FILTER (WHERE ce.status = 'cured' AND ce.exited_at BETWEEN $1 AND $2)::float8,
+			AVG(
+				(ce.exit_weight_grams - ce.admission_weight_grams)::float8
+				/ (ce.admission_weight_grams / 1000.0)
+				/ NULLIF(ce.exited_at - ce.admitted_at, 0)
+			) FILTER (WHERE ce.status = 'cured' AND ce.exited_at BETWEEN $1 AND $2
+				AND ce.exit_weight_grams IS NOT NULL AND ce.admission_weight_grams IS NOT NULL)::float8
+		FROM cmam_enrollments ce
+		JOIN facilities f ON f.id = ce.facility_id
+		WHERE ce.admitted_at IS NOT NULL
+			AND (ce.admitted_at BETWEEN $1 AND $2 OR ce.exited_at BETWEEN $1 AND $2)
+			AND ($3::uuid IS NULL OR ce.facility_id = $3)
+		GROUP BY ce.facility_id, f.name, ce.programme
+		ORDER BY f.name, ce.programme
+	`
+
+	rows, err := database.GetQuerier(ctx, r.pool).Query(ctx, query, from, to, facilityID)
+	if err != nil {
+		return nil, errorx.Wrap(err, errorx.InternalServerError, "failed to query CMAM outcomes")
+	}
+	defer rows.Close()
+
+	var outcomes []*model.CMAMFacilityOutcomes
+	for rows.Next() {
+		var outcome model.CMAMFacilityOutcomes
+		if err := rows.Scan(
+			&outcome.FacilityID,
+			&outcome.FacilityName,
+			&outcome.Programme,
+			&outcome.Admissions,
+			&outcome.Cured,
+			&outcome.Defaulted,
+			&outcome.Died,
+			&outcome.NonResponder,
+			&outcome.MeanLengthOfStayDays,
+			&outcome.MeanWeightGainGKgDay,
+		); err != nil {
+			return nil, errorx.Wrap(err, errorx.InternalServerError, "failed to scan CMAM outcomes")
+		}
+		outcomes = append(outcomes, &outcome)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, errorx.Wrap(err, errorx.InternalServerError, "error iterating over CMAM outcome rows")
+	}
+
+	return outcomes, nil
+}
+
+// queryFollowUps runs a follow-up query and scans every row
+func (r *CMAMRepository) queryFollowUps(ctx context.Context, query string, args ...interface{}) ([]*model.CMAMFollowUp, error) {
+	rows, err := database.GetQuerier(ctx, r.pool).Query(ctx, query, args...)
+	if err != nil {
+		return nil, errorx.Wrap(err, errorx.InternalServerError, "failed to query CMAM follow-ups")
+	}
+	defer rows.Close()
+
+	var followUps []*model.CMAMFollowUp
+	for rows.Next() {
+		followUp, err := scanCMAMFollowUp(rows)
+		if err != nil {
+			return nil, err
+		}
+		followUps = append(followUps, followUp)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, errorx.Wrap(err, errorx.InternalServerError, "error iterating over CMAM follow-up rows")
+	}
+
+	return followUps, nil
+}
+
+// followUpValues returns the follow-up's update parameters, $1 to $12
+func followUpValues(followUp *model.CMAMFollowUp) []interface{} {
+	return []interface{}{
+		followUp.ID,
+		followUp.Status,
+		followUp.VisitedAt,
+		followUp.WeightGrams,
+		followUp.HeightCm,
+		followUp.MUACCm,
+		followUp.WHZ,
+		followUp.OedemaGrade,
+		followUp.RUTFSachets,
+		followUp.RecordedByID,
+		followUp.Notes,
+		followUp.UpdatedAt,
+	}
+}
+
+// criteriaStrings converts admission criteria to a text array
+func criteriaStrings(criteria []model.CMAMCriterion) []string {
+	values := make([]string, 0, len(criteria))
+	for _, criterion := range criteria {
+		values = append(values, string(criterion))
+	}
+	return values
+}
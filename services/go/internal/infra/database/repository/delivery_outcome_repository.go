@@ -0,0 +1,261 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/mamacare/services/internal/domain/model"
+	"github.com/mamacare/services/internal/domain/repository"
+	"github.com/mamacare/services/internal/infra/database"
+	"github.com/mamacare/services/pkg/errorx"
+	"github.com/mamacare/services/pkg/logger"
+)
+
+// deliveryOutcomeColumns is the column list shared by delivery outcome queries
+const deliveryOutcomeColumns = `
+	d.id,
+	d.mother_id,
+	d.delivery_date,
+	d.place,
+	d.facility_id,
+	d.mode,
+	d.gestational_age_weeks,
+	d.maternal_outcome,
+	d.maternal_complications,
+	d.newborns,
+	d.attended_by_id,
+	d.recorded_by_id,
+	d.notes,
+	d.created_at,
+	d.updated_at
+`
+
+// DeliveryOutcomeRepository implements repository.DeliveryOutcomeRepository interface
+type DeliveryOutcomeRepository struct {
+	pool   *pgxpool.Pool
+	logger logger.Logger
+}
+
+// NewDeliveryOutcomeRepository creates a new delivery outcome repository
+func NewDeliveryOutcomeRepository(pool *pgxpool.Pool, logger logger.Logger) repository.DeliveryOutcomeRepository {
+	return &DeliveryOutcomeRepository{
+		pool:   pool,
+		logger: logger,
+	}
+}
+
+// scanDeliveryOutcome scans a delivery outcome from a row
+func scanDeliveryOutcome(row pgx.Row) (*model.DeliveryOutcome, error) {
+	var outcome model.DeliveryOutcome
+	var gestationalAge *int
+	var complications []string
+	var newbornsJSON []byte
+	var notes *string
+
+	err := row.Scan(
+		&outcome.ID,
+		&outcome.MotherID,
+		&outcome.DeliveryDate,
+		&outcome.Place,
+		&outcome.FacilityID,
+		&outcome.Mode,
+		&gestationalAge,
+		&outcome.MaternalOutcome,
+		&complications,
+		&newbornsJSON,
+		&outcome.AttendedByID,
+		&outcome.RecordedByID,
+		&notes,
+		&outcome.CreatedAt,
+		&outcome.UpdatedAt,
+	)
+
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, errorx.New(errorx.NotFound, "delivery outcome not found")
+		}
+		return nil, errorx.Wrap(err, errorx.InternalServerError, "failed to scan delivery outcome")
+	}
+
+	if gestationalAge != nil {
+		outcome.GestationalAgeWeeks = *gestationalAge
+	}
+	if notes != nil {
+		outcome.Notes = *notes
+	}
+	outcome.MaternalComplications = complications
+
+	outcome.Newborns = []model.NewbornOutcome{}
+	if newbornsJSON != nil {
+		if err := json.Unmarshal(newbornsJSON, &outcome.Newborns); err != nil {
+			return nil, errorx.Wrap(err, errorx.InternalServerError, "failed to unmarshal newborn outcomes")
+		}
+	}
+
+	return &outcome, nil
+}
+
+// scanDeliveryOutcomes scans multiple delivery outcomes from rows
+func scanDeliveryOutcomes(rows pgx.Rows) ([]*model.DeliveryOutcome, error) {
+	var outcomes []*model.DeliveryOutcome
+
+	for rows.Next() {
+		outcome, err := scanDeliveryOutcome(rows)
+		if err != nil {
+			return nil, err
+		}
+		outcomes = append(outcomes, outcome)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, errorx.Wrap(err, errorx.InternalServerError, "error iterating over delivery outcome rows")
+	}
+
+	return outcomes, nil
+}
+
+// Create creates a new delivery outcome
+func (r *DeliveryOutcomeRepository) Create(ctx context.Context, outcome *model.DeliveryOutcome) error {
+	newbornsJSON, err := json.Marshal(outcome.Newborns)
+	if err != nil {
+		return errorx.Wrap(err, errorx.InternalServerError, "failed to marshal newborn outcomes")
+	}
+
+	query := `
+		INSERT INTO delivery_outcomes (
+			id, mother_id, delivery_date, place, facility_id, mode, gestational_age_weeks,
+			maternal_outcome, maternal_complications, newborns, attended_by_id, recorded_by_id,
+			notes, created_at, updated_at
+		) VALUES (
+			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15
+		)
+	`
+
+	_, err = database.GetQuerier(ctx, r.pool).Exec(ctx, query,
+		outcome.ID,
+		outcome.MotherID,
+		outcome.DeliveryDate,
+		outcome.Place,
+		outcome.FacilityID,
+		outcome.Mode,
+		nullableInt(outcome.GestationalAgeWeeks),
+		outcome.MaternalOutcome,
+		outcome.MaternalComplications,
+		newbornsJSON,
+		outcome.AttendedByID,
+		outcome.RecordedByID,
+		outcome.Notes,
+		outcome.CreatedAt,
+		outcome.UpdatedAt,
+	)
+
+	if err != nil {
+		return errorx.Wrap(err, errorx.InternalServerError, "failed to create delivery outcome")
+	}
+
+	return nil
+}
+
+// GetByID retrieves a delivery outcome by its ID
+func (r *DeliveryOutcomeRepository) GetByID(ctx context.Context, id uuid.UUID) (*model.DeliveryOutcome, error) {
+	query := `SELECT ` + deliveryOutcomeColumns + ` FROM delivery_outcomes d WHERE d.id = $1`
+
+	row := database.GetQuerier(ctx, r.pool).QueryRow(ctx, query, id)
+	return scanDeliveryOutcome(row)
+}
+
+// GetByMotherID retrieves delivery outcomes for a mother, most recent first
+func (r *DeliveryOutcomeRepository) GetByMotherID(ctx context.Context, motherID uuid.UUID) ([]*model.DeliveryOutcome, error) {
+	query := `SELECT ` + deliveryOutcomeColumns + `
+		FROM delivery_outcomes d
+		WHERE d.mother_id = $1
+		ORDER BY d.delivery_date DESC
+	`
+
+	rows, err := database.GetQuerier(ctx, r.pool).Query(ctx, query, motherID)
+	if err != nil {
+		return nil, errorx.Wrap(err, errorx.InternalServerError, "failed to query delivery outcomes by mother")
+	}
+	defer rows.Close()
+
+	return scanDeliveryOutcomes(rows)
+}
+
+// GetByDateRange retrieves delivery outcomes with a delivery date in the range
+func (r *DeliveryOutcomeRepository) GetByDateRange(ctx context.Context, start, end time.Time) ([]*model.DeliveryOutcome, error) {
+	query := `SELECT ` + deliveryOutcomeColumns + `
+		FROM delivery_outcomes d
+		WHERE d.delivery_date >= $1 AND d.delivery_date < $2
+		ORDER BY d.delivery_date ASC
+	`
+
+	rows, err := database.GetQuerier(ctx, r.pool).Query(ctx, query, start, end)
+	if err != nil {
+		return nil, errorx.Wrap(err, errorx.InternalServerError, "failed to query delivery outcomes by date range")
+	}
+	defer rows.Close()
+
+	return scanDeliveryOutcomes(rows)
+}
+
+// Update updates an existing delivery outcome
+func (r *DeliveryOutcomeRepository) Update(ctx context.Context, outcome *model.DeliveryOutcome) error {
+	outcome.UpdatedAt = time.Now()
+
+	newbornsJSON, err := json.Marshal(outcome.Newborns)
+	if err != nil {
+		return errorx.Wrap(err, errorx.InternalServerError, "failed to marshal newborn outcomes")
+	}
+
+	query := `
+		UPDATE delivery_outcomes SET
+			delivery_date = $2,
+			place = $3,
+			facility_id = $4,
+			mode = $5,
+			gestational_age_weeks = $6,
+			maternal_outcome = $7,
+			maternal_complications = $8,
+			newborns = $9,
+			attended_by_id = $10,
+			notes = $11,
+			updated_at = $12
+		WHERE id = $1
+	`
+
+	tag, err := database.GetQuerier(ctx, r.pool).Exec(ctx, query,
+		outcome.ID,
+		outcome.DeliveryDate,
+		outcome.Place,
+		outcome.FacilityID,
+		outcome.Mode,
+		nullableInt(outcome.GestationalAgeWeeks),
+		outcome.MaternalOutcome,
+		outcome.MaternalComplications,
+		newbornsJSON,
+		outcome.AttendedByID,
+		outcome.Notes,
+		outcome.UpdatedAt,
+	)
+
+	if err != nil {
+		return errorx.Wrap(err, errorx.InternalServerError, "failed to update delivery outcome")
+	}
+	if tag.RowsAffected() == 0 {
+		return errorx.New(errorx.NotFound, "delivery outcome not found")
+	}
+
+	return nil
+}
+
+// nullableInt returns nil for zero so optional integer columns are stored as NULL
+func nullableInt(value int) *int {
+	if value == 0 {
+		return nil
+	}
+	return &value
+}
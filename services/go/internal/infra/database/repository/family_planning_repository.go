@@ -0,0 +1,366 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/mamacare/services/internal/domain/model"
+	"github.com/mamacare/services/internal/domain/repository"
+	"github.com/mamacare/services/internal/infra/database"
+	"github.com/mamacare/services/pkg/errorx"
+	"github.com/mamacare/services/pkg/logger"
+)
+
+// familyPlanningVisitColumns is the column list shared by family planning visit queries
+const familyPlanningVisitColumns = `
+	fv.id,
+	fv.mother_id,
+	fv.facility_id,
+	fv.visit_date,
+	fv.postpartum_days,
+	fv.topics,
+	fv.method,
+	fv.method_given,
+	fv.resupply,
+	fv.quantity,
+	fv.next_due_date,
+	fv.discontinued,
+	fv.discontinuation_reason,
+	fv.due_reminder_sent_at,
+	fv.overdue_reminder_sent_at,
+	fv.recorded_by_id,
+	fv.notes,
+	fv.created_at,
+	fv.updated_at
+`
+
+// FamilyPlanningRepository implements repository.FamilyPlanningRepository interface
+type FamilyPlanningRepository struct {
+	pool   *pgxpool.Pool
+	logger logger.Logger
+}
+
+// NewFamilyPlanningRepository creates a new family planning repository
+func NewFamilyPlanningRepository(pool *pgxpool.Pool, logger logger.Logger) repository.FamilyPlanningRepository {
+	return &FamilyPlanningRepository{
+		pool:   pool,
+		logger: logger,
+	}
+}
+
+// scanFamilyPlanningVisit scans a family planning visit from a row
+func scanFamilyPlanningVisit(row pgx.Row) (*model.FamilyPlanningVisit, error) {
+	var visit model.FamilyPlanningVisit
+	var topics []string
+	var method *string
+
+	err := row.Scan(
+		&visit.ID,
+		&visit.MotherID,
+		&visit.FacilityID,
+		&visit.VisitDate,
+		&visit.PostpartumDays,
+		&topics,
+		&method,
+		&visit.MethodGiven,
+		&visit.Resupply,
+		&visit.Quantity,
+		&visit.NextDueDate,
+		&visit.Discontinued,
+		&visit.DiscontinuationReason,
+		&visit.DueReminderSentAt,
+		&visit.OverdueReminderSentAt,
+		&visit.RecordedByID,
+		&visit.Notes,
+		&visit.CreatedAt,
+		&visit.UpdatedAt,
+	)
+
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, errorx.New(errorx.NotFound, "family planning visit not found")
+		}
+		return nil, errorx.Wrap(err, errorx.InternalServerError, "failed to scan family planning visit")
+	}
+
+	visit.Topics = make([]model.FPTopic, 0, len(topics))
+	for _, topic := range topics {
+		visit.Topics = append(visit.Topics, model.FPTopic(topic))
+	}
+	if method != nil {
+		m := model.FPMethod(*method)
+		visit.Method = &m
+	}
+
+	return &visit, nil
+}
+
+// CreateVisit stores a family planning visit
+func (r *FamilyPlanningRepository) CreateVisit(ctx context.Context, visit *model.FamilyPlanningVisit) error {
+	query := `
+		INSERT INTO family_planning_visits (
+			id, mother_id, facility_id, visit_date, postpartum_days, topics, method,
+			method_given, resupply, quantity, next_due_date, discontinued,
+			discontinuation_reason, recorded_by_id, notes, created_at, updated_at
+		) VALUES (
+			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17
+		)
+	`
+
+	topics := make([]string, 0, len(visit.Topics))
+	for _, topic := range visit.Topics {
+		topics = append(topics, string(topic))
+	}
+	var method *string
+	if visit.Method != nil {
+		method = nullableString(string(*visit.Method))
+	}
+
+	_, err := database.GetQuerier(ctx, r.pool).Exec(ctx, query,
+		visit.ID,
+		visit.MotherID,
+		visit.FacilityID,
+		visit.VisitDate,
+		visit.PostpartumDays,
+		topics,
+		method,
+		visit.MethodGiven,
+		visit.Resupply,
+		visit.Quantity,
+		visit.NextDueDate,
+		visit.Discontinued,
+		visit.DiscontinuationReason,
+		visit.RecordedByID,
+		visit.Notes,
+		visit.CreatedAt,
+		visit.UpdatedAt,
+	)
+
+	if err != nil {
+		return errorx.Wrap(err, errorx.InternalServerError, "failed to create family planning visit")
+	}
+
+	return nil
+}
+
+// GetVisitsByMotherID retrieves a mother's family planning visits, newest first
+func (r *FamilyPlanningRepository) GetVisitsByMotherID(ctx context.Context, motherID uuid.UUID) ([]*model.FamilyPlanningVisit, error) {
+	query := `SELECT ` + familyPlanningVisitColumns + `
+		FROM family_planning_visits fv
+		WHERE fv.mother_id = $1
+		ORDER BY fv.visit_date DESC, fv.created_at DESC
+	`
+
+	return r.queryVisits(ctx, query, motherID)
+}
+
+// GetRemindersDue retrieves each mother's latest visit at which a method was given or
+// stopped, where a method was given with a next due date on or before the given date and
+// the reminder of the given kind has not been sent
+func (r *FamilyPlanningRepository) GetRemindersDue(ctx context.Context, kind model.FPReminderKind, before time.Time) ([]*model.FamilyPlanningVisit, error) {
+	sentColumn := "fv.due_reminder_sent_at"
+	if kind == model.FPReminderOverdue {
+		sentColumn = "fv.overdue_reminder_sent_at"
+	}
+
+	query := `
+		WITH latest AS (
+			SELECT DISTINCT ON (mother_id) id
+			FROM family_planning_visits
+			WHERE method_given OR discontinued
+			ORDER BY mother_id, visit_date DESC, created_at DESC
+		)
+		SELECT ` + familyPlanningVisitColumns + `
+		FROM family_planning_visits fv
+		JOIN latest l ON l.id = fv.id
+		WHERE fv.method_given
+			AND fv.next_due_date <= $1
+			AND ` + sentColumn + ` IS NULL
+		ORDER BY fv.next_due_date
+	`
+
+	return r.queryVisits(ctx, query, before)
+}
+
+// MarkReminderSent records a reminder for a visit, returning false if it was already sent
+func (r *FamilyPlanningRepository) MarkReminderSent(ctx context.Context, visitID uuid.UUID, kind model.FPReminderKind) (bool, error) {
+	query := `
+		UPDATE family_planning_visits
+		SET due_reminder_sent_at = NOW(), updated_at = NOW()
+		WHERE id = $1 AND due_reminder_sent_at IS NULL
+	`
+	if kind == model.FPReminderOverdue {
+		query = `
+			UPDATE family_planning_visits
+			SET overdue_reminder_sent_at = NOW(), updated_at = NOW()
+			WHERE id = $1 AND overdue_reminder_sent_at IS NULL
+		`
+	}
+
+	tag, err := database.GetQuerier(ctx, r.pool).Exec(ctx, query, visitID)
+	if err != nil {
+		return false, errorx.Wrap(err, errorx.InternalServerError, "failed to record family planning reminder")
+	}
+
+	return tag.RowsAffected() > 0, nil
+}
+
+// ClearReminderSent forgets a reminder recorded for a visit, so it is sent on the next run
+func (r *FamilyPlanningRepository) ClearReminderSent(ctx context.Context, visitID uuid.UUID, kind model.FPReminderKind) error {
+	query := `
+		UPDATE family_planning_visits
+		SET due_reminder_sent_at = NULL, updated_at = NOW()
+		WHERE id = $1
+	`
+	if kind == model.FPReminderOverdue {
+		query = `
+			UPDATE family_planning_visits
+			SET overdue_reminder_sent_at = NULL, updated_at = NOW()
+			WHERE id = $1
+		`
+	}
+
+	if _, err := database.GetQuerier(ctx, r.pool).Exec(ctx, query, visitID); err != nil {
+		return errorx.Wrap(err, errorx.InternalServerError, "failed to clear family planning reminder")
+	}
+
+	return nil
+}
+
+// GetFacilityCoverage counts deliveries and family planning services per facility over a
+// period. Postpartum counselling and uptake follow the women who delivered at the facility
+// in the period, wherever they were later seen; the other counts are the facility's visits.
+func (r *FamilyPlanningRepository) GetFacilityCoverage(
+	ctx context.Context,
+	from, to time.Time,
+	facilityID *uuid.UUID,
+	district string,
+) ([]*model.FamilyPlanningCoverage, error) {
+	query := `
+		WITH deliveries AS (
+			SELECT
+				d.facility_id,
+				COUNT(*) AS deliveries,
+				COUNT(*) FILTER (WHERE EXISTS (
+					SELECT 1 FROM family_planning_visits v
+					WHERE v.mother_id = d.mother_id
+						AND cardinality(v.topics) > 0
+						AND v.visit_date BETWEEN d.delivery_date::date AND d.delivery_date::date + $5::int
+				)) AS counselled,
+				COUNT(*) FILTER (WHERE EXISTS (
+					SELECT 1 FROM family_planning_visits v
+					WHERE v.mother_id = d.mother_id
+						AND v.method_given
+						AND v.visit_date BETWEEN d.delivery_date::date AND d.delivery_date::date + $6::int
+				)) AS uptake
+			FROM delivery_outcomes d
+			WHERE d.facility_id IS NOT NULL
+				AND d.delivery_date::date BETWEEN $1 AND $2
+			GROUP BY d.facility_id
+		),
+		visits AS (
+			SELECT
+				fv.facility_id,
+				COUNT(*) FILTER (WHERE cardinality(fv.topics) > 0) AS counselling,
+				COUNT(*) FILTER (WHERE fv.method_given AND NOT fv.resupply) AS new_acceptors,
+				COUNT(*) FILTER (WHERE fv.method_given AND fv.resupply) AS resupplies,
+				COUNT(*) FILTER (WHERE fv.discontinued) AS discontinuations
+			FROM family_planning_visits fv
+			WHERE fv.visit_date BETWEEN $1 AND $2
+			GROUP BY fv.facility_id
+		),
+		methods AS (
+			SELECT facility_id, jsonb_object_agg(method, given) AS methods_given
+			FROM (
+				SELECT fv.facility_id, fv.method, COUNT(*) AS given
+				FROM family_planning_visits fv
+				WHERE fv.method_given AND fv.visit_date BETWEEN $1 AND $2
+				GROUP BY fv.facility_id, fv.method
+			) m
+			GROUP BY facility_id
+		)
+		SELECT
+			f.id,
+			f.name,
+			f.district,
+			COALESCE(dl.deliveries, 0),
+			COALESCE(dl.counselled, 0),
+			COALESCE(dl.uptake, 0),
+			COALESCE(vs.counselling, 0),
+			COALESCE(vs.new_acceptors, 0),
+			COALESCE(vs.resupplies, 0),
+			COALESCE(vs.discontinuations, 0),
+			COALESCE(mg.methods_given, '{}'::jsonb)
+		FROM facilities f
+		LEFT JOIN deliveries dl ON dl.facility_id = f.id
+		LEFT JOIN visits vs ON vs.facility_id = f.id
+		LEFT JOIN methods mg ON mg.facility_id = f.id
+		WHERE (dl.facility_id IS NOT NULL OR vs.facility_id IS NOT NULL)
+			AND ($3::uuid IS NULL OR f.id = $3)
+			AND ($4::text = '' OR f.district = $4)
+		ORDER BY f.district, f.name
+	`
+
+	rows, err := database.GetQuerier(ctx, r.pool).Query(ctx, query,
+		from, to, facilityID, district,
+		model.FPPostpartumCounsellingDays, model.FPPostpartumUptakeDays,
+	)
+	if err != nil {
+		return nil, errorx.Wrap(err, errorx.InternalServerError, "failed to query family planning coverage")
+	}
+	defer rows.Close()
+
+	var coverage []*model.FamilyPlanningCoverage
+	for rows.Next() {
+		var facility model.FamilyPlanningCoverage
+		if err := rows.Scan(
+			&facility.FacilityID,
+			&facility.FacilityName,
+			&facility.District,
+			&facility.Deliveries,
+			&facility.CounselledPostpartum,
+			&facility.PostpartumUptake,
+			&facility.CounsellingVisits,
+			&facility.NewAcceptors,
+			&facility.Resupplies,
+			&facility.Discontinuations,
+			&facility.MethodsGiven,
+		); err != nil {
+			return nil, errorx.Wrap(err, errorx.InternalServerError, "failed to scan family planning coverage")
+		}
+		coverage = append(coverage, &facility)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, errorx.Wrap(err, errorx.InternalServerError, "error iterating over family planning coverage rows")
+	}
+
+	return coverage, nil
+}
+
+// queryVisits runs a family planning visit query and scans every row
+func (r *FamilyPlanningRepository) queryVisits(ctx context.Context, query string, args ...interface{}) ([]*model.FamilyPlanningVisit, error) {
+	rows, err := database.GetQuerier(ctx, r.pool).Query(ctx, query, args...)
+	if err != nil {
+		return nil, errorx.Wrap(err, errorx.InternalServerError, "failed to query family planning visits")
+	}
+	defer rows.Close()
+
+	var visits []*model.FamilyPlanningVisit
+	for rows.Next() {
+		visit, err := scanFamilyPlanningVisit(rows)
+		if err != nil {
+			return nil, err
+		}
+		visits = append(visits, visit)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, errorx.Wrap(err, errorx.InternalServerError, "error iterating over family planning visit rows")
+	}
+
+	return visits, nil
+}
@@ -0,0 +1,251 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/mamacare/services/internal/domain/model"
+	"github.com/mamacare/services/internal/domain/repository"
+	"github.com/mamacare/services/internal/infra/database"
+	"github.com/mamacare/services/pkg/errorx"
+	"github.com/mamacare/services/pkg/logger"
+)
+
+// groupSessionColumns is the column list shared by group session queries
+const groupSessionColumns = `
+	g.id,
+	g.facility_id,
+	g.facilitator_id,
+	g.title,
+	g.topic_content_id,
+	g.scheduled_time,
+	g.duration_minutes,
+	g.capacity,
+	g.status,
+	g.members,
+	g.notes,
+	g.created_at,
+	g.updated_at
+`
+
+// GroupSessionRepository implements repository.GroupSessionRepository interface
+type GroupSessionRepository struct {
+	pool   *pgxpool.Pool
+	logger logger.Logger
+}
+
+// NewGroupSessionRepository creates a new group session repository
+func NewGroupSessionRepository(pool *pgxpool.Pool, logger logger.Logger) repository.GroupSessionRepository {
+	return &GroupSessionRepository{
+		pool:   pool,
+		logger: logger,
+	}
+}
+
+// scanGroupSession scans a group session from a row
+func scanGroupSession(row pgx.Row) (*model.GroupSession, error) {
+	var session model.GroupSession
+	var membersJSON []byte
+	var notes *string
+
+	err := row.Scan(
+		&session.ID,
+		&session.FacilityID,
+		&session.FacilitatorID,
+		&session.Title,
+		&session.TopicContentID,
+		&session.ScheduledTime,
+		&session.DurationMinutes,
+		&session.Capacity,
+		&session.Status,
+		&membersJSON,
+		&notes,
+		&session.CreatedAt,
+		&session.UpdatedAt,
+	)
+
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, errorx.New(errorx.NotFound, "group session not found")
+		}
+		return nil, errorx.Wrap(err, errorx.InternalServerError, "failed to scan group session")
+	}
+
+	if notes != nil {
+		session.Notes = *notes
+	}
+
+	session.Members = []model.GroupSessionMember{}
+	if membersJSON != nil {
+		if err := json.Unmarshal(membersJSON, &session.Members); err != nil {
+			return nil, errorx.Wrap(err, errorx.InternalServerError, "failed to unmarshal group session members")
+		}
+	}
+
+	return &session, nil
+}
+
+// scanGroupSessions scans multiple group sessions from rows
+func scanGroupSessions(rows pgx.Rows) ([]*model.GroupSession, error) {
+	var sessions []*model.GroupSession
+
+	for rows.Next() {
+		session, err := scanGroupSession(rows)
+		if err != nil {
+			return nil, err
+		}
+		sessions = append(sessions, session)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, errorx.Wrap(err, errorx.InternalServerError, "error iterating over group session rows")
+	}
+
+	return sessions, nil
+}
+
+// Create creates a new group session
+func (r *GroupSessionRepository) Create(ctx context.Context, session *model.GroupSession) error {
+	membersJSON, err := json.Marshal(session.Members)
+	if err != nil {
+		return errorx.Wrap(err, errorx.InternalServerError, "failed to marshal group session members")
+	}
+
+	query := `
+		INSERT INTO group_sessions (
+			id, facility_id, facilitator_id, title, topic_content_id, scheduled_time,
+			duration_minutes, capacity, status, members, notes, created_at, updated_at
+		) VALUES (
+			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13
+		)
+	`
+
+	_, err = database.GetQuerier(ctx, r.pool).Exec(ctx, query,
+		session.ID,
+		session.FacilityID,
+		session.FacilitatorID,
+		session.Title,
+		session.TopicContentID,
+		session.ScheduledTime,
+		session.DurationMinutes,
+		session.Capacity,
+		session.Status,
+		membersJSON,
+		session.Notes,
+		session.CreatedAt,
+		session.UpdatedAt,
+	)
+
+	if err != nil {
+		return errorx.Wrap(err, errorx.InternalServerError, "failed to create group session")
+	}
+
+	return nil
+}
+
+// GetByID retrieves a group session by its ID
+func (r *GroupSessionRepository) GetByID(ctx context.Context, id uuid.UUID) (*model.GroupSession, error) {
+	query := `SELECT ` + groupSessionColumns + ` FROM group_sessions g WHERE g.id = $1`
+
+	row := database.GetQuerier(ctx, r.pool).QueryRow(ctx, query, id)
+	return scanGroupSession(row)
+}
+
+// GetByIDForUpdate retrieves a group session and locks it until the surrounding transaction ends
+func (r *GroupSessionRepository) GetByIDForUpdate(ctx context.Context, id uuid.UUID) (*model.GroupSession, error) {
+	query := `SELECT ` + groupSessionColumns + ` FROM group_sessions g WHERE g.id = $1 FOR UPDATE`
+
+	row := database.GetQuerier(ctx, r.pool).QueryRow(ctx, query, id)
+	return scanGroupSession(row)
+}
+
+// GetByFacilityID retrieves the group sessions at a facility scheduled in the range
+func (r *GroupSessionRepository) GetByFacilityID(ctx context.Context, facilityID uuid.UUID, start, end time.Time) ([]*model.GroupSession, error) {
+	query := `SELECT ` + groupSessionColumns + `
+		FROM group_sessions g
+		WHERE g.facility_id = $1 AND g.scheduled_time >= $2 AND g.scheduled_time < $3
+		ORDER BY g.scheduled_time ASC
+	`
+
+	rows, err := database.GetQuerier(ctx, r.pool).Query(ctx, query, facilityID, start, end)
+	if err != nil {
+		return nil, errorx.Wrap(err, errorx.InternalServerError, "failed to query group sessions by facility")
+	}
+	defer rows.Close()
+
+	return scanGroupSessions(rows)
+}
+
+// GetByMotherID retrieves the group sessions a mother is enrolled in, most recent first
+func (r *GroupSessionRepository) GetByMotherID(ctx context.Context, motherID uuid.UUID) ([]*model.GroupSession, error) {
+	member, err := json.Marshal([]map[string]string{{"mother_id": motherID.String()}})
+	if err != nil {
+		return nil, errorx.Wrap(err, errorx.InternalServerError, "failed to marshal member filter")
+	}
+
+	query := `SELECT ` + groupSessionColumns + `
+		FROM group_sessions g
+		WHERE g.members @> $1::jsonb
+		ORDER BY g.scheduled_time DESC
+	`
+
+	rows, err := database.GetQuerier(ctx, r.pool).Query(ctx, query, member)
+	if err != nil {
+		return nil, errorx.Wrap(err, errorx.InternalServerError, "failed to query group sessions by mother")
+	}
+	defer rows.Close()
+
+	return scanGroupSessions(rows)
+}
+
+// Update updates an existing group session, including its roster
+func (r *GroupSessionRepository) Update(ctx context.Context, session *model.GroupSession) error {
+	session.UpdatedAt = time.Now()
+
+	membersJSON, err := json.Marshal(session.Members)
+	if err != nil {
+		return errorx.Wrap(err, errorx.InternalServerError, "failed to marshal group session members")
+	}
+
+	query := `
+		UPDATE group_sessions SET
+			facilitator_id = $2,
+			title = $3,
+			topic_content_id = $4,
+			scheduled_time = $5,
+			duration_minutes = $6,
+			capacity = $7,
+			status = $8,
+			members = $9,
+			notes = $10,
+			updated_at = $11
+		WHERE id = $1
+	`
+
+	tag, err := database.GetQuerier(ctx, r.pool).Exec(ctx, query,
+		session.ID,
+		session.FacilitatorID,
+		session.Title,
+		session.TopicContentID,
+		session.ScheduledTime,
+		session.DurationMinutes,
+		session.Capacity,
+		session.Status,
+		membersJSON,
+		session.Notes,
+		session.UpdatedAt,
+	)
+
+	if err != nil {
+		return errorx.Wrap(err, errorx.InternalServerError, "failed to update group session")
+	}
+	if tag.RowsAffected() == 0 {
+		return errorx.New(errorx.NotFound, "group session not found")
+	}
+
+	return nil
+}
@@ -0,0 +1,187 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/mamacare/services/internal/domain/model"
+	"github.com/mamacare/services/internal/domain/repository"
+	"github.com/mamacare/services/internal/infra/database"
+	"github.com/mamacare/services/pkg/errorx"
+	"github.com/mamacare/services/pkg/logger"
+)
+
+// growthMeasurementColumns is the column list shared by growth measurement queries
+const growthMeasurementColumns = `
+	gm.id,
+	gm.child_id,
+	gm.measured_at,
+	gm.weight_grams,
+	gm.height_cm::float8,
+	gm.measured_recumbent,
+	gm.head_circumference_cm::float8,
+	gm.mid_upper_arm_circumference_cm::float8,
+	gm.weight_for_age_z::float8,
+	gm.height_for_age_z::float8,
+	gm.weight_for_height_z::float8,
+	gm.head_circumference_for_age_z::float8,
+	gm.muac_for_age_z::float8,
+	gm.implausible_z,
+	gm.growth_status,
+	gm.measured_by_user_id,
+	gm.measured_by_name,
+	gm.measured_at_facility_id,
+	gm.notes,
+	gm.created_at,
+	gm.updated_at
+`
+
+// GrowthMeasurementRepository implements repository.GrowthMeasurementRepository interface
+type GrowthMeasurementRepository struct {
+	pool   *pgxpool.Pool
+	logger logger.Logger
+}
+
+// NewGrowthMeasurementRepository creates a new growth measurement repository
+func NewGrowthMeasurementRepository(pool *pgxpool.Pool, logger logger.Logger) repository.GrowthMeasurementRepository {
+	return &GrowthMeasurementRepository{
+		pool:   pool,
+		logger: logger,
+	}
+}
+
+// scanGrowthMeasurement scans a growth measurement from a row
+func scanGrowthMeasurement(row pgx.Row) (*model.GrowthMeasurement, error) {
+	var measurement model.GrowthMeasurement
+	var growthStatus string
+	var measuredByName, notes *string
+
+	err := row.Scan(
+		&measurement.ID,
+		&measurement.ChildID,
+		&measurement.MeasuredAt,
+		&measurement.WeightGrams,
+		&measurement.HeightCm,
+		&measurement.MeasuredRecumbent,
+		&measurement.HeadCircumferenceCm,
+		&measurement.MUACCm,
+		&measurement.WeightForAgeZ,
+		&measurement.HeightForAgeZ,
+		&measurement.WeightForHeightZ,
+		&measurement.HeadCircumferenceForAgeZ,
+		&measurement.MUACForAgeZ,
+		&measurement.ImplausibleZ,
+		&growthStatus,
+		&measurement.MeasuredByID,
+		&measuredByName,
+		&measurement.FacilityID,
+		&notes,
+		&measurement.CreatedAt,
+		&measurement.UpdatedAt,
+	)
+
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, errorx.New(errorx.NotFound, "growth measurement not found")
+		}
+		return nil, errorx.Wrap(err, errorx.InternalServerError, "failed to scan growth measurement")
+	}
+
+	measurement.GrowthStatus = model.GrowthStatus(growthStatus)
+	measurement.MeasuredByName = stringValue(measuredByName)
+	measurement.Notes = stringValue(notes)
+
+	return &measurement, nil
+}
+
+// Create stores a growth measurement
+func (r *GrowthMeasurementRepository) Create(ctx context.Context, measurement *model.GrowthMeasurement) error {
+	query := `
+		INSERT INTO growth_measurements (
+			id, child_id, measured_at, weight_grams, height_cm, measured_recumbent,
+			head_circumference_cm, mid_upper_arm_circumference_cm,
+			weight_for_age_z, height_for_age_z, weight_for_height_z,
+			head_circumference_for_age_z, muac_for_age_z, implausible_z, growth_status,
+			measured_by_user_id, measured_by_name, measured_at_facility_id, notes,
+			created_at, updated_at
+		) VALUES (
+			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11,
+			$12, $13, $14, $15, $16, $17, $18, $19, $20, $21
+		)
+	`
+
+	_, err := database.GetQuerier(ctx, r.pool).Exec(ctx, query,
+		measurement.ID,
+		measurement.ChildID,
+		measurement.MeasuredAt,
+		measurement.WeightGrams,
+		measurement.HeightCm,
+		measurement.MeasuredRecumbent,
+		measurement.HeadCircumferenceCm,
+		measurement.MUACCm,
+		measurement.WeightForAgeZ,
+		measurement.HeightForAgeZ,
+		measurement.WeightForHeightZ,
+		measurement.HeadCircumferenceForAgeZ,
+		measurement.MUACForAgeZ,
+		measurement.ImplausibleZ,
+		string(measurement.GrowthStatus),
+		measurement.MeasuredByID,
+		nullableString(measurement.MeasuredByName),
+		measurement.FacilityID,
+		nullableString(measurement.Notes),
+		measurement.CreatedAt,
+		measurement.UpdatedAt,
+	)
+
+	if err != nil {
+		return errorx.Wrap(err, errorx.InternalServerError, "failed to create growth measurement")
+	}
+
+	return nil
+}
+
+// GetByChildID retrieves a child's measurements, oldest first
+func (r *GrowthMeasurementRepository) GetByChildID(ctx context.Context, childID uuid.UUID) ([]*model.GrowthMeasurement, error) {
+	query := `SELECT ` + growthMeasurementColumns + `
+		FROM growth_measurements gm
+		WHERE gm.child_id = $1
+		ORDER BY gm.measured_at ASC, gm.created_at ASC
+	`
+
+	rows, err := database.GetQuerier(ctx, r.pool).Query(ctx, query, childID)
+	if err != nil {
+		return nil, errorx.Wrap(err, errorx.InternalServerError, "failed to query growth measurements by child")
+	}
+	defer rows.Close()
+
+	var measurements []*model.GrowthMeasurement
+	for rows.Next() {
+		measurement, err := scanGrowthMeasurement(rows)
+		if err != nil {
+			return nil, err
+		}
+		measurements = append(measurements, measurement)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, errorx.Wrap(err, errorx.InternalServerError, "error iterating over growth measurement rows")
+	}
+
+	return measurements, nil
+}
+
+// GetLatestByChildID retrieves a child's most recent measurement
+func (r *GrowthMeasurementRepository) GetLatestByChildID(ctx context.Context, childID uuid.UUID) (*model.GrowthMeasurement, error) {
+	query := `SELECT ` + growthMeasurementColumns + `
+		FROM growth_measurements gm
+		WHERE gm.child_id = $1
+		ORDER BY gm.measured_at DESC, gm.created_at DESC
+		LIMIT 1
+	`
+
+	row := database.GetQuerier(ctx, r.pool).QueryRow(ctx, query, childID)
+	return scanGrowthMeasurement(row)
+}
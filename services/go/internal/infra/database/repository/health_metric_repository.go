@@ -34,6 +34,9 @@ func scanHealthMetric(row pgx.Row) (*model.HealthMetric, error) {
 	var recordedByID *uuid.UUID
 	var bloodPressureSystolic, bloodPressureDiastolic, fetalHeartRate, fetalMovement *float64
 	var bloodSugar, hemoglobinLevel, ironLevel, weight *float64
+	var urineProtein *model.UrineProtein
+	var bloodSugarTiming *model.GlucoseTiming
+	var fundalHeightCm, estimatedFetalWeight, muacCm *float64
 
 	err := row.Scan(
 		&metric.ID,
@@ -49,6 +52,11 @@ func scanHealthMetric(row pgx.Row) (*model.HealthMetric, error) {
 		&hemoglobinLevel,
 		&ironLevel,
 		&weight,
+		&urineProtein,
+		&bloodSugarTiming,
+		&fundalHeightCm,
+		&estimatedFetalWeight,
+		&muacCm,
 		&metric.Notes,
 		&metric.CreatedAt,
 		&metric.UpdatedAt,
@@ -79,6 +87,11 @@ func scanHealthMetric(row pgx.Row) (*model.HealthMetric, error) {
 	metric.VitalSigns.HemoglobinLevel = hemoglobinLevel
 	metric.VitalSigns.IronLevel = ironLevel
 	metric.VitalSigns.Weight = weight
+	metric.VitalSigns.UrineProtein = urineProtein
+	metric.VitalSigns.BloodSugarTiming = bloodSugarTiming
+	metric.VitalSigns.FundalHeightCm = fundalHeightCm
+	metric.VitalSigns.EstimatedFetalWeight = estimatedFetalWeight
+	metric.VitalSigns.MUACCm = muacCm
 
 	return &metric, nil
 }
@@ -100,6 +113,11 @@ func (r *HealthMetricRepository) FindByID(ctx context.Context, id uuid.UUID) (*m
 			h.hemoglobin_level, 
 			h.iron_level, 
 			h.weight, 
+			h.urine_protein, 
+			h.blood_sugar_timing, 
+			h.fundal_height_cm, 
+			h.estimated_fetal_weight, 
+			h.muac_cm, 
 			h.notes, 
 			h.created_at, 
 			h.updated_at
@@ -128,6 +146,11 @@ func (r *HealthMetricRepository) FindByMother(ctx context.Context, motherID uuid
 			h.hemoglobin_level, 
 			h.iron_level, 
 			h.weight, 
+			h.urine_protein, 
+			h.blood_sugar_timing, 
+			h.fundal_height_cm, 
+			h.estimated_fetal_weight, 
+			h.muac_cm, 
 			h.notes, 
 			h.created_at, 
 			h.updated_at
@@ -162,6 +185,11 @@ func (r *HealthMetricRepository) FindByVisit(ctx context.Context, visitID uuid.U
 			h.hemoglobin_level, 
 			h.iron_level, 
 			h.weight, 
+			h.urine_protein, 
+			h.blood_sugar_timing, 
+			h.fundal_height_cm, 
+			h.estimated_fetal_weight, 
+			h.muac_cm, 
 			h.notes, 
 			h.created_at, 
 			h.updated_at
@@ -196,6 +224,11 @@ func (r *HealthMetricRepository) FindByDateRange(ctx context.Context, motherID u
 			h.hemoglobin_level, 
 			h.iron_level, 
 			h.weight, 
+			h.urine_protein, 
+			h.blood_sugar_timing, 
+			h.fundal_height_cm, 
+			h.estimated_fetal_weight, 
+			h.muac_cm, 
 			h.notes, 
 			h.created_at, 
 			h.updated_at
@@ -230,6 +263,11 @@ func (r *HealthMetricRepository) FindLatest(ctx context.Context, motherID uuid.U
 			h.hemoglobin_level, 
 			h.iron_level, 
 			h.weight, 
+			h.urine_protein, 
+			h.blood_sugar_timing, 
+			h.fundal_height_cm, 
+			h.estimated_fetal_weight, 
+			h.muac_cm, 
 			h.notes, 
 			h.created_at, 
 			h.updated_at
@@ -260,6 +298,11 @@ func (r *HealthMetricRepository) FindAbnormalBloodPressure(ctx context.Context,
 			h.hemoglobin_level, 
 			h.iron_level, 
 			h.weight, 
+			h.urine_protein, 
+			h.blood_sugar_timing, 
+			h.fundal_height_cm, 
+			h.estimated_fetal_weight, 
+			h.muac_cm, 
 			h.notes, 
 			h.created_at, 
 			h.updated_at
@@ -287,7 +330,7 @@ func (r *HealthMetricRepository) GetWeightTrend(ctx context.Context, motherID uu
 		FROM health_metrics h
 		WHERE h.mother_id = $1 
 		AND h.weight IS NOT NULL
-		AND h.recorded_at > NOW() - INTERVAL '$2 months'
+		AND h.recorded_at > NOW() - make_interval(months => $2)
 		ORDER BY h.recorded_at
 	`
 
@@ -336,9 +379,10 @@ func (r *HealthMetricRepository) Save(ctx context.Context, metric *model.HealthM
 		INSERT INTO health_metrics (
 			id, mother_id, visit_id, recorded_by, recorded_at, 
 			blood_pressure_systolic, blood_pressure_diastolic, fetal_heart_rate, fetal_movement,
-			blood_sugar, hemoglobin_level, iron_level, weight, notes, created_at, updated_at
+			blood_sugar, hemoglobin_level, iron_level, weight, urine_protein,
+			blood_sugar_timing, fundal_height_cm, estimated_fetal_weight, muac_cm, notes, created_at, updated_at
 		) VALUES (
-			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16
+			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21
 		) ON CONFLICT (id) DO UPDATE SET
 			mother_id = EXCLUDED.mother_id,
 			visit_id = EXCLUDED.visit_id,
@@ -352,6 +396,11 @@ func (r *HealthMetricRepository) Save(ctx context.Context, metric *model.HealthM
 			hemoglobin_level = EXCLUDED.hemoglobin_level,
 			iron_level = EXCLUDED.iron_level,
 			weight = EXCLUDED.weight,
+			urine_protein = EXCLUDED.urine_protein,
+			blood_sugar_timing = EXCLUDED.blood_sugar_timing,
+			fundal_height_cm = EXCLUDED.fundal_height_cm,
+			estimated_fetal_weight = EXCLUDED.estimated_fetal_weight,
+			muac_cm = EXCLUDED.muac_cm,
 			notes = EXCLUDED.notes,
 			updated_at = EXCLUDED.updated_at
 	`
@@ -370,6 +419,11 @@ func (r *HealthMetricRepository) Save(ctx context.Context, metric *model.HealthM
 		metric.VitalSigns.HemoglobinLevel,
 		metric.VitalSigns.IronLevel,
 		metric.VitalSigns.Weight,
+		metric.VitalSigns.UrineProtein,
+		metric.VitalSigns.BloodSugarTiming,
+		metric.VitalSigns.FundalHeightCm,
+		metric.VitalSigns.EstimatedFetalWeight,
+		metric.VitalSigns.MUACCm,
 		metric.Notes,
 		metric.CreatedAt,
 		metric.UpdatedAt,
@@ -461,6 +515,8 @@ func scanHealthMetrics(rows pgx.Rows) ([]*model.HealthMetric, error) {
 		var recordedByID *uuid.UUID
 		var bloodPressureSystolic, bloodPressureDiastolic, fetalHeartRate, fetalMovement *float64
 		var bloodSugar, hemoglobinLevel, ironLevel, weight *float64
+		var urineProtein *model.UrineProtein
+		var bloodSugarTiming *model.GlucoseTiming
 
 		err := rows.Scan(
 			&metric.ID,
@@ -476,6 +532,10 @@ func scanHealthMetrics(rows pgx.Rows) ([]*model.HealthMetric, error) {
 			&hemoglobinLevel,
 			&ironLevel,
 			&weight,
+			&urineProtein,
+			&bloodSugarTiming,
+		&urineProtein,
+		&bloodSugarTiming,
 			&metric.Notes,
 			&metric.CreatedAt,
 			&metric.UpdatedAt,
@@ -503,6 +563,8 @@ func scanHealthMetrics(rows pgx.Rows) ([]*model.HealthMetric, error) {
 		metric.VitalSigns.HemoglobinLevel = hemoglobinLevel
 		metric.VitalSigns.IronLevel = ironLevel
 		metric.VitalSigns.Weight = weight
+		metric.VitalSigns.UrineProtein = urineProtein
+		metric.VitalSigns.BloodSugarTiming = bloodSugarTiming
 
 		metrics = append(metrics, &metric)
 	}
@@ -0,0 +1,255 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/mamacare/services/internal/domain/model"
+	"github.com/mamacare/services/internal/domain/repository"
+	"github.com/mamacare/services/internal/infra/database"
+	"github.com/mamacare/services/pkg/errorx"
+	"github.com/mamacare/services/pkg/logger"
+)
+
+// vaccineScheduleColumns is the column list shared by vaccine schedule queries
+const vaccineScheduleColumns = `
+	vs.id,
+	vs.vaccine_name,
+	vs.vaccine_dose,
+	vs.age_days,
+	vs.window_start_days,
+	vs.window_end_days,
+	vs.min_interval_days,
+	vs.max_age_days,
+	vs.disease_target,
+	vs.is_required,
+	vs.is_active
+`
+
+// immunizationRecordColumns is the column list shared by immunization record queries
+const immunizationRecordColumns = `
+	ir.id,
+	ir.child_id,
+	ir.vaccine_name,
+	ir.vaccine_dose,
+	ir.administered_date,
+	ir.administered_by_user_id,
+	ir.administered_by_name,
+	ir.administered_at_facility_id,
+	ir.batch_number,
+	ir.manufacturer,
+	ir.adverse_reactions,
+	ir.notes,
+	ir.is_verified,
+	ir.is_valid,
+	ir.invalid_reason,
+	ir.created_at,
+	ir.updated_at
+`
+
+// ImmunizationRepository implements repository.ImmunizationRepository interface
+type ImmunizationRepository struct {
+	pool   *pgxpool.Pool
+	logger logger.Logger
+}
+
+// NewImmunizationRepository creates a new immunization repository
+func NewImmunizationRepository(pool *pgxpool.Pool, logger logger.Logger) repository.ImmunizationRepository {
+	return &ImmunizationRepository{
+		pool:   pool,
+		logger: logger,
+	}
+}
+
+// scanVaccineSchedule scans a vaccine schedule entry from a row
+func scanVaccineSchedule(row pgx.Row) (*model.VaccineSchedule, error) {
+	var schedule model.VaccineSchedule
+
+	err := row.Scan(
+		&schedule.ID,
+		&schedule.VaccineName,
+		&schedule.Dose,
+		&schedule.AgeDays,
+		&schedule.WindowStartDays,
+		&schedule.WindowEndDays,
+		&schedule.MinIntervalDays,
+		&schedule.MaxAgeDays,
+		&schedule.DiseaseTarget,
+		&schedule.IsRequired,
+		&schedule.IsActive,
+	)
+
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, errorx.New(errorx.NotFound, "vaccine schedule not found")
+		}
+		return nil, errorx.Wrap(err, errorx.InternalServerError, "failed to scan vaccine schedule")
+	}
+
+	return &schedule, nil
+}
+
+// scanImmunizationRecord scans an immunization record from a row
+func scanImmunizationRecord(row pgx.Row) (*model.ImmunizationRecord, error) {
+	var record model.ImmunizationRecord
+	var administeredByName, batchNumber, manufacturer, adverseReactions, notes, invalidReason *string
+
+	err := row.Scan(
+		&record.ID,
+		&record.ChildID,
+		&record.VaccineName,
+		&record.Dose,
+		&record.AdministeredDate,
+		&record.AdministeredByID,
+		&administeredByName,
+		&record.FacilityID,
+		&batchNumber,
+		&manufacturer,
+		&adverseReactions,
+		&notes,
+		&record.IsVerified,
+		&record.IsValid,
+		&invalidReason,
+		&record.CreatedAt,
+		&record.UpdatedAt,
+	)
+
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, errorx.New(errorx.NotFound, "immunization record not found")
+		}
+		return nil, errorx.Wrap(err, errorx.InternalServerError, "failed to scan immunization record")
+	}
+
+	record.AdministeredByName = stringValue(administeredByName)
+	record.BatchNumber = stringValue(batchNumber)
+	record.Manufacturer = stringValue(manufacturer)
+	record.AdverseReactions = stringValue(adverseReactions)
+	record.Notes = stringValue(notes)
+	record.InvalidReason = stringValue(invalidReason)
+
+	return &record, nil
+}
+
+// GetSchedules retrieves the active vaccine schedule, youngest age first
+func (r *ImmunizationRepository) GetSchedules(ctx context.Context) ([]*model.VaccineSchedule, error) {
+	query := `SELECT ` + vaccineScheduleColumns + `
+		FROM vaccine_schedules vs
+		WHERE vs.is_active
+		ORDER BY vs.age_days ASC, vs.vaccine_name ASC, vs.vaccine_dose ASC
+	`
+
+	rows, err := database.GetQuerier(ctx, r.pool).Query(ctx, query)
+	if err != nil {
+		return nil, errorx.Wrap(err, errorx.InternalServerError, "failed to query vaccine schedules")
+	}
+	defer rows.Close()
+
+	var schedules []*model.VaccineSchedule
+	for rows.Next() {
+		schedule, err := scanVaccineSchedule(rows)
+		if err != nil {
+			return nil, err
+		}
+		schedules = append(schedules, schedule)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, errorx.Wrap(err, errorx.InternalServerError, "error iterating over vaccine schedule rows")
+	}
+
+	return schedules, nil
+}
+
+// CreateRecord stores a vaccine dose given to a child
+func (r *ImmunizationRepository) CreateRecord(ctx context.Context, record *model.ImmunizationRecord) error {
+	query := `
+		INSERT INTO immunization_records (
+			id, child_id, vaccine_name, vaccine_dose, administered_date,
+			administered_by_user_id, administered_by_name, administered_at_facility_id,
+			batch_number, manufacturer, adverse_reactions, notes,
+			is_verified, is_valid, invalid_reason, created_at, updated_at
+		) VALUES (
+			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17
+		)
+	`
+
+	_, err := database.GetQuerier(ctx, r.pool).Exec(ctx, query,
+		record.ID,
+		record.ChildID,
+		record.VaccineName,
+		record.Dose,
+		record.AdministeredDate,
+		record.AdministeredByID,
+		nullableString(record.AdministeredByName),
+		record.FacilityID,
+		nullableString(record.BatchNumber),
+		nullableString(record.Manufacturer),
+		nullableString(record.AdverseReactions),
+		nullableString(record.Notes),
+		record.IsVerified,
+		record.IsValid,
+		nullableString(record.InvalidReason),
+		record.CreatedAt,
+		record.UpdatedAt,
+	)
+
+	if err != nil {
+		return errorx.Wrap(err, errorx.InternalServerError, "failed to create immunization record")
+	}
+
+	return nil
+}
+
+// GetRecordsByChildID retrieves a child's doses, oldest first
+func (r *ImmunizationRepository) GetRecordsByChildID(ctx context.Context, childID uuid.UUID) ([]*model.ImmunizationRecord, error) {
+	query := `SELECT ` + immunizationRecordColumns + `
+		FROM immunization_records ir
+		WHERE ir.child_id = $1
+		ORDER BY ir.administered_date ASC, ir.created_at ASC
+	`
+
+	rows, err := database.GetQuerier(ctx, r.pool).Query(ctx, query, childID)
+	if err != nil {
+		return nil, errorx.Wrap(err, errorx.InternalServerError, "failed to query immunization records by child")
+	}
+	defer rows.Close()
+
+	var records []*model.ImmunizationRecord
+	for rows.Next() {
+		record, err := scanImmunizationRecord(rows)
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, record)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, errorx.Wrap(err, errorx.InternalServerError, "error iterating over immunization record rows")
+	}
+
+	return records, nil
+}
+
+// MarkReminderSent records a reminder for a child's dose, returning false if it was already sent
+func (r *ImmunizationRepository) MarkReminderSent(
+	ctx context.Context,
+	childID uuid.UUID,
+	vaccineName, dose string,
+	kind model.VaccineReminderKind,
+) (bool, error) {
+	query := `
+		INSERT INTO immunization_reminders (child_id, vaccine_name, vaccine_dose, kind)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT DO NOTHING
+	`
+
+	tag, err := database.GetQuerier(ctx, r.pool).Exec(ctx, query, childID, vaccineName, dose, kind)
+	if err != nil {
+		return false, errorx.Wrap(err, errorx.InternalServerError, "failed to record immunization reminder")
+	}
+
+	return tag.RowsAffected() > 0, nil
+}
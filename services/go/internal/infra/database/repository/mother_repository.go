@@ -43,6 +43,13 @@ func scanMother(row pgx.Row) (*model.Mother, error) {
 		&healthConditions,
 		&pregnancyHistoryJSON,
 		&mother.RiskLevel,
+		&mother.PregnancyStage,
+		&mother.DeliveryDate,
+		&mother.DateOfBirth,
+		&mother.HeightCm,
+		&mother.PrePregnancyWeight,
+		&mother.LMP,
+		&mother.DatingMethod,
 		&mother.CreatedAt,
 		&mother.UpdatedAt,
 	)
@@ -78,6 +85,13 @@ func (r *MotherRepository) FindByID(ctx context.Context, id uuid.UUID) (*model.M
 			m.health_conditions, 
 			m.pregnancy_history, 
 			m.risk_level, 
+			m.pregnancy_stage, 
+			m.delivery_date, 
+			m.date_of_birth, 
+			m.height_cm, 
+			m.pre_pregnancy_weight_kg, 
+			m.lmp, 
+			m.dating_method, 
 			m.created_at, 
 			m.updated_at
 		FROM mothers m
@@ -99,6 +113,13 @@ func (r *MotherRepository) FindByUserID(ctx context.Context, userID uuid.UUID) (
 			m.health_conditions, 
 			m.pregnancy_history, 
 			m.risk_level, 
+			m.pregnancy_stage, 
+			m.delivery_date, 
+			m.date_of_birth, 
+			m.height_cm, 
+			m.pre_pregnancy_weight_kg, 
+			m.lmp, 
+			m.dating_method, 
 			m.created_at, 
 			m.updated_at
 		FROM mothers m
@@ -120,6 +141,13 @@ func (r *MotherRepository) FindAll(ctx context.Context) ([]*model.Mother, error)
 			m.health_conditions, 
 			m.pregnancy_history, 
 			m.risk_level, 
+			m.pregnancy_stage, 
+			m.delivery_date, 
+			m.date_of_birth, 
+			m.height_cm, 
+			m.pre_pregnancy_weight_kg, 
+			m.lmp, 
+			m.dating_method, 
 			m.created_at, 
 			m.updated_at
 		FROM mothers m
@@ -146,6 +174,13 @@ func (r *MotherRepository) FindByRiskLevel(ctx context.Context, riskLevel model.
 			m.health_conditions, 
 			m.pregnancy_history, 
 			m.risk_level, 
+			m.pregnancy_stage, 
+			m.delivery_date, 
+			m.date_of_birth, 
+			m.height_cm, 
+			m.pre_pregnancy_weight_kg, 
+			m.lmp, 
+			m.dating_method, 
 			m.created_at, 
 			m.updated_at
 		FROM mothers m
@@ -173,6 +208,13 @@ func (r *MotherRepository) FindByDueDate(ctx context.Context, startDate, endDate
 			m.health_conditions, 
 			m.pregnancy_history, 
 			m.risk_level, 
+			m.pregnancy_stage, 
+			m.delivery_date, 
+			m.date_of_birth, 
+			m.height_cm, 
+			m.pre_pregnancy_weight_kg, 
+			m.lmp, 
+			m.dating_method, 
 			m.created_at, 
 			m.updated_at
 		FROM mothers m
@@ -200,6 +242,13 @@ func (r *MotherRepository) FindByDistrict(ctx context.Context, district string)
 			m.health_conditions, 
 			m.pregnancy_history, 
 			m.risk_level, 
+			m.pregnancy_stage, 
+			m.delivery_date, 
+			m.date_of_birth, 
+			m.height_cm, 
+			m.pre_pregnancy_weight_kg, 
+			m.lmp, 
+			m.dating_method, 
 			m.created_at, 
 			m.updated_at
 		FROM mothers m
@@ -217,6 +266,42 @@ func (r *MotherRepository) FindByDistrict(ctx context.Context, district string)
 	return scanMothers(rows)
 }
 
+// FindByDistrictAndDueDate retrieves mothers in a district with EDDs in a date range
+func (r *MotherRepository) FindByDistrictAndDueDate(ctx context.Context, district string, startDate, endDate time.Time) ([]*model.Mother, error) {
+	query := `
+		SELECT 
+			m.id, 
+			m.user_id, 
+			m.expected_delivery_date, 
+			m.blood_type, 
+			m.health_conditions, 
+			m.pregnancy_history, 
+			m.risk_level, 
+			m.pregnancy_stage, 
+			m.delivery_date, 
+			m.date_of_birth, 
+			m.height_cm, 
+			m.pre_pregnancy_weight_kg, 
+			m.lmp, 
+			m.dating_method, 
+			m.created_at, 
+			m.updated_at
+		FROM mothers m
+		JOIN users u ON m.user_id = u.id
+		WHERE u.district = $1
+		AND m.expected_delivery_date BETWEEN $2 AND $3
+		ORDER BY m.expected_delivery_date
+	`
+
+	rows, err := database.GetQuerier(ctx, r.pool).Query(ctx, query, district, startDate, endDate)
+	if err != nil {
+		return nil, errorx.Wrap(err, errorx.InternalServerError, "failed to query mothers by district and delivery date range")
+	}
+	defer rows.Close()
+
+	return scanMothers(rows)
+}
+
 // FindByHealthcareProvider retrieves mothers assigned to a healthcare provider
 func (r *MotherRepository) FindByHealthcareProvider(ctx context.Context, providerID uuid.UUID) ([]*model.Mother, error) {
 	query := `
@@ -228,6 +313,13 @@ func (r *MotherRepository) FindByHealthcareProvider(ctx context.Context, provide
 			m.health_conditions, 
 			m.pregnancy_history, 
 			m.risk_level, 
+			m.pregnancy_stage, 
+			m.delivery_date, 
+			m.date_of_birth, 
+			m.height_cm, 
+			m.pre_pregnancy_weight_kg, 
+			m.lmp, 
+			m.dating_method, 
 			m.created_at, 
 			m.updated_at
 		FROM mothers m
@@ -260,9 +352,10 @@ func (r *MotherRepository) Save(ctx context.Context, mother *model.Mother) error
 	query := `
 		INSERT INTO mothers (
 			id, user_id, expected_delivery_date, blood_type, health_conditions,
-			pregnancy_history, risk_level, created_at, updated_at
+			pregnancy_history, risk_level, pregnancy_stage, delivery_date, date_of_birth,
+			height_cm, pre_pregnancy_weight_kg, lmp, dating_method, created_at, updated_at
 		) VALUES (
-			$1, $2, $3, $4, $5, $6, $7, $8, $9
+			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16
 		) ON CONFLICT (id) DO UPDATE SET
 			user_id = EXCLUDED.user_id,
 			expected_delivery_date = EXCLUDED.expected_delivery_date,
@@ -270,6 +363,13 @@ func (r *MotherRepository) Save(ctx context.Context, mother *model.Mother) error
 			health_conditions = EXCLUDED.health_conditions,
 			pregnancy_history = EXCLUDED.pregnancy_history,
 			risk_level = EXCLUDED.risk_level,
+			pregnancy_stage = EXCLUDED.pregnancy_stage,
+			delivery_date = EXCLUDED.delivery_date,
+			date_of_birth = EXCLUDED.date_of_birth,
+			height_cm = EXCLUDED.height_cm,
+			pre_pregnancy_weight_kg = EXCLUDED.pre_pregnancy_weight_kg,
+			lmp = EXCLUDED.lmp,
+			dating_method = EXCLUDED.dating_method,
 			updated_at = EXCLUDED.updated_at
 	`
 
@@ -281,6 +381,13 @@ func (r *MotherRepository) Save(ctx context.Context, mother *model.Mother) error
 		mother.HealthConditions,
 		pregnancyHistoryJSON,
 		mother.RiskLevel,
+		mother.PregnancyStage,
+		mother.DeliveryDate,
+		mother.DateOfBirth,
+		mother.HeightCm,
+		mother.PrePregnancyWeight,
+		mother.LMP,
+		mother.DatingMethod,
 		mother.CreatedAt,
 		mother.UpdatedAt,
 	)
@@ -388,6 +495,13 @@ func scanMothers(rows pgx.Rows) ([]*model.Mother, error) {
 			&healthConditions,
 			&pregnancyHistoryJSON,
 			&mother.RiskLevel,
+			&mother.PregnancyStage,
+			&mother.DeliveryDate,
+			&mother.DateOfBirth,
+			&mother.HeightCm,
+			&mother.PrePregnancyWeight,
+			&mother.LMP,
+			&mother.DatingMethod,
 			&mother.CreatedAt,
 			&mother.UpdatedAt,
 		)
@@ -0,0 +1,479 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/mamacare/services/internal/domain/model"
+	"github.com/mamacare/services/internal/domain/repository"
+	"github.com/mamacare/services/internal/infra/database"
+	"github.com/mamacare/services/pkg/errorx"
+	"github.com/mamacare/services/pkg/logger"
+)
+
+// neonatalRecordColumns is the column list shared by neonatal record queries
+const neonatalRecordColumns = `
+	nr.id,
+	nr.child_id,
+	nr.apgar_1_min,
+	nr.apgar_5_min,
+	nr.apgar_10_min,
+	nr.birth_weight_grams,
+	nr.birth_weight_category,
+	nr.breastfeeding_initiated_at,
+	nr.early_breastfeeding,
+	nr.cord_care,
+	nr.kmc_started_at,
+	nr.kmc_ended_at,
+	nr.kmc_end_reason,
+	nr.recorded_by_id,
+	nr.notes,
+	nr.created_at,
+	nr.updated_at
+`
+
+// kmcSessionColumns is the column list shared by KMC session queries
+const kmcSessionColumns = `
+	ks.id,
+	ks.child_id,
+	ks.date,
+	ks.skin_to_skin_hours::float8,
+	ks.temperature_c::float8,
+	ks.weight_grams,
+	ks.exclusive_breastfeeding,
+	ks.recorded_by_id,
+	ks.notes,
+	ks.created_at
+`
+
+// neonatalCheckColumns is the column list shared by newborn check queries
+const neonatalCheckColumns = `
+	nc.id,
+	nc.child_id,
+	nc.facility_id,
+	nc.visit_id,
+	nc.checked_at,
+	nc.checked_by_id,
+	nc.temperature_c::float8,
+	nc.respiratory_rate,
+	nc.weight_grams,
+	nc.feeding,
+	nc.jaundice,
+	nc.convulsions,
+	nc.lethargic,
+	nc.severe_chest_indrawing,
+	nc.umbilical_infection,
+	nc.danger_signs,
+	nc.severity,
+	nc.sos_event_id,
+	nc.follow_up_visit_id,
+	nc.notes,
+	nc.created_at
+`
+
+// NeonatalRepository implements repository.NeonatalRepository interface
+type NeonatalRepository struct {
+	pool   *pgxpool.Pool
+	logger logger.Logger
+}
+
+// NewNeonatalRepository creates a new neonatal repository
+func NewNeonatalRepository(pool *pgxpool.Pool, logger logger.Logger) repository.NeonatalRepository {
+	return &NeonatalRepository{
+		pool:   pool,
+		logger: logger,
+	}
+}
+
+// scanNeonatalRecord scans a neonatal record from a row
+func scanNeonatalRecord(row pgx.Row) (*model.NeonatalRecord, error) {
+	var record model.NeonatalRecord
+	var category, cordCare *string
+
+	err := row.Scan(
+		&record.ID,
+		&record.ChildID,
+		&record.Apgar1Min,
+		&record.Apgar5Min,
+		&record.Apgar10Min,
+		&record.BirthWeightGrams,
+		&category,
+		&record.BreastfeedingInitiatedAt,
+		&record.EarlyBreastfeeding,
+		&cordCare,
+		&record.KMCStartedAt,
+		&record.KMCEndedAt,
+		&record.KMCEndReason,
+		&record.RecordedByID,
+		&record.Notes,
+		&record.CreatedAt,
+		&record.UpdatedAt,
+	)
+
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, errorx.New(errorx.NotFound, "neonatal record not found")
+		}
+		return nil, errorx.Wrap(err, errorx.InternalServerError, "failed to scan neonatal record")
+	}
+
+	record.BirthWeightCategory = model.BirthWeightCategory(stringValue(category))
+	record.CordCare = model.CordCare(stringValue(cordCare))
+
+	return &record, nil
+}
+
+// scanKMCSession scans a KMC session from a row
+func scanKMCSession(row pgx.Row) (*model.KMCSession, error) {
+	var session model.KMCSession
+
+	err := row.Scan(
+		&session.ID,
+		&session.ChildID,
+		&session.Date,
+		&session.SkinToSkinHours,
+		&session.TemperatureC,
+		&session.WeightGrams,
+		&session.ExclusiveBreastfeeding,
+		&session.RecordedByID,
+		&session.Notes,
+		&session.CreatedAt,
+	)
+
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, errorx.New(errorx.NotFound, "KMC session not found")
+		}
+		return nil, errorx.Wrap(err, errorx.InternalServerError, "failed to scan KMC session")
+	}
+
+	return &session, nil
+}
+
+// scanNeonatalCheck scans a newborn check from a row
+func scanNeonatalCheck(row pgx.Row) (*model.NeonatalCheck, error) {
+	var check model.NeonatalCheck
+	var signs []string
+
+	err := row.Scan(
+		&check.ID,
+		&check.ChildID,
+		&check.FacilityID,
+		&check.VisitID,
+		&check.CheckedAt,
+		&check.CheckedByID,
+		&check.TemperatureC,
+		&check.RespiratoryRate,
+		&check.WeightGrams,
+		&check.Feeding,
+		&check.Jaundice,
+		&check.Convulsions,
+		&check.Lethargic,
+		&check.SevereChestIndrawing,
+		&check.UmbilicalInfection,
+		&signs,
+		&check.Severity,
+		&check.SOSEventID,
+		&check.FollowUpVisitID,
+		&check.Notes,
+		&check.CreatedAt,
+	)
+
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, errorx.New(errorx.NotFound, "newborn check not found")
+		}
+		return nil, errorx.Wrap(err, errorx.InternalServerError, "failed to scan newborn check")
+	}
+
+	check.DangerSigns = make([]model.NeonatalDangerSign, 0, len(signs))
+	for _, sign := range signs {
+		check.DangerSigns = append(check.DangerSigns, model.NeonatalDangerSign(sign))
+	}
+
+	return &check, nil
+}
+
+// CreateRecord stores a child's neonatal record
+func (r *NeonatalRepository) CreateRecord(ctx context.Context, record *model.NeonatalRecord) error {
+	query := `
+		INSERT INTO neonatal_records (
+			id, child_id, apgar_1_min, apgar_5_min, apgar_10_min, birth_weight_grams,
+			birth_weight_category, breastfeeding_initiated_at, early_breastfeeding,
+			cord_care, kmc_started_at, kmc_ended_at, kmc_end_reason, recorded_by_id,
+			notes, created_at, updated_at
+		) VALUES (
+			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17
+		)
+	`
+
+	_, err := database.GetQuerier(ctx, r.pool).Exec(ctx, query,
+		record.ID,
+		record.ChildID,
+		record.Apgar1Min,
+		record.Apgar5Min,
+		record.Apgar10Min,
+		record.BirthWeightGrams,
+		nullableString(string(record.BirthWeightCategory)),
+		record.BreastfeedingInitiatedAt,
+		record.EarlyBreastfeeding,
+		nullableString(string(record.CordCare)),
+		record.KMCStartedAt,
+		record.KMCEndedAt,
+		record.KMCEndReason,
+		record.RecordedByID,
+		record.Notes,
+		record.CreatedAt,
+		record.UpdatedAt,
+	)
+
+	if err != nil {
+		if pgErr, ok := err.(*pgconn.PgError); ok && pgErr.Code == "23505" { // Unique violation
+			return errorx.New(errorx.AlreadyExists, "child already has a neonatal record")
+		}
+		return errorx.Wrap(err, errorx.InternalServerError, "failed to create neonatal record")
+	}
+
+	return nil
+}
+
+// UpdateRecord updates a child's neonatal record
+func (r *NeonatalRepository) UpdateRecord(ctx context.Context, record *model.NeonatalRecord) error {
+	query := `
+		UPDATE neonatal_records SET
+			apgar_1_min = $2,
+			apgar_5_min = $3,
+			apgar_10_min = $4,
+			birth_weight_grams = $5,
+			birth_weight_category = $6,
+			breastfeeding_initiated_at = $7,
+			early_breastfeeding = $8,
+			cord_care = $9,
+			kmc_started_at = $10,
+			kmc_ended_at = $11,
+			kmc_end_reason = $12,
+			notes = $13,
+			updated_at = $14
+		WHERE id = $1
+	`
+
+	result, err := database.GetQuerier(ctx, r.pool).Exec(ctx, query,
+		record.ID,
+		record.Apgar1Min,
+		record.Apgar5Min,
+		record.Apgar10Min,
+		record.BirthWeightGrams,
+		nullableString(string(record.BirthWeightCategory)),
+		record.BreastfeedingInitiatedAt,
+		record.EarlyBreastfeeding,
+		nullableString(string(record.CordCare)),
+		record.KMCStartedAt,
+		record.KMCEndedAt,
+		record.KMCEndReason,
+		record.Notes,
+		record.UpdatedAt,
+	)
+
+	if err != nil {
+		return errorx.Wrap(err, errorx.InternalServerError, "failed to update neonatal record")
+	}
+
+	if result.RowsAffected() == 0 {
+		return errorx.New(errorx.NotFound, "neonatal record not found")
+	}
+
+	return nil
+}
+
+// GetRecordByChildID retrieves a child's neonatal record
+func (r *NeonatalRepository) GetRecordByChildID(ctx context.Context, childID uuid.UUID) (*model.NeonatalRecord, error) {
+	query := `SELECT ` + neonatalRecordColumns + `
+		FROM neonatal_records nr
+		WHERE nr.child_id = $1
+	`
+
+	row := database.GetQuerier(ctx, r.pool).QueryRow(ctx, query, childID)
+	return scanNeonatalRecord(row)
+}
+
+// SaveKMCSession stores a day of kangaroo mother care, replacing any already recorded for that day
+func (r *NeonatalRepository) SaveKMCSession(ctx context.Context, session *model.KMCSession) error {
+	query := `
+		INSERT INTO kmc_sessions (
+			id, child_id, date, skin_to_skin_hours, temperature_c, weight_grams,
+			exclusive_breastfeeding, recorded_by_id, notes, created_at
+		) VALUES (
+			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10
+		)
+		ON CONFLICT (child_id, date) DO UPDATE SET
+			skin_to_skin_hours = EXCLUDED.skin_to_skin_hours,
+			temperature_c = EXCLUDED.temperature_c,
+			weight_grams = EXCLUDED.weight_grams,
+			exclusive_breastfeeding = EXCLUDED.exclusive_breastfeeding,
+			recorded_by_id = EXCLUDED.recorded_by_id,
+			notes = EXCLUDED.notes
+		RETURNING id, created_at
+	`
+
+	err := database.GetQuerier(ctx, r.pool).QueryRow(ctx, query,
+		session.ID,
+		session.ChildID,
+		session.Date,
+		session.SkinToSkinHours,
+		session.TemperatureC,
+		session.WeightGrams,
+		session.ExclusiveBreastfeeding,
+		session.RecordedByID,
+		session.Notes,
+		session.CreatedAt,
+	).Scan(&session.ID, &session.CreatedAt)
+
+	if err != nil {
+		return errorx.Wrap(err, errorx.InternalServerError, "failed to save KMC session")
+	}
+
+	return nil
+}
+
+// GetKMCSessions retrieves a child's kangaroo mother care in date order
+func (r *NeonatalRepository) GetKMCSessions(ctx context.Context, childID uuid.UUID) ([]*model.KMCSession, error) {
+	query := `SELECT ` + kmcSessionColumns + `
+		FROM kmc_sessions ks
+		WHERE ks.child_id = $1
+		ORDER BY ks.date
+	`
+
+	rows, err := database.GetQuerier(ctx, r.pool).Query(ctx, query, childID)
+	if err != nil {
+		return nil, errorx.Wrap(err, errorx.InternalServerError, "failed to query KMC sessions")
+	}
+	defer rows.Close()
+
+	var sessions []*model.KMCSession
+	for rows.Next() {
+		session, err := scanKMCSession(rows)
+		if err != nil {
+			return nil, err
+		}
+		sessions = append(sessions, session)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, errorx.Wrap(err, errorx.InternalServerError, "error iterating over KMC session rows")
+	}
+
+	return sessions, nil
+}
+
+// CreateCheck stores a newborn check
+func (r *NeonatalRepository) CreateCheck(ctx context.Context, check *model.NeonatalCheck) error {
+	query := `
+		INSERT INTO neonatal_checks (
+			id, child_id, facility_id, visit_id, checked_at, checked_by_id,
+			temperature_c, respiratory_rate, weight_grams, feeding, jaundice,
+			convulsions, lethargic, severe_chest_indrawing, umbilical_infection,
+			danger_signs, severity, sos_event_id, follow_up_visit_id, notes, created_at
+		) VALUES (
+			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11,
+			$12, $13, $14, $15, $16, $17, $18, $19, $20, $21
+		)
+	`
+
+	_, err := database.GetQuerier(ctx, r.pool).Exec(ctx, query,
+		check.ID,
+		check.ChildID,
+		check.FacilityID,
+		check.VisitID,
+		check.CheckedAt,
+		check.CheckedByID,
+		check.TemperatureC,
+		check.RespiratoryRate,
+		check.WeightGrams,
+		check.Feeding,
+		check.Jaundice,
+		check.Convulsions,
+		check.Lethargic,
+		check.SevereChestIndrawing,
+		check.UmbilicalInfection,
+		dangerSignStrings(check.DangerSigns),
+		check.Severity,
+		check.SOSEventID,
+		check.FollowUpVisitID,
+		check.Notes,
+		check.CreatedAt,
+	)
+
+	if err != nil {
+		return errorx.Wrap(err, errorx.InternalServerError, "failed to create newborn check")
+	}
+
+	return nil
+}
+
+// UpdateCheck updates the referral and follow-up raised by a newborn check
+func (r *NeonatalRepository) UpdateCheck(ctx context.Context, check *model.NeonatalCheck) error {
+	query := `
+		UPDATE neonatal_checks SET
+			sos_event_id = $2,
+			follow_up_visit_id = $3
+		WHERE id = $1
+	`
+
+	result, err := database.GetQuerier(ctx, r.pool).Exec(ctx, query,
+		check.ID,
+		check.SOSEventID,
+		check.FollowUpVisitID,
+	)
+
+	if err != nil {
+		return errorx.Wrap(err, errorx.InternalServerError, "failed to update newborn check")
+	}
+
+	if result.RowsAffected() == 0 {
+		return errorx.New(errorx.NotFound, "newborn check not found")
+	}
+
+	return nil
+}
+
+// GetChecksByChildID retrieves a child's newborn checks, newest first
+func (r *NeonatalRepository) GetChecksByChildID(ctx context.Context, childID uuid.UUID) ([]*model.NeonatalCheck, error) {
+	query := `SELECT ` + neonatalCheckColumns + `
+		FROM neonatal_checks nc
+		WHERE nc.child_id = $1
+		ORDER BY nc.checked_at DESC
+	`
+
+	rows, err := database.GetQuerier(ctx, r.pool).Query(ctx, query, childID)
+	if err != nil {
+		return nil, errorx.Wrap(err, errorx.InternalServerError, "failed to query newborn checks")
+	}
+	defer rows.Close()
+
+	var checks []*model.NeonatalCheck
+	for rows.Next() {
+		check, err := scanNeonatalCheck(rows)
+		if err != nil {
+			return nil, err
+		}
+		checks = append(checks, check)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, errorx.Wrap(err, errorx.InternalServerError, "error iterating over newborn check rows")
+	}
+
+	return checks, nil
+}
+
+// dangerSignStrings converts danger signs to the strings stored in the danger_signs array
+func dangerSignStrings(signs []model.NeonatalDangerSign) []string {
+	values := make([]string, 0, len(signs))
+	for _, sign := range signs {
+		values = append(values, string(sign))
+	}
+	return values
+}
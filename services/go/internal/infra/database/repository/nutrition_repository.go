@@ -0,0 +1,418 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/mamacare/services/internal/domain/model"
+	"github.com/mamacare/services/internal/domain/repository"
+	"github.com/mamacare/services/internal/infra/database"
+	"github.com/mamacare/services/pkg/errorx"
+	"github.com/mamacare/services/pkg/logger"
+)
+
+// nutritionCounsellingColumns is the column list shared by counselling queries
+const nutritionCounsellingColumns = `
+	nc.id,
+	nc.mother_id,
+	nc.visit_id,
+	nc.topics,
+	nc.notes,
+	nc.counselled_by_id,
+	nc.counselled_at,
+	nc.created_at
+`
+
+// supplementationEnrollmentColumns is the column list shared by enrollment queries
+const supplementationEnrollmentColumns = `
+	se.id,
+	se.mother_id,
+	se.programme,
+	se.reasons,
+	se.status,
+	se.facility_id,
+	se.enrolled_at,
+	se.ended_at,
+	se.end_reason,
+	se.created_at,
+	se.updated_at
+`
+
+// supplementDispensingColumns is the column list shared by dispensing queries
+const supplementDispensingColumns = `
+	sd.id,
+	sd.enrollment_id,
+	sd.mother_id,
+	sd.visit_id,
+	sd.facility_id,
+	sd.item,
+	sd.quantity,
+	sd.dispensed_by_id,
+	sd.dispensed_at,
+	sd.created_at
+`
+
+// NutritionRepository implements repository.NutritionRepository interface
+type NutritionRepository struct {
+	pool   *pgxpool.Pool
+	logger logger.Logger
+}
+
+// NewNutritionRepository creates a new nutrition repository
+func NewNutritionRepository(pool *pgxpool.Pool, logger logger.Logger) repository.NutritionRepository {
+	return &NutritionRepository{
+		pool:   pool,
+		logger: logger,
+	}
+}
+
+// scanNutritionCounselling scans a counselling session from a row
+func scanNutritionCounselling(row pgx.Row) (*model.NutritionCounselling, error) {
+	var counselling model.NutritionCounselling
+	var topics []string
+
+	err := row.Scan(
+		&counselling.ID,
+		&counselling.MotherID,
+		&counselling.VisitID,
+		&topics,
+		&counselling.Notes,
+		&counselling.CounselledByID,
+		&counselling.CounselledAt,
+		&counselling.CreatedAt,
+	)
+
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, errorx.New(errorx.NotFound, "nutrition counselling not found")
+		}
+		return nil, errorx.Wrap(err, errorx.InternalServerError, "failed to scan nutrition counselling")
+	}
+
+	counselling.Topics = make([]model.CounsellingTopic, 0, len(topics))
+	for _, topic := range topics {
+		counselling.Topics = append(counselling.Topics, model.CounsellingTopic(topic))
+	}
+
+	return &counselling, nil
+}
+
+// scanSupplementationEnrollment scans an enrollment from a row
+func scanSupplementationEnrollment(row pgx.Row) (*model.SupplementationEnrollment, error) {
+	var enrollment model.SupplementationEnrollment
+	var reasons []string
+
+	err := row.Scan(
+		&enrollment.ID,
+		&enrollment.MotherID,
+		&enrollment.Programme,
+		&reasons,
+		&enrollment.Status,
+		&enrollment.FacilityID,
+		&enrollment.EnrolledAt,
+		&enrollment.EndedAt,
+		&enrollment.EndReason,
+		&enrollment.CreatedAt,
+		&enrollment.UpdatedAt,
+	)
+
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, errorx.New(errorx.NotFound, "supplementation enrollment not found")
+		}
+		return nil, errorx.Wrap(err, errorx.InternalServerError, "failed to scan supplementation enrollment")
+	}
+
+	enrollment.Reasons = make([]model.NutritionFlag, 0, len(reasons))
+	for _, reason := range reasons {
+		enrollment.Reasons = append(enrollment.Reasons, model.NutritionFlag(reason))
+	}
+
+	return &enrollment, nil
+}
+
+// scanSupplementDispensing scans a dispensing from a row
+func scanSupplementDispensing(row pgx.Row) (*model.SupplementDispensing, error) {
+	var dispensing model.SupplementDispensing
+
+	err := row.Scan(
+		&dispensing.ID,
+		&dispensing.EnrollmentID,
+		&dispensing.MotherID,
+		&dispensing.VisitID,
+		&dispensing.FacilityID,
+		&dispensing.Item,
+		&dispensing.Quantity,
+		&dispensing.DispensedByID,
+		&dispensing.DispensedAt,
+		&dispensing.CreatedAt,
+	)
+
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, errorx.New(errorx.NotFound, "supplement dispensing not found")
+		}
+		return nil, errorx.Wrap(err, errorx.InternalServerError, "failed to scan supplement dispensing")
+	}
+
+	return &dispensing, nil
+}
+
+// CreateCounselling stores a dietary counselling session
+func (r *NutritionRepository) CreateCounselling(ctx context.Context, counselling *model.NutritionCounselling) error {
+	query := `
+		INSERT INTO nutrition_counsellings (
+			id, mother_id, visit_id, topics, notes, counselled_by_id, counselled_at, created_at
+		) VALUES (
+			$1, $2, $3, $4, $5, $6, $7, $8
+		)
+	`
+
+	topics := make([]string, 0, len(counselling.Topics))
+	for _, topic := range counselling.Topics {
+		topics = append(topics, string(topic))
+	}
+
+	_, err := database.GetQuerier(ctx, r.pool).Exec(ctx, query,
+		counselling.ID,
+		counselling.MotherID,
+		counselling.VisitID,
+		topics,
+		counselling.Notes,
+		counselling.CounselledByID,
+		counselling.CounselledAt,
+		counselling.CreatedAt,
+	)
+
+	if err != nil {
+		return errorx.Wrap(err, errorx.InternalServerError, "failed to create nutrition counselling")
+	}
+
+	return nil
+}
+
+// GetCounsellingByMotherID retrieves a mother's counselling sessions, newest first
+func (r *NutritionRepository) GetCounsellingByMotherID(ctx context.Context, motherID uuid.UUID) ([]*model.NutritionCounselling, error) {
+	query := `SELECT ` + nutritionCounsellingColumns + `
+		FROM nutrition_counsellings nc
+		WHERE nc.mother_id = $1
+		ORDER BY nc.counselled_at DESC
+	`
+
+	rows, err := database.GetQuerier(ctx, r.pool).Query(ctx, query, motherID)
+	if err != nil {
+		return nil, errorx.Wrap(err, errorx.InternalServerError, "failed to query nutrition counselling by mother")
+	}
+	defer rows.Close()
+
+	var sessions []*model.NutritionCounselling
+	for rows.Next() {
+		counselling, err := scanNutritionCounselling(rows)
+		if err != nil {
+			return nil, err
+		}
+		sessions = append(sessions, counselling)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, errorx.Wrap(err, errorx.InternalServerError, "error iterating over nutrition counselling rows")
+	}
+
+	return sessions, nil
+}
+
+// SaveEnrollment creates or updates a supplementation enrollment
+func (r *NutritionRepository) SaveEnrollment(ctx context.Context, enrollment *model.SupplementationEnrollment) error {
+	query := `
+		INSERT INTO supplementation_enrollments (
+			id, mother_id, programme, reasons, status, facility_id,
+			enrolled_at, ended_at, end_reason, created_at, updated_at
+		) VALUES (
+			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11
+		) ON CONFLICT (id) DO UPDATE SET
+			reasons = EXCLUDED.reasons,
+			status = EXCLUDED.status,
+			facility_id = EXCLUDED.facility_id,
+			ended_at = EXCLUDED.ended_at,
+			end_reason = EXCLUDED.end_reason,
+			updated_at = EXCLUDED.updated_at
+	`
+
+	reasons := make([]string, 0, len(enrollment.Reasons))
+	for _, reason := range enrollment.Reasons {
+		reasons = append(reasons, string(reason))
+	}
+
+	_, err := database.GetQuerier(ctx, r.pool).Exec(ctx, query,
+		enrollment.ID,
+		enrollment.MotherID,
+		enrollment.Programme,
+		reasons,
+		enrollment.Status,
+		enrollment.FacilityID,
+		enrollment.EnrolledAt,
+		enrollment.EndedAt,
+		enrollment.EndReason,
+		enrollment.CreatedAt,
+		enrollment.UpdatedAt,
+	)
+
+	if err != nil {
+		return errorx.Wrap(err, errorx.InternalServerError, "failed to save supplementation enrollment")
+	}
+
+	return nil
+}
+
+// GetEnrollmentByID retrieves a supplementation enrollment
+func (r *NutritionRepository) GetEnrollmentByID(ctx context.Context, id uuid.UUID) (*model.SupplementationEnrollment, error) {
+	query := `SELECT ` + supplementationEnrollmentColumns + `
+		FROM supplementation_enrollments se
+		WHERE se.id = $1
+	`
+
+	row := database.GetQuerier(ctx, r.pool).QueryRow(ctx, query, id)
+	return scanSupplementationEnrollment(row)
+}
+
+// GetActiveEnrollment retrieves a mother's active enrollment
+func (r *NutritionRepository) GetActiveEnrollment(ctx context.Context, motherID uuid.UUID) (*model.SupplementationEnrollment, error) {
+	query := `SELECT ` + supplementationEnrollmentColumns + `
+		FROM supplementation_enrollments se
+		WHERE se.mother_id = $1 AND se.status = 'active'
+		ORDER BY se.enrolled_at DESC
+		LIMIT 1
+	`
+
+	row := database.GetQuerier(ctx, r.pool).QueryRow(ctx, query, motherID)
+	return scanSupplementationEnrollment(row)
+}
+
+// Dispense records supplements handed out and takes them off the facility's stock
+func (r *NutritionRepository) Dispense(ctx context.Context, dispensing *model.SupplementDispensing) (*model.SupplementStock, error) {
+	// The stock update and the dispensing insert are one statement, so stock can
+	// never go negative or be taken without a dispensing record
+	query := `
+		WITH stock AS (
+			UPDATE supplement_stocks
+			SET quantity_on_hand = quantity_on_hand - $7, updated_at = $10
+			WHERE facility_id = $5 AND item = $6 AND quantity_on_hand >= $7
+			RETURNING facility_id, item, quantity_on_hand, updated_at
+		), dispensed AS (
+			INSERT INTO supplement_dispensings (
+				id, enrollment_id, mother_id, visit_id, facility_id, item,
+				quantity, dispensed_by_id, dispensed_at, created_at
+			)
+			SELECT $1, $2, $3, $4, $5, $6, $7, $8, $9, $10
+			FROM stock
+		)
+		SELECT facility_id, item, quantity_on_hand, updated_at FROM stock
+	`
+
+	var stock model.SupplementStock
+	err := database.GetQuerier(ctx, r.pool).QueryRow(ctx, query,
+		dispensing.ID,
+		dispensing.EnrollmentID,
+		dispensing.MotherID,
+		dispensing.VisitID,
+		dispensing.FacilityID,
+		dispensing.Item,
+		dispensing.Quantity,
+		dispensing.DispensedByID,
+		dispensing.DispensedAt,
+		dispensing.CreatedAt,
+	).Scan(&stock.FacilityID, &stock.Item, &stock.QuantityOnHand, &stock.UpdatedAt)
+
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, errorx.Newf(errorx.BadRequest, "not enough %s in stock at the facility", dispensing.Item)
+		}
+		return nil, errorx.Wrap(err, errorx.InternalServerError, "failed to dispense supplement")
+	}
+
+	return &stock, nil
+}
+
+// GetDispensingsByEnrollment retrieves the supplements dispensed for an enrollment, oldest first
+func (r *NutritionRepository) GetDispensingsByEnrollment(ctx context.Context, enrollmentID uuid.UUID) ([]*model.SupplementDispensing, error) {
+	query := `SELECT ` + supplementDispensingColumns + `
+		FROM supplement_dispensings sd
+		WHERE sd.enrollment_id = $1
+		ORDER BY sd.dispensed_at
+	`
+
+	rows, err := database.GetQuerier(ctx, r.pool).Query(ctx, query, enrollmentID)
+	if err != nil {
+		return nil, errorx.Wrap(err, errorx.InternalServerError, "failed to query supplement dispensings by enrollment")
+	}
+	defer rows.Close()
+
+	var dispensings []*model.SupplementDispensing
+	for rows.Next() {
+		dispensing, err := scanSupplementDispensing(rows)
+		if err != nil {
+			return nil, err
+		}
+		dispensings = append(dispensings, dispensing)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, errorx.Wrap(err, errorx.InternalServerError, "error iterating over supplement dispensing rows")
+	}
+
+	return dispensings, nil
+}
+
+// AddStock adds received supplements to a facility's stock and returns the new level
+func (r *NutritionRepository) AddStock(ctx context.Context, facilityID uuid.UUID, item model.SupplementItem, quantity int) (*model.SupplementStock, error) {
+	query := `
+		INSERT INTO supplement_stocks (facility_id, item, quantity_on_hand, updated_at)
+		VALUES ($1, $2, $3, NOW())
+		ON CONFLICT (facility_id, item) DO UPDATE SET
+			quantity_on_hand = supplement_stocks.quantity_on_hand + EXCLUDED.quantity_on_hand,
+			updated_at = EXCLUDED.updated_at
+		RETURNING facility_id, item, quantity_on_hand, updated_at
+	`
+
+	var stock model.SupplementStock
+	err := database.GetQuerier(ctx, r.pool).QueryRow(ctx, query, facilityID, item, quantity).
+		Scan(&stock.FacilityID, &stock.Item, &stock.QuantityOnHand, &stock.UpdatedAt)
+	if err != nil {
+		return nil, errorx.Wrap(err, errorx.InternalServerError, "failed to add supplement stock")
+	}
+
+	return &stock, nil
+}
+
+// GetStockByFacility retrieves a facility's stock of every supplement it holds
+func (r *NutritionRepository) GetStockByFacility(ctx context.Context, facilityID uuid.UUID) ([]*model.SupplementStock, error) {
+	query := `
+		SELECT facility_id, item, quantity_on_hand, updated_at
+		FROM supplement_stocks
+		WHERE facility_id = $1
+		ORDER BY item
+	`
+
+	rows, err := database.GetQuerier(ctx, r.pool).Query(ctx, query, facilityID)
+	if err != nil {
+		return nil, errorx.Wrap(err, errorx.InternalServerError, "failed to query supplement stock by facility")
+	}
+	defer rows.Close()
+
+	var stocks []*model.SupplementStock
+	for rows.Next() {
+		var stock model.SupplementStock
+		if err := rows.Scan(&stock.FacilityID, &stock.Item, &stock.QuantityOnHand, &stock.UpdatedAt); err != nil {
+			return nil, errorx.Wrap(err, errorx.InternalServerError, "failed to scan supplement stock")
+		}
+		stocks = append(stocks, &stock)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, errorx.Wrap(err, errorx.InternalServerError, "error iterating over supplement stock rows")
+	}
+
+	return stocks, nil
+}
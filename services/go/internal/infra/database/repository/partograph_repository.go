@@ -0,0 +1,257 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/mamacare/services/internal/domain/model"
+	"github.com/mamacare/services/internal/domain/repository"
+	"github.com/mamacare/services/internal/infra/database"
+	"github.com/mamacare/services/pkg/errorx"
+	"github.com/mamacare/services/pkg/logger"
+)
+
+// partographColumns is the column list shared by partograph queries
+const partographColumns = `
+	p.id,
+	p.mother_id,
+	p.facility_id,
+	p.attendant_id,
+	p.admitted_at,
+	p.active_phase_at,
+	p.status,
+	p.observations,
+	p.alerts,
+	p.referral_sos_id,
+	p.referral_reason,
+	p.closed_at,
+	p.created_at,
+	p.updated_at
+`
+
+// PartographRepository implements repository.PartographRepository interface
+type PartographRepository struct {
+	pool   *pgxpool.Pool
+	logger logger.Logger
+}
+
+// NewPartographRepository creates a new partograph repository
+func NewPartographRepository(pool *pgxpool.Pool, logger logger.Logger) repository.PartographRepository {
+	return &PartographRepository{
+		pool:   pool,
+		logger: logger,
+	}
+}
+
+// scanPartograph scans a partograph from a row
+func scanPartograph(row pgx.Row) (*model.Partograph, error) {
+	var partograph model.Partograph
+	var observationsJSON, alertsJSON []byte
+	var referralReason *string
+
+	err := row.Scan(
+		&partograph.ID,
+		&partograph.MotherID,
+		&partograph.FacilityID,
+		&partograph.AttendantID,
+		&partograph.AdmittedAt,
+		&partograph.ActivePhaseAt,
+		&partograph.Status,
+		&observationsJSON,
+		&alertsJSON,
+		&partograph.ReferralSOSID,
+		&referralReason,
+		&partograph.ClosedAt,
+		&partograph.CreatedAt,
+		&partograph.UpdatedAt,
+	)
+
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, errorx.New(errorx.NotFound, "partograph not found")
+		}
+		return nil, errorx.Wrap(err, errorx.InternalServerError, "failed to scan partograph")
+	}
+
+	if referralReason != nil {
+		partograph.ReferralReason = *referralReason
+	}
+
+	partograph.Observations = []model.PartographObservation{}
+	if observationsJSON != nil {
+		if err := json.Unmarshal(observationsJSON, &partograph.Observations); err != nil {
+			return nil, errorx.Wrap(err, errorx.InternalServerError, "failed to unmarshal partograph observations")
+		}
+	}
+
+	partograph.Alerts = []model.PartographAlert{}
+	if alertsJSON != nil {
+		if err := json.Unmarshal(alertsJSON, &partograph.Alerts); err != nil {
+			return nil, errorx.Wrap(err, errorx.InternalServerError, "failed to unmarshal partograph alerts")
+		}
+	}
+
+	return &partograph, nil
+}
+
+// marshalPartograph marshals the JSONB columns of a partograph
+func marshalPartograph(partograph *model.Partograph) ([]byte, []byte, error) {
+	observationsJSON, err := json.Marshal(partograph.Observations)
+	if err != nil {
+		return nil, nil, errorx.Wrap(err, errorx.InternalServerError, "failed to marshal partograph observations")
+	}
+
+	alertsJSON, err := json.Marshal(partograph.Alerts)
+	if err != nil {
+		return nil, nil, errorx.Wrap(err, errorx.InternalServerError, "failed to marshal partograph alerts")
+	}
+
+	return observationsJSON, alertsJSON, nil
+}
+
+// Create creates a new partograph
+func (r *PartographRepository) Create(ctx context.Context, partograph *model.Partograph) error {
+	observationsJSON, alertsJSON, err := marshalPartograph(partograph)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO partographs (
+			id, mother_id, facility_id, attendant_id, admitted_at, active_phase_at, status,
+			observations, alerts, referral_sos_id, referral_reason, closed_at, created_at, updated_at
+		) VALUES (
+			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14
+		)
+	`
+
+	_, err = database.GetQuerier(ctx, r.pool).Exec(ctx, query,
+		partograph.ID,
+		partograph.MotherID,
+		partograph.FacilityID,
+		partograph.AttendantID,
+		partograph.AdmittedAt,
+		partograph.ActivePhaseAt,
+		partograph.Status,
+		observationsJSON,
+		alertsJSON,
+		partograph.ReferralSOSID,
+		partograph.ReferralReason,
+		partograph.ClosedAt,
+		partograph.CreatedAt,
+		partograph.UpdatedAt,
+	)
+
+	if err != nil {
+		return errorx.Wrap(err, errorx.InternalServerError, "failed to create partograph")
+	}
+
+	return nil
+}
+
+// GetByID retrieves a partograph by its ID
+func (r *PartographRepository) GetByID(ctx context.Context, id uuid.UUID) (*model.Partograph, error) {
+	query := `SELECT ` + partographColumns + ` FROM partographs p WHERE p.id = $1`
+
+	row := database.GetQuerier(ctx, r.pool).QueryRow(ctx, query, id)
+	return scanPartograph(row)
+}
+
+// GetByIDForUpdate retrieves a partograph and locks it until the surrounding transaction ends
+func (r *PartographRepository) GetByIDForUpdate(ctx context.Context, id uuid.UUID) (*model.Partograph, error) {
+	query := `SELECT ` + partographColumns + ` FROM partographs p WHERE p.id = $1 FOR UPDATE`
+
+	row := database.GetQuerier(ctx, r.pool).QueryRow(ctx, query, id)
+	return scanPartograph(row)
+}
+
+// GetActiveByMotherID retrieves the mother's partograph that is still being monitored
+func (r *PartographRepository) GetActiveByMotherID(ctx context.Context, motherID uuid.UUID) (*model.Partograph, error) {
+	query := `SELECT ` + partographColumns + `
+		FROM partographs p
+		WHERE p.mother_id = $1 AND p.status = $2
+	`
+
+	row := database.GetQuerier(ctx, r.pool).QueryRow(ctx, query, motherID, model.PartographStatusActive)
+	return scanPartograph(row)
+}
+
+// GetActiveByFacilityID retrieves the partographs being monitored at a facility
+func (r *PartographRepository) GetActiveByFacilityID(ctx context.Context, facilityID uuid.UUID) ([]*model.Partograph, error) {
+	query := `SELECT ` + partographColumns + `
+		FROM partographs p
+		WHERE p.facility_id = $1 AND p.status = $2
+		ORDER BY p.admitted_at ASC
+	`
+
+	rows, err := database.GetQuerier(ctx, r.pool).Query(ctx, query, facilityID, model.PartographStatusActive)
+	if err != nil {
+		return nil, errorx.Wrap(err, errorx.InternalServerError, "failed to query partographs by facility")
+	}
+	defer rows.Close()
+
+	var partographs []*model.Partograph
+	for rows.Next() {
+		partograph, err := scanPartograph(rows)
+		if err != nil {
+			return nil, err
+		}
+		partographs = append(partographs, partograph)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, errorx.Wrap(err, errorx.InternalServerError, "error iterating over partograph rows")
+	}
+
+	return partographs, nil
+}
+
+// Update updates an existing partograph, including its observations and alerts
+func (r *PartographRepository) Update(ctx context.Context, partograph *model.Partograph) error {
+	partograph.UpdatedAt = time.Now()
+
+	observationsJSON, alertsJSON, err := marshalPartograph(partograph)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		UPDATE partographs SET
+			attendant_id = $2,
+			active_phase_at = $3,
+			status = $4,
+			observations = $5,
+			alerts = $6,
+			referral_sos_id = $7,
+			referral_reason = $8,
+			closed_at = $9,
+			updated_at = $10
+		WHERE id = $1
+	`
+
+	tag, err := database.GetQuerier(ctx, r.pool).Exec(ctx, query,
+		partograph.ID,
+		partograph.AttendantID,
+		partograph.ActivePhaseAt,
+		partograph.Status,
+		observationsJSON,
+		alertsJSON,
+		partograph.ReferralSOSID,
+		partograph.ReferralReason,
+		partograph.ClosedAt,
+		partograph.UpdatedAt,
+	)
+
+	if err != nil {
+		return errorx.Wrap(err, errorx.InternalServerError, "failed to update partograph")
+	}
+	if tag.RowsAffected() == 0 {
+		return errorx.New(errorx.NotFound, "partograph not found")
+	}
+
+	return nil
+}
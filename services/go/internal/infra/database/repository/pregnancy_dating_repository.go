@@ -0,0 +1,149 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/mamacare/services/internal/domain/model"
+	"github.com/mamacare/services/internal/domain/repository"
+	"github.com/mamacare/services/internal/infra/database"
+	"github.com/mamacare/services/pkg/errorx"
+	"github.com/mamacare/services/pkg/logger"
+)
+
+// pregnancyDatingColumns is the column list shared by pregnancy dating queries
+const pregnancyDatingColumns = `
+	pd.id,
+	pd.mother_id,
+	pd.method,
+	pd.exam_date,
+	pd.lmp,
+	pd.measurement_mm,
+	pd.fundal_height_cm,
+	pd.gestational_age_days,
+	pd.estimated_edd,
+	pd.previous_edd,
+	pd.previous_method,
+	pd.discrepancy_days,
+	pd.applied,
+	pd.reason,
+	pd.recorded_by_id,
+	pd.created_at
+`
+
+// PregnancyDatingRepository implements repository.PregnancyDatingRepository interface
+type PregnancyDatingRepository struct {
+	pool   *pgxpool.Pool
+	logger logger.Logger
+}
+
+// NewPregnancyDatingRepository creates a new pregnancy dating repository
+func NewPregnancyDatingRepository(pool *pgxpool.Pool, logger logger.Logger) repository.PregnancyDatingRepository {
+	return &PregnancyDatingRepository{
+		pool:   pool,
+		logger: logger,
+	}
+}
+
+// scanPregnancyDating scans a pregnancy dating from a row
+func scanPregnancyDating(row pgx.Row) (*model.PregnancyDating, error) {
+	var dating model.PregnancyDating
+
+	err := row.Scan(
+		&dating.ID,
+		&dating.MotherID,
+		&dating.Method,
+		&dating.ExamDate,
+		&dating.LMP,
+		&dating.MeasurementMm,
+		&dating.FundalHeightCm,
+		&dating.GestationalAgeDays,
+		&dating.EstimatedEDD,
+		&dating.PreviousEDD,
+		&dating.PreviousMethod,
+		&dating.DiscrepancyDays,
+		&dating.Applied,
+		&dating.Reason,
+		&dating.RecordedByID,
+		&dating.CreatedAt,
+	)
+
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, errorx.New(errorx.NotFound, "pregnancy dating not found")
+		}
+		return nil, errorx.Wrap(err, errorx.InternalServerError, "failed to scan pregnancy dating")
+	}
+
+	return &dating, nil
+}
+
+// Create stores a new dating estimate
+func (r *PregnancyDatingRepository) Create(ctx context.Context, dating *model.PregnancyDating) error {
+	query := `
+		INSERT INTO pregnancy_datings (
+			id, mother_id, method, exam_date, lmp, measurement_mm, fundal_height_cm,
+			gestational_age_days, estimated_edd, previous_edd, previous_method,
+			discrepancy_days, applied, reason, recorded_by_id, created_at
+		) VALUES (
+			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16
+		)
+	`
+
+	_, err := database.GetQuerier(ctx, r.pool).Exec(ctx, query,
+		dating.ID,
+		dating.MotherID,
+		dating.Method,
+		dating.ExamDate,
+		dating.LMP,
+		dating.MeasurementMm,
+		dating.FundalHeightCm,
+		dating.GestationalAgeDays,
+		dating.EstimatedEDD,
+		dating.PreviousEDD,
+		dating.PreviousMethod,
+		dating.DiscrepancyDays,
+		dating.Applied,
+		dating.Reason,
+		dating.RecordedByID,
+		dating.CreatedAt,
+	)
+
+	if err != nil {
+		return errorx.Wrap(err, errorx.InternalServerError, "failed to create pregnancy dating")
+	}
+
+	return nil
+}
+
+// GetByMotherID retrieves a mother's dating history, oldest first
+func (r *PregnancyDatingRepository) GetByMotherID(ctx context.Context, motherID uuid.UUID) ([]*model.PregnancyDating, error) {
+	query := `SELECT ` + pregnancyDatingColumns + `
+		FROM pregnancy_datings pd
+		WHERE pd.mother_id = $1
+		ORDER BY pd.created_at
+	`
+
+	rows, err := database.GetQuerier(ctx, r.pool).Query(ctx, query, motherID)
+	if err != nil {
+		return nil, errorx.Wrap(err, errorx.InternalServerError, "failed to query pregnancy datings by mother")
+	}
+	defer rows.Close()
+
+	var datings []*model.PregnancyDating
+	for rows.Next() {
+		dating, err := scanPregnancyDating(rows)
+		if err != nil {
+			return nil, err
+		}
+		datings = append(datings, dating)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, errorx.Wrap(err, errorx.InternalServerError, "error iterating over pregnancy dating rows")
+	}
+
+	return datings, nil
+}
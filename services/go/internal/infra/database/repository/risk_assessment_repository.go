@@ -0,0 +1,194 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/mamacare/services/internal/domain/model"
+	"github.com/mamacare/services/internal/domain/repository"
+	"github.com/mamacare/services/internal/infra/database"
+	"github.com/mamacare/services/pkg/errorx"
+	"github.com/mamacare/services/pkg/logger"
+)
+
+// riskAssessmentColumns is the column list shared by risk assessment queries
+const riskAssessmentColumns = `
+	ra.id,
+	ra.mother_id,
+	ra.risk_level,
+	ra.previous_risk_level,
+	ra.risk_score,
+	ra.risk_factors,
+	ra.fired_rules,
+	ra.rule_set_version,
+	ra.trigger,
+	ra.trigger_ref_id,
+	ra.maternal_age,
+	ra.gestational_age_weeks,
+	ra.assessed_at,
+	ra.created_at
+`
+
+// RiskAssessmentRepository implements repository.RiskAssessmentRepository interface
+type RiskAssessmentRepository struct {
+	pool   *pgxpool.Pool
+	logger logger.Logger
+}
+
+// NewRiskAssessmentRepository creates a new risk assessment repository
+func NewRiskAssessmentRepository(pool *pgxpool.Pool, logger logger.Logger) repository.RiskAssessmentRepository {
+	return &RiskAssessmentRepository{
+		pool:   pool,
+		logger: logger,
+	}
+}
+
+// scanRiskAssessment scans a risk assessment from a row
+func scanRiskAssessment(row pgx.Row) (*model.RiskAssessment, error) {
+	var assessment model.RiskAssessment
+	var factorsJSON, firedRulesJSON []byte
+
+	err := row.Scan(
+		&assessment.ID,
+		&assessment.MotherID,
+		&assessment.RiskLevel,
+		&assessment.PreviousRiskLevel,
+		&assessment.RiskScore,
+		&factorsJSON,
+		&firedRulesJSON,
+		&assessment.RuleSetVersion,
+		&assessment.Trigger,
+		&assessment.TriggerRefID,
+		&assessment.MaternalAge,
+		&assessment.GestationalAgeWeeks,
+		&assessment.AssessedAt,
+		&assessment.CreatedAt,
+	)
+
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, errorx.New(errorx.NotFound, "risk assessment not found")
+		}
+		return nil, errorx.Wrap(err, errorx.InternalServerError, "failed to scan risk assessment")
+	}
+
+	if factorsJSON != nil {
+		if err := json.Unmarshal(factorsJSON, &assessment.RiskFactors); err != nil {
+			return nil, errorx.Wrap(err, errorx.InternalServerError, "failed to unmarshal risk factors")
+		}
+	}
+
+	assessment.FiredRules = []model.FiredRule{}
+	if firedRulesJSON != nil {
+		if err := json.Unmarshal(firedRulesJSON, &assessment.FiredRules); err != nil {
+			return nil, errorx.Wrap(err, errorx.InternalServerError, "failed to unmarshal fired rules")
+		}
+	}
+
+	return &assessment, nil
+}
+
+// Create stores a new risk assessment
+func (r *RiskAssessmentRepository) Create(ctx context.Context, assessment *model.RiskAssessment) error {
+	factorsJSON, err := json.Marshal(assessment.RiskFactors)
+	if err != nil {
+		return errorx.Wrap(err, errorx.InternalServerError, "failed to marshal risk factors")
+	}
+
+	firedRulesJSON, err := json.Marshal(assessment.FiredRules)
+	if err != nil {
+		return errorx.Wrap(err, errorx.InternalServerError, "failed to marshal fired rules")
+	}
+
+	query := `
+		INSERT INTO risk_assessments (
+			id, mother_id, risk_level, previous_risk_level, risk_score, risk_factors, fired_rules,
+			rule_set_version, trigger, trigger_ref_id, maternal_age, gestational_age_weeks,
+			assessed_at, created_at
+		) VALUES (
+			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14
+		)
+	`
+
+	_, err = database.GetQuerier(ctx, r.pool).Exec(ctx, query,
+		assessment.ID,
+		assessment.MotherID,
+		assessment.RiskLevel,
+		assessment.PreviousRiskLevel,
+		assessment.RiskScore,
+		factorsJSON,
+		firedRulesJSON,
+		assessment.RuleSetVersion,
+		assessment.Trigger,
+		assessment.TriggerRefID,
+		assessment.MaternalAge,
+		assessment.GestationalAgeWeeks,
+		assessment.AssessedAt,
+		assessment.CreatedAt,
+	)
+
+	if err != nil {
+		return errorx.Wrap(err, errorx.InternalServerError, "failed to create risk assessment")
+	}
+
+	return nil
+}
+
+// GetByID retrieves a risk assessment by its ID
+func (r *RiskAssessmentRepository) GetByID(ctx context.Context, id uuid.UUID) (*model.RiskAssessment, error) {
+	query := `SELECT ` + riskAssessmentColumns + ` FROM risk_assessments ra WHERE ra.id = $1`
+
+	row := database.GetQuerier(ctx, r.pool).QueryRow(ctx, query, id)
+	return scanRiskAssessment(row)
+}
+
+// GetLatestByMotherID retrieves the most recent risk assessment for a mother
+func (r *RiskAssessmentRepository) GetLatestByMotherID(ctx context.Context, motherID uuid.UUID) (*model.RiskAssessment, error) {
+	query := `SELECT ` + riskAssessmentColumns + `
+		FROM risk_assessments ra
+		WHERE ra.mother_id = $1
+		ORDER BY ra.assessed_at DESC
+		LIMIT 1
+	`
+
+	row := database.GetQuerier(ctx, r.pool).QueryRow(ctx, query, motherID)
+	return scanRiskAssessment(row)
+}
+
+// GetByMotherID retrieves a mother's risk assessments, most recent first
+func (r *RiskAssessmentRepository) GetByMotherID(ctx context.Context, motherID uuid.UUID, limit int) ([]*model.RiskAssessment, error) {
+	if limit <= 0 {
+		limit = 50
+	}
+
+	query := `SELECT ` + riskAssessmentColumns + `
+		FROM risk_assessments ra
+		WHERE ra.mother_id = $1
+		ORDER BY ra.assessed_at DESC
+		LIMIT $2
+	`
+
+	rows, err := database.GetQuerier(ctx, r.pool).Query(ctx, query, motherID, limit)
+	if err != nil {
+		return nil, errorx.Wrap(err, errorx.InternalServerError, "failed to query risk assessments by mother")
+	}
+	defer rows.Close()
+
+	var assessments []*model.RiskAssessment
+	for rows.Next() {
+		assessment, err := scanRiskAssessment(rows)
+		if err != nil {
+			return nil, err
+		}
+		assessments = append(assessments, assessment)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, errorx.Wrap(err, errorx.InternalServerError, "error iterating over risk assessment rows")
+	}
+
+	return assessments, nil
+}
@@ -0,0 +1,875 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/mamacare/services/internal/domain/model"
+	"github.com/mamacare/services/internal/domain/repository"
+	"github.com/mamacare/services/internal/infra/database"
+	"github.com/mamacare/services/pkg/errorx"
+	"github.com/mamacare/services/pkg/logger"
+)
+
+// screenerQuestionColumns is the column list shared by screener question queries
+const screenerQuestionColumns = `
+	sq.id,
+	sq.questionnaire_id,
+	sq.question_text,
+	sq.question_code,
+	sq.answer_type,
+	sq.answer_options,
+	sq.is_danger_sign,
+	sq.risk_category::text,
+	sq.category,
+	sq.subcategory,
+	sq.display_order,
+	sq.depends_on_question_id,
+	sq.depends_on_answer,
+	sq.translation_key,
+	sq.is_active,
+	sq.created_at,
+	sq.updated_at
+`
+
+// screenerQuestionnaireColumns is the column list shared by screener questionnaire queries
+const screenerQuestionnaireColumns = `
+	qn.id,
+	qn.subject,
+	qn.version,
+	qn.title,
+	qn.status,
+	qn.based_on_id,
+	qn.notes,
+	qn.created_by_id,
+	qn.published_by_id,
+	qn.published_at,
+	qn.created_at,
+	qn.updated_at
+`
+
+// screenerResultColumns is the column list shared by screener result queries
+const screenerResultColumns = `
+	sr.id,
+	sr.questionnaire_id,
+	sr.mother_id,
+	sr.child_id,
+	sr.screened_at,
+	sr.screened_by_user_id,
+	sr.screened_by_name,
+	sr.completed,
+	sr.location_latitude::float8,
+	sr.location_longitude::float8,
+	sr.facility_id,
+	sr.risk_level::text,
+	sr.primary_concern,
+	sr.danger_signs_detected,
+	sr.followup_recommended,
+	sr.action_taken,
+	sr.referral_facility_id,
+	sr.followup_visit_id,
+	sr.created_at,
+	sr.updated_at
+`
+
+// screenerAnswerColumns is the column list shared by screener answer queries
+const screenerAnswerColumns = `
+	sa.id,
+	sa.screener_result_id,
+	sa.question_id,
+	sa.question_text,
+	sa.answer_boolean,
+	sa.answer_text,
+	sa.answer_numeric::float8,
+	sa.answer_option,
+	sa.contributed_to_risk,
+	sa.individual_risk_level::text,
+	sa.answer_sequence,
+	sa.created_at
+`
+
+// ScreenerRepository implements repository.ScreenerRepository interface
+type ScreenerRepository struct {
+	pool   *pgxpool.Pool
+	logger logger.Logger
+}
+
+// NewScreenerRepository creates a new screener repository
+func NewScreenerRepository(pool *pgxpool.Pool, logger logger.Logger) repository.ScreenerRepository {
+	return &ScreenerRepository{
+		pool:   pool,
+		logger: logger,
+	}
+}
+
+// GetSymptomsByUserID retrieves the positive answers recorded in a mother's screenings
+func (r *ScreenerRepository) GetSymptomsByUserID(ctx context.Context, userID uuid.UUID, since time.Time) ([]*model.ScreenerSymptom, error) {
+	query := `
+		SELECT
+			sr.id,
+			sq.id,
+			sq.question_code,
+			sq.category,
+			COALESCE(sq.subcategory, ''),
+			sq.is_danger_sign,
+			sr.screened_at
+		FROM screener_answers sa
+		JOIN screener_results sr ON sr.id = sa.screener_result_id
+		JOIN screener_questions sq ON sq.id = sa.question_id
+		WHERE sr.mother_id = $1
+		AND sr.screened_at >= $2
+		AND (sa.answer_boolean = TRUE OR sa.contributed_to_risk = TRUE)
+		ORDER BY sr.screened_at DESC, sa.answer_sequence
+	`
+
+	rows, err := database.GetQuerier(ctx, r.pool).Query(ctx, query, userID, since)
+	if err != nil {
+		return nil, errorx.Wrap(err, errorx.InternalServerError, "failed to query screener symptoms")
+	}
+	defer rows.Close()
+
+	var symptoms []*model.ScreenerSymptom
+	for rows.Next() {
+		var symptom model.ScreenerSymptom
+		if err := rows.Scan(
+			&symptom.ScreenerResultID,
+			&symptom.QuestionID,
+			&symptom.QuestionCode,
+			&symptom.Category,
+			&symptom.Subcategory,
+			&symptom.IsDangerSign,
+			&symptom.ScreenedAt,
+		); err != nil {
+			return nil, errorx.Wrap(err, errorx.InternalServerError, "failed to scan screener symptom")
+		}
+		symptoms = append(symptoms, &symptom)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, errorx.Wrap(err, errorx.InternalServerError, "error iterating over screener symptom rows")
+	}
+
+	return symptoms, nil
+}
+
+// scanScreenerQuestion scans a screener question from a row
+func scanScreenerQuestion(row pgx.Row) (*model.ScreenerQuestion, error) {
+	var question model.ScreenerQuestion
+	var optionsJSON []byte
+	var riskCategory, subcategory *string
+
+	err := row.Scan(
+		&question.ID,
+		&question.QuestionnaireID,
+		&question.Text,
+		&question.Code,
+		&question.AnswerType,
+		&optionsJSON,
+		&question.IsDangerSign,
+		&riskCategory,
+		&question.Category,
+		&subcategory,
+		&question.DisplayOrder,
+		&question.DependsOnQuestionID,
+		&question.DependsOnAnswer,
+		&question.TranslationKey,
+		&question.IsActive,
+		&question.CreatedAt,
+		&question.UpdatedAt,
+	)
+
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, errorx.New(errorx.NotFound, "screener question not found")
+		}
+		return nil, errorx.Wrap(err, errorx.InternalServerError, "failed to scan screener question")
+	}
+
+	if riskCategory != nil {
+		risk := model.ScreenerRisk(*riskCategory)
+		question.RiskCategory = &risk
+	}
+	question.Subcategory = stringValue(subcategory)
+
+	if optionsJSON != nil {
+		if err := json.Unmarshal(optionsJSON, &question.AnswerOptions); err != nil {
+			return nil, errorx.Wrap(err, errorx.InternalServerError, "failed to unmarshal screener answer options")
+		}
+	}
+
+	return &question, nil
+}
+
+// scanScreenerResult scans a screener result from a row
+func scanScreenerResult(row pgx.Row) (*model.ScreenerResult, error) {
+	var result model.ScreenerResult
+	var screenedByName, primaryConcern, actionTaken *string
+
+	err := row.Scan(
+		&result.ID,
+		&result.QuestionnaireID,
+		&result.MotherID,
+		&result.ChildID,
+		&result.ScreenedAt,
+		&result.ScreenedByID,
+		&screenedByName,
+		&result.Completed,
+		&result.Latitude,
+		&result.Longitude,
+		&result.FacilityID,
+		&result.RiskLevel,
+		&primaryConcern,
+		&result.DangerSignsDetected,
+		&result.FollowupRecommended,
+		&actionTaken,
+		&result.ReferralFacilityID,
+		&result.FollowupVisitID,
+		&result.CreatedAt,
+		&result.UpdatedAt,
+	)
+
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, errorx.New(errorx.NotFound, "screener result not found")
+		}
+		return nil, errorx.Wrap(err, errorx.InternalServerError, "failed to scan screener result")
+	}
+
+	result.ScreenedByName = stringValue(screenedByName)
+	result.PrimaryConcern = stringValue(primaryConcern)
+	result.ActionTaken = stringValue(actionTaken)
+	result.Answers = []*model.ScreenerAnswer{}
+
+	return &result, nil
+}
+
+// scanScreenerAnswer scans a screener answer from a row
+func scanScreenerAnswer(row pgx.Row) (*model.ScreenerAnswer, error) {
+	var answer model.ScreenerAnswer
+	var riskLevel *string
+
+	err := row.Scan(
+		&answer.ID,
+		&answer.ScreenerResultID,
+		&answer.QuestionID,
+		&answer.QuestionText,
+		&answer.Boolean,
+		&answer.Text,
+		&answer.Numeric,
+		&answer.Option,
+		&answer.ContributedToRisk,
+		&riskLevel,
+		&answer.Sequence,
+		&answer.CreatedAt,
+	)
+
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, errorx.New(errorx.NotFound, "screener answer not found")
+		}
+		return nil, errorx.Wrap(err, errorx.InternalServerError, "failed to scan screener answer")
+	}
+
+	if riskLevel != nil {
+		risk := model.ScreenerRisk(*riskLevel)
+		answer.IndividualRiskLevel = &risk
+	}
+
+	return &answer, nil
+}
+
+// scanScreenerQuestionnaire scans a screener questionnaire from a row
+func scanScreenerQuestionnaire(row pgx.Row) (*model.ScreenerQuestionnaire, error) {
+	var questionnaire model.ScreenerQuestionnaire
+	var notes *string
+
+	err := row.Scan(
+		&questionnaire.ID,
+		&questionnaire.Subject,
+		&questionnaire.Version,
+		&questionnaire.Title,
+		&questionnaire.Status,
+		&questionnaire.BasedOnID,
+		&notes,
+		&questionnaire.CreatedByID,
+		&questionnaire.PublishedByID,
+		&questionnaire.PublishedAt,
+		&questionnaire.CreatedAt,
+		&questionnaire.UpdatedAt,
+	)
+
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, errorx.New(errorx.NotFound, "screener questionnaire not found")
+		}
+		return nil, errorx.Wrap(err, errorx.InternalServerError, "failed to scan screener questionnaire")
+	}
+
+	questionnaire.Notes = stringValue(notes)
+
+	return &questionnaire, nil
+}
+
+// GetQuestionnaires retrieves the questionnaire versions, newest first and without their questions
+func (r *ScreenerRepository) GetQuestionnaires(ctx context.Context, subject *model.ScreenerSubject) ([]*model.ScreenerQuestionnaire, error) {
+	query := `SELECT ` + screenerQuestionnaireColumns + `
+		FROM screener_questionnaires qn
+		WHERE ($1::text IS NULL OR qn.subject = $1)
+		ORDER BY qn.subject, qn.version DESC
+	`
+
+	var subjectFilter *string
+	if subject != nil {
+		value := string(*subject)
+		subjectFilter = &value
+	}
+
+	rows, err := database.GetQuerier(ctx, r.pool).Query(ctx, query, subjectFilter)
+	if err != nil {
+		return nil, errorx.Wrap(err, errorx.InternalServerError, "failed to query screener questionnaires")
+	}
+	defer rows.Close()
+
+	questionnaires := []*model.ScreenerQuestionnaire{}
+	for rows.Next() {
+		questionnaire, err := scanScreenerQuestionnaire(rows)
+		if err != nil {
+			return nil, err
+		}
+		questionnaires = append(questionnaires, questionnaire)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, errorx.Wrap(err, errorx.InternalServerError, "error iterating over screener questionnaire rows")
+	}
+
+	return questionnaires, nil
+}
+
+// GetQuestionnaireByID retrieves a questionnaire version with all its questions in display order
+func (r *ScreenerRepository) GetQuestionnaireByID(ctx context.Context, id uuid.UUID) (*model.ScreenerQuestionnaire, error) {
+	query := `SELECT ` + screenerQuestionnaireColumns + `
+		FROM screener_questionnaires qn
+		WHERE qn.id = $1
+	`
+
+	questionnaire, err := scanScreenerQuestionnaire(database.GetQuerier(ctx, r.pool).QueryRow(ctx, query, id))
+	if err != nil {
+		return nil, err
+	}
+
+	if err := r.loadQuestions(ctx, questionnaire); err != nil {
+		return nil, err
+	}
+
+	return questionnaire, nil
+}
+
+// GetPublishedQuestionnaire retrieves the version a subject is screened with
+func (r *ScreenerRepository) GetPublishedQuestionnaire(ctx context.Context, subject model.ScreenerSubject) (*model.ScreenerQuestionnaire, error) {
+	query := `SELECT ` + screenerQuestionnaireColumns + `
+		FROM screener_questionnaires qn
+		WHERE qn.subject = $1
+		AND qn.status = 'PUBLISHED'
+	`
+
+	questionnaire, err := scanScreenerQuestionnaire(database.GetQuerier(ctx, r.pool).QueryRow(ctx, query, string(subject)))
+	if err != nil {
+		return nil, err
+	}
+
+	if err := r.loadQuestions(ctx, questionnaire); err != nil {
+		return nil, err
+	}
+
+	return questionnaire, nil
+}
+
+// loadQuestions loads all of a questionnaire's questions in display order
+func (r *ScreenerRepository) loadQuestions(ctx context.Context, questionnaire *model.ScreenerQuestionnaire) error {
+	query := `SELECT ` + screenerQuestionColumns + `
+		FROM screener_questions sq
+		WHERE sq.questionnaire_id = $1
+		ORDER BY sq.display_order, sq.question_code
+	`
+
+	rows, err := database.GetQuerier(ctx, r.pool).Query(ctx, query, questionnaire.ID)
+	if err != nil {
+		return errorx.Wrap(err, errorx.InternalServerError, "failed to query screener questions")
+	}
+	defer rows.Close()
+
+	questionnaire.Questions = []*model.ScreenerQuestion{}
+	for rows.Next() {
+		question, err := scanScreenerQuestion(rows)
+		if err != nil {
+			return err
+		}
+		questionnaire.Questions = append(questionnaire.Questions, question)
+	}
+
+	if err := rows.Err(); err != nil {
+		return errorx.Wrap(err, errorx.InternalServerError, "error iterating over screener question rows")
+	}
+
+	return nil
+}
+
+// CreateQuestionnaire creates a draft questionnaire numbered after the subject's latest version.
+// The questions of the version it is based on are copied with new IDs in the same statement,
+// with their dependencies pointing at the copies.
+func (r *ScreenerRepository) CreateQuestionnaire(ctx context.Context, questionnaire *model.ScreenerQuestionnaire) error {
+	query := `
+		WITH created AS (
+			INSERT INTO screener_questionnaires (
+				id, subject, version, title, status, based_on_id, notes, created_by_id,
+				created_at, updated_at
+			)
+			SELECT $1, $2, COALESCE(MAX(qn.version), 0) + 1, $3, $4, $5, $6, $7, $8, $9
+			FROM screener_questionnaires qn
+			WHERE qn.subject = $2
+			RETURNING id, version, based_on_id
+		),
+		source AS (
+			SELECT sq.*, uuid_generate_v4() AS new_id
+			FROM screener_questions sq
+			JOIN created c ON sq.questionnaire_id = c.based_on_id
+		),
+		copied AS (
+			INSERT INTO screener_questions (
+				id, questionnaire_id, question_text, question_code, answer_type, answer_options,
+				is_danger_sign, risk_category, category, subcategory, display_order,
+				depends_on_question_id, depends_on_answer, translation_key, is_active,
+				created_at, updated_at
+			)
+			SELECT
+				s.new_id, c.id, s.question_text, s.question_code, s.answer_type, s.answer_options,
+				s.is_danger_sign, s.risk_category, s.category, s.subcategory, s.display_order,
+				d.new_id, s.depends_on_answer, s.translation_key, s.is_active,
+				$8, $9
+			FROM source s
+			CROSS JOIN created c
+			LEFT JOIN source d ON d.id = s.depends_on_question_id
+		)
+		SELECT version FROM created
+	`
+
+	err := database.GetQuerier(ctx, r.pool).QueryRow(ctx, query,
+		questionnaire.ID,
+		string(questionnaire.Subject),
+		questionnaire.Title,
+		string(questionnaire.Status),
+		questionnaire.BasedOnID,
+		nullableString(questionnaire.Notes),
+		questionnaire.CreatedByID,
+		questionnaire.CreatedAt,
+		questionnaire.UpdatedAt,
+	).Scan(&questionnaire.Version)
+
+	if err != nil {
+		if pgErr, ok := err.(*pgconn.PgError); ok && pgErr.Code == "23505" { // Unique violation
+			return errorx.New(errorx.AlreadyExists, "another version of this questionnaire was just created")
+		}
+		return errorx.Wrap(err, errorx.InternalServerError, "failed to create screener questionnaire")
+	}
+
+	return nil
+}
+
+// UpdateQuestionnaire updates a draft questionnaire's title and notes
+func (r *ScreenerRepository) UpdateQuestionnaire(ctx context.Context, questionnaire *model.ScreenerQuestionnaire) error {
+	query := `
+		UPDATE screener_questionnaires SET
+			title = $2,
+			notes = $3,
+			updated_at = $4
+		WHERE id = $1
+		AND status = 'DRAFT'
+	`
+
+	tag, err := database.GetQuerier(ctx, r.pool).Exec(ctx, query,
+		questionnaire.ID,
+		questionnaire.Title,
+		nullableString(questionnaire.Notes),
+		questionnaire.UpdatedAt,
+	)
+
+	if err != nil {
+		return errorx.Wrap(err, errorx.InternalServerError, "failed to update screener questionnaire")
+	}
+
+	if tag.RowsAffected() == 0 {
+		return errorx.New(errorx.NotFound, "draft screener questionnaire not found")
+	}
+
+	return nil
+}
+
+// PublishQuestionnaire publishes a draft questionnaire and retires the version published before it.
+// The one published version per subject constraint is deferred, so both updates can run together.
+func (r *ScreenerRepository) PublishQuestionnaire(ctx context.Context, id, publishedByID uuid.UUID, publishedAt time.Time) error {
+	query := `
+		WITH draft AS (
+			SELECT id, subject
+			FROM screener_questionnaires
+			WHERE id = $1
+			AND status = 'DRAFT'
+		),
+		retired AS (
+			UPDATE screener_questionnaires qn SET
+				status = 'RETIRED',
+				updated_at = $3
+			FROM draft
+			WHERE qn.subject = draft.subject
+			AND qn.status = 'PUBLISHED'
+			RETURNING qn.id
+		)
+		UPDATE screener_questionnaires qn SET
+			status = 'PUBLISHED',
+			published_by_id = $2,
+			published_at = $3,
+			updated_at = $3
+		FROM draft
+		WHERE qn.id = draft.id
+	`
+
+	tag, err := database.GetQuerier(ctx, r.pool).Exec(ctx, query, id, publishedByID, publishedAt)
+	if err != nil {
+		return errorx.Wrap(err, errorx.InternalServerError, "failed to publish screener questionnaire")
+	}
+
+	if tag.RowsAffected() == 0 {
+		return errorx.New(errorx.NotFound, "draft screener questionnaire not found")
+	}
+
+	return nil
+}
+
+// GetQuestionByID retrieves a screener question by ID
+func (r *ScreenerRepository) GetQuestionByID(ctx context.Context, id uuid.UUID) (*model.ScreenerQuestion, error) {
+	query := `SELECT ` + screenerQuestionColumns + `
+		FROM screener_questions sq
+		WHERE sq.id = $1
+	`
+
+	return scanScreenerQuestion(database.GetQuerier(ctx, r.pool).QueryRow(ctx, query, id))
+}
+
+// questionArgs returns the stored answer options and risk category of a question
+func questionArgs(question *model.ScreenerQuestion) ([]byte, *string, error) {
+	var optionsJSON []byte
+	if len(question.AnswerOptions) > 0 {
+		var err error
+		optionsJSON, err = json.Marshal(question.AnswerOptions)
+		if err != nil {
+			return nil, nil, errorx.Wrap(err, errorx.InternalServerError, "failed to marshal screener answer options")
+		}
+	}
+
+	var riskCategory *string
+	if question.RiskCategory != nil {
+		risk := string(*question.RiskCategory)
+		riskCategory = &risk
+	}
+
+	return optionsJSON, riskCategory, nil
+}
+
+// CreateQuestion creates a question in a draft questionnaire
+func (r *ScreenerRepository) CreateQuestion(ctx context.Context, question *model.ScreenerQuestion) error {
+	query := `
+		INSERT INTO screener_questions (
+			id, questionnaire_id, question_text, question_code, answer_type, answer_options,
+			is_danger_sign, risk_category, category, subcategory, display_order,
+			depends_on_question_id, depends_on_answer, translation_key, is_active,
+			created_at, updated_at
+		) VALUES (
+			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17
+		)
+	`
+
+	optionsJSON, riskCategory, err := questionArgs(question)
+	if err != nil {
+		return err
+	}
+
+	_, err = database.GetQuerier(ctx, r.pool).Exec(ctx, query,
+		question.ID,
+		question.QuestionnaireID,
+		question.Text,
+		question.Code,
+		string(question.AnswerType),
+		optionsJSON,
+		question.IsDangerSign,
+		riskCategory,
+		question.Category,
+		nullableString(question.Subcategory),
+		question.DisplayOrder,
+		question.DependsOnQuestionID,
+		question.DependsOnAnswer,
+		question.TranslationKey,
+		question.IsActive,
+		question.CreatedAt,
+		question.UpdatedAt,
+	)
+
+	if err != nil {
+		if pgErr, ok := err.(*pgconn.PgError); ok && pgErr.Code == "23505" { // Unique violation
+			return errorx.New(errorx.AlreadyExists, "question code already used in this questionnaire")
+		}
+		return errorx.Wrap(err, errorx.InternalServerError, "failed to create screener question")
+	}
+
+	return nil
+}
+
+// UpdateQuestion updates a question of a draft questionnaire
+func (r *ScreenerRepository) UpdateQuestion(ctx context.Context, question *model.ScreenerQuestion) error {
+	query := `
+		UPDATE screener_questions SET
+			question_text = $2,
+			question_code = $3,
+			answer_type = $4,
+			answer_options = $5,
+			is_danger_sign = $6,
+			risk_category = $7,
+			category = $8,
+			subcategory = $9,
+			display_order = $10,
+			depends_on_question_id = $11,
+			depends_on_answer = $12,
+			translation_key = $13,
+			is_active = $14,
+			updated_at = $15
+		WHERE id = $1
+	`
+
+	optionsJSON, riskCategory, err := questionArgs(question)
+	if err != nil {
+		return err
+	}
+
+	tag, err := database.GetQuerier(ctx, r.pool).Exec(ctx, query,
+		question.ID,
+		question.Text,
+		question.Code,
+		string(question.AnswerType),
+		optionsJSON,
+		question.IsDangerSign,
+		riskCategory,
+		question.Category,
+		nullableString(question.Subcategory),
+		question.DisplayOrder,
+		question.DependsOnQuestionID,
+		question.DependsOnAnswer,
+		question.TranslationKey,
+		question.IsActive,
+		question.UpdatedAt,
+	)
+
+	if err != nil {
+		if pgErr, ok := err.(*pgconn.PgError); ok && pgErr.Code == "23505" { // Unique violation
+			return errorx.New(errorx.AlreadyExists, "question code already used in this questionnaire")
+		}
+		return errorx.Wrap(err, errorx.InternalServerError, "failed to update screener question")
+	}
+
+	if tag.RowsAffected() == 0 {
+		return errorx.New(errorx.NotFound, "screener question not found")
+	}
+
+	return nil
+}
+
+// DeleteQuestion deletes a question of a draft questionnaire
+func (r *ScreenerRepository) DeleteQuestion(ctx context.Context, id uuid.UUID) error {
+	tag, err := database.GetQuerier(ctx, r.pool).Exec(ctx, `DELETE FROM screener_questions WHERE id = $1`, id)
+	if err != nil {
+		return errorx.Wrap(err, errorx.InternalServerError, "failed to delete screener question")
+	}
+
+	if tag.RowsAffected() == 0 {
+		return errorx.New(errorx.NotFound, "screener question not found")
+	}
+
+	return nil
+}
+
+// CreateResult creates a new screening result without its answers
+func (r *ScreenerRepository) CreateResult(ctx context.Context, result *model.ScreenerResult) error {
+	query := `
+		INSERT INTO screener_results (
+			id, questionnaire_id, mother_id, child_id, screened_at, screened_by_user_id,
+			screened_by_name, completed, location_latitude, location_longitude, facility_id,
+			risk_level, primary_concern, danger_signs_detected, followup_recommended,
+			action_taken, referral_facility_id, followup_visit_id, created_at, updated_at
+		) VALUES (
+			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20
+		)
+	`
+
+	_, err := database.GetQuerier(ctx, r.pool).Exec(ctx, query,
+		result.ID,
+		result.QuestionnaireID,
+		result.MotherID,
+		result.ChildID,
+		result.ScreenedAt,
+		result.ScreenedByID,
+		nullableString(result.ScreenedByName),
+		result.Completed,
+		result.Latitude,
+		result.Longitude,
+		result.FacilityID,
+		string(result.RiskLevel),
+		nullableString(result.PrimaryConcern),
+		result.DangerSignsDetected,
+		result.FollowupRecommended,
+		nullableString(result.ActionTaken),
+		result.ReferralFacilityID,
+		result.FollowupVisitID,
+		result.CreatedAt,
+		result.UpdatedAt,
+	)
+
+	if err != nil {
+		if pgErr, ok := err.(*pgconn.PgError); ok && pgErr.Code == "23505" { // Unique violation
+			return errorx.New(errorx.AlreadyExists, "screener result already exists")
+		}
+		return errorx.Wrap(err, errorx.InternalServerError, "failed to create screener result")
+	}
+
+	return nil
+}
+
+// UpdateResult updates a screening result's completion, risk evaluation and actions. Results
+// started before questionnaires were versioned are linked to the version they are finished with.
+func (r *ScreenerRepository) UpdateResult(ctx context.Context, result *model.ScreenerResult) error {
+	query := `
+		UPDATE screener_results SET
+			completed = $2,
+			facility_id = $3,
+			risk_level = $4,
+			primary_concern = $5,
+			danger_signs_detected = $6,
+			followup_recommended = $7,
+			action_taken = $8,
+			referral_facility_id = $9,
+			followup_visit_id = $10,
+			updated_at = $11,
+			questionnaire_id = COALESCE(questionnaire_id, $12)
+		WHERE id = $1
+	`
+
+	tag, err := database.GetQuerier(ctx, r.pool).Exec(ctx, query,
+		result.ID,
+		result.Completed,
+		result.FacilityID,
+		string(result.RiskLevel),
+		nullableString(result.PrimaryConcern),
+		result.DangerSignsDetected,
+		result.FollowupRecommended,
+		nullableString(result.ActionTaken),
+		result.ReferralFacilityID,
+		result.FollowupVisitID,
+		result.UpdatedAt,
+		result.QuestionnaireID,
+	)
+
+	if err != nil {
+		return errorx.Wrap(err, errorx.InternalServerError, "failed to update screener result")
+	}
+
+	if tag.RowsAffected() == 0 {
+		return errorx.New(errorx.NotFound, "screener result not found")
+	}
+
+	return nil
+}
+
+// GetResultByID retrieves a screening result with its answers in the order they were given
+func (r *ScreenerRepository) GetResultByID(ctx context.Context, id uuid.UUID) (*model.ScreenerResult, error) {
+	query := `SELECT ` + screenerResultColumns + `
+		FROM screener_results sr
+		WHERE sr.id = $1
+	`
+
+	querier := database.GetQuerier(ctx, r.pool)
+	result, err := scanScreenerResult(querier.QueryRow(ctx, query, id))
+	if err != nil {
+		return nil, err
+	}
+
+	answersQuery := `SELECT ` + screenerAnswerColumns + `
+		FROM screener_answers sa
+		WHERE sa.screener_result_id = $1
+		ORDER BY sa.answer_sequence
+	`
+
+	rows, err := querier.Query(ctx, answersQuery, id)
+	if err != nil {
+		return nil, errorx.Wrap(err, errorx.InternalServerError, "failed to query screener answers")
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		answer, err := scanScreenerAnswer(rows)
+		if err != nil {
+			return nil, err
+		}
+		result.Answers = append(result.Answers, answer)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, errorx.Wrap(err, errorx.InternalServerError, "error iterating over screener answer rows")
+	}
+
+	return result, nil
+}
+
+// CreateAnswer records an answer in a screening
+func (r *ScreenerRepository) CreateAnswer(ctx context.Context, answer *model.ScreenerAnswer) error {
+	query := `
+		INSERT INTO screener_answers (
+			id, screener_result_id, question_id, question_text, answer_boolean, answer_text,
+			answer_numeric, answer_option, contributed_to_risk, individual_risk_level,
+			answer_sequence, created_at
+		) VALUES (
+			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12
+		)
+	`
+
+	var riskLevel *string
+	if answer.IndividualRiskLevel != nil {
+		level := string(*answer.IndividualRiskLevel)
+		riskLevel = &level
+	}
+
+	_, err := database.GetQuerier(ctx, r.pool).Exec(ctx, query,
+		answer.ID,
+		answer.ScreenerResultID,
+		answer.QuestionID,
+		answer.QuestionText,
+		answer.Boolean,
+		answer.Text,
+		answer.Numeric,
+		answer.Option,
+		answer.ContributedToRisk,
+		riskLevel,
+		answer.Sequence,
+		answer.CreatedAt,
+	)
+
+	if err != nil {
+		if pgErr, ok := err.(*pgconn.PgError); ok && pgErr.Code == "23505" { // Unique violation
+			return errorx.New(errorx.AlreadyExists, "question already answered in this screening")
+		}
+		return errorx.Wrap(err, errorx.InternalServerError, "failed to create screener answer")
+	}
+
+	return nil
+}
@@ -33,6 +33,7 @@ func NewUserRepository(pool *pgxpool.Pool, logger logger.Logger) repository.User
 // scanUser scans a user from a row
 func scanUser(row pgx.Row) (*model.User, error) {
 	var user model.User
+	var assignedArea *string
 	var facilityID *uuid.UUID
 
 	err := row.Scan(
@@ -42,6 +43,7 @@ func scanUser(row pgx.Row) (*model.User, error) {
 		&user.Phone,
 		&user.Role,
 		&user.District,
+		&assignedArea,
 		&facilityID,
 		&user.CreatedAt,
 		&user.UpdatedAt,
@@ -54,6 +56,7 @@ func scanUser(row pgx.Row) (*model.User, error) {
 		return nil, errorx.Wrap(err, errorx.InternalServerError, "failed to scan user")
 	}
 
+	user.AssignedArea = stringValue(assignedArea)
 	user.FacilityID = facilityID
 	return &user, nil
 }
@@ -61,7 +64,7 @@ func scanUser(row pgx.Row) (*model.User, error) {
 // FindByID retrieves a user by ID
 func (r *UserRepository) FindByID(ctx context.Context, id uuid.UUID) (*model.User, error) {
 	query := `
-		SELECT id, name, email, phone_number, role, district, facility_id, created_at, updated_at
+		SELECT id, name, email, phone_number, role, district, assigned_area, facility_id, created_at, updated_at
 		FROM users
 		WHERE id = $1
 	`
@@ -73,7 +76,7 @@ func (r *UserRepository) FindByID(ctx context.Context, id uuid.UUID) (*model.Use
 // FindByEmail retrieves a user by email
 func (r *UserRepository) FindByEmail(ctx context.Context, email string) (*model.User, error) {
 	query := `
-		SELECT id, name, email, phone_number, role, district, facility_id, created_at, updated_at
+		SELECT id, name, email, phone_number, role, district, assigned_area, facility_id, created_at, updated_at
 		FROM users
 		WHERE email = $1
 	`
@@ -85,7 +88,7 @@ func (r *UserRepository) FindByEmail(ctx context.Context, email string) (*model.
 // FindByPhoneNumber retrieves a user by phone number
 func (r *UserRepository) FindByPhoneNumber(ctx context.Context, phoneNumber string) (*model.User, error) {
 	query := `
-		SELECT id, name, email, phone_number, role, district, facility_id, created_at, updated_at
+		SELECT id, name, email, phone_number, role, district, assigned_area, facility_id, created_at, updated_at
 		FROM users
 		WHERE phone_number = $1
 	`
@@ -99,7 +102,7 @@ func (r *UserRepository) FindByFirebaseUID(ctx context.Context, firebaseUID stri
 	// Assuming we have a column for firebase_uid or similar mechanism
 	// This might need to be adjusted based on how Firebase integration is handled
 	query := `
-		SELECT id, name, email, phone_number, role, district, facility_id, created_at, updated_at
+		SELECT id, name, email, phone_number, role, district, assigned_area, facility_id, created_at, updated_at
 		FROM users
 		WHERE firebase_uid = $1
 	`
@@ -116,15 +119,16 @@ func (r *UserRepository) Save(ctx context.Context, user *model.User) error {
 	// Use upsert to handle both insert and update
 	query := `
 		INSERT INTO users (
-			id, name, email, phone_number, role, district, facility_id, created_at, updated_at
+			id, name, email, phone_number, role, district, assigned_area, facility_id, created_at, updated_at
 		) VALUES (
-			$1, $2, $3, $4, $5, $6, $7, $8, $9
+			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10
 		) ON CONFLICT (id) DO UPDATE SET
 			name = EXCLUDED.name,
 			email = EXCLUDED.email,
 			phone_number = EXCLUDED.phone_number,
 			role = EXCLUDED.role,
 			district = EXCLUDED.district,
+			assigned_area = EXCLUDED.assigned_area,
 			facility_id = EXCLUDED.facility_id,
 			updated_at = EXCLUDED.updated_at
 	`
@@ -136,6 +140,7 @@ func (r *UserRepository) Save(ctx context.Context, user *model.User) error {
 		user.Phone,
 		user.Role,
 		user.District,
+		nullableString(user.AssignedArea),
 		user.FacilityID,
 		user.CreatedAt,
 		user.UpdatedAt,
@@ -172,7 +177,7 @@ func (r *UserRepository) Delete(ctx context.Context, id uuid.UUID) error {
 // FindHealthcareProvidersByDistrict retrieves healthcare providers by district
 func (r *UserRepository) FindHealthcareProvidersByDistrict(ctx context.Context, district string) ([]*model.User, error) {
 	query := `
-		SELECT id, name, email, phone_number, role, district, facility_id, created_at, updated_at
+		SELECT id, name, email, phone_number, role, district, assigned_area, facility_id, created_at, updated_at
 		FROM users
 		WHERE district = $1 AND role IN ('chw', 'clinician')
 		ORDER BY name
@@ -190,7 +195,7 @@ func (r *UserRepository) FindHealthcareProvidersByDistrict(ctx context.Context,
 // FindByRole retrieves users by role
 func (r *UserRepository) FindByRole(ctx context.Context, role model.UserRole) ([]*model.User, error) {
 	query := `
-		SELECT id, name, email, phone_number, role, district, facility_id, created_at, updated_at
+		SELECT id, name, email, phone_number, role, district, assigned_area, facility_id, created_at, updated_at
 		FROM users
 		WHERE role = $1
 		ORDER BY name
@@ -215,6 +220,7 @@ func (r *UserRepository) FindByFacility(ctx context.Context, facilityID uuid.UUI
 			u.phone_number, 
 			u.role, 
 			u.district, 
+			u.assigned_area, 
 			u.facility_id, 
 			u.created_at, 
 			u.updated_at
@@ -241,6 +247,7 @@ func (r *UserRepository) FindByDistrict(ctx context.Context, district string) ([
 			u.phone_number, 
 			u.role, 
 			u.district, 
+			u.assigned_area, 
 			u.facility_id, 
 			u.created_at, 
 			u.updated_at
@@ -267,6 +274,7 @@ func (r *UserRepository) FindAll(ctx context.Context) ([]*model.User, error) {
 			u.phone_number, 
 			u.role, 
 			u.district, 
+			u.assigned_area, 
 			u.facility_id, 
 			u.created_at, 
 			u.updated_at
@@ -324,6 +332,7 @@ func scanUsers(rows pgx.Rows) ([]*model.User, error) {
 
 	for rows.Next() {
 		var user model.User
+		var assignedArea *string
 		var facilityID *uuid.UUID
 
 		err := rows.Scan(
@@ -333,6 +342,7 @@ func scanUsers(rows pgx.Rows) ([]*model.User, error) {
 			&user.Phone,
 			&user.Role,
 			&user.District,
+			&assignedArea,
 			&facilityID,
 			&user.CreatedAt,
 			&user.UpdatedAt,
@@ -342,6 +352,7 @@ func scanUsers(rows pgx.Rows) ([]*model.User, error) {
 			return nil, errorx.Wrap(err, errorx.InternalServerError, "failed to scan user")
 		}
 
+		user.AssignedArea = stringValue(assignedArea)
 		user.FacilityID = facilityID
 		users = append(users, &user)
 	}
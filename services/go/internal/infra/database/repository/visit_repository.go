@@ -2,6 +2,7 @@ package repository
 
 import (
 	"context"
+	"encoding/json"
 	"time"
 
 	"github.com/google/uuid"
@@ -33,6 +34,7 @@ func scanVisit(row pgx.Row) (*model.Visit, error) {
 	var visit model.Visit
 	var checkInTime, checkOutTime *time.Time
 	var chwID, clinicianID *uuid.UUID
+	var verificationJSON []byte
 
 	err := row.Scan(
 		&visit.ID,
@@ -48,6 +50,10 @@ func scanVisit(row pgx.Row) (*model.Visit, error) {
 		&visit.Status,
 		&visit.CreatedAt,
 		&visit.UpdatedAt,
+		&visit.ChildID,
+		&visit.DueBy,
+		&visit.Sequence,
+		&verificationJSON,
 	)
 
 	if err != nil {
@@ -62,6 +68,12 @@ func scanVisit(row pgx.Row) (*model.Visit, error) {
 	visit.CheckInTime = checkInTime
 	visit.CheckOutTime = checkOutTime
 
+	if verificationJSON != nil {
+		if err := json.Unmarshal(verificationJSON, &visit.CheckInVerification); err != nil {
+			return nil, errorx.Wrap(err, errorx.InternalServerError, "failed to unmarshal check-in verification")
+		}
+	}
+
 	return &visit, nil
 }
 
@@ -81,7 +93,11 @@ func (r *VisitRepository) FindByID(ctx context.Context, id uuid.UUID) (*model.Vi
 			v.visit_notes, 
 			v.status, 
 			v.created_at, 
-			v.updated_at
+			v.updated_at, 
+			v.child_id, 
+			v.due_by,
+			v.sequence,
+			v.check_in_verification
 		FROM visits v
 		WHERE v.id = $1
 	`
@@ -106,7 +122,11 @@ func (r *VisitRepository) FindByMother(ctx context.Context, motherID uuid.UUID)
 			v.visit_notes, 
 			v.status, 
 			v.created_at, 
-			v.updated_at
+			v.updated_at, 
+			v.child_id, 
+			v.due_by,
+			v.sequence,
+			v.check_in_verification
 		FROM visits v
 		WHERE v.mother_id = $1
 		ORDER BY v.scheduled_time DESC
@@ -137,7 +157,11 @@ func (r *VisitRepository) FindByFacility(ctx context.Context, facilityID uuid.UU
 			v.visit_notes, 
 			v.status, 
 			v.created_at, 
-			v.updated_at
+			v.updated_at, 
+			v.child_id, 
+			v.due_by,
+			v.sequence,
+			v.check_in_verification
 		FROM visits v
 		WHERE v.facility_id = $1
 		ORDER BY v.scheduled_time
@@ -168,7 +192,11 @@ func (r *VisitRepository) FindByCHW(ctx context.Context, chwID uuid.UUID) ([]*mo
 			v.visit_notes, 
 			v.status, 
 			v.created_at, 
-			v.updated_at
+			v.updated_at, 
+			v.child_id, 
+			v.due_by,
+			v.sequence,
+			v.check_in_verification
 		FROM visits v
 		WHERE v.chw_id = $1
 		ORDER BY v.scheduled_time
@@ -199,7 +227,11 @@ func (r *VisitRepository) FindByClinician(ctx context.Context, clinicianID uuid.
 			v.visit_notes, 
 			v.status, 
 			v.created_at, 
-			v.updated_at
+			v.updated_at, 
+			v.child_id, 
+			v.due_by,
+			v.sequence,
+			v.check_in_verification
 		FROM visits v
 		WHERE v.clinician_id = $1
 		ORDER BY v.scheduled_time
@@ -230,7 +262,11 @@ func (r *VisitRepository) FindByDateRange(ctx context.Context, startDate, endDat
 			v.visit_notes, 
 			v.status, 
 			v.created_at, 
-			v.updated_at
+			v.updated_at, 
+			v.child_id, 
+			v.due_by,
+			v.sequence,
+			v.check_in_verification
 		FROM visits v
 		WHERE v.scheduled_time BETWEEN $1 AND $2
 		ORDER BY v.scheduled_time
@@ -261,7 +297,11 @@ func (r *VisitRepository) FindByStatus(ctx context.Context, status model.VisitSt
 			v.visit_notes, 
 			v.status, 
 			v.created_at, 
-			v.updated_at
+			v.updated_at, 
+			v.child_id, 
+			v.due_by,
+			v.sequence,
+			v.check_in_verification
 		FROM visits v
 		WHERE v.status = $1
 		ORDER BY v.scheduled_time
@@ -292,7 +332,11 @@ func (r *VisitRepository) FindUpcoming(ctx context.Context, motherID uuid.UUID)
 			v.visit_notes, 
 			v.status, 
 			v.created_at, 
-			v.updated_at
+			v.updated_at, 
+			v.child_id, 
+			v.due_by,
+			v.sequence,
+			v.check_in_verification
 		FROM visits v
 		WHERE v.mother_id = $1 
 		AND v.scheduled_time > NOW() 
@@ -326,7 +370,11 @@ func (r *VisitRepository) FindUpcomingByFacility(ctx context.Context, facilityID
 			v.visit_notes, 
 			v.status, 
 			v.created_at, 
-			v.updated_at
+			v.updated_at, 
+			v.child_id, 
+			v.due_by,
+			v.sequence,
+			v.check_in_verification
 		FROM visits v
 		WHERE v.facility_id = $1 
 		AND v.scheduled_time > NOW() 
@@ -349,13 +397,23 @@ func (r *VisitRepository) Save(ctx context.Context, visit *model.Visit) error {
 	// Update timestamp for modifications
 	visit.UpdatedAt = time.Now()
 
+	var verificationJSON []byte
+	if visit.CheckInVerification != nil {
+		var err error
+		verificationJSON, err = json.Marshal(visit.CheckInVerification)
+		if err != nil {
+			return errorx.Wrap(err, errorx.InternalServerError, "failed to marshal check-in verification")
+		}
+	}
+
 	// Use upsert to handle both insert and update
 	query := `
 		INSERT INTO visits (
 			id, mother_id, facility_id, chw_id, clinician_id, scheduled_time,
-			check_in_time, check_out_time, visit_type, visit_notes, status, created_at, updated_at
+			check_in_time, check_out_time, visit_type, visit_notes, status, created_at, updated_at,
+			child_id, due_by, sequence, check_in_verification
 		) VALUES (
-			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13
+			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17
 		) ON CONFLICT (id) DO UPDATE SET
 			mother_id = EXCLUDED.mother_id,
 			facility_id = EXCLUDED.facility_id,
@@ -367,7 +425,11 @@ func (r *VisitRepository) Save(ctx context.Context, visit *model.Visit) error {
 			visit_type = EXCLUDED.visit_type,
 			visit_notes = EXCLUDED.visit_notes,
 			status = EXCLUDED.status,
-			updated_at = EXCLUDED.updated_at
+			updated_at = EXCLUDED.updated_at,
+			child_id = EXCLUDED.child_id,
+			due_by = EXCLUDED.due_by,
+			sequence = EXCLUDED.sequence,
+			check_in_verification = EXCLUDED.check_in_verification
 	`
 
 	_, err := database.GetQuerier(ctx, r.pool).Exec(ctx, query,
@@ -384,6 +446,10 @@ func (r *VisitRepository) Save(ctx context.Context, visit *model.Visit) error {
 		visit.Status,
 		visit.CreatedAt,
 		visit.UpdatedAt,
+		visit.ChildID,
+		visit.DueBy,
+		visit.Sequence,
+		verificationJSON,
 	)
 
 	if err != nil {
@@ -453,6 +519,7 @@ func scanVisits(rows pgx.Rows) ([]*model.Visit, error) {
 		var visit model.Visit
 		var checkInTime, checkOutTime *time.Time
 		var chwID, clinicianID *uuid.UUID
+		var verificationJSON []byte
 
 		err := rows.Scan(
 			&visit.ID,
@@ -468,6 +535,10 @@ func scanVisits(rows pgx.Rows) ([]*model.Visit, error) {
 			&visit.Status,
 			&visit.CreatedAt,
 			&visit.UpdatedAt,
+			&visit.ChildID,
+			&visit.DueBy,
+			&visit.Sequence,
+			&verificationJSON,
 		)
 
 		if err != nil {
@@ -479,6 +550,12 @@ func scanVisits(rows pgx.Rows) ([]*model.Visit, error) {
 		visit.CheckInTime = checkInTime
 		visit.CheckOutTime = checkOutTime
 
+		if verificationJSON != nil {
+			if err := json.Unmarshal(verificationJSON, &visit.CheckInVerification); err != nil {
+				return nil, errorx.Wrap(err, errorx.InternalServerError, "failed to unmarshal check-in verification")
+			}
+		}
+
 		visits = append(visits, &visit)
 	}
 
@@ -5,7 +5,9 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"strings"
 
+	"github.com/google/uuid"
 	"github.com/mamacare/services/internal/port/middleware"
 	"github.com/mamacare/services/pkg/errorx"
 	"github.com/mamacare/services/pkg/logger"
@@ -26,6 +28,42 @@ type ActionRequest struct {
 	RequestQuery string `json:"request_query"`
 }
 
+// Session variables Hasura sets from the caller's JWT claims
+const (
+	// SessionUserID is the session variable holding the caller's user ID
+	SessionUserID = "x-hasura-user-id"
+	// SessionRole is the session variable holding the role the caller is acting as
+	SessionRole = "x-hasura-role"
+)
+
+// SessionVariable returns a session variable; Hasura treats their names case-insensitively
+func (r *ActionRequest) SessionVariable(name string) string {
+	if value, ok := r.SessionVariables[name]; ok {
+		return value
+	}
+	for key, value := range r.SessionVariables {
+		if strings.EqualFold(key, name) {
+			return value
+		}
+	}
+	return ""
+}
+
+// UserID returns the ID of the user making the request from the Hasura session.
+// Callers are identified this way rather than by IDs in the input, which they control.
+func (r *ActionRequest) UserID() (uuid.UUID, error) {
+	userID, err := uuid.Parse(r.SessionVariable(SessionUserID))
+	if err != nil {
+		return uuid.Nil, errorx.New(errorx.Unauthorized, "request has no valid user session")
+	}
+	return userID, nil
+}
+
+// Role returns the role the caller is acting as from the Hasura session
+func (r *ActionRequest) Role() string {
+	return r.SessionVariable(SessionRole)
+}
+
 // BaseActionHandler provides common functionality for Hasura action handlers
 type BaseActionHandler struct {
 	log logger.Logger
@@ -43,6 +43,45 @@ type Config struct {
 		WebhookSecret  string `mapstructure:"webhook_secret"`
 	} `mapstructure:"hasura"`
 	
+	// Calendar feed configuration
+	Calendar struct {
+		FeedBaseURL string `mapstructure:"feed_base_url"`
+		SigningKey  string `mapstructure:"signing_key"`
+	} `mapstructure:"calendar"`
+	
+	// Visit check-in verification configuration
+	CheckIn struct {
+		GeofenceRadiusMeters float64 `mapstructure:"geofence_radius_meters"`
+		MaxAccuracyMeters    float64 `mapstructure:"max_accuracy_meters"`
+		CodeSecret           string  `mapstructure:"code_secret"`
+		CodeStepSeconds      int     `mapstructure:"code_step_seconds"`
+	} `mapstructure:"check_in"`
+	
+	// Risk scoring configuration
+	Risk struct {
+		// RulesFile is a YAML or JSON rule set; the built-in rules are used when empty
+		RulesFile string `mapstructure:"rules_file"`
+	} `mapstructure:"risk"`
+	
+	// Child growth configuration
+	Growth struct {
+		// StandardsDir holds the published WHO growth tables, such as wfl_boys.txt;
+		// the service does not start without them
+		StandardsDir string `mapstructure:"standards_dir"`
+	} `mapstructure:"growth"`
+	
+	// Screener configuration
+	Screener struct {
+		// RedAction is what is done for a red screening: sos, referral, follow_up_visit or none
+		RedAction string `mapstructure:"red_action"`
+		// RedActionByConcern overrides RedAction for a screening's primary concern
+		RedActionByConcern map[string]string `mapstructure:"red_action_by_concern"`
+		// ReferralTypes are the facility types red screenings are referred to
+		ReferralTypes      []string `mapstructure:"referral_types"`
+		ReferralRadiusKm   float64  `mapstructure:"referral_radius_km"`
+		FollowUpAfterHours int      `mapstructure:"follow_up_after_hours"`
+	} `mapstructure:"screener"`
+	
 	// Logging configuration
 	Log struct {
 		Level  string `mapstructure:"level"`
@@ -114,4 +153,18 @@ func setDefaults(v *viper.Viper) {
 	
 	// Hasura defaults
 	v.SetDefault("hasura.jwt_namespace", "https://hasura.io/jwt/claims")
+	
+	// Calendar defaults
+	v.SetDefault("calendar.feed_base_url", "http://localhost:8080/calendar")
+	
+	// Check-in defaults
+	v.SetDefault("check_in.geofence_radius_meters", 200)
+	v.SetDefault("check_in.max_accuracy_meters", 150)
+	v.SetDefault("check_in.code_step_seconds", 300)
+	
+	// Screener defaults
+	v.SetDefault("screener.red_action", "sos")
+	v.SetDefault("screener.referral_types", []string{"hospital"})
+	v.SetDefault("screener.referral_radius_km", 50)
+	v.SetDefault("screener.follow_up_after_hours", 4)
 }
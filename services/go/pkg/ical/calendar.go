@@ -0,0 +1,203 @@
+package ical
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// Event statuses defined by RFC 5545
+const (
+	// StatusConfirmed marks an event that will take place
+	StatusConfirmed = "CONFIRMED"
+	// StatusTentative marks an event that is not yet confirmed
+	StatusTentative = "TENTATIVE"
+	// StatusCancelled marks an event that will no longer take place
+	StatusCancelled = "CANCELLED"
+)
+
+// maxLineOctets is the longest content line allowed before folding
+const maxLineOctets = 75
+
+// Alarm is a display reminder before an event starts
+type Alarm struct {
+	Before      time.Duration
+	Description string
+}
+
+// Event is a single VEVENT in a calendar
+type Event struct {
+	UID          string
+	Sequence     int
+	Start        time.Time
+	End          time.Time
+	Summary      string
+	Description  string
+	Location     string
+	Latitude     *float64
+	Longitude    *float64
+	Status       string
+	Categories   []string
+	Created      time.Time
+	LastModified time.Time
+	Alarms       []Alarm
+}
+
+// Calendar is a minimal RFC 5545 calendar writer for published feeds
+type Calendar struct {
+	ProdID string
+	Name   string
+	// RefreshInterval suggests how often subscribed clients should poll the feed
+	RefreshInterval time.Duration
+	Events          []Event
+}
+
+// New creates a new calendar with the given display name
+func New(name string) *Calendar {
+	return &Calendar{
+		ProdID:          "-//MamaCare SL//Visit Calendar//EN",
+		Name:            name,
+		RefreshInterval: time.Hour,
+	}
+}
+
+// AddEvent adds an event to the calendar
+func (c *Calendar) AddEvent(event Event) *Calendar {
+	c.Events = append(c.Events, event)
+	return c
+}
+
+// Bytes renders the calendar as an iCalendar stream
+func (c *Calendar) Bytes() []byte {
+	buf := &bytes.Buffer{}
+	stamp := formatTime(time.Now())
+
+	writeLine(buf, "BEGIN:VCALENDAR")
+	writeLine(buf, "VERSION:2.0")
+	writeLine(buf, "PRODID:"+c.ProdID)
+	writeLine(buf, "CALSCALE:GREGORIAN")
+	writeLine(buf, "METHOD:PUBLISH")
+	if c.Name != "" {
+		writeLine(buf, "X-WR-CALNAME:"+escapeText(c.Name))
+		writeLine(buf, "NAME:"+escapeText(c.Name))
+	}
+	if c.RefreshInterval > 0 {
+		writeLine(buf, "REFRESH-INTERVAL;VALUE=DURATION:"+formatDuration(c.RefreshInterval))
+		writeLine(buf, "X-PUBLISHED-TTL:"+formatDuration(c.RefreshInterval))
+	}
+
+	for _, e := range c.Events {
+		writeLine(buf, "BEGIN:VEVENT")
+		writeLine(buf, "UID:"+e.UID)
+		writeLine(buf, "DTSTAMP:"+stamp)
+		writeLine(buf, fmt.Sprintf("SEQUENCE:%d", e.Sequence))
+		writeLine(buf, "DTSTART:"+formatTime(e.Start))
+		if !e.End.IsZero() {
+			writeLine(buf, "DTEND:"+formatTime(e.End))
+		}
+		writeLine(buf, "SUMMARY:"+escapeText(e.Summary))
+		if e.Description != "" {
+			writeLine(buf, "DESCRIPTION:"+escapeText(e.Description))
+		}
+		if e.Location != "" {
+			writeLine(buf, "LOCATION:"+escapeText(e.Location))
+		}
+		if e.Latitude != nil && e.Longitude != nil {
+			writeLine(buf, fmt.Sprintf("GEO:%.6f;%.6f", *e.Latitude, *e.Longitude))
+		}
+		if len(e.Categories) > 0 {
+			escaped := make([]string, len(e.Categories))
+			for i, category := range e.Categories {
+				escaped[i] = escapeText(category)
+			}
+			writeLine(buf, "CATEGORIES:"+strings.Join(escaped, ","))
+		}
+		if e.Status != "" {
+			writeLine(buf, "STATUS:"+e.Status)
+		}
+		if !e.Created.IsZero() {
+			writeLine(buf, "CREATED:"+formatTime(e.Created))
+		}
+		if !e.LastModified.IsZero() {
+			writeLine(buf, "LAST-MODIFIED:"+formatTime(e.LastModified))
+		}
+		// Cancelled events keep their place in the feed but shouldn't ring
+		if e.Status != StatusCancelled {
+			for _, alarm := range e.Alarms {
+				writeLine(buf, "BEGIN:VALARM")
+				writeLine(buf, "ACTION:DISPLAY")
+				writeLine(buf, "TRIGGER:-"+formatDuration(alarm.Before))
+				writeLine(buf, "DESCRIPTION:"+escapeText(alarm.Description))
+				writeLine(buf, "END:VALARM")
+			}
+		}
+		writeLine(buf, "END:VEVENT")
+	}
+
+	writeLine(buf, "END:VCALENDAR")
+	return buf.Bytes()
+}
+
+// writeLine writes a content line, folding it at 75 octets without splitting characters
+func writeLine(buf *bytes.Buffer, line string) {
+	limit := maxLineOctets
+	for len(line) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(line[cut]) {
+			cut--
+		}
+		buf.WriteString(line[:cut])
+		buf.WriteString("\r\n ")
+		line = line[cut:]
+		// Continuation lines start with a space, which counts towards the limit
+		limit = maxLineOctets - 1
+	}
+	buf.WriteString(line)
+	buf.WriteString("\r\n")
+}
+
+// escapeText escapes a TEXT property value
+func escapeText(text string) string {
+	replacer := strings.NewReplacer(
+		`\`, `\\`,
+		";", `\;`,
+		",", `\,`,
+		"\r\n", `\n`,
+		"\n", `\n`,
+	)
+	return replacer.Replace(text)
+}
+
+// formatTime formats a time as a UTC DATE-TIME value
+func formatTime(t time.Time) string {
+	return t.UTC().Format("20060102T150405Z")
+}
+
+// formatDuration formats a positive duration as an RFC 5545 DURATION value
+func formatDuration(d time.Duration) string {
+	if d < 0 {
+		d = -d
+	}
+
+	day := 24 * time.Hour
+	if d%day == 0 {
+		return fmt.Sprintf("P%dD", d/day)
+	}
+
+	out := "P"
+	if days := d / day; days > 0 {
+		out += fmt.Sprintf("%dD", days)
+		d -= days * day
+	}
+	out += "T"
+	if hours := d / time.Hour; hours > 0 {
+		out += fmt.Sprintf("%dH", hours)
+		d -= hours * time.Hour
+	}
+	if minutes := d / time.Minute; minutes > 0 || out == "PT" {
+		out += fmt.Sprintf("%dM", minutes)
+	}
+	return out
+}
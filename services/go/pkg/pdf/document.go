@@ -0,0 +1,205 @@
+package pdf
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Page sizes in PDF points (1/72 inch)
+const (
+	// A4Width is the width of an A4 page
+	A4Width = 595.28
+	// A4Height is the height of an A4 page
+	A4Height = 841.89
+)
+
+// Document is a minimal PDF writer for text-based documents such as route
+// sheets, reports and cards. It only uses the standard Helvetica fonts so
+// nothing needs to be embedded.
+type Document struct {
+	width  float64
+	height float64
+	title  string
+	author string
+	pages  []*bytes.Buffer
+}
+
+// New creates a new document with the given page size in points
+func New(width, height float64) *Document {
+	return &Document{
+		width:  width,
+		height: height,
+	}
+}
+
+// NewA4 creates a new portrait A4 document
+func NewA4() *Document {
+	return New(A4Width, A4Height)
+}
+
+// SetInfo sets the document title and author metadata
+func (d *Document) SetInfo(title, author string) *Document {
+	d.title = title
+	d.author = author
+	return d
+}
+
+// Width returns the page width
+func (d *Document) Width() float64 {
+	return d.width
+}
+
+// Height returns the page height
+func (d *Document) Height() float64 {
+	return d.height
+}
+
+// PageCount returns the number of pages added so far
+func (d *Document) PageCount() int {
+	return len(d.pages)
+}
+
+// AddPage starts a new page; subsequent drawing goes to this page
+func (d *Document) AddPage() {
+	d.pages = append(d.pages, &bytes.Buffer{})
+}
+
+// current returns the page being drawn on, adding one if needed
+func (d *Document) current() *bytes.Buffer {
+	if len(d.pages) == 0 {
+		d.AddPage()
+	}
+	return d.pages[len(d.pages)-1]
+}
+
+// Text draws text with its baseline at (x, y), where y is measured from the top of the page
+func (d *Document) Text(x, y, size float64, bold bool, text string) {
+	font := "F1"
+	if bold {
+		font = "F2"
+	}
+	fmt.Fprintf(d.current(), "BT /%s %.2f Tf %.2f %.2f Td (%s) Tj ET\n",
+		font, size, x, d.height-y, escape(text))
+}
+
+// Line draws a line between two points, with y measured from the top of the page
+func (d *Document) Line(x1, y1, x2, y2 float64) {
+	fmt.Fprintf(d.current(), "%.2f %.2f m %.2f %.2f l S\n",
+		x1, d.height-y1, x2, d.height-y2)
+}
+
+// Rect draws a rectangle outline whose top-left corner is at (x, y)
+func (d *Document) Rect(x, y, w, h float64) {
+	fmt.Fprintf(d.current(), "%.2f %.2f %.2f %.2f re S\n",
+		x, d.height-y-h, w, h)
+}
+
+// FillRect draws a filled rectangle in the given grey level (0 black, 1 white)
+func (d *Document) FillRect(x, y, w, h, grey float64) {
+	fmt.Fprintf(d.current(), "q %.2f g %.2f %.2f %.2f %.2f re f Q\n",
+		grey, x, d.height-y-h, w, h)
+}
+
+// TextWidth approximates the rendered width of text in Helvetica
+func TextWidth(text string, size float64) float64 {
+	// Helvetica averages roughly half an em per glyph
+	return float64(len(text)) * size * 0.5
+}
+
+// Truncate shortens text so it fits within width at the given size
+func Truncate(text string, size, width float64) string {
+	maxChars := int(width / (size * 0.5))
+	if maxChars <= 0 {
+		return ""
+	}
+	if len(text) <= maxChars {
+		return text
+	}
+	if maxChars <= 3 {
+		return text[:maxChars]
+	}
+	return text[:maxChars-3] + "..."
+}
+
+// WriteTo serialises the document as PDF 1.4
+func (d *Document) WriteTo(w io.Writer) (int64, error) {
+	if len(d.pages) == 0 {
+		d.AddPage()
+	}
+
+	buf := &bytes.Buffer{}
+	offsets := []int{}
+
+	// Object numbering: 1 catalog, 2 pages, 3 info, 4-5 fonts,
+	// then a (page, content) pair for every page
+	writeObj := func(body string) {
+		offsets = append(offsets, buf.Len())
+		fmt.Fprintf(buf, "%d 0 obj\n%s\nendobj\n", len(offsets), body)
+	}
+
+	buf.WriteString("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+
+	firstPage := 6
+	kids := make([]string, len(d.pages))
+	for i := range d.pages {
+		kids[i] = fmt.Sprintf("%d 0 R", firstPage+i*2)
+	}
+
+	writeObj("<< /Type /Catalog /Pages 2 0 R >>")
+	writeObj(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>",
+		strings.Join(kids, " "), len(d.pages)))
+	writeObj(fmt.Sprintf("<< /Title (%s) /Author (%s) /Producer (MamaCare SL) >>",
+		escape(d.title), escape(d.author)))
+	writeObj("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>")
+	writeObj("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica-Bold /Encoding /WinAnsiEncoding >>")
+
+	for i, page := range d.pages {
+		contentObj := firstPage + i*2 + 1
+		writeObj(fmt.Sprintf(
+			"<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %.2f %.2f] "+
+				"/Resources << /Font << /F1 4 0 R /F2 5 0 R >> >> /Contents %d 0 R >>",
+			d.width, d.height, contentObj))
+		writeObj(fmt.Sprintf("<< /Length %d >>\nstream\n%sendstream", page.Len(), page.String()))
+	}
+
+	xref := buf.Len()
+	fmt.Fprintf(buf, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, off := range offsets {
+		fmt.Fprintf(buf, "%010d 00000 n \n", off)
+	}
+	fmt.Fprintf(buf, "trailer\n<< /Size %d /Root 1 0 R /Info 3 0 R >>\nstartxref\n%d\n%%%%EOF\n",
+		len(offsets)+1, xref)
+
+	return buf.WriteTo(w)
+}
+
+// Bytes returns the serialised document
+func (d *Document) Bytes() ([]byte, error) {
+	buf := &bytes.Buffer{}
+	if _, err := d.WriteTo(buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// escape escapes a string for use in a PDF literal string, replacing
+// characters outside printable ASCII since the standard fonts can't show them
+func escape(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		switch {
+		case r == '(' || r == ')' || r == '\\':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case r == '\t':
+			b.WriteString("    ")
+		case r < 32 || r > 126:
+			b.WriteByte('?')
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
@@ -177,7 +177,7 @@ type (
 		ReportedBy  uuid.UUID `json:"reported_by" validate:"required,uuid"`
 		Latitude    float64   `json:"latitude" validate:"required,latitude"`
 		Longitude   float64   `json:"longitude" validate:"required,longitude"`
-		Nature      string    `json:"nature" validate:"required,oneof=labor bleeding accident other"`
+		Nature      string    `json:"nature" validate:"required,oneof=labor bleeding accident newborn other"`
 		Description string    `json:"description" validate:"omitempty,max=1000"`
 	}
 